@@ -8,13 +8,18 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"runtime/pprof"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/omni-lang/omni/internal/cache"
 	"github.com/omni-lang/omni/internal/compiler"
 	"github.com/omni-lang/omni/internal/lexer"
 	"github.com/omni-lang/omni/internal/logging"
+	"github.com/omni-lang/omni/internal/target"
+	"github.com/omni-lang/omni/internal/types/checker"
 )
 
 var (
@@ -48,7 +53,7 @@ func main() {
 		optLevel        = flag.String("O", "O0", "optimization level (O0-O3)")
 		emitFlag        = newStringFlag("exe")
 		emitShort       = newStringFlag("")
-		dump            = flag.String("dump", "", "dump intermediate representation (mir)")
+		dump            = flag.String("dump", "", "dump intermediate representation (mir|cfg|ast|ast-json)")
 		dumpShort       = flag.String("d", "", "alias for -dump")
 		output          = flag.String("o", "", "output binary path")
 		debug           = flag.Bool("debug", false, "generate debug symbols and debug information")
@@ -63,22 +68,46 @@ func main() {
 		quiet           = flag.Bool("quiet", false, "suppress non-error output")
 		quietShort      = flag.Bool("q", false, "alias for -quiet")
 		timeCompile     = flag.Bool("time", false, "print compilation timing summary")
+		profileBuild    = flag.String("profile-build", "", "write a Chrome trace of compilation phases to the given JSON file")
+		profileMode     = flag.String("profile", "", "write a pprof profile of omnic itself (cpu|mem)")
+		profileOutput   = flag.String("profile-output", "", "path of the -profile output (default <output>.pprof)")
 		watchFlag       = flag.Bool("watch", false, "watch input file and recompile on changes")
 		watchShort      = flag.Bool("w", false, "alias for -watch")
+		debounce        = flag.Duration("debounce", 250*time.Millisecond, "how long -watch waits for changes to settle before recompiling")
 		jsonOutput      = flag.Bool("json", false, "output machine-readable JSON for listings")
 		diagnosticsJSON = flag.Bool("diagnostics-json", false, "emit structured JSON diagnostics on failure")
+		strict          = flag.Bool("strict", false, "enable all strict checks (-warn-unreachable -warn-unused-vars -Werror -max-complexity 10)")
+		warnUnreachable = flag.Bool("warn-unreachable", true, "warn about statements after return, throw, break or continue")
+		warnDeadCode    = flag.Bool("warn-dead-code", false, "alias for -warn-unreachable")
+		warnUnusedVars  = flag.Bool("warn-unused-vars", true, "warn about local variables that are never read")
+		warnUnusedParam = flag.Bool("warn-unused-params", false, "warn about function parameters that are never read")
+		werror          = flag.Bool("Werror", false, "treat warnings as errors")
+		warnAsErrors    = flag.Bool("warn-as-errors", false, "alias for -Werror")
+		maxComplexity   = flag.Int("max-complexity", 0, "warn about functions with cyclomatic complexity above N (0 disables)")
+		parallel        = flag.Int("parallel", 1, "number of functions the C backend generates concurrently")
+		parallelShort   = flag.Int("j", 0, "alias for -parallel")
+		divCheck        = flag.Bool("div-check", false, "stop with an error on division or modulo by zero in C builds (always on with -debug)")
+		inlineThreshold = flag.Int("inline-threshold", 0, "inline functions with fewer than N instructions (default 10 above -O0, negative disables)")
+		targetFlag      = flag.String("target", "", "cross-compile for os/arch, e.g. linux/amd64 or windows/arm64 (default host)")
+		cacheDir        = flag.String("cache-dir", "", "directory of the compilation cache (default $XDG_CACHE_HOME/omni)")
+		noCache         = flag.Bool("no-cache", false, "always compile from scratch without reading or updating the cache")
 		version         = flag.Bool("version", false, "print version and exit")
 		versionShort    = flag.Bool("v", false, "alias for -version")
 		verbose         = flag.Bool("verbose", false, "enable verbose output")
 		verboseShort    = flag.Bool("V", false, "alias for -verbose")
+		logLevel        = flag.String("log-level", "", "log level (debug|info|warn|error); overrides -verbose and -quiet")
 		listBackends    = flag.Bool("list-backends", false, "list supported backends and exit")
 		listBackendsSh  = flag.Bool("B", false, "alias for -list-backends")
 		listEmits       = flag.Bool("list-emits", false, "list supported emit targets and exit")
 		listEmitsShort  = flag.Bool("E", false, "alias for -list-emits")
+		emitC           = flag.Bool("emit-c", false, "write the generated C source and stop (same as -emit c)")
+		emitMIRBinary   = flag.Bool("emit-mir-binary", false, "write the MIR in the binary format (same as -emit mir-binary)")
+		verifyFlag      = flag.Bool("verify", false, "run the MIR verifier and stop without generating code (same as -emit verify)")
+		verifyStrict    = flag.Bool("verify-strict", false, "like -verify, also rejecting unreachable blocks and phis that do not match their predecessors")
 		help            = flag.Bool("help", false, "show help and exit")
 		showHelp        = flag.Bool("h", false, "show help and exit")
 	)
-	flag.Var(emitFlag, "emit", "emission format (mir|obj|exe|binary|asm)")
+	flag.Var(emitFlag, "emit", "emission format (mir|mir-binary|obj|exe|binary|asm|c|verify)")
 	flag.Var(emitShort, "e", "alias for -emit")
 
 	flag.Parse()
@@ -107,12 +136,21 @@ func main() {
 	if *debugModulesSh {
 		*debugModules = true
 	}
+	if *warnDeadCode {
+		*warnUnreachable = true
+	}
+	if *warnAsErrors {
+		*werror = true
+	}
+	if *parallelShort != 0 {
+		*parallel = *parallelShort
+	}
 	if emitShort.set {
 		emitFlag.value = emitShort.value
 		emitFlag.set = true
 	}
 	if *noColor {
-		os.Setenv("LOG_COLORIZE", "false")
+		os.Setenv("NO_COLOR", "1")
 	}
 	if *diagnosticsJSON {
 		*jsonOutput = true
@@ -120,7 +158,17 @@ func main() {
 
 	logger := logging.Logger()
 	logging.SetLevel(logging.LevelInfo)
-	if *quiet {
+	if *logLevel != "" {
+		level, err := logging.LevelFromString(*logLevel)
+		if err != nil {
+			logger.ErrorString(err.Error())
+			os.Exit(2)
+		}
+		if *quiet || *verbose || *verboseShort {
+			logger.WarnString("-log-level takes precedence over -verbose and -quiet")
+		}
+		logging.SetLevel(level)
+	} else if *quiet {
 		logging.SetLevel(logging.LevelError)
 	} else if *verbose || *verboseShort {
 		logging.SetLevel(logging.LevelDebug)
@@ -153,7 +201,9 @@ func main() {
 		os.Exit(2)
 	}
 
-	input := flag.Arg(0)
+	inputs := flag.Args()
+	input := inputs[0]
+	inputLabel := strings.Join(inputs, ", ")
 	emit := emitFlag.value
 	if !emitFlag.set {
 		// Set appropriate defaults based on backend
@@ -165,16 +215,136 @@ func main() {
 			emit = "obj"
 		}
 	}
+	if *emitC {
+		if emitFlag.set && emitFlag.value != "c" {
+			logger.ErrorString(fmt.Sprintf("-emit-c conflicts with -emit %s", emitFlag.value))
+			os.Exit(2)
+		}
+		emit = "c"
+	}
+	if *emitMIRBinary {
+		if emitFlag.set && emitFlag.value != "mir-binary" {
+			logger.ErrorString(fmt.Sprintf("-emit-mir-binary conflicts with -emit %s", emitFlag.value))
+			os.Exit(2)
+		}
+		if *emitC {
+			logger.ErrorString("-emit-mir-binary conflicts with -emit-c")
+			os.Exit(2)
+		}
+		emit = "mir-binary"
+	}
+	if *verifyFlag || *verifyStrict {
+		if emitFlag.set && emitFlag.value != "verify" {
+			logger.ErrorString(fmt.Sprintf("-verify conflicts with -emit %s", emitFlag.value))
+			os.Exit(2)
+		}
+		if *emitC || *emitMIRBinary {
+			logger.ErrorString("-verify conflicts with -emit-c and -emit-mir-binary")
+			os.Exit(2)
+		}
+		emit = "verify"
+	}
+
+	tgt := target.Host()
+	if *targetFlag != "" {
+		parsed, err := target.Parse(*targetFlag)
+		if err != nil {
+			logger.ErrorString(err.Error())
+			os.Exit(2)
+		}
+		tgt = parsed
+	}
+
+	if *parallel < 1 {
+		logger.ErrorString(fmt.Sprintf("-parallel must be at least 1, got %d", *parallel))
+		os.Exit(2)
+	}
+
+	checks := checker.Options{
+		WarnDeadCode:     *warnUnreachable,
+		WarnUnusedVars:   *warnUnusedVars,
+		WarnUnusedParams: *warnUnusedParam,
+		WarningsAsErrors: *werror,
+		MaxComplexity:    *maxComplexity,
+	}
+	project, err := compiler.FindProjectConfig(filepath.Dir(input))
+	if err != nil {
+		logger.ErrorString(err.Error())
+		os.Exit(2)
+	}
+	if *strict || project.Strict {
+		checks = withStrict(checks)
+	}
 
+	// Verification writes no output
 	finalOutput := *output
-	if finalOutput == "" {
-		finalOutput = deriveOutputPath(input, emit, *emitDir, *emitPrefix)
+	if finalOutput == "" && emit != "verify" {
+		finalOutput = deriveOutputPath(inputs, emit, *emitDir, *emitPrefix, tgt)
 	}
 
+	switch *profileMode {
+	case "", "cpu", "mem":
+	default:
+		logger.ErrorString(fmt.Sprintf("unsupported -profile %q (expected cpu or mem)", *profileMode))
+		os.Exit(2)
+	}
+	if *profileOutput != "" && *profileMode == "" {
+		logger.ErrorString("-profile-output requires -profile")
+		os.Exit(2)
+	}
+	profilePath := *profileOutput
+	if profilePath == "" && *profileMode != "" {
+		base := finalOutput
+		if base == "" {
+			base = compiler.DefaultOutputPath(input, emit)
+		}
+		profilePath = base + ".pprof"
+	}
+
+	var dumpPath string
+	switch *dump {
+	case "cfg":
+		dumpPath = deriveOutputPath(inputs, "dot", *emitDir, *emitPrefix, tgt)
+	case "ast":
+		dumpPath = deriveOutputPath(inputs, "ast", *emitDir, *emitPrefix, tgt)
+	case "ast-json":
+		dumpPath = deriveOutputPath(inputs, "ast.json", *emitDir, *emitPrefix, tgt)
+	}
+
+	// Dumps and build profiles are side effects of compiling, so a cached
+	// artifact cannot stand in for them.
+	var buildCache *cache.Cache
+	if !*noCache && *dump == "" && *profileBuild == "" && *profileMode == "" && emit != "verify" {
+		dir := *cacheDir
+		if dir == "" {
+			dir, err = cache.DefaultDir()
+		}
+		if err == nil {
+			buildCache, err = cache.Open(dir)
+		}
+		if err != nil {
+			logger.WarnString("compilation cache disabled: " + err.Error())
+		}
+	}
+
+	// deps collects the files read by the most recent compilation so that
+	// watch mode can follow imports.
+	var deps []string
 	compileAndReport := func() (string, error) {
 		start := time.Now()
-		outputPath, err := run(input, finalOutput, *backend, *optLevel, emit, *dump, *verbose || *verboseShort, *debug, *debugModules)
+		var (
+			outputPath string
+			cached     bool
+			warnings   []string
+		)
+		err := profileCompile(*profileMode, profilePath, func() (err error) {
+			outputPath, cached, err = run(inputs, finalOutput, *backend, *optLevel, emit, *dump, dumpPath, *profileBuild, *verbose || *verboseShort, *debug, *debugModules, *verifyStrict, *divCheck, checks, *parallel, *inlineThreshold, tgt, buildCache, &deps, &warnings)
+			return err
+		})
 		duration := time.Since(start)
+		for _, warning := range warnings {
+			logger.WarnString(warning)
+		}
 		if err != nil {
 			logger.ErrorString(err.Error())
 			if (*jsonOutput || *diagnosticsJSON) && !*watchFlag {
@@ -188,6 +358,9 @@ func main() {
 					"output":    outputPath,
 					"timestamp": time.Now().Format(time.RFC3339Nano),
 				}
+				if len(inputs) > 1 {
+					payload["inputs"] = inputs
+				}
 				if duration > 0 {
 					payload["duration_ms"] = float64(duration) / float64(time.Millisecond)
 				}
@@ -203,13 +376,20 @@ func main() {
 		if target == "" {
 			target = "(default)"
 		}
+		if cached {
+			target += " (cached)"
+		}
 
-		if *timeCompile && !*quiet && !*jsonOutput {
+		if emit == "verify" {
+			if !*quiet && !*jsonOutput && !*watchFlag {
+				logging.Logger().InfoString(fmt.Sprintf("Verified MIR of %s in %s", inputLabel, duration.Round(time.Millisecond)))
+			}
+		} else if *timeCompile && !*quiet && !*jsonOutput {
 			logging.Logger().InfoString(fmt.Sprintf("Compiled %s -> %s in %s (backend=%s emit=%s)",
-				input, target, duration.Round(time.Millisecond), *backend, emit))
+				inputLabel, target, duration.Round(time.Millisecond), *backend, emit))
 		} else if !*quiet && !*jsonOutput && !*watchFlag {
 			logging.Logger().InfoString(fmt.Sprintf("Compiled %s -> %s (backend=%s emit=%s)",
-				input, target, *backend, emit))
+				inputLabel, target, *backend, emit))
 		}
 
 		if *jsonOutput && !*watchFlag {
@@ -222,6 +402,12 @@ func main() {
 				"output":    outputPath,
 				"timestamp": time.Now().Format(time.RFC3339Nano),
 			}
+			if len(inputs) > 1 {
+				result["inputs"] = inputs
+			}
+			if cached {
+				result["cached"] = true
+			}
 			if *timeCompile {
 				result["duration_ms"] = float64(duration) / float64(time.Millisecond)
 			}
@@ -231,9 +417,9 @@ func main() {
 		return outputPath, nil
 	}
 
-	compileOnce := func() error {
+	compileOnce := func() ([]string, error) {
 		_, err := compileAndReport()
-		return err
+		return deps, err
 	}
 
 	if *watchFlag {
@@ -241,7 +427,7 @@ func main() {
 			logger.ErrorString("cannot use --watch together with --json")
 			os.Exit(2)
 		}
-		if err := watchAndCompile(input, compileOnce, *quiet); err != nil {
+		if err := watchAndCompile(input, compileOnce, *debounce, *quiet); err != nil {
 			logger.ErrorString(err.Error())
 			os.Exit(1)
 		}
@@ -257,16 +443,25 @@ func showUsage() {
 	fmt.Fprintf(os.Stderr, "OmniLang Compiler (omnic) %s\n", Version)
 	fmt.Fprintf(os.Stderr, "Built: %s\n\n", BuildTime)
 	fmt.Fprintf(os.Stderr, "USAGE:\n")
-	fmt.Fprintf(os.Stderr, "  omnic [options] <file.omni>\n\n")
+	fmt.Fprintf(os.Stderr, "  omnic [options] <file.omni> [more.omni...]\n\n")
 	fmt.Fprintf(os.Stderr, "OPTIONS:\n")
 	fmt.Fprintf(os.Stderr, "  -backend, -b string\n")
 	fmt.Fprintf(os.Stderr, "        code generation backend (vm|clift|c) (default \"c\")\n")
 	fmt.Fprintf(os.Stderr, "  -O string\n")
 	fmt.Fprintf(os.Stderr, "        optimization level (O0-O3) (default \"O0\")\n")
 	fmt.Fprintf(os.Stderr, "  -emit, -e string\n")
-	fmt.Fprintf(os.Stderr, "        emission format (mir|obj|exe|binary|asm) (default \"exe\")\n")
+	fmt.Fprintf(os.Stderr, "        emission format (mir|mir-binary|obj|exe|binary|asm|c|verify) (default \"exe\")\n")
+	fmt.Fprintf(os.Stderr, "  -emit-c\n")
+	fmt.Fprintf(os.Stderr, "        write the generated C source instead of compiling it (same as -emit c)\n")
+	fmt.Fprintf(os.Stderr, "  -emit-mir-binary\n")
+	fmt.Fprintf(os.Stderr, "        write the MIR in the binary format to a .mirb file (same as -emit mir-binary)\n")
+	fmt.Fprintf(os.Stderr, "  -verify\n")
+	fmt.Fprintf(os.Stderr, "        run the MIR verifier, print its errors and stop before code generation (same as -emit verify)\n")
+	fmt.Fprintf(os.Stderr, "  -verify-strict\n")
+	fmt.Fprintf(os.Stderr, "        like -verify, also rejecting unreachable blocks and phis that do not match their predecessors\n")
 	fmt.Fprintf(os.Stderr, "  -dump, -d string\n")
-	fmt.Fprintf(os.Stderr, "        dump intermediate representation (mir)\n")
+	fmt.Fprintf(os.Stderr, "        dump intermediate representation (mir, cfg for a Graphviz .dot file,\n")
+	fmt.Fprintf(os.Stderr, "        ast for the parsed syntax tree in a .ast file, or ast-json for it as JSON)\n")
 	fmt.Fprintf(os.Stderr, "  -o string\n")
 	fmt.Fprintf(os.Stderr, "        output binary path\n")
 	fmt.Fprintf(os.Stderr, "  -emit-dir, -C string\n")
@@ -281,16 +476,51 @@ func showUsage() {
 	fmt.Fprintf(os.Stderr, "        enable verbose output\n")
 	fmt.Fprintf(os.Stderr, "  -quiet, -q\n")
 	fmt.Fprintf(os.Stderr, "        suppress non-error output\n")
+	fmt.Fprintf(os.Stderr, "  -log-level string\n")
+	fmt.Fprintf(os.Stderr, "        log level: debug, info, warn or error (overrides -verbose and -quiet)\n")
 	fmt.Fprintf(os.Stderr, "  -no-color\n")
-	fmt.Fprintf(os.Stderr, "        disable colored log output\n")
+	fmt.Fprintf(os.Stderr, "        disable colored log output (also set by NO_COLOR)\n")
 	fmt.Fprintf(os.Stderr, "  -time\n")
 	fmt.Fprintf(os.Stderr, "        print compilation timing summary\n")
+	fmt.Fprintf(os.Stderr, "  -profile-build string\n")
+	fmt.Fprintf(os.Stderr, "        write a Chrome trace (chrome://tracing) of compilation phases to a JSON file\n")
+	fmt.Fprintf(os.Stderr, "  -profile string\n")
+	fmt.Fprintf(os.Stderr, "        write a pprof profile of the compiler itself (cpu|mem)\n")
+	fmt.Fprintf(os.Stderr, "  -profile-output string\n")
+	fmt.Fprintf(os.Stderr, "        path of the -profile output (default <output>.pprof)\n")
 	fmt.Fprintf(os.Stderr, "  -watch, -w\n")
-	fmt.Fprintf(os.Stderr, "        watch input file for changes and recompile\n")
+	fmt.Fprintf(os.Stderr, "        watch input file and its imports for changes and recompile\n")
+	fmt.Fprintf(os.Stderr, "  -debounce duration\n")
+	fmt.Fprintf(os.Stderr, "        quiet period before -watch recompiles, 10ms to 30s (default 250ms)\n")
 	fmt.Fprintf(os.Stderr, "  -json\n")
 	fmt.Fprintf(os.Stderr, "        output machine-readable JSON for listings and one-shot builds\n")
 	fmt.Fprintf(os.Stderr, "  -diagnostics-json\n")
 	fmt.Fprintf(os.Stderr, "        include structured diagnostics in JSON output when compilation fails\n")
+	fmt.Fprintf(os.Stderr, "  -strict\n")
+	fmt.Fprintf(os.Stderr, "        enable all strict checks: -warn-unreachable, -warn-unused-vars, -Werror and -max-complexity 10\n")
+	fmt.Fprintf(os.Stderr, "        (also enabled by strict = true in omni.toml; combine with the flags below to fine-tune)\n")
+	fmt.Fprintf(os.Stderr, "  -warn-unreachable, -warn-dead-code\n")
+	fmt.Fprintf(os.Stderr, "        warn about statements after return, throw, break or continue (default true;\n")
+	fmt.Fprintf(os.Stderr, "        -warn-unreachable=false turns it off)\n")
+	fmt.Fprintf(os.Stderr, "  -warn-unused-vars\n")
+	fmt.Fprintf(os.Stderr, "        warn about local variables that are never read; names starting with _ are exempt\n")
+	fmt.Fprintf(os.Stderr, "        (default true; -warn-unused-vars=false turns it off)\n")
+	fmt.Fprintf(os.Stderr, "  -warn-unused-params\n")
+	fmt.Fprintf(os.Stderr, "        warn about function parameters that are never read, except method receivers\n")
+	fmt.Fprintf(os.Stderr, "  -Werror, -warn-as-errors\n")
+	fmt.Fprintf(os.Stderr, "        treat warnings as errors\n")
+	fmt.Fprintf(os.Stderr, "  -max-complexity int\n")
+	fmt.Fprintf(os.Stderr, "        warn about functions whose cyclomatic complexity exceeds the limit\n")
+	fmt.Fprintf(os.Stderr, "  -target string\n")
+	fmt.Fprintf(os.Stderr, "        cross-compile for os/arch, e.g. linux/arm64 or windows/amd64 (default host; uses clang)\n")
+	fmt.Fprintf(os.Stderr, "  -parallel, -j int\n")
+	fmt.Fprintf(os.Stderr, "        number of functions the C backend generates concurrently (default 1)\n")
+	fmt.Fprintf(os.Stderr, "  -div-check\n")
+	fmt.Fprintf(os.Stderr, "        stop with an error on division or modulo by zero in C builds (always on with -debug)\n")
+	fmt.Fprintf(os.Stderr, "  -cache-dir string\n")
+	fmt.Fprintf(os.Stderr, "        directory of the compilation cache (default $XDG_CACHE_HOME/omni)\n")
+	fmt.Fprintf(os.Stderr, "  -no-cache\n")
+	fmt.Fprintf(os.Stderr, "        always compile from scratch without reading or updating the cache\n")
 	fmt.Fprintf(os.Stderr, "  -version, -v\n")
 	fmt.Fprintf(os.Stderr, "        print version and exit\n")
 	fmt.Fprintf(os.Stderr, "  -list-backends, -B\n")
@@ -304,25 +534,51 @@ func showUsage() {
 	fmt.Fprintf(os.Stderr, "  omnic -backend vm hello.omni        # Compile with VM backend to MIR\n")
 	fmt.Fprintf(os.Stderr, "  omnic -backend clift hello.omni     # Compile with Cranelift backend\n")
 	fmt.Fprintf(os.Stderr, "  omnic -emit mir hello.omni          # Emit MIR instead of binary\n")
+	fmt.Fprintf(os.Stderr, "  omnic -emit-c hello.omni            # Write hello.c for another C toolchain\n")
+	fmt.Fprintf(os.Stderr, "  omnic -b vm -emit-mir-binary hello.omni  # Write hello.mirb for MIR tooling\n")
+	fmt.Fprintf(os.Stderr, "  omnic -verify-strict hello.omni     # Check the MIR without generating code\n")
 	fmt.Fprintf(os.Stderr, "  omnic -verbose hello.omni           # Show compilation steps\n")
 	fmt.Fprintf(os.Stderr, "  omnic -dump mir hello.omni          # Dump MIR to file\n")
+	fmt.Fprintf(os.Stderr, "  omnic -dump cfg hello.omni          # Write the control-flow graphs to hello.dot\n")
+	fmt.Fprintf(os.Stderr, "  omnic -dump ast hello.omni          # Write the parsed AST to hello.ast\n")
+	fmt.Fprintf(os.Stderr, "  omnic -profile-build trace.json hello.omni  # Profile the compiler itself\n")
+	fmt.Fprintf(os.Stderr, "  omnic -strict -max-complexity 15 hello.omni # Strict checks with a looser complexity limit\n")
+	fmt.Fprintf(os.Stderr, "  omnic -o app main.omni utils.omni   # Compile several files into one program\n")
+	fmt.Fprintf(os.Stderr, "  omnic -target windows/amd64 hello.omni   # Cross-compile hello.exe for Windows\n")
+	fmt.Fprintf(os.Stderr, "  omnic -j 8 big.omni                 # Generate C for up to 8 functions at once\n")
 }
 
-func run(input, output, backend, optLevel, emit, dump string, verbose, debug, debugModules bool) (string, error) {
-	if filepath.Ext(input) != ".omni" {
-		return "", fmt.Errorf("%s: unsupported input (expected .omni)", input)
+// withStrict enables every check implied by -strict on top of opts. The
+// -warn-unused-params choice and an explicit -max-complexity limit are kept,
+// the latter so it can loosen or tighten the default.
+func withStrict(opts checker.Options) checker.Options {
+	strict := checker.StrictOptions()
+	strict.WarnUnusedParams = opts.WarnUnusedParams
+	if opts.MaxComplexity > 0 {
+		strict.MaxComplexity = opts.MaxComplexity
+	}
+	return strict
+}
+
+// run compiles inputs and returns the output path and whether the artifacts
+// were restored from buildCache. A nil buildCache always compiles.
+func run(inputs []string, output, backend, optLevel, emit, dump, dumpPath, profileBuild string, verbose, debug, debugModules, verifyStrict, divCheck bool, checks checker.Options, parallelism, inlineThreshold int, tgt target.Target, buildCache *cache.Cache, deps, warnings *[]string) (string, bool, error) {
+	for _, input := range inputs {
+		if filepath.Ext(input) != ".omni" {
+			return "", false, fmt.Errorf("%s: unsupported input (expected .omni)", input)
+		}
 	}
 
 	if output != "" {
 		if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
-			return "", fmt.Errorf("create output directory: %w", err)
+			return "", false, fmt.Errorf("create output directory: %w", err)
 		}
 	}
 
 	logger := logging.Logger()
 
 	if verbose {
-		logger.DebugString("Compiling " + input + "...")
+		logger.DebugString("Compiling " + strings.Join(inputs, ", ") + "...")
 		logger.DebugFields("Compilation settings",
 			logging.String("backend", backend),
 			logging.String("optimization", optLevel),
@@ -336,40 +592,176 @@ func run(input, output, backend, optLevel, emit, dump string, verbose, debug, de
 		}
 	}
 
+	// The warnings are kept as text, which is how the cache stores them for
+	// a hit to report again
+	var checked []checker.Warning
 	cfg := compiler.Config{
-		InputPath:    input,
+		InputPaths:   inputs,
 		OutputPath:   output,
 		Backend:      backend,
 		OptLevel:     optLevel,
 		Emit:         emit,
+		EmitC:        emit == "c",
 		Dump:         dump,
+		DumpPath:     dumpPath,
 		DebugInfo:    debug,
 		DebugModules: debugModules,
+		ProfileBuild: profileBuild,
+		Checks:       checks,
+		TargetOS:     tgt.OS,
+		TargetArch:   tgt.Arch,
+		Parallelism:  parallelism,
+		DivCheck:     divCheck,
+		RecordDeps:   deps,
+		Warnings:     &checked,
+		VerifyStrict: verifyStrict,
+
+		InlineThreshold: inlineThreshold,
+	}
+
+	key := cache.Key{
+		Inputs:    inputs,
+		Backend:   backend,
+		OptLevel:  optLevel,
+		Emit:      emit,
+		DebugInfo: debug,
+		Target:    tgt.String(),
+		Extra:     fmt.Sprintf("%s %+v inline=%d divcheck=%t", toolchainFingerprint(), checks, inlineThreshold, divCheck),
+	}
+	artifacts := cacheArtifacts(cfg, emit, tgt)
+	if buildCache != nil {
+		entry, hit, err := buildCache.Get(key, artifacts)
+		if err != nil {
+			logger.WarnString("compilation cache: " + err.Error())
+		} else if hit {
+			*deps = entry.Deps
+			*warnings = entry.Warnings
+			if verbose {
+				logger.DebugFields("Restored from compilation cache", logging.String("dir", buildCache.Dir()))
+			}
+			return cfg.OutputPath, true, nil
+		}
 	}
 
 	if verbose {
 		logger.DebugString("Starting compilation...")
 	}
 
-	if err := compiler.Compile(cfg); err != nil {
+	err := compiler.Compile(cfg)
+	for _, warning := range checked {
+		*warnings = append(*warnings, strings.TrimRight(warning.Error(), "\n"))
+	}
+	if err != nil {
 		if errors.Is(err, compiler.ErrNotImplemented) {
-			return "", fmt.Errorf("omnic: feature not implemented: %w", err)
+			return "", false, fmt.Errorf("omnic: feature not implemented: %w", err)
 		}
-		return "", err
+		return "", false, err
 	}
 
 	if verbose {
 		logger.DebugString("Compilation completed successfully!")
 	}
 
-	return cfg.OutputPath, nil
+	if buildCache != nil {
+		var written []string
+		for _, path := range artifacts {
+			if _, err := os.Stat(path); err != nil {
+				break
+			}
+			written = append(written, path)
+		}
+		if err := buildCache.Put(key, cache.Entry{Deps: *deps, Warnings: *warnings}, written); err != nil {
+			logger.WarnString("compilation cache: " + err.Error())
+		}
+	}
+
+	return cfg.OutputPath, false, nil
+}
+
+// cacheArtifacts lists the files a compilation with cfg writes, main output
+// first. Optional files come last so that the ones that were not written can
+// be dropped from the end.
+func cacheArtifacts(cfg compiler.Config, emit string, tgt target.Target) []string {
+	output := cfg.OutputPath
+	if output == "" {
+		output = compiler.DefaultOutputPath(cfg.InputPaths[0], emit)
+		if cfg.Backend == "c" && emit == "exe" {
+			output += tgt.ExeSuffix()
+		}
+	}
+	artifacts := []string{output}
+	if cfg.DebugInfo && cfg.Backend == "c" && emit == "exe" {
+		artifacts = append(artifacts, compiler.SourceMapPath(output))
+	}
+	return artifacts
+}
+
+// profileCompile runs compile under the pprof profile selected by -profile
+// and writes it to path: a CPU profile covering the whole compilation, or a
+// heap profile taken once it has finished. An empty mode runs compile as is.
+func profileCompile(mode, path string, compile func() error) error {
+	if mode == "" {
+		return compile()
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create profile: %w", err)
+	}
+	defer f.Close()
+
+	switch mode {
+	case "cpu":
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return fmt.Errorf("start CPU profile: %w", err)
+		}
+		err = compile()
+		pprof.StopCPUProfile()
+	case "mem":
+		err = compile()
+		runtime.GC()
+		if writeErr := pprof.WriteHeapProfile(f); writeErr != nil && err == nil {
+			err = fmt.Errorf("write heap profile: %w", writeErr)
+		}
+	default:
+		return fmt.Errorf("unsupported profile %q", mode)
+	}
+	if closeErr := f.Close(); closeErr != nil && err == nil {
+		err = fmt.Errorf("write profile: %w", closeErr)
+	}
+	return err
+}
+
+var (
+	fingerprintOnce sync.Once
+	fingerprint     string
+)
+
+// toolchainFingerprint identifies the running omnic build so that cache
+// entries written by a different compiler are never reused. Development
+// builds all report the same Version, so the executable itself is hashed.
+func toolchainFingerprint() string {
+	fingerprintOnce.Do(func() {
+		fingerprint = Version
+		exe, err := os.Executable()
+		if err != nil {
+			return
+		}
+		if sum, err := cache.HashFile(exe); err == nil {
+			fingerprint += "+" + sum
+		}
+	})
+	return fingerprint
 }
 
-func deriveOutputPath(input, emit, emitDir, emitPrefix string) string {
+// deriveOutputPath names the output after the first input file when -o is
+// not given.
+func deriveOutputPath(inputs []string, emit, emitDir, emitPrefix string, tgt target.Target) string {
 	if emitDir == "" && emitPrefix == "" && emit == "exe" {
 		return ""
 	}
 
+	input := inputs[0]
+
 	dir := filepath.Dir(input)
 	if emitDir != "" {
 		dir = emitDir
@@ -383,6 +775,8 @@ func deriveOutputPath(input, emit, emitDir, emitPrefix string) string {
 	switch emit {
 	case "mir":
 		ext = ".mir"
+	case "mir-binary":
+		ext = ".mirb"
 	case "obj":
 		ext = ".o"
 	case "asm":
@@ -390,9 +784,7 @@ func deriveOutputPath(input, emit, emitDir, emitPrefix string) string {
 	case "binary":
 		ext = ".bin"
 	case "exe":
-		if runtime.GOOS == "windows" {
-			ext = ".exe"
-		}
+		ext = tgt.ExeSuffix()
 	default:
 		ext = "." + emit
 	}
@@ -414,19 +806,19 @@ func printBackends(jsonOutput bool) {
 			Name:        "c",
 			Description: "C code-generation backend (default)",
 			Default:     true,
-			Emits:       []string{"exe", "obj", "asm", "binary"},
+			Emits:       []string{"exe", "obj", "asm", "binary", "c", "verify"},
 		},
 		{
 			Name:        "vm",
 			Description: "Virtual machine interpreter backend",
-			Emits:       []string{"mir"},
+			Emits:       []string{"mir", "mir-binary", "verify"},
 			Notes:       []string{"emits MIR for execution with omnir"},
 		},
 		{
 			Name:         "clift",
 			Description:  "Cranelift backend",
 			Experimental: true,
-			Emits:        []string{"obj"},
+			Emits:        []string{"obj", "verify"},
 			Notes:        []string{"requires Rust toolchain for native bridge"},
 		},
 	}
@@ -481,6 +873,13 @@ func printEmits(jsonOutput bool) {
 			DefaultBackend: "vm",
 			FileExtension:  ".mir",
 		},
+		{
+			Name:           "mir-binary",
+			Description:    "OmniLang MIR in the versioned binary format",
+			DefaultBackend: "vm",
+			FileExtension:  ".mirb",
+			ProducesBinary: true,
+		},
 		{
 			Name:            "obj",
 			Description:     "Object file (used by C/Cranelift backends)",
@@ -499,11 +898,28 @@ func printEmits(jsonOutput bool) {
 			Description:   "Assembly listing",
 			FileExtension: ".s",
 		},
+		{
+			Name:          "c",
+			Description:   "Generated C source, not compiled (C backend)",
+			FileExtension: ".c",
+		},
+		{
+			Name:        "verify",
+			Description: "MIR verification only, without output (any backend)",
+		},
+	}
+	// The intermediate forms -dump writes while compiling
+	dumps := []emitInfo{
+		{Name: "mir", Description: "MIR printed to standard output"},
+		{Name: "cfg", Description: "Control-flow graphs as Graphviz source", FileExtension: ".dot"},
+		{Name: "ast", Description: "Parsed syntax tree of the input", FileExtension: ".ast"},
+		{Name: "ast-json", Description: "Parsed syntax tree of the input as JSON, with spans", FileExtension: ".ast.json"},
 	}
 	if jsonOutput {
 		payload := map[string]any{
 			"status": "ok",
 			"emits":  data,
+			"dumps":  dumps,
 		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
@@ -523,6 +939,13 @@ func printEmits(jsonOutput bool) {
 			fmt.Printf("            requires external linking\n")
 		}
 	}
+	fmt.Println("Available dumps (-dump):")
+	for _, entry := range dumps {
+		fmt.Printf("  %-9s- %s\n", entry.Name, entry.Description)
+		if entry.FileExtension != "" {
+			fmt.Printf("            extension: %s\n", entry.FileExtension)
+		}
+	}
 }
 
 func runtimeExeExtension() string {
@@ -532,13 +955,19 @@ func runtimeExeExtension() string {
 	return ""
 }
 
-func watchAndCompile(path string, compile func() error, quiet bool) error {
+// watchAndCompile compiles path and recompiles whenever it or any file it
+// imports changes. compile returns the files read by the compilation; their
+// directories are added to the watcher after every build so that imports
+// added while watching are picked up too. Bursts of events are coalesced
+// into one rebuild once no change has been seen for delay.
+func watchAndCompile(path string, compile func() ([]string, error), delay time.Duration, quiet bool) error {
+	if err := checkDebounce(delay); err != nil {
+		return err
+	}
 	abs, err := filepath.Abs(path)
 	if err != nil {
 		return fmt.Errorf("resolve path: %w", err)
 	}
-	dir := filepath.Dir(abs)
-	base := filepath.Base(abs)
 
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -546,8 +975,9 @@ func watchAndCompile(path string, compile func() error, quiet bool) error {
 	}
 	defer watcher.Close()
 
-	if err := watcher.Add(dir); err != nil {
-		return fmt.Errorf("watch directory %s: %w", dir, err)
+	watched := newWatchSet()
+	if err := watched.add(watcher, []string{abs}); err != nil {
+		return err
 	}
 
 	if !quiet {
@@ -555,9 +985,14 @@ func watchAndCompile(path string, compile func() error, quiet bool) error {
 			logging.String("file", abs))
 	}
 
-	if err := compile(); err != nil {
+	recompile := func() {
 		// Errors are already logged; continue watching.
+		deps, _ := compile()
+		if err := watched.add(watcher, deps); err != nil {
+			logging.Logger().ErrorFields("watch error", logging.Error("error", err))
+		}
 	}
+	recompile()
 
 	debounce := time.NewTimer(time.Hour)
 	debounce.Stop()
@@ -565,7 +1000,7 @@ func watchAndCompile(path string, compile func() error, quiet bool) error {
 	for {
 		select {
 		case event := <-watcher.Events:
-			if filepath.Base(event.Name) != base {
+			if !watched.matches(event.Name) {
 				continue
 			}
 			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
@@ -577,11 +1012,9 @@ func watchAndCompile(path string, compile func() error, quiet bool) error {
 				default:
 				}
 			}
-			debounce.Reset(200 * time.Millisecond)
+			debounce.Reset(delay)
 		case <-debounce.C:
-			if err := compile(); err != nil {
-				// already logged
-			}
+			recompile()
 			debounce.Stop()
 		case err := <-watcher.Errors:
 			logging.Logger().ErrorFields("watch error", logging.Error("error", err))
@@ -589,6 +1022,57 @@ func watchAndCompile(path string, compile func() error, quiet bool) error {
 	}
 }
 
+// Bounds accepted by -debounce.
+const (
+	minDebounce = 10 * time.Millisecond
+	maxDebounce = 30 * time.Second
+)
+
+// checkDebounce reports an error if d is outside [minDebounce, maxDebounce].
+func checkDebounce(d time.Duration) error {
+	if d < minDebounce || d > maxDebounce {
+		return fmt.Errorf("-debounce must be between %s and %s, got %s", minDebounce, maxDebounce, d)
+	}
+	return nil
+}
+
+// watchSet tracks the files watch mode recompiles for and the directories
+// registered with the watcher. Directories are watched rather than files so
+// that editors which save by renaming a temporary file are still noticed.
+type watchSet struct {
+	files map[string]bool
+	dirs  map[string]bool
+}
+
+func newWatchSet() *watchSet {
+	return &watchSet{files: make(map[string]bool), dirs: make(map[string]bool)}
+}
+
+// add starts tracking paths, registering each new directory with watcher.
+func (w *watchSet) add(watcher *fsnotify.Watcher, paths []string) error {
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		w.files[path] = true
+		dir := filepath.Dir(path)
+		if w.dirs[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("watch directory %s: %w", dir, err)
+		}
+		w.dirs[dir] = true
+	}
+	return nil
+}
+
+// matches reports whether an event for name concerns a tracked file.
+func (w *watchSet) matches(name string) bool {
+	if abs, err := filepath.Abs(name); err == nil {
+		name = abs
+	}
+	return w.files[filepath.Clean(name)]
+}
+
 func buildDiagnostics(err error) []map[string]any {
 	var diags []lexer.Diagnostic
 	collectDiagnostics(err, &diags)
@@ -2,7 +2,18 @@ package main
 
 import (
 	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/omni-lang/omni/internal/cache"
+	"github.com/omni-lang/omni/internal/compiler"
+	"github.com/omni-lang/omni/internal/target"
+	"github.com/omni-lang/omni/internal/types/checker"
 )
 
 func TestStringFlag(t *testing.T) {
@@ -142,3 +153,111 @@ func TestStringFlagEdgeCases(t *testing.T) {
 		t.Errorf("Expected special string, got '%s'", flag.value)
 	}
 }
+
+func TestWatchSetTracksImports(t *testing.T) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatalf("create watcher: %v", err)
+	}
+	defer watcher.Close()
+
+	root := t.TempDir()
+	libDir := t.TempDir()
+	main := filepath.Join(root, "hello.omni")
+	utils := filepath.Join(libDir, "utils.omni")
+
+	watched := newWatchSet()
+	if err := watched.add(watcher, []string{main}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := watched.add(watcher, []string{main, utils}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	if len(watched.dirs) != 2 || !watched.dirs[root] || !watched.dirs[libDir] {
+		t.Errorf("watched dirs = %v, want %s and %s", watched.dirs, root, libDir)
+	}
+	if !watched.matches(main) || !watched.matches(utils) {
+		t.Error("expected events for the root file and its import to match")
+	}
+	if watched.matches(filepath.Join(root, "other.omni")) || watched.matches(filepath.Join(root, "utils.omni")) {
+		t.Error("events for untracked files should not match")
+	}
+}
+
+func TestCheckDebounce(t *testing.T) {
+	for _, d := range []time.Duration{10 * time.Millisecond, 250 * time.Millisecond, 30 * time.Second} {
+		if err := checkDebounce(d); err != nil {
+			t.Errorf("checkDebounce(%s) = %v, want nil", d, err)
+		}
+	}
+	for _, d := range []time.Duration{0, 9 * time.Millisecond, 31 * time.Second, -time.Second} {
+		if err := checkDebounce(d); err == nil {
+			t.Errorf("checkDebounce(%s) = nil, want error", d)
+		}
+	}
+}
+
+func TestProfileCompileWritesProfile(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "main.omni")
+	if err := os.WriteFile(input, []byte("func main():int {\n    return 42\n}\n"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	for _, mode := range []string{"cpu", "mem"} {
+		t.Run(mode, func(t *testing.T) {
+			profile := filepath.Join(dir, mode+".pprof")
+			err := profileCompile(mode, profile, func() error {
+				return compiler.Compile(compiler.Config{InputPath: input, OutputPath: filepath.Join(dir, "main.mir"), Backend: "vm", Emit: "mir"})
+			})
+			if err != nil {
+				t.Fatalf("profileCompile: %v", err)
+			}
+			info, err := os.Stat(profile)
+			if err != nil {
+				t.Fatalf("stat profile: %v", err)
+			}
+			if info.Size() == 0 {
+				t.Fatalf("%s profile is empty", mode)
+			}
+		})
+	}
+}
+
+func TestRunReportsWarningsOnCacheHit(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "main.omni")
+	if err := os.WriteFile(input, []byte("func main():int {\n    let x:int = 1\n    return 0\n}\n"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	buildCache, err := cache.Open(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatalf("open cache: %v", err)
+	}
+	checks := checker.Options{WarnUnusedVars: true}
+
+	build := func() ([]string, bool) {
+		t.Helper()
+		var deps, warnings []string
+		_, cached, err := run([]string{input}, filepath.Join(dir, "main.mir"), "vm", "O0", "mir", "", "", "", false, false, false, false, false, checks, 1, 0, target.Host(), buildCache, &deps, &warnings)
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		return warnings, cached
+	}
+	first, cached := build()
+	if cached {
+		t.Fatal("first build should not come from the cache")
+	}
+	if len(first) != 1 || !strings.Contains(first[0], `variable "x" is declared but never used`) {
+		t.Fatalf("first build warnings = %q, want the unused variable", first)
+	}
+	second, cached := build()
+	if !cached {
+		t.Fatal("second build should come from the cache")
+	}
+	if !reflect.DeepEqual(second, first) {
+		t.Errorf("cached build warnings = %q, want %q", second, first)
+	}
+}
@@ -1,20 +1,31 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/omni-lang/omni/tools/coverage"
 )
 
+// errNoStdFunctions is returned by check when the std library path holds no
+// .omni functions, usually because the path argument is wrong.
+var errNoStdFunctions = errors.New("no functions found in standard library path; check the path argument")
+
 func main() {
 	var (
 		analyzeCmd    = flag.NewFlagSet("analyze", flag.ExitOnError)
 		reportCmd     = flag.NewFlagSet("report", flag.ExitOnError)
 		checkCmd      = flag.NewFlagSet("check", flag.ExitOnError)
-		formatFlag    = reportCmd.String("format", "text", "output format (text|html)")
+		diffCmd       = flag.NewFlagSet("diff", flag.ExitOnError)
+		minCoverage   = analyzeCmd.Float64("min-coverage", 0, "warn about files whose function coverage is below this percentage")
+		formatFlag    = reportCmd.String("format", "text", "output format (text|html|lcov)")
 		thresholdFlag = checkCmd.Float64("threshold", 60.0, "coverage threshold percentage")
+		diffFormat    = diffCmd.String("format", "text", "output format (text|json)")
+		failOnRegress = diffCmd.Bool("fail-on-regression", true, "exit with status 1 if any file's coverage decreased")
 	)
 
 	if len(os.Args) < 2 {
@@ -27,19 +38,19 @@ func main() {
 	case "analyze":
 		analyzeCmd.Parse(os.Args[2:])
 		if analyzeCmd.NArg() < 2 {
-			fmt.Fprintf(os.Stderr, "Usage: omnicover analyze <coverage.json> <std-library-path>\n")
+			fmt.Fprintf(os.Stderr, "Usage: omnicover analyze [--min-coverage=N] <coverage.json> <std-library-path>\n")
 			os.Exit(1)
 		}
 		coveragePath := analyzeCmd.Arg(0)
 		stdPath := analyzeCmd.Arg(1)
-		if err := runAnalyze(coveragePath, stdPath); err != nil {
+		if err := runAnalyze(coveragePath, stdPath, *minCoverage); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 	case "report":
 		reportCmd.Parse(os.Args[2:])
 		if reportCmd.NArg() < 2 {
-			fmt.Fprintf(os.Stderr, "Usage: omnicover report <coverage.json> <std-library-path> [--format=text|html]\n")
+			fmt.Fprintf(os.Stderr, "Usage: omnicover report <coverage.json> <std-library-path> [--format=text|html|lcov]\n")
 			os.Exit(1)
 		}
 		coveragePath := reportCmd.Arg(0)
@@ -64,6 +75,20 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+	case "diff":
+		diffCmd.Parse(os.Args[2:])
+		if diffCmd.NArg() < 3 {
+			fmt.Fprintf(os.Stderr, "Usage: omnicover diff <baseline.json> <current.json> <std-library-path> [--format=text|json]\n")
+			os.Exit(1)
+		}
+		regressed, err := runDiff(os.Stdout, diffCmd.Arg(0), diffCmd.Arg(1), diffCmd.Arg(2), *diffFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if regressed && *failOnRegress {
+			os.Exit(1)
+		}
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", command)
 		showUsage()
@@ -76,15 +101,17 @@ func showUsage() {
 	fmt.Fprintf(os.Stderr, "USAGE:\n")
 	fmt.Fprintf(os.Stderr, "  omnicover <command> [options] <args>\n\n")
 	fmt.Fprintf(os.Stderr, "COMMANDS:\n")
-	fmt.Fprintf(os.Stderr, "  analyze <coverage.json> <std-library-path>\n")
-	fmt.Fprintf(os.Stderr, "        Analyze coverage data and print summary\n")
-	fmt.Fprintf(os.Stderr, "  report <coverage.json> <std-library-path> [--format=text|html] [output]\n")
+	fmt.Fprintf(os.Stderr, "  analyze [--min-coverage=N] <coverage.json> <std-library-path>\n")
+	fmt.Fprintf(os.Stderr, "        Analyze coverage data and print a summary and per-file table\n")
+	fmt.Fprintf(os.Stderr, "  report <coverage.json> <std-library-path> [--format=text|html|lcov] [output]\n")
 	fmt.Fprintf(os.Stderr, "        Generate coverage report\n")
 	fmt.Fprintf(os.Stderr, "  check <coverage.json> <std-library-path> [--threshold=60]\n")
-	fmt.Fprintf(os.Stderr, "        Check if coverage meets threshold\n\n")
+	fmt.Fprintf(os.Stderr, "        Check if coverage meets threshold\n")
+	fmt.Fprintf(os.Stderr, "  diff <baseline.json> <current.json> <std-library-path> [--format=text|json] [--fail-on-regression=true]\n")
+	fmt.Fprintf(os.Stderr, "        Compare two coverage runs and fail if any file's coverage decreased\n\n")
 }
 
-func runAnalyze(coveragePath, stdPath string) error {
+func runAnalyze(coveragePath, stdPath string, minCoverage float64) error {
 	// Parse coverage data
 	coverageData, err := coverage.ParseCoverageFile(coveragePath)
 	if err != nil {
@@ -102,18 +129,52 @@ func runAnalyze(coveragePath, stdPath string) error {
 
 	// Calculate statistics
 	stats := coverage.CalculateCoverage(matches)
+	stats.AddBranches(coverageData.Branches)
 
-	// Print summary
-	fmt.Printf("Coverage Analysis\n")
-	fmt.Printf("================\n\n")
-	fmt.Printf("Total Runtime-Wired Functions: %d\n", stats.TotalFunctions)
-	fmt.Printf("Covered Functions: %d\n", stats.CoveredFunctions)
-	fmt.Printf("Function Coverage: %.2f%%\n", stats.GetFunctionCoveragePercentage())
-	fmt.Printf("Line Coverage: %.2f%%\n", stats.GetLineCoveragePercentage())
-
+	printAnalysis(os.Stdout, stats, minCoverage)
 	return nil
 }
 
+// printAnalysis writes the summary and a per-file table, worst-covered file
+// first. Files below minCoverage percent are flagged with a warning line.
+func printAnalysis(w io.Writer, stats coverage.CoverageStats, minCoverage float64) {
+	fmt.Fprintf(w, "Coverage Analysis\n")
+	fmt.Fprintf(w, "================\n\n")
+	fmt.Fprintf(w, "Total Runtime-Wired Functions: %d\n", stats.TotalFunctions)
+	fmt.Fprintf(w, "Covered Functions: %d\n", stats.CoveredFunctions)
+	fmt.Fprintf(w, "Function Coverage: %.2f%%\n", stats.GetFunctionCoveragePercentage())
+	fmt.Fprintf(w, "Line Coverage: %.2f%%\n", stats.GetLineCoveragePercentage())
+	if stats.TotalBranches > 0 {
+		fmt.Fprintf(w, "Branch Coverage: %.2f%% (%d/%d branches)\n", stats.GetBranchCoveragePercentage(), stats.CoveredBranches, stats.TotalBranches)
+	}
+
+	files := stats.FilesByCoverage()
+	if len(files) == 0 {
+		return
+	}
+	width := len("File")
+	for _, file := range files {
+		if len(file) > width {
+			width = len(file)
+		}
+	}
+	fmt.Fprintf(w, "\n%-*s  %9s  %8s\n", width, "File", "Functions", "Coverage")
+	for _, file := range files {
+		fc := stats.ByFile[file]
+		fmt.Fprintf(w, "%-*s  %9s  %7.2f%%\n", width, file,
+			fmt.Sprintf("%d/%d", fc.CoveredFunctions, fc.TotalFunctions), fc.GetFunctionCoveragePercentage())
+	}
+
+	if minCoverage <= 0 {
+		return
+	}
+	for _, file := range files {
+		if pct := stats.ByFile[file].GetFunctionCoveragePercentage(); pct < minCoverage {
+			fmt.Fprintf(w, "warning: %s coverage %.2f%% is below %.2f%%\n", file, pct, minCoverage)
+		}
+	}
+}
+
 func runReport(coveragePath, stdPath, format, outputPath string) error {
 	// Parse coverage data
 	coverageData, err := coverage.ParseCoverageFile(coveragePath)
@@ -132,6 +193,7 @@ func runReport(coveragePath, stdPath, format, outputPath string) error {
 
 	// Calculate statistics
 	stats := coverage.CalculateCoverage(matches)
+	stats.AddBranches(coverageData.Branches)
 
 	// Generate report
 	switch format {
@@ -142,8 +204,10 @@ func runReport(coveragePath, stdPath, format, outputPath string) error {
 			outputPath = "coverage.html"
 		}
 		return coverage.GenerateHTMLReport(stats, outputPath)
+	case "lcov":
+		return coverage.GenerateLCOVReport(stats, outputPath)
 	default:
-		return fmt.Errorf("unsupported format: %s (use 'text' or 'html')", format)
+		return fmt.Errorf("unsupported format: %s (use 'text', 'html' or 'lcov')", format)
 	}
 }
 
@@ -159,6 +223,10 @@ func runCheck(coveragePath, stdPath string, threshold float64) error {
 	if err != nil {
 		return fmt.Errorf("parse std library: %w", err)
 	}
+	// With no functions the percentages are 0/0, which must not pass the check
+	if len(funcsByFile) == 0 {
+		return errNoStdFunctions
+	}
 
 	// Match coverage to functions
 	matches := coverage.MatchCoverageToFunctions(coverageData, funcsByFile)
@@ -176,3 +244,53 @@ func runCheck(coveragePath, stdPath string, threshold float64) error {
 
 	return nil
 }
+
+// runDiff compares the baseline and current coverage runs and writes the
+// per-file changes to w. It reports whether any file regressed.
+func runDiff(w io.Writer, baselinePath, currentPath, stdPath, format string) (bool, error) {
+	if format != "text" && format != "json" {
+		return false, fmt.Errorf("unsupported format: %s (use 'text' or 'json')", format)
+	}
+
+	baseline, err := coverage.ParseCoverageFile(baselinePath)
+	if err != nil {
+		return false, fmt.Errorf("parse baseline coverage file: %w", err)
+	}
+	current, err := coverage.ParseCoverageFile(currentPath)
+	if err != nil {
+		return false, fmt.Errorf("parse current coverage file: %w", err)
+	}
+
+	funcsByFile, err := coverage.ParseStdLibrary(stdPath)
+	if err != nil {
+		return false, fmt.Errorf("parse std library: %w", err)
+	}
+	if len(funcsByFile) == 0 {
+		return false, errNoStdFunctions
+	}
+
+	result := coverage.DiffCoverage(*baseline, *current, funcsByFile)
+	regressed := len(result.Regressions) > 0
+
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return regressed, enc.Encode(result)
+	}
+
+	fmt.Fprintf(w, "Coverage Diff\n")
+	fmt.Fprintf(w, "=============\n\n")
+	for _, delta := range result.Files {
+		fmt.Fprintf(w, "%s: %.2f%% -> %.2f%% (%+d functions, %+d lines)\n",
+			delta.File, delta.Before, delta.After, delta.CoveredFunctionsDelta, delta.CoveredLinesDelta)
+	}
+	if !regressed {
+		fmt.Fprintf(w, "\nNo coverage regressions\n")
+		return false, nil
+	}
+	fmt.Fprintf(w, "\n%d file(s) regressed:\n", len(result.Regressions))
+	for _, r := range result.Regressions {
+		fmt.Fprintf(w, "  %s: %.2f%% -> %.2f%%\n", r.File, r.Before, r.After)
+	}
+	return true, nil
+}
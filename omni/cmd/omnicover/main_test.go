@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/tools/coverage"
+)
+
+func writeCoverageFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "coverage.json")
+	if err := os.WriteFile(path, []byte(`{"entries": []}`), 0o644); err != nil {
+		t.Fatalf("write coverage file: %v", err)
+	}
+	return path
+}
+
+func TestRunCheckEmptyStdLibrary(t *testing.T) {
+	err := runCheck(writeCoverageFile(t), t.TempDir(), 60)
+	if !errors.Is(err, errNoStdFunctions) {
+		t.Fatalf("runCheck error = %v, want %v", err, errNoStdFunctions)
+	}
+}
+
+func TestCheckEmptyStdLibraryExitCode(t *testing.T) {
+	if os.Getenv("OMNICOVER_TEST_MAIN") == "1" {
+		os.Args = append([]string{"omnicover"}, strings.Fields(os.Getenv("OMNICOVER_TEST_ARGS"))...)
+		main()
+		os.Exit(0)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCheckEmptyStdLibraryExitCode$")
+	cmd.Env = append(os.Environ(),
+		"OMNICOVER_TEST_MAIN=1",
+		"OMNICOVER_TEST_ARGS=check "+writeCoverageFile(t)+" "+t.TempDir(),
+	)
+	output, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Fatalf("expected exit code 1, got %v (output: %s)", err, output)
+	}
+	if !strings.Contains(string(output), "Error: no functions found in standard library path; check the path argument") {
+		t.Errorf("unexpected output: %s", output)
+	}
+}
+
+func TestRunReportLCOV(t *testing.T) {
+	dir := t.TempDir()
+	stdDir := filepath.Join(dir, "std")
+	source := filepath.Join(stdDir, "io", "print.omni")
+	if err := os.MkdirAll(filepath.Dir(source), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	src := "func print(s:string):void {\n}\n\nfunc println(s:string):void {\n}\n"
+	if err := os.WriteFile(source, []byte(src), 0o644); err != nil {
+		t.Fatalf("write std file: %v", err)
+	}
+	coveragePath := filepath.Join(dir, "coverage.json")
+	data := `{"entries": [{"function": "std.io.println", "file": "", "line": 0, "count": 3}]}`
+	if err := os.WriteFile(coveragePath, []byte(data), 0o644); err != nil {
+		t.Fatalf("write coverage file: %v", err)
+	}
+
+	output := filepath.Join(dir, "lcov.info")
+	if err := runReport(coveragePath, stdDir, "lcov", output); err != nil {
+		t.Fatalf("runReport: %v", err)
+	}
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"TN:",
+		"SF:" + source,
+		"FN:1,std.io.print",
+		"FN:4,std.io.println",
+		"FNDA:0,std.io.print",
+		"FNDA:3,std.io.println",
+		"FNF:2",
+		"FNH:1",
+		"DA:1,0",
+		"DA:4,3",
+		"LF:2",
+		"LH:1",
+		"end_of_record",
+		"",
+	}, "\n")
+	if string(got) != want {
+		t.Fatalf("unexpected LCOV report:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrintAnalysisPerFile(t *testing.T) {
+	match := func(name, file string, covered bool) *coverage.CoverageMatch {
+		return &coverage.CoverageMatch{
+			Function: coverage.FunctionInfo{Name: name, File: file, LineNumber: 1, IsWired: true},
+			Covered:  covered,
+		}
+	}
+	stats := coverage.CalculateCoverage(map[string]*coverage.CoverageMatch{
+		"std.io.print":    match("std.io.print", "std/io.omni", true),
+		"std.io.println":  match("std.io.println", "std/io.omni", true),
+		"std.math.abs":    match("std.math.abs", "std/math.omni", true),
+		"std.math.max":    match("std.math.max", "std/math.omni", false),
+		"std.math.min":    match("std.math.min", "std/math.omni", false),
+		"std.string.trim": match("std.string.trim", "std/string.omni", false),
+	})
+
+	if got := stats.ByFile["std/math.omni"]; got != (coverage.FileCoverage{CoveredFunctions: 1, TotalFunctions: 3, CoveredLines: 1, TotalLines: 3}) {
+		t.Fatalf("ByFile[std/math.omni] = %+v", got)
+	}
+
+	var out bytes.Buffer
+	printAnalysis(&out, stats, 50)
+	text := out.String()
+
+	// Worst-covered files come first.
+	str, math, io := strings.Index(text, "std/string.omni"), strings.Index(text, "std/math.omni"), strings.Index(text, "std/io.omni")
+	if str < 0 || math < 0 || io < 0 || !(str < math && math < io) {
+		t.Fatalf("files not sorted by coverage:\n%s", text)
+	}
+	if !strings.Contains(text, "warning: std/math.omni coverage 33.33% is below 50.00%") ||
+		!strings.Contains(text, "warning: std/string.omni coverage 0.00% is below 50.00%") {
+		t.Fatalf("missing threshold warnings:\n%s", text)
+	}
+	if strings.Contains(text, "warning: std/io.omni") {
+		t.Fatalf("fully covered file flagged:\n%s", text)
+	}
+}
+
+func TestRunDiffReportsRegression(t *testing.T) {
+	dir := t.TempDir()
+	stdDir := filepath.Join(dir, "std")
+	source := filepath.Join(stdDir, "io", "print.omni")
+	if err := os.MkdirAll(filepath.Dir(source), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(source, []byte("func print(s:string):void {\n}\n"), 0o644); err != nil {
+		t.Fatalf("write std file: %v", err)
+	}
+	baseline := filepath.Join(dir, "baseline.json")
+	current := filepath.Join(dir, "current.json")
+	if err := os.WriteFile(baseline, []byte(`{"entries": [{"function": "std.io.print", "count": 2}]}`), 0o644); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+	if err := os.WriteFile(current, []byte(`{"entries": []}`), 0o644); err != nil {
+		t.Fatalf("write current: %v", err)
+	}
+
+	var out bytes.Buffer
+	regressed, err := runDiff(&out, baseline, current, stdDir, "json")
+	if err != nil {
+		t.Fatalf("runDiff: %v", err)
+	}
+	if !regressed {
+		t.Fatalf("expected a regression, output: %s", out.String())
+	}
+	var result struct {
+		Regressions []struct {
+			File   string  `json:"file"`
+			Before float64 `json:"before"`
+			After  float64 `json:"after"`
+		} `json:"regressions"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(result.Regressions) != 1 || result.Regressions[0].File != source || result.Regressions[0].Before != 100 || result.Regressions[0].After != 0 {
+		t.Fatalf("unexpected regressions: %+v", result.Regressions)
+	}
+
+	if regressed, err := runDiff(&out, baseline, baseline, stdDir, "text"); err != nil || regressed {
+		t.Fatalf("identical runs: regressed=%v err=%v", regressed, err)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/omni-lang/omni/internal/ast"
+	"github.com/omni-lang/omni/internal/parser"
+)
+
+func main() {
+	var (
+		write = flag.Bool("w", false, "write the formatted source back to each file instead of printing it")
+		check = flag.Bool("check", false, "list files that are not formatted and exit with status 1 if there are any")
+		help  = flag.Bool("help", false, "show help and exit")
+	)
+	flag.Usage = showUsage
+	flag.Parse()
+
+	if *help {
+		showUsage()
+		return
+	}
+	if flag.NArg() == 0 {
+		showUsage()
+		os.Exit(2)
+	}
+
+	files, err := collectFiles(flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	unformatted := 0
+	for _, path := range files {
+		src, formatted, err := formatFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		switch {
+		case *check:
+			if formatted != src {
+				fmt.Println(path)
+				unformatted++
+			}
+		case *write:
+			if formatted == src {
+				continue
+			}
+			if err := os.WriteFile(path, []byte(formatted), 0o644); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: write %s: %v\n", path, err)
+				os.Exit(2)
+			}
+		default:
+			fmt.Print(formatted)
+		}
+	}
+	if unformatted > 0 {
+		os.Exit(1)
+	}
+}
+
+// formatFile reads the OmniLang source at path and returns it along with
+// its formatted form.
+func formatFile(path string) (string, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("read %s: %w", path, err)
+	}
+	src := string(data)
+	mod, err := parser.Parse(path, src)
+	if err != nil {
+		return "", "", err
+	}
+	return src, ast.Format(mod, src), nil
+}
+
+// collectFiles expands the directories among args to the .omni files they
+// contain. Files named directly are kept whatever their extension.
+func collectFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && filepath.Ext(path) == ".omni" {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func showUsage() {
+	fmt.Fprintf(os.Stderr, "OmniLang Formatter\n\n")
+	fmt.Fprintf(os.Stderr, "USAGE:\n")
+	fmt.Fprintf(os.Stderr, "  omnifmt [options] <file.omni|dir>...\n\n")
+	fmt.Fprintf(os.Stderr, "Formats OmniLang source: two-space indentation, spaces around binary\n")
+	fmt.Fprintf(os.Stderr, "operators, no trailing whitespace and a single trailing newline.\n")
+	fmt.Fprintf(os.Stderr, "Directories are searched for .omni files.\n\n")
+	fmt.Fprintf(os.Stderr, "OPTIONS:\n")
+	fmt.Fprintf(os.Stderr, "  -w          write the result back to each file instead of printing it\n")
+	fmt.Fprintf(os.Stderr, "  --check     list files that are not formatted; exit with status 1 if any\n")
+	fmt.Fprintf(os.Stderr, "  --help      show this help\n\n")
+	fmt.Fprintf(os.Stderr, "EXAMPLES:\n")
+	fmt.Fprintf(os.Stderr, "  omnifmt main.omni          print main.omni formatted\n")
+	fmt.Fprintf(os.Stderr, "  omnifmt -w src/           format every .omni file under src in place\n")
+	fmt.Fprintf(os.Stderr, "  omnifmt --check src/       fail if any file under src is not formatted\n")
+}
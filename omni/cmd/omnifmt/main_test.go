@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runMain runs main in a child process with args and returns its output and
+// exit code.
+func runMain(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMainProcess$")
+	cmd.Env = append(os.Environ(), "OMNIFMT_TEST_MAIN=1", "OMNIFMT_TEST_ARGS="+strings.Join(args, "\n"))
+	output, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return string(output), exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatalf("run omnifmt: %v", err)
+	}
+	return string(output), 0
+}
+
+func TestMainProcess(t *testing.T) {
+	if os.Getenv("OMNIFMT_TEST_MAIN") != "1" {
+		t.Skip("helper process")
+	}
+	os.Args = append([]string{"omnifmt"}, strings.Split(os.Getenv("OMNIFMT_TEST_ARGS"), "\n")...)
+	main()
+	os.Exit(0)
+}
+
+func writeSource(t *testing.T, dir, name, src string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+const (
+	messySource     = "func main():int {\n    return 1+2   \n}"
+	formattedSource = "func main():int {\n  return 1 + 2\n}\n"
+)
+
+func TestCheckExitCode(t *testing.T) {
+	dir := t.TempDir()
+	writeSource(t, dir, "good.omni", formattedSource)
+	if output, code := runMain(t, "--check", dir); code != 0 {
+		t.Fatalf("check of formatted files exited %d: %s", code, output)
+	}
+
+	messy := writeSource(t, dir, "messy.omni", messySource)
+	output, code := runMain(t, "--check", dir)
+	if code != 1 {
+		t.Fatalf("check of unformatted file exited %d, want 1: %s", code, output)
+	}
+	if strings.TrimSpace(output) != messy {
+		t.Errorf("check listed %q, want %q", output, messy)
+	}
+}
+
+func TestWriteFormatsInPlace(t *testing.T) {
+	path := writeSource(t, t.TempDir(), "main.omni", messySource)
+	if output, code := runMain(t, "-w", path); code != 0 {
+		t.Fatalf("omnifmt -w exited %d: %s", code, output)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read formatted file: %v", err)
+	}
+	if string(data) != formattedSource {
+		t.Fatalf("formatted file = %q, want %q", data, formattedSource)
+	}
+}
+
+func TestFormatFileReportsParseErrors(t *testing.T) {
+	path := writeSource(t, t.TempDir(), "bad.omni", "func main( {\n")
+	if _, _, err := formatFile(path); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
@@ -3,11 +3,15 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/omni-lang/omni/internal/logging"
 	"github.com/omni-lang/omni/internal/packaging"
+	"github.com/omni-lang/omni/internal/target"
 )
 
 var (
@@ -16,6 +20,15 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "verify":
+			os.Exit(runVerify(os.Args[2:], os.Stdout))
+		case "extract":
+			os.Exit(runExtract(os.Args[2:]))
+		}
+	}
+
 	var (
 		output        = flag.String("o", "", "output package path")
 		packageType   = flag.String("type", "tar.gz", "package type (tar.gz|zip)")
@@ -30,12 +43,16 @@ func main() {
 		platformShort = flag.String("p", "", "alias for -platform")
 		arch          = flag.String("arch", runtime.GOARCH, "target architecture")
 		archShort     = flag.String("a", "", "alias for -arch")
+		targetFlag    = flag.String("target", "", "os/arch the packaged binaries were built for; sets -platform and -arch")
 		manifest      = flag.String("manifest", "", "write manifest JSON to file")
 		manifestShort = flag.String("m", "", "alias for -manifest")
 		checksum      = flag.Bool("checksum", false, "write SHA256 checksum alongside the package")
 		checksumPath  = flag.String("checksum-path", "", "override checksum file path")
 		checksumShort = flag.String("S", "", "alias for -checksum-path")
+		binDir        = flag.String("bin-dir", "", "directory containing the omnic and omnir binaries (default: bin)")
+		extraFiles    = flag.String("include", "", "comma-separated extra files or directories to add to the package")
 		dryRun        = flag.Bool("dry-run", false, "show package contents without creating an archive")
+		noColor       = flag.Bool("no-color", false, "disable colored log output")
 		listTypes     = flag.Bool("list-types", false, "list supported package types and exit")
 		listTypesAlt  = flag.Bool("T", false, "alias for -list-types")
 		help          = flag.Bool("help", false, "show help and exit")
@@ -44,6 +61,9 @@ func main() {
 
 	flag.Parse()
 
+	if *noColor {
+		os.Setenv("NO_COLOR", "1")
+	}
 	logger := logging.Logger()
 	logging.SetLevel(logging.LevelInfo)
 
@@ -71,6 +91,22 @@ func main() {
 	if *versionShort != "" {
 		*version = *versionShort
 	}
+	if *targetFlag != "" {
+		tgt, err := target.Parse(*targetFlag)
+		if err != nil {
+			logger.ErrorString(err.Error())
+			os.Exit(1)
+		}
+		// An explicit -platform or -arch still takes precedence.
+		explicit := map[string]bool{}
+		flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+		if !explicit["platform"] {
+			*platform = tgt.OS
+		}
+		if !explicit["arch"] {
+			*arch = tgt.Arch
+		}
+	}
 	if *platformShort != "" {
 		*platform = *platformShort
 	}
@@ -118,6 +154,8 @@ func main() {
 		ManifestPath: *manifest,
 		Checksum:     *checksum,
 		ChecksumPath: *checksumPath,
+		BinDir:       *binDir,
+		ExtraFiles:   splitList(*extraFiles),
 	}
 
 	action := "Creating package"
@@ -148,16 +186,132 @@ func main() {
 
 	if *dryRun {
 		logger.InfoFields("Dry run completed", logging.String("output", outputPath))
-	} else {
-		logger.InfoFields("Package created successfully",
+		return
+	}
+
+	if err := packaging.WriteChecksumsFile(filepath.Dir(outputPath)); err != nil {
+		logger.ErrorFields("failed to write checksums",
+			logging.Error("error", err),
 			logging.String("output", outputPath),
 		)
+		os.Exit(1)
+	}
+
+	logger.InfoFields("Package created successfully",
+		logging.String("output", outputPath),
+	)
+}
+
+// runVerify implements omnipkg verify, which checks the files of a package
+// against a release manifest, and returns the exit status: 0 when every
+// file matches, 1 otherwise.
+func runVerify(args []string, stdout io.Writer) int {
+	verifyCmd := flag.NewFlagSet("verify", flag.ContinueOnError)
+	strict := verifyCmd.Bool("strict", false, "fail if the archive holds files the manifest does not list")
+	verifyCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: omnipkg verify [--strict] <package.tar.gz|zip> [manifest.json]\n")
+	}
+	positional, err := parseInterleaved(verifyCmd, args)
+	if err != nil {
+		return 2
+	}
+	if len(positional) < 1 || len(positional) > 2 {
+		verifyCmd.Usage()
+		return 2
+	}
+	manifestPath := ""
+	if len(positional) == 2 {
+		manifestPath = positional[1]
+	}
+
+	logger := logging.Logger()
+	results, err := packaging.VerifyPackage(positional[0], manifestPath)
+	if err != nil {
+		logger.ErrorFields("failed to verify package",
+			logging.Error("error", err),
+			logging.String("package", positional[0]),
+		)
+		return 1
+	}
+
+	failed := 0
+	for _, result := range results {
+		status := "ok"
+		if !result.Passed(*strict) {
+			status = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(stdout, "%-4s  %-8s  %s\n", status, result.Status, result.Name)
+	}
+	fmt.Fprintf(stdout, "%d files checked, %d failed\n", len(results), failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runExtract implements omnipkg extract, which installs a package into a
+// directory, and returns the exit status.
+func runExtract(args []string) int {
+	extractCmd := flag.NewFlagSet("extract", flag.ContinueOnError)
+	dest := extractCmd.String("dest", ".", "directory to install the package into")
+	extractCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: omnipkg extract <package.tar.gz|zip> [--dest <dir>]\n")
+	}
+	positional, err := parseInterleaved(extractCmd, args)
+	if err != nil {
+		return 2
+	}
+	if len(positional) != 1 {
+		extractCmd.Usage()
+		return 2
+	}
+
+	logger := logging.Logger()
+	if err := packaging.ExtractPackage(positional[0], *dest); err != nil {
+		logger.ErrorFields("failed to extract package",
+			logging.Error("error", err),
+			logging.String("package", positional[0]),
+		)
+		return 1
+	}
+	logger.InfoFields("Package extracted",
+		logging.String("package", positional[0]),
+		logging.String("dest", *dest),
+	)
+	return 0
+}
+
+// parseInterleaved parses the flags of fs wherever they appear among args
+// and returns the remaining arguments in order.
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
 	}
 }
 
 func showUsage() {
-	fmt.Fprintf(os.Stderr, "Usage: omnipkg [options]\n\n")
+	fmt.Fprintf(os.Stderr, "Usage: omnipkg [options]\n")
+	fmt.Fprintf(os.Stderr, "       omnipkg verify [--strict] <package.tar.gz|zip> [manifest.json]\n")
+	fmt.Fprintf(os.Stderr, "       omnipkg extract <package.tar.gz|zip> [--dest <dir>]\n\n")
 	fmt.Fprintf(os.Stderr, "Create distribution packages for OmniLang\n\n")
+	fmt.Fprintf(os.Stderr, "COMMANDS:\n")
+	fmt.Fprintf(os.Stderr, "  verify <package> [manifest.json]\n")
+	fmt.Fprintf(os.Stderr, "        check the SHA-256 of every file in the package against a release\n")
+	fmt.Fprintf(os.Stderr, "        manifest, by default the release.json inside the package; with\n")
+	fmt.Fprintf(os.Stderr, "        --strict, files the manifest does not list also fail\n")
+	fmt.Fprintf(os.Stderr, "  extract <package> [--dest <dir>]\n")
+	fmt.Fprintf(os.Stderr, "        install the package into dir (default \".\") without its top-level\n")
+	fmt.Fprintf(os.Stderr, "        directory, checking files against its release.json if it has one\n\n")
 	fmt.Fprintf(os.Stderr, "OPTIONS:\n")
 	fmt.Fprintf(os.Stderr, "  -o string\n")
 	fmt.Fprintf(os.Stderr, "        output package path (default: auto-generated)\n")
@@ -173,6 +327,8 @@ func showUsage() {
 	fmt.Fprintf(os.Stderr, "        target platform (default current OS)\n")
 	fmt.Fprintf(os.Stderr, "  -arch, -a string\n")
 	fmt.Fprintf(os.Stderr, "        target architecture (default current arch)\n")
+	fmt.Fprintf(os.Stderr, "  -target string\n")
+	fmt.Fprintf(os.Stderr, "        os/arch the binaries were built for (sets -platform and -arch)\n")
 	fmt.Fprintf(os.Stderr, "  -list-types, -T\n")
 	fmt.Fprintf(os.Stderr, "        list supported package types and exit\n")
 	fmt.Fprintf(os.Stderr, "  -manifest, -m string\n")
@@ -181,8 +337,14 @@ func showUsage() {
 	fmt.Fprintf(os.Stderr, "        generate a SHA256 checksum file\n")
 	fmt.Fprintf(os.Stderr, "  -checksum-path, -S string\n")
 	fmt.Fprintf(os.Stderr, "        override checksum output file path\n")
+	fmt.Fprintf(os.Stderr, "  -bin-dir string\n")
+	fmt.Fprintf(os.Stderr, "        directory containing the omnic and omnir binaries (default \"bin\")\n")
+	fmt.Fprintf(os.Stderr, "  -include string\n")
+	fmt.Fprintf(os.Stderr, "        comma-separated extra files or directories to add to the package\n")
 	fmt.Fprintf(os.Stderr, "  -dry-run\n")
 	fmt.Fprintf(os.Stderr, "        show package contents without creating an archive\n")
+	fmt.Fprintf(os.Stderr, "  -no-color\n")
+	fmt.Fprintf(os.Stderr, "        disable colored log output (also set by NO_COLOR)\n")
 	fmt.Fprintf(os.Stderr, "  -help, -h\n")
 	fmt.Fprintf(os.Stderr, "        show help and exit\n\n")
 	fmt.Fprintf(os.Stderr, "EXAMPLES:\n")
@@ -191,4 +353,18 @@ func showUsage() {
 	fmt.Fprintf(os.Stderr, "  omnipkg -debug -src                       # Include debug and source\n")
 	fmt.Fprintf(os.Stderr, "  omnipkg -o my-package.tar.gz              # Custom output name\n")
 	fmt.Fprintf(os.Stderr, "  omnipkg -version 1.0.0 -platform linux    # Specific version and platform\n")
+	fmt.Fprintf(os.Stderr, "  omnipkg -target linux/arm64               # Package a cross-compiled build\n")
+	fmt.Fprintf(os.Stderr, "  omnipkg verify omni.tar.gz release.json   # Check a package against its manifest\n")
+	fmt.Fprintf(os.Stderr, "  omnipkg extract omni.zip --dest ~/omni    # Install a package\n")
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
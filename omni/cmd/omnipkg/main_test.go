@@ -1,7 +1,17 @@
 package main
 
 import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/omni-lang/omni/internal/packaging"
 )
 
 func TestVersionConstants(t *testing.T) {
@@ -76,3 +86,78 @@ func TestArchitectureDetection(t *testing.T) {
 	// This is a basic test to ensure the architecture detection logic is available
 	t.Log("Architecture detection logic is available")
 }
+
+// writeVerifyFixture writes a zip package holding files and, inside it, a
+// release.json that lists only the files named in listed.
+func writeVerifyFixture(t *testing.T, files map[string]string, listed ...string) string {
+	t.Helper()
+	manifest := packaging.ReleaseManifest{Version: "1.0.0"}
+	for _, name := range listed {
+		sum := sha256.Sum256([]byte(files[name]))
+		manifest.Artifacts = append(manifest.Artifacts, packaging.ReleaseArtifact{Name: name, Size: int64(len(files[name])), SHA256: hex.EncodeToString(sum[:])})
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("encode manifest: %v", err)
+	}
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	add := func(name, body string) {
+		w, err := zipWriter.Create("omni-lang-1.0.0/" + name)
+		if err != nil {
+			t.Fatalf("add %s: %v", name, err)
+		}
+		w.Write([]byte(body))
+	}
+	for name, body := range files {
+		add(name, body)
+	}
+	add(packaging.ReleaseManifestName, string(data))
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "omni.zip")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write package: %v", err)
+	}
+	return path
+}
+
+func TestRunVerify(t *testing.T) {
+	files := map[string]string{"bin/omnic": "compiler", "README.md": "readme"}
+	pkg := writeVerifyFixture(t, files, "bin/omnic")
+
+	var out bytes.Buffer
+	if code := runVerify([]string{pkg}, &out); code != 0 {
+		t.Fatalf("runVerify exit = %d, want 0; output:\n%s", code, out.String())
+	}
+	if !strings.Contains(out.String(), "2 files checked, 0 failed") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if code := runVerify([]string{pkg, "--strict"}, &out); code != 1 {
+		t.Fatalf("runVerify --strict exit = %d, want 1; output:\n%s", code, out.String())
+	}
+	if !strings.Contains(out.String(), "FAIL  unlisted  omni-lang-1.0.0/README.md") {
+		t.Errorf("unlisted file not reported:\n%s", out.String())
+	}
+}
+
+func TestRunExtract(t *testing.T) {
+	files := map[string]string{"bin/omnic": "compiler"}
+	pkg := writeVerifyFixture(t, files, "bin/omnic")
+	dest := t.TempDir()
+
+	if code := runExtract([]string{pkg, "--dest", dest}); code != 0 {
+		t.Fatalf("runExtract exit = %d, want 0", code)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "bin", "omnic"))
+	if err != nil || string(data) != "compiler" {
+		t.Fatalf("bin/omnic = %q, %v; want %q", data, err, "compiler")
+	}
+	if code := runExtract(nil); code != 2 {
+		t.Errorf("runExtract without a package exit = %d, want 2", code)
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -8,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"time"
@@ -15,6 +18,7 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/omni-lang/omni/internal/compiler"
 	"github.com/omni-lang/omni/internal/logging"
+	"github.com/omni-lang/omni/internal/moduleloader"
 	"github.com/omni-lang/omni/internal/runner"
 	"github.com/omni-lang/omni/internal/vm"
 )
@@ -24,29 +28,98 @@ var (
 	BuildTime = "unknown"
 )
 
+// dirListFlag collects the directories of a flag that may be repeated.
+type dirListFlag []string
+
+func (f *dirListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *dirListFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// benchFlag is the -bench flag. It may be given alone, to run every
+// benchmark, or with a pattern selecting the benchmarks to run.
+type benchFlag struct {
+	set     bool
+	pattern string
+}
+
+func (f *benchFlag) String() string {
+	return f.pattern
+}
+
+func (f *benchFlag) Set(v string) error {
+	f.set = true
+	if v != "true" {
+		f.pattern = v
+	}
+	return nil
+}
+
+// IsBoolFlag lets -bench be given without a value.
+func (f *benchFlag) IsBoolFlag() bool {
+	return true
+}
+
 func main() {
 	var (
 		version        = flag.Bool("version", false, "print version and exit")
 		versionAlt     = flag.Bool("v", false, "alias for -version")
 		verbose        = flag.Bool("verbose", false, "enable verbose output")
 		verboseAlt     = flag.Bool("V", false, "alias for -verbose")
+		noColor        = flag.Bool("no-color", false, "disable colored log output")
+		logLevel       = flag.String("log-level", "", "log level (debug|info|warn|error); overrides -verbose")
 		backend        = flag.String("backend", "vm", "execution backend (vm|c)")
 		backendAlt     = flag.String("b", "", "alias for -backend")
 		stats          = flag.Bool("stats", false, "print execution duration summary")
 		stdinSrc       = flag.Bool("stdin", false, "read OmniLang source from stdin")
 		watch          = flag.Bool("watch", false, "watch program file and rerun on changes")
 		watchShort     = flag.Bool("w", false, "alias for -watch")
+		timeout        = flag.Duration("timeout", 0, "stop the program if it runs longer than this (0 disables)")
+		jsonOutput     = flag.Bool("json", false, "with -watch, print one JSON event per line for each run")
+		debounce       = flag.Duration("debounce", 250*time.Millisecond, "how long -watch waits for changes to settle before rerunning")
 		testMode       = flag.Bool("test", false, "run using the built-in testing harness (vm backend only)")
+		runPattern     = flag.String("run", "", "with -test, run only the tests whose name matches this regular expression")
 		coverage       = flag.Bool("coverage", false, "enable coverage tracking for standard library functions")
-		coverageOutput = flag.String("coverage-output", "", "file path to write coverage data (JSON format)")
+		coverageOutput = flag.String("coverage-output", "", "file path to write coverage data (default coverage.json, or coverage.xml for cobertura)")
+		coverageFormat = flag.String("coverage-format", "json", "coverage data format: json or cobertura")
+		gcThreshold    = flag.Int("gc-threshold", vm.DefaultGCThreshold, "number of live heap objects that triggers a VM garbage collection")
+		overflowCheck  = flag.Bool("overflow-check", false, "stop the program when int arithmetic overflows 32 bits (vm backend only)")
+		profileVM      = flag.Bool("profile-vm", false, "report per-opcode instruction counts and times as JSON (vm backend only)")
+		profileVMOut   = flag.String("profile-vm-output", "", "file path to write the -profile-vm report (default stderr)")
+		importMapPath  = flag.String("import-map", "", "JSON file redirecting imports to replacement modules (vm backend only)")
+		sandbox        = flag.Bool("sandbox", false, "deny the program file, network and environment access (vm backend only)")
+		bench          benchFlag
+		allowRead      dirListFlag
+		allowWrite     dirListFlag
+		allowProcess   = flag.Bool("allow-process", false, "with -sandbox, allow running other programs through std.process")
 		help           = flag.Bool("help", false, "show help and exit")
 		showHelp       = flag.Bool("h", false, "show help and exit")
 	)
+	flag.Var(&bench, "bench", "run the bench_* functions matching the given pattern, or all of them, and report ns/op (vm backend only)")
+	flag.Var(&allowRead, "allow-read", "with -sandbox, allow reading files below this directory (repeatable)")
+	flag.Var(&allowWrite, "allow-write", "with -sandbox, allow writing files below this directory (repeatable)")
 	flag.Parse()
 
+	if *noColor {
+		os.Setenv("NO_COLOR", "1")
+	}
 	logger := logging.Logger()
 	logging.SetLevel(logging.LevelInfo)
-	if *verbose || *verboseAlt {
+	if *logLevel != "" {
+		level, err := logging.LevelFromString(*logLevel)
+		if err != nil {
+			logger.ErrorString(err.Error())
+			os.Exit(2)
+		}
+		if *verbose || *verboseAlt {
+			logger.WarnString("-log-level takes precedence over -verbose")
+		}
+		logging.SetLevel(level)
+	} else if *verbose || *verboseAlt {
 		logging.SetLevel(logging.LevelDebug)
 	}
 
@@ -116,12 +189,97 @@ func main() {
 		defer cleanup()
 	}
 
+	if *timeout < 0 {
+		logger.ErrorString(fmt.Sprintf("--timeout must not be negative, got %s", *timeout))
+		os.Exit(2)
+	}
+
+	var importMap moduleloader.ImportMap
+	if *importMapPath != "" {
+		if *backend != "vm" {
+			logger.ErrorString("--import-map currently supports only the vm backend")
+			os.Exit(2)
+		}
+		m, err := moduleloader.LoadImportMap(*importMapPath)
+		if err != nil {
+			logger.ErrorString(err.Error())
+			os.Exit(2)
+		}
+		importMap = m
+	}
+
+	var policy *vm.SandboxPolicy
+	if (len(allowRead) > 0 || len(allowWrite) > 0 || *allowProcess) && !*sandbox {
+		logger.ErrorString("--allow-read, --allow-write and --allow-process require --sandbox")
+		os.Exit(2)
+	}
+	if *sandbox {
+		if *backend != "vm" {
+			logger.ErrorString("--sandbox supports only the vm backend")
+			os.Exit(2)
+		}
+		policy = &vm.SandboxPolicy{AllowRead: allowRead, AllowWrite: allowWrite, AllowProcess: *allowProcess}
+	}
+
+	if *coverageFormat != "json" && *coverageFormat != "cobertura" {
+		logger.ErrorString(fmt.Sprintf("unknown --coverage-format %q (want json or cobertura)", *coverageFormat))
+		os.Exit(2)
+	}
+
 	// Enable coverage tracking if requested
 	if *coverage {
 		vm.SetCoverageEnabled(true)
 		vm.ResetCoverage()
 	}
 
+	if *gcThreshold < 1 {
+		logger.ErrorString(fmt.Sprintf("--gc-threshold must be at least 1, got %d", *gcThreshold))
+		os.Exit(2)
+	}
+	vm.SetGCThreshold(*gcThreshold)
+
+	if *overflowCheck && *backend != "vm" {
+		logger.ErrorString("--overflow-check supports only the vm backend")
+		os.Exit(2)
+	}
+
+	if *profileVMOut != "" && !*profileVM {
+		logger.ErrorString("--profile-vm-output requires --profile-vm")
+		os.Exit(2)
+	}
+	if *profileVM {
+		if *backend != "vm" {
+			logger.ErrorString("--profile-vm supports only the vm backend")
+			os.Exit(2)
+		}
+		if *watch || *watchShort {
+			logger.ErrorString("--profile-vm cannot be combined with --watch")
+			os.Exit(2)
+		}
+		vm.SetProfilingEnabled(true)
+	}
+
+	if bench.set {
+		if *testMode || *watch || *watchShort {
+			logger.ErrorString("--bench cannot be combined with --test or --watch")
+			os.Exit(2)
+		}
+		if *backend != "vm" {
+			logger.ErrorString("--bench supports only the vm backend")
+			os.Exit(2)
+		}
+		os.Exit(runBenchmarks(program, bench.pattern, *verbose || *verboseAlt))
+	}
+
+	if *runPattern != "" && !*testMode {
+		logger.ErrorString("--run requires --test")
+		os.Exit(2)
+	}
+	if _, err := regexp.Compile(*runPattern); err != nil {
+		logger.ErrorString(fmt.Sprintf("invalid --run pattern: %v", err))
+		os.Exit(2)
+	}
+
 	if *testMode {
 		if *watch || *watchShort {
 			logger.ErrorString("--test cannot be combined with --watch")
@@ -135,31 +293,87 @@ func main() {
 			logger.ErrorString("--test mode does not support forwarding program arguments")
 			os.Exit(2)
 		}
-		code := runTests(program, *verbose || *verboseAlt, *stats, *coverage, *coverageOutput)
+		ctx, cancel := runContext(*timeout)
+		code := runTests(ctx, program, *runPattern, *verbose || *verboseAlt, *stats, *coverage, *coverageOutput, *coverageFormat, importMap, policy, *overflowCheck)
+		cancel()
+		if *profileVM {
+			writeVMProfile(*profileVMOut)
+		}
 		if code != 0 {
 			logger.ErrorString(fmt.Sprintf("%d test(s) failed", code))
 		}
 		os.Exit(code)
 	}
 
+	if *jsonOutput && !*watch {
+		logger.ErrorString("--json is only supported together with --watch")
+		os.Exit(2)
+	}
+
 	if *watch || *watchShort {
 		if *stdinSrc {
 			logger.ErrorString("watch mode is not supported with --stdin")
 			os.Exit(2)
 		}
-		if err := watchAndRun(program, programArgs, *backend, *verbose || *verboseAlt, *stats, *coverage, *coverageOutput); err != nil {
+		if err := watchAndRun(program, programArgs, *backend, *verbose || *verboseAlt, *stats, *coverage, *coverageOutput, *coverageFormat, importMap, policy, *overflowCheck, *timeout, *debounce, *jsonOutput); err != nil {
 			logger.ErrorString(err.Error())
 			os.Exit(1)
 		}
 		return
 	}
 
-	if err := runProgram(program, programArgs, *backend, *verbose || *verboseAlt, *stats, *coverage, *coverageOutput); err != nil {
-		logger.ErrorString(err.Error())
+	ctx, cancel := runContext(*timeout)
+	defer cancel()
+	err := runProgram(ctx, program, programArgs, *backend, *verbose || *verboseAlt, *stats, *coverage, *coverageOutput, *coverageFormat, importMap, policy, *overflowCheck)
+	if *profileVM {
+		writeVMProfile(*profileVMOut)
+	}
+	if err != nil {
+		reportRunError(err, *timeout)
 		os.Exit(1)
 	}
 }
 
+// writeVMProfile writes the -profile-vm report to path, or to stderr when
+// path is empty.
+func writeVMProfile(path string) {
+	report, err := vm.ExportProfile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to export VM profile: %v\n", err)
+		return
+	}
+	report = append(report, '\n')
+	if path == "" {
+		os.Stderr.Write(report)
+		return
+	}
+	if err := os.WriteFile(path, report, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write VM profile: %v\n", err)
+	}
+}
+
+// reportRunError logs why a run failed, followed by the VM call stack when
+// the program failed at runtime.
+func reportRunError(err error, timeout time.Duration) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("program timed out after %s", timeout)
+	}
+	logging.Logger().ErrorString(err.Error())
+	var trace vm.StackTraceError
+	if errors.As(err, &trace) {
+		fmt.Fprint(os.Stderr, trace.StackTrace())
+	}
+}
+
+// runContext returns the context for one program run: it expires after
+// timeout, or never when timeout is zero.
+func runContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
 func showUsage() {
 	fmt.Fprintf(os.Stderr, "OmniLang Runner (omnir) %s\n", Version)
 	fmt.Fprintf(os.Stderr, "Built: %s\n\n", BuildTime)
@@ -168,22 +382,57 @@ func showUsage() {
 	fmt.Fprintf(os.Stderr, "OPTIONS:\n")
 	fmt.Fprintf(os.Stderr, "  -verbose, -V\n")
 	fmt.Fprintf(os.Stderr, "        enable verbose output\n")
+	fmt.Fprintf(os.Stderr, "  -log-level string\n")
+	fmt.Fprintf(os.Stderr, "        log level: debug, info, warn or error (overrides -verbose)\n")
+	fmt.Fprintf(os.Stderr, "  -no-color\n")
+	fmt.Fprintf(os.Stderr, "        disable colored log output (also set by NO_COLOR)\n")
 	fmt.Fprintf(os.Stderr, "  -version, -v\n")
 	fmt.Fprintf(os.Stderr, "        print version and exit\n")
 	fmt.Fprintf(os.Stderr, "  -backend, -b string\n")
 	fmt.Fprintf(os.Stderr, "        execution backend (vm|c) (default \"vm\")\n")
 	fmt.Fprintf(os.Stderr, "  -stats\n")
 	fmt.Fprintf(os.Stderr, "        print execution duration summary\n")
+	fmt.Fprintf(os.Stderr, "  -timeout duration\n")
+	fmt.Fprintf(os.Stderr, "        stop the program if it runs longer than this, e.g. 30s (default 0, no limit)\n")
 	fmt.Fprintf(os.Stderr, "  -test\n")
 	fmt.Fprintf(os.Stderr, "        execute using the OmniLang test harness (vm backend only)\n")
+	fmt.Fprintf(os.Stderr, "  -run regexp\n")
+	fmt.Fprintf(os.Stderr, "        with -test, run only the tests whose name matches regexp\n")
+	fmt.Fprintf(os.Stderr, "  -bench [=pattern]\n")
+	fmt.Fprintf(os.Stderr, "        run the bench_* functions whose name matches pattern, or all of them, and\n")
+	fmt.Fprintf(os.Stderr, "        report ns/op; each runs until it takes at least 1s (vm backend only)\n")
 	fmt.Fprintf(os.Stderr, "  -coverage\n")
 	fmt.Fprintf(os.Stderr, "        enable coverage tracking for standard library functions\n")
 	fmt.Fprintf(os.Stderr, "  -coverage-output string\n")
-	fmt.Fprintf(os.Stderr, "        file path to write coverage data (JSON format)\n")
+	fmt.Fprintf(os.Stderr, "        file path to write coverage data (default coverage.json, or coverage.xml for cobertura)\n")
+	fmt.Fprintf(os.Stderr, "  -coverage-format string\n")
+	fmt.Fprintf(os.Stderr, "        coverage data format: json or cobertura (default \"json\")\n")
+	fmt.Fprintf(os.Stderr, "  -gc-threshold int\n")
+	fmt.Fprintf(os.Stderr, "        number of live heap objects that triggers a VM garbage collection (default %d)\n", vm.DefaultGCThreshold)
+	fmt.Fprintf(os.Stderr, "  -overflow-check\n")
+	fmt.Fprintf(os.Stderr, "        stop with an error when int arithmetic overflows 32 bits (vm backend only)\n")
+	fmt.Fprintf(os.Stderr, "  -profile-vm\n")
+	fmt.Fprintf(os.Stderr, "        report per-opcode instruction counts and times as JSON (vm backend only)\n")
+	fmt.Fprintf(os.Stderr, "  -profile-vm-output string\n")
+	fmt.Fprintf(os.Stderr, "        file path to write the -profile-vm report (default stderr)\n")
+	fmt.Fprintf(os.Stderr, "  -import-map string\n")
+	fmt.Fprintf(os.Stderr, "        JSON file mapping import paths to replacement .omni files (vm backend only)\n")
+	fmt.Fprintf(os.Stderr, "  -sandbox\n")
+	fmt.Fprintf(os.Stderr, "        deny the program file, network and environment access (vm backend only)\n")
+	fmt.Fprintf(os.Stderr, "  -allow-read dir\n")
+	fmt.Fprintf(os.Stderr, "        with -sandbox, allow reading files below dir; may be repeated\n")
+	fmt.Fprintf(os.Stderr, "  -allow-write dir\n")
+	fmt.Fprintf(os.Stderr, "        with -sandbox, allow writing files below dir; may be repeated\n")
+	fmt.Fprintf(os.Stderr, "  -allow-process\n")
+	fmt.Fprintf(os.Stderr, "        with -sandbox, allow running other programs through std.process\n")
 	fmt.Fprintf(os.Stderr, "  -stdin\n")
 	fmt.Fprintf(os.Stderr, "        read source code from standard input\n")
 	fmt.Fprintf(os.Stderr, "  -watch, -w\n")
 	fmt.Fprintf(os.Stderr, "        watch program for changes and rerun automatically\n")
+	fmt.Fprintf(os.Stderr, "  -json\n")
+	fmt.Fprintf(os.Stderr, "        with -watch, print a JSON start and done event per run on stdout\n")
+	fmt.Fprintf(os.Stderr, "  -debounce duration\n")
+	fmt.Fprintf(os.Stderr, "        quiet period before -watch reruns, 10ms to 30s (default 250ms)\n")
 	fmt.Fprintf(os.Stderr, "  -help, -h\n")
 	fmt.Fprintf(os.Stderr, "        show help and exit\n\n")
 	fmt.Fprintf(os.Stderr, "EXAMPLES:\n")
@@ -191,11 +440,18 @@ func showUsage() {
 	fmt.Fprintf(os.Stderr, "  omnir -backend c hello.omni -- hi # Compile to native exe then run with args\n")
 	fmt.Fprintf(os.Stderr, "  cat hello.omni | omnir --stdin    # Run source from stdin\n")
 	fmt.Fprintf(os.Stderr, "  omnir --watch hello.omni          # Automatically rerun on file changes\n")
+	fmt.Fprintf(os.Stderr, "  omnir --timeout 30s script.omni   # Kill the program after 30 seconds\n")
+	fmt.Fprintf(os.Stderr, "  omnir --watch --json hello.omni   # Stream run events as JSON lines\n")
+	fmt.Fprintf(os.Stderr, "  omnir --import-map mocks.json app.omni # Run against stub modules\n")
+	fmt.Fprintf(os.Stderr, "  omnir --test --run parse tests.omni # Run only tests whose name contains parse\n")
+	fmt.Fprintf(os.Stderr, "  omnir --bench bench.omni          # Run every bench_* function\n")
+	fmt.Fprintf(os.Stderr, "  omnir --bench=fib bench.omni      # Run benchmarks matching fib\n")
+	fmt.Fprintf(os.Stderr, "  omnir --sandbox --allow-read data script.omni # Only read files below data/\n")
 }
 
-func runTests(program string, verbose bool, stats bool, coverageEnabled bool, coverageOutput string) int {
+func runTests(ctx context.Context, program, runPattern string, verbose bool, stats bool, coverageEnabled bool, coverageOutput, coverageFormat string, importMap moduleloader.ImportMap, sandbox *vm.SandboxPolicy, overflowCheck bool) int {
 	start := time.Now()
-	result, err := runner.Execute(program, nil, verbose)
+	result, err := runner.ExecuteContext(ctx, program, runner.Options{Verbose: verbose, ImportMap: importMap, Sandbox: sandbox, RunPattern: runPattern, OverflowCheck: overflowCheck})
 	code := 0
 	if err != nil {
 		var exitErr vm.ExitError
@@ -211,26 +467,14 @@ func runTests(program string, verbose bool, stats bool, coverageEnabled bool, co
 		}
 	}
 
+	if runPattern != "" && vm.MatchedTests() == 0 {
+		logging.Logger().WarnString(fmt.Sprintf("no tests match --run %q", runPattern))
+		code = 0
+	}
+
 	// Export coverage data if enabled
 	if coverageEnabled {
-		coverageData, err := vm.ExportCoverage()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "failed to export coverage: %v\n", err)
-		} else if coverageOutput != "" {
-			if err := os.WriteFile(coverageOutput, coverageData, 0644); err != nil {
-				fmt.Fprintf(os.Stderr, "failed to write coverage file: %v\n", err)
-			} else if verbose {
-				fmt.Fprintf(os.Stderr, "Coverage data written to %s\n", coverageOutput)
-			}
-		} else {
-			// Write to default location
-			defaultPath := "coverage.json"
-			if err := os.WriteFile(defaultPath, coverageData, 0644); err != nil {
-				fmt.Fprintf(os.Stderr, "failed to write coverage file: %v\n", err)
-			} else if verbose {
-				fmt.Fprintf(os.Stderr, "Coverage data written to %s\n", defaultPath)
-			}
-		}
+		writeCoverage(coverageOutput, coverageFormat, verbose)
 	}
 
 	if stats {
@@ -240,43 +484,109 @@ func runTests(program string, verbose bool, stats bool, coverageEnabled bool, co
 	return code
 }
 
-func runProgram(program string, args []string, backend string, verbose bool, stats bool, coverageEnabled bool, coverageOutput string) error {
+// runBenchmarks runs the benchmarks of program matching pattern and prints
+// one line per benchmark. It returns the process exit code.
+func runBenchmarks(program, pattern string, verbose bool) int {
+	results, err := runner.Benchmark(program, pattern, verbose)
+	for _, result := range results {
+		fmt.Printf("%-30s %10d %14.1f ns/op\n", result.Name, result.Iterations, result.NsPerOp)
+	}
+	if err != nil {
+		logging.Logger().ErrorString(fmt.Sprintf("benchmark failed: %v", err))
+		return 1
+	}
+	if len(results) == 0 {
+		logging.Logger().WarnString("no benchmarks to run")
+	}
+	return 0
+}
+
+func runProgram(ctx context.Context, program string, args []string, backend string, verbose bool, stats bool, coverageEnabled bool, coverageOutput, coverageFormat string, importMap moduleloader.ImportMap, sandbox *vm.SandboxPolicy, overflowCheck bool) error {
 	switch backend {
 	case "vm":
-		err := runner.Run(program, args, verbose)
-		// Export coverage data if enabled
+		err := runner.RunContext(ctx, program, runner.Options{Args: args, Verbose: verbose, ImportMap: importMap, Sandbox: sandbox, OverflowCheck: overflowCheck})
 		if coverageEnabled {
-			coverageData, exportErr := vm.ExportCoverage()
-			if exportErr != nil {
-				fmt.Fprintf(os.Stderr, "failed to export coverage: %v\n", exportErr)
-			} else if coverageOutput != "" {
-				if writeErr := os.WriteFile(coverageOutput, coverageData, 0644); writeErr != nil {
-					fmt.Fprintf(os.Stderr, "failed to write coverage file: %v\n", writeErr)
-				} else if verbose {
-					fmt.Fprintf(os.Stderr, "Coverage data written to %s\n", coverageOutput)
-				}
-			} else {
-				// Write to default location
-				defaultPath := "coverage.json"
-				if writeErr := os.WriteFile(defaultPath, coverageData, 0644); writeErr != nil {
-					fmt.Fprintf(os.Stderr, "failed to write coverage file: %v\n", writeErr)
-				} else if verbose {
-					fmt.Fprintf(os.Stderr, "Coverage data written to %s\n", defaultPath)
-				}
-			}
+			writeCoverage(coverageOutput, coverageFormat, verbose)
 		}
 		return err
 	case "c":
-		return runNative(program, args, verbose, stats)
+		return runNative(ctx, program, args, verbose, stats)
 	default:
 		return fmt.Errorf("unsupported backend: %s", backend)
 	}
 }
 
-func runNative(program string, args []string, verbose bool, stats bool) error {
+// writeCoverage exports the coverage collected so far in format ("json" or
+// "cobertura") to path, or to coverage.json or coverage.xml when path is
+// empty. Failures are reported but not fatal.
+func writeCoverage(path, format string, verbose bool) {
+	export, defaultPath := vm.ExportCoverage, "coverage.json"
+	if format == "cobertura" {
+		export, defaultPath = vm.ExportCoverageCobertura, "coverage.xml"
+	}
+	coverageData, err := export()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to export coverage: %v\n", err)
+		return
+	}
+	if path == "" {
+		path = defaultPath
+	}
+	if err := os.WriteFile(path, coverageData, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write coverage file: %v\n", err)
+	} else if verbose {
+		fmt.Fprintf(os.Stderr, "Coverage data written to %s\n", path)
+	}
+}
+
+// runProgramJSON runs program like runProgram, but reports the run as a
+// "start" and a "done" JSON event on enc instead of printing the VM result.
+// A program exit, including std.os.exit in the VM, is recorded in the done
+// event rather than terminating omnir, so watch mode keeps running.
+func runProgramJSON(ctx context.Context, enc *json.Encoder, program string, args []string, backend string, verbose bool, stats bool, coverageEnabled bool, coverageOutput, coverageFormat string, importMap moduleloader.ImportMap, sandbox *vm.SandboxPolicy, overflowCheck bool) {
+	_ = enc.Encode(map[string]any{"event": "start", "file": program})
+
+	start := time.Now()
+	code := 0
+	var err error
+	switch backend {
+	case "vm":
+		_, err = runner.ExecuteContext(ctx, program, runner.Options{Args: args, Verbose: verbose, ImportMap: importMap, Sandbox: sandbox, OverflowCheck: overflowCheck})
+		if coverageEnabled {
+			writeCoverage(coverageOutput, coverageFormat, verbose)
+		}
+	default:
+		err = runProgram(ctx, program, args, backend, verbose, stats, false, "", "", importMap, sandbox, overflowCheck)
+	}
+
+	var vmExit vm.ExitError
+	var procExit *exec.ExitError
+	switch {
+	case errors.As(err, &vmExit):
+		code = vmExit.Code
+	case errors.As(err, &procExit):
+		code = procExit.ExitCode()
+	case err != nil:
+		code = 1
+	}
+
+	done := map[string]any{
+		"event":       "done",
+		"status":      "ok",
+		"duration_ms": time.Since(start).Milliseconds(),
+		"exit_code":   code,
+	}
+	if err != nil {
+		done["status"] = "error"
+		done["error"] = err.Error()
+	}
+	_ = enc.Encode(done)
+}
+
+func runNative(ctx context.Context, program string, args []string, verbose bool, stats bool) error {
 	if filepath.Ext(program) != ".omni" {
 		// Assume it's an already-built binary; execute directly.
-		return execWithStats(program, args, stats)
+		return execWithStats(ctx, program, args, stats)
 	}
 
 	tmpDir, err := os.MkdirTemp("", "omnir-*")
@@ -313,11 +623,11 @@ func runNative(program string, args []string, verbose bool, stats bool) error {
 		return fmt.Errorf("compile program: %w", err)
 	}
 
-	return execWithStats(output, args, stats)
+	return execWithStats(ctx, output, args, stats)
 }
 
-func execWithStats(binary string, args []string, stats bool) error {
-	cmd := exec.Command(binary, args...)
+func execWithStats(ctx context.Context, binary string, args []string, stats bool) error {
+	cmd := exec.CommandContext(ctx, binary, args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
@@ -328,6 +638,10 @@ func execWithStats(binary string, args []string, stats bool) error {
 	}
 
 	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			// CommandContext killed the process; report why.
+			return fmt.Errorf("program stopped: %w", ctxErr)
+		}
 		return err
 	}
 
@@ -357,7 +671,10 @@ func writeStdinProgram() (string, func(), error) {
 	return path, cleanup, nil
 }
 
-func watchAndRun(program string, args []string, backend string, verbose bool, stats bool, coverageEnabled bool, coverageOutput string) error {
+func watchAndRun(program string, args []string, backend string, verbose bool, stats bool, coverageEnabled bool, coverageOutput, coverageFormat string, importMap moduleloader.ImportMap, sandbox *vm.SandboxPolicy, overflowCheck bool, timeout, delay time.Duration, jsonOutput bool) error {
+	if err := checkDebounce(delay); err != nil {
+		return err
+	}
 	abs, err := filepath.Abs(program)
 	if err != nil {
 		return fmt.Errorf("resolve program path: %w", err)
@@ -382,20 +699,39 @@ func watchAndRun(program string, args []string, backend string, verbose bool, st
 	logger := logging.Logger()
 	logger.InfoFields("Watching file for changes", logging.String("file", abs))
 
+	var enc *json.Encoder
+	if jsonOutput {
+		enc = json.NewEncoder(os.Stdout)
+	}
 	runOnce := func() {
-		if err := runProgram(program, args, backend, verbose, stats, coverageEnabled, coverageOutput); err != nil {
-			logger.ErrorString(err.Error())
+		ctx, cancel := runContext(timeout)
+		defer cancel()
+		if enc != nil {
+			runProgramJSON(ctx, enc, abs, args, backend, verbose, stats, coverageEnabled, coverageOutput, coverageFormat, importMap, sandbox, overflowCheck)
+			return
+		}
+		if err := runProgram(ctx, program, args, backend, verbose, stats, coverageEnabled, coverageOutput, coverageFormat, importMap, sandbox, overflowCheck); err != nil {
+			reportRunError(err, timeout)
 		}
 	}
 
 	runOnce()
+	watchLoop(watcher.Events, watcher.Errors, base, delay, runOnce)
+	return nil
+}
 
+// watchLoop calls run once changes to the file named base have settled for
+// delay. It returns when events is closed.
+func watchLoop(events <-chan fsnotify.Event, errs <-chan error, base string, delay time.Duration, run func()) {
 	debounce := time.NewTimer(time.Hour)
 	debounce.Stop()
 
 	for {
 		select {
-		case evt := <-watcher.Events:
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
 			if filepath.Base(evt.Name) != base {
 				continue
 			}
@@ -408,12 +744,26 @@ func watchAndRun(program string, args []string, backend string, verbose bool, st
 				default:
 				}
 			}
-			debounce.Reset(250 * time.Millisecond)
+			debounce.Reset(delay)
 		case <-debounce.C:
-			runOnce()
+			run()
 			debounce.Stop()
-		case err := <-watcher.Errors:
-			logger.ErrorFields("watch error", logging.Error("error", err))
+		case err := <-errs:
+			logging.Logger().ErrorFields("watch error", logging.Error("error", err))
 		}
 	}
 }
+
+// Bounds accepted by -debounce.
+const (
+	minDebounce = 10 * time.Millisecond
+	maxDebounce = 30 * time.Second
+)
+
+// checkDebounce reports an error if d is outside [minDebounce, maxDebounce].
+func checkDebounce(d time.Duration) error {
+	if d < minDebounce || d > maxDebounce {
+		return fmt.Errorf("-debounce must be between %s and %s, got %s", minDebounce, maxDebounce, d)
+	}
+	return nil
+}
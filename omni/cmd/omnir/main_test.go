@@ -1,7 +1,19 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
 )
 
 func TestVersionConstants(t *testing.T) {
@@ -81,3 +93,136 @@ func TestExitCodes(t *testing.T) {
 	// This is a basic test to ensure the exit code logic is available
 	t.Log("Exit code logic is available")
 }
+
+func TestCheckDebounce(t *testing.T) {
+	for _, d := range []time.Duration{10 * time.Millisecond, 250 * time.Millisecond, 30 * time.Second} {
+		if err := checkDebounce(d); err != nil {
+			t.Errorf("checkDebounce(%s) = %v, want nil", d, err)
+		}
+	}
+	for _, d := range []time.Duration{0, 9 * time.Millisecond, 31 * time.Second, -time.Second} {
+		if err := checkDebounce(d); err == nil {
+			t.Errorf("checkDebounce(%s) = nil, want error", d)
+		}
+	}
+}
+
+func TestBenchFlag(t *testing.T) {
+	cases := []struct {
+		args    []string
+		set     bool
+		pattern string
+	}{
+		{nil, false, ""},
+		{[]string{"-bench"}, true, ""},
+		{[]string{"-bench=fib"}, true, "fib"},
+	}
+	for _, tc := range cases {
+		fs := flag.NewFlagSet("omnir", flag.ContinueOnError)
+		var bench benchFlag
+		fs.Var(&bench, "bench", "")
+		if err := fs.Parse(append(tc.args, "prog.omni")); err != nil {
+			t.Fatalf("parse %v: %v", tc.args, err)
+		}
+		if bench.set != tc.set || bench.pattern != tc.pattern {
+			t.Errorf("%v: got set=%v pattern=%q, want set=%v pattern=%q", tc.args, bench.set, bench.pattern, tc.set, tc.pattern)
+		}
+		if fs.Arg(0) != "prog.omni" {
+			t.Errorf("%v: program = %q, want prog.omni", tc.args, fs.Arg(0))
+		}
+	}
+}
+
+func TestWatchJSONEvents(t *testing.T) {
+	dir := t.TempDir()
+	program := filepath.Join(dir, "app.omni")
+	if err := os.WriteFile(program, []byte("func main():int {\n    return 0\n}\n"), 0o644); err != nil {
+		t.Fatalf("write program: %v", err)
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	ran := make(chan struct{}, 1)
+	run := func() {
+		runProgramJSON(context.Background(), enc, program, nil, "vm", false, false, false, "", "json", nil, nil, false)
+		ran <- struct{}{}
+	}
+	run()
+	<-ran
+
+	events := make(chan fsnotify.Event)
+	errs := make(chan error)
+	done := make(chan struct{})
+	go func() {
+		watchLoop(events, errs, filepath.Base(program), 10*time.Millisecond, run)
+		close(done)
+	}()
+
+	// Break the program and report the change; the rerun must fail.
+	if err := os.WriteFile(program, []byte("func main():int {\n    return missing\n}\n"), 0o644); err != nil {
+		t.Fatalf("rewrite program: %v", err)
+	}
+	events <- fsnotify.Event{Name: filepath.Join(dir, "other.omni"), Op: fsnotify.Write}
+	events <- fsnotify.Event{Name: program, Op: fsnotify.Write}
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch loop did not rerun the program")
+	}
+	close(events)
+	<-done
+
+	var got []map[string]any
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var event map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, event)
+	}
+	if len(got) != 4 {
+		t.Fatalf("expected 4 events, got %d: %v", len(got), got)
+	}
+	for i, want := range []string{"start", "done", "start", "done"} {
+		if got[i]["event"] != want {
+			t.Fatalf("event %d = %v, want %s", i, got[i]["event"], want)
+		}
+	}
+	if got[0]["file"] != program {
+		t.Errorf("start file = %v, want %s", got[0]["file"], program)
+	}
+	if got[1]["status"] != "ok" || got[1]["error"] != nil {
+		t.Errorf("first run = %v, want ok", got[1])
+	}
+	if _, ok := got[1]["duration_ms"].(float64); !ok {
+		t.Errorf("done event missing duration_ms: %v", got[1])
+	}
+	if got[3]["status"] != "error" || got[3]["error"] == nil {
+		t.Errorf("second run = %v, want error", got[3])
+	}
+}
+
+func TestRunProgramTimeout(t *testing.T) {
+	program := filepath.Join(t.TempDir(), "loop.omni")
+	src := "func main():int {\n    var n:int = 0\n    while true {\n        n = n + 1\n    }\n    return n\n}\n"
+	if err := os.WriteFile(program, []byte(src), 0o644); err != nil {
+		t.Fatalf("write program: %v", err)
+	}
+
+	// The C backend compiles before running, so it gets more time.
+	timeouts := map[string]time.Duration{"vm": 200 * time.Millisecond}
+	if _, err := exec.LookPath("gcc"); err == nil {
+		timeouts["c"] = 3 * time.Second
+	}
+	for backend, timeout := range timeouts {
+		t.Run(backend, func(t *testing.T) {
+			ctx, cancel := runContext(timeout)
+			defer cancel()
+			err := runProgram(ctx, program, nil, backend, false, false, false, "", "json", nil, nil, false)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("expected a timeout, got %v", err)
+			}
+		})
+	}
+}
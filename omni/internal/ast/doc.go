@@ -0,0 +1,46 @@
+package ast
+
+import "strings"
+
+// docCommentPrefix marks a documentation comment. Ordinary // comments are
+// never treated as documentation.
+const docCommentPrefix = "///"
+
+// DocComment returns the documentation for a declaration that starts on the
+// given 1-based line: the run of /// comment lines directly above it, with
+// the markers (and one following space) removed and lines joined by "\n".
+func DocComment(lines []string, line int) string {
+	end := line - 1 // index of the declaration line
+	if end > len(lines) {
+		return ""
+	}
+	start := end
+	for start > 0 && strings.HasPrefix(strings.TrimSpace(lines[start-1]), docCommentPrefix) {
+		start--
+	}
+	if start == end {
+		return ""
+	}
+	doc := make([]string, 0, end-start)
+	for _, l := range lines[start:end] {
+		text := strings.TrimPrefix(strings.TrimSpace(l), docCommentPrefix)
+		doc = append(doc, strings.TrimPrefix(text, " "))
+	}
+	return strings.Join(doc, "\n")
+}
+
+// AttachDocComments fills in Doc for the top-level function and method
+// declarations in mod from the source lines it was parsed from.
+func AttachDocComments(mod *Module, lines []string) {
+	if mod == nil {
+		return
+	}
+	for _, decl := range mod.Decls {
+		switch fn := decl.(type) {
+		case *FuncDecl:
+			fn.Doc = DocComment(lines, fn.SpanInfo.Start.Line)
+		case *MethodDecl:
+			fn.Func.Doc = DocComment(lines, fn.SpanInfo.Start.Line)
+		}
+	}
+}
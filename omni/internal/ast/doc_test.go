@@ -0,0 +1,29 @@
+package ast
+
+import "testing"
+
+func TestDocComment(t *testing.T) {
+	lines := []string{
+		"// not documentation",
+		"/// first line",
+		"///second line",
+		"   /// indented",
+		"func documented() {}",
+		"",
+		"// plain comment",
+		"func plain() {}",
+	}
+
+	if got, want := DocComment(lines, 5), "first line\nsecond line\nindented"; got != want {
+		t.Errorf("DocComment = %q, want %q", got, want)
+	}
+	if got := DocComment(lines, 8); got != "" {
+		t.Errorf("expected no doc for // comments, got %q", got)
+	}
+	if got := DocComment(lines, 1); got != "" {
+		t.Errorf("expected no doc on first line, got %q", got)
+	}
+	if got := DocComment(lines, 100); got != "" {
+		t.Errorf("expected no doc past end of input, got %q", got)
+	}
+}
@@ -0,0 +1,317 @@
+package ast
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/lexer"
+)
+
+// formatIndent is one level of indentation in formatted source.
+const formatIndent = "  "
+
+// Format returns src, the source mod was parsed from, in canonical form.
+// The tokens and comments of src are kept in order and on their lines, while
+// the whitespace around them is normalized: every line is indented two
+// spaces per open bracket it sits in, binary and assignment operators have
+// one space on each side, a comma is followed by one, other tokens keep a
+// single space where src had any, runs of blank lines shrink to one, and the
+// file ends with a single newline. mod tells binary operators from the
+// unary ones and the angle brackets of generic types. Formatting formatted
+// source returns it unchanged. Source the lexer rejects is returned as is.
+func Format(mod *Module, src string) string {
+	tokens, err := lexer.LexAll("", src)
+	if err != nil {
+		return src
+	}
+	if n := len(tokens); n > 0 && tokens[n-1].Kind == lexer.TokenEOF {
+		tokens = tokens[:n-1]
+	}
+	items := formatItems(src, tokens, binaryOperators(mod, tokens))
+
+	var out strings.Builder
+	var stack []bool // open brackets, whether each indents the lines after it
+	level := 0
+	for i, line := range splitFormatLines(items) {
+		if i > 0 {
+			blank := line[0].newlines - 1
+			if blank > 1 {
+				blank = 1
+			}
+			for ; blank > 0; blank-- {
+				out.WriteString("\n")
+			}
+		}
+
+		// Closing brackets at the start of a line go back to the
+		// indentation of the line that opened them
+		j := 0
+		for ; j < len(line) && line[j].closes(); j++ {
+			if n := len(stack); n > 0 {
+				if stack[n-1] {
+					level--
+				}
+				stack = stack[:n-1]
+			}
+		}
+		base := len(stack)
+		out.WriteString(strings.Repeat(formatIndent, level))
+		for k, item := range line {
+			if k > 0 {
+				out.WriteString(formatSeparator(line[k-1], item))
+			}
+			out.WriteString(item.text)
+			if k < j {
+				continue
+			}
+			switch {
+			case item.opens():
+				stack = append(stack, false)
+			case item.closes():
+				if n := len(stack); n > 0 {
+					if stack[n-1] {
+						level--
+					}
+					stack = stack[:n-1]
+				}
+				if len(stack) < base {
+					base = len(stack)
+				}
+			}
+		}
+		// The outermost bracket the line leaves open indents the lines
+		// up to its closing one
+		if len(stack) > base {
+			stack[base] = true
+			level++
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+// formatItem is a token or comment of the source being formatted.
+type formatItem struct {
+	text    string
+	kind    lexer.Kind
+	comment bool
+	// newlines counts the line breaks between the item and the one before
+	// it, and space tells whether there was whitespace between them
+	newlines int
+	space    bool
+	// spaced marks binary and assignment operators
+	spaced bool
+}
+
+func (it formatItem) opens() bool {
+	return !it.comment && (it.kind == lexer.TokenLBrace || it.kind == lexer.TokenLParen || it.kind == lexer.TokenLBracket)
+}
+
+func (it formatItem) closes() bool {
+	return !it.comment && (it.kind == lexer.TokenRBrace || it.kind == lexer.TokenRParen || it.kind == lexer.TokenRBracket)
+}
+
+// formatSeparator returns the whitespace between two items on a line.
+func formatSeparator(prev, next formatItem) string {
+	switch {
+	case prev.comment || next.comment:
+		return " "
+	case next.kind == lexer.TokenComma || next.kind == lexer.TokenSemicolon:
+		return ""
+	case prev.spaced || next.spaced:
+		return " "
+	case prev.kind == lexer.TokenComma && !next.closes():
+		return " "
+	case next.space:
+		return " "
+	}
+	return ""
+}
+
+// splitFormatLines groups items into the lines they start on.
+func splitFormatLines(items []formatItem) [][]formatItem {
+	var lines [][]formatItem
+	for i, item := range items {
+		if i == 0 || item.newlines > 0 {
+			lines = append(lines, nil)
+		}
+		lines[len(lines)-1] = append(lines[len(lines)-1], item)
+	}
+	return lines
+}
+
+// formatItems interleaves the tokens of src with the comments between them.
+// binary holds the indexes of the tokens that are binary operators.
+func formatItems(src string, tokens []lexer.Token, binary map[int]bool) []formatItem {
+	offsets := newOffsetTable(src)
+	var items []formatItem
+	prev := 0
+	for i, tok := range tokens {
+		start, end := offsets.offset(tok.Span.Start), offsets.offset(tok.Span.End)
+		if start < prev || end < start {
+			// Tokens the lexer synthesized have no text of their own
+			continue
+		}
+		newlines, space := gapItems(src[prev:start], &items)
+		item := formatItem{text: src[start:end], kind: tok.Kind, newlines: newlines, space: space}
+		item.spaced = binary[i] || tok.Kind == lexer.TokenAssign || tok.Kind == lexer.TokenFatArrow
+		items = append(items, item)
+		prev = end
+	}
+	gapItems(src[prev:], &items)
+	return items
+}
+
+// gapItems appends the comments in gap, the text between two tokens, to
+// items, and returns the line breaks and whether there was whitespace
+// between the last of them and the next token.
+func gapItems(gap string, items *[]formatItem) (int, bool) {
+	newlines, space := 0, false
+	for i := 0; i < len(gap); {
+		switch {
+		case gap[i] == '\n':
+			newlines++
+			i++
+		case gap[i] == ' ' || gap[i] == '\t' || gap[i] == '\r':
+			space = true
+			i++
+		case strings.HasPrefix(gap[i:], "//"):
+			end := strings.IndexByte(gap[i:], '\n')
+			if end < 0 {
+				end = len(gap) - i
+			}
+			text := strings.TrimRight(gap[i:i+end], " \t\r")
+			*items = append(*items, formatItem{text: text, comment: true, newlines: newlines, space: space})
+			newlines, space = 0, false
+			i += end
+		case strings.HasPrefix(gap[i:], "/*"):
+			end := blockCommentEnd(gap[i:])
+			lines := strings.Split(gap[i:i+end], "\n")
+			for k := range lines {
+				lines[k] = strings.TrimRight(lines[k], " \t\r")
+			}
+			*items = append(*items, formatItem{text: strings.Join(lines, "\n"), comment: true, newlines: newlines, space: space})
+			newlines, space = 0, false
+			i += end
+		default:
+			i++
+		}
+	}
+	return newlines, space
+}
+
+// blockCommentEnd returns the length of the block comment text starts with,
+// which like in the lexer may nest.
+func blockCommentEnd(text string) int {
+	depth := 0
+	for i := 0; i+1 < len(text); i++ {
+		switch text[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(text)
+}
+
+// offsetTable converts lexer positions, whose columns count tabs up to the
+// next multiple of eight, to byte offsets in the source.
+type offsetTable struct {
+	src        string
+	lineStarts []int
+}
+
+func newOffsetTable(src string) offsetTable {
+	starts := []int{0}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return offsetTable{src: src, lineStarts: starts}
+}
+
+func (t offsetTable) offset(pos lexer.Position) int {
+	if pos.Line < 1 {
+		return 0
+	}
+	if pos.Line > len(t.lineStarts) {
+		return len(t.src)
+	}
+	offset, column := t.lineStarts[pos.Line-1], 1
+	for _, r := range t.src[offset:] {
+		if column >= pos.Column || r == '\n' {
+			break
+		}
+		if r == '\t' {
+			column = ((column-1)/8+1)*8 + 1
+		} else {
+			column++
+		}
+		offset += len(string(r))
+	}
+	return offset
+}
+
+// binaryOperators returns the indexes in tokens of the operators of the
+// binary expressions of mod.
+func binaryOperators(mod *Module, tokens []lexer.Token) map[int]bool {
+	ops := make(map[int]bool)
+	if mod == nil {
+		return ops
+	}
+	walkNodes(reflect.ValueOf(mod), func(node any) {
+		bin, ok := node.(*BinaryExpr)
+		if !ok || bin.Left == nil || bin.Right == nil {
+			return
+		}
+		after, before := bin.Left.Span().End, bin.Right.Span().Start
+		for i, tok := range tokens {
+			if positionBefore(tok.Span.Start, after) {
+				continue
+			}
+			if tok.Kind == lexer.TokenRParen {
+				continue
+			}
+			if tok.Lexeme == bin.Op && positionBefore(tok.Span.Start, before) {
+				ops[i] = true
+			}
+			return
+		}
+	})
+	return ops
+}
+
+func positionBefore(a, b lexer.Position) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+}
+
+// walkNodes calls visit with every node pointer reachable from v.
+func walkNodes(v reflect.Value, visit func(any)) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if v.Kind() == reflect.Pointer && v.Elem().Kind() == reflect.Struct && v.Elem().Type().PkgPath() == astPackage {
+			visit(v.Interface())
+		}
+		walkNodes(v.Elem(), visit)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				walkNodes(v.Field(i), visit)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkNodes(v.Index(i), visit)
+		}
+	}
+}
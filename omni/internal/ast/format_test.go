@@ -0,0 +1,77 @@
+package ast_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/ast"
+	"github.com/omni-lang/omni/internal/parser"
+)
+
+func format(t *testing.T, name, src string) string {
+	t.Helper()
+	mod, err := parser.Parse(name, src)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return ast.Format(mod, src)
+}
+
+// TestFormatGoldens formats each input of tests/goldens/fmt and compares it
+// byte for byte with the .fmt file next to it, then checks that formatting
+// the result again changes nothing.
+func TestFormatGoldens(t *testing.T) {
+	goldenDir := filepath.Join("..", "..", "tests", "goldens", "fmt")
+	inputs, err := filepath.Glob(filepath.Join(goldenDir, "*.omni"))
+	if err != nil {
+		t.Fatalf("glob goldens: %v", err)
+	}
+	if len(inputs) == 0 {
+		t.Fatalf("no format goldens found in %s", goldenDir)
+	}
+
+	for _, input := range inputs {
+		base := strings.TrimSuffix(filepath.Base(input), ".omni")
+		t.Run(base, func(t *testing.T) {
+			src, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatalf("read input: %v", err)
+			}
+			got := format(t, input, string(src))
+			golden := filepath.Join(goldenDir, base+".fmt")
+			if os.Getenv("UPDATE_GOLDENS") == "1" {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatalf("write golden: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden: %v", err)
+			}
+			if got != string(want) {
+				t.Fatalf("formatted %s differs\n--- expected ---\n%s\n--- actual ---\n%s", base, want, got)
+			}
+			if again := format(t, input, got); again != got {
+				t.Fatalf("formatting %s is not idempotent\n--- first ---\n%s\n--- second ---\n%s", base, got, again)
+			}
+		})
+	}
+}
+
+func TestFormatKeepsStringsAndComments(t *testing.T) {
+	src := "func main():int {\n\tlet s:string = \"a  +  b\"   /* x  y */\n\treturn 0 // done\n}"
+	want := "func main():int {\n  let s:string = \"a  +  b\" /* x  y */\n  return 0 // done\n}\n"
+	if got := format(t, "strings.omni", src); got != want {
+		t.Fatalf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatSpacesOnlyBinaryOperators(t *testing.T) {
+	src := "func f(a:int):int {\n    return -a*(a-1)\n}\n"
+	want := "func f(a:int):int {\n  return -a * (a - 1)\n}\n"
+	if got := format(t, "unary.omni", src); got != want {
+		t.Fatalf("Format() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,86 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// MarshalJSON encodes mod as JSON for tools that consume the syntax tree.
+// Every node becomes an object whose "node" member names its Go type, such
+// as "FuncDecl", next to its fields keyed in lowerCamelCase; SpanInfo fields
+// are keyed "span". Spans keep their start and end lines and columns. Nil
+// nodes, empty lists and strings and false flags are left out.
+func MarshalJSON(mod *Module) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(jsonValue(reflect.ValueOf(mod))); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+var astPackage = reflect.TypeOf(Module{}).PkgPath()
+
+// jsonValue converts v to the maps, slices and scalars MarshalJSON encodes.
+func jsonValue(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return jsonValue(v.Elem())
+	case reflect.Struct:
+		t := v.Type()
+		obj := make(map[string]any, t.NumField()+1)
+		if t.PkgPath() == astPackage {
+			obj["node"] = t.Name()
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() || omitJSONField(v.Field(i)) {
+				continue
+			}
+			obj[jsonKey(field.Name)] = jsonValue(v.Field(i))
+		}
+		return obj
+	case reflect.Slice, reflect.Array:
+		list := make([]any, v.Len())
+		for i := range list {
+			list[i] = jsonValue(v.Index(i))
+		}
+		return list
+	default:
+		return v.Interface()
+	}
+}
+
+// omitJSONField reports whether a field holding v is left out of the JSON.
+func omitJSONField(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	}
+	return false
+}
+
+// jsonKey returns the JSON key of the Go field name.
+func jsonKey(name string) string {
+	if name == "SpanInfo" {
+		return "span"
+	}
+	if strings.ToUpper(name) == name {
+		return strings.ToLower(name)
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,55 @@
+package ast
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/lexer"
+)
+
+func TestMarshalJSONKeepsNodesAndSpans(t *testing.T) {
+	span := func(line, start, end int) lexer.Span {
+		return lexer.Span{Start: lexer.Position{Line: line, Column: start}, End: lexer.Position{Line: line, Column: end}}
+	}
+	mod := &Module{Decls: []Decl{&FuncDecl{
+		SpanInfo: span(1, 1, 20),
+		Name:     "one",
+		Return:   &TypeExpr{SpanInfo: span(1, 11, 14), Name: "int"},
+		ExprBody: &LiteralExpr{SpanInfo: span(1, 18, 19), Kind: LiteralInt, Value: "1"},
+	}}}
+
+	data, err := MarshalJSON(mod)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+	var decoded struct {
+		Node  string `json:"node"`
+		Decls []struct {
+			Node     string     `json:"node"`
+			Name     string     `json:"name"`
+			Span     lexer.Span `json:"span"`
+			IsAsync  *bool      `json:"isAsync"`
+			ExprBody struct {
+				Node string     `json:"node"`
+				Kind string     `json:"kind"`
+				Span lexer.Span `json:"span"`
+			} `json:"exprBody"`
+		} `json:"decls"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, data)
+	}
+	if decoded.Node != "Module" || len(decoded.Decls) != 1 {
+		t.Fatalf("unexpected module: %s", data)
+	}
+	fn := decoded.Decls[0]
+	if fn.Node != "FuncDecl" || fn.Name != "one" || fn.Span != span(1, 1, 20) {
+		t.Errorf("function not preserved: %+v", fn)
+	}
+	if fn.ExprBody.Node != "LiteralExpr" || fn.ExprBody.Kind != "int" || fn.ExprBody.Span != span(1, 18, 19) {
+		t.Errorf("body not preserved: %+v", fn.ExprBody)
+	}
+	if fn.IsAsync != nil {
+		t.Error("false flags should be left out")
+	}
+}
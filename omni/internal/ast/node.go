@@ -1,6 +1,11 @@
 package ast
 
-import "github.com/omni-lang/omni/internal/lexer"
+import (
+	"math"
+	"strconv"
+
+	"github.com/omni-lang/omni/internal/lexer"
+)
 
 // Node represents any syntax tree node with an associated span.
 type Node interface {
@@ -101,6 +106,27 @@ type StructField struct {
 	Span lexer.Span
 }
 
+// InterfaceDecl defines an interface type: the methods that a struct must
+// have for its values to be used as values of the interface.
+type InterfaceDecl struct {
+	SpanInfo lexer.Span
+	Name     string
+	Methods  []InterfaceMethod
+}
+
+func (d *InterfaceDecl) Span() lexer.Span { return d.SpanInfo }
+func (d *InterfaceDecl) node()            {}
+func (d *InterfaceDecl) decl()            {}
+
+// InterfaceMethod is a method an interface requires, declared as
+// name(self, params):R. Params leaves out the self receiver.
+type InterfaceMethod struct {
+	Name   string
+	Params []Param
+	Return *TypeExpr
+	Span   lexer.Span
+}
+
 // EnumDecl defines an enum with variants.
 type EnumDecl struct {
 	SpanInfo lexer.Span
@@ -125,9 +151,15 @@ type FuncDecl struct {
 	TypeParams []TypeParam // Generic type parameters
 	Params     []Param
 	Return     *TypeExpr
-	Body       *BlockStmt
-	ExprBody   Expr // for fat arrow shorthand
-	IsAsync    bool // async function
+	// ReturnName names the result variable of `func f():T as name`, which
+	// the body assigns and a bare return hands back; it is empty otherwise
+	ReturnName     string
+	ReturnNameSpan lexer.Span
+	Body           *BlockStmt
+	ExprBody       Expr   // for fat arrow shorthand
+	IsAsync        bool   // async function
+	Doc            string // /// documentation comment, without markers
+	File           string // source file the function was parsed from
 }
 
 // TypeParam represents a generic type parameter.
@@ -140,11 +172,55 @@ func (d *FuncDecl) Span() lexer.Span { return d.SpanInfo }
 func (d *FuncDecl) node()            {}
 func (d *FuncDecl) decl()            {}
 
+// MethodDecl describes a method, `func (s T) name(params):R { ... }`, a
+// function of the struct type T that is called on a receiver as s.name().
+type MethodDecl struct {
+	SpanInfo lexer.Span
+	Receiver Param
+	// Func holds the method with its own name and parameters, without the
+	// receiver
+	Func *FuncDecl
+}
+
+func (d *MethodDecl) Span() lexer.Span { return d.SpanInfo }
+func (d *MethodDecl) node()            {}
+func (d *MethodDecl) decl()            {}
+
+// TypeName returns the name of the type the method belongs to.
+func (d *MethodDecl) TypeName() string {
+	if d.Receiver.Type == nil {
+		return ""
+	}
+	return d.Receiver.Type.Name
+}
+
+// FuncName returns the name of the function that implements the method,
+// the type name and the method name joined by a dot.
+func (d *MethodDecl) FuncName() string {
+	return d.TypeName() + "." + d.Func.Name
+}
+
+// Function returns the method as the function that implements it: named
+// FuncName, with the receiver as its first parameter.
+func (d *MethodDecl) Function() *FuncDecl {
+	fn := *d.Func
+	fn.Name = d.FuncName()
+	fn.Params = append([]Param{d.Receiver}, d.Func.Params...)
+	fn.SpanInfo = d.SpanInfo
+	return &fn
+}
+
 // Param represents a function parameter.
 type Param struct {
 	Name string
 	Type *TypeExpr
-	Span lexer.Span
+	// Variadic marks a last parameter written ...T, which takes any number
+	// of trailing arguments of type T as an array.
+	Variadic bool
+	// Default is the constant value written `= expr` after the type, which
+	// a call that omits the argument passes instead.
+	Default Expr
+	Span    lexer.Span
 }
 
 // Stmt is a statement.
@@ -280,6 +356,17 @@ func (s *ThrowStmt) Span() lexer.Span { return s.SpanInfo }
 func (s *ThrowStmt) node()            {}
 func (s *ThrowStmt) stmt()            {}
 
+// DeferStmt represents a defer statement, which runs Call when the
+// enclosing function returns. The arguments are evaluated at the defer.
+type DeferStmt struct {
+	SpanInfo lexer.Span
+	Call     Expr
+}
+
+func (s *DeferStmt) Span() lexer.Span { return s.SpanInfo }
+func (s *DeferStmt) node()            {}
+func (s *DeferStmt) stmt()            {}
+
 // TypeAliasDecl represents a type alias declaration: type UserID = int
 type TypeAliasDecl struct {
 	SpanInfo   lexer.Span
@@ -353,6 +440,8 @@ const (
 	LiteralNull   LiteralKind = "null"
 	LiteralHex    LiteralKind = "hex"
 	LiteralBinary LiteralKind = "binary"
+	LiteralInt64  LiteralKind = "int64"
+	LiteralUint   LiteralKind = "uint"
 )
 
 // LiteralExpr stores literal values as raw lexemes.
@@ -366,6 +455,29 @@ func (e *LiteralExpr) Span() lexer.Span { return e.SpanInfo }
 func (e *LiteralExpr) node()            {}
 func (e *LiteralExpr) expr()            {}
 
+// UnsignedType returns the type of a u-suffixed literal: uint, or uint64
+// when its value does not fit in 32 bits. It reports false when the value
+// does not fit in 64 bits either.
+func (e *LiteralExpr) UnsignedType() (string, bool) {
+	digits, base := e.Value, 10
+	if len(digits) > 2 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x', 'X':
+			digits, base = digits[2:], 16
+		case 'b', 'B':
+			digits, base = digits[2:], 2
+		}
+	}
+	v, err := strconv.ParseUint(digits, base, 64)
+	if err != nil {
+		return "", false
+	}
+	if v > math.MaxUint32 {
+		return "uint64", true
+	}
+	return "uint", true
+}
+
 // StringInterpolationExpr represents string interpolation: "Hello, ${name}!"
 type StringInterpolationExpr struct {
 	SpanInfo lexer.Span
@@ -450,6 +562,16 @@ func (e *ArrayLiteralExpr) Span() lexer.Span { return e.SpanInfo }
 func (e *ArrayLiteralExpr) node()            {}
 func (e *ArrayLiteralExpr) expr()            {}
 
+// TupleLiteralExpr represents a tuple of two or more values: (a, b)
+type TupleLiteralExpr struct {
+	SpanInfo lexer.Span
+	Elements []Expr
+}
+
+func (e *TupleLiteralExpr) Span() lexer.Span { return e.SpanInfo }
+func (e *TupleLiteralExpr) node()            {}
+func (e *TupleLiteralExpr) expr()            {}
+
 // MapEntry pairs key/value expressions.
 type MapEntry struct {
 	Key   Expr
@@ -559,3 +681,53 @@ type AwaitExpr struct {
 func (e *AwaitExpr) Span() lexer.Span { return e.SpanInfo }
 func (e *AwaitExpr) node()            {}
 func (e *AwaitExpr) expr()            {}
+
+// MatchExpr selects the body of the first case whose pattern matches the
+// subject: match expr { case pattern => expr, ... }
+type MatchExpr struct {
+	SpanInfo lexer.Span
+	Subject  Expr
+	Cases    []MatchCase
+}
+
+func (e *MatchExpr) Span() lexer.Span { return e.SpanInfo }
+func (e *MatchExpr) node()            {}
+func (e *MatchExpr) expr()            {}
+
+// MatchCase is one case of a match expression.
+type MatchCase struct {
+	Pattern MatchPattern
+	Body    Expr
+	Span    lexer.Span
+}
+
+// PatternKind identifies the form of a match pattern.
+type PatternKind string
+
+const (
+	PatternWildcard PatternKind = "wildcard"
+	PatternLiteral  PatternKind = "literal"
+	PatternEnum     PatternKind = "enum"
+	PatternStruct   PatternKind = "struct"
+)
+
+// MatchPattern is the pattern of a match case: the wildcard _, a literal
+// value, an enum variant Enum.Variant, or a struct destructuring
+// { field: binding } that binds fields of the subject.
+type MatchPattern struct {
+	Kind PatternKind
+	// Value is the literal of a literal pattern
+	Value Expr
+	// Enum and Variant name the variant of an enum pattern
+	Enum    string
+	Variant string
+	Fields  []FieldPattern
+	Span    lexer.Span
+}
+
+// FieldPattern binds the field of a struct pattern to a variable.
+type FieldPattern struct {
+	Field   string
+	Binding string
+	Span    lexer.Span
+}
@@ -114,7 +114,17 @@ func (p *printer) writeDecl(decl Decl) {
 				p.writeLine("Params [")
 				p.indent(func() {
 					for _, param := range d.Params {
+						if param.Variadic {
+							p.writeLine(param.Name + ": ..." + p.formatType(param.Type))
+							continue
+						}
 						p.writeLine(param.Name + ": " + p.formatType(param.Type))
+						if param.Default != nil {
+							p.indent(func() {
+								p.writeLine("Default")
+								p.indent(func() { p.writeExpr(param.Default) })
+							})
+						}
 					}
 				})
 				p.writeLine("]")
@@ -122,6 +132,9 @@ func (p *printer) writeDecl(decl Decl) {
 			if d.Return != nil {
 				p.writeLine("Return " + p.formatType(d.Return))
 			}
+			if d.ReturnName != "" {
+				p.writeLine("ReturnName " + d.ReturnName)
+			}
 			if d.ExprBody != nil {
 				p.writeLine("ExprBody")
 				p.indent(func() { p.writeExpr(d.ExprBody) })
@@ -131,6 +144,36 @@ func (p *printer) writeDecl(decl Decl) {
 			}
 		})
 		p.writeLine("}")
+	case *InterfaceDecl:
+		p.writeLine("InterfaceDecl {")
+		p.indent(func() {
+			p.writeLine("Name " + d.Name)
+			if len(d.Methods) > 0 {
+				p.writeLine("Methods [")
+				p.indent(func() {
+					for _, m := range d.Methods {
+						params := []string{"self"}
+						for _, param := range m.Params {
+							params = append(params, param.Name+": "+p.formatType(param.Type))
+						}
+						line := m.Name + "(" + strings.Join(params, ", ") + ")"
+						if m.Return != nil {
+							line += ": " + p.formatType(m.Return)
+						}
+						p.writeLine(line)
+					}
+				})
+				p.writeLine("]")
+			}
+		})
+		p.writeLine("}")
+	case *MethodDecl:
+		p.writeLine("MethodDecl {")
+		p.indent(func() {
+			p.writeLine("Receiver " + d.Receiver.Name + ": " + p.formatType(d.Receiver.Type))
+			p.writeDecl(d.Func)
+		})
+		p.writeLine("}")
 	case *TypeAliasDecl:
 		p.writeLine("TypeAliasDecl {")
 		p.indent(func() {
@@ -233,6 +276,29 @@ func (p *printer) writeStmt(stmt Stmt) {
 			}
 		})
 		p.writeLine("}")
+	case *DestructureStmt:
+		mut := "let"
+		if s.Mutable {
+			mut = "var"
+		}
+		p.writeLine("DestructureStmt " + mut + " {")
+		p.indent(func() {
+			if s.Tuple {
+				p.writeLine("Tuple (" + strings.Join(s.Names, ", ") + ")")
+			} else {
+				fields := make([]string, 0, len(s.Fields)+1)
+				for _, f := range s.Fields {
+					fields = append(fields, f.Field+": "+f.Binding)
+				}
+				if s.Rest {
+					fields = append(fields, "..")
+				}
+				p.writeLine("Struct { " + strings.Join(fields, ", ") + " }")
+			}
+			p.writeLine("Value")
+			p.indent(func() { p.writeExpr(s.Value) })
+		})
+		p.writeLine("}")
 	case *ShortVarDeclStmt:
 		p.writeLine("ShortVarDecl {")
 		p.indent(func() {
@@ -281,6 +347,9 @@ func (p *printer) writeStmt(stmt Stmt) {
 	case *ThrowStmt:
 		p.writeLine("ThrowStmt")
 		p.indent(func() { p.writeExpr(s.Expr) })
+	case *DeferStmt:
+		p.writeLine("DeferStmt")
+		p.indent(func() { p.writeExpr(s.Call) })
 	default:
 		p.writeLine("<unknown stmt>")
 	}
@@ -345,6 +414,14 @@ func (p *printer) writeExpr(expr Expr) {
 			}
 		})
 		p.writeLine("]")
+	case *TupleLiteralExpr:
+		p.writeLine("TupleLiteral (")
+		p.indent(func() {
+			for _, el := range e.Elements {
+				p.writeExpr(el)
+			}
+		})
+		p.writeLine(")")
 	case *MapLiteralExpr:
 		p.writeLine("MapLiteral {")
 		p.indent(func() {
@@ -380,11 +457,40 @@ func (p *printer) writeExpr(expr Expr) {
 	case *IncrementExpr:
 		p.writeLine("Increment " + e.Op)
 		p.indent(func() { p.writeExpr(e.Target) })
+	case *MatchExpr:
+		p.writeLine("Match")
+		p.indent(func() {
+			p.writeExpr(e.Subject)
+			for _, c := range e.Cases {
+				p.writeLine("Case " + p.formatPattern(c.Pattern))
+				p.indent(func() {
+					if c.Pattern.Kind == PatternLiteral {
+						p.writeExpr(c.Pattern.Value)
+					}
+					p.writeExpr(c.Body)
+				})
+			}
+		})
 	default:
 		p.writeLine("<unknown expr>")
 	}
 }
 
+func (p *printer) formatPattern(pat MatchPattern) string {
+	switch pat.Kind {
+	case PatternEnum:
+		return "enum " + pat.Enum + "." + pat.Variant
+	case PatternStruct:
+		fields := make([]string, 0, len(pat.Fields))
+		for _, f := range pat.Fields {
+			fields = append(fields, f.Field+": "+f.Binding)
+		}
+		return "struct { " + strings.Join(fields, ", ") + " }"
+	default:
+		return string(pat.Kind)
+	}
+}
+
 func (p *printer) formatType(t *TypeExpr) string {
 	if t == nil {
 		return "<nil>"
@@ -27,6 +27,27 @@ func (s *BindingStmt) Span() lexer.Span { return s.SpanInfo }
 func (s *BindingStmt) node()            {}
 func (s *BindingStmt) stmt()            {}
 
+// DestructureStmt models `let`/`var` declarations that bind the parts of a
+// value: the elements of a tuple, let (x, y) = pair, or the fields of a
+// struct, let { name, age } = person.
+type DestructureStmt struct {
+	SpanInfo lexer.Span
+	Mutable  bool
+	// Tuple selects a tuple pattern, whose Names bind the elements in
+	// order, with _ for those it skips; a struct pattern binds Fields
+	Tuple  bool
+	Names  []string
+	Fields []FieldPattern
+	// Rest is set when a struct pattern ends in .., leaving the fields it
+	// does not name unbound
+	Rest  bool
+	Value Expr
+}
+
+func (s *DestructureStmt) Span() lexer.Span { return s.SpanInfo }
+func (s *DestructureStmt) node()            {}
+func (s *DestructureStmt) stmt()            {}
+
 // AssignmentStmt wraps an assignment expression as a statement.
 type AssignmentStmt struct {
 	SpanInfo lexer.Span
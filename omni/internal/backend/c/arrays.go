@@ -0,0 +1,154 @@
+package cbackend
+
+import (
+	"strconv"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// ownedArrays returns the heap-allocated arrays that fn must free before it
+// returns: the arrays it creates with array.init or with the loops of
+// std.collections.filter and map_fn and of the ordered map keys, values and
+// entries, and those returned to it by calls to functions that return a
+// fresh array literal. An array that is used for anything other than
+// indexing, len() or those loops (returned, passed to a function, stored in a
+// struct, or merged by a phi) escapes and is not owned.
+func (g *CGenerator) ownedArrays(fn *mir.Function) map[mir.ValueID]bool {
+	owned := make(map[mir.ValueID]bool)
+	for _, block := range fn.Blocks {
+		for _, inst := range block.Instructions {
+			if inst.ID == mir.InvalidValue {
+				continue
+			}
+			if inst.Op == "array.init" && len(inst.Operands) > 0 {
+				owned[inst.ID] = true
+			}
+			if callee, ok := inst.CallTarget(); ok {
+				if _, fresh := g.returnedArrayLength(callee); fresh {
+					owned[inst.ID] = true
+				}
+			}
+			if collectionsArrayCall(&inst) || orderedMapArrayCall(&inst) {
+				owned[inst.ID] = true
+			}
+		}
+	}
+	if len(owned) == 0 {
+		return owned
+	}
+
+	for _, block := range fn.Blocks {
+		for _, inst := range block.Instructions {
+			callee, isCall := inst.CallTarget()
+			for i, op := range inst.Operands {
+				if op.Kind != mir.OperandValue || !owned[op.Value] {
+					continue
+				}
+				_, isLoop := collectionsCallback(&inst)
+				isRead := (inst.Op == "index" && i == 0) || (isCall && callee == "len" && i == 1) || (isLoop && i == 1)
+				if !isRead {
+					delete(owned, op.Value)
+				}
+			}
+		}
+		for _, op := range block.Terminator.Operands {
+			if (block.Terminator.Op == "ret" || block.Terminator.Op == "ret.named") && op.Kind == mir.OperandValue {
+				delete(owned, op.Value)
+			}
+		}
+	}
+	return owned
+}
+
+// returnedArrayLength reports the length of the arrays returned by the
+// module function name, when every return in it hands back an array literal
+// of the same length. Such a function always returns a fresh heap array that
+// the caller owns and can index with bounds checks.
+func (g *CGenerator) returnedArrayLength(name string) (int, bool) {
+	var fn *mir.Function
+	for _, candidate := range g.module.Functions {
+		if candidate.Name == name {
+			fn = candidate
+			break
+		}
+	}
+	if fn == nil {
+		return 0, false
+	}
+
+	inits := make(map[mir.ValueID]int)
+	for _, block := range fn.Blocks {
+		for _, inst := range block.Instructions {
+			if inst.Op == "array.init" && len(inst.Operands) > 0 {
+				inits[inst.ID] = len(inst.Operands)
+			}
+		}
+	}
+	length := -1
+	for _, block := range fn.Blocks {
+		term := block.Terminator
+		if term.Op != "ret" && term.Op != "ret.named" {
+			continue
+		}
+		if len(term.Operands) == 0 || term.Operands[0].Kind != mir.OperandValue {
+			return 0, false
+		}
+		n, ok := inits[term.Operands[0].Value]
+		if !ok || (length >= 0 && n != length) {
+			return 0, false
+		}
+		length = n
+	}
+	if length < 0 {
+		return 0, false
+	}
+	return length, true
+}
+
+// arrayLength returns the C expression for the length of the array op, a
+// constant when it is known at compile time and otherwise the variable of
+// arrayCounts that holds it.
+func (g *CGenerator) arrayLength(op mir.Operand) (string, bool) {
+	if op.Kind != mir.OperandValue {
+		return "", false
+	}
+	if length, ok := g.arrayLengths[op.Value]; ok {
+		return strconv.Itoa(length), true
+	}
+	count, ok := g.arrayCounts[op.Value]
+	return count, ok
+}
+
+// sortFunctions are the runtime functions that sort an array in place, by
+// the std.sort function they implement.
+var sortFunctions = map[string]string{
+	"std.sort.ints":            "omni_sort_ints",
+	"std.sort.strings":         "omni_sort_strings",
+	"std.sort.floats":          "omni_sort_floats",
+	"std.sort.reverse_ints":    "omni_sort_reverse_ints",
+	"std.sort.reverse_strings": "omni_sort_reverse_strings",
+}
+
+// countedArrayFunctions are the runtime functions that return an array of
+// strings and store its length through their last argument, by the std
+// function they implement.
+var countedArrayFunctions = map[string]string{
+	"std.string.split":   "omni_string_split",
+	"string.split":       "omni_string_split",
+	"std.regex.find_all": "omni_regex_find_all",
+	"std.regex.groups":   "omni_regex_groups",
+
+	"std.collections.trie.keys_with_prefix": "omni_trie_keys_with_prefix",
+}
+
+// countedArrayCall returns the runtime function of inst if it calls one of
+// countedArrayFunctions, whose result carries its length in a variable of
+// its own.
+func countedArrayCall(inst *mir.Instruction) (string, bool) {
+	callee, ok := inst.CallTarget()
+	if !ok {
+		return "", false
+	}
+	runtimeFunc, ok := countedArrayFunctions[callee]
+	return runtimeFunc, ok
+}
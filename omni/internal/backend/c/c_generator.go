@@ -2,6 +2,7 @@ package cbackend
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -21,6 +22,12 @@ type CGenerator struct {
 	variables map[mir.ValueID]string
 	// Track PHI variables that need special handling
 	phiVars map[mir.ValueID]bool
+	// PHI nodes by block, and the block being generated: a jump out of
+	// currentBlock assigns the phis of its target their incoming values
+	blockPhis    map[string][]mir.Instruction
+	currentBlock string
+	// Function being generated, for diagnostic locations
+	currentFunction string
 	// Track variables that are updated in loop contexts
 	mutableVars map[mir.ValueID]bool
 	// Track variables that are maps (by variable name - legacy)
@@ -29,23 +36,51 @@ type CGenerator struct {
 	mapTypes map[mir.ValueID]string
 	// Track array lengths by value ID (for runtime bounds checking and len())
 	arrayLengths map[mir.ValueID]int
+	// Arrays whose length is only known at run time, such as variadic
+	// parameters and the pieces of std.string.split, with the C variable
+	// holding their length
+	arrayCounts map[mir.ValueID]string
 	// Debug symbol tracking
 	sourceMap map[string]int // Maps source locations to line numbers
 	lineMap   map[int]string // Maps line numbers to source locations
 	// Track discovered value types to help with conversions
 	valueTypes map[mir.ValueID]string
-	// Track errors during code generation
-	errors []string
+	// Errors and warnings reported during code generation
+	diags []CodeGenDiag
 	// Track variables that hold heap-allocated strings (need to be freed)
 	stringsToFree map[mir.ValueID]bool
 	// Track promises that need to be freed
 	promisesToFree map[mir.ValueID]bool
+	// Track heap-allocated arrays that are freed before returning (arrays
+	// that are returned or otherwise escape are left to their new owner)
+	arrayAllocsToFree map[mir.ValueID]bool
 	// Track temporary string variables created in convertOperandToString
 	tempStringsToFree []string
+	// How many defer.push instructions of the function being generated
+	// have been generated, and the thunks that make their calls, which go
+	// in front of the function
+	deferPushed int
+	deferThunks strings.Builder
+	// Whether the function being generated has a try statement, and how
+	// many of its try.enter instructions have been generated
+	hasTry    bool
+	tryFrames int
+	// While loops written as C while statements, by header block, the
+	// statements being generated, innermost last, and the blocks done
+	whileLoops    map[string]*whileLoop
+	openLoops     []*whileLoop
+	emittedBlocks map[string]bool
 	// Track the value ID that is being returned (to exclude from cleanup)
 	returnedValueID mir.ValueID
+	// namedReturn is the C variable of the named return value of the
+	// function, which the caller owns; empty when it has none
+	namedReturn string
 	// Track which variables were declared at the top of the function
 	declaredVariables map[mir.ValueID]bool
+	// Number of functions to generate concurrently; 0 or 1 is serial
+	parallelism int
+	// divCheck tests divisors for zero even without debugInfo
+	divCheck bool
 }
 
 // NewCGenerator creates a new C code generator
@@ -64,9 +99,9 @@ func NewCGenerator(module *mir.Module) *CGenerator {
 		sourceMap:         make(map[string]int),
 		lineMap:           make(map[int]string),
 		valueTypes:        make(map[mir.ValueID]string),
-		errors:            []string{},
 		stringsToFree:     make(map[mir.ValueID]bool),
 		promisesToFree:    make(map[mir.ValueID]bool),
+		arrayAllocsToFree: make(map[mir.ValueID]bool),
 		tempStringsToFree: []string{},
 		returnedValueID:   mir.InvalidValue,
 		declaredVariables: make(map[mir.ValueID]bool),
@@ -89,9 +124,9 @@ func NewCGeneratorWithOptLevel(module *mir.Module, optLevel string) *CGenerator
 		sourceMap:         make(map[string]int),
 		lineMap:           make(map[int]string),
 		valueTypes:        make(map[mir.ValueID]string),
-		errors:            []string{},
 		stringsToFree:     make(map[mir.ValueID]bool),
 		promisesToFree:    make(map[mir.ValueID]bool),
+		arrayAllocsToFree: make(map[mir.ValueID]bool),
 		tempStringsToFree: []string{},
 		returnedValueID:   mir.InvalidValue,
 		declaredVariables: make(map[mir.ValueID]bool),
@@ -114,9 +149,9 @@ func NewCGeneratorWithDebug(module *mir.Module, optLevel string, debugInfo bool,
 		sourceMap:         make(map[string]int),
 		lineMap:           make(map[int]string),
 		valueTypes:        make(map[mir.ValueID]string),
-		errors:            []string{},
 		stringsToFree:     make(map[mir.ValueID]bool),
 		promisesToFree:    make(map[mir.ValueID]bool),
+		arrayAllocsToFree: make(map[mir.ValueID]bool),
 		tempStringsToFree: []string{},
 		returnedValueID:   mir.InvalidValue,
 		declaredVariables: make(map[mir.ValueID]bool),
@@ -152,20 +187,25 @@ func (g *CGenerator) generate() (string, error) {
 	g.writeStdLibFunctions()
 
 	// Generate function declarations first
+	g.writeInterfaceTypes()
+	g.writeGlobals()
 	g.writeFunctionDeclarations()
+	g.writeVTables()
 
-	// Then generate function definitions
-	for _, fn := range g.module.Functions {
-		if err := g.generateFunction(fn); err != nil {
-			return "", err
+	// Then generate function definitions. A failure is normally reported
+	// as an error diagnostic too, which carries its location.
+	if err := g.generateFunctions(); err != nil {
+		if diagErr := g.diagError(); diagErr != nil {
+			return "", diagErr
 		}
+		return "", err
 	}
 
 	g.writeMain()
 
 	// Check for errors collected during code generation
-	if len(g.errors) > 0 {
-		return "", fmt.Errorf("code generation errors:\n%s", strings.Join(g.errors, "\n"))
+	if err := g.diagError(); err != nil {
+		return "", err
 	}
 
 	// Apply optimizations
@@ -222,16 +262,36 @@ typedef struct {
     const char* function_name;
 } omni_debug_location_t;
 
+// Source location markers on function definitions; only clang keeps
+// annotate attributes, other compilers get the location from #line
+#if defined(__clang__)
+#define OMNI_DEBUG_LOC(loc) __attribute__((annotate(loc)))
+#else
+#define OMNI_DEBUG_LOC(loc)
+#endif
+
 // Global debug symbol table
 static omni_debug_location_t debug_symbols[] = {
 `)
-		// We'll populate this in generateFunction
-		g.output.WriteString(`};
+		g.writeDebugSymbols()
+		g.output.WriteString(`    { NULL, 0, 0, NULL }
+};
+
+// Look up the source location of a function by its Omni name
+omni_debug_location_t* omni_get_function_location(const char* function_name) {
+    for (omni_debug_location_t* loc = debug_symbols; loc->function_name != NULL; loc++) {
+        if (strcmp(loc->function_name, function_name) == 0) {
+            return loc;
+        }
+    }
+    return NULL;
+}
 
 // Function to get debug location by address (simplified)
 omni_debug_location_t* omni_get_debug_location(uintptr_t addr) {
-    // In a real implementation, this would use DWARF or similar
-    // For now, return a placeholder
+    // Addresses are resolved through the DWARF info the C compiler emits;
+    // this placeholder only serves programs that query it directly
+    (void)addr;
     static omni_debug_location_t default_location = {
         .filename = "unknown",
         .line = 0,
@@ -298,7 +358,7 @@ func (g *CGenerator) writeFunctionDeclarations() {
 						paramType = "char*" // Buffer parameter should be writable
 					}
 
-					g.output.WriteString(fmt.Sprintf("%s %s", paramType, param.Name))
+					g.output.WriteString(fmt.Sprintf("%s %s%s", paramType, param.Name, variadicCountParam(param)))
 				}
 			}
 			g.output.WriteString(");\n")
@@ -309,11 +369,13 @@ func (g *CGenerator) writeFunctionDeclarations() {
 
 // generateFunction generates C code for a single function
 func (g *CGenerator) generateFunction(fn *mir.Function) error {
+	g.currentFunction, g.currentBlock = fn.Name, ""
+	start := g.output.Len()
 	// Skip functions that are provided by the runtime
 	if g.isRuntimeProvidedFunction(fn.Name) {
 		// Verify that the function actually has a runtime implementation
 		if !g.hasRuntimeImplementation(fn.Name) {
-			g.errors = append(g.errors, fmt.Sprintf("ERROR: Function '%s' is marked as runtime-provided but has no runtime implementation. Remove it from isRuntimeProvidedFunction or implement it in the runtime.", fn.Name))
+			g.error("missing-runtime", fmt.Sprintf("function '%s' is marked as runtime-provided but has no runtime implementation. Remove it from isRuntimeProvidedFunction or implement it in the runtime.", fn.Name))
 		}
 		return nil
 	}
@@ -322,7 +384,7 @@ func (g *CGenerator) generateFunction(fn *mir.Function) error {
 	if g.isStdFunction(fn.Name) && !g.hasRuntimeImplementation(fn.Name) {
 		// This is a stdlib function without a runtime implementation
 		// It will use its stub body, which is likely wrong
-		g.errors = append(g.errors, fmt.Sprintf("WARNING: stdlib function '%s' is not implemented in the runtime. It will use a stub body that returns a default value. Consider implementing it or removing it from the stdlib.", fn.Name))
+		g.warn("stdlib-stub", fmt.Sprintf("stdlib function '%s' is not implemented in the runtime. It will use a stub body that returns a default value. Consider implementing it or removing it from the stdlib.", fn.Name))
 	}
 
 	// Add debug information if enabled
@@ -332,7 +394,7 @@ func (g *CGenerator) generateFunction(fn *mir.Function) error {
 
 		// Add source location information
 		if g.sourceFile != "" {
-			g.output.WriteString(fmt.Sprintf("#line 1 \"%s\"\n", g.sourceFile))
+			g.output.WriteString(fmt.Sprintf("#line %d \"%s\"\n", debugLine(fn), g.sourceFile))
 		}
 	}
 
@@ -354,6 +416,10 @@ func (g *CGenerator) generateFunction(fn *mir.Function) error {
 		returnType = "omni_promise_t*"
 	}
 
+	if g.debugInfo {
+		g.output.WriteString(fmt.Sprintf("OMNI_DEBUG_LOC(\"%s\") ", g.debugLocation(fn)))
+	}
+
 	// Handle function pointer return types
 	if strings.Contains(fn.ReturnType, ") -> ") {
 		// This is a function pointer return type - need special handling
@@ -379,18 +445,27 @@ func (g *CGenerator) generateFunction(fn *mir.Function) error {
 					paramType = "char*" // Buffer parameter should be writable
 				}
 
-				g.output.WriteString(fmt.Sprintf("%s %s", paramType, param.Name))
+				g.output.WriteString(fmt.Sprintf("%s %s%s", paramType, param.Name, variadicCountParam(param)))
 			}
 		}
 		g.output.WriteString(") {\n")
 	}
 
-	// Reset maps for this function to avoid conflicts
+	// Reset maps for this function to avoid conflicts. Value IDs are only
+	// unique within a function, so nothing keyed by them may carry over.
 	g.variables = make(map[mir.ValueID]string)
+	g.mapVars = make(map[string]bool)
+	g.mapTypes = make(map[mir.ValueID]string)
+	g.arrayLengths = make(map[mir.ValueID]int)
+	g.arrayCounts = make(map[mir.ValueID]string)
+	g.valueTypes = make(map[mir.ValueID]string)
 	g.phiVars = make(map[mir.ValueID]bool)
+	g.blockPhis = blockPhis(fn)
+	g.hasTry = hasTry(fn)
 	g.mutableVars = make(map[mir.ValueID]bool)
 	g.stringsToFree = make(map[mir.ValueID]bool)
 	g.promisesToFree = make(map[mir.ValueID]bool)
+	g.arrayAllocsToFree = g.ownedArrays(fn)
 	g.tempStringsToFree = []string{}
 	g.returnedValueID = mir.InvalidValue
 	g.declaredVariables = make(map[mir.ValueID]bool)
@@ -398,7 +473,14 @@ func (g *CGenerator) generateFunction(fn *mir.Function) error {
 	// Map parameter SSA values to their names
 	for _, param := range fn.Params {
 		g.variables[param.ID] = param.Name
+		if param.Variadic {
+			g.arrayCounts[param.ID] = param.Name + "_count"
+		}
 	}
+	// The named return value is declared with its OmniLang name, which the
+	// cleanup recognizes it by
+	namedSlot, namedReturn, hasNamedReturn := namedReturnSlot(fn)
+	g.namedReturn = namedReturn
 
 	// Collect all variables that need to be declared
 	allVariables := make(map[mir.ValueID]string)
@@ -415,14 +497,25 @@ func (g *CGenerator) generateFunction(fn *mir.Function) error {
 		}
 		// Terminators don't produce values, so we don't need to track them
 	}
+	if hasNamedReturn {
+		allVariables[namedSlot] = namedReturn
+	}
 
-	// Declare all variables at the beginning of the function
-	for id, varName := range allVariables {
+	// Declare all variables at the beginning of the function, in value order
+	// so that the generated code is deterministic
+	declOrder := make([]mir.ValueID, 0, len(allVariables))
+	for id := range allVariables {
+		declOrder = append(declOrder, id)
+	}
+	sortValueIDs(declOrder)
+	for _, id := range declOrder {
+		varName := allVariables[id]
 		// Skip parameters (they're already declared)
 		if _, isParam := g.variables[id]; !isParam {
 			// Determine the type based on the instruction that produces this value
 			var varType string
 			var isArrayInit bool
+			var isFuncRef bool
 			// Use the instruction map for O(1) lookup instead of scanning all instructions
 			if inst, found := instructionMap[id]; found {
 				// Special case for read_line() - always returns string
@@ -445,6 +538,8 @@ func (g *CGenerator) generateFunction(fn *mir.Function) error {
 						varType = "int32_t"
 					} else if inst.Type == "float" || inst.Type == "double" {
 						varType = "double"
+					} else if g.mapType(inst.Type) == "omni_struct_t*" {
+						varType = "omni_struct_t*"
 					} else if strings.HasPrefix(inst.Type, "Promise<") {
 						// If type is still Promise, extract inner type
 						innerType := inst.Type[8 : len(inst.Type)-1]
@@ -588,14 +683,28 @@ func (g *CGenerator) generateFunction(fn *mir.Function) error {
 				if inst.Op == "struct.init" {
 					varType = "omni_struct_t*"
 				}
+				isFuncRef = inst.Op == "func.ref"
 			}
 			if varType == "" {
 				varType = "int32_t" // default type
 			}
+			if g.hasTry && varType != "void" {
+				varType = volatileType(varType)
+			}
+			// Arrays are allocated in array.init; start them out NULL so the
+			// cleanup before each return can free them on every path
+			if isArrayInit {
+				if varType == "omni_struct_t*" {
+					varType = "omni_struct_t**"
+				}
+				g.output.WriteString(fmt.Sprintf("  %s %s = NULL;\n", varType, varName))
+				g.declaredVariables[id] = true
+			}
 			// Skip declaring void variables (they don't produce values)
-			// Skip declaring array variables (they're declared in array.init)
+			// Skip declaring array variables (they're declared NULL above)
+			// Skip declaring function pointers (they're declared in func.ref)
 			// For string constants, we'll initialize them in the const instruction
-			if varType != "void" && !isArrayInit {
+			if varType != "void" && !isArrayInit && !isFuncRef {
 				// Check if this is a const instruction with a string literal
 				// Use instruction map for O(1) lookup
 				isStringConst := false
@@ -614,7 +723,24 @@ func (g *CGenerator) generateFunction(fn *mir.Function) error {
 						}
 					}
 				}
-				if !isStringConst {
+				if inst, found := instructionMap[id]; found {
+					if _, counted := countedArrayCall(inst); counted || collectionsArrayCall(inst) || orderedMapArrayCall(inst) {
+						g.arrayCounts[id] = varName + "_count"
+						g.output.WriteString(fmt.Sprintf("  int32_t %s_count = 0;\n", varName))
+					}
+				}
+				// A string a call allocates starts out NULL as well: the
+				// cleanup frees it even when the call sits on a path not taken
+				ownedString := false
+				if inst, found := instructionMap[id]; found {
+					if callee, ok := inst.CallTarget(); ok {
+						ownedString = inst.Type == "string" && g.isStringReturningFunction(callee)
+					}
+				}
+				if !isStringConst && (g.arrayAllocsToFree[id] || ownedString) {
+					// An owned array returned by a call; see the array.init case
+					g.output.WriteString(fmt.Sprintf("  %s %s = NULL;\n", varType, varName))
+				} else if !isStringConst {
 					g.output.WriteString(fmt.Sprintf("  %s %s;\n", varType, varName))
 				}
 				// Mark this variable as declared
@@ -622,6 +748,9 @@ func (g *CGenerator) generateFunction(fn *mir.Function) error {
 			}
 		}
 	}
+	if hasNamedReturn {
+		g.variables[namedSlot] = namedReturn
+	}
 
 	// Pre-populate valueTypes for ALL blocks in this function
 	// This ensures type information is available when processing struct.init
@@ -654,8 +783,32 @@ func (g *CGenerator) generateFunction(fn *mir.Function) error {
 		}
 	}
 
+	// The defer statements push their calls on a frame of the function
+	g.deferPushed = 0
+	g.deferThunks.Reset()
+	if hasDefers(fn) {
+		g.output.WriteString("  omni_defer_frame omni_defers = {NULL, omni_defer_top};\n  omni_defer_top = &omni_defers;\n")
+	}
+
+	// Each try statement gets a frame that omni_throw can longjmp to
+	g.tryFrames = 0
+	for _, block := range fn.Blocks {
+		for _, inst := range block.Instructions {
+			if inst.Op == "try.enter" {
+				g.output.WriteString(fmt.Sprintf("  omni_try_frame omni_try_%d;\n", g.tryFrames))
+				g.tryFrames++
+			}
+		}
+	}
+	g.tryFrames = 0
+
 	// Generate function body
+	g.whileLoops, g.openLoops = whileLoops(fn, g.blockPhis), nil
+	g.emittedBlocks = make(map[string]bool, len(fn.Blocks))
 	for _, block := range fn.Blocks {
+		if g.emittedBlocks[block.Name] {
+			continue
+		}
 		if err := g.generateBlock(block, fn); err != nil {
 			return err
 		}
@@ -670,12 +823,13 @@ func (g *CGenerator) generateFunction(fn *mir.Function) error {
 		// Collect all string IDs and sort them in reverse order
 		var stringIDs []mir.ValueID
 		for id := range g.stringsToFree {
-			// Skip the returned value - caller owns it
-			if id == g.returnedValueID {
+			// Skip the returned value and the named return - caller owns them
+			if id == g.returnedValueID || (g.namedReturn != "" && g.getVariableName(id) == g.namedReturn) {
 				continue
 			}
 			stringIDs = append(stringIDs, id)
 		}
+		sortValueIDs(stringIDs)
 		// Sort in reverse order (free later variables first)
 		for i := len(stringIDs) - 1; i >= 0; i-- {
 			id := stringIDs[i]
@@ -697,22 +851,37 @@ func (g *CGenerator) generateFunction(fn *mir.Function) error {
 	// Free all tracked promises
 	if len(g.promisesToFree) > 0 {
 		g.output.WriteString("  // Cleanup: free promises\n")
+		promiseIDs := make([]mir.ValueID, 0, len(g.promisesToFree))
 		for id := range g.promisesToFree {
+			promiseIDs = append(promiseIDs, id)
+		}
+		sortValueIDs(promiseIDs)
+		for _, id := range promiseIDs {
 			varName := g.getVariableName(id)
 			g.output.WriteString(fmt.Sprintf("  if (%s != NULL) { omni_promise_free(%s); %s = NULL; }\n", varName, varName, varName))
 		}
 	}
 
 	g.output.WriteString("}\n\n")
+	g.insertDeferThunks(start)
 	return nil
 }
 
+// sortValueIDs sorts ids in ascending order.
+func sortValueIDs(ids []mir.ValueID) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+}
+
 // generateBlock generates C code for a basic block
 func (g *CGenerator) generateBlock(block *mir.BasicBlock, fn *mir.Function) error {
 	funcName := fn.Name
 	if funcName == "main" {
 		funcName = "omni_main"
 	}
+	g.currentBlock = block.Name
+	if g.emittedBlocks != nil {
+		g.emittedBlocks[block.Name] = true
+	}
 	// Generate block label if it's not the entry block
 	if block.Name != "entry" {
 		g.output.WriteString(fmt.Sprintf("  %s:\n", block.Name))
@@ -755,6 +924,10 @@ func (g *CGenerator) generateBlock(block *mir.BasicBlock, fn *mir.Function) erro
 		}
 	}
 
+	if loop, ok := g.whileLoops[block.Name]; ok {
+		return g.generateWhile(loop, fn, funcName)
+	}
+
 	// Generate terminator
 	if err := g.generateTerminator(&block.Terminator, funcName, fn.ReturnType); err != nil {
 		return err
@@ -774,14 +947,7 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 		g.output.WriteString(fmt.Sprintf("  // Debug: %s instruction (ID: %s, Type: %s)\n",
 			inst.Op, inst.ID.String(), inst.Type))
 
-		// Add line mapping for better debugging
-		if g.sourceFile != "" {
-			// Create a unique location identifier
-			location := fmt.Sprintf("%s:%s:%s", g.sourceFile, inst.Op, inst.ID.String())
-			if lineNum, exists := g.sourceMap[location]; exists {
-				g.output.WriteString(fmt.Sprintf("  #line %d \"%s\"\n", lineNum, g.sourceFile))
-			}
-		}
+		g.writeLineDirective(inst.Location)
 	}
 
 	switch inst.Op {
@@ -801,6 +967,13 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 				if convertedValue == "0" {
 					g.mutableVars[inst.ID] = true
 				}
+			case "int64", "long":
+				// The LL suffix keeps literals beyond int32_t range intact
+				g.output.WriteString(fmt.Sprintf("  %s = %sLL;\n",
+					varName, g.convertLiteralToDecimal(literalValue)))
+			case "uint", "uint8", "uint16", "uint32", "uint64":
+				g.output.WriteString(fmt.Sprintf("  %s = %s;\n",
+					varName, unsignedLiteral(literalValue, inst.Type)))
 			case "float", "double":
 				// Assign to already declared variable
 				g.output.WriteString(fmt.Sprintf("  %s = %s;\n",
@@ -822,6 +995,8 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 					// Function type - assign function pointer to already declared variable
 					g.output.WriteString(fmt.Sprintf("  %s = %s;\n",
 						varName, literalValue))
+				} else if _, ok := g.module.Enum(inst.Type); ok {
+					g.output.WriteString(fmt.Sprintf("  %s = %s;\n", varName, literalValue))
 				} else {
 					g.output.WriteString(fmt.Sprintf("  // TODO: Handle const type %s\n", inst.Type))
 				}
@@ -833,17 +1008,8 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 			left := g.getOperandValue(inst.Operands[0])
 			right := g.getOperandValue(inst.Operands[1])
 			varName := g.getVariableName(inst.ID)
-
-			// Check if the left operand is a PHI variable (loop variable)
-			if inst.Operands[0].Kind == mir.OperandValue && g.phiVars[inst.Operands[0].Value] {
-				// This is an increment to a PHI variable - update it in place
-				g.output.WriteString(fmt.Sprintf("  %s = %s + %s;\n",
-					left, left, right))
-			} else {
-				// Assign to already declared variable
-				g.output.WriteString(fmt.Sprintf("  %s = %s + %s;\n",
-					varName, left, right))
-			}
+			g.output.WriteString(fmt.Sprintf("  %s = %s + %s;\n",
+				varName, left, right))
 		}
 	case "sub":
 		// Handle subtraction
@@ -851,17 +1017,8 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 			left := g.getOperandValue(inst.Operands[0])
 			right := g.getOperandValue(inst.Operands[1])
 			varName := g.getVariableName(inst.ID)
-
-			// Check if the left operand is a PHI variable (loop variable)
-			if inst.Operands[0].Kind == mir.OperandValue && g.phiVars[inst.Operands[0].Value] {
-				// This is a decrement to a PHI variable - update it in place
-				g.output.WriteString(fmt.Sprintf("  %s = %s - %s;\n",
-					left, left, right))
-			} else {
-				// Regular subtraction - assign to already declared variable
-				g.output.WriteString(fmt.Sprintf("  %s = %s - %s;\n",
-					varName, left, right))
-			}
+			g.output.WriteString(fmt.Sprintf("  %s = %s - %s;\n",
+				varName, left, right))
 		}
 	case "mul":
 		// Handle multiplication
@@ -878,6 +1035,7 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 			left := g.getOperandValue(inst.Operands[0])
 			right := g.getOperandValue(inst.Operands[1])
 			varName := g.getVariableName(inst.ID)
+			g.emitDivisionCheck(inst, right)
 			// Division - assign to already declared variable
 			g.output.WriteString(fmt.Sprintf("  %s = %s / %s;\n",
 				varName, left, right))
@@ -888,10 +1046,27 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 			left := g.getOperandValue(inst.Operands[0])
 			right := g.getOperandValue(inst.Operands[1])
 			varName := g.getVariableName(inst.ID)
+			g.emitDivisionCheck(inst, right)
 			// Modulo - assign to already declared variable
 			g.output.WriteString(fmt.Sprintf("  %s = %s %% %s;\n",
 				varName, left, right))
 		}
+	case "udiv", "umod":
+		// Unsigned division and modulo: both operands are converted to the
+		// unsigned result type so that C does not divide them as signed
+		if len(inst.Operands) >= 2 {
+			left := g.getOperandValue(inst.Operands[0])
+			right := g.getOperandValue(inst.Operands[1])
+			varName := g.getVariableName(inst.ID)
+			ctype := g.mapType(inst.Type)
+			op := "/"
+			if inst.Op == "umod" {
+				op = "%"
+			}
+			g.emitDivisionCheck(inst, right)
+			g.output.WriteString(fmt.Sprintf("  %s = (%s)%s %s (%s)%s;\n",
+				varName, ctype, left, op, ctype, right))
+		}
 	case "bitand":
 		// Handle bitwise AND
 		if len(inst.Operands) >= 2 {
@@ -960,13 +1135,21 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 			}
 		}
 	case "throw":
-		// Handle throw statement - for now, just print the exception and continue
-		// In a full implementation, this would set a global exception state
-		if len(inst.Operands) >= 1 {
-			exceptionValue := g.getOperandValue(inst.Operands[0])
-			g.output.WriteString(fmt.Sprintf("  // Throwing exception: %s\n", exceptionValue))
-			g.output.WriteString(fmt.Sprintf("  printf(\"Exception: %%s\\n\", %s);\n", exceptionValue))
-		}
+		g.generateThrow(inst)
+	case "try.enter":
+		g.generateTryEnter(inst)
+	case "try.exit":
+		g.output.WriteString("  omni_try_top = omni_try_top->prev;\n")
+	case "catch.begin":
+		g.generateCatchBegin(inst)
+	case "finally.begin":
+		g.output.WriteString(fmt.Sprintf("  %s = omni_exception;\n", g.getVariableName(inst.ID)))
+	case "opt.some", "opt.none", "opt.unwrap", "opt.unwrap_or", "opt.is_some", "opt.is_none":
+		g.generateOptional(inst)
+	case "interface.init", "interface.call":
+		g.generateInterface(inst)
+	case "global.load", "global.store":
+		g.generateGlobal(inst)
 	case "neg":
 		// Handle negation
 		if len(inst.Operands) >= 1 {
@@ -995,6 +1178,9 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 				varName, operand))
 		}
 	case "cast":
+		if g.emitAnyCast(inst) {
+			return nil
+		}
 		// Handle type cast
 		if len(inst.Operands) >= 1 {
 			operand := g.getOperandValue(inst.Operands[0])
@@ -1030,6 +1216,12 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 			g.output.WriteString(fmt.Sprintf("  %s = %s || %s;\n",
 				varName, left, right))
 		}
+	case "defer.push":
+		return g.generateDeferPush(inst)
+	case "defer.run":
+		// Make the deferred calls in reverse order and pop the frame
+		g.output.WriteString("  omni_defer_unwind(omni_defers.prev);\n")
+		return nil
 	case "call", "call.int", "call.void", "call.string", "call.bool":
 		// Handle function calls
 		if len(inst.Operands) > 0 {
@@ -1041,8 +1233,22 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 			} else {
 				funcName = g.getOperandValue(inst.Operands[0])
 			}
+			// Arrays returned by the callee have the length of its literal
+			if length, ok := g.returnedArrayLength(funcName); ok {
+				g.arrayLengths[inst.ID] = length
+				if g.arrayAllocsToFree[inst.ID] {
+					// Release the array from a previous loop iteration, as
+					// for array.init
+					g.output.WriteString(fmt.Sprintf("  free(%s);\n", g.getVariableName(inst.ID)))
+				}
+			}
 
 			// Special handling for len() function
+			if funcName == "len" && len(inst.Operands) == 2 && g.isAny(inst.Operands[1]) {
+				g.output.WriteString(fmt.Sprintf("  %s = omni_json_length(%s);\n",
+					g.getVariableName(inst.ID), g.getOperandValue(inst.Operands[1])))
+				return nil
+			}
 			if funcName == "len" && len(inst.Operands) == 2 {
 				varName := g.getVariableName(inst.ID)
 				arrayVar := g.getOperandValue(inst.Operands[1])
@@ -1050,13 +1256,17 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 				arrayLength := -1 // Use -1 as sentinel for "unknown length"
 				if inst.Operands[1].Kind == mir.OperandValue {
 					arrayOperandID := inst.Operands[1].Value
+					if count, ok := g.arrayCounts[arrayOperandID]; ok {
+						g.output.WriteString(fmt.Sprintf("  %s = %s;\n", varName, count))
+						return nil
+					}
 					if length, ok := g.arrayLengths[arrayOperandID]; ok {
 						arrayLength = length
 					} else {
 						// Array length not found - this might be a parameter or passed array
 						// For function parameters, we need to track array lengths separately
 						// For now, fail loudly to prevent silent bugs
-						g.errors = append(g.errors, fmt.Sprintf("array length not known for variable %s (ID: %d) - len() requires compile-time known array length or explicit length parameter", arrayVar, arrayOperandID))
+						g.error("unknown-array-length", fmt.Sprintf("array length not known for variable %s (ID: %d) - len() requires compile-time known array length or explicit length parameter", arrayVar, arrayOperandID))
 						// Still emit code with -1 so the program fails at runtime rather than silently returning 0
 						arrayLength = -1
 					}
@@ -1093,19 +1303,140 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 				return nil
 			}
 
+			// cubic_spline needs the number of points, which C arrays do not carry
+			if funcName == "std.math.interpolation.cubic_spline" && len(inst.Operands) == 3 {
+				varName := g.getVariableName(inst.ID)
+				points := -1
+				for _, arg := range inst.Operands[1:] {
+					length, ok := -1, false
+					if arg.Kind == mir.OperandValue {
+						length, ok = g.arrayLengths[arg.Value]
+					}
+					if !ok {
+						g.error("unknown-array-length", fmt.Sprintf("array length not known for %s - cubic_spline requires arrays of compile-time known length", g.getOperandValue(arg)))
+						continue
+					}
+					if points < 0 || length < points {
+						points = length
+					}
+				}
+				g.output.WriteString(fmt.Sprintf("  %s = omni_interp_cubic_spline(%s, %s, %d);\n",
+					varName, g.getOperandValue(inst.Operands[1]), g.getOperandValue(inst.Operands[2]), points))
+				return nil
+			}
+
+			// The pieces of a split and the matches of a regex come back
+			// with their number, which len() and indexing read; see arrayCounts
+			if runtimeFunc, ok := countedArrayCall(inst); ok && len(inst.Operands) == 3 {
+				varName := g.getVariableName(inst.ID)
+				g.output.WriteString(fmt.Sprintf("  %s = (const char**)%s(%s, %s, &%s);\n",
+					varName, runtimeFunc, g.getOperandValue(inst.Operands[1]), g.getOperandValue(inst.Operands[2]), g.arrayCounts[inst.ID]))
+				return nil
+			}
+
+			if isStringFormat(inst) && len(inst.Operands) >= 2 {
+				g.emitStringFormat(inst)
+				return nil
+			}
+
+			if parse, ok := stringParseFunctions[funcName]; ok && len(inst.Operands) == 2 {
+				g.emitStringParse(inst, parse)
+				return nil
+			}
+
+			if callee, ok := collectionsCallback(inst); ok {
+				g.emitCollectionsCallback(inst, callee)
+				return nil
+			}
+
+			if op, ok := dequeCall(inst); ok {
+				g.emitDequeCall(inst, op)
+				return nil
+			}
+
+			if op, ok := trieCall(inst); ok {
+				g.emitTrieCall(inst, op)
+				return nil
+			}
+
+			if op, ok := orderedMapCall(inst); ok {
+				g.emitOrderedMapCall(inst, op)
+				return nil
+			}
+
+			// The parts of path.join arrive one by one, without the array a
+			// variadic parameter would take
+			if funcName == "std.path.join" {
+				varName := g.getVariableName(inst.ID)
+				parts := make([]string, 0, len(inst.Operands)-1)
+				for _, arg := range inst.Operands[1:] {
+					parts = append(parts, g.getOperandValue(arg))
+				}
+				if len(parts) == 0 {
+					g.output.WriteString(fmt.Sprintf("  %s = omni_path_join(NULL, 0);\n", varName))
+				} else {
+					g.output.WriteString(fmt.Sprintf("  %s = omni_path_join((const char*[]){%s}, %d);\n", varName, strings.Join(parts, ", "), len(parts)))
+				}
+				g.stringsToFree[inst.ID] = true
+				return nil
+			}
+
+			// The sort functions need the length of the array they sort
+			if runtimeFunc, ok := sortFunctions[funcName]; ok && len(inst.Operands) == 2 {
+				count, ok := g.arrayLength(inst.Operands[1])
+				if !ok {
+					count = "0"
+					g.error("unknown-array-length", fmt.Sprintf("array length not known for %s - %s requires an array of compile-time known length", g.getOperandValue(inst.Operands[1]), strings.TrimPrefix(funcName, "std.")))
+				}
+				g.output.WriteString(fmt.Sprintf("  %s(%s, %s);\n", runtimeFunc, g.getOperandValue(inst.Operands[1]), count))
+				return nil
+			}
+
+			// process.run needs the number of arguments, which C arrays do
+			// not carry; run_async runs the command before resolving
+			if (funcName == "std.process.run" || funcName == "std.process.run_async") && len(inst.Operands) == 3 {
+				varName := g.getVariableName(inst.ID)
+				args := inst.Operands[2]
+				count, ok := g.arrayLength(args)
+				if !ok {
+					count = "0"
+					g.error("unknown-array-length", fmt.Sprintf("array length not known for %s - process.run requires an argument array of compile-time known length", g.getOperandValue(args)))
+				}
+				run := fmt.Sprintf("omni_process_run(%s, (const char**)%s, %s)", g.getOperandValue(inst.Operands[1]), g.getOperandValue(args), count)
+				if funcName == "std.process.run_async" {
+					run = fmt.Sprintf("omni_promise_create_struct(%s)", run)
+					g.valueTypes[inst.ID] = "Promise<ProcessResult>"
+					g.promisesToFree[inst.ID] = true
+				}
+				g.output.WriteString(fmt.Sprintf("  %s = %s;\n", varName, run))
+				return nil
+			}
+
 			// Special-case std.io print helpers so we can perform type conversion.
 			if (funcName == "std.io.print" || funcName == "io.print") && len(inst.Operands) >= 2 {
-				g.emitPrint(inst.Operands[1], false)
+				g.emitPrint(inst.Operands[1], false, false)
 				return nil
 			}
 			if funcName == "std.io.println" || funcName == "io.println" {
 				if len(inst.Operands) >= 2 {
-					g.emitPrint(inst.Operands[1], true)
+					g.emitPrint(inst.Operands[1], true, false)
 				} else {
 					g.output.WriteString("  omni_println_string(\"\");\n")
 				}
 				return nil
 			}
+			if (funcName == "std.io.eprint" || funcName == "io.eprint") && len(inst.Operands) >= 2 {
+				g.emitPrint(inst.Operands[1], false, true)
+				return nil
+			}
+			if funcName == "std.io.eprintln" || funcName == "io.eprintln" {
+				if len(inst.Operands) >= 2 {
+					g.emitPrint(inst.Operands[1], true, true)
+				} else {
+					g.output.WriteString("  omni_eprintln_string(\"\");\n")
+				}
+				return nil
+			}
 
 			// Special-case std.io.read_line to ensure result is assigned
 			if funcName == "std.io.read_line" || funcName == "io.read_line" {
@@ -1187,12 +1518,7 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 			if inst.Type == "void" {
 				g.output.WriteString(fmt.Sprintf("  %s(", cFuncName))
 				// Add arguments
-				for i, arg := range inst.Operands[1:] {
-					if i > 0 {
-						g.output.WriteString(", ")
-					}
-					g.output.WriteString(g.getOperandValue(arg))
-				}
+				g.output.WriteString(g.callArguments(funcName, inst.Operands[1:]))
 				g.output.WriteString(");\n")
 			} else {
 				varName := g.getVariableName(inst.ID)
@@ -1201,12 +1527,7 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 					// Async functions already return omni_promise_t*, so just assign (variable already declared)
 					g.output.WriteString(fmt.Sprintf("  %s = %s(", varName, cFuncName))
 					// Add arguments
-					for i, arg := range inst.Operands[1:] {
-						if i > 0 {
-							g.output.WriteString(", ")
-						}
-						g.output.WriteString(g.getOperandValue(arg))
-					}
+					g.output.WriteString(g.callArguments(funcName, inst.Operands[1:]))
 					g.output.WriteString(");\n")
 					// Store the Promise type in valueTypes so await can find it
 					g.valueTypes[inst.ID] = inst.Type
@@ -1220,12 +1541,7 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 					g.output.WriteString(fmt.Sprintf("  %s = %s(",
 						g.mapFunctionTypeWithName(inst.Type, varName), cFuncName))
 					// Add arguments
-					for i, arg := range inst.Operands[1:] {
-						if i > 0 {
-							g.output.WriteString(", ")
-						}
-						g.output.WriteString(g.getOperandValue(arg))
-					}
+					g.output.WriteString(g.callArguments(funcName, inst.Operands[1:]))
 					g.output.WriteString(");\n")
 				} else {
 					// Special handling for functions that return structs (IPAddress, URL, HTTPResponse, etc.)
@@ -1569,12 +1885,7 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 							g.output.WriteString(fmt.Sprintf("  %s = %s(",
 								varName, cFuncName))
 							// Add arguments
-							for i, arg := range inst.Operands[1:] {
-								if i > 0 {
-									g.output.WriteString(", ")
-								}
-								g.output.WriteString(g.getOperandValue(arg))
-							}
+							g.output.WriteString(g.callArguments(funcName, inst.Operands[1:]))
 							g.output.WriteString(");\n")
 							// Track strings that need freeing if this function returns a heap-allocated string
 							if g.isStringReturningFunction(funcName) && inst.Type == "string" {
@@ -1582,7 +1893,7 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 							}
 							// Warn if function is called but doesn't have a runtime implementation
 							if !g.hasRuntimeImplementation(funcName) && g.isStdFunction(funcName) {
-								g.errors = append(g.errors, fmt.Sprintf("WARNING: stdlib function '%s' is called but has no runtime implementation. It will return a default value or do nothing.", funcName))
+								g.warn("stdlib-stub", fmt.Sprintf("stdlib function '%s' is called but has no runtime implementation. It will return a default value or do nothing.", funcName))
 							}
 						}
 					}
@@ -1590,6 +1901,9 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 			}
 		}
 	case "index":
+		if g.emitAnyIndex(inst) {
+			return nil
+		}
 		// Handle array/map indexing
 		if len(inst.Operands) >= 2 {
 			target := g.getOperandValue(inst.Operands[0])
@@ -1624,7 +1938,7 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 				if getFunc != "" {
 					g.output.WriteString(fmt.Sprintf("  %s = %s(%s, %s);\n", varName, getFunc, target, index))
 				} else {
-					g.errors = append(g.errors, fmt.Sprintf("unsupported map get operation for type: %s", mapType))
+					g.error("unsupported-map-op", fmt.Sprintf("unsupported map get operation for type: %s", mapType))
 					g.output.WriteString(fmt.Sprintf("  // ERROR: Unsupported map get for %s\n", mapType))
 				}
 			} else {
@@ -1672,6 +1986,9 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 						// Use bounds checking for string arrays
 						g.output.WriteString(fmt.Sprintf("  if (%s < 0 || %s >= %d) { fprintf(stderr, \"Array index out of bounds: %%d (length: %%d)\\n\", %s, %d); exit(1); }\n",
 							index, index, arrayLength, index, arrayLength))
+					} else if count, ok := g.arrayCounts[inst.Operands[0].Value]; ok && inst.Operands[0].Kind == mir.OperandValue {
+						g.output.WriteString(fmt.Sprintf("  if (%s < 0 || %s >= %s) { fprintf(stderr, \"Array index out of bounds: %%d (length: %%d)\\n\", %s, %s); exit(1); }\n",
+							index, index, count, index, count))
 					}
 					g.output.WriteString(fmt.Sprintf("  %s = %s[%s];\n", varName, target, index))
 				} else if elementType == "int" || elementType == "int32" {
@@ -1687,10 +2004,13 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 						// Use runtime function with bounds checking
 						g.output.WriteString(fmt.Sprintf("  %s = omni_array_get_int(%s, %s, %d);\n",
 							varName, target, index, arrayLength))
+					} else if count, ok := g.arrayCounts[inst.Operands[0].Value]; ok && inst.Operands[0].Kind == mir.OperandValue {
+						g.output.WriteString(fmt.Sprintf("  %s = omni_array_get_int(%s, %s, %s);\n",
+							varName, target, index, count))
 					} else {
 						// Length unknown (might be parameter) - still use runtime function but with -1
 						// This will cause a runtime error rather than silent memory corruption
-						g.errors = append(g.errors, fmt.Sprintf("array length not known for indexing %s (ID: %d) - bounds checking disabled, may cause memory corruption", target, inst.Operands[0].Value))
+						g.warn("unknown-array-length", fmt.Sprintf("array length not known for indexing %s (ID: %d) - bounds checking disabled, may cause memory corruption", target, inst.Operands[0].Value))
 						g.output.WriteString(fmt.Sprintf("  // WARNING: Array length unknown, bounds checking disabled\n"))
 						g.output.WriteString(fmt.Sprintf("  %s = %s[%s]; // UNSAFE: No bounds check\n", varName, target, index))
 					}
@@ -1713,12 +2033,8 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 			}
 		}
 	case "array.init":
-		// Handle array literal initialization
-		// WARNING: Arrays are currently allocated on the stack, which means:
-		// - They cannot be returned from functions (dangling pointers)
-		// - They cannot be stored in longer-lived variables
-		// - They decay to pointers when passed around
-		// TODO: Implement heap allocation for arrays to support proper value semantics
+		// Handle array literal initialization. Arrays live on the heap so that
+		// they can be returned and stored; see ownedArrays for when they are freed.
 		if len(inst.Operands) > 0 {
 			varName := g.getVariableName(inst.ID)
 			arrayLength := len(inst.Operands)
@@ -1738,30 +2054,44 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 			// Check if element type is a struct (not a primitive)
 			isStruct := !g.isPrimitiveType(elementTypeStr) && !strings.Contains(elementTypeStr, "<") && !strings.Contains(elementTypeStr, "(")
 
-			if isStruct {
-				// For struct arrays, create array of pointers
-				elementType := "omni_struct_t*"
-				g.output.WriteString(fmt.Sprintf("  // WARNING: Stack-allocated array, cannot be returned or stored long-term\n"))
-				g.output.WriteString(fmt.Sprintf("  %s %s[%d];\n", elementType, varName, arrayLength))
-				// Initialize each struct element
-				for i, op := range inst.Operands {
-					// Each operand should be a struct.init instruction result
-					// Get the variable name for this struct
-					structVar := g.getOperandValue(op)
-					g.output.WriteString(fmt.Sprintf("  %s[%d] = %s;\n", varName, i, structVar))
+			// For struct arrays, create array of pointers to the structs
+			elementType := "omni_struct_t*"
+			if !isStruct {
+				elementType = g.mapType(elementTypeStr) // Map "int" to "int32_t"
+			}
+			if g.arrayAllocsToFree[inst.ID] {
+				// Release the array from a previous loop iteration; owned
+				// arrays never escape, so nothing else refers to it
+				g.output.WriteString(fmt.Sprintf("  free(%s);\n", varName))
+			}
+			g.output.WriteString(fmt.Sprintf("  %s = (%s*)malloc(%d * sizeof(%s));\n", varName, elementType, arrayLength, elementType))
+			for i, op := range inst.Operands {
+				g.output.WriteString(fmt.Sprintf("  %s[%d] = %s;\n", varName, i, g.getOperandValue(op)))
+			}
+		} else if inst.ID != mir.InvalidValue {
+			// An empty array, such as no arguments for a variadic parameter
+			g.arrayLengths[inst.ID] = 0
+			g.output.WriteString(fmt.Sprintf("  %s = NULL;\n", g.getVariableName(inst.ID)))
+		}
+	case "map.contains":
+		// Handle map key lookup
+		if len(inst.Operands) >= 2 {
+			target := g.getOperandValue(inst.Operands[0])
+			key := g.getOperandValue(inst.Operands[1])
+			varName := g.getVariableName(inst.ID)
+			mapType := inst.Operands[0].Type
+			if inst.Operands[0].Kind == mir.OperandValue {
+				if storedType, ok := g.valueTypes[inst.Operands[0].Value]; ok && strings.HasPrefix(storedType, "map<") {
+					mapType = storedType
 				}
+			}
+			keyType, valueType := g.extractMapTypes(mapType)
+			containsFunc := g.getMapContainsFunction(keyType, valueType)
+			if containsFunc != "" {
+				g.output.WriteString(fmt.Sprintf("  %s = %s(%s, %s);\n", varName, containsFunc, target, key))
 			} else {
-				// For primitive types, create simple array
-				elementType := g.mapType(elementTypeStr) // Map "int" to "int32_t"
-				g.output.WriteString(fmt.Sprintf("  // WARNING: Stack-allocated array, cannot be returned or stored long-term\n"))
-				g.output.WriteString(fmt.Sprintf("  %s %s[] = {", elementType, varName))
-				for i, op := range inst.Operands {
-					if i > 0 {
-						g.output.WriteString(", ")
-					}
-					g.output.WriteString(g.getOperandValue(op))
-				}
-				g.output.WriteString("};\n")
+				g.error("unsupported-map-op", fmt.Sprintf("unsupported map contains operation for type: %s", mapType))
+				g.output.WriteString(fmt.Sprintf("  // ERROR: Unsupported map contains for %s\n", mapType))
 			}
 		}
 	case "map.init":
@@ -1791,7 +2121,7 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 							g.output.WriteString(fmt.Sprintf("  %s(%s, %s, %s);\n", putFunc, varName, key, value))
 						} else {
 							// Unsupported type combination - report error
-							g.errors = append(g.errors, fmt.Sprintf("unsupported map type combination: map<%s,%s>", keyType, valueType))
+							g.error("unsupported-map-type", fmt.Sprintf("unsupported map type combination: map<%s,%s>", keyType, valueType))
 							g.output.WriteString(fmt.Sprintf("  // ERROR: Unsupported map type %s\n", mapType))
 						}
 					}
@@ -2001,7 +2331,7 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 
 						// Default to int if still no type, but warn about it
 						if fieldType == "" || fieldType == "<inferred>" {
-							g.errors = append(g.errors, fmt.Sprintf("could not infer type for struct field '%s' in struct.init, defaulting to int (this may cause incorrect behavior)", fieldName))
+							g.warn("struct-field-type", fmt.Sprintf("could not infer type for struct field '%s' in struct.init, defaulting to int (this may cause incorrect behavior)", fieldName))
 							fieldType = "int"
 						}
 
@@ -2023,7 +2353,7 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 							// For non-primitive types, we can't use the primitive setters
 							// This should have been caught earlier, but fail loudly here
 							if !g.isPrimitiveType(fieldType) {
-								g.errors = append(g.errors, fmt.Sprintf("cannot set struct field '%s' with type %s: only primitive types are supported", fieldName, fieldType))
+								g.error("unsupported-struct-field", fmt.Sprintf("cannot set struct field '%s' with type %s: only primitive types are supported", fieldName, fieldType))
 								// Fall back to int to prevent compilation errors
 								g.output.WriteString(fmt.Sprintf("  // ERROR: Cannot set field %s with type %s, using int setter (WRONG)\n", fieldName, fieldType))
 								g.output.WriteString(fmt.Sprintf("  omni_struct_set_int_field(%s, \"%s\", %s); // WRONG TYPE\n", varName, fieldName, fieldValue))
@@ -2089,6 +2419,9 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 			}
 		}
 	case "member":
+		if g.emitAnyIndex(inst) {
+			return nil
+		}
 		// Handle struct member access
 		if len(inst.Operands) >= 2 {
 			structVar := g.getOperandValue(inst.Operands[0])
@@ -2184,6 +2517,9 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 				}
 			}
 
+			if g.generateNullComparison(inst, leftType, rightType) {
+				break
+			}
 			// If either operand is a string, use string comparison function
 			if leftType == "string" || rightType == "string" {
 				switch inst.Op {
@@ -2237,6 +2573,10 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 
 			// Assign the source value to the target variable
 			g.output.WriteString(fmt.Sprintf("  %s = %s;\n", target, source))
+			// A string assigned to the named return goes to the caller
+			if g.namedReturn != "" && target == g.namedReturn && inst.Operands[1].Kind == mir.OperandValue {
+				delete(g.stringsToFree, inst.Operands[1].Value)
+			}
 
 			// Update the variable mapping to point to the target
 			g.variables[inst.ID] = target
@@ -2262,14 +2602,18 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 				g.valueTypes[inst.ID] = resultType
 			}
 
-			// Create a mutable variable that can be updated in the loop
-			// For PHI nodes, we initialize with the first value and will update it later
-			// Note: This is a special case where we need to initialize the variable
-			g.output.WriteString(fmt.Sprintf("  %s = %s;\n",
-				varName, firstValue))
-
 			// Track this as a PHI variable that needs special handling
 			g.phiVars[inst.ID] = true
+
+			// A phi that names its predecessors is assigned on each incoming
+			// edge (see writePhiCopies), so the variable already holds the
+			// value from the block control came from
+			if hasIncomingBlocks(*inst) {
+				break
+			}
+			// Without predecessor blocks, fall back to the initial value
+			g.output.WriteString(fmt.Sprintf("  %s = %s;\n",
+				varName, firstValue))
 		}
 	case "malloc":
 		// Handle dynamic memory allocation
@@ -2467,15 +2811,15 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 		}
 	case "closure.create", "closure.capture", "closure.bind":
 		// Closures are not yet supported in the C backend
-		g.errors = append(g.errors, fmt.Sprintf("closures are not supported in the C backend (instruction: %s)", inst.Op))
+		g.error("unsupported-closure", fmt.Sprintf("closures are not supported in the C backend (instruction: %s)", inst.Op))
 		return fmt.Errorf("closures are not supported in the C backend: %s", inst.Op)
 	case "std.io.print":
 		if len(inst.Operands) >= 1 {
-			g.emitPrint(inst.Operands[0], false)
+			g.emitPrint(inst.Operands[0], false, false)
 		}
 	case "std.io.println":
 		if len(inst.Operands) >= 1 {
-			g.emitPrint(inst.Operands[0], true)
+			g.emitPrint(inst.Operands[0], true, false)
 		} else {
 			g.output.WriteString("  omni_println_string(\"\");\n")
 		}
@@ -2620,7 +2964,7 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 				if resultType == "<infer>" || resultType == "<inferred>" || resultType == inferTypePlaceholder || resultType == "" {
 					// Try one more time to get it from the function signature if this is awaiting a direct call
 					// For now, we'll need to fail with a more helpful error
-					g.errors = append(g.errors, fmt.Sprintf("cannot await Promise: type could not be inferred. Ensure async functions have explicit return types (e.g., async func f():int instead of async func f())"))
+					g.error("await-type", "cannot await Promise: type could not be inferred. Ensure async functions have explicit return types (e.g., async func f():int instead of async func f())")
 					// Default to int as a fallback to allow compilation to continue
 					g.output.WriteString(fmt.Sprintf("  // ERROR: Type inference failed, defaulting to int\n"))
 					if needsDecl {
@@ -2630,10 +2974,18 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 						g.output.WriteString(fmt.Sprintf("  %s = omni_await_int(%s); // INFERRED TYPE\n", varName, promiseVar))
 					}
 					resultType = "int"
+				} else if cType := g.mapType(resultType); cType == "omni_struct_t*" {
+					// Struct promises hand the struct over to the awaiter
+					if needsDecl {
+						g.output.WriteString(fmt.Sprintf("  %s %s = omni_await_struct(%s);\n", cType, varName, promiseVar))
+						g.declaredVariables[inst.ID] = true
+					} else {
+						g.output.WriteString(fmt.Sprintf("  %s = omni_await_struct(%s);\n", varName, promiseVar))
+					}
 				} else {
-					// For user-defined types, we cannot await them yet
+					// For other user-defined types, we cannot await them yet
 					// Fail loudly instead of silently defaulting to string
-					g.errors = append(g.errors, fmt.Sprintf("cannot await Promise<%s>: user-defined types are not supported in await expressions", resultType))
+					g.error("await-type", fmt.Sprintf("cannot await Promise<%s>: user-defined types are not supported in await expressions", resultType))
 					// Still emit code to prevent compilation errors, but it will be wrong
 					g.output.WriteString(fmt.Sprintf("  // ERROR: Cannot await user-defined type %s, defaulting to int (WRONG)\n", resultType))
 					if needsDecl {
@@ -2650,7 +3002,7 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 		}
 	default:
 		// Unknown instructions should cause a hard failure
-		g.errors = append(g.errors, fmt.Sprintf("unsupported MIR instruction: %s (this indicates a missing implementation or invalid MIR)", inst.Op))
+		g.error("unsupported-instruction", fmt.Sprintf("unsupported MIR instruction: %s (this indicates a missing implementation or invalid MIR)", inst.Op))
 		return fmt.Errorf("unsupported MIR instruction: %s", inst.Op)
 	}
 
@@ -2661,8 +3013,24 @@ func (g *CGenerator) generateInstruction(inst *mir.Instruction) error {
 // funcName is the C function name (e.g., "omni_main")
 // originalReturnType is the original MIR return type (e.g., "Promise<int>" or "int")
 func (g *CGenerator) generateTerminator(term *mir.Terminator, funcName string, originalReturnType string) error {
+	if g.debugInfo {
+		g.writeLineDirective(term.Location)
+	}
 	switch term.Op {
-	case "ret":
+	case "ret", "ret.named":
+		// Free the arrays this function owns; the returned array escapes, so
+		// it is never among them
+		if len(g.arrayAllocsToFree) > 0 {
+			arrayIDs := make([]mir.ValueID, 0, len(g.arrayAllocsToFree))
+			for id := range g.arrayAllocsToFree {
+				arrayIDs = append(arrayIDs, id)
+			}
+			sortValueIDs(arrayIDs)
+			g.output.WriteString("  // Cleanup: free heap-allocated arrays\n")
+			for i := len(arrayIDs) - 1; i >= 0; i-- {
+				g.output.WriteString(fmt.Sprintf("  free(%s);\n", g.getVariableName(arrayIDs[i])))
+			}
+		}
 		// Handle return statement
 		if len(term.Operands) > 0 {
 			// Track the returned value ID to exclude it from cleanup
@@ -2705,9 +3073,13 @@ func (g *CGenerator) generateTerminator(term *mir.Terminator, funcName string, o
 				case "bool":
 					promiseFunc = "omni_promise_create_bool"
 				default:
-					// For user-defined types, we can't create promises yet
+					if g.mapType(innerType) == "omni_struct_t*" {
+						promiseFunc = "omni_promise_create_struct"
+						break
+					}
+					// For other user-defined types, we can't create promises yet
 					// This should be caught earlier, but fail loudly here
-					g.errors = append(g.errors, fmt.Sprintf("cannot create promise for user-defined type: %s", innerType))
+					g.error("promise-type", fmt.Sprintf("cannot create promise for user-defined type: %s", innerType))
 					promiseFunc = "omni_promise_create_int" // Fallback to prevent compilation error
 				}
 				// Create promise and return it
@@ -2732,16 +3104,14 @@ func (g *CGenerator) generateTerminator(term *mir.Terminator, funcName string, o
 				g.output.WriteString("  return;\n")
 			}
 		}
-	case "jmp":
-		// Handle unconditional jump
-		if len(term.Operands) > 0 {
-			blockName := g.getOperandValue(term.Operands[0])
-			g.output.WriteString(fmt.Sprintf("  goto %s;\n", blockName))
-		}
-	case "br":
-		// Handle unconditional branch
+	case "jmp", "br", "loop.break", "loop.continue":
+		// Handle unconditional branch, including break and continue
 		if len(term.Operands) > 0 {
 			blockName := g.getOperandValue(term.Operands[0])
+			if done, err := g.jumpInWhile(blockName); done {
+				return err
+			}
+			g.writePhiCopies(blockName, "  ")
 			g.output.WriteString(fmt.Sprintf("  goto %s;\n", blockName))
 		}
 	case "cbr":
@@ -2751,14 +3121,19 @@ func (g *CGenerator) generateTerminator(term *mir.Terminator, funcName string, o
 			trueBlock := g.getOperandValue(term.Operands[1])
 			falseBlock := g.getOperandValue(term.Operands[2])
 			g.output.WriteString(fmt.Sprintf("  if (%s) {\n", condition))
+			g.writePhiCopies(trueBlock, "    ")
 			g.output.WriteString(fmt.Sprintf("    goto %s;\n", trueBlock))
 			g.output.WriteString("  } else {\n")
+			g.writePhiCopies(falseBlock, "    ")
 			g.output.WriteString(fmt.Sprintf("    goto %s;\n", falseBlock))
 			g.output.WriteString("  }\n")
 		}
+	case "unreachable":
+		// Only follows a throw, which longjmps or exits
+		g.output.WriteString("  abort();\n")
 	default:
 		// Unknown terminators should cause a hard failure
-		g.errors = append(g.errors, fmt.Sprintf("unsupported MIR terminator: %s (this indicates a missing implementation or invalid MIR)", term.Op))
+		g.error("unsupported-terminator", fmt.Sprintf("unsupported MIR terminator: %s (this indicates a missing implementation or invalid MIR)", term.Op))
 		return fmt.Errorf("unsupported MIR terminator: %s", term.Op)
 	}
 
@@ -2812,6 +3187,16 @@ func (g *CGenerator) convertOperandToString(op mir.Operand) string {
 			// Track this temporary string for cleanup
 			g.tempStringsToFree = append(g.tempStringsToFree, tempVar)
 			return tempVar
+		} else if operandType == "int64" || operandType == "long" {
+			tempVar := fmt.Sprintf("temp_str_%d_%d", op.Value, g.output.Len())
+			g.output.WriteString(fmt.Sprintf("  const char* %s = omni_int64_to_string(%s);\n", tempVar, varName))
+			g.tempStringsToFree = append(g.tempStringsToFree, tempVar)
+			return tempVar
+		} else if isUnsignedType(operandType) {
+			tempVar := fmt.Sprintf("temp_str_%d_%d", op.Value, g.output.Len())
+			g.output.WriteString(fmt.Sprintf("  const char* %s = omni_uint64_to_string(%s);\n", tempVar, varName))
+			g.tempStringsToFree = append(g.tempStringsToFree, tempVar)
+			return tempVar
 		} else if operandType == "float" || operandType == "double" {
 			// Convert float to string - use unique counter to avoid conflicts
 			tempVar := fmt.Sprintf("temp_str_%d_%d", op.Value, g.output.Len())
@@ -2856,10 +3241,16 @@ func (g *CGenerator) convertOperandToString(op mir.Operand) string {
 	}
 }
 
-// emitPrint handles std.io.print/println for primitive and convertible types.
-func (g *CGenerator) emitPrint(op mir.Operand, newline bool) {
+// emitPrint handles std.io.print/println and eprint/eprintln, which write
+// to stderr, for primitive and convertible types.
+func (g *CGenerator) emitPrint(op mir.Operand, newline, stderr bool) {
 	funcName := "omni_print_string"
-	if newline {
+	switch {
+	case newline && stderr:
+		funcName = "omni_eprintln_string"
+	case stderr:
+		funcName = "omni_eprint_string"
+	case newline:
 		funcName = "omni_println_string"
 	}
 
@@ -2896,11 +3287,54 @@ func (g *CGenerator) mapType(omniType string) string {
 		return g.mapFunctionType(omniType)
 	}
 
+	// The null literal is compared against pointer-backed values
+	if omniType == "null" {
+		return "void*"
+	}
+
+	// The exception a finally block rethrows
+	if omniType == "exception" {
+		return "omni_exception_t"
+	}
+
+	// Values of std.json.parse; see json.go
+	if omniType == "any" {
+		return "omni_json_t*"
+	}
+
+	// Handle optional types whose C representation is already a pointer:
+	// null is NULL, so array<int>? lowers to int32_t*. Optional numbers are
+	// structs with a has_value flag; see optionalStruct.
+	if strings.HasSuffix(omniType, "?") {
+		baseType := g.mapType(strings.TrimSuffix(omniType, "?"))
+		if strings.HasSuffix(baseType, "*") {
+			return baseType
+		}
+		if structType, ok := optionalStruct(baseType); ok {
+			return structType
+		}
+	}
+
 	// Handle Promise types: Promise<T>
 	if strings.HasPrefix(omniType, "Promise<") && strings.HasSuffix(omniType, ">") {
 		return "omni_promise_t*"
 	}
 
+	// std.collections.deque values; see deque.go
+	if strings.HasPrefix(omniType, "deque<") && strings.HasSuffix(omniType, ">") {
+		return "omni_deque_t*"
+	}
+
+	// std.collections.trie values; see trie.go
+	if strings.HasPrefix(omniType, "Trie<") && strings.HasSuffix(omniType, ">") {
+		return "omni_trie_t*"
+	}
+
+	// std.collections.ordered_map values; see ordered_map.go
+	if strings.HasPrefix(omniType, "OrderedMap<") && strings.HasSuffix(omniType, ">") {
+		return "omni_ordered_map_t*"
+	}
+
 	// Handle array types: []<ElementType>
 	if strings.HasPrefix(omniType, "[]<") && strings.HasSuffix(omniType, ">") {
 		elementType := omniType[3 : len(omniType)-1]
@@ -2934,11 +3368,20 @@ func (g *CGenerator) mapType(omniType string) string {
 		return "omni_map_t*"
 	}
 
-	// Handle struct types: struct<Field1Type,Field2Type,...>
-	if strings.HasPrefix(omniType, "struct<") && strings.HasSuffix(omniType, ">") {
+	// Handle struct types: struct<Field1Type,Field2Type,...>, and tuples,
+	// which are structs with a field for each element
+	if (strings.HasPrefix(omniType, "struct<") || strings.HasPrefix(omniType, "tuple<")) && strings.HasSuffix(omniType, ">") {
 		return "omni_struct_t*"
 	}
 
+	if ifaceType, ok := g.interfaceType(omniType); ok {
+		return ifaceType
+	}
+	// Enum values are the int tags of their variants
+	if _, ok := g.module.Enum(omniType); ok {
+		return "int32_t"
+	}
+
 	// Handle named struct types (like Point, User, etc.)
 	// For now, assume any unknown type that's not a primitive is a struct
 	if !g.isPrimitiveType(omniType) && !strings.Contains(omniType, "(") && !strings.Contains(omniType, "<") {
@@ -2948,6 +3391,16 @@ func (g *CGenerator) mapType(omniType string) string {
 	switch omniType {
 	case "int":
 		return "int32_t"
+	case "int64", "long":
+		return "int64_t"
+	case "uint", "uint32":
+		return "uint32_t"
+	case "uint8":
+		return "uint8_t"
+	case "uint16":
+		return "uint16_t"
+	case "uint64":
+		return "uint64_t"
 	case "float", "double":
 		return "double"
 	case "string":
@@ -2968,7 +3421,7 @@ func (g *CGenerator) mapType(omniType string) string {
 		}
 		// Unknown type - this should not happen in valid programs
 		// Report error instead of silently defaulting to int32_t
-		g.errors = append(g.errors, fmt.Sprintf("unknown type: %s (cannot map to C type)", omniType))
+		g.error("unknown-type", fmt.Sprintf("unknown type: %s (cannot map to C type)", omniType))
 		return "int32_t" // Temporary fallback to allow compilation to continue
 	}
 }
@@ -3165,7 +3618,7 @@ func (g *CGenerator) generateCompleteFunctionSignature(returnType string, funcNa
 			cType.WriteString(g.mapFunctionTypeWithName(param.Type, param.Name))
 		} else {
 			paramType := g.mapType(param.Type)
-			cType.WriteString(fmt.Sprintf("%s %s", paramType, param.Name))
+			cType.WriteString(fmt.Sprintf("%s %s%s", paramType, param.Name, variadicCountParam(param)))
 		}
 	}
 
@@ -3246,6 +3699,14 @@ func (g *CGenerator) mapFunctionName(funcName string) string {
 		return "omni_cbrt"
 	case "std.math.trunc":
 		return "omni_trunc"
+	case "std.math.random":
+		return "omni_random_float"
+	case "std.math.random_int":
+		return "omni_random_int"
+	case "std.math.seed":
+		return "omni_random_seed"
+	case "std.math.random_string":
+		return "omni_random_string"
 	// Note: is_prime and fibonacci are implemented in OmniLang, not runtime
 	case "std.math.is_prime":
 		return "std_math_is_prime" // Will use OmniLang implementation
@@ -3418,8 +3879,20 @@ func (g *CGenerator) mapFunctionName(funcName string) string {
 		return "omni_print_string"
 	case "std.io.println":
 		return "omni_println_string"
+	case "std.io.write":
+		return "omni_write_string"
+	case "std.io.eprint":
+		return "omni_eprint_string"
+	case "std.io.eprintln":
+		return "omni_eprintln_string"
 	case "std.io.read_line":
 		return "omni_read_line"
+	case "std.io.prompt":
+		return "omni_prompt"
+	case "std.io.prompt_int":
+		return "omni_prompt_int"
+	case "std.io.prompt_float":
+		return "omni_prompt_float"
 
 	// Logging functions
 	case "std.log.debug":
@@ -3462,6 +3935,138 @@ func (g *CGenerator) mapFunctionName(funcName string) string {
 		return "omni_string_equals"
 	case "std.string.compare":
 		return "omni_string_compare"
+	case "std.string.split", "string.split":
+		return "omni_string_split"
+	case "std.string.format", "string.format":
+		return "omni_string_format"
+	case "std.string.repeat":
+		return "omni_string_repeat"
+	case "std.string.pad_left":
+		return "omni_string_pad_left"
+	case "std.string.pad_right":
+		return "omni_string_pad_right"
+	case "std.string.center":
+		return "omni_string_center"
+	case "std.string.replace":
+		return "omni_string_replace_first"
+	case "std.string.replace_all":
+		return "omni_string_replace_all"
+	case "std.string.count_occurrences":
+		return "omni_string_count_occurrences"
+
+	// Interpolation functions
+	case "std.math.interpolation.linear":
+		return "omni_interp_linear"
+	case "std.math.interpolation.lerp":
+		return "omni_interp_lerp"
+	case "std.math.interpolation.smooth_step":
+		return "omni_interp_smooth_step"
+	case "std.math.interpolation.cubic_spline":
+		return "omni_interp_cubic_spline"
+	case "std.math.interpolation.eval":
+		return "omni_interp_eval"
+
+	// Command history functions
+	case "std.io.readline_history.load":
+		return "omni_history_load"
+	case "std.io.readline_history.save":
+		return "omni_history_save"
+	case "std.io.readline_history.add":
+		return "omni_history_add"
+	case "std.io.readline_history.get":
+		return "omni_history_get"
+	case "std.io.readline_history.count":
+		return "omni_history_count"
+	case "std.io.readline_history.set_max_entries":
+		return "omni_history_set_max_entries"
+
+	// Diff functions
+	case "std.io.diff.unified":
+		return "omni_diff_unified"
+	case "std.io.diff.apply":
+		return "omni_diff_apply"
+
+	// Hash functions
+	case "std.hash.sha256":
+		return "omni_hash_sha256"
+	case "std.hash.sha512":
+		return "omni_hash_sha512"
+	case "std.hash.md5":
+		return "omni_hash_md5"
+	case "std.hash.fnv32":
+		return "omni_hash_fnv32"
+	case "std.hash.fnv64":
+		return "omni_hash_fnv64"
+	case "std.hash.murmur3":
+		return "omni_hash_murmur3"
+
+	// Regular expressions
+	case "std.regex.find":
+		return "omni_regex_find"
+	case "std.regex.find_all":
+		return "omni_regex_find_all"
+	case "std.regex.groups":
+		return "omni_regex_groups"
+
+	// Subprocesses
+	case "std.process.run", "std.process.run_async":
+		return "omni_process_run"
+
+	// Sorting
+	case "std.sort.ints", "std.sort.strings", "std.sort.floats", "std.sort.reverse_ints", "std.sort.reverse_strings":
+		return sortFunctions[funcName]
+
+	// Environment variables
+	case "std.env.get":
+		return "omni_env_get_string"
+	case "std.env.get_int":
+		return "omni_env_get_int"
+	case "std.env.get_bool":
+		return "omni_env_get_bool"
+	case "std.env.require":
+		return "omni_env_require"
+
+	// Paths
+	case "std.path.join":
+		return "omni_path_join"
+	case "std.path.clean":
+		return "omni_path_clean"
+	case "std.path.dirname":
+		return "omni_path_dirname"
+	case "std.path.basename":
+		return "omni_path_basename"
+	case "std.path.ext":
+		return "omni_path_ext"
+	case "std.path.abs":
+		return "omni_path_abs"
+	case "std.path.is_abs":
+		return "omni_path_is_abs"
+
+	// JSON functions
+	case "std.json.parse":
+		return "omni_json_parse"
+	case "std.json.stringify":
+		return "omni_json_stringify"
+
+	// Graph functions
+	case "std.collections.graph.create":
+		return "omni_graph_create"
+	case "std.collections.graph.add_edge":
+		return "omni_graph_add_edge"
+	case "std.collections.graph.has_edge":
+		return "omni_graph_has_edge"
+	case "std.collections.graph.node_count":
+		return "omni_graph_node_count"
+	case "std.collections.graph.edge_count":
+		return "omni_graph_edge_count"
+	case "std.collections.graph.total_weight":
+		return "omni_graph_total_weight"
+	case "std.collections.graph.mst":
+		return "omni_graph_mst"
+	case "std.collections.graph.topological_sort":
+		return "omni_graph_toposort"
+	case "std.collections.graph.strongly_connected_components":
+		return "omni_graph_scc"
 
 	// OS functions
 	case "std.os.exit":
@@ -3520,6 +4125,10 @@ func (g *CGenerator) mapFunctionName(funcName string) string {
 		return "omni_getpid"
 	case "os.getppid":
 		return "omni_getppid"
+	case "std.os.signal.trap":
+		return "omni_signal_trap"
+	case "std.os.signal.raise":
+		return "omni_signal_raise"
 	case "std.string.is_alpha":
 		return "omni_string_is_alpha"
 	case "std.string.is_digit":
@@ -3590,6 +4199,14 @@ func (g *CGenerator) mapFunctionName(funcName string) string {
 		return "omni_time_zone_offset"
 	case "std.time.time_zone_name":
 		return "omni_time_zone_name"
+	case "std.time.format.strftime":
+		return "omni_time_strftime"
+	case "std.time.format.strptime":
+		return "omni_time_strptime"
+	case "std.time.format":
+		return "omni_time_format"
+	case "std.time.parse":
+		return "omni_time_parse"
 	case "time.now":
 		return "omni_time_now_unix"
 	case "time.unix_timestamp":
@@ -3716,9 +4333,93 @@ func (g *CGenerator) hasRuntimeImplementation(funcName string) bool {
 		"std.io.println":   "omni_println_string",
 		"io.print":         "omni_print_string",
 		"io.println":       "omni_println_string",
+		"std.io.write":     "omni_write_string",
+		"std.io.eprint":    "omni_eprint_string",
+		"std.io.eprintln":  "omni_eprintln_string",
+		"io.write":         "omni_write_string",
+		"io.eprint":        "omni_eprint_string",
+		"io.eprintln":      "omni_eprintln_string",
 		"std.io.read_line": "omni_read_line",
 		"io.read_line":     "omni_read_line",
 
+		// Prompts
+		"std.io.prompt":       "omni_prompt",
+		"std.io.prompt_int":   "omni_prompt_int",
+		"std.io.prompt_float": "omni_prompt_float",
+
+		// Interpolation functions
+		"std.math.interpolation.linear":       "omni_interp_linear",
+		"std.math.interpolation.lerp":         "omni_interp_lerp",
+		"std.math.interpolation.smooth_step":  "omni_interp_smooth_step",
+		"std.math.interpolation.cubic_spline": "omni_interp_cubic_spline",
+		"std.math.interpolation.eval":         "omni_interp_eval",
+
+		// Command history functions
+		"std.io.readline_history.load":            "omni_history_load",
+		"std.io.readline_history.save":            "omni_history_save",
+		"std.io.readline_history.add":             "omni_history_add",
+		"std.io.readline_history.get":             "omni_history_get",
+		"std.io.readline_history.count":           "omni_history_count",
+		"std.io.readline_history.set_max_entries": "omni_history_set_max_entries",
+
+		// Diff functions
+		"std.io.diff.unified": "omni_diff_unified",
+		"std.io.diff.apply":   "omni_diff_apply",
+
+		// Hash functions
+		"std.hash.sha256":  "omni_hash_sha256",
+		"std.hash.sha512":  "omni_hash_sha512",
+		"std.hash.md5":     "omni_hash_md5",
+		"std.hash.fnv32":   "omni_hash_fnv32",
+		"std.hash.fnv64":   "omni_hash_fnv64",
+		"std.hash.murmur3": "omni_hash_murmur3",
+
+		// Regular expressions
+		"std.regex.find":     "omni_regex_find",
+		"std.regex.find_all": "omni_regex_find_all",
+		"std.regex.groups":   "omni_regex_groups",
+
+		// Subprocesses
+		"std.process.run":       "omni_process_run",
+		"std.process.run_async": "omni_process_run",
+
+		// Sorting
+		"std.sort.ints":            "omni_sort_ints",
+		"std.sort.strings":         "omni_sort_strings",
+		"std.sort.floats":          "omni_sort_floats",
+		"std.sort.reverse_ints":    "omni_sort_reverse_ints",
+		"std.sort.reverse_strings": "omni_sort_reverse_strings",
+
+		// Environment variables
+		"std.env.get":      "omni_env_get_string",
+		"std.env.get_int":  "omni_env_get_int",
+		"std.env.get_bool": "omni_env_get_bool",
+		"std.env.require":  "omni_env_require",
+
+		// Paths
+		"std.path.join":     "omni_path_join",
+		"std.path.clean":    "omni_path_clean",
+		"std.path.dirname":  "omni_path_dirname",
+		"std.path.basename": "omni_path_basename",
+		"std.path.ext":      "omni_path_ext",
+		"std.path.abs":      "omni_path_abs",
+		"std.path.is_abs":   "omni_path_is_abs",
+
+		// JSON functions
+		"std.json.parse":     "omni_json_parse",
+		"std.json.stringify": "omni_json_stringify",
+
+		// Graph functions
+		"std.collections.graph.create":                        "omni_graph_create",
+		"std.collections.graph.add_edge":                      "omni_graph_add_edge",
+		"std.collections.graph.has_edge":                      "omni_graph_has_edge",
+		"std.collections.graph.node_count":                    "omni_graph_node_count",
+		"std.collections.graph.edge_count":                    "omni_graph_edge_count",
+		"std.collections.graph.total_weight":                  "omni_graph_total_weight",
+		"std.collections.graph.mst":                           "omni_graph_mst",
+		"std.collections.graph.topological_sort":              "omni_graph_toposort",
+		"std.collections.graph.strongly_connected_components": "omni_graph_scc",
+
 		// String functions
 		"std.string.length":        "omni_strlen",
 		"std.string.concat":        "omni_strcat",
@@ -3734,6 +4435,14 @@ func (g *CGenerator) hasRuntimeImplementation(funcName string) bool {
 		"std.string.to_lower":      "omni_to_lower",
 		"std.string.equals":        "omni_string_equals",
 		"std.string.compare":       "omni_string_compare",
+		"std.string.split":         "omni_string_split",
+		"std.string.format":        "omni_string_format",
+		"std.string.repeat":        "omni_string_repeat",
+		"std.string.pad_left":      "omni_string_pad_left",
+		"std.string.pad_right":     "omni_string_pad_right",
+		"std.string.center":        "omni_string_center",
+		"std.string.replace":       "omni_string_replace_first",
+		"std.string.replace_all":   "omni_string_replace_all",
 		"string.length":            "omni_strlen",
 		"string.concat":            "omni_strcat",
 		"string.substring":         "omni_substring",
@@ -3748,6 +4457,16 @@ func (g *CGenerator) hasRuntimeImplementation(funcName string) bool {
 		"string.to_lower":          "omni_to_lower",
 		"string.equals":            "omni_string_equals",
 		"string.compare":           "omni_string_compare",
+		"string.split":             "omni_string_split",
+		"string.format":            "omni_string_format",
+
+		// Substring counting
+		"std.string.count_occurrences": "omni_string_count_occurrences",
+
+		// Parsing, emitted by emitStringParse
+		"std.string.parse_int":   "omni_parse_int",
+		"std.string.parse_float": "omni_parse_float",
+		"std.string.parse_bool":  "omni_parse_bool",
 
 		// Math functions (only those with runtime implementations)
 		"std.math.abs":       "omni_abs",
@@ -3805,6 +4524,16 @@ func (g *CGenerator) hasRuntimeImplementation(funcName string) bool {
 		"math.cbrt":          "omni_cbrt",
 		"math.trunc":         "omni_trunc",
 
+		// Random numbers
+		"std.math.random":        "omni_random_float",
+		"std.math.random_int":    "omni_random_int",
+		"std.math.seed":          "omni_random_seed",
+		"std.math.random_string": "omni_random_string",
+		"math.random":            "omni_random_float",
+		"math.random_int":        "omni_random_int",
+		"math.seed":              "omni_random_seed",
+		"math.random_string":     "omni_random_string",
+
 		// Type conversion functions
 		"std.int_to_string":   "omni_int_to_string",
 		"std.float_to_string": "omni_float_to_string",
@@ -3938,6 +4667,10 @@ func (g *CGenerator) hasRuntimeImplementation(funcName string) bool {
 		"std.time.time_from_string":   "omni_time_from_string",
 		"std.time.time_to_unix_nano":  "omni_time_to_unix_nano",
 		"std.time.duration_to_string": "omni_duration_to_string",
+		"std.time.format.strftime":    "omni_time_strftime",
+		"std.time.format.strptime":    "omni_time_strptime",
+		"std.time.format":             "omni_time_format",
+		"std.time.parse":              "omni_time_parse",
 		"time.now":                    "omni_time_now_unix",
 		"time.unix_timestamp":         "omni_time_now_unix",
 		"time.unix_nano":              "omni_time_now_unix_nano",
@@ -3970,6 +4703,10 @@ func (g *CGenerator) hasRuntimeImplementation(funcName string) bool {
 		"os.getpid":      "omni_getpid",
 		"os.getppid":     "omni_getppid",
 
+		// Signal functions
+		"std.os.signal.trap":  "omni_signal_trap",
+		"std.os.signal.raise": "omni_signal_raise",
+
 		// Collections functions
 		"std.collections.keys":   "omni_map_keys_string_int",
 		"std.collections.values": "omni_map_values_string_int",
@@ -4077,6 +4814,12 @@ func (g *CGenerator) isRuntimeProvidedFunction(funcName string) bool {
 		"std.io.println":           true,
 		"io.print":                 true,
 		"io.println":               true,
+		"std.io.write":             true,
+		"std.io.eprint":            true,
+		"std.io.eprintln":          true,
+		"io.write":                 true,
+		"io.eprint":                true,
+		"io.eprintln":              true,
 		"std.string.length":        true,
 		"std.string.concat":        true,
 		"std.string.substring":     true,
@@ -4091,6 +4834,8 @@ func (g *CGenerator) isRuntimeProvidedFunction(funcName string) bool {
 		"std.string.to_lower":      true,
 		"std.string.equals":        true,
 		"std.string.compare":       true,
+		"std.string.split":         true,
+		"std.string.format":        true,
 		"string.length":            true,
 		"string.concat":            true,
 		"string.substring":         true,
@@ -4105,6 +4850,8 @@ func (g *CGenerator) isRuntimeProvidedFunction(funcName string) bool {
 		"string.to_lower":          true,
 		"string.equals":            true,
 		"string.compare":           true,
+		"string.split":             true,
+		"string.format":            true,
 		"std.math.abs":             true,
 		"std.math.max":             true,
 		"std.math.min":             true,
@@ -4263,9 +5010,14 @@ func (g *CGenerator) isStringReturningFunction(funcName string) bool {
 	stringReturningFunctions := map[string]bool{
 		"std.io.read_line":     true,
 		"io.read_line":         true,
+		"std.io.prompt":        true,
 		"std.string.concat":    true,
 		"std.string.substring": true,
 		"std.string.trim":      true,
+		"std.string.repeat":    true,
+		"std.string.pad_left":  true,
+		"std.string.pad_right": true,
+		"std.string.center":    true,
 		"std.string.to_upper":  true,
 		"std.string.to_lower":  true,
 		"std.int_to_string":    true,
@@ -4273,7 +5025,21 @@ func (g *CGenerator) isStringReturningFunction(funcName string) bool {
 		"std.bool_to_string":   true,
 		"std.os.read_file":     true,
 		"os.read_file":         true,
+		"std.io.diff.unified":  true,
+		"std.io.diff.apply":    true,
+		"std.hash.sha256":      true,
+		"std.hash.sha512":      true,
+		"std.hash.md5":         true,
+		"std.json.stringify":   true,
+		"std.regex.find":       true,
+		"std.path.join":        true,
+		"std.path.clean":       true,
+		"std.path.dirname":     true,
+		"std.path.basename":    true,
+		"std.path.ext":         true,
+		"std.path.abs":         true,
 		"omni_read_line":       true,
+		"omni_prompt":          true,
 		"omni_strcat":          true,
 		"omni_substring":       true,
 		"omni_trim":            true,
@@ -4283,7 +5049,28 @@ func (g *CGenerator) isStringReturningFunction(funcName string) bool {
 		"omni_float_to_string": true,
 		"omni_bool_to_string":  true,
 		"omni_read_file":       true,
+		"omni_diff_unified":    true,
+		"omni_diff_apply":      true,
+		"omni_hash_sha256":     true,
+		"omni_hash_sha512":     true,
+		"omni_hash_md5":        true,
 		"omni_await_string":    true,
+
+		// Substring replacement
+		"std.string.replace":        true,
+		"std.string.replace_all":    true,
+		"omni_string_replace_first": true,
+		"omni_string_replace_all":   true,
+
+		// Random strings
+		"std.math.random_string": true,
+		"math.random_string":     true,
+		"omni_random_string":     true,
+
+		// Time formatting
+		"std.time.format.strftime": true,
+		"std.time.format":          true,
+		"omni_time_strftime":       true,
 	}
 	return stringReturningFunctions[funcName]
 }
@@ -4326,13 +5113,39 @@ func (g *CGenerator) declareVariable(varName string) {
 // isPrimitiveType checks if a type is a primitive type
 func (g *CGenerator) isPrimitiveType(omniType string) bool {
 	switch omniType {
-	case "int", "float", "double", "string", "void", "void*", "bool", "ptr":
+	case "int", "int64", "long", "float", "double", "string", "void", "void*", "bool", "ptr", "any":
+		return true
+	default:
+		return isUnsignedType(omniType)
+	}
+}
+
+// isUnsignedType reports whether omniType is one of the unsigned integer
+// types.
+func isUnsignedType(omniType string) bool {
+	switch omniType {
+	case "uint", "uint8", "uint16", "uint32", "uint64":
 		return true
 	default:
 		return false
 	}
 }
 
+// unsignedLiteral renders literal as a C constant of the unsigned type
+// omniType. Binary literals are converted to decimal, as C has no 0b prefix
+// before C23.
+func unsignedLiteral(literal, omniType string) string {
+	if strings.HasPrefix(literal, "0b") || strings.HasPrefix(literal, "0B") {
+		if val, err := strconv.ParseUint(strings.ReplaceAll(literal[2:], "_", ""), 2, 64); err == nil {
+			literal = strconv.FormatUint(val, 10)
+		}
+	}
+	if omniType == "uint64" {
+		return literal + "ULL"
+	}
+	return literal + "U"
+}
+
 // extractMapTypes extracts key and value types from a map type string
 func (g *CGenerator) extractMapTypes(mapType string) (keyType, valueType string) {
 	if !strings.HasPrefix(mapType, "map<") || !strings.HasSuffix(mapType, ">") {
@@ -4467,6 +5280,16 @@ func (g *CGenerator) getMapGetFunction(keyType, valueType string) string {
 	return "" // Unsupported combination
 }
 
+// getMapContainsFunction returns the map contains function name for the
+// given key and value types, or "" for unsupported combinations.
+func (g *CGenerator) getMapContainsFunction(keyType, valueType string) string {
+	getFunc := g.getMapGetFunction(keyType, valueType)
+	if getFunc == "" {
+		return ""
+	}
+	return strings.Replace(getFunc, "omni_map_get_", "omni_map_contains_", 1)
+}
+
 // convertLiteralToDecimal converts hex and binary literals to decimal
 func (g *CGenerator) convertLiteralToDecimal(literal string) string {
 	if strings.HasPrefix(literal, "0x") || strings.HasPrefix(literal, "0X") {
@@ -4535,3 +5358,15 @@ func (g *CGenerator) writeMain() {
 
 	g.output.WriteString("}\n")
 }
+
+// namedReturnSlot returns the slot and name of the named return value of
+// fn, which its ret.named terminators return.
+func namedReturnSlot(fn *mir.Function) (mir.ValueID, string, bool) {
+	for _, block := range fn.Blocks {
+		term := block.Terminator
+		if term.Op == "ret.named" && len(term.Operands) == 2 && term.Operands[0].Kind == mir.OperandValue {
+			return term.Operands[0].Value, term.Operands[1].Literal, true
+		}
+	}
+	return mir.InvalidValue, "", false
+}
@@ -507,6 +507,13 @@ func TestCGenerator(t *testing.T) {
 			expected string
 		}{
 			{"int", "int32_t"},
+			{"int64", "int64_t"},
+			{"long", "int64_t"},
+			{"uint", "uint32_t"},
+			{"uint8", "uint8_t"},
+			{"uint16", "uint16_t"},
+			{"uint32", "uint32_t"},
+			{"uint64", "uint64_t"},
 			{"float", "double"},
 			{"double", "double"},
 			{"string", "const char*"},
@@ -2223,7 +2230,7 @@ func TestCGenerator(t *testing.T) {
 		}
 
 		// Should have a warning about unknown length
-		if len(generator.errors) == 0 {
+		if len(generator.Diagnostics()) == 0 {
 			t.Error("Expected warning about unknown array length")
 		}
 	})
@@ -2645,7 +2652,7 @@ func TestCGenerator(t *testing.T) {
 		}
 
 		// Should have an error about unsupported type
-		if len(generator.errors) == 0 {
+		if len(generator.Diagnostics()) == 0 {
 			t.Error("Expected error about unsupported map type")
 		}
 	})
@@ -3073,7 +3080,7 @@ func TestCGenerator(t *testing.T) {
 			t.Error("Expected error for unknown terminator")
 		}
 
-		if len(generator.errors) == 0 {
+		if len(generator.Diagnostics()) == 0 {
 			t.Error("Expected error message for unknown terminator")
 		}
 	})
@@ -3714,7 +3721,7 @@ func TestCGenerator(t *testing.T) {
 			t.Error("Expected error for unsupported closure instruction")
 		}
 
-		if len(generator.errors) == 0 {
+		if len(generator.Diagnostics()) == 0 {
 			t.Error("Expected error to be recorded")
 		}
 	})
@@ -3820,3 +3827,93 @@ func TestCGenerator(t *testing.T) {
 		}
 	})
 }
+
+// TestDeferRunsBeforeEachReturn checks that both returns of a function with
+// a defer statement run the calls pushed on its defer frame, and that the
+// deferred call is made by a thunk with the argument captured at the defer
+// statement.
+func TestDeferRunsBeforeEachReturn(t *testing.T) {
+	fn := mir.NewFunction("pick", "int", []mir.Param{{Name: "f", Type: "int"}})
+	entry := fn.NewBlock("entry")
+	left := fn.NewBlock("deferred_0")
+	right := fn.NewBlock("deferred_1")
+
+	closed, cond := fn.NextValue(), fn.NextValue()
+	param := mir.Operand{Kind: mir.OperandValue, Value: fn.Params[0].ID, Type: "int"}
+	entry.Instructions = []mir.Instruction{
+		{ID: closed, Op: "defer.push", Type: "int", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "std.file.close"}, param}},
+		{ID: cond, Op: "cmp.gt", Type: "bool", Operands: []mir.Operand{param, {Kind: mir.OperandLiteral, Literal: "0", Type: "int"}}},
+	}
+	entry.Terminator = mir.Terminator{Op: "cbr", Operands: []mir.Operand{
+		{Kind: mir.OperandValue, Value: cond, Type: "bool"}, {Kind: mir.OperandLiteral, Literal: "deferred_0"}, {Kind: mir.OperandLiteral, Literal: "deferred_1"},
+	}}
+	for i, block := range []*mir.BasicBlock{left, right} {
+		block.Instructions = []mir.Instruction{{ID: mir.InvalidValue, Op: "defer.run", Type: "void"}}
+		block.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: string(rune('1' + i)), Type: "int"}}}
+	}
+
+	code, err := GenerateC(&mir.Module{Functions: []*mir.Function{fn}})
+	if err != nil {
+		t.Fatalf("GenerateC failed: %v", err)
+	}
+	for _, want := range []string{
+		"omni_defer_top = &omni_defers;",
+		"int32_t f = omni_env->a0;",
+		"omni_env->a0 = f;",
+		"omni_defer_push(&omni_defers, omni_defer_pick_0, omni_env);",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated C missing %q:\n%s", want, code)
+		}
+	}
+	if got := strings.Count(code, "omni_defer_unwind(omni_defers.prev);"); got != 2 {
+		t.Errorf("deferred calls run %d times, want once per return:\n%s", got, code)
+	}
+	if got := strings.Count(code, "omni_file_close("); got != 1 {
+		t.Errorf("deferred close emitted %d times, want once in the thunk:\n%s", got, code)
+	}
+	if thunk, fn := strings.Index(code, "static void omni_defer_pick_0("), strings.Index(code, "int32_t pick(int32_t f) {"); thunk < 0 || thunk > fn {
+		t.Errorf("thunk not defined before the function:\n%s", code)
+	}
+}
+
+// TestGlobalsAtFileScope checks that globals are declared before the
+// functions that load and store them.
+func TestGlobalsAtFileScope(t *testing.T) {
+	fn := mir.NewFunction("bump", "int", nil)
+	entry := fn.NewBlock("entry")
+	loaded, sum := fn.NextValue(), fn.NextValue()
+	entry.Instructions = []mir.Instruction{
+		{ID: loaded, Op: "global.load", Type: "int", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "count"}}},
+		{ID: sum, Op: "add", Type: "int", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: loaded, Type: "int"}, {Kind: mir.OperandLiteral, Literal: "1", Type: "int"}}},
+		{ID: mir.InvalidValue, Op: "global.store", Type: "int", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "count"}, {Kind: mir.OperandValue, Value: sum, Type: "int"}}},
+	}
+	entry.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: sum, Type: "int"}}}
+	module := &mir.Module{
+		Functions: []*mir.Function{fn},
+		Globals: []mir.GlobalVar{
+			{Name: "count", Type: "int", InitValue: mir.Operand{Kind: mir.OperandLiteral, Literal: "0x10", Type: "int"}},
+			{Name: "big", Type: "int64", InitValue: mir.Operand{Kind: mir.OperandLiteral, Literal: "-5", Type: "int64"}},
+			{Name: "name", Type: "string", InitValue: mir.Operand{Kind: mir.OperandLiteral, Literal: `"omni"`, Type: "string"}},
+		},
+	}
+
+	code, err := GenerateC(module)
+	if err != nil {
+		t.Fatalf("GenerateC failed: %v", err)
+	}
+	for _, want := range []string{
+		"static int32_t omni_global_count = 16;",
+		"static int64_t omni_global_big = -5LL;",
+		`static const char* omni_global_name = "omni";`,
+		"v0 = omni_global_count;",
+		"omni_global_count = v1;",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated C missing %q:\n%s", want, code)
+		}
+	}
+	if strings.Index(code, "omni_global_count = 16") > strings.Index(code, "int32_t bump(") {
+		t.Errorf("global declared after the function declarations:\n%s", code)
+	}
+}
@@ -0,0 +1,93 @@
+package cbackend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// collectionsCallback reports whether inst calls one of the std.collections
+// functions that call a function value for each array element. They are
+// written as loops calling the function pointer, as func.call does.
+func collectionsCallback(inst *mir.Instruction) (string, bool) {
+	callee, ok := inst.CallTarget()
+	if !ok {
+		return "", false
+	}
+	switch callee {
+	case "std.collections.filter", "std.collections.map_fn", "std.collections.reduce":
+		return callee, true
+	}
+	return "", false
+}
+
+// collectionsArrayCall reports whether inst builds a new array with filter
+// or map_fn. The array carries its length in a variable of arrayCounts.
+func collectionsArrayCall(inst *mir.Instruction) bool {
+	callee, ok := collectionsCallback(inst)
+	return ok && callee != "std.collections.reduce"
+}
+
+// emitCollectionsCallback writes a call of filter, map_fn or reduce as a for
+// loop over the elements of the array.
+func (g *CGenerator) emitCollectionsCallback(inst *mir.Instruction, callee string) {
+	name := strings.TrimPrefix(callee, "std.")
+	want := 3
+	if callee == "std.collections.reduce" {
+		want = 4
+	}
+	if len(inst.Operands) != want {
+		g.error("invalid-call", fmt.Sprintf("%s: expected %d arguments, got %d", name, want-1, len(inst.Operands)-1))
+		return
+	}
+	arr := inst.Operands[1]
+	count, ok := g.arrayLength(arr)
+	if !ok {
+		count = "0"
+		g.error("unknown-array-length", fmt.Sprintf("array length not known for %s - %s requires an array of known length", g.getOperandValue(arr), name))
+	}
+	varName := g.getVariableName(inst.ID)
+	arrVar := g.getOperandValue(arr)
+	fn := g.getOperandValue(inst.Operands[len(inst.Operands)-1])
+	index := varName + "_i"
+	elem := fmt.Sprintf("%s[%s]", arrVar, index)
+
+	switch callee {
+	case "std.collections.filter", "std.collections.map_fn":
+		outCount := g.arrayCounts[inst.ID]
+		elemType := g.mapType(elementTypeOf(inst.Type))
+		if g.arrayAllocsToFree[inst.ID] {
+			// Release the array from a previous loop iteration, as for
+			// array.init
+			g.output.WriteString(fmt.Sprintf("  free(%s);\n", varName))
+		}
+		// One slot at least, so that an empty result is not NULL
+		g.output.WriteString(fmt.Sprintf("  %s = malloc(sizeof(%s) * (%s > 0 ? %s : 1));\n", varName, elemType, count, count))
+		g.output.WriteString(fmt.Sprintf("  %s = 0;\n", outCount))
+		g.output.WriteString(fmt.Sprintf("  for (int32_t %s = 0; %s < %s; %s++) {\n", index, index, count, index))
+		if callee == "std.collections.filter" {
+			g.output.WriteString(fmt.Sprintf("    if (%s(%s)) {\n", fn, elem))
+			g.output.WriteString(fmt.Sprintf("      %s[%s++] = %s;\n", varName, outCount, elem))
+			g.output.WriteString("    }\n")
+		} else {
+			g.output.WriteString(fmt.Sprintf("    %s[%s++] = %s(%s);\n", varName, outCount, fn, elem))
+		}
+		g.output.WriteString("  }\n")
+	default:
+		g.output.WriteString(fmt.Sprintf("  %s = %s;\n", varName, g.getOperandValue(inst.Operands[2])))
+		g.output.WriteString(fmt.Sprintf("  for (int32_t %s = 0; %s < %s; %s++) {\n", index, index, count, index))
+		g.output.WriteString(fmt.Sprintf("    %s = %s(%s, %s);\n", varName, fn, varName, elem))
+		g.output.WriteString("  }\n")
+	}
+}
+
+// elementTypeOf returns the element type of an array<T> or []<T> type.
+func elementTypeOf(arrayType string) string {
+	for _, prefix := range []string{"array<", "[]<"} {
+		if strings.HasPrefix(arrayType, prefix) && strings.HasSuffix(arrayType, ">") {
+			return strings.TrimSpace(arrayType[len(prefix) : len(arrayType)-1])
+		}
+	}
+	return inferTypePlaceholder
+}
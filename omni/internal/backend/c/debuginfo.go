@@ -0,0 +1,51 @@
+package cbackend
+
+import (
+	"fmt"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// writeDebugSymbols writes one debug_symbols entry per generated function,
+// locating its declaration in the source file.
+func (g *CGenerator) writeDebugSymbols() {
+	for _, fn := range g.module.Functions {
+		if g.isRuntimeProvidedFunction(fn.Name) {
+			continue
+		}
+		g.output.WriteString(fmt.Sprintf("    { \"%s\", %d, %d, \"%s\" },\n",
+			g.sourceFile, debugLine(fn), fn.Span.Start.Column, fn.Name))
+	}
+}
+
+// debugLocation returns the annotate marker for fn, "omni:loc:file:line".
+func (g *CGenerator) debugLocation(fn *mir.Function) string {
+	return fmt.Sprintf("omni:loc:%s:%d", g.sourceFile, debugLine(fn))
+}
+
+// debugLine returns the source line of fn's declaration, or 1 when fn has
+// no span.
+func debugLine(fn *mir.Function) int {
+	if fn.Span.Start.Line > 0 {
+		return fn.Span.Start.Line
+	}
+	return 1
+}
+
+// writeLineDirective points the C that follows at loc with a #line
+// directive, so that debuggers and C compiler messages report the OmniLang
+// source line. Unknown locations are skipped.
+func (g *CGenerator) writeLineDirective(loc mir.SourceLocation) {
+	if loc.IsZero() {
+		return
+	}
+	file := loc.File
+	if file == "" {
+		file = g.sourceFile
+	}
+	if file == "" {
+		g.output.WriteString(fmt.Sprintf("  #line %d\n", loc.Line))
+		return
+	}
+	g.output.WriteString(fmt.Sprintf("  #line %d \"%s\"\n", loc.Line, file))
+}
@@ -0,0 +1,68 @@
+package cbackend
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/lexer"
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+func TestDebugInfoLocatesFunctions(t *testing.T) {
+	fn := mir.NewFunction("answer", "int", nil)
+	fn.Span = lexer.Span{Start: lexer.Position{Line: 7, Column: 1}}
+	entry := fn.NewBlock("entry")
+	entry.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "42", Type: "int"}}}
+	mod := &mir.Module{Functions: []*mir.Function{fn}}
+
+	code, err := GenerateCWithDebug(mod, "O0", true, "answer.omni")
+	if err != nil {
+		t.Fatalf("GenerateCWithDebug failed: %v", err)
+	}
+	for _, want := range []string{
+		`{ "answer.omni", 7, 1, "answer" },`,
+		"#line 7 \"answer.omni\"\nOMNI_DEBUG_LOC(\"omni:loc:answer.omni:7\") int32_t answer(",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated C missing %q:\n%s", want, code)
+		}
+	}
+
+	plain, err := GenerateC(mod)
+	if err != nil {
+		t.Fatalf("GenerateC failed: %v", err)
+	}
+	if strings.Contains(plain, "OMNI_DEBUG_LOC") {
+		t.Error("location markers emitted without debug info")
+	}
+}
+
+func TestDebugInfoEmitsLineDirectives(t *testing.T) {
+	fn := mir.NewFunction("main", "int", nil)
+	entry := fn.NewBlock("entry")
+	id := fn.NextValue()
+	entry.Instructions = []mir.Instruction{{ID: id, Op: "const", Type: "int",
+		Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "1", Type: "int"}},
+		Location: mir.SourceLocation{File: "lib.omni", Line: 3, Column: 5}}}
+	entry.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: id, Type: "int"}},
+		Location: mir.SourceLocation{Line: 4, Column: 5}}
+	mod := &mir.Module{Functions: []*mir.Function{fn}}
+
+	code, err := GenerateCWithDebug(mod, "O0", true, "main.omni")
+	if err != nil {
+		t.Fatalf("GenerateCWithDebug failed: %v", err)
+	}
+	for _, want := range []string{"#line 3 \"lib.omni\"\n", "#line 4 \"main.omni\"\n"} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated C missing %q:\n%s", want, code)
+		}
+	}
+
+	plain, err := GenerateC(mod)
+	if err != nil {
+		t.Fatalf("GenerateC failed: %v", err)
+	}
+	if strings.Contains(plain, "#line 3") {
+		t.Error("#line directives emitted without debug info")
+	}
+}
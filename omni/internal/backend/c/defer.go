@@ -0,0 +1,154 @@
+package cbackend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// hasDefers reports whether fn has a defer statement. It then pushes a
+// frame for its deferred calls at entry, which omni_throw unwinds when an
+// exception leaves the function.
+func hasDefers(fn *mir.Function) bool {
+	for _, block := range fn.Blocks {
+		for _, inst := range block.Instructions {
+			if inst.Op == "defer.push" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// generateDeferPush writes defer.push, which copies the arguments of the
+// deferred call into an environment and pushes it with a thunk that makes
+// the call, as the VM evaluates them at the defer statement. A defer
+// statement in a loop pushes a call for every iteration.
+func (g *CGenerator) generateDeferPush(inst *mir.Instruction) error {
+	thunk := fmt.Sprintf("omni_defer_%s_%d", g.mapFunctionName(g.currentFunction), g.deferPushed)
+	g.deferPushed++
+
+	var fields, loads, stores []string
+	capture := func(cType, name, value string) {
+		field := fmt.Sprintf("a%d", len(fields))
+		fields = append(fields, fmt.Sprintf("  %s;\n", declaration(cType, field)))
+		loads = append(loads, fmt.Sprintf("  %s = omni_env->%s;\n", declaration(cType, name), field))
+		stores = append(stores, fmt.Sprintf("  omni_env->%s = %s;\n", field, value))
+	}
+	captured := make(map[mir.ValueID]bool)
+	for _, operand := range inst.Operands {
+		if operand.Kind != mir.OperandValue || captured[operand.Value] {
+			continue
+		}
+		captured[operand.Value] = true
+		name := g.getVariableName(operand.Value)
+		capture(g.deferredType(operand), name, name)
+		if count, ok := g.arrayCounts[operand.Value]; ok && isIdentifier(count) {
+			capture("int32_t", count, count)
+		}
+	}
+
+	// The call is generated as usual, into the body of the thunk
+	call := *inst
+	call.Op = "call"
+	if len(call.Operands) > 0 && call.Operands[0].Kind != mir.OperandLiteral {
+		call.Op = "func.call"
+	}
+	code := g.output.String()
+	g.output.Reset()
+	temps := len(g.tempStringsToFree)
+	ownedResult := g.stringsToFree[call.ID]
+	err := g.generateInstruction(&call)
+	body := g.output.String()
+	g.output.Reset()
+	g.output.WriteString(code)
+	if err != nil {
+		return err
+	}
+
+	env := thunk + "_env"
+	if len(fields) > 0 {
+		g.deferThunks.WriteString(fmt.Sprintf("typedef struct {\n%s} %s;\n\n", strings.Join(fields, ""), env))
+	}
+	g.deferThunks.WriteString(fmt.Sprintf("static void %s(void* omni_env_ptr) {\n", thunk))
+	if len(fields) > 0 {
+		g.deferThunks.WriteString(fmt.Sprintf("  %s* omni_env = (%s*)omni_env_ptr;\n", env, env))
+		g.deferThunks.WriteString(strings.Join(loads, ""))
+	} else {
+		g.deferThunks.WriteString("  (void)omni_env_ptr;\n")
+	}
+	if call.ID != mir.InvalidValue {
+		if cType := g.mapType(call.Type); cType != "void" {
+			g.deferThunks.WriteString(fmt.Sprintf("  %s;\n", declaration(cType, g.getVariableName(call.ID))))
+		}
+	}
+	g.deferThunks.WriteString(body)
+	// What the call allocated is freed by the thunk rather than by the
+	// function that deferred it
+	if call.ID != mir.InvalidValue && g.stringsToFree[call.ID] && !ownedResult {
+		delete(g.stringsToFree, call.ID)
+		varName := g.getVariableName(call.ID)
+		g.deferThunks.WriteString(fmt.Sprintf("  if (%s != NULL) { free((void*)%s); }\n", varName, varName))
+	}
+	for _, temp := range g.tempStringsToFree[temps:] {
+		g.deferThunks.WriteString(fmt.Sprintf("  if (%s != NULL) { free((void*)%s); }\n", temp, temp))
+	}
+	g.tempStringsToFree = g.tempStringsToFree[:temps]
+	g.deferThunks.WriteString("}\n\n")
+
+	if len(fields) == 0 {
+		g.output.WriteString(fmt.Sprintf("  omni_defer_push(&omni_defers, %s, NULL);\n", thunk))
+		return nil
+	}
+	g.output.WriteString(fmt.Sprintf("  {\n  %s* omni_env = (%s*)malloc(sizeof(%s));\n", env, env, env))
+	g.output.WriteString(strings.Join(stores, ""))
+	g.output.WriteString(fmt.Sprintf("  omni_defer_push(&omni_defers, %s, omni_env);\n  }\n", thunk))
+	return nil
+}
+
+// deferredType returns the C type of the captured argument op.
+func (g *CGenerator) deferredType(op mir.Operand) string {
+	typ := op.Type
+	if stored, ok := g.valueTypes[op.Value]; ok && stored != "" && stored != inferTypePlaceholder {
+		typ = stored
+	}
+	if strings.Contains(typ, ") -> ") {
+		return g.mapFunctionTypeWithName(typ, "%s")
+	}
+	return g.mapType(typ)
+}
+
+// declaration declares name with the C type cType, which holds a %s verb
+// where the name goes for function pointer types.
+func declaration(cType, name string) string {
+	if strings.Contains(cType, "%s") {
+		return fmt.Sprintf(cType, name)
+	}
+	return cType + " " + name
+}
+
+// isIdentifier reports whether s is a C identifier rather than an
+// expression.
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if r != '_' && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') && (i == 0 || !('0' <= r && r <= '9')) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// insertDeferThunks writes the thunks of the deferred calls of the function
+// whose definition starts at offset start in front of it.
+func (g *CGenerator) insertDeferThunks(start int) {
+	if g.deferThunks.Len() == 0 {
+		return
+	}
+	code := g.output.String()
+	g.output.Reset()
+	g.output.WriteString(code[:start])
+	g.output.WriteString(g.deferThunks.String())
+	g.output.WriteString(code[start:])
+	g.deferThunks.Reset()
+}
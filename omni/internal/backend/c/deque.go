@@ -0,0 +1,78 @@
+package cbackend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// dequeCall reports whether inst calls one of the std.collections.deque
+// functions, returning the function name without the module prefix.
+func dequeCall(inst *mir.Instruction) (string, bool) {
+	callee, ok := inst.CallTarget()
+	if !ok || !strings.HasPrefix(callee, "std.collections.deque.") {
+		return "", false
+	}
+	return strings.TrimPrefix(callee, "std.collections.deque."), true
+}
+
+// dequeValueField returns the member of omni_deque_value that holds values of
+// the OmniLang type elemType.
+func dequeValueField(elemType string) (string, bool) {
+	switch elemType {
+	case "int", "bool":
+		return "i", true
+	case "float", "double":
+		return "f", true
+	case "string":
+		return "s", true
+	}
+	return "", false
+}
+
+// emitDequeCall writes a call of a deque function. Elements travel through
+// the omni_deque_value union, so pushes build one from the value and pops
+// and peeks read the member for the element type.
+func (g *CGenerator) emitDequeCall(inst *mir.Instruction, op string) {
+	runtimeFunc := "omni_deque_" + op
+	varName := g.getVariableName(inst.ID)
+	switch op {
+	case "create":
+		g.output.WriteString(fmt.Sprintf("  %s = %s();\n", varName, runtimeFunc))
+		return
+	case "push_front", "push_back", "pop_front", "pop_back", "peek_front", "peek_back", "size", "is_empty":
+	default:
+		g.error("invalid-call", fmt.Sprintf("collections.deque.%s is not a deque function", op))
+		return
+	}
+	want := 2
+	if op == "push_front" || op == "push_back" {
+		want = 3
+	}
+	if len(inst.Operands) != want {
+		g.error("invalid-call", fmt.Sprintf("collections.deque.%s: expected %d arguments, got %d", op, want-1, len(inst.Operands)-1))
+		return
+	}
+	d := g.getOperandValue(inst.Operands[1])
+
+	switch op {
+	case "push_front", "push_back":
+		val := inst.Operands[2]
+		field, ok := dequeValueField(val.Type)
+		if !ok {
+			g.error("unsupported-deque-element", fmt.Sprintf("collections.deque.%s: deques of %s are not supported by the C backend", op, val.Type))
+			return
+		}
+		g.output.WriteString(fmt.Sprintf("  %s(%s, (omni_deque_value){.%s = %s});\n", runtimeFunc, d, field, g.getOperandValue(val)))
+	case "size", "is_empty":
+		g.output.WriteString(fmt.Sprintf("  %s = %s(%s);\n", varName, runtimeFunc, d))
+	default:
+		field, ok := dequeValueField(inst.Type)
+		if !ok {
+			g.error("unsupported-deque-element", fmt.Sprintf("collections.deque.%s: deques of %s are not supported by the C backend", op, inst.Type))
+			return
+		}
+		g.output.WriteString(fmt.Sprintf("  %s = %s(%s).%s;\n", varName, runtimeFunc, d, field))
+	}
+}
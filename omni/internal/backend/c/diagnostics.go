@@ -0,0 +1,81 @@
+package cbackend
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DiagLevel is the severity of a code generation diagnostic.
+type DiagLevel int
+
+const (
+	// LevelError marks a construct the generator could not translate; the
+	// generated C is unusable and Generate fails.
+	LevelError DiagLevel = iota
+	// LevelWarning marks C that compiles but may not behave like the VM.
+	LevelWarning
+)
+
+func (l DiagLevel) String() string {
+	if l == LevelWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// CodeGenDiag is a diagnostic reported while generating C.
+type CodeGenDiag struct {
+	Level   DiagLevel
+	Code    string // stable identifier such as "unknown-type"
+	Message string
+	// Location names the function, and the block when known, being
+	// generated ("main" or "main:loop_body_1").
+	Location string
+}
+
+// Error formats d as "location: level [code]: message".
+func (d CodeGenDiag) Error() string {
+	prefix := ""
+	if d.Location != "" {
+		prefix = d.Location + ": "
+	}
+	return fmt.Sprintf("%s%s [%s]: %s", prefix, d.Level, d.Code, d.Message)
+}
+
+// Diagnostics returns the errors and warnings reported while generating, in
+// the order they were found. Generate fails if any of them is an error.
+func (g *CGenerator) Diagnostics() []CodeGenDiag {
+	return g.diags
+}
+
+// error reports a construct that cannot be translated to C.
+func (g *CGenerator) error(code, msg string) {
+	g.diags = append(g.diags, CodeGenDiag{Level: LevelError, Code: code, Message: msg, Location: g.location()})
+}
+
+// warn reports generated C that may not behave as intended.
+func (g *CGenerator) warn(code, msg string) {
+	g.diags = append(g.diags, CodeGenDiag{Level: LevelWarning, Code: code, Message: msg, Location: g.location()})
+}
+
+func (g *CGenerator) location() string {
+	if g.currentFunction == "" || g.currentBlock == "" {
+		return g.currentFunction
+	}
+	return g.currentFunction + ":" + g.currentBlock
+}
+
+// diagError joins the error-level diagnostics into one error, or returns nil
+// when there are none.
+func (g *CGenerator) diagError() error {
+	var errs []error
+	for _, d := range g.diags {
+		if d.Level == LevelError {
+			errs = append(errs, d)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("code generation errors:\n%w", errors.Join(errs...))
+}
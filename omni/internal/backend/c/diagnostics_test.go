@@ -0,0 +1,51 @@
+package cbackend
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/mir/builder"
+	"github.com/omni-lang/omni/internal/parser"
+)
+
+func TestWarningsDoNotFailGeneration(t *testing.T) {
+	src := "func first(xs:array<int>):int {\n    return xs[0]\n}\n\nfunc main():int {\n    return 0\n}\n"
+	mod, err := parser.Parse("first.omni", src)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	mirMod, err := builder.BuildModule(mod)
+	if err != nil {
+		t.Fatalf("build MIR: %v", err)
+	}
+
+	gen := NewCGenerator(mirMod)
+	if _, err := gen.Generate(); err != nil {
+		t.Fatalf("Generate failed on a warning: %v", err)
+	}
+	diags := gen.Diagnostics()
+	if len(diags) != 1 {
+		t.Fatalf("got %d diagnostics, want 1: %v", len(diags), diags)
+	}
+	if d := diags[0]; d.Level != LevelWarning || d.Code != "unknown-array-length" || d.Location != "first:entry" {
+		t.Errorf("diagnostic = %+v, want an unknown-array-length warning in first:entry", d)
+	}
+}
+
+func TestErrorsFailGeneration(t *testing.T) {
+	fn := mir.NewFunction("main", "int", nil)
+	fn.NewBlock("entry").Terminator = mir.Terminator{Op: "bogus"}
+
+	gen := NewCGenerator(&mir.Module{Functions: []*mir.Function{fn}})
+	_, err := gen.Generate()
+	if err == nil {
+		t.Fatal("Generate succeeded with an unsupported terminator")
+	}
+	if want := "main:entry: error [unsupported-terminator]: unsupported MIR terminator: bogus"; !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want it to contain %q", err, want)
+	}
+	if diags := gen.Diagnostics(); len(diags) != 1 || diags[0].Level != LevelError {
+		t.Errorf("diagnostics = %v, want one error", diags)
+	}
+}
@@ -0,0 +1,37 @@
+package cbackend
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// SetDivCheck makes every division and modulo check its divisor and stop
+// the program through omni_panic when it is zero, as debug builds always do.
+func (g *CGenerator) SetDivCheck(on bool) {
+	g.divCheck = on
+}
+
+// emitDivisionCheck writes the zero test of the divisor of the div, mod,
+// udiv or umod instruction inst, when debug information or SetDivCheck asks
+// for it. A literal divisor other than zero needs no test.
+func (g *CGenerator) emitDivisionCheck(inst *mir.Instruction, divisor string) {
+	if !g.debugInfo && !g.divCheck {
+		return
+	}
+	if operand := inst.Operands[1]; operand.Kind == mir.OperandLiteral {
+		if value, err := strconv.ParseFloat(operand.Literal, 64); err == nil && value != 0 {
+			return
+		}
+	}
+	what := "division"
+	if inst.Op == "mod" || inst.Op == "umod" {
+		what = "modulo"
+	}
+	message := fmt.Sprintf("%s by zero in %s", what, g.currentFunction)
+	if !inst.Location.IsZero() {
+		message += " at " + inst.Location.String()
+	}
+	g.output.WriteString(fmt.Sprintf("  if (%s == 0) { omni_panic(%s); }\n", divisor, strconv.Quote(message)))
+}
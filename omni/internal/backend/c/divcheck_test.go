@@ -0,0 +1,75 @@
+package cbackend
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// divisionModule builds a function ratio that computes 7 op b for a b
+// loaded from a constant, so that the divisor is not a literal.
+func divisionModule(op string) *mir.Module {
+	fn := mir.NewFunction("ratio", "int", nil)
+	entry := fn.NewBlock("entry")
+	divisor, result := fn.NextValue(), fn.NextValue()
+	entry.Instructions = []mir.Instruction{
+		{ID: divisor, Op: "const", Type: "int", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "0", Type: "int"}}},
+		{ID: result, Op: op, Type: "int", Operands: []mir.Operand{
+			{Kind: mir.OperandLiteral, Literal: "7", Type: "int"},
+			{Kind: mir.OperandValue, Value: divisor, Type: "int"},
+		}, Location: mir.SourceLocation{File: "ratio.omni", Line: 3, Column: 15}},
+	}
+	entry.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: result, Type: "int"}}}
+	return &mir.Module{Functions: []*mir.Function{fn}}
+}
+
+func TestDivCheck(t *testing.T) {
+	for op, want := range map[string]string{
+		"div": `omni_panic("division by zero in ratio at ratio.omni:3:15");`,
+		"mod": `omni_panic("modulo by zero in ratio at ratio.omni:3:15");`,
+	} {
+		t.Run(op, func(t *testing.T) {
+			plain, err := GenerateC(divisionModule(op))
+			if err != nil {
+				t.Fatalf("GenerateC failed: %v", err)
+			}
+			if strings.Contains(plain, "by zero") {
+				t.Errorf("divisor checked without -div-check or debug info:\n%s", plain)
+			}
+
+			gen := NewCGenerator(divisionModule(op))
+			gen.SetDivCheck(true)
+			checked, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+			if !strings.Contains(checked, want) {
+				t.Errorf("generated C missing %q:\n%s", want, checked)
+			}
+
+			debug, err := GenerateCWithDebug(divisionModule(op), "O0", true, "ratio.omni")
+			if err != nil {
+				t.Fatalf("GenerateCWithDebug failed: %v", err)
+			}
+			if !strings.Contains(debug, want) {
+				t.Errorf("debug build missing %q:\n%s", want, debug)
+			}
+		})
+	}
+}
+
+func TestDivCheckSkipsNonZeroLiterals(t *testing.T) {
+	mod := divisionModule("div")
+	block := mod.Functions[0].Blocks[0]
+	block.Instructions[1].Operands[1] = mir.Operand{Kind: mir.OperandLiteral, Literal: "2", Type: "int"}
+	gen := NewCGenerator(mod)
+	gen.SetDivCheck(true)
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if strings.Contains(code, "by zero") {
+		t.Errorf("literal divisor 2 checked for zero:\n%s", code)
+	}
+}
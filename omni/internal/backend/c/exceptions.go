@@ -0,0 +1,99 @@
+package cbackend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// hasTry reports whether fn has a try statement. Its locals are then
+// declared volatile, as a longjmp back into the function would otherwise
+// leave those assigned since the setjmp indeterminate.
+func hasTry(fn *mir.Function) bool {
+	for _, block := range fn.Blocks {
+		for _, inst := range block.Instructions {
+			if inst.Op == "try.enter" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// volatileType returns the C type cType with a volatile qualifier on the
+// variable itself rather than on what a pointer points to.
+func volatileType(cType string) string {
+	if strings.HasSuffix(cType, "*") {
+		return cType + " volatile"
+	}
+	return "volatile " + cType
+}
+
+// generateTryEnter writes try.enter, which pushes a try frame and setjmps
+// into it. The frame records the innermost defer frame, as omni_throw runs
+// the deferred calls of the frames above it. When an exception reaches the
+// frame the setjmp returns again and the code jumps to the block for its
+// type, or throws it on.
+func (g *CGenerator) generateTryEnter(inst *mir.Instruction) {
+	frame := fmt.Sprintf("omni_try_%d", g.tryFrames)
+	g.tryFrames++
+	g.output.WriteString(fmt.Sprintf("  %s.prev = omni_try_top;\n  %s.defers = omni_defer_top;\n  omni_try_top = &%s;\n", frame, frame, frame))
+	g.output.WriteString(fmt.Sprintf("  if (setjmp(%s.env)) {\n", frame))
+	g.output.WriteString(fmt.Sprintf("  omni_try_top = %s.prev;\n", frame))
+	for i := 0; i+1 < len(inst.Operands); i += 2 {
+		typ, block := inst.Operands[i].Literal, inst.Operands[i+1].Literal
+		if typ == mir.CatchAny {
+			g.output.WriteString(fmt.Sprintf("  goto %s;\n  }\n", block))
+			return
+		}
+		g.output.WriteString(fmt.Sprintf("  if (strcmp(omni_exception.type, %q) == 0) goto %s;\n", typ, block))
+	}
+	g.output.WriteString("  omni_rethrow();\n  }\n")
+}
+
+// generateCatchBegin writes catch.begin, which reads the caught exception
+// as a value of the catch clause type.
+func (g *CGenerator) generateCatchBegin(inst *mir.Instruction) {
+	if inst.ID == mir.InvalidValue {
+		return
+	}
+	varName := g.getVariableName(inst.ID)
+	switch cType := g.mapType(inst.Type); {
+	case cType == "const char*" || cType == "char*":
+		g.output.WriteString(fmt.Sprintf("  %s = omni_exception.s;\n", varName))
+	case cType == "double" || cType == "float":
+		g.output.WriteString(fmt.Sprintf("  %s = omni_exception.f;\n", varName))
+	case strings.HasSuffix(cType, "*"):
+		g.output.WriteString(fmt.Sprintf("  %s = (%s)omni_exception.p;\n", varName, cType))
+	default:
+		g.output.WriteString(fmt.Sprintf("  %s = (%s)omni_exception.i;\n", varName, cType))
+	}
+}
+
+// generateThrow writes throw, which raises its operand with the name of its
+// type. An operand of type exception is one that finally.begin caught and
+// is raised as it was.
+func (g *CGenerator) generateThrow(inst *mir.Instruction) {
+	if len(inst.Operands) == 0 {
+		return
+	}
+	operand := inst.Operands[0]
+	value := g.getOperandValue(operand)
+	if operand.Type == "exception" {
+		g.output.WriteString(fmt.Sprintf("  omni_throw(%s);\n", value))
+		return
+	}
+	i, f, s, p := "0", "0", "NULL", "NULL"
+	switch cType := g.mapType(operand.Type); {
+	case cType == "const char*" || cType == "char*":
+		s = value
+	case cType == "double" || cType == "float":
+		f = value
+	case strings.HasSuffix(cType, "*"):
+		p = "(void*)" + value
+	default:
+		i = "(int64_t)" + value
+	}
+	g.output.WriteString(fmt.Sprintf("  omni_throw((omni_exception_t){%q, %s, %s, %s, %s});\n", operand.Type, i, f, s, p))
+}
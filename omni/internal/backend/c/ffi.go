@@ -4,7 +4,7 @@
 package cbackend
 
 /*
-#cgo CFLAGS: -I${SRCDIR}/../../../runtime
+#cgo CFLAGS: -I${SRCDIR}/../../../runtime -D_GNU_SOURCE
 #cgo linux  LDFLAGS: -lm
 #cgo darwin LDFLAGS: -lm
 #include <stdlib.h>
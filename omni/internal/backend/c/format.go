@@ -0,0 +1,51 @@
+package cbackend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// isStringFormat reports whether inst calls std.string.format, whose
+// arguments after the template may be of any type.
+func isStringFormat(inst *mir.Instruction) bool {
+	callee, ok := inst.CallTarget()
+	return ok && (callee == "std.string.format" || callee == "string.format")
+}
+
+// formatArgConstructors are the runtime functions that tag an argument of
+// omni_string_format with its kind, by the type of the argument.
+var formatArgConstructors = map[string]string{
+	"int": "omni_format_int", "int64": "omni_format_int", "long": "omni_format_int",
+	"byte": "omni_format_int", "char": "omni_format_int", "uint": "omni_format_int",
+	"uint8": "omni_format_int", "uint16": "omni_format_int", "uint32": "omni_format_int",
+	"uint64": "omni_format_int", "float": "omni_format_float", "double": "omni_format_float",
+	"string": "omni_format_string", "bool": "omni_format_bool",
+}
+
+// emitStringFormat writes a call of std.string.format as one of
+// omni_string_format, passing the arguments after the template as an array
+// of tagged values.
+func (g *CGenerator) emitStringFormat(inst *mir.Instruction) {
+	args := inst.Operands[2:]
+	tagged := make([]string, 0, len(args))
+	for _, arg := range args {
+		constructor, ok := formatArgConstructors[arg.Type]
+		if !ok {
+			g.error("unsupported-format-argument", fmt.Sprintf("std.string.format cannot format a value of type %s", arg.Type))
+			continue
+		}
+		tagged = append(tagged, fmt.Sprintf("%s(%s)", constructor, g.getOperandValue(arg)))
+	}
+	list := "NULL"
+	if len(tagged) > 0 {
+		list = fmt.Sprintf("(omni_format_arg[]){%s}", strings.Join(tagged, ", "))
+	}
+	g.output.WriteString(fmt.Sprintf("  %s = omni_string_format(%s, %d, %s);\n",
+		g.getVariableName(inst.ID), g.getOperandValue(inst.Operands[1]), len(tagged), list))
+	// omni_string_format returns a heap-allocated string
+	if inst.ID != mir.InvalidValue {
+		g.stringsToFree[inst.ID] = true
+	}
+}
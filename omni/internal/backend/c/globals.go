@@ -0,0 +1,55 @@
+package cbackend
+
+import (
+	"fmt"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// globalName returns the C name of the OmniLang global name. The prefix
+// keeps globals apart from the runtime and the C library.
+func globalName(name string) string {
+	return "omni_global_" + name
+}
+
+// writeGlobals declares the global variables of the module at file scope,
+// initialized to their initial values.
+func (g *CGenerator) writeGlobals() {
+	if len(g.module.Globals) == 0 {
+		return
+	}
+	for _, global := range g.module.Globals {
+		g.output.WriteString(fmt.Sprintf("static %s %s = %s;\n", g.mapType(global.Type), globalName(global.Name), g.globalInitializer(global)))
+	}
+	g.output.WriteString("\n")
+}
+
+// globalInitializer returns the C constant for the initial value of global.
+func (g *CGenerator) globalInitializer(global mir.GlobalVar) string {
+	literal := g.getOperandValue(global.InitValue)
+	switch global.Type {
+	case "int", "byte":
+		return g.convertLiteralToDecimal(literal)
+	case "int64", "long":
+		return g.convertLiteralToDecimal(literal) + "LL"
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		return unsignedLiteral(literal, global.Type)
+	}
+	return literal
+}
+
+// generateGlobal writes global.load, which copies a global into the value
+// of the instruction, and global.store, which assigns one.
+func (g *CGenerator) generateGlobal(inst *mir.Instruction) {
+	name := globalName(inst.Operands[0].Literal)
+	if inst.Op == "global.load" {
+		g.output.WriteString(fmt.Sprintf("  %s = %s;\n", g.getVariableName(inst.ID), name))
+		return
+	}
+	value := inst.Operands[1]
+	g.output.WriteString(fmt.Sprintf("  %s = %s;\n", name, g.getOperandValue(value)))
+	// The global keeps a string stored in it past the end of the function
+	if value.Kind == mir.OperandValue {
+		delete(g.stringsToFree, value.Value)
+	}
+}
@@ -0,0 +1,98 @@
+package cbackend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// An interface value is a fat pointer: the struct it holds and the vtable of
+// the functions implementing the interface for that struct. Each interface
+// I has a vtable type I_vtable, and each struct S converted to it a static
+// vtable S_I_vtable.
+
+// interfaceType returns the C type of the interface values of typ, if it
+// names an interface of the module.
+func (g *CGenerator) interfaceType(typ string) (string, bool) {
+	if _, ok := g.module.Interface(typ); !ok {
+		return "", false
+	}
+	return typ + "_t", true
+}
+
+// writeInterfaceTypes writes the vtable and value types of the interfaces
+// of the module. The methods take the struct as omni_struct_t*.
+func (g *CGenerator) writeInterfaceTypes() {
+	for _, iface := range g.module.Interfaces {
+		g.output.WriteString(fmt.Sprintf("typedef struct %s_vtable {\n", iface.Name))
+		for _, m := range iface.Methods {
+			params := []string{"omni_struct_t*"}
+			for _, param := range m.Params {
+				params = append(params, g.mapType(param))
+			}
+			g.output.WriteString(fmt.Sprintf("  %s (*%s)(%s);\n", g.mapType(m.Return), m.Name, strings.Join(params, ", ")))
+		}
+		g.output.WriteString(fmt.Sprintf("} %s_vtable;\n", iface.Name))
+		g.output.WriteString(fmt.Sprintf("typedef struct { void* data; const %s_vtable* vtable; } %s_t;\n\n", iface.Name, iface.Name))
+	}
+}
+
+// writeVTables writes the static vtable of every struct that the module
+// converts to an interface, after the function declarations they point to.
+func (g *CGenerator) writeVTables() {
+	written := make(map[string]bool)
+	for _, fn := range g.module.Functions {
+		for _, block := range fn.Blocks {
+			for _, inst := range block.Instructions {
+				if inst.Op != "interface.init" || len(inst.Operands) < 2 {
+					continue
+				}
+				name := vtableName(inst)
+				if written[name] {
+					continue
+				}
+				written[name] = true
+				impls := make([]string, 0, len(inst.Operands)-2)
+				for _, impl := range inst.Operands[2:] {
+					impls = append(impls, g.mapFunctionName(impl.Literal))
+				}
+				g.output.WriteString(fmt.Sprintf("static const %s_vtable %s = { %s };\n", inst.Type, name, strings.Join(impls, ", ")))
+			}
+		}
+	}
+	if len(written) > 0 {
+		g.output.WriteString("\n")
+	}
+}
+
+// vtableName returns the name of the static vtable an interface.init
+// instruction points its value to.
+func vtableName(inst mir.Instruction) string {
+	return strings.ReplaceAll(inst.Operands[1].Literal, ".", "_") + "_" + inst.Type + "_vtable"
+}
+
+// generateInterface writes interface.init, which makes an interface value
+// of a struct, and interface.call, which calls a method through the vtable
+// of an interface value.
+func (g *CGenerator) generateInterface(inst *mir.Instruction) {
+	if len(inst.Operands) < 2 {
+		return
+	}
+	value := g.getOperandValue(inst.Operands[0])
+	if inst.Op == "interface.init" {
+		g.output.WriteString(fmt.Sprintf("  %s = (%s_t){ (void*)%s, &%s };\n",
+			g.getVariableName(inst.ID), inst.Type, value, vtableName(*inst)))
+		return
+	}
+	args := []string{fmt.Sprintf("(omni_struct_t*)%s.data", value)}
+	for _, op := range inst.Operands[2:] {
+		args = append(args, g.getOperandValue(op))
+	}
+	call := fmt.Sprintf("%s.vtable->%s(%s)", value, inst.Operands[1].Literal, strings.Join(args, ", "))
+	if inst.Type == "void" || inst.ID == mir.InvalidValue {
+		g.output.WriteString(fmt.Sprintf("  %s;\n", call))
+		return
+	}
+	g.output.WriteString(fmt.Sprintf("  %s = %s;\n", g.getVariableName(inst.ID), call))
+}
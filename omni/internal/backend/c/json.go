@@ -0,0 +1,84 @@
+package cbackend
+
+import (
+	"fmt"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// anyToC are the runtime functions that read the value a JSON tree holds,
+// by the type a cast from any converts it to.
+var anyToC = map[string]string{
+	"int": "omni_json_to_int", "int64": "omni_json_to_int", "long": "omni_json_to_int",
+	"float": "omni_json_to_float", "double": "omni_json_to_float",
+	"string": "omni_json_to_string", "bool": "omni_json_to_bool",
+}
+
+// anyFromC are the runtime functions that wrap a value in a JSON tree, by
+// the type of the value a cast to any converts.
+var anyFromC = map[string]string{
+	"int": "omni_json_from_int", "int64": "omni_json_from_int", "long": "omni_json_from_int",
+	"float": "omni_json_from_float", "double": "omni_json_from_float",
+	"string": "omni_json_from_string", "bool": "omni_json_from_bool",
+}
+
+// isAny reports whether op is a value of type any. Such values are the
+// omni_json_t trees of std.json.parse.
+func (g *CGenerator) isAny(op mir.Operand) bool {
+	if op.Type == "any" {
+		return true
+	}
+	return op.Kind == mir.OperandValue && g.valueTypes[op.Value] == "any"
+}
+
+// emitAnyIndex writes a member or index instruction on an any value as a
+// lookup in its JSON tree, and reports whether inst was one.
+func (g *CGenerator) emitAnyIndex(inst *mir.Instruction) bool {
+	if len(inst.Operands) < 2 || !g.isAny(inst.Operands[0]) {
+		return false
+	}
+	target := g.getOperandValue(inst.Operands[0])
+	index := inst.Operands[1]
+	var lookup string
+	switch {
+	case inst.Op == "member":
+		lookup = fmt.Sprintf("omni_json_member(%s, %q)", target, index.Literal)
+	case index.Type == "string":
+		lookup = fmt.Sprintf("omni_json_member(%s, %s)", target, g.getOperandValue(index))
+	default:
+		lookup = fmt.Sprintf("omni_json_index(%s, %s)", target, g.getOperandValue(index))
+	}
+	g.output.WriteString(fmt.Sprintf("  %s = %s;\n", g.getVariableName(inst.ID), lookup))
+	return true
+}
+
+// emitAnyCast writes a cast from or to any as a call of the runtime function
+// that reads or wraps the value, and reports whether inst was one.
+func (g *CGenerator) emitAnyCast(inst *mir.Instruction) bool {
+	if len(inst.Operands) < 1 {
+		return false
+	}
+	operand := inst.Operands[0]
+	var convert string
+	switch {
+	case inst.Type == "any" && g.isAny(operand):
+		g.output.WriteString(fmt.Sprintf("  %s = %s;\n", g.getVariableName(inst.ID), g.getOperandValue(operand)))
+		return true
+	case inst.Type == "any":
+		var ok bool
+		if convert, ok = anyFromC[operand.Type]; !ok {
+			g.error("unsupported-any-cast", fmt.Sprintf("a value of type %s cannot be converted to any", operand.Type))
+			return true
+		}
+	case g.isAny(operand):
+		var ok bool
+		if convert, ok = anyToC[inst.Type]; !ok {
+			g.error("unsupported-any-cast", fmt.Sprintf("an any value cannot be converted to %s", inst.Type))
+			return true
+		}
+	default:
+		return false
+	}
+	g.output.WriteString(fmt.Sprintf("  %s = %s(%s);\n", g.getVariableName(inst.ID), convert, g.getOperandValue(operand)))
+	return true
+}
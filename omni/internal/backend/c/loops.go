@@ -0,0 +1,180 @@
+package cbackend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// whileLoop is a while loop of the MIR builder that the generator writes as
+// a C while statement rather than with labels and gotos.
+type whileLoop struct {
+	header *mir.BasicBlock
+	// cond is the value the header branches on
+	cond mir.Operand
+	exit string
+	// blocks are the blocks of the loop besides the header: those reached
+	// from the body that lead back to the header
+	blocks map[string]bool
+}
+
+// whileCondOps are the instructions a loop header may compute its condition
+// with. A back edge evaluates the header again before continuing, so these
+// must be safe to generate more than once.
+var whileCondOps = map[string]bool{
+	"const": true, "add": true, "sub": true, "mul": true, "div": true, "mod": true, "neg": true,
+	"not": true, "and": true, "or": true, "cast": true, "member": true, "index": true, "call": true,
+	"cmp.eq": true, "cmp.neq": true, "cmp.lt": true, "cmp.lte": true, "cmp.gt": true, "cmp.gte": true,
+}
+
+// whileLoops finds the while loops of fn that can be written as C while
+// statements, by the name of their header block. The header must be a
+// while_header block that only computes the condition and branches on it,
+// and neither it nor the body may have phis, as the edges into them would
+// need copies.
+func whileLoops(fn *mir.Function, phis map[string][]mir.Instruction) map[string]*whileLoop {
+	blocks := make(map[string]*mir.BasicBlock, len(fn.Blocks))
+	for _, block := range fn.Blocks {
+		blocks[block.Name] = block
+	}
+	loops := make(map[string]*whileLoop)
+	for _, header := range fn.Blocks {
+		term := header.Terminator
+		if !strings.HasPrefix(header.Name, "while_header") || term.Op != "cbr" || len(term.Operands) != 3 {
+			continue
+		}
+		body, exit := term.Operands[1].Literal, term.Operands[2].Literal
+		if blocks[body] == nil || len(phis[header.Name]) > 0 || len(phis[body]) > 0 {
+			continue
+		}
+		simple := true
+		for _, inst := range header.Instructions {
+			if !whileCondOps[inst.Op] {
+				simple = false
+			}
+		}
+		if !simple {
+			continue
+		}
+		region := loopRegion(blocks, header.Name, body)
+		if !region[body] {
+			// The body never loops back, so it is no loop at all
+			continue
+		}
+		loops[header.Name] = &whileLoop{header: header, cond: term.Operands[0], exit: exit, blocks: region}
+	}
+	return loops
+}
+
+// loopRegion returns the blocks reachable from body without passing the
+// header that can reach the header again.
+func loopRegion(blocks map[string]*mir.BasicBlock, header, body string) map[string]bool {
+	reached := map[string]bool{}
+	stack := []string{body}
+	for len(stack) > 0 {
+		name := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if name == header || reached[name] || blocks[name] == nil {
+			continue
+		}
+		reached[name] = true
+		stack = append(stack, terminatorTargets(blocks[name].Terminator)...)
+	}
+
+	region := map[string]bool{}
+	for changed := true; changed; {
+		changed = false
+		for name := range reached {
+			if region[name] {
+				continue
+			}
+			for _, target := range terminatorTargets(blocks[name].Terminator) {
+				if target == header || region[target] {
+					region[name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return region
+}
+
+// terminatorTargets returns the blocks a terminator branches to.
+func terminatorTargets(term mir.Terminator) []string {
+	switch term.Op {
+	case "br", "jmp", "loop.break", "loop.continue":
+		if len(term.Operands) > 0 {
+			return []string{term.Operands[0].Literal}
+		}
+	case "cbr":
+		if len(term.Operands) >= 3 {
+			return []string{term.Operands[1].Literal, term.Operands[2].Literal}
+		}
+	}
+	return nil
+}
+
+// generateWhile writes loop, whose header block has been generated up to
+// its terminator, as a C while statement holding the blocks of the loop.
+// The temporaries that the blocks declare move in front of the statement,
+// as the cleanup at the end of the function frees them.
+func (g *CGenerator) generateWhile(loop *whileLoop, fn *mir.Function, funcName string) error {
+	start := g.output.Len()
+	temps := len(g.tempStringsToFree)
+
+	g.openLoops = append(g.openLoops, loop)
+	for _, block := range fn.Blocks {
+		if !loop.blocks[block.Name] || g.emittedBlocks[block.Name] {
+			continue
+		}
+		if err := g.generateBlock(block, fn); err != nil {
+			return err
+		}
+	}
+	g.openLoops = g.openLoops[:len(g.openLoops)-1]
+
+	code := g.output.String()
+	body := code[start:]
+	g.output.Reset()
+	g.output.WriteString(code[:start])
+	for _, temp := range g.tempStringsToFree[temps:] {
+		g.output.WriteString(fmt.Sprintf("  const char* %s = NULL;\n", temp))
+		body = strings.Replace(body, fmt.Sprintf("const char* %s = ", temp), temp+" = ", 1)
+	}
+	g.output.WriteString(fmt.Sprintf("  while (%s) {\n", g.getOperandValue(loop.cond)))
+	g.output.WriteString(body)
+	g.output.WriteString("  }\n")
+
+	// The loop ends when the header finds the condition false
+	g.currentBlock = loop.header.Name
+	exit := mir.Terminator{Op: "br", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: loop.exit}}}
+	return g.generateTerminator(&exit, funcName, fn.ReturnType)
+}
+
+// jumpInWhile writes a jump to target from a block of the innermost while
+// statement as a break or continue of the statement, if target is its exit
+// or its header, and reports whether it did. A continue runs the header
+// instructions again so that the statement tests the new condition.
+func (g *CGenerator) jumpInWhile(target string) (bool, error) {
+	if len(g.openLoops) == 0 {
+		return false, nil
+	}
+	loop := g.openLoops[len(g.openLoops)-1]
+	switch {
+	case target == loop.exit && len(g.blockPhis[target]) == 0:
+		g.output.WriteString("  break;\n")
+		return true, nil
+	case target == loop.header.Name:
+		for i := range loop.header.Instructions {
+			inst := loop.header.Instructions[i]
+			if err := g.generateInstruction(&inst); err != nil {
+				return true, err
+			}
+		}
+		g.output.WriteString("  continue;\n")
+		return true, nil
+	}
+	return false, nil
+}
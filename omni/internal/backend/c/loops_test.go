@@ -0,0 +1,114 @@
+package cbackend
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// countdownModule builds the MIR of
+//
+//	func main():int {
+//	    var n:int = 10
+//	    var steps:int = 0
+//	    while n > 0 {
+//	        n = n - 1
+//	        if n == 2 { break }
+//	        steps = steps + 1
+//	    }
+//	    return steps
+//	}
+func countdownModule() *mir.Module {
+	fn := mir.NewFunction("main", "int", nil)
+	entry := fn.NewBlock("entry")
+	header := fn.NewBlock("while_header_0")
+	body := fn.NewBlock("while_body_1")
+	exit := fn.NewBlock("while_exit_2")
+	brk := fn.NewBlock("then_3")
+	merge := fn.NewBlock("merge_4")
+
+	value := func(id mir.ValueID, typ string) mir.Operand {
+		return mir.Operand{Kind: mir.OperandValue, Value: id, Type: typ}
+	}
+	literal := func(lit string) mir.Operand { return mir.Operand{Kind: mir.OperandLiteral, Literal: lit, Type: "int"} }
+	block := func(name string) mir.Operand { return mir.Operand{Kind: mir.OperandLiteral, Literal: name} }
+
+	n, steps := fn.NextValue(), fn.NextValue()
+	entry.Instructions = []mir.Instruction{
+		{ID: n, Op: "const", Type: "int", Operands: []mir.Operand{literal("10")}},
+		{ID: steps, Op: "const", Type: "int", Operands: []mir.Operand{literal("0")}},
+	}
+	entry.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{block("while_header_0")}}
+
+	cond := fn.NextValue()
+	header.Instructions = []mir.Instruction{
+		{ID: cond, Op: "cmp.gt", Type: "bool", Operands: []mir.Operand{value(n, "int"), literal("0")}},
+	}
+	header.Terminator = mir.Terminator{Op: "cbr", Operands: []mir.Operand{value(cond, "bool"), block("while_body_1"), block("while_exit_2")}}
+
+	dec, set, isTwo := fn.NextValue(), fn.NextValue(), fn.NextValue()
+	body.Instructions = []mir.Instruction{
+		{ID: dec, Op: "sub", Type: "int", Operands: []mir.Operand{value(n, "int"), literal("1")}},
+		{ID: set, Op: "assign", Type: "int", Operands: []mir.Operand{value(n, "int"), value(dec, "int")}},
+		{ID: isTwo, Op: "cmp.eq", Type: "bool", Operands: []mir.Operand{value(n, "int"), literal("2")}},
+	}
+	body.Terminator = mir.Terminator{Op: "cbr", Operands: []mir.Operand{value(isTwo, "bool"), block("then_3"), block("merge_4")}}
+
+	brk.Terminator = mir.Terminator{Op: "loop.break", Operands: []mir.Operand{block("while_exit_2")}}
+
+	inc, setSteps := fn.NextValue(), fn.NextValue()
+	merge.Instructions = []mir.Instruction{
+		{ID: inc, Op: "add", Type: "int", Operands: []mir.Operand{value(steps, "int"), literal("1")}},
+		{ID: setSteps, Op: "assign", Type: "int", Operands: []mir.Operand{value(steps, "int"), value(inc, "int")}},
+	}
+	merge.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{block("while_header_0")}}
+
+	exit.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{value(steps, "int")}}
+	return &mir.Module{Functions: []*mir.Function{fn}}
+}
+
+// TestWhileLoopEmitsWhileStatement checks that a while loop becomes a C
+// while statement whose back edge tests the condition again, and that it
+// counts 7 steps when gcc is available to run it.
+func TestWhileLoopEmitsWhileStatement(t *testing.T) {
+	code, err := GenerateC(countdownModule())
+	if err != nil {
+		t.Fatalf("GenerateC failed: %v", err)
+	}
+	for _, want := range []string{
+		"v2 = (v0 > 0) ? 1 : 0;\nwhile (v2) {\nwhile_body_1:",
+		"v1 = v6;\nv2 = (v0 > 0) ? 1 : 0;\ncontinue;\n}\ngoto while_exit_2;",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated C missing %q:\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "goto while_body_1;") {
+		t.Errorf("while loop still enters its body with a goto:\n%s", code)
+	}
+
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("gcc not available")
+	}
+	dir := t.TempDir()
+	src := filepath.Join(dir, "countdown.c")
+	if err := os.WriteFile(src, []byte(code), 0o644); err != nil {
+		t.Fatalf("write C: %v", err)
+	}
+	runtimeDir := filepath.Join("..", "..", "..", "runtime")
+	bin := filepath.Join(dir, "countdown")
+	build := exec.Command("gcc", "-I", runtimeDir, "-o", bin, src, filepath.Join(runtimeDir, "omni_rt.c"), "-lm", "-lpthread")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("gcc failed: %v\n%s", err, out)
+	}
+	err = exec.Command(bin).Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 7 {
+		t.Fatalf("run countdown loop: %v, want exit status 7", err)
+	}
+}
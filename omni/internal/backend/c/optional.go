@@ -0,0 +1,99 @@
+package cbackend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// An optional T? whose T is a pointer in C is that pointer, and NULL when
+// absent. The other optionals are structs with a has_value flag and the
+// value, which the runtime declares as omni_opt_<T> for these types.
+var optionalStructs = map[string]bool{
+	"int32_t": true, "int64_t": true, "uint8_t": true, "uint16_t": true,
+	"uint32_t": true, "uint64_t": true, "double": true,
+}
+
+// optionalStruct returns the C struct type of the optional whose value has
+// the C type cType, if it is not a pointer.
+func optionalStruct(cType string) (string, bool) {
+	if !optionalStructs[cType] {
+		return "", false
+	}
+	return "omni_opt_" + cType, true
+}
+
+// isOptionalStruct reports whether the OmniLang type typ is an optional
+// held in a struct.
+func (g *CGenerator) isOptionalStruct(typ string) bool {
+	return strings.HasSuffix(typ, "?") && strings.HasPrefix(g.mapType(typ), "omni_opt_")
+}
+
+// generateOptional writes the opt instructions, which make and inspect
+// optional values.
+func (g *CGenerator) generateOptional(inst *mir.Instruction) {
+	varName := g.getVariableName(inst.ID)
+	if inst.Op == "opt.none" {
+		if cType := g.mapType(inst.Type); strings.HasPrefix(cType, "omni_opt_") {
+			g.output.WriteString(fmt.Sprintf("  %s = (%s){0, 0};\n", varName, cType))
+		} else {
+			g.output.WriteString(fmt.Sprintf("  %s = NULL;\n", varName))
+		}
+		return
+	}
+	if len(inst.Operands) == 0 {
+		return
+	}
+	value := g.getOperandValue(inst.Operands[0])
+	if inst.Op == "opt.some" {
+		if cType := g.mapType(inst.Type); strings.HasPrefix(cType, "omni_opt_") {
+			g.output.WriteString(fmt.Sprintf("  %s = (%s){1, %s};\n", varName, cType, value))
+		} else {
+			g.output.WriteString(fmt.Sprintf("  %s = %s;\n", varName, value))
+		}
+		return
+	}
+
+	// The other instructions read the flag and value of their operand
+	hasValue, held := value+".has_value", value+".value"
+	if !g.isOptionalStruct(inst.Operands[0].Type) {
+		hasValue, held = "("+value+" != NULL)", value
+	}
+	switch inst.Op {
+	case "opt.unwrap":
+		g.output.WriteString(fmt.Sprintf("  if (!%s) omni_opt_unwrap_none();\n", hasValue))
+		g.output.WriteString(fmt.Sprintf("  %s = %s;\n", varName, held))
+	case "opt.unwrap_or":
+		if len(inst.Operands) > 1 {
+			g.output.WriteString(fmt.Sprintf("  %s = %s ? %s : %s;\n", varName, hasValue, held, g.getOperandValue(inst.Operands[1])))
+		}
+	case "opt.is_some":
+		g.output.WriteString(fmt.Sprintf("  %s = %s ? 1 : 0;\n", varName, hasValue))
+	case "opt.is_none":
+		g.output.WriteString(fmt.Sprintf("  %s = %s ? 0 : 1;\n", varName, hasValue))
+	}
+}
+
+// generateNullComparison writes a comparison with null of an optional held
+// in a struct, which tests its flag, and reports whether the comparison was
+// one.
+func (g *CGenerator) generateNullComparison(inst *mir.Instruction, leftType, rightType string) bool {
+	if inst.Op != "cmp.eq" && inst.Op != "cmp.neq" {
+		return false
+	}
+	optional := inst.Operands[0]
+	switch {
+	case rightType == "null" && g.isOptionalStruct(leftType):
+	case leftType == "null" && g.isOptionalStruct(rightType):
+		optional = inst.Operands[1]
+	default:
+		return false
+	}
+	negate := "!"
+	if inst.Op == "cmp.neq" {
+		negate = ""
+	}
+	g.output.WriteString(fmt.Sprintf("  %s = %s%s.has_value;\n", g.getVariableName(inst.ID), negate, g.getOperandValue(optional)))
+	return true
+}
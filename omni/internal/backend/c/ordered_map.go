@@ -0,0 +1,154 @@
+package cbackend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// orderedMapCall reports whether inst calls one of the
+// std.collections.ordered_map functions, returning the function name without
+// the module prefix.
+func orderedMapCall(inst *mir.Instruction) (string, bool) {
+	callee, ok := inst.CallTarget()
+	if !ok || !strings.HasPrefix(callee, "std.collections.ordered_map.") {
+		return "", false
+	}
+	return strings.TrimPrefix(callee, "std.collections.ordered_map."), true
+}
+
+// orderedMapArrayCall reports whether inst builds a new array with keys,
+// values or entries. The array carries its length in a variable of
+// arrayCounts.
+func orderedMapArrayCall(inst *mir.Instruction) bool {
+	op, ok := orderedMapCall(inst)
+	return ok && (op == "keys" || op == "values" || op == "entries")
+}
+
+// orderedMapTypes returns the key and value types of an OrderedMap<K, V>
+// type.
+func orderedMapTypes(mapType string) (string, string, bool) {
+	if !strings.HasPrefix(mapType, "OrderedMap<") || !strings.HasSuffix(mapType, ">") {
+		return "", "", false
+	}
+	body := mapType[len("OrderedMap<") : len(mapType)-1]
+	depth := 0
+	for i, r := range body {
+		switch r {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				return strings.TrimSpace(body[:i]), strings.TrimSpace(body[i+1:]), true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// tupleFieldSetters are the runtime functions that set an element of a
+// tuple, by the type of the element.
+var tupleFieldSetters = map[string]string{
+	"int": "omni_struct_set_int_field", "bool": "omni_struct_set_bool_field",
+	"float": "omni_struct_set_float_field", "double": "omni_struct_set_float_field",
+	"string": "omni_struct_set_string_field",
+}
+
+// emitOrderedMapCall writes a call of an ordered map function. Keys and
+// values travel through omni_ordered_map_value, the deque's union; keys,
+// values and entries are loops over the entry list that fill a new array.
+func (g *CGenerator) emitOrderedMapCall(inst *mir.Instruction, op string) {
+	varName := g.getVariableName(inst.ID)
+	want := 2
+	switch op {
+	case "create":
+		want = 1
+	case "put":
+		want = 4
+	case "get", "remove":
+		want = 3
+	case "keys", "values", "entries":
+	default:
+		g.error("invalid-call", fmt.Sprintf("collections.ordered_map.%s is not an ordered map function", op))
+		return
+	}
+	if len(inst.Operands) != want {
+		g.error("invalid-call", fmt.Sprintf("collections.ordered_map.%s: expected %d arguments, got %d", op, want-1, len(inst.Operands)-1))
+		return
+	}
+
+	mapType := inst.Type
+	if op != "create" {
+		mapType = inst.Operands[1].Type
+	}
+	keyType, valueType, ok := orderedMapTypes(mapType)
+	if !ok {
+		g.error("invalid-call", fmt.Sprintf("collections.ordered_map.%s: expected an ordered map, got %s", op, mapType))
+		return
+	}
+	keyField, ok := dequeValueField(keyType)
+	if !ok || keyField == "f" {
+		g.error("unsupported-ordered-map-key", fmt.Sprintf("collections.ordered_map.%s: keys of %s are not supported by the C backend", op, keyType))
+		return
+	}
+	valueField, ok := dequeValueField(valueType)
+	if !ok {
+		g.error("unsupported-ordered-map-value", fmt.Sprintf("collections.ordered_map.%s: values of %s are not supported by the C backend", op, valueType))
+		return
+	}
+	if op == "create" {
+		stringKeys := 0
+		if keyField == "s" {
+			stringKeys = 1
+		}
+		g.output.WriteString(fmt.Sprintf("  %s = omni_ordered_map_create(%d);\n", varName, stringKeys))
+		return
+	}
+	m := g.getOperandValue(inst.Operands[1])
+	key := ""
+	if want > 2 {
+		key = fmt.Sprintf("(omni_ordered_map_value){.%s = %s}", keyField, g.getOperandValue(inst.Operands[2]))
+	}
+
+	switch op {
+	case "put":
+		g.output.WriteString(fmt.Sprintf("  omni_ordered_map_put(%s, %s, (omni_ordered_map_value){.%s = %s});\n",
+			m, key, valueField, g.getOperandValue(inst.Operands[3])))
+	case "remove":
+		g.output.WriteString(fmt.Sprintf("  %s = omni_ordered_map_remove(%s, %s);\n", varName, m, key))
+	case "get":
+		found, missing := "found."+valueField, "NULL"
+		if cType := g.mapType(inst.Type); strings.HasPrefix(cType, "omni_opt_") {
+			found, missing = fmt.Sprintf("(%s){1, found.%s}", cType, valueField), fmt.Sprintf("(%s){0, 0}", cType)
+		}
+		g.output.WriteString(fmt.Sprintf("  {\n    omni_ordered_map_value found;\n    %s = omni_ordered_map_get(%s, %s, &found) ? %s : %s;\n  }\n",
+			varName, m, key, found, missing))
+	default:
+		count := g.arrayCounts[inst.ID]
+		if g.arrayAllocsToFree[inst.ID] {
+			// Release the array from a previous loop iteration, as for
+			// array.init
+			g.output.WriteString(fmt.Sprintf("  free(%s);\n", varName))
+		}
+		// One slot at least, so that an empty result is not NULL
+		g.output.WriteString(fmt.Sprintf("  %s = malloc(sizeof(%s) * (%s->size > 0 ? %s->size : 1));\n",
+			varName, g.mapType(elementTypeOf(inst.Type)), m, m))
+		g.output.WriteString(fmt.Sprintf("  %s = 0;\n", count))
+		g.output.WriteString(fmt.Sprintf("  for (omni_ordered_map_entry* e = %s->first; e; e = e->next) {\n", m))
+		switch op {
+		case "keys":
+			g.output.WriteString(fmt.Sprintf("    %s[%s++] = e->key.%s;\n", varName, count, keyField))
+		case "values":
+			g.output.WriteString(fmt.Sprintf("    %s[%s++] = e->value.%s;\n", varName, count, valueField))
+		default:
+			g.output.WriteString("    omni_struct_t* pair = omni_struct_create();\n")
+			g.output.WriteString(fmt.Sprintf("    %s(pair, \"0\", e->key.%s);\n", tupleFieldSetters[keyType], keyField))
+			g.output.WriteString(fmt.Sprintf("    %s(pair, \"1\", e->value.%s);\n", tupleFieldSetters[valueType], valueField))
+			g.output.WriteString(fmt.Sprintf("    %s[%s++] = pair;\n", varName, count))
+		}
+		g.output.WriteString("  }\n")
+	}
+}
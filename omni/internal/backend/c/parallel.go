@@ -0,0 +1,95 @@
+package cbackend
+
+import (
+	"sync"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// SetParallelism sets how many function definitions are generated
+// concurrently. Values below 2 generate them one at a time. The output is
+// identical either way: definitions are always emitted in declaration order.
+func (g *CGenerator) SetParallelism(n int) {
+	g.parallelism = n
+}
+
+// functionOutput is the code and diagnostics of one function definition
+// generated by a worker.
+type functionOutput struct {
+	code  string
+	diags []CodeGenDiag
+	err   error
+}
+
+// generateFunctions writes the definitions of every function in the module,
+// fanning the work out across g.parallelism workers when it is above 1.
+func (g *CGenerator) generateFunctions() error {
+	functions := g.module.Functions
+	if g.parallelism < 2 || len(functions) < 2 {
+		for _, fn := range functions {
+			if err := g.generateFunction(fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	results := make([]functionOutput, len(functions))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(g.parallelism, len(functions)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				worker := g.forkForFunction()
+				err := worker.generateFunction(functions[i])
+				results[i] = functionOutput{code: worker.output.String(), diags: worker.diags, err: err}
+			}
+		}()
+	}
+	for i := range functions {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Stitch the results together as the serial loop would have produced
+	// them, stopping at the first function that failed.
+	for _, result := range results {
+		g.diags = append(g.diags, result.diags...)
+		if result.err != nil {
+			return result.err
+		}
+		g.output.WriteString(result.code)
+	}
+	return nil
+}
+
+// forkForFunction returns a generator with g's settings and its own output
+// buffer and per-function state, so that it can generate one function
+// definition without sharing mutable state with other workers.
+func (g *CGenerator) forkForFunction() *CGenerator {
+	return &CGenerator{
+		module:            g.module,
+		optLevel:          g.optLevel,
+		debugInfo:         g.debugInfo,
+		divCheck:          g.divCheck,
+		sourceFile:        g.sourceFile,
+		variables:         make(map[mir.ValueID]string),
+		phiVars:           make(map[mir.ValueID]bool),
+		mutableVars:       make(map[mir.ValueID]bool),
+		mapVars:           make(map[string]bool),
+		mapTypes:          make(map[mir.ValueID]string),
+		arrayLengths:      make(map[mir.ValueID]int),
+		sourceMap:         g.sourceMap,
+		lineMap:           g.lineMap,
+		valueTypes:        make(map[mir.ValueID]string),
+		stringsToFree:     make(map[mir.ValueID]bool),
+		promisesToFree:    make(map[mir.ValueID]bool),
+		arrayAllocsToFree: make(map[mir.ValueID]bool),
+		tempStringsToFree: []string{},
+		returnedValueID:   mir.InvalidValue,
+		declaredVariables: make(map[mir.ValueID]bool),
+	}
+}
@@ -0,0 +1,55 @@
+package cbackend
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir/builder"
+	"github.com/omni-lang/omni/internal/parser"
+)
+
+func TestParallelGenerationMatchesSerial(t *testing.T) {
+	var src strings.Builder
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&src, "func f%d(x:int):int {\n", i)
+		fmt.Fprintf(&src, "    let values:array<int> = [x, %d, x * 2]\n", i)
+		src.WriteString("    var total:int = 0\n")
+		src.WriteString("    for v in values {\n        total = total + v\n    }\n")
+		fmt.Fprintf(&src, "    if total > %d {\n        return total - x\n    }\n", i*3)
+		src.WriteString("    return total\n}\n\n")
+	}
+	src.WriteString("func main():int {\n    var sum:int = 0\n")
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&src, "    sum = sum + f%d(%d)\n", i, i)
+	}
+	src.WriteString("    return sum\n}\n")
+
+	mod, err := parser.Parse("many.omni", src.String())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	mirMod, err := builder.BuildModule(mod)
+	if err != nil {
+		t.Fatalf("build MIR: %v", err)
+	}
+	if len(mirMod.Functions) != 51 {
+		t.Fatalf("expected 51 functions, got %d", len(mirMod.Functions))
+	}
+
+	serial, err := NewCGenerator(mirMod).Generate()
+	if err != nil {
+		t.Fatalf("serial generation failed: %v", err)
+	}
+	for _, workers := range []int{2, 8, 64} {
+		gen := NewCGenerator(mirMod)
+		gen.SetParallelism(workers)
+		parallel, err := gen.Generate()
+		if err != nil {
+			t.Fatalf("parallel generation with %d workers failed: %v", workers, err)
+		}
+		if parallel != serial {
+			t.Errorf("output with %d workers differs from serial output", workers)
+		}
+	}
+}
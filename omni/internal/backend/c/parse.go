@@ -0,0 +1,35 @@
+package cbackend
+
+import (
+	"fmt"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// stringParse is the runtime function behind a std.string parse function
+// and the C type and struct setter of the value it parses.
+type stringParse struct {
+	runtimeFunc string
+	cType       string
+	setter      string
+}
+
+// stringParseFunctions are the std.string functions that parse a string
+// into a (value, ok) tuple.
+var stringParseFunctions = map[string]stringParse{
+	"std.string.parse_int":   {"omni_parse_int", "int32_t", "omni_struct_set_int_field"},
+	"std.string.parse_float": {"omni_parse_float", "double", "omni_struct_set_float_field"},
+	"std.string.parse_bool":  {"omni_parse_bool", "int32_t", "omni_struct_set_bool_field"},
+}
+
+// emitStringParse writes a call of a std.string parse function. The runtime
+// stores the value through a pointer and returns whether it parsed, and the
+// two become the elements of the tuple the call returns.
+func (g *CGenerator) emitStringParse(inst *mir.Instruction, parse stringParse) {
+	varName := g.getVariableName(inst.ID)
+	g.output.WriteString(fmt.Sprintf("  {\n    %s parsed;\n    int32_t ok = %s(%s, &parsed);\n",
+		parse.cType, parse.runtimeFunc, g.getOperandValue(inst.Operands[1])))
+	g.output.WriteString(fmt.Sprintf("    %s = omni_struct_create();\n", varName))
+	g.output.WriteString(fmt.Sprintf("    %s(%s, \"0\", parsed);\n", parse.setter, varName))
+	g.output.WriteString(fmt.Sprintf("    omni_struct_set_bool_field(%s, \"1\", ok);\n  }\n", varName))
+}
@@ -0,0 +1,98 @@
+package cbackend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// blockPhis returns the phi nodes of fn that name their predecessors, by the
+// block they belong to. Their operands come in (value, block) pairs; the
+// value paired with a block is the one the phi takes when control arrives
+// from that block.
+func blockPhis(fn *mir.Function) map[string][]mir.Instruction {
+	phis := make(map[string][]mir.Instruction)
+	for _, block := range fn.Blocks {
+		for _, inst := range block.Instructions {
+			if inst.Op == "phi" && hasIncomingBlocks(inst) {
+				phis[block.Name] = append(phis[block.Name], inst)
+			}
+		}
+	}
+	return phis
+}
+
+// hasIncomingBlocks reports whether every value of a phi is paired with the
+// name of the block it comes from.
+func hasIncomingBlocks(inst mir.Instruction) bool {
+	if len(inst.Operands) < 2 || len(inst.Operands)%2 != 0 {
+		return false
+	}
+	for i := 1; i < len(inst.Operands); i += 2 {
+		if inst.Operands[i].Kind != mir.OperandLiteral {
+			return false
+		}
+	}
+	return true
+}
+
+// incomingValue returns the operand a phi takes when control arrives from
+// the block pred.
+func incomingValue(inst mir.Instruction, pred string) (mir.Operand, bool) {
+	for i := 0; i+1 < len(inst.Operands); i += 2 {
+		if inst.Operands[i+1].Literal == pred {
+			return inst.Operands[i], true
+		}
+	}
+	return mir.Operand{}, false
+}
+
+// writePhiCopies assigns the phis of the block target the values incoming
+// from the block being generated, just before jumping there. The copies
+// happen in parallel: when one phi takes the previous value of another, as
+// with loop variables swapped on the backedge, the values are read into
+// temporaries first.
+func (g *CGenerator) writePhiCopies(target, indent string) {
+	type phiCopy struct {
+		dest, value, ctype string
+	}
+	var copies []phiCopy
+	for _, phi := range g.blockPhis[target] {
+		op, ok := incomingValue(phi, g.currentBlock)
+		if !ok {
+			g.error("phi-predecessor", fmt.Sprintf("phi %%%d in block %s has no value for predecessor %s", phi.ID, target, g.currentBlock))
+			continue
+		}
+		copies = append(copies, phiCopy{
+			dest:  g.getVariableName(phi.ID),
+			value: g.getOperandValue(op),
+			ctype: g.mapType(phi.Type),
+		})
+	}
+
+	// Sequential copies are enough unless a phi reads one assigned before it
+	sequential := true
+	for i := range copies {
+		for j := i + 1; j < len(copies); j++ {
+			if copies[j].value == copies[i].dest {
+				sequential = false
+			}
+		}
+	}
+	if sequential {
+		for _, c := range copies {
+			g.output.WriteString(fmt.Sprintf("%s%s = %s;\n", indent, c.dest, c.value))
+		}
+		return
+	}
+	var reads, writes strings.Builder
+	for i, c := range copies {
+		reads.WriteString(fmt.Sprintf("%s  %s phi_tmp%d = %s;\n", indent, c.ctype, i, c.value))
+		writes.WriteString(fmt.Sprintf("%s  %s = phi_tmp%d;\n", indent, c.dest, i))
+	}
+	g.output.WriteString(indent + "{\n")
+	g.output.WriteString(reads.String())
+	g.output.WriteString(writes.String())
+	g.output.WriteString(indent + "}\n")
+}
@@ -0,0 +1,133 @@
+package cbackend
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// sumLoopModule builds the MIR of
+//
+//	func main():int {
+//	    var sum:int = 0
+//	    for i = 0; i < 10; i++ { sum = sum + i }
+//	    return sum
+//	}
+//
+// with the loop variables merged by phi nodes in the loop header.
+func sumLoopModule() *mir.Module {
+	fn := mir.NewFunction("main", "int", nil)
+	entry := fn.NewBlock("entry")
+	header := fn.NewBlock("loop")
+	body := fn.NewBlock("body")
+	exit := fn.NewBlock("exit")
+
+	value := func(id mir.ValueID) mir.Operand { return mir.Operand{Kind: mir.OperandValue, Value: id, Type: "int"} }
+	literal := func(lit string) mir.Operand { return mir.Operand{Kind: mir.OperandLiteral, Literal: lit, Type: "int"} }
+	block := func(name string) mir.Operand { return mir.Operand{Kind: mir.OperandLiteral, Literal: name} }
+
+	i0, sum0 := fn.NextValue(), fn.NextValue()
+	entry.Instructions = []mir.Instruction{
+		{ID: i0, Op: "const", Type: "int", Operands: []mir.Operand{literal("0")}},
+		{ID: sum0, Op: "const", Type: "int", Operands: []mir.Operand{literal("0")}},
+	}
+	entry.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{block("loop")}}
+
+	i, sum, cond := fn.NextValue(), fn.NextValue(), fn.NextValue()
+	nextSum, nextI := fn.NextValue(), fn.NextValue()
+	header.Instructions = []mir.Instruction{
+		{ID: i, Op: "phi", Type: "int", Operands: []mir.Operand{value(i0), block("entry"), value(nextI), block("body")}},
+		{ID: sum, Op: "phi", Type: "int", Operands: []mir.Operand{value(sum0), block("entry"), value(nextSum), block("body")}},
+		{ID: cond, Op: "cmp.lt", Type: "bool", Operands: []mir.Operand{value(i), literal("10")}},
+	}
+	header.Terminator = mir.Terminator{Op: "cbr", Operands: []mir.Operand{
+		{Kind: mir.OperandValue, Value: cond, Type: "bool"}, block("body"), block("exit"),
+	}}
+
+	body.Instructions = []mir.Instruction{
+		{ID: nextSum, Op: "add", Type: "int", Operands: []mir.Operand{value(sum), value(i)}},
+		{ID: nextI, Op: "add", Type: "int", Operands: []mir.Operand{value(i), literal("1")}},
+	}
+	body.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{block("loop")}}
+
+	exit.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{value(sum)}}
+	return &mir.Module{Functions: []*mir.Function{fn}}
+}
+
+// TestPhiAssignedOnIncomingEdges checks that each jump into the loop header
+// assigns the phis the values from the block it leaves, and that the loop
+// computes 0+1+...+9 when gcc is available to run it.
+func TestPhiAssignedOnIncomingEdges(t *testing.T) {
+	code, err := GenerateC(sumLoopModule())
+	if err != nil {
+		t.Fatalf("GenerateC failed: %v", err)
+	}
+	for _, want := range []string{
+		"v2 = v0;\nv3 = v1;\ngoto loop;",
+		"v5 = v3 + v2;\nv6 = v2 + 1;\nv2 = v6;\nv3 = v5;\ngoto loop;",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated C missing %q:\n%s", want, code)
+		}
+	}
+
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("gcc not available")
+	}
+	dir := t.TempDir()
+	src := filepath.Join(dir, "sum.c")
+	if err := os.WriteFile(src, []byte(code), 0o644); err != nil {
+		t.Fatalf("write C: %v", err)
+	}
+	runtimeDir := filepath.Join("..", "..", "..", "runtime")
+	bin := filepath.Join(dir, "sum")
+	build := exec.Command("gcc", "-I", runtimeDir, "-o", bin, src, filepath.Join(runtimeDir, "omni_rt.c"), "-lm", "-lpthread")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("gcc failed: %v\n%s", err, out)
+	}
+	err = exec.Command(bin).Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 45 {
+		t.Fatalf("run sum loop: %v, want exit status 45", err)
+	}
+}
+
+// TestPhiSwapUsesTemporaries checks that phis which take each other's value
+// on the same edge are assigned in parallel.
+func TestPhiSwapUsesTemporaries(t *testing.T) {
+	fn := mir.NewFunction("swap", "int", nil)
+	entry := fn.NewBlock("entry")
+	header := fn.NewBlock("loop")
+
+	a0, b0, a, b := fn.NextValue(), fn.NextValue(), fn.NextValue(), fn.NextValue()
+	entry.Instructions = []mir.Instruction{
+		{ID: a0, Op: "const", Type: "int", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "1", Type: "int"}}},
+		{ID: b0, Op: "const", Type: "int", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "2", Type: "int"}}},
+	}
+	entry.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "loop"}}}
+	header.Instructions = []mir.Instruction{
+		{ID: a, Op: "phi", Type: "int", Operands: []mir.Operand{
+			{Kind: mir.OperandValue, Value: a0, Type: "int"}, {Kind: mir.OperandLiteral, Literal: "entry"},
+			{Kind: mir.OperandValue, Value: b, Type: "int"}, {Kind: mir.OperandLiteral, Literal: "loop"},
+		}},
+		{ID: b, Op: "phi", Type: "int", Operands: []mir.Operand{
+			{Kind: mir.OperandValue, Value: b0, Type: "int"}, {Kind: mir.OperandLiteral, Literal: "entry"},
+			{Kind: mir.OperandValue, Value: a, Type: "int"}, {Kind: mir.OperandLiteral, Literal: "loop"},
+		}},
+	}
+	header.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "loop"}}}
+
+	code, err := GenerateC(&mir.Module{Functions: []*mir.Function{fn}})
+	if err != nil {
+		t.Fatalf("GenerateC failed: %v", err)
+	}
+	want := "int32_t phi_tmp0 = v3;\nint32_t phi_tmp1 = v2;\nv2 = phi_tmp0;\nv3 = phi_tmp1;"
+	if !strings.Contains(code, want) {
+		t.Errorf("generated C missing %q:\n%s", want, code)
+	}
+}
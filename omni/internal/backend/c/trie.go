@@ -0,0 +1,73 @@
+package cbackend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// trieCall reports whether inst calls one of the std.collections.trie
+// functions, returning the function name without the module prefix.
+// keys_with_prefix is a countedArrayFunctions call instead.
+func trieCall(inst *mir.Instruction) (string, bool) {
+	callee, ok := inst.CallTarget()
+	if !ok || !strings.HasPrefix(callee, "std.collections.trie.") {
+		return "", false
+	}
+	return strings.TrimPrefix(callee, "std.collections.trie."), true
+}
+
+// emitTrieCall writes a call of a trie function. Values travel through
+// omni_trie_value, the deque's union, and search turns the flag the runtime
+// returns into an optional.
+func (g *CGenerator) emitTrieCall(inst *mir.Instruction, op string) {
+	runtimeFunc := "omni_trie_" + op
+	varName := g.getVariableName(inst.ID)
+	want := 3
+	switch op {
+	case "create":
+		want = 1
+	case "insert":
+		want = 4
+	case "search", "starts_with":
+	default:
+		g.error("invalid-call", fmt.Sprintf("collections.trie.%s is not a trie function", op))
+		return
+	}
+	if len(inst.Operands) != want {
+		g.error("invalid-call", fmt.Sprintf("collections.trie.%s: expected %d arguments, got %d", op, want-1, len(inst.Operands)-1))
+		return
+	}
+	if op == "create" {
+		g.output.WriteString(fmt.Sprintf("  %s = %s();\n", varName, runtimeFunc))
+		return
+	}
+	t, key := g.getOperandValue(inst.Operands[1]), g.getOperandValue(inst.Operands[2])
+
+	switch op {
+	case "insert":
+		val := inst.Operands[3]
+		field, ok := dequeValueField(val.Type)
+		if !ok {
+			g.error("unsupported-trie-value", fmt.Sprintf("collections.trie.insert: tries of %s are not supported by the C backend", val.Type))
+			return
+		}
+		g.output.WriteString(fmt.Sprintf("  %s(%s, %s, (omni_trie_value){.%s = %s});\n", runtimeFunc, t, key, field, g.getOperandValue(val)))
+	case "starts_with":
+		g.output.WriteString(fmt.Sprintf("  %s = %s(%s, %s);\n", varName, runtimeFunc, t, key))
+	case "search":
+		valueType := strings.TrimSuffix(inst.Type, "?")
+		field, ok := dequeValueField(valueType)
+		if !ok {
+			g.error("unsupported-trie-value", fmt.Sprintf("collections.trie.search: tries of %s are not supported by the C backend", valueType))
+			return
+		}
+		found, missing := "found."+field, "NULL"
+		if cType := g.mapType(inst.Type); strings.HasPrefix(cType, "omni_opt_") {
+			found, missing = fmt.Sprintf("(%s){1, found.%s}", cType, field), fmt.Sprintf("(%s){0, 0}", cType)
+		}
+		g.output.WriteString(fmt.Sprintf("  {\n    omni_trie_value found;\n    %s = %s(%s, %s, &found) ? %s : %s;\n  }\n",
+			varName, runtimeFunc, t, key, found, missing))
+	}
+}
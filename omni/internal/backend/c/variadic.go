@@ -0,0 +1,58 @@
+package cbackend
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// variadicCountParam returns the extra C parameter that carries the length
+// of param, for a variadic parameter. Its arguments arrive packed in an
+// array, whose length the callee cannot recover from the pointer alone.
+func variadicCountParam(param mir.Param) string {
+	if !param.Variadic {
+		return ""
+	}
+	return fmt.Sprintf(", int32_t %s_count", param.Name)
+}
+
+// isVariadicFunction reports whether the module function name takes a
+// variadic parameter.
+func (g *CGenerator) isVariadicFunction(name string) bool {
+	for _, fn := range g.module.Functions {
+		if fn.Name == name {
+			return len(fn.Params) > 0 && fn.Params[len(fn.Params)-1].Variadic
+		}
+	}
+	return false
+}
+
+// callArguments returns the C arguments of a call of funcName. A variadic
+// callee gets the length of the array holding its variadic arguments after
+// the array.
+func (g *CGenerator) callArguments(funcName string, args []mir.Operand) string {
+	parts := make([]string, 0, len(args)+1)
+	for _, arg := range args {
+		parts = append(parts, g.getOperandValue(arg))
+	}
+	if len(args) > 0 && g.isVariadicFunction(funcName) {
+		parts = append(parts, g.arrayLengthExpr(args[len(args)-1]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// arrayLengthExpr returns a C expression for the length of the array op.
+func (g *CGenerator) arrayLengthExpr(op mir.Operand) string {
+	if op.Kind == mir.OperandValue {
+		if length, ok := g.arrayLengths[op.Value]; ok {
+			return strconv.Itoa(length)
+		}
+		if count, ok := g.arrayCounts[op.Value]; ok {
+			return count
+		}
+	}
+	g.error("unknown-array-length", fmt.Sprintf("array length not known for variadic argument %s", g.getOperandValue(op)))
+	return "-1"
+}
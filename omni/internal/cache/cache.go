@@ -0,0 +1,256 @@
+// Package cache implements omnic's persistent compilation cache. Entries are
+// keyed on the content of the input files and the settings that affect the
+// generated artifact, and record the hash of every file the compilation read
+// so that a change to an imported module invalidates them.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+const manifestName = "manifest.json"
+
+// Key describes a compilation. Two compilations with equal keys and
+// unchanged dependencies produce the same artifacts.
+type Key struct {
+	// Inputs are the source files being compiled, in command-line order.
+	Inputs    []string
+	Backend   string
+	OptLevel  string
+	Emit      string
+	DebugInfo bool
+	// Target is the os/arch the artifacts were built for.
+	Target string
+	// Extra fingerprints any other setting that affects the result, such as
+	// the compiler build and the enabled checks.
+	Extra string
+}
+
+// Entry is what a cache entry records about its compilation besides the
+// artifacts.
+type Entry struct {
+	// Deps lists every file the compilation read, including the inputs.
+	Deps []string
+	// Warnings holds the formatted warnings the compilation reported, which
+	// a hit reports again.
+	Warnings []string
+}
+
+// Cache is a directory of cached compilation results.
+type Cache struct {
+	dir string
+}
+
+// DefaultDir returns $XDG_CACHE_HOME/omni, falling back to the platform's
+// user cache directory when XDG_CACHE_HOME is unset.
+func DefaultDir() (string, error) {
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "omni"), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locate cache directory: %w", err)
+	}
+	return filepath.Join(dir, "omni"), nil
+}
+
+// Open returns the cache stored in dir, creating the directory if needed.
+func Open(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache directory: %w", err)
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Dir returns the directory the cache is stored in.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// manifest is the on-disk description of a cache entry.
+type manifest struct {
+	// Deps maps every file read by the compilation to its SHA-256.
+	Deps map[string]string `json:"deps"`
+	// Artifacts lists the cached outputs, stored next to the manifest as
+	// artifact-0, artifact-1, and so on.
+	Artifacts []artifact `json:"artifacts"`
+	// Warnings are the warnings of the compilation, as formatted text.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type artifact struct {
+	Mode os.FileMode `json:"mode"`
+}
+
+// Get restores the artifacts cached for key to outputs, which name the
+// files in the order they were passed to Put, and returns the entry with
+// its dependencies sorted. It reports false without touching outputs when
+// there is no entry or a dependency has changed since the entry was stored.
+func (c *Cache) Get(key Key, outputs []string) (Entry, bool, error) {
+	entryDir, err := c.entryDir(key)
+	if err != nil {
+		return Entry{}, false, nil
+	}
+	data, err := os.ReadFile(filepath.Join(entryDir, manifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return Entry{}, false, nil
+	} else if err != nil {
+		return Entry{}, false, fmt.Errorf("read cache entry: %w", err)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil || len(m.Artifacts) > len(outputs) {
+		// Treat a corrupt or incompatible entry as a miss; Put replaces it.
+		return Entry{}, false, nil
+	}
+	deps := make([]string, 0, len(m.Deps))
+	for path, want := range m.Deps {
+		if got, err := HashFile(path); err != nil || got != want {
+			return Entry{}, false, nil
+		}
+		deps = append(deps, path)
+	}
+	sort.Strings(deps)
+
+	for i, a := range m.Artifacts {
+		if err := os.MkdirAll(filepath.Dir(outputs[i]), 0o755); err != nil {
+			return Entry{}, false, fmt.Errorf("create output directory: %w", err)
+		}
+		if err := copyFile(filepath.Join(entryDir, artifactName(i)), outputs[i], a.Mode); err != nil {
+			return Entry{}, false, fmt.Errorf("restore cached artifact: %w", err)
+		}
+	}
+	return Entry{Deps: deps, Warnings: m.Warnings}, true, nil
+}
+
+// Put stores artifacts as the result of the compilation of key described
+// by entry.
+func (c *Cache) Put(key Key, entry Entry, artifacts []string) error {
+	entryDir, err := c.entryDir(key)
+	if err != nil {
+		return err
+	}
+
+	m := manifest{Deps: make(map[string]string, len(entry.Deps)), Warnings: entry.Warnings}
+	for _, path := range entry.Deps {
+		sum, err := HashFile(path)
+		if err != nil {
+			return fmt.Errorf("hash dependency: %w", err)
+		}
+		m.Deps[path] = sum
+	}
+
+	// Build the entry in a temporary directory and move it into place so
+	// that concurrent builds never observe a half-written entry.
+	tmp, err := os.MkdirTemp(c.dir, "tmp-")
+	if err != nil {
+		return fmt.Errorf("create cache entry: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	for i, path := range artifacts {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("stat artifact: %w", err)
+		}
+		if err := copyFile(path, filepath.Join(tmp, artifactName(i)), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("store artifact: %w", err)
+		}
+		m.Artifacts = append(m.Artifacts, artifact{Mode: info.Mode().Perm()})
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cache entry: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, manifestName), data, 0o644); err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+
+	if err := os.RemoveAll(entryDir); err != nil {
+		return fmt.Errorf("replace cache entry: %w", err)
+	}
+	if err := os.Rename(tmp, entryDir); err != nil {
+		return fmt.Errorf("store cache entry: %w", err)
+	}
+	return nil
+}
+
+// entryDir returns the directory holding the entry for key. Hashing the key
+// reads the inputs, so a missing input is reported as an error.
+func (c *Cache) entryDir(key Key) (string, error) {
+	type hashedInput struct {
+		Path   string `json:"path"`
+		SHA256 string `json:"sha256"`
+	}
+	inputs := make([]hashedInput, 0, len(key.Inputs))
+	for _, path := range key.Inputs {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", err
+		}
+		sum, err := HashFile(abs)
+		if err != nil {
+			return "", fmt.Errorf("hash input: %w", err)
+		}
+		inputs = append(inputs, hashedInput{Path: abs, SHA256: sum})
+	}
+
+	data, err := json.Marshal(struct {
+		Key
+		Inputs []hashedInput
+	}{key, inputs})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])), nil
+}
+
+func artifactName(i int) string {
+	return "artifact-" + strconv.Itoa(i)
+}
+
+// HashFile returns the lowercase hex SHA-256 digest of the file at path.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	// OpenFile only applies mode to new files.
+	return os.Chmod(dst, mode)
+}
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// setup writes an input importing dep and a fake artifact for it, and stores
+// them in a fresh cache.
+func setup(t *testing.T) (c *Cache, key Key, input, dep, artifact string) {
+	t.Helper()
+	dir := t.TempDir()
+	input = filepath.Join(dir, "main.omni")
+	dep = filepath.Join(dir, "utils.omni")
+	artifact = filepath.Join(dir, "main")
+	writeFile(t, input, "import utils\nfunc main():int {\n    return utils.answer()\n}\n")
+	writeFile(t, dep, "func answer():int {\n    return 42\n}\n")
+	if err := os.WriteFile(artifact, []byte("binary v1"), 0o755); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+
+	c, err := Open(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	key = Key{Inputs: []string{input}, Backend: "c", OptLevel: "O0", Emit: "exe"}
+	if err := c.Put(key, Entry{Deps: []string{input, dep}}, []string{artifact}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	return c, key, input, dep, artifact
+}
+
+func TestCacheHitIgnoresMtime(t *testing.T) {
+	c, key, input, dep, _ := setup(t)
+
+	// Touching the sources without changing them must not force a rebuild.
+	later := time.Now().Add(time.Hour)
+	for _, path := range []string{input, dep} {
+		if err := os.Chtimes(path, later, later); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+
+	restored := filepath.Join(t.TempDir(), "out", "app")
+	entry, hit, err := c.Get(key, []string{restored})
+	if err != nil || !hit {
+		t.Fatalf("expected cache hit, got hit=%v err=%v", hit, err)
+	}
+	if len(entry.Deps) != 2 {
+		t.Errorf("got deps %v, want the input and its import", entry.Deps)
+	}
+	data, err := os.ReadFile(restored)
+	if err != nil || string(data) != "binary v1" {
+		t.Fatalf("restored artifact = %q, %v", data, err)
+	}
+	if info, err := os.Stat(restored); err != nil || info.Mode().Perm() != 0o755 {
+		t.Errorf("restored artifact mode = %v, %v; want 0755", info.Mode().Perm(), err)
+	}
+}
+
+func TestCacheMisses(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T, key *Key, input, dep string)
+	}{
+		{"input edited", func(t *testing.T, key *Key, input, dep string) {
+			writeFile(t, input, "func main():int {\n    return 0\n}\n")
+		}},
+		{"import edited", func(t *testing.T, key *Key, input, dep string) {
+			writeFile(t, dep, "func answer():int {\n    return 7\n}\n")
+		}},
+		{"import removed", func(t *testing.T, key *Key, input, dep string) {
+			if err := os.Remove(dep); err != nil {
+				t.Fatalf("remove: %v", err)
+			}
+		}},
+		{"backend changed", func(t *testing.T, key *Key, input, dep string) { key.Backend = "vm" }},
+		{"opt level changed", func(t *testing.T, key *Key, input, dep string) { key.OptLevel = "O2" }},
+		{"emit changed", func(t *testing.T, key *Key, input, dep string) { key.Emit = "asm" }},
+		{"debug info enabled", func(t *testing.T, key *Key, input, dep string) { key.DebugInfo = true }},
+		{"extra changed", func(t *testing.T, key *Key, input, dep string) { key.Extra = "strict" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, key, input, dep, _ := setup(t)
+			tt.change(t, &key, input, dep)
+
+			restored := filepath.Join(t.TempDir(), "app")
+			_, hit, err := c.Get(key, []string{restored})
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if hit {
+				t.Fatal("expected cache miss")
+			}
+			if _, err := os.Stat(restored); !os.IsNotExist(err) {
+				t.Errorf("output should not be written on a miss, stat err = %v", err)
+			}
+		})
+	}
+}
+
+func TestCachePutReplacesEntry(t *testing.T) {
+	c, key, _, _, artifact := setup(t)
+	if err := os.WriteFile(artifact, []byte("binary v2"), 0o755); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+	sidecar := artifact + ".map"
+	writeFile(t, sidecar, "{}")
+	if err := c.Put(key, Entry{Deps: key.Inputs}, []string{artifact, sidecar}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	outDir := t.TempDir()
+	outputs := []string{filepath.Join(outDir, "app"), filepath.Join(outDir, "app.map")}
+	if _, hit, err := c.Get(key, outputs[:1]); err != nil || hit {
+		t.Fatalf("an entry with more artifacts than outputs should miss, got hit=%v err=%v", hit, err)
+	}
+	if _, hit, err := c.Get(key, outputs); err != nil || !hit {
+		t.Fatalf("expected cache hit, got hit=%v err=%v", hit, err)
+	}
+	for i, want := range []string{"binary v2", "{}"} {
+		if data, err := os.ReadFile(outputs[i]); err != nil || string(data) != want {
+			t.Errorf("output %d = %q, %v; want %q", i, data, err, want)
+		}
+	}
+}
+
+func TestCacheHitRestoresWarnings(t *testing.T) {
+	c, key, input, _, artifact := setup(t)
+	warnings := []string{"warning: unused variable 'x'", "warning: unreachable code"}
+	if err := c.Put(key, Entry{Deps: []string{input}, Warnings: warnings}, []string{artifact}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	entry, hit, err := c.Get(key, []string{filepath.Join(t.TempDir(), "app")})
+	if err != nil || !hit {
+		t.Fatalf("expected cache hit, got hit=%v err=%v", hit, err)
+	}
+	if !reflect.DeepEqual(entry.Warnings, warnings) {
+		t.Errorf("restored warnings = %q, want %q", entry.Warnings, warnings)
+	}
+}
+
+func TestDefaultDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/tmp/xdg-cache")
+	dir, err := DefaultDir()
+	if err != nil {
+		t.Fatalf("DefaultDir: %v", err)
+	}
+	if dir != filepath.Join("/tmp/xdg-cache", "omni") {
+		t.Errorf("DefaultDir() = %s, want /tmp/xdg-cache/omni", dir)
+	}
+}
@@ -1,37 +1,88 @@
 package compiler
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"runtime"
 	"strings"
 
 	"github.com/omni-lang/omni/internal/ast"
 	cbackend "github.com/omni-lang/omni/internal/backend/c"
 	"github.com/omni-lang/omni/internal/backend/cranelift"
+	"github.com/omni-lang/omni/internal/lexer"
 	"github.com/omni-lang/omni/internal/logging"
 	"github.com/omni-lang/omni/internal/mir"
 	"github.com/omni-lang/omni/internal/mir/builder"
 	"github.com/omni-lang/omni/internal/mir/printer"
 	"github.com/omni-lang/omni/internal/parser"
 	"github.com/omni-lang/omni/internal/passes"
+	"github.com/omni-lang/omni/internal/target"
 	"github.com/omni-lang/omni/internal/types/checker"
 )
 
 // Config captures the minimal inputs needed to drive the compilation pipeline.
 type Config struct {
-	InputPath    string
-	OutputPath   string
-	Backend      string
-	OptLevel     string
-	Emit         string
-	Dump         string
+	InputPath  string
+	OutputPath string
+	Backend    string
+	OptLevel   string
+	Emit       string
+	Dump       string
+	// DumpPath is the file -dump cfg writes the Graphviz DOT source of the
+	// control-flow graphs to, and -dump ast or ast-json the syntax tree of
+	// the input; empty names it after the input with a .dot, .ast or
+	// .ast.json extension.
+	DumpPath     string
 	DebugInfo    bool
 	DebugModules bool
+	// DwarfVersion is the DWARF version of the debug information that
+	// DebugInfo builds ask the C compiler for; 0 means 4.
+	DwarfVersion int
+	// EmitC stops the C backend after code generation and writes the
+	// generated source to a .c file instead of invoking the C compiler.
+	EmitC bool
+	// InputPaths lists every source file compiled into the program. When
+	// set, its first entry replaces InputPath and names the outputs; the
+	// declarations of the remaining files are merged into it.
+	InputPaths []string
+	// ProfileBuild, when set, is the path of a Chrome Trace Event JSON file
+	// recording how long each compilation phase took.
+	ProfileBuild string
+	// Checks selects optional type-checker diagnostics such as dead code
+	// warnings (see checker.StrictOptions for the --strict set).
+	Checks checker.Options
+	// TargetOS and TargetArch select the platform the C backend builds for,
+	// using GOOS and GOARCH names. Empty fields default to the host.
+	TargetOS   string
+	TargetArch string
+	// Parallelism is the number of functions the C backend generates
+	// concurrently; 0 or 1 generates them one at a time.
+	Parallelism int
+	// DivCheck makes the C backend stop the program with an error when a
+	// division or modulo has a zero divisor. Debug builds always check.
+	DivCheck bool
+	// RecordDeps, when non-nil, receives the absolute paths of the input files
+	// and every module file loaded while compiling them. It is filled in even
+	// when compilation fails so that watchers can track broken imports.
+	RecordDeps *[]string
+	// Warnings, when non-nil, receives the warnings of the type checker
+	// instead of the log, so that the caller can report them itself.
+	Warnings *[]checker.Warning
+	// InlineThreshold is the instruction count below which functions are
+	// inlined into their callers. 0 inlines with
+	// passes.DefaultInlineThreshold above O0 and not at all at O0; a
+	// negative value disables inlining.
+	InlineThreshold int
+	// VerifyStrict makes -emit verify also reject blocks unreachable from
+	// the entry block and phis that do not match the predecessors of their
+	// block (see passes.VerifyStrict).
+	VerifyStrict bool
+
+	trace *eventRecorder
 }
 
 // ErrNotImplemented indicates that a requested stage has not yet been implemented.
@@ -39,11 +90,23 @@ var ErrNotImplemented = errors.New("not implemented")
 
 // Compile wires together the compiler pipeline. It currently serves as a thin
 // placeholder until the real frontend, midend and backend are ready.
-func Compile(cfg Config) error {
+func Compile(cfg Config) (err error) {
+	if len(cfg.InputPaths) > 0 {
+		cfg.InputPath = cfg.InputPaths[0]
+	}
 	if cfg.InputPath == "" {
 		return fmt.Errorf("input path required")
 	}
 
+	if cfg.ProfileBuild != "" {
+		cfg.trace = newEventRecorder(cfg.InputPath)
+		defer func() {
+			if flushErr := cfg.trace.flush(cfg.ProfileBuild); flushErr != nil && err == nil {
+				err = flushErr
+			}
+		}()
+	}
+
 	backend := cfg.Backend
 	if backend == "" {
 		backend = "c"
@@ -53,6 +116,12 @@ func Compile(cfg Config) error {
 	}
 
 	emit := cfg.Emit
+	if cfg.EmitC {
+		if backend != "c" {
+			return fmt.Errorf("%s backend: emitting C source requires the c backend", backend)
+		}
+		emit = "c"
+	}
 	if emit == "" {
 		if backend == "vm" {
 			emit = "mir"
@@ -63,21 +132,31 @@ func Compile(cfg Config) error {
 		}
 	}
 
-	switch backend {
-	case "vm":
-		if emit != "mir" {
+	// Verification stops before code generation, so every backend accepts it
+	switch {
+	case emit == "verify":
+	case backend == "vm":
+		if emit != "mir" && emit != "mir-binary" {
 			return fmt.Errorf("vm backend: emit option %q not supported", emit)
 		}
-	case "clift":
+	case backend == "clift":
 		if emit != "obj" && emit != "exe" && emit != "binary" && emit != "asm" {
 			return fmt.Errorf("clift backend: emit option %q not supported", emit)
 		}
-	case "c":
-		if emit != "exe" && emit != "asm" {
+	case backend == "c":
+		if emit != "exe" && emit != "asm" && emit != "c" {
 			return fmt.Errorf("c backend: emit option %q not supported", emit)
 		}
 	}
 
+	tgt, err := cfg.target()
+	if err != nil {
+		return err
+	}
+	if backend == "clift" && !tgt.IsHost() {
+		return fmt.Errorf("clift backend: cross-compilation to %s is not supported", tgt)
+	}
+
 	if cfg.OutputPath != "" {
 		if ext := filepath.Ext(cfg.OutputPath); ext == "" {
 			// Allow executables without extensions
@@ -87,49 +166,128 @@ func Compile(cfg Config) error {
 		}
 	}
 
-	src, err := os.ReadFile(cfg.InputPath)
-	if err != nil {
-		return fmt.Errorf("read input %s: %w", cfg.InputPath, err)
+	extraInputs := []string(nil)
+	if len(cfg.InputPaths) > 1 {
+		extraInputs = cfg.InputPaths[1:]
 	}
 
-	mod, err := parser.Parse(cfg.InputPath, string(src))
+	if cfg.RecordDeps != nil {
+		*cfg.RecordDeps = nil
+		for _, path := range append([]string{cfg.InputPath}, extraInputs...) {
+			if abs, err := filepath.Abs(path); err == nil {
+				*cfg.RecordDeps = append(*cfg.RecordDeps, abs)
+			}
+		}
+	}
+
+	mod, src, err := parseInput(cfg.InputPath, cfg.trace)
 	if err != nil {
 		return err
 	}
+	if cfg.Dump == "ast" || cfg.Dump == "ast-json" {
+		if err := dumpAST(cfg, mod); err != nil {
+			return err
+		}
+	}
+
+	// Additional input files share the root module's namespace, and their
+	// local imports are resolved relative to their own directories.
+	loader := NewModuleLoader()
+	for _, path := range extraInputs {
+		extra, _, err := parseInput(path, cfg.trace)
+		if err != nil {
+			return err
+		}
+		mergeInputModule(mod, extra)
+		if dir := filepath.Dir(path); dir != filepath.Dir(cfg.InputPath) {
+			if abs, err := filepath.Abs(dir); err == nil {
+				dir = abs
+			}
+			loader.AddSearchPath(dir)
+		}
+	}
 
 	// Merge locally imported modules' functions into the main module so the VM can resolve them
-	if err := MergeImportedModules(mod, filepath.Dir(cfg.InputPath), cfg.DebugModules, backend); err != nil {
+	if cfg.RecordDeps != nil {
+		defer func() { *cfg.RecordDeps = append(*cfg.RecordDeps, loader.LoadedFiles()...) }()
+	}
+	endImports := cfg.trace.begin("imports")
+	err = MergeImportedModulesWithLoader(mod, loader, filepath.Dir(cfg.InputPath), cfg.DebugModules, backend)
+	endImports()
+	if err != nil {
 		return err
 	}
 
-	if err := checker.Check(cfg.InputPath, string(src), mod); err != nil {
+	endCheck := cfg.trace.begin("typecheck")
+	warnings, err := checker.CheckWithOptions(cfg.InputPath, src, mod, cfg.Checks)
+	endCheck()
+	if cfg.Warnings != nil {
+		*cfg.Warnings = append(*cfg.Warnings, warnings...)
+	} else {
+		for _, warning := range warnings {
+			logging.Logger().WarnString(strings.TrimRight(warning.Error(), "\n"))
+		}
+	}
+	if err != nil {
 		return err
 	}
 
+	endBuild := cfg.trace.begin("mir-build")
 	mirMod, err := builder.BuildModule(mod)
+	endBuild()
 	if err != nil {
 		return err
 	}
 
+	if emit == "verify" {
+		defer cfg.trace.begin("pass:verify")()
+		return verifyMIR(cfg, mirMod)
+	}
+
 	// Run MIR passes (constant folding disabled temporarily due to loop variable issues)
-	if err := passes.Verify(mirMod); err != nil {
+	endVerify := cfg.trace.begin("pass:verify")
+	err = passes.JoinVerifyErrors(passes.Verify(mirMod))
+	endVerify()
+	if err != nil {
 		return err
 	}
+	if threshold := cfg.inlineThreshold(); threshold > 0 {
+		endInline := cfg.trace.begin("pass:inline")
+		err = passes.InliningPass{Threshold: threshold}.Run(mirMod)
+		if err == nil {
+			err = passes.JoinVerifyErrors(passes.Verify(mirMod))
+		}
+		endInline()
+		if err != nil {
+			return err
+		}
+	}
 	// TODO: Re-enable constant folding with proper handling of mutable variables
 	// pipeline := passes.NewPipeline("default")
 	// if _, err := pipeline.Run(*mirMod); err != nil {
 	// 	return err
 	// }
 
-	if cfg.Dump == "mir" {
+	switch cfg.Dump {
+	case "mir":
 		fmt.Println(printer.Format(mirMod))
+	case "cfg":
+		path := cfg.DumpPath
+		if path == "" {
+			path = strings.TrimSuffix(cfg.InputPath, filepath.Ext(cfg.InputPath)) + ".dot"
+		}
+		if err := os.WriteFile(path, []byte(mir.DumpCFG(mirMod)), 0o644); err != nil {
+			return fmt.Errorf("write CFG dump: %w", err)
+		}
 	}
 
 	switch backend {
 	case "vm":
+		defer cfg.trace.begin("codegen")()
 		return compileVM(cfg, emit, mirMod)
 	case "clift":
 		logging.Logger().InfoFields("Using Cranelift backend", logging.String("emit", emit))
+		defer cfg.trace.begin("codegen")()
 		return compileCraneliftBackend(cfg, emit, mirMod)
 	case "c":
 		return compileCBackend(cfg, emit, mirMod)
@@ -138,12 +296,133 @@ func Compile(cfg Config) error {
 	}
 }
 
+// dumpAST writes the syntax tree of the input file, before the imports are
+// merged into it, for -dump ast as printed by ast.Print and for -dump
+// ast-json as encoded by ast.MarshalJSON.
+func dumpAST(cfg Config, mod *ast.Module) error {
+	ext, data := ".ast", []byte(ast.Print(mod))
+	if cfg.Dump == "ast-json" {
+		encoded, err := ast.MarshalJSON(mod)
+		if err != nil {
+			return fmt.Errorf("encode AST dump: %w", err)
+		}
+		ext, data = ".ast.json", append(encoded, '\n')
+	}
+	path := cfg.DumpPath
+	if path == "" {
+		path = strings.TrimSuffix(cfg.InputPath, filepath.Ext(cfg.InputPath)) + ext
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write AST dump: %w", err)
+	}
+	return nil
+}
+
+// target returns the platform cfg builds for.
+func (cfg Config) target() (target.Target, error) {
+	tgt := target.Host()
+	if cfg.TargetOS != "" {
+		tgt.OS = cfg.TargetOS
+	}
+	if cfg.TargetArch != "" {
+		tgt.Arch = cfg.TargetArch
+	}
+	if tgt.IsHost() {
+		return tgt, nil
+	}
+	return tgt, tgt.Validate()
+}
+
+// dwarfVersion returns the DWARF version debug builds with cfg emit.
+func (cfg Config) dwarfVersion() int {
+	if cfg.DwarfVersion > 0 {
+		return cfg.DwarfVersion
+	}
+	return 4
+}
+
+// inlineThreshold returns the threshold of the inlining pass for cfg, or a
+// negative value when cfg does not inline.
+func (cfg Config) inlineThreshold() int {
+	if cfg.InlineThreshold != 0 {
+		return cfg.InlineThreshold
+	}
+	if cfg.OptLevel == "" || cfg.OptLevel == "O0" {
+		return -1
+	}
+	return passes.DefaultInlineThreshold
+}
+
+// parseInput reads, lexes and parses the source file at path.
+func parseInput(path string, trace *eventRecorder) (*ast.Module, string, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("read input %s: %w", path, err)
+	}
+
+	endLex := trace.begin("lex")
+	tokens, err := lexer.LexAll(path, string(src))
+	endLex()
+	if err != nil {
+		return nil, "", err
+	}
+
+	endParse := trace.begin("parse")
+	mod, err := parser.ParseTokens(path, string(src), tokens)
+	endParse()
+	if err != nil {
+		return nil, "", err
+	}
+	return mod, string(src), nil
+}
+
+// mergeInputModule appends the declarations of another input file to mod.
+// Imports that mod already has are dropped so that a module imported by
+// several input files is only merged once.
+func mergeInputModule(mod, extra *ast.Module) {
+	seen := make(map[string]bool)
+	for _, imp := range mod.Imports {
+		seen[importKey(imp)] = true
+	}
+	for _, d := range mod.Decls {
+		if imp, ok := d.(*ast.ImportDecl); ok {
+			seen[importKey(imp)] = true
+		}
+	}
+
+	addImport := func(imp *ast.ImportDecl) {
+		if key := importKey(imp); !seen[key] {
+			seen[key] = true
+			mod.Imports = append(mod.Imports, imp)
+		}
+	}
+	for _, imp := range extra.Imports {
+		addImport(imp)
+	}
+	for _, d := range extra.Decls {
+		if imp, ok := d.(*ast.ImportDecl); ok {
+			addImport(imp)
+			continue
+		}
+		mod.Decls = append(mod.Decls, d)
+	}
+}
+
+func importKey(imp *ast.ImportDecl) string {
+	return strings.Join(imp.Path, ".") + " as " + imp.Alias
+}
+
 // MergeImportedModules loads imported local modules and appends their function declarations
 // into the root module with namespaced names (aliasOrSegment.funcName) so that calls like
 // `math_utils.add` resolve at runtime. std imports are ignored for C backend (handled as intrinsics)
 // but loaded for VM backend.
 func MergeImportedModules(mod *ast.Module, baseDir string, debugModules bool, backend string) error {
-	loader := NewModuleLoader()
+	return MergeImportedModulesWithLoader(mod, NewModuleLoader(), baseDir, debugModules, backend)
+}
+
+// MergeImportedModulesWithLoader is MergeImportedModules with a caller-supplied
+// loader, e.g. one configured with an import map.
+func MergeImportedModulesWithLoader(mod *ast.Module, loader *ModuleLoader, baseDir string, debugModules bool, backend string) error {
 	logger := logging.Logger()
 
 	// Add the base directory for local modules
@@ -186,23 +465,7 @@ func MergeImportedModules(mod *ast.Module, baseDir string, debugModules bool, ba
 					return fmt.Errorf("load std import %s: %w", strings.Join(imp.Path, "."), err)
 				}
 
-				aliases := make([]string, 0, 2)
-				if imp.Alias != "" {
-					aliases = append(aliases, imp.Alias)
-				} else if len(imp.Path) > 0 {
-					aliases = append(aliases, imp.Path[len(imp.Path)-1])
-				}
-				qualified := strings.Join(imp.Path, ".")
-				additional := true
-				for _, a := range aliases {
-					if a == qualified {
-						additional = false
-						break
-					}
-				}
-				if qualified != "" && additional {
-					aliases = append(aliases, qualified)
-				}
+				aliases := importNamespaces(imp)
 
 				// Recursively merge nested std imports used within this module
 				if err := mergeNestedImports(imported, loader, mod, debugModules, map[string]bool{}); err != nil {
@@ -229,14 +492,20 @@ func MergeImportedModules(mod *ast.Module, baseDir string, debugModules bool, ba
 							cloned := *decl
 							cloned.Name = ns + "." + decl.Name
 							mod.Decls = append(mod.Decls, &cloned)
+						case *ast.LetDecl:
+							appendModuleConstant(mod, ns, decl)
 						}
 					}
 				}
 			} else {
-				// For C backend, skip std imports (handled as intrinsics)
+				// For C backend, std functions are handled as intrinsics; only
+				// the modules' constants are merged so they can be inlined.
 				if debugModules {
 					logger.DebugFields("Skipping std import (handled as intrinsic)", logging.String("path", strings.Join(imp.Path, ".")))
 				}
+				if err := mergeStdConstants(imp, loader, mod, map[string]bool{}); err != nil {
+					return err
+				}
 			}
 			continue
 		}
@@ -272,6 +541,8 @@ func MergeImportedModules(mod *ast.Module, baseDir string, debugModules bool, ba
 				cloned := *decl
 				cloned.Name = local + "." + decl.Name
 				mod.Decls = append(mod.Decls, &cloned)
+			case *ast.LetDecl:
+				appendModuleConstant(mod, local, decl)
 			}
 		}
 	}
@@ -313,23 +584,7 @@ func mergeNestedImports(module *ast.Module, loader *ModuleLoader, targetMod *ast
 			}
 
 			// Merge the functions with the target module
-			aliases := make([]string, 0, 2)
-			if imp.Alias != "" {
-				aliases = append(aliases, imp.Alias)
-			} else if len(imp.Path) > 0 {
-				aliases = append(aliases, imp.Path[len(imp.Path)-1])
-			}
-			qualified := strings.Join(imp.Path, ".")
-			additional := true
-			for _, a := range aliases {
-				if a == qualified {
-					additional = false
-					break
-				}
-			}
-			if qualified != "" && additional {
-				aliases = append(aliases, qualified)
-			}
+			aliases := importNamespaces(imp)
 
 			for _, ns := range aliases {
 				for _, d := range imported.Decls {
@@ -350,6 +605,8 @@ func mergeNestedImports(module *ast.Module, loader *ModuleLoader, targetMod *ast
 						cloned := *decl
 						cloned.Name = ns + "." + decl.Name
 						targetMod.Decls = append(targetMod.Decls, &cloned)
+					case *ast.LetDecl:
+						appendModuleConstant(targetMod, ns, decl)
 					}
 				}
 			}
@@ -358,6 +615,84 @@ func mergeNestedImports(module *ast.Module, loader *ModuleLoader, targetMod *ast
 	return nil
 }
 
+// importNamespaces returns the names an import's declarations are merged
+// under: its alias (or last path segment) and its fully qualified path.
+func importNamespaces(imp *ast.ImportDecl) []string {
+	aliases := make([]string, 0, 2)
+	if imp.Alias != "" {
+		aliases = append(aliases, imp.Alias)
+	} else if len(imp.Path) > 0 {
+		aliases = append(aliases, imp.Path[len(imp.Path)-1])
+	}
+	qualified := strings.Join(imp.Path, ".")
+	additional := true
+	for _, a := range aliases {
+		if a == qualified {
+			additional = false
+			break
+		}
+	}
+	if qualified != "" && additional {
+		aliases = append(aliases, qualified)
+	}
+	return aliases
+}
+
+// appendModuleConstant merges a module-level let binding with a literal
+// initializer (e.g. signal.SIGINT) into mod under ns. The MIR builder inlines
+// these constants, so a name reached through several imports is merged once.
+func appendModuleConstant(mod *ast.Module, ns string, decl *ast.LetDecl) {
+	if _, ok := decl.Value.(*ast.LiteralExpr); !ok {
+		return
+	}
+	name := ns + "." + decl.Name
+	for _, d := range mod.Decls {
+		if existing, ok := d.(*ast.LetDecl); ok && existing.Name == name {
+			return
+		}
+	}
+	cloned := *decl
+	cloned.Name = name
+	mod.Decls = append(mod.Decls, &cloned)
+}
+
+// mergeStdConstants merges the constants of a std import, and of the std
+// modules it imports, into targetMod without merging its functions.
+func mergeStdConstants(imp *ast.ImportDecl, loader *ModuleLoader, targetMod *ast.Module, visited map[string]bool) error {
+	key := strings.Join(imp.Path, ".")
+	if visited[key] {
+		return nil
+	}
+	visited[key] = true
+
+	imported, err := loader.LoadModule(imp.Path)
+	if err != nil {
+		return fmt.Errorf("load std import %s: %w", key, err)
+	}
+	nested := append([]*ast.ImportDecl{}, imported.Imports...)
+	for _, d := range imported.Decls {
+		if nestedImp, ok := d.(*ast.ImportDecl); ok {
+			nested = append(nested, nestedImp)
+		}
+	}
+	for _, nestedImp := range nested {
+		if len(nestedImp.Path) > 0 && nestedImp.Path[0] == "std" {
+			if err := mergeStdConstants(nestedImp, loader, targetMod, visited); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, ns := range importNamespaces(imp) {
+		for _, d := range imported.Decls {
+			if decl, ok := d.(*ast.LetDecl); ok {
+				appendModuleConstant(targetMod, ns, decl)
+			}
+		}
+	}
+	return nil
+}
+
 func compileVM(cfg Config, emit string, mod *mir.Module) error {
 	output := cfg.OutputPath
 	if output == "" {
@@ -367,6 +702,17 @@ func compileVM(cfg Config, emit string, mod *mir.Module) error {
 		return err
 	}
 
+	if emit == "mir-binary" {
+		var buf bytes.Buffer
+		if err := mir.EncodeBinary(mod, &buf); err != nil {
+			return err
+		}
+		if err := os.WriteFile(output, buf.Bytes(), 0o644); err != nil {
+			return fmt.Errorf("write mir output: %w", err)
+		}
+		return nil
+	}
+
 	rendered := printer.Format(mod)
 	if !strings.HasSuffix(rendered, "\n") {
 		rendered += "\n"
@@ -377,11 +723,31 @@ func compileVM(cfg Config, emit string, mod *mir.Module) error {
 	return nil
 }
 
+// verifyMIR runs the MIR verifier over mod for -emit verify, in strict mode
+// when cfg asks for it, and reports every problem found.
+func verifyMIR(cfg Config, mod *mir.Module) error {
+	errs := passes.Verify(mod)
+	if cfg.VerifyStrict {
+		errs = passes.VerifyStrict(mod)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("MIR verification found %d problem(s):\n%w", len(errs), passes.JoinVerifyErrors(errs))
+}
+
 // compileCBackend compiles MIR using the C backend
 func compileCBackend(cfg Config, emit string, mod *mir.Module) error {
+	tgt, err := cfg.target()
+	if err != nil {
+		return err
+	}
 	output := cfg.OutputPath
 	if output == "" {
 		output = defaultOutputPath(cfg.InputPath, emit)
+		if emit == "exe" {
+			output += tgt.ExeSuffix()
+		}
 	}
 	if err := ensureDir(output); err != nil {
 		return err
@@ -390,23 +756,68 @@ func compileCBackend(cfg Config, emit string, mod *mir.Module) error {
 	switch emit {
 	case "exe":
 		if cfg.DebugInfo {
-			return compileCToExecutableWithDebug(mod, output, cfg.OptLevel, cfg.InputPath)
+			return compileCToExecutableWithDebug(mod, output, cfg.OptLevel, cfg.InputPath, cfg.dwarfVersion(), cfg.Parallelism, tgt, cfg.trace)
 		} else if cfg.OptLevel != "O0" {
-			return compileCToExecutableWithOpt(mod, output, cfg.OptLevel)
+			return compileCToExecutableWithOpt(mod, output, cfg.OptLevel, cfg.Parallelism, cfg.DivCheck, tgt, cfg.trace)
 		} else {
-			return compileCToExecutable(mod, output)
+			return compileCToExecutable(mod, output, cfg.Parallelism, cfg.DivCheck, tgt, cfg.trace)
 		}
 	case "asm":
-		return compileToAssembly(mod, output)
+		defer cfg.trace.begin("codegen")()
+		return compileToAssembly(mod, output, cfg.Parallelism, cfg.DivCheck, tgt)
+	case "c":
+		defer cfg.trace.begin("codegen")()
+		return emitCSource(mod, output, cfg)
 	default:
 		return fmt.Errorf("c backend: emit option %q not supported", emit)
 	}
 }
 
+// emitCSource writes the C translation of mod to outputPath, using the same
+// generator settings as an executable build with cfg.
+func emitCSource(mod *mir.Module, outputPath string, cfg Config) error {
+	var gen *cbackend.CGenerator
+	switch {
+	case cfg.DebugInfo:
+		gen = cbackend.NewCGeneratorWithDebug(mod, cfg.OptLevel, true, cfg.InputPath)
+	case cfg.OptLevel != "O0":
+		gen = cbackend.NewCGeneratorWithOptLevel(mod, cfg.OptLevel)
+	default:
+		gen = cbackend.NewCGenerator(mod)
+	}
+	gen.SetParallelism(cfg.Parallelism)
+	gen.SetDivCheck(cfg.DivCheck)
+	cCode, err := gen.Generate()
+	logCodegenWarnings(gen)
+	if err != nil {
+		return fmt.Errorf("failed to generate C code: %w", err)
+	}
+	if err := os.WriteFile(outputPath, []byte(cCode), 0o644); err != nil {
+		return fmt.Errorf("failed to write C code: %w", err)
+	}
+	return nil
+}
+
+// logCodegenWarnings forwards the warnings of the C backend to the logger.
+// Its errors need no forwarding: they make up the error Generate returns.
+func logCodegenWarnings(gen *cbackend.CGenerator) {
+	for _, diag := range gen.Diagnostics() {
+		if diag.Level == cbackend.LevelWarning {
+			logging.Logger().WarnString(diag.Error())
+		}
+	}
+}
+
 // compileCToExecutable compiles MIR to executable using C backend
-func compileCToExecutable(mod *mir.Module, outputPath string) error {
+func compileCToExecutable(mod *mir.Module, outputPath string, parallelism int, divCheck bool, tgt target.Target, rec *eventRecorder) error {
 	// Generate C code
-	cCode, err := cbackend.GenerateC(mod)
+	endCodegen := rec.begin("codegen")
+	gen := cbackend.NewCGenerator(mod)
+	gen.SetParallelism(parallelism)
+	gen.SetDivCheck(divCheck)
+	cCode, err := gen.Generate()
+	logCodegenWarnings(gen)
+	endCodegen()
 	if err != nil {
 		return fmt.Errorf("failed to generate C code: %w", err)
 	}
@@ -418,7 +829,10 @@ func compileCToExecutable(mod *mir.Module, outputPath string) error {
 	}
 
 	// Compile C code to executable
-	if err := compileCWrapper(cPath, outputPath); err != nil {
+	endLink := rec.begin("link")
+	err = compileCWrapper(cPath, outputPath, tgt)
+	endLink()
+	if err != nil {
 		return fmt.Errorf("failed to compile C code: %w", err)
 	}
 
@@ -429,9 +843,15 @@ func compileCToExecutable(mod *mir.Module, outputPath string) error {
 }
 
 // compileCToExecutableWithOpt compiles MIR to optimized executable using C backend
-func compileCToExecutableWithOpt(mod *mir.Module, outputPath string, optLevel string) error {
+func compileCToExecutableWithOpt(mod *mir.Module, outputPath string, optLevel string, parallelism int, divCheck bool, tgt target.Target, rec *eventRecorder) error {
 	// Generate optimized C code
-	cCode, err := cbackend.GenerateCOptimized(mod, optLevel)
+	endCodegen := rec.begin("codegen")
+	gen := cbackend.NewCGeneratorWithOptLevel(mod, optLevel)
+	gen.SetParallelism(parallelism)
+	gen.SetDivCheck(divCheck)
+	cCode, err := gen.Generate()
+	logCodegenWarnings(gen)
+	endCodegen()
 	if err != nil {
 		return fmt.Errorf("failed to generate optimized C code: %w", err)
 	}
@@ -443,7 +863,10 @@ func compileCToExecutableWithOpt(mod *mir.Module, outputPath string, optLevel st
 	}
 
 	// Compile C code to executable with optimization
-	if err := compileCWrapperWithOpt(cPath, outputPath, optLevel); err != nil {
+	endLink := rec.begin("link")
+	err = compileCWrapperWithOpt(cPath, outputPath, optLevel, tgt)
+	endLink()
+	if err != nil {
 		return fmt.Errorf("failed to compile optimized C code: %w", err)
 	}
 
@@ -454,10 +877,14 @@ func compileCToExecutableWithOpt(mod *mir.Module, outputPath string, optLevel st
 }
 
 // compileCToExecutableWithDebug compiles MIR to debug executable using C backend
-func compileCToExecutableWithDebug(mod *mir.Module, outputPath string, optLevel string, sourceFile string) error {
+func compileCToExecutableWithDebug(mod *mir.Module, outputPath string, optLevel string, sourceFile string, dwarfVersion int, parallelism int, tgt target.Target, rec *eventRecorder) error {
 	// Generate C code with debug information
+	endCodegen := rec.begin("codegen")
 	gen := cbackend.NewCGeneratorWithDebug(mod, optLevel, true, sourceFile)
+	gen.SetParallelism(parallelism)
 	cCode, err := gen.Generate()
+	logCodegenWarnings(gen)
+	endCodegen()
 	if err != nil {
 		return fmt.Errorf("failed to generate C code with debug: %w", err)
 	}
@@ -471,7 +898,7 @@ func compileCToExecutableWithDebug(mod *mir.Module, outputPath string, optLevel
 	// Generate source map if debug info is enabled
 	sourceMap := gen.GenerateSourceMap()
 	if sourceMap != nil {
-		sourceMapPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".map"
+		sourceMapPath := SourceMapPath(outputPath)
 		sourceMapJSON, err := json.MarshalIndent(sourceMap, "", "  ")
 		if err != nil {
 			return fmt.Errorf("failed to marshal source map: %w", err)
@@ -482,7 +909,10 @@ func compileCToExecutableWithDebug(mod *mir.Module, outputPath string, optLevel
 	}
 
 	// Compile C code to executable with debug symbols
-	if err := compileCWrapperWithDebug(cPath, outputPath, optLevel); err != nil {
+	endLink := rec.begin("link")
+	err = compileCWrapperWithDebug(cPath, outputPath, optLevel, dwarfVersion, tgt)
+	endLink()
+	if err != nil {
 		return fmt.Errorf("failed to compile C code with debug: %w", err)
 	}
 
@@ -507,14 +937,14 @@ func compileCraneliftBackend(cfg Config, emit string, mod *mir.Module) error {
 		return compileToObject(mod, output)
 	case "exe", "binary":
 		if cfg.DebugInfo {
-			return compileToExecutableWithDebug(mod, output, cfg.OptLevel, cfg.InputPath)
+			return compileToExecutableWithDebug(mod, output, cfg.OptLevel, cfg.InputPath, cfg.dwarfVersion())
 		}
 		if cfg.OptLevel != "" {
 			return compileToExecutableWithOpt(mod, output, cfg.OptLevel)
 		}
 		return compileToExecutable(mod, output)
 	case "asm":
-		return compileToAssembly(mod, output)
+		return compileToAssembly(mod, output, cfg.Parallelism, cfg.DivCheck, target.Host())
 	default:
 		return fmt.Errorf("unsupported emit format: %s", emit)
 	}
@@ -539,12 +969,12 @@ func compileToExecutable(mod *mir.Module, outputPath string) error {
 
 	// Create a C wrapper that links with the runtime
 	cPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".c"
-	if err := generateCWrapper(mod, cPath); err != nil {
+	if err := generateCWrapper(mod, cPath, 0, false); err != nil {
 		return fmt.Errorf("failed to generate C wrapper: %w", err)
 	}
 
 	// Compile the C wrapper with the runtime
-	if err := compileCWrapper(cPath, outputPath); err != nil {
+	if err := compileCWrapper(cPath, outputPath, target.Host()); err != nil {
 		return fmt.Errorf("failed to compile C wrapper: %w", err)
 	}
 
@@ -569,7 +999,7 @@ func compileToExecutableWithOpt(mod *mir.Module, outputPath string, optLevel str
 	}
 
 	// Compile the C wrapper with the runtime
-	if err := compileCWrapperWithOpt(cPath, outputPath, optLevel); err != nil {
+	if err := compileCWrapperWithOpt(cPath, outputPath, optLevel, target.Host()); err != nil {
 		return fmt.Errorf("failed to compile C wrapper: %w", err)
 	}
 
@@ -580,7 +1010,7 @@ func compileToExecutableWithOpt(mod *mir.Module, outputPath string, optLevel str
 	return nil
 }
 
-func compileToExecutableWithDebug(mod *mir.Module, outputPath string, optLevel string, sourceFile string) error {
+func compileToExecutableWithDebug(mod *mir.Module, outputPath string, optLevel string, sourceFile string, dwarfVersion int) error {
 	// First compile to object file
 	objPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".o"
 	if err := compileToObject(mod, objPath); err != nil {
@@ -594,7 +1024,7 @@ func compileToExecutableWithDebug(mod *mir.Module, outputPath string, optLevel s
 	}
 
 	// Compile the C wrapper with the runtime and debug symbols
-	if err := compileCWrapperWithDebug(cPath, outputPath, optLevel); err != nil {
+	if err := compileCWrapperWithDebug(cPath, outputPath, optLevel, dwarfVersion, target.Host()); err != nil {
 		return fmt.Errorf("failed to compile C wrapper: %w", err)
 	}
 
@@ -605,15 +1035,15 @@ func compileToExecutableWithDebug(mod *mir.Module, outputPath string, optLevel s
 	return nil
 }
 
-func compileToAssembly(mod *mir.Module, outputPath string) error {
+func compileToAssembly(mod *mir.Module, outputPath string, parallelism int, divCheck bool, tgt target.Target) error {
 	// First generate C code
 	cPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".c"
-	if err := generateCWrapper(mod, cPath); err != nil {
+	if err := generateCWrapper(mod, cPath, parallelism, divCheck); err != nil {
 		return fmt.Errorf("failed to generate C code: %w", err)
 	}
 
 	// Compile C to assembly
-	if err := compileCToAssembly(cPath, outputPath); err != nil {
+	if err := compileCToAssembly(cPath, outputPath, tgt); err != nil {
 		return fmt.Errorf("failed to compile C to assembly: %w", err)
 	}
 
@@ -623,7 +1053,7 @@ func compileToAssembly(mod *mir.Module, outputPath string) error {
 	return nil
 }
 
-func compileCToAssembly(cPath, asmPath string) error {
+func compileCToAssembly(cPath, asmPath string, tgt target.Target) error {
 	// Find the runtime directory
 	runtimeDir := findRuntimeDir()
 	if runtimeDir == "" {
@@ -631,7 +1061,7 @@ func compileCToAssembly(cPath, asmPath string) error {
 	}
 
 	// Determine target platform
-	targetOS, targetArch := getTargetPlatform()
+	targetOS, targetArch := getTargetPlatform(tgt)
 
 	// First compile the runtime to assembly
 	runtimeAsmPath := strings.TrimSuffix(asmPath, filepath.Ext(asmPath)) + "_rt.s"
@@ -661,7 +1091,8 @@ func compileCToAssembly(cPath, asmPath string) error {
 		runtimeArgs = append(runtimeArgs, "-DARCH_ARM64")
 	}
 
-	cmd := exec.Command("gcc", runtimeArgs...)
+	cc, ccFlags := cCompiler(tgt)
+	cmd := exec.Command(cc, append(ccFlags, runtimeArgs...)...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
@@ -695,7 +1126,7 @@ func compileCToAssembly(cPath, asmPath string) error {
 		mainArgs = append(mainArgs, "-DARCH_ARM64")
 	}
 
-	cmd = exec.Command("gcc", mainArgs...)
+	cmd = exec.Command(cc, append(ccFlags, mainArgs...)...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
@@ -708,7 +1139,7 @@ func compileCToAssembly(cPath, asmPath string) error {
 	return nil
 }
 
-func compileCWrapperWithOpt(cPath, outputPath string, optLevel string) error {
+func compileCWrapperWithOpt(cPath, outputPath string, optLevel string, tgt target.Target) error {
 	// Find the runtime directory
 	runtimeDir := findRuntimeDir()
 	if runtimeDir == "" {
@@ -716,7 +1147,7 @@ func compileCWrapperWithOpt(cPath, outputPath string, optLevel string) error {
 	}
 
 	// Determine target platform
-	targetOS, targetArch := getTargetPlatform()
+	targetOS, targetArch := getTargetPlatform(tgt)
 
 	// Compile with platform-specific flags and optimization
 	args := []string{
@@ -762,7 +1193,8 @@ func compileCWrapperWithOpt(cPath, outputPath string, optLevel string) error {
 		args = append(args, "-DARCH_ARM64")
 	}
 
-	cmd := exec.Command("gcc", args...)
+	cc, ccFlags := cCompiler(tgt)
+	cmd := exec.Command(cc, append(ccFlags, args...)...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
@@ -772,7 +1204,7 @@ func compileCWrapperWithOpt(cPath, outputPath string, optLevel string) error {
 	return nil
 }
 
-func compileCWrapperWithDebug(cPath, outputPath string, optLevel string) error {
+func compileCWrapperWithDebug(cPath, outputPath string, optLevel string, dwarfVersion int, tgt target.Target) error {
 	// Find the runtime directory
 	runtimeDir := findRuntimeDir()
 	if runtimeDir == "" {
@@ -780,7 +1212,7 @@ func compileCWrapperWithDebug(cPath, outputPath string, optLevel string) error {
 	}
 
 	// Determine target platform
-	targetOS, targetArch := getTargetPlatform()
+	targetOS, targetArch := getTargetPlatform(tgt)
 
 	// Compile with platform-specific flags, optimization, and debug symbols
 	args := []string{
@@ -792,6 +1224,7 @@ func compileCWrapperWithDebug(cPath, outputPath string, optLevel string) error {
 		"-Wall",
 		"-Wextra",
 		"-g", // Generate debug symbols
+		fmt.Sprintf("-gdwarf-%d", dwarfVersion),
 		"-lm",
 	}
 
@@ -827,7 +1260,8 @@ func compileCWrapperWithDebug(cPath, outputPath string, optLevel string) error {
 		args = append(args, "-DARCH_ARM64")
 	}
 
-	cmd := exec.Command("gcc", args...)
+	cc, ccFlags := cCompiler(tgt)
+	cmd := exec.Command(cc, append(ccFlags, args...)...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
@@ -837,9 +1271,13 @@ func compileCWrapperWithDebug(cPath, outputPath string, optLevel string) error {
 	return nil
 }
 
-func generateCWrapper(mod *mir.Module, cPath string) error {
+func generateCWrapper(mod *mir.Module, cPath string, parallelism int, divCheck bool) error {
 	// Generate C code from MIR module
-	cCode, err := cbackend.GenerateC(mod)
+	gen := cbackend.NewCGenerator(mod)
+	gen.SetParallelism(parallelism)
+	gen.SetDivCheck(divCheck)
+	cCode, err := gen.Generate()
+	logCodegenWarnings(gen)
 	if err != nil {
 		return fmt.Errorf("generate C code: %w", err)
 	}
@@ -879,7 +1317,7 @@ func generateCWrapperWithDebug(mod *mir.Module, cPath string, optLevel string, d
 	return nil
 }
 
-func compileCWrapper(cPath, outputPath string) error {
+func compileCWrapper(cPath, outputPath string, tgt target.Target) error {
 	// Find the runtime directory
 	runtimeDir := findRuntimeDir()
 	if runtimeDir == "" {
@@ -887,7 +1325,7 @@ func compileCWrapper(cPath, outputPath string) error {
 	}
 
 	// Determine target platform
-	targetOS, targetArch := getTargetPlatform()
+	targetOS, targetArch := getTargetPlatform(tgt)
 
 	// Compile with platform-specific flags
 	args := []string{
@@ -917,7 +1355,8 @@ func compileCWrapper(cPath, outputPath string) error {
 		args = append(args, "-DARCH_ARM64")
 	}
 
-	cmd := exec.Command("gcc", args...)
+	cc, ccFlags := cCompiler(tgt)
+	cmd := exec.Command(cc, append(ccFlags, args...)...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
@@ -927,10 +1366,20 @@ func compileCWrapper(cPath, outputPath string) error {
 	return nil
 }
 
-func getTargetPlatform() (string, string) {
-	// Get current platform information
-	os := runtime.GOOS
-	arch := runtime.GOARCH
+// cCompiler returns the C compiler to run for tgt and the flags that select
+// the target. Native builds use gcc; cross builds use clang, which can
+// target every supported platform from a single installation.
+func cCompiler(tgt target.Target) (string, []string) {
+	if tgt.IsHost() {
+		return "gcc", nil
+	}
+	return "clang", []string{"-target", tgt.Triple()}
+}
+
+func getTargetPlatform(tgt target.Target) (string, string) {
+	// Get target platform information
+	os := tgt.OS
+	arch := tgt.Arch
 
 	// Map Go platform names to standard names
 	osMap := map[string]string{
@@ -1039,13 +1488,29 @@ func ensureDir(path string) error {
 	return nil
 }
 
+// DefaultOutputPath returns the file Compile writes for input when
+// Config.OutputPath is empty.
+func DefaultOutputPath(input, emit string) string {
+	return defaultOutputPath(input, emit)
+}
+
+// SourceMapPath returns where a debug build of the C backend writes the
+// source map for the executable at output.
+func SourceMapPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".map"
+}
+
 func defaultOutputPath(input, emit string) string {
 	base := strings.TrimSuffix(input, filepath.Ext(input))
 	switch emit {
 	case "mir":
 		return base + ".mir"
+	case "mir-binary":
+		return base + ".mirb"
 	case "asm":
 		return base + ".s"
+	case "c":
+		return base + ".c"
 	case "obj":
 		return base + ".o"
 	case "exe", "binary":
@@ -1,7 +1,17 @@
 package compiler
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/mir/printer"
+	"github.com/omni-lang/omni/internal/testutil/snapshots"
 )
 
 func TestCompile(t *testing.T) {
@@ -109,3 +119,314 @@ func TestErrNotImplemented(t *testing.T) {
 		t.Errorf("Expected ErrNotImplemented to be 'not implemented', got '%s'", ErrNotImplemented.Error())
 	}
 }
+
+func TestCompileRecordsDeps(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "main.omni")
+	utils := filepath.Join(dir, "utils.omni")
+	if err := os.WriteFile(utils, []byte("func add(a:int, b:int):int {\n    return a + b\n}\n"), 0o644); err != nil {
+		t.Fatalf("write utils: %v", err)
+	}
+	source := "import utils\n\nfunc main():int {\n    return utils.add(1, 2) - 3\n}\n"
+	if err := os.WriteFile(input, []byte(source), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	var deps []string
+	if err := Compile(Config{InputPath: input, Backend: "vm", Emit: "mir", RecordDeps: &deps}); err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	if len(deps) != 2 || deps[0] != input || deps[1] != utils {
+		t.Errorf("got deps %v, want [%s %s]", deps, input, utils)
+	}
+
+	// A broken import is still recorded so watch mode notices when it is fixed.
+	if err := os.WriteFile(utils, []byte("func add(:int\n"), 0o644); err != nil {
+		t.Fatalf("write utils: %v", err)
+	}
+	if err := Compile(Config{InputPath: input, Backend: "vm", Emit: "mir", RecordDeps: &deps}); err == nil {
+		t.Fatal("expected compile to fail on the broken import")
+	}
+	if len(deps) != 2 || deps[1] != utils {
+		t.Errorf("got deps %v after failed compile, want %s recorded", deps, utils)
+	}
+}
+
+func TestCompileMultipleInputs(t *testing.T) {
+	dir := t.TempDir()
+	libDir := filepath.Join(dir, "lib")
+	if err := os.MkdirAll(libDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	files := map[string]string{
+		filepath.Join(dir, "main.omni"):      "import std.io as io\n\nfunc main():int {\n    io.println(greeting())\n    return helper(2)\n}\n",
+		filepath.Join(libDir, "helper.omni"): "import std.io as io\nimport mathx\n\nfunc helper(x:int):int {\n    io.println(\"helper\")\n    return mathx.double(x) - 4\n}\n",
+		filepath.Join(libDir, "mathx.omni"):  "func double(x:int):int {\n    return x * 2\n}\n",
+		filepath.Join(dir, "greeting.omni"):  "func greeting():string {\n    return \"hi\"\n}\n",
+	}
+	for path, src := range files {
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+
+	inputs := []string{filepath.Join(dir, "main.omni"), filepath.Join(libDir, "helper.omni"), filepath.Join(dir, "greeting.omni")}
+	output := filepath.Join(dir, "out.mir")
+	var deps []string
+	cfg := Config{InputPaths: inputs, OutputPath: output, Backend: "vm", Emit: "mir", RecordDeps: &deps}
+	if err := Compile(cfg); err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+
+	mir, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	for _, fn := range []string{"func main", "func helper", "func greeting", "func mathx.double"} {
+		if !strings.Contains(string(mir), fn) {
+			t.Errorf("expected %q in MIR output", fn)
+		}
+	}
+	if len(deps) < len(inputs) || deps[0] != inputs[0] || deps[1] != inputs[1] || deps[2] != inputs[2] {
+		t.Errorf("got deps %v, want the inputs first", deps)
+	}
+}
+
+func TestEmitCGoldens(t *testing.T) {
+	goldenDir := filepath.Join("..", "..", "tests", "goldens", "c")
+	inputs, err := filepath.Glob(filepath.Join(goldenDir, "*.omni"))
+	if err != nil {
+		t.Fatalf("glob goldens: %v", err)
+	}
+	if len(inputs) == 0 {
+		t.Fatalf("no C goldens found in %s", goldenDir)
+	}
+
+	for _, input := range inputs {
+		base := strings.TrimSuffix(filepath.Base(input), ".omni")
+		t.Run(base, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), base+".c")
+			if err := Compile(Config{InputPath: input, OutputPath: output, Backend: "c", OptLevel: "O0", EmitC: true}); err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatalf("read emitted C: %v", err)
+			}
+			snapshots.CompareText(t, string(data), filepath.Join(goldenDir, base+".c"))
+		})
+	}
+}
+
+// TestDumpASTGoldens dumps the syntax tree of each AST golden input and
+// compares it with the golden. The dump is written before type checking, so
+// the snippets that do not compile still dump.
+func TestDumpASTGoldens(t *testing.T) {
+	goldenDir := filepath.Join("..", "..", "tests", "goldens", "ast")
+	inputs, err := filepath.Glob(filepath.Join(goldenDir, "*.omni"))
+	if err != nil {
+		t.Fatalf("glob goldens: %v", err)
+	}
+	if len(inputs) == 0 {
+		t.Fatalf("no AST goldens found in %s", goldenDir)
+	}
+
+	for _, input := range inputs {
+		base := strings.TrimSuffix(filepath.Base(input), ".omni")
+		t.Run(base, func(t *testing.T) {
+			dir := t.TempDir()
+			dump := filepath.Join(dir, base+".ast")
+			_ = Compile(Config{InputPath: input, OutputPath: filepath.Join(dir, base+".mir"), Backend: "vm", Dump: "ast", DumpPath: dump})
+			data, err := os.ReadFile(dump)
+			if err != nil {
+				t.Fatalf("read AST dump: %v", err)
+			}
+			snapshots.CompareText(t, string(data), filepath.Join(goldenDir, base+".ast"))
+		})
+	}
+}
+
+func TestDumpASTJSON(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "main.omni")
+	if err := os.WriteFile(input, []byte("func main():int {\n    return 40 + 2\n}\n"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	if err := Compile(Config{InputPath: input, Backend: "vm", Dump: "ast-json"}); err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "main.ast.json"))
+	if err != nil {
+		t.Fatalf("read AST dump: %v", err)
+	}
+	for _, want := range []string{`"node": "FuncDecl"`, `"node": "BinaryExpr"`, `"span": {`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON dump missing %s:\n%s", want, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main.mir")); err != nil {
+		t.Errorf("compilation did not continue after the dump: %v", err)
+	}
+}
+
+// TestCBackendArrayReturn builds and runs the array_return golden: arrays
+// are heap-allocated, so one created in a callee survives the return.
+func TestCBackendArrayReturn(t *testing.T) {
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("gcc not available")
+	}
+	input := filepath.Join("..", "..", "tests", "goldens", "c", "array_return.omni")
+	output := filepath.Join(t.TempDir(), "array_return")
+	if err := Compile(Config{InputPath: input, OutputPath: output, Backend: "c", OptLevel: "O0"}); err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	err := exec.Command(output).Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 42 {
+		t.Fatalf("run array_return: %v, want exit status 42", err)
+	}
+}
+
+// TestCBackendTryCatch builds and runs the try_catch golden: exceptions
+// thrown in a callee longjmp to the handler of their type, and the finally
+// block runs whether or not one caught them.
+func TestCBackendTryCatch(t *testing.T) {
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("gcc not available")
+	}
+	input := filepath.Join("..", "..", "tests", "goldens", "c", "try_catch.omni")
+	output := filepath.Join(t.TempDir(), "try_catch")
+	if err := Compile(Config{InputPath: input, OutputPath: output, Backend: "c", OptLevel: "O0"}); err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	err := exec.Command(output).Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 42 {
+		t.Fatalf("run try_catch: %v, want exit status 42", err)
+	}
+}
+
+// TestCBackendDebugInfo builds with debug info and checks the DWARF info for
+// the program's functions at the requested version.
+func TestCBackendDebugInfo(t *testing.T) {
+	for _, tool := range []string{"gcc", "readelf"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not available", tool)
+		}
+	}
+	dir := t.TempDir()
+	input := filepath.Join(dir, "debug.omni")
+	src := "func triple(x:int):int {\n    return x * 3\n}\n\nfunc main():int {\n    return triple(14)\n}\n"
+	if err := os.WriteFile(input, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		dwarfVersion int
+		want         string
+	}{
+		{0, "Version:       4"},
+		{5, "Version:       5"},
+	} {
+		output := filepath.Join(dir, fmt.Sprintf("debug%d", tc.dwarfVersion))
+		cfg := Config{InputPath: input, OutputPath: output, Backend: "c", OptLevel: "O0", DebugInfo: true, DwarfVersion: tc.dwarfVersion}
+		if err := Compile(cfg); err != nil {
+			t.Fatalf("Compile: %v", err)
+		}
+
+		out, err := exec.Command("readelf", "--debug-dump=info", output).CombinedOutput()
+		if err != nil {
+			t.Fatalf("readelf: %v\n%s", err, out)
+		}
+		info := string(out)
+		if !strings.Contains(info, tc.want) {
+			t.Errorf("DwarfVersion %d: debug info lacks %q", tc.dwarfVersion, tc.want)
+		}
+		for _, name := range []string{"triple", "omni_main"} {
+			if !strings.Contains(info, ": "+name+"\n") {
+				t.Errorf("DwarfVersion %d: debug info has no entry for %s", tc.dwarfVersion, name)
+			}
+		}
+	}
+}
+
+func TestEmitCRequiresCBackend(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "main.omni")
+	if err := os.WriteFile(input, []byte("func main():int {\n    return 0\n}\n"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	err := Compile(Config{InputPath: input, Backend: "vm", EmitC: true})
+	if err == nil || !strings.Contains(err.Error(), "requires the c backend") {
+		t.Fatalf("expected c backend error, got %v", err)
+	}
+}
+
+func TestCompileEmitsBinaryMIR(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "main.omni")
+	if err := os.WriteFile(input, []byte("func main():int {\n    return 40 + 2\n}\n"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	if err := Compile(Config{InputPath: input, Backend: "vm", Emit: "mir-binary"}); err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "main.mirb"))
+	if err != nil {
+		t.Fatalf("open output: %v", err)
+	}
+	defer f.Close()
+	mod, err := mir.DecodeBinary(f)
+	if err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if !strings.Contains(printer.Format(mod), "func main():int") {
+		t.Errorf("expected main in the decoded MIR, got:\n%s", printer.Format(mod))
+	}
+}
+
+func TestCompileVerifyOnlyWritesNothing(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "main.omni")
+	if err := os.WriteFile(input, []byte("func main():int {\n    if true {\n        return 1\n    }\n    return 2\n}\n"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	for _, backend := range []string{"c", "vm", "clift"} {
+		if err := Compile(Config{InputPath: input, Backend: backend, Emit: "verify", VerifyStrict: true}); err != nil {
+			t.Fatalf("%s: verify failed: %v", backend, err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the input in %s, got %d entries", dir, len(entries))
+	}
+}
+
+func TestVerifyMIRReportsEveryProblem(t *testing.T) {
+	mod := &mir.Module{Functions: []*mir.Function{
+		{Name: "a", Blocks: []*mir.BasicBlock{{Name: "entry"}}},
+		{Name: "b", Blocks: []*mir.BasicBlock{
+			{Name: "entry", Terminator: mir.Terminator{Op: "ret"}},
+			{Name: "dead", Terminator: mir.Terminator{Op: "ret"}},
+		}},
+	}}
+	err := verifyMIR(Config{}, mod)
+	if err == nil || !strings.Contains(err.Error(), "1 problem(s)") {
+		t.Fatalf("expected the missing terminator, got %v", err)
+	}
+
+	mod.Functions[0].Blocks[0].Terminator = mir.Terminator{Op: "ret"}
+	if err := verifyMIR(Config{}, mod); err != nil {
+		t.Fatalf("expected the module to verify, got %v", err)
+	}
+	err = verifyMIR(Config{VerifyStrict: true}, mod)
+	if err == nil || !strings.Contains(err.Error(), "block dead in function b: unreachable from entry block entry") {
+		t.Errorf("expected strict verification to report the unreachable block, got %v", err)
+	}
+}
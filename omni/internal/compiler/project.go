@@ -0,0 +1,94 @@
+package compiler
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectConfigFile is the name of the per-project settings file that omnic
+// looks for next to the input file or in any parent directory.
+const ProjectConfigFile = "omni.toml"
+
+// ProjectConfig holds the project-level settings read from omni.toml.
+type ProjectConfig struct {
+	// Path is the file the settings were read from; empty if none was found.
+	Path string
+	// Strict is equivalent to passing --strict to omnic.
+	Strict bool
+}
+
+// FindProjectConfig loads the nearest omni.toml in dir or its parents. A
+// missing file is not an error and yields the zero ProjectConfig.
+func FindProjectConfig(dir string) (ProjectConfig, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ProjectConfig{}, err
+	}
+	for current := abs; ; current = filepath.Dir(current) {
+		path := filepath.Join(current, ProjectConfigFile)
+		if _, err := os.Stat(path); err == nil {
+			return LoadProjectConfig(path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return ProjectConfig{}, err
+		}
+		if filepath.Dir(current) == current {
+			return ProjectConfig{}, nil
+		}
+	}
+}
+
+// LoadProjectConfig parses the omni.toml at path. Only top-level `key = value`
+// settings are interpreted; tables and unknown keys are ignored so that the
+// file can carry settings for other tools.
+func LoadProjectConfig(path string) (ProjectConfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ProjectConfig{}, fmt.Errorf("open project config: %w", err)
+	}
+	defer file.Close()
+
+	cfg := ProjectConfig{Path: path}
+	inTable := false
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			inTable = true
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return ProjectConfig{}, fmt.Errorf("%s:%d: expected key = value", path, lineNo)
+		}
+		if inTable {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch key {
+		case "strict":
+			switch value {
+			case "true":
+				cfg.Strict = true
+			case "false":
+				cfg.Strict = false
+			default:
+				return ProjectConfig{}, fmt.Errorf("%s:%d: strict must be true or false, got %s", path, lineNo, value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ProjectConfig{}, fmt.Errorf("read project config: %w", err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,68 @@
+package compiler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/types/checker"
+)
+
+func TestCompileStrict(t *testing.T) {
+	source := "func main():int {\n    var x:int = 1\n    return x - 1\n    x = 2\n}\n"
+	input := filepath.Join(t.TempDir(), "dead.omni")
+	if err := os.WriteFile(input, []byte(source), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	cfg := Config{InputPath: input, Backend: "vm", Emit: "mir"}
+	if err := Compile(cfg); err != nil {
+		t.Fatalf("compile without strict checks failed: %v", err)
+	}
+
+	cfg.Checks = checker.StrictOptions()
+	err := Compile(cfg)
+	if err == nil {
+		t.Fatal("expected strict compile to fail on unreachable code")
+	}
+	if !strings.Contains(err.Error(), "unreachable code") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "src", "app")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	cfg, err := FindProjectConfig(nested)
+	if err != nil {
+		t.Fatalf("missing config should not be an error: %v", err)
+	}
+	if cfg.Path != "" && strings.HasPrefix(cfg.Path, root) {
+		t.Fatalf("unexpected config found: %+v", cfg)
+	}
+
+	path := filepath.Join(root, ProjectConfigFile)
+	contents := "# project settings\nstrict = true # enforce lints\n\n[package]\nname = \"demo\"\n"
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err = FindProjectConfig(nested)
+	if err != nil {
+		t.Fatalf("FindProjectConfig: %v", err)
+	}
+	if cfg.Path != path || !cfg.Strict {
+		t.Errorf("got %+v, want strict config from %s", cfg, path)
+	}
+
+	if err := os.WriteFile(path, []byte("strict = yes\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := FindProjectConfig(nested); err == nil || !strings.Contains(err.Error(), "strict must be true or false") {
+		t.Errorf("expected invalid strict value error, got %v", err)
+	}
+}
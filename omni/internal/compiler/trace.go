@@ -0,0 +1,78 @@
+package compiler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// traceEvent is a single Chrome Trace Event Format "complete" event.
+type traceEvent struct {
+	Name string            `json:"name"`
+	Cat  string            `json:"cat"`
+	Ph   string            `json:"ph"`
+	Ts   int64             `json:"ts"`
+	Dur  int64             `json:"dur"`
+	Pid  int               `json:"pid"`
+	Tid  int               `json:"tid"`
+	Args map[string]string `json:"args,omitempty"`
+}
+
+// eventRecorder collects timing events for the phases of a single compilation
+// so they can be inspected with chrome://tracing. A nil recorder is valid and
+// records nothing, which keeps call sites free of profiling checks.
+type eventRecorder struct {
+	input  string
+	origin time.Time
+	events []traceEvent
+}
+
+func newEventRecorder(input string) *eventRecorder {
+	return &eventRecorder{input: input, origin: time.Now()}
+}
+
+// begin starts timing the named phase and returns a function that ends it.
+func (r *eventRecorder) begin(name string) func() {
+	if r == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		r.events = append(r.events, traceEvent{
+			Name: name,
+			Cat:  "compile",
+			Ph:   "X",
+			Ts:   start.Sub(r.origin).Microseconds(),
+			Dur:  time.Since(start).Microseconds(),
+			Pid:  1,
+			Tid:  1,
+			Args: map[string]string{"file": r.input},
+		})
+	}
+}
+
+// flush writes the recorded events to path as a Chrome trace JSON document.
+func (r *eventRecorder) flush(path string) error {
+	if r == nil {
+		return nil
+	}
+	events := r.events
+	if events == nil {
+		events = []traceEvent{}
+	}
+	data, err := json.MarshalIndent(map[string]any{
+		"traceEvents":     events,
+		"displayTimeUnit": "ms",
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode build profile: %w", err)
+	}
+	if err := ensureDir(path); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write build profile: %w", err)
+	}
+	return nil
+}
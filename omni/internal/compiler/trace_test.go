@@ -0,0 +1,65 @@
+package compiler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompileProfileBuild(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "main.omni")
+	if err := os.WriteFile(input, []byte("func main():int {\n    return 1 + 2\n}\n"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	profile := filepath.Join(dir, "trace.json")
+
+	err := Compile(Config{
+		InputPath:    input,
+		OutputPath:   filepath.Join(dir, "main.mir"),
+		Backend:      "vm",
+		ProfileBuild: profile,
+	})
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	data, err := os.ReadFile(profile)
+	if err != nil {
+		t.Fatalf("read profile: %v", err)
+	}
+	var trace struct {
+		TraceEvents []traceEvent `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(data, &trace); err != nil {
+		t.Fatalf("profile is not valid JSON: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, ev := range trace.TraceEvents {
+		if ev.Ph != "X" {
+			t.Errorf("event %s: expected complete event, got ph=%q", ev.Name, ev.Ph)
+		}
+		if ev.Dur < 0 || ev.Ts < 0 {
+			t.Errorf("event %s: invalid timing ts=%d dur=%d", ev.Name, ev.Ts, ev.Dur)
+		}
+		if ev.Args["file"] != input {
+			t.Errorf("event %s: expected file %q, got %q", ev.Name, input, ev.Args["file"])
+		}
+		seen[ev.Name] = true
+	}
+	for _, phase := range []string{"lex", "parse", "typecheck", "mir-build", "pass:verify", "codegen"} {
+		if !seen[phase] {
+			t.Errorf("missing %q phase in build profile", phase)
+		}
+	}
+}
+
+func TestEventRecorderNil(t *testing.T) {
+	var rec *eventRecorder
+	rec.begin("noop")()
+	if err := rec.flush(filepath.Join(t.TempDir(), "unused.json")); err != nil {
+		t.Errorf("nil recorder flush returned error: %v", err)
+	}
+}
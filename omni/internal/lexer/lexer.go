@@ -15,34 +15,38 @@ const eofRune = -1
 // Keywords are case-sensitive and must be lowercase. Uppercase versions
 // will be lexed as identifiers and should be rejected by the parser.
 var keywords = map[string]Kind{
-	"let":      TokenLet,
-	"var":      TokenVar,
-	"func":     TokenFunc,
-	"return":   TokenReturn,
-	"struct":   TokenStruct,
-	"enum":     TokenEnum,
-	"import":   TokenImport,
-	"as":       TokenAs,
-	"if":       TokenIf,
-	"else":     TokenElse,
-	"for":      TokenFor,
-	"in":       TokenIn,
-	"while":    TokenWhile,
-	"break":    TokenBreak,
-	"continue": TokenContinue,
-	"true":     TokenTrue,
-	"false":    TokenFalse,
-	"null":     TokenNullLiteral,
-	"new":      TokenNew,
-	"delete":   TokenDelete,
-	"try":      TokenTry,
-	"catch":    TokenCatch,
-	"finally":  TokenFinally,
-	"throw":    TokenThrow,
-	"type":     TokenType,
-	"optional": TokenOptional,
-	"async":    TokenAsync,
-	"await":    TokenAwait,
+	"let":       TokenLet,
+	"var":       TokenVar,
+	"func":      TokenFunc,
+	"return":    TokenReturn,
+	"struct":    TokenStruct,
+	"enum":      TokenEnum,
+	"import":    TokenImport,
+	"as":        TokenAs,
+	"if":        TokenIf,
+	"else":      TokenElse,
+	"for":       TokenFor,
+	"in":        TokenIn,
+	"while":     TokenWhile,
+	"break":     TokenBreak,
+	"continue":  TokenContinue,
+	"true":      TokenTrue,
+	"false":     TokenFalse,
+	"null":      TokenNullLiteral,
+	"new":       TokenNew,
+	"delete":    TokenDelete,
+	"try":       TokenTry,
+	"catch":     TokenCatch,
+	"finally":   TokenFinally,
+	"throw":     TokenThrow,
+	"defer":     TokenDefer,
+	"type":      TokenType,
+	"optional":  TokenOptional,
+	"async":     TokenAsync,
+	"await":     TokenAwait,
+	"interface": TokenInterface,
+	"match":     TokenMatch,
+	"case":      TokenCase,
 }
 
 // Lexer transforms a source buffer into a stream of tokens while tracking
@@ -309,6 +313,10 @@ func (l *Lexer) scanNumber() (Token, error) {
 		}
 		// Strip underscores from hex literals so they can be parsed
 		normalizedLexeme := strings.ReplaceAll(lexeme, "_", "")
+		if l.peek() == 'u' && !isIdentifierPart(l.peekRuneAhead(1)) {
+			l.advance()
+			return l.emitTokenWithLexeme(TokenUintLiteral, startPos, startOffset, normalizedLexeme), nil
+		}
 		return l.emitTokenWithLexeme(TokenHexLiteral, startPos, startOffset, normalizedLexeme), nil
 	}
 
@@ -350,6 +358,10 @@ func (l *Lexer) scanNumber() (Token, error) {
 		}
 		// Strip underscores from binary literals so they can be parsed
 		normalizedLexeme := strings.ReplaceAll(lexeme, "_", "")
+		if l.peek() == 'u' && !isIdentifierPart(l.peekRuneAhead(1)) {
+			l.advance()
+			return l.emitTokenWithLexeme(TokenUintLiteral, startPos, startOffset, normalizedLexeme), nil
+		}
 		return l.emitTokenWithLexeme(TokenBinaryLiteral, startPos, startOffset, normalizedLexeme), nil
 	}
 
@@ -424,6 +436,18 @@ done:
 	if hasDot || hasExponent {
 		return l.emitTokenWithLexeme(TokenFloatLiteral, startPos, startOffset, normalizedLexeme), nil
 	}
+	// An i64 suffix makes the literal an int64; the lexeme keeps only the digits
+	if l.peek() == 'i' && l.peekRuneAhead(1) == '6' && l.peekRuneAhead(2) == '4' && !isIdentifierPart(l.peekRuneAhead(3)) {
+		l.advance()
+		l.advance()
+		l.advance()
+		return l.emitTokenWithLexeme(TokenInt64Literal, startPos, startOffset, normalizedLexeme), nil
+	}
+	// Likewise a u suffix makes it a uint
+	if l.peek() == 'u' && !isIdentifierPart(l.peekRuneAhead(1)) {
+		l.advance()
+		return l.emitTokenWithLexeme(TokenUintLiteral, startPos, startOffset, normalizedLexeme), nil
+	}
 	return l.emitTokenWithLexeme(TokenIntLiteral, startPos, startOffset, normalizedLexeme), nil
 }
 
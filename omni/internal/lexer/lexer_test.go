@@ -147,6 +147,61 @@ func TestEvilLexerCases(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:         "int64_suffix",
+			input:        "3_000_000_000i64 i64x",
+			expectError:  false,
+			expectTokens: true,
+			validateTokens: func(t *testing.T, tokens []lexer.Token) {
+				if len(tokens) < 3 {
+					t.Fatalf("expected at least 3 tokens, got %d", len(tokens))
+				}
+				if tokens[0].Kind != lexer.TokenInt64Literal || tokens[0].Lexeme != "3000000000" {
+					t.Errorf("expected INT64 3000000000, got %v %q", tokens[0].Kind, tokens[0].Lexeme)
+				}
+				if tokens[1].Kind != lexer.TokenIdentifier {
+					t.Errorf("expected identifier after the literal, got %v", tokens[1].Kind)
+				}
+			},
+		},
+		{
+			name:         "int64_suffix_needs_word_boundary",
+			input:        "5i64abc",
+			expectError:  false,
+			expectTokens: true,
+			validateTokens: func(t *testing.T, tokens []lexer.Token) {
+				if tokens[0].Kind != lexer.TokenIntLiteral || tokens[1].Lexeme != "i64abc" {
+					t.Errorf("expected INT then identifier i64abc, got %v then %q", tokens[0].Kind, tokens[1].Lexeme)
+				}
+			},
+		},
+		{
+			name:         "uint_suffix",
+			input:        "4_294_967_295u 0xFFu 0b1010u",
+			expectError:  false,
+			expectTokens: true,
+			validateTokens: func(t *testing.T, tokens []lexer.Token) {
+				if len(tokens) < 4 {
+					t.Fatalf("expected at least 4 tokens, got %d", len(tokens))
+				}
+				for i, want := range []string{"4294967295", "0xFF", "0b1010"} {
+					if tokens[i].Kind != lexer.TokenUintLiteral || tokens[i].Lexeme != want {
+						t.Errorf("token %d: expected UINT %s, got %v %q", i, want, tokens[i].Kind, tokens[i].Lexeme)
+					}
+				}
+			},
+		},
+		{
+			name:         "uint_suffix_needs_word_boundary",
+			input:        "7units",
+			expectError:  false,
+			expectTokens: true,
+			validateTokens: func(t *testing.T, tokens []lexer.Token) {
+				if tokens[0].Kind != lexer.TokenIntLiteral || tokens[1].Lexeme != "units" {
+					t.Errorf("expected INT then identifier units, got %v then %q", tokens[0].Kind, tokens[1].Lexeme)
+				}
+			},
+		},
 		// 3. Operator ambiguities
 		{
 			name:         "double_dot",
@@ -19,6 +19,8 @@ const (
 	TokenNullLiteral
 	TokenHexLiteral
 	TokenBinaryLiteral
+	TokenInt64Literal
+	TokenUintLiteral
 
 	// Keywords
 	TokenLet
@@ -44,10 +46,14 @@ const (
 	TokenCatch
 	TokenFinally
 	TokenThrow
+	TokenDefer
 	TokenType
 	TokenOptional
 	TokenAsync
 	TokenAwait
+	TokenInterface
+	TokenMatch
+	TokenCase
 
 	// Delimiters
 	TokenLParen
@@ -102,6 +108,8 @@ var kindNames = map[Kind]string{
 	TokenNullLiteral:         "NULL",
 	TokenHexLiteral:          "HEX",
 	TokenBinaryLiteral:       "BINARY",
+	TokenInt64Literal:        "INT64",
+	TokenUintLiteral:         "UINT",
 	TokenLet:                 "LET",
 	TokenVar:                 "VAR",
 	TokenFunc:                "FUNC",
@@ -125,10 +133,14 @@ var kindNames = map[Kind]string{
 	TokenCatch:               "CATCH",
 	TokenFinally:             "FINALLY",
 	TokenThrow:               "THROW",
+	TokenDefer:               "DEFER",
 	TokenType:                "TYPE",
 	TokenOptional:            "OPTIONAL",
 	TokenAsync:               "ASYNC",
 	TokenAwait:               "AWAIT",
+	TokenInterface:           "INTERFACE",
+	TokenMatch:               "MATCH",
+	TokenCase:                "CASE",
 	TokenLParen:              "LPAREN",
 	TokenRParen:              "RPAREN",
 	TokenLBrace:              "LBRACE",
@@ -2,7 +2,9 @@
 package logging
 
 import (
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -21,9 +23,7 @@ func Logger() *slogger.Logger {
 		if _, ok := os.LookupEnv("LOG_OUTPUT"); !ok {
 			cfg.Output = "stderr"
 		}
-		if _, ok := os.LookupEnv("LOG_COLORIZE"); !ok {
-			cfg.Colorize = true
-		}
+		cfg.Colorize = IsColorEnabled()
 		cfg.EnableCaller = false
 		cfg.SyncWrites = true
 		global = slogger.ApplyConfig(cfg)
@@ -31,29 +31,68 @@ func Logger() *slogger.Logger {
 	return global
 }
 
+// IsColorEnabled reports whether log output should carry ANSI colors.
+// NO_COLOR (https://no-color.org) set to any non-empty value disables them.
+// Otherwise LOG_COLORIZE decides when it holds a boolean, and without it
+// colors are used only when the log goes to a terminal.
+func IsColorEnabled() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if colorize, err := strconv.ParseBool(os.Getenv("LOG_COLORIZE")); err == nil {
+		return colorize
+	}
+	switch os.Getenv("LOG_OUTPUT") {
+	case "", "stderr":
+		return isTerminal(os.Stderr)
+	case "stdout":
+		return isTerminal(os.Stdout)
+	}
+	return false
+}
+
+// isTerminal reports whether f is a character device such as a terminal.
+// Tests replace it to simulate one.
+var isTerminal = func(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 // SetLevel overrides the active log level for the shared logger.
-func SetLevel(level slogger.LogLevel) {
+func SetLevel(level Level) {
 	Logger().SetLevel(level)
 }
 
 // SetLevelByName adjusts the log level using a string such as "debug", "info", etc.
 // Returns true when the level name is recognised.
 func SetLevelByName(name string) bool {
-	switch strings.ToUpper(name) {
+	level, err := LevelFromString(name)
+	if err != nil {
+		return false
+	}
+	SetLevel(level)
+	return true
+}
+
+// LevelFromString parses a level name such as "debug", "info", "warn" or
+// "error", ignoring case.
+func LevelFromString(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
 	case "DEBUG":
-		SetLevel(LevelDebug)
+		return LevelDebug, nil
 	case "INFO":
-		SetLevel(LevelInfo)
+		return LevelInfo, nil
 	case "WARN", "WARNING":
-		SetLevel(LevelWarn)
+		return LevelWarn, nil
 	case "ERROR", "ERR":
-		SetLevel(LevelError)
-	default:
-		return false
+		return LevelError, nil
 	}
-	return true
+	return LevelInfo, fmt.Errorf("unknown log level %q (want debug, info, warn or error)", s)
 }
 
+// Level is the severity threshold of the logger.
+type Level = slogger.LogLevel
+
 // Level aliases simplify call sites without importing simple-logger directly.
 const (
 	LevelDebug = slogger.DEBUG
@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// freshLogger drops the shared logger so the next Logger call reads the
+// environment again, and pretends stderr is a terminal.
+func freshLogger(t *testing.T) {
+	t.Helper()
+	restore := isTerminal
+	isTerminal = func(*os.File) bool { return true }
+	initOnce, global = sync.Once{}, nil
+	t.Cleanup(func() {
+		isTerminal = restore
+		initOnce, global = sync.Once{}, nil
+	})
+}
+
+func logLine(t *testing.T) string {
+	t.Helper()
+	var buf bytes.Buffer
+	Logger().SetOutput(&buf)
+	Logger().ErrorString("something failed")
+	return buf.String()
+}
+
+func TestNoColorDisablesANSI(t *testing.T) {
+	freshLogger(t)
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LOG_COLORIZE", "true")
+
+	if IsColorEnabled() {
+		t.Fatal("IsColorEnabled() = true with NO_COLOR set")
+	}
+	if out := logLine(t); strings.Contains(out, "\x1b[") {
+		t.Fatalf("log output contains ANSI escapes with NO_COLOR set: %q", out)
+	}
+}
+
+func TestColorOnTerminal(t *testing.T) {
+	freshLogger(t)
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("LOG_COLORIZE", "")
+	t.Setenv("LOG_OUTPUT", "")
+
+	if !IsColorEnabled() {
+		t.Fatal("IsColorEnabled() = false on a terminal")
+	}
+	if out := logLine(t); !strings.Contains(out, "\x1b[") {
+		t.Fatalf("log output has no ANSI escapes on a terminal: %q", out)
+	}
+}
+
+func TestIsColorEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		noColor  string
+		colorize string
+		output   string
+		terminal bool
+		want     bool
+	}{
+		{name: "terminal", terminal: true, want: true},
+		{name: "not a terminal", want: false},
+		{name: "NO_COLOR", noColor: "1", terminal: true, want: false},
+		{name: "LOG_COLORIZE false", colorize: "false", terminal: true, want: false},
+		{name: "LOG_COLORIZE true off terminal", colorize: "true", want: true},
+		{name: "file output", output: "omni.log", terminal: true, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restore := isTerminal
+			isTerminal = func(*os.File) bool { return tt.terminal }
+			defer func() { isTerminal = restore }()
+			t.Setenv("NO_COLOR", tt.noColor)
+			t.Setenv("LOG_COLORIZE", tt.colorize)
+			t.Setenv("LOG_OUTPUT", tt.output)
+
+			if got := IsColorEnabled(); got != tt.want {
+				t.Errorf("IsColorEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelFromString(t *testing.T) {
+	tests := []struct {
+		name string
+		want Level
+	}{
+		{"debug", LevelDebug},
+		{"INFO", LevelInfo},
+		{"warn", LevelWarn},
+		{"warning", LevelWarn},
+		{" error ", LevelError},
+	}
+	for _, tt := range tests {
+		got, err := LevelFromString(tt.name)
+		if err != nil || got != tt.want {
+			t.Errorf("LevelFromString(%q) = %v, %v, want %v", tt.name, got, err, tt.want)
+		}
+	}
+	if _, err := LevelFromString("loud"); err == nil {
+		t.Error("LevelFromString(\"loud\") succeeded")
+	}
+}
@@ -0,0 +1,355 @@
+package mir
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/omni-lang/omni/internal/lexer"
+)
+
+// The binary MIR format is a compact alternative to the text of the printer
+// for tools that read MIR back. A file starts with binaryMagic followed by
+// the format version in one byte. Counts and lengths are unsigned varints,
+// value IDs and source positions signed varints, and strings are a length
+// followed by their bytes. After the header come the functions, then the
+// interfaces, the enums and the globals of the module, each list preceded
+// by its length. Instructions and terminators end with their source
+// location.
+const (
+	binaryMagic = "MIR"
+	// BinaryVersion is the version of the binary MIR format that
+	// EncodeBinary writes and DecodeBinary reads.
+	BinaryVersion = 3
+)
+
+// maxBinaryLength bounds the counts and string lengths DecodeBinary accepts,
+// so that a corrupt file fails instead of allocating without limit.
+const maxBinaryLength = 1 << 28
+
+// EncodeBinary writes mod to w in the binary MIR format.
+func EncodeBinary(mod *Module, w io.Writer) error {
+	e := &binaryEncoder{w: bufio.NewWriter(w)}
+	e.w.WriteString(binaryMagic)
+	e.w.WriteByte(BinaryVersion)
+
+	e.uint(len(mod.Functions))
+	for _, fn := range mod.Functions {
+		e.function(fn)
+	}
+	e.uint(len(mod.Interfaces))
+	for _, iface := range mod.Interfaces {
+		e.string(iface.Name)
+		e.uint(len(iface.Methods))
+		for _, method := range iface.Methods {
+			e.string(method.Name)
+			e.strings(method.Params)
+			e.string(method.Return)
+		}
+	}
+	e.uint(len(mod.Enums))
+	for _, enum := range mod.Enums {
+		e.string(enum.Name)
+		e.strings(enum.Variants)
+	}
+	e.uint(len(mod.Globals))
+	for _, global := range mod.Globals {
+		e.string(global.Name)
+		e.string(global.Type)
+		e.operands([]Operand{global.InitValue})
+	}
+	if e.err != nil {
+		return fmt.Errorf("encode binary MIR: %w", e.err)
+	}
+	if err := e.w.Flush(); err != nil {
+		return fmt.Errorf("encode binary MIR: %w", err)
+	}
+	return nil
+}
+
+// DecodeBinary reads a module in the binary MIR format from r. It rejects
+// input without the magic number and versions other than BinaryVersion.
+func DecodeBinary(r io.Reader) (*Module, error) {
+	d := &binaryDecoder{r: bufio.NewReader(r)}
+	header := make([]byte, len(binaryMagic)+1)
+	if _, err := io.ReadFull(d.r, header); err != nil {
+		return nil, fmt.Errorf("decode binary MIR: read header: %w", err)
+	}
+	if string(header[:len(binaryMagic)]) != binaryMagic {
+		return nil, errors.New("decode binary MIR: not a binary MIR file")
+	}
+	if version := header[len(binaryMagic)]; version != BinaryVersion {
+		return nil, fmt.Errorf("decode binary MIR: unsupported version %d (expected %d)", version, BinaryVersion)
+	}
+
+	mod := &Module{}
+	mod.Functions = make([]*Function, d.uint())
+	for i := range mod.Functions {
+		mod.Functions[i] = d.function()
+	}
+	mod.Interfaces = make([]*Interface, d.uint())
+	for i := range mod.Interfaces {
+		iface := &Interface{Name: d.string()}
+		iface.Methods = make([]InterfaceMethod, d.uint())
+		for j := range iface.Methods {
+			iface.Methods[j] = InterfaceMethod{Name: d.string(), Params: d.strings(), Return: d.string()}
+		}
+		mod.Interfaces[i] = iface
+	}
+	mod.Enums = make([]*Enum, d.uint())
+	for i := range mod.Enums {
+		mod.Enums[i] = &Enum{Name: d.string(), Variants: d.strings()}
+	}
+	if n := d.uint(); n > 0 {
+		mod.Globals = make([]GlobalVar, n)
+		for i := range mod.Globals {
+			mod.Globals[i] = GlobalVar{Name: d.string(), Type: d.string()}
+			if init := d.operands(); len(init) == 1 {
+				mod.Globals[i].InitValue = init[0]
+			} else if d.err == nil {
+				d.fail(fmt.Errorf("global %s has %d initial values", mod.Globals[i].Name, len(init)))
+			}
+		}
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("decode binary MIR: %w", d.err)
+	}
+	return mod, nil
+}
+
+// binaryEncoder writes the parts of the format, keeping the first error so
+// that callers check once at the end.
+type binaryEncoder struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (e *binaryEncoder) uint(n int) {
+	if e.err == nil {
+		_, e.err = e.w.Write(e.buf[:binary.PutUvarint(e.buf[:], uint64(n))])
+	}
+}
+
+func (e *binaryEncoder) int(n int) {
+	if e.err == nil {
+		_, e.err = e.w.Write(e.buf[:binary.PutVarint(e.buf[:], int64(n))])
+	}
+}
+
+func (e *binaryEncoder) bool(b bool) {
+	if b {
+		e.uint(1)
+	} else {
+		e.uint(0)
+	}
+}
+
+func (e *binaryEncoder) string(s string) {
+	e.uint(len(s))
+	if e.err == nil {
+		_, e.err = e.w.WriteString(s)
+	}
+}
+
+func (e *binaryEncoder) strings(list []string) {
+	e.uint(len(list))
+	for _, s := range list {
+		e.string(s)
+	}
+}
+
+func (e *binaryEncoder) span(span lexer.Span) {
+	e.int(span.Start.Line)
+	e.int(span.Start.Column)
+	e.int(span.End.Line)
+	e.int(span.End.Column)
+}
+
+func (e *binaryEncoder) location(loc SourceLocation) {
+	e.string(loc.File)
+	e.int(loc.Line)
+	e.int(loc.Column)
+}
+
+func (e *binaryEncoder) function(fn *Function) {
+	e.string(fn.Name)
+	e.string(fn.ReturnType)
+	e.uint(len(fn.Params))
+	for _, param := range fn.Params {
+		e.string(param.Name)
+		e.string(param.Type)
+		e.int(int(param.ID))
+		e.bool(param.Variadic)
+	}
+	e.span(fn.Span)
+	e.uint(len(fn.Blocks))
+	for _, block := range fn.Blocks {
+		e.string(block.Name)
+		e.uint(len(block.Instructions))
+		for _, inst := range block.Instructions {
+			e.int(int(inst.ID))
+			e.string(inst.Op)
+			e.string(inst.Type)
+			e.operands(inst.Operands)
+			e.location(inst.Location)
+		}
+		e.string(block.Terminator.Op)
+		e.operands(block.Terminator.Operands)
+		e.location(block.Terminator.Location)
+	}
+}
+
+func (e *binaryEncoder) operands(ops []Operand) {
+	e.uint(len(ops))
+	for _, op := range ops {
+		e.uint(int(op.Kind))
+		if op.Kind == OperandValue {
+			e.int(int(op.Value))
+		} else {
+			e.string(op.Literal)
+		}
+		e.string(op.Type)
+	}
+}
+
+// binaryDecoder reads the parts of the format. After the first error every
+// read returns a zero value, so the result is only used when err is nil.
+type binaryDecoder struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (d *binaryDecoder) fail(err error) {
+	if d.err == nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		d.err = err
+	}
+}
+
+func (d *binaryDecoder) uint() int {
+	if d.err != nil {
+		return 0
+	}
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		d.fail(err)
+		return 0
+	}
+	if n > maxBinaryLength {
+		d.fail(fmt.Errorf("length %d out of range", n))
+		return 0
+	}
+	return int(n)
+}
+
+func (d *binaryDecoder) int() int {
+	if d.err != nil {
+		return 0
+	}
+	n, err := binary.ReadVarint(d.r)
+	if err != nil {
+		d.fail(err)
+		return 0
+	}
+	return int(n)
+}
+
+func (d *binaryDecoder) bool() bool {
+	return d.uint() != 0
+}
+
+func (d *binaryDecoder) string() string {
+	n := d.uint()
+	if d.err != nil || n == 0 {
+		return ""
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		d.fail(err)
+		return ""
+	}
+	return string(buf)
+}
+
+func (d *binaryDecoder) strings() []string {
+	n := d.uint()
+	if n == 0 {
+		return nil
+	}
+	list := make([]string, n)
+	for i := range list {
+		list[i] = d.string()
+	}
+	return list
+}
+
+func (d *binaryDecoder) span() lexer.Span {
+	var span lexer.Span
+	span.Start.Line = d.int()
+	span.Start.Column = d.int()
+	span.End.Line = d.int()
+	span.End.Column = d.int()
+	return span
+}
+
+func (d *binaryDecoder) location() SourceLocation {
+	return SourceLocation{File: d.string(), Line: d.int(), Column: d.int()}
+}
+
+func (d *binaryDecoder) function() *Function {
+	fn := &Function{Name: d.string(), ReturnType: d.string()}
+	fn.Params = make([]Param, d.uint())
+	for i := range fn.Params {
+		fn.Params[i] = Param{Name: d.string(), Type: d.string(), ID: ValueID(d.int()), Variadic: d.bool()}
+		fn.noteValue(fn.Params[i].ID)
+	}
+	fn.Span = d.span()
+	fn.Blocks = make([]*BasicBlock, d.uint())
+	for i := range fn.Blocks {
+		block := &BasicBlock{Name: d.string()}
+		block.Instructions = make([]Instruction, d.uint())
+		for j := range block.Instructions {
+			inst := Instruction{ID: ValueID(d.int()), Op: d.string(), Type: d.string(), Operands: d.operands(), Location: d.location()}
+			fn.noteValue(inst.ID)
+			block.Instructions[j] = inst
+		}
+		block.Terminator = Terminator{Op: d.string(), Operands: d.operands(), Location: d.location()}
+		fn.Blocks[i] = block
+	}
+	return fn
+}
+
+func (d *binaryDecoder) operands() []Operand {
+	n := d.uint()
+	if n == 0 {
+		return nil
+	}
+	ops := make([]Operand, n)
+	for i := range ops {
+		op := Operand{Kind: OperandKind(d.uint())}
+		switch op.Kind {
+		case OperandValue:
+			op.Value = ValueID(d.int())
+		case OperandLiteral:
+			op.Literal = d.string()
+		default:
+			d.fail(fmt.Errorf("unknown operand kind %d", op.Kind))
+		}
+		op.Type = d.string()
+		ops[i] = op
+	}
+	return ops
+}
+
+// noteValue keeps the next value of fn past id, so that values added to a
+// decoded function do not collide with the ones it has.
+func (f *Function) noteValue(id ValueID) {
+	if id >= f.nextValue {
+		f.nextValue = id + 1
+	}
+}
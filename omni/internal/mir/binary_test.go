@@ -0,0 +1,154 @@
+package mir_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/mir/builder"
+	mirprinter "github.com/omni-lang/omni/internal/mir/printer"
+	"github.com/omni-lang/omni/internal/parser"
+	"github.com/omni-lang/omni/internal/passes"
+	"github.com/omni-lang/omni/internal/types/checker"
+)
+
+// TestBinaryRoundTripGoldens encodes the module of every MIR golden, decodes
+// it and encodes it again: both encodings match and the decoded module
+// prints as the golden does.
+func TestBinaryRoundTripGoldens(t *testing.T) {
+	goldenDir := filepath.Join("..", "..", "tests", "goldens", "mir")
+	inputs, err := filepath.Glob(filepath.Join(goldenDir, "*.omni"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	sort.Strings(inputs)
+	if len(inputs) == 0 {
+		t.Fatalf("no golden inputs found in %s", goldenDir)
+	}
+
+	for _, inputPath := range inputs {
+		base := strings.TrimSuffix(filepath.Base(inputPath), ".omni")
+		t.Run(base, func(t *testing.T) {
+			src, err := os.ReadFile(inputPath)
+			if err != nil {
+				t.Fatalf("read input: %v", err)
+			}
+			astMod, err := parser.Parse(inputPath, string(src))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if err := checker.Check(inputPath, string(src), astMod); err != nil {
+				t.Fatalf("type check: %v", err)
+			}
+			mod, err := builder.BuildModule(astMod)
+			if err != nil {
+				t.Fatalf("build MIR: %v", err)
+			}
+			if _, err := passes.NewPipeline("mir-golden").Run(*mod); err != nil {
+				t.Fatalf("pipeline: %v", err)
+			}
+
+			var original bytes.Buffer
+			if err := mir.EncodeBinary(mod, &original); err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			decoded, err := mir.DecodeBinary(bytes.NewReader(original.Bytes()))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			var again bytes.Buffer
+			if err := mir.EncodeBinary(decoded, &again); err != nil {
+				t.Fatalf("encode decoded module: %v", err)
+			}
+			if !bytes.Equal(original.Bytes(), again.Bytes()) {
+				t.Errorf("re-encoding changed the binary MIR (%d bytes, was %d)", again.Len(), original.Len())
+			}
+
+			golden, err := os.ReadFile(filepath.Join(goldenDir, base+".mir"))
+			if err != nil {
+				t.Fatalf("read golden: %v", err)
+			}
+			if got := mirprinter.Format(decoded); strings.TrimSpace(got) != strings.TrimSpace(string(golden)) {
+				t.Errorf("decoded MIR differs from the golden:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestBinaryKeepsModuleTypesAndSpans(t *testing.T) {
+	fn := mir.NewFunction("sum", "int", []mir.Param{{Name: "xs", Type: "[]<int>", Variadic: true}})
+	fn.Span.Start.Line = 3
+	fn.Span.End.Column = 12
+	entry := fn.NewBlock("entry")
+	entry.Instructions = []mir.Instruction{{ID: fn.NextValue(), Op: "const", Type: "int", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "-7", Type: "int"}},
+		Location: mir.SourceLocation{File: "sum.omni", Line: 4, Column: 9}}}
+	entry.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: 1, Type: "int"}},
+		Location: mir.SourceLocation{File: "sum.omni", Line: 4, Column: 2}}
+	mod := &mir.Module{
+		Functions:  []*mir.Function{fn},
+		Interfaces: []*mir.Interface{{Name: "Shape", Methods: []mir.InterfaceMethod{{Name: "area", Params: []string{"int"}, Return: "float"}}}},
+		Enums:      []*mir.Enum{{Name: "Color", Variants: []string{"Red", "Green"}}},
+	}
+
+	var buf bytes.Buffer
+	if err := mir.EncodeBinary(mod, &buf); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	decoded, err := mir.DecodeBinary(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	got := decoded.Functions[0]
+	if got.Span != fn.Span || !got.Params[0].Variadic || got.Blocks[0].Instructions[0].Operands[0].Literal != "-7" {
+		t.Errorf("function not preserved: %+v", got)
+	}
+	if loc := got.Blocks[0].Instructions[0].Location; loc.String() != "sum.omni:4:9" {
+		t.Errorf("instruction location = %s, want sum.omni:4:9", loc)
+	}
+	if loc := got.Blocks[0].Terminator.Location; loc.String() != "sum.omni:4:2" {
+		t.Errorf("terminator location = %s, want sum.omni:4:2", loc)
+	}
+	if next := got.NextValue(); next != 2 {
+		t.Errorf("NextValue of the decoded function = %v, want %%2", next)
+	}
+	if iface, ok := decoded.Interface("Shape"); !ok || iface.Methods[0].Return != "float" {
+		t.Errorf("interface not preserved: %+v", decoded.Interfaces)
+	}
+	if enum, ok := decoded.Enum("Color"); !ok || len(enum.Variants) != 2 || enum.Variants[1] != "Green" {
+		t.Errorf("enum not preserved: %+v", decoded.Enums)
+	}
+}
+
+func TestDecodeBinaryRejectsInvalidInput(t *testing.T) {
+	var valid bytes.Buffer
+	if err := mir.EncodeBinary(&mir.Module{Functions: []*mir.Function{mir.NewFunction("main", "int", nil)}}, &valid); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	newer := append([]byte{}, valid.Bytes()...)
+	newer[3] = mir.BinaryVersion + 1
+
+	for name, tc := range map[string]struct {
+		data []byte
+		want string
+	}{
+		"text MIR":    {[]byte("func main():int\n"), "not a binary MIR file"},
+		"newer":       {newer, "unsupported version"},
+		"short":       {[]byte("MI"), "read header"},
+		"truncated":   {valid.Bytes()[:valid.Len()-2], io.ErrUnexpectedEOF.Error()},
+		"header only": {valid.Bytes()[:4], io.ErrUnexpectedEOF.Error()},
+	} {
+		_, err := mir.DecodeBinary(bytes.NewReader(tc.data))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected error containing %q, got %v", name, tc.want, err)
+		}
+	}
+	if _, err := mir.DecodeBinary(bytes.NewReader(valid.Bytes()[:valid.Len()-2])); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated input: expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
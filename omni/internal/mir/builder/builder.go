@@ -2,10 +2,13 @@ package builder
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/omni-lang/omni/internal/ast"
+	"github.com/omni-lang/omni/internal/lexer"
 	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/types"
 )
 
 const inferTypePlaceholder = "<infer>"
@@ -16,12 +19,25 @@ func BuildModule(mod *ast.Module) (*mir.Module, error) {
 		module:       &mir.Module{},
 		signatures:   make(map[string]FunctionSignature),
 		structFields: make(map[string]map[string]string),
+		stdAliases:   make(map[string]string),
+		constants:    make(map[string]*ast.LiteralExpr),
+		methods:      make(map[string]map[string]string),
+		interfaces:   make(map[string]*mir.Interface),
+		enums:        make(map[string]*mir.Enum),
+		globals:      make(map[string]symbol),
 	}
 	mb.collectFunctionSignatures(mod)
+	mb.collectInterfaces(mod)
+	mb.collectEnums(mod)
 	mb.collectStructDefinitions(mod)
+	mb.collectStdAliases(mod)
+	mb.collectModuleConstants(mod)
+	if err := mb.collectGlobals(mod); err != nil {
+		return nil, err
+	}
 
 	for _, decl := range mod.Decls {
-		fn, ok := decl.(*ast.FuncDecl)
+		fn, ok := funcOf(decl)
 		if !ok {
 			continue
 		}
@@ -43,6 +59,24 @@ type moduleBuilder struct {
 	signatures   map[string]FunctionSignature
 	lambdas      []*mir.Function              // Collect lambda functions
 	structFields map[string]map[string]string // struct type name -> field name -> field type
+	stdAliases   map[string]string            // local module name -> qualified std path (e.g. diff -> std.io.diff)
+	constants    map[string]*ast.LiteralExpr  // merged module constants (e.g. signal.SIGINT -> 2)
+	methods      map[string]map[string]string // struct type name -> method name -> function name
+	interfaces   map[string]*mir.Interface    // interface type name -> its methods
+	enums        map[string]*mir.Enum         // enum type name -> its variants
+	globals      map[string]symbol            // global variable name -> its type and mutability
+}
+
+// funcOf returns the function that decl declares: a function declaration
+// itself, or the function that implements a method.
+func funcOf(decl ast.Decl) (*ast.FuncDecl, bool) {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d, true
+	case *ast.MethodDecl:
+		return d.Function(), true
+	}
+	return nil, false
 }
 
 type functionBuilder struct {
@@ -53,11 +87,94 @@ type functionBuilder struct {
 	blocks    int
 	mb        *moduleBuilder // Reference to module builder for lambda collection
 	loopStack []loopContext  // Stack of loop contexts for break/continue
+	hasDefers bool           // Whether the function contains a defer statement
+	tryStack  []tryContext   // Stack of the try statements whose handlers are active
+	// namedReturn holds the result variable of a function declared with
+	// `as name`; its name is empty otherwise
+	namedReturn namedReturn
+	// file is the source file of the function and loc the location of the
+	// node being lowered. located counts the instructions of each block
+	// that already carry their location.
+	file    string
+	loc     mir.SourceLocation
+	located map[*mir.BasicBlock]int
+}
+
+// at makes span the location of the instructions lowered from now on. The
+// returned function restores the previous location once the node that
+// starts at span is lowered.
+func (fb *functionBuilder) at(span lexer.Span) func() {
+	fb.locate()
+	prev := fb.loc
+	if span.Start.Line > 0 {
+		fb.loc = mir.SourceLocation{File: fb.file, Line: span.Start.Line, Column: span.Start.Column}
+	}
+	return func() {
+		fb.locate()
+		fb.loc = prev
+	}
+}
+
+// locate gives the current location to the instructions and terminators
+// added since the last call.
+func (fb *functionBuilder) locate() {
+	if fb.located == nil {
+		fb.located = make(map[*mir.BasicBlock]int)
+	}
+	for _, block := range fb.fn.Blocks {
+		for i := fb.located[block]; i < len(block.Instructions); i++ {
+			if block.Instructions[i].Location.IsZero() {
+				block.Instructions[i].Location = fb.loc
+			}
+		}
+		fb.located[block] = len(block.Instructions)
+		if block.Terminator.Op != "" && block.Terminator.Location.IsZero() {
+			block.Terminator.Location = fb.loc
+		}
+	}
+}
+
+// namedReturn is the result variable of a function, the slot its body
+// assigns and a ret.named terminator returns.
+type namedReturn struct {
+	name string
+	slot mirValue
 }
 
 type loopContext struct {
 	continueBlock *mir.BasicBlock
 	breakBlock    *mir.BasicBlock
+	// stepName names the block continue statements branch to in a loop
+	// that ends each iteration with a step, such as the post statement of
+	// a for loop. The block is only created once a continue needs it;
+	// until then continueBlock is nil.
+	stepName string
+	// tryDepth is the number of handlers that were active when the loop
+	// began; break and continue leave the ones pushed inside the loop
+	tryDepth int
+}
+
+// pushLoop enters the loop ctx for break and continue statements.
+func (fb *functionBuilder) pushLoop(ctx loopContext) {
+	ctx.tryDepth = len(fb.tryStack)
+	fb.loopStack = append(fb.loopStack, ctx)
+}
+
+// enterStep moves lowering into the step block of the innermost loop, if a
+// continue statement created one, so that the iterations that reach the end
+// of the body and those that continue run the step there.
+func (fb *functionBuilder) enterStep() {
+	step := fb.loopStack[len(fb.loopStack)-1].continueBlock
+	if step == nil {
+		return
+	}
+	if !fb.block.HasTerminator() {
+		fb.block.Terminator = mir.Terminator{
+			Op:       "br",
+			Operands: []mir.Operand{blockOperand(step)},
+		}
+	}
+	fb.block = step
 }
 
 type symbol struct {
@@ -70,11 +187,23 @@ type symbol struct {
 type FunctionSignature struct {
 	Return string
 	Params []string
+	// Variadic marks a function whose last parameter is variadic; its
+	// entry in Params is the element type of the arguments it takes.
+	Variadic bool
+	// Defaults holds the default value of each parameter, or nil for the
+	// parameters without one.
+	Defaults []ast.Expr
 }
 
 func (mb *moduleBuilder) collectFunctionSignatures(mod *ast.Module) {
 	for _, decl := range mod.Decls {
-		fn, ok := decl.(*ast.FuncDecl)
+		if method, ok := decl.(*ast.MethodDecl); ok {
+			if mb.methods[method.TypeName()] == nil {
+				mb.methods[method.TypeName()] = make(map[string]string)
+			}
+			mb.methods[method.TypeName()][method.Func.Name] = method.FuncName()
+		}
+		fn, ok := funcOf(decl)
 		if !ok {
 			continue
 		}
@@ -91,14 +220,61 @@ func (mb *moduleBuilder) collectFunctionSignatures(mod *ast.Module) {
 			}
 		}
 		sig.Params = make([]string, len(fn.Params))
+		sig.Defaults = make([]ast.Expr, len(fn.Params))
 		for i, param := range fn.Params {
 			sig.Params[i] = typeExprToString(param.Type)
+			sig.Variadic = param.Variadic
+			sig.Defaults[i] = param.Default
 		}
 		mb.signatures[fn.Name] = sig
 	}
 }
 
+// collectInterfaces records the interface types of mod, in the module for
+// the backends to lay out the vtables of their values.
+func (mb *moduleBuilder) collectInterfaces(mod *ast.Module) {
+	for _, decl := range mod.Decls {
+		d, ok := decl.(*ast.InterfaceDecl)
+		if !ok {
+			continue
+		}
+		iface := &mir.Interface{Name: d.Name}
+		for _, m := range d.Methods {
+			method := mir.InterfaceMethod{Name: m.Name, Return: "void"}
+			for _, param := range m.Params {
+				method.Params = append(method.Params, typeExprToString(param.Type))
+			}
+			if m.Return != nil {
+				method.Return = typeExprToString(m.Return)
+			}
+			iface.Methods = append(iface.Methods, method)
+		}
+		mb.interfaces[d.Name] = iface
+		mb.module.Interfaces = append(mb.module.Interfaces, iface)
+	}
+}
+
+// collectEnums records the enum types of mod. A value of an enum is the
+// index of its variant, an int tag.
+func (mb *moduleBuilder) collectEnums(mod *ast.Module) {
+	for _, decl := range mod.Decls {
+		d, ok := decl.(*ast.EnumDecl)
+		if !ok {
+			continue
+		}
+		enum := &mir.Enum{Name: d.Name}
+		for _, v := range d.Variants {
+			enum.Variants = append(enum.Variants, v.Name)
+		}
+		mb.enums[d.Name] = enum
+		mb.module.Enums = append(mb.module.Enums, enum)
+	}
+}
+
 func (mb *moduleBuilder) collectStructDefinitions(mod *ast.Module) {
+	// std.process.run returns a ProcessResult whether or not the module
+	// that declares it was merged
+	mb.structFields["ProcessResult"] = map[string]string{"stdout": "string", "stderr": "string", "exit_code": "int"}
 	for _, decl := range mod.Decls {
 		structDecl, ok := decl.(*ast.StructDecl)
 		if !ok {
@@ -112,10 +288,137 @@ func (mb *moduleBuilder) collectStructDefinitions(mod *ast.Module) {
 	}
 }
 
+// collectStdAliases records the local names bound by nested std imports so
+// calls such as diff.unified resolve to std.io.diff.unified.
+func (mb *moduleBuilder) collectStdAliases(mod *ast.Module) {
+	imports := append([]*ast.ImportDecl{}, mod.Imports...)
+	for _, decl := range mod.Decls {
+		if imp, ok := decl.(*ast.ImportDecl); ok {
+			imports = append(imports, imp)
+		}
+	}
+	for _, imp := range imports {
+		if len(imp.Path) < 3 || imp.Path[0] != "std" {
+			continue
+		}
+		local := imp.Alias
+		if local == "" {
+			local = imp.Path[len(imp.Path)-1]
+		}
+		mb.stdAliases[local] = strings.Join(imp.Path, ".")
+	}
+}
+
+// collectModuleConstants records the literal-valued constants merged from
+// imported modules so member accesses such as signal.SIGINT are inlined.
+func (mb *moduleBuilder) collectModuleConstants(mod *ast.Module) {
+	for _, decl := range mod.Decls {
+		letDecl, ok := decl.(*ast.LetDecl)
+		if !ok || !strings.Contains(letDecl.Name, ".") {
+			continue
+		}
+		if lit, ok := letDecl.Value.(*ast.LiteralExpr); ok {
+			mb.constants[letDecl.Name] = lit
+		}
+	}
+}
+
+// collectGlobals records the top-level let and var declarations of mod as
+// the global variables of the module. A global starts out with the value of
+// its initializer, which must be a literal.
+func (mb *moduleBuilder) collectGlobals(mod *ast.Module) error {
+	for _, decl := range mod.Decls {
+		var name string
+		var typ *ast.TypeExpr
+		var value ast.Expr
+		var mutable bool
+		switch d := decl.(type) {
+		case *ast.LetDecl:
+			name, typ, value = d.Name, d.Type, d.Value
+		case *ast.VarDecl:
+			name, typ, value, mutable = d.Name, d.Type, d.Value, true
+		default:
+			continue
+		}
+		// Constants merged from imported modules are inlined instead
+		if strings.Contains(name, ".") {
+			continue
+		}
+		init, ok := globalInitializer(value)
+		if !ok {
+			return fmt.Errorf("mir builder: global %q must be initialized with a literal", name)
+		}
+		if typ != nil {
+			init.Type = typeExprToString(typ)
+		}
+		if _, ok := zeroLiterals[init.Type]; !ok {
+			return fmt.Errorf("mir builder: global %q has unsupported type %s", name, init.Type)
+		}
+		mb.module.Globals = append(mb.module.Globals, mir.GlobalVar{Name: name, Type: init.Type, InitValue: init})
+		mb.globals[name] = symbol{Value: mir.InvalidValue, Type: init.Type, Mutable: mutable}
+	}
+	return nil
+}
+
+// globalInitializer returns the literal operand of the initializer of a
+// global: a literal, or a negated number.
+func globalInitializer(expr ast.Expr) (mir.Operand, bool) {
+	negate := false
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == "-" {
+		negate, expr = true, unary.Expr
+	}
+	lit, ok := expr.(*ast.LiteralExpr)
+	if !ok {
+		return mir.Operand{}, false
+	}
+	op := mir.Operand{Kind: mir.OperandLiteral, Literal: lit.Value, Type: literalType(lit)}
+	if negate {
+		if op.Type != "int" && op.Type != "int64" && op.Type != "float" {
+			return mir.Operand{}, false
+		}
+		op.Literal = "-" + op.Literal
+	}
+	return op, true
+}
+
+// loadGlobal reads the global variable name with global.load.
+func (fb *functionBuilder) loadGlobal(name string, global symbol) mirValue {
+	inst := mir.Instruction{ID: fb.fn.NextValue(), Op: "global.load", Type: global.Type, Operands: []mir.Operand{
+		{Kind: mir.OperandLiteral, Literal: name},
+	}}
+	fb.block.Instructions = append(fb.block.Instructions, inst)
+	return mirValue{ID: inst.ID, Type: global.Type}
+}
+
+// storeGlobal writes value to the global variable name with global.store.
+func (fb *functionBuilder) storeGlobal(name string, global symbol, value mirValue) {
+	fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{ID: mir.InvalidValue, Op: "global.store", Type: global.Type, Operands: []mir.Operand{
+		{Kind: mir.OperandLiteral, Literal: name},
+		valueOperand(value.ID, value.Type),
+	}})
+}
+
+// assignGlobal lowers an assignment of expr to the global variable name.
+func (fb *functionBuilder) assignGlobal(name string, expr ast.Expr) error {
+	global := fb.mb.globals[name]
+	if !global.Mutable {
+		return fmt.Errorf("mir builder: cannot assign to immutable variable %q", name)
+	}
+	rhs, err := fb.lowerExpr(expr)
+	if err != nil {
+		return err
+	}
+	fb.storeGlobal(name, global, fb.coerce(rhs, global.Type))
+	return nil
+}
+
 func (mb *moduleBuilder) buildFunction(fn *ast.FuncDecl) (*mir.Function, error) {
 	params := make([]mir.Param, len(fn.Params))
 	for i, p := range fn.Params {
-		params[i] = mir.Param{Name: p.Name, Type: typeExprToString(p.Type)}
+		params[i] = mir.Param{Name: p.Name, Type: typeExprToString(p.Type), Variadic: p.Variadic}
+		if p.Variadic {
+			params[i].Type = buildGeneric("[]", []string{params[i].Type})
+		}
 	}
 	returnType := "void"
 	if fn.Return != nil {
@@ -131,17 +434,25 @@ func (mb *moduleBuilder) buildFunction(fn *ast.FuncDecl) (*mir.Function, error)
 	}
 
 	mirFunc := mir.NewFunction(fn.Name, returnType, params)
+	mirFunc.Span = fn.SpanInfo
 	fb := &functionBuilder{
 		fn:    mirFunc,
 		block: mirFunc.NewBlock("entry"),
 		env:   make(map[string]symbol),
 		sigs:  mb.signatures,
 		mb:    mb,
+		file:  fn.File,
 	}
+	defer fb.at(fn.SpanInfo)()
 
 	for _, p := range mirFunc.Params {
 		fb.env[p.Name] = symbol{Value: p.ID, Type: p.Type, Mutable: true}
 	}
+	if fn.ReturnName != "" && fn.Return != nil {
+		if err := fb.declareNamedReturn(fn.ReturnName, typeExprToString(fn.Return)); err != nil {
+			return nil, err
+		}
+	}
 
 	if fn.ExprBody != nil {
 		value, err := fb.lowerExpr(fn.ExprBody)
@@ -156,11 +467,10 @@ func (mb *moduleBuilder) buildFunction(fn *ast.FuncDecl) (*mir.Function, error)
 		if err := fb.lowerBlock(fn.Body); err != nil {
 			return nil, err
 		}
-		if fb.block == nil {
-			return mirFunc, nil
-		}
-		if !fb.block.HasTerminator() {
-			if mirFunc.ReturnType == "void" {
+		if fb.block != nil && !fb.block.HasTerminator() {
+			if fb.namedReturn.name != "" {
+				fb.block.Terminator = fb.namedReturnTerminator()
+			} else if mirFunc.ReturnType == "void" {
 				fb.block.Terminator = mir.Terminator{Op: "ret"}
 			} else {
 				return nil, fmt.Errorf("mir builder: missing return in function %s", fn.Name)
@@ -168,9 +478,65 @@ func (mb *moduleBuilder) buildFunction(fn *ast.FuncDecl) (*mir.Function, error)
 		}
 	}
 
+	if fb.hasDefers {
+		fb.insertDeferredBlocks()
+	}
 	return mirFunc, nil
 }
 
+// zeroLiterals are the values a named return of each type starts with.
+var zeroLiterals = map[string]string{
+	"int": "0", "byte": "0", "int64": "0", "long": "0",
+	"uint": "0", "uint8": "0", "uint16": "0", "uint32": "0", "uint64": "0",
+	"float": "0.0", "double": "0.0", "bool": "false", "string": `""`,
+}
+
+// declareNamedReturn binds name to a mutable slot of type typ that holds the
+// zero value of the type, or none for an optional type, until the body
+// assigns it.
+func (fb *functionBuilder) declareNamedReturn(name, typ string) error {
+	inst := mir.Instruction{ID: fb.fn.NextValue(), Op: "opt.none", Type: typ}
+	if !strings.HasSuffix(typ, "?") {
+		zero, ok := zeroLiterals[typ]
+		if !ok {
+			return fmt.Errorf("mir builder: named return %q of type %s has no zero value", name, typ)
+		}
+		inst.Op = "const"
+		inst.Operands = []mir.Operand{{Kind: mir.OperandLiteral, Literal: zero, Type: typ}}
+	}
+	fb.block.Instructions = append(fb.block.Instructions, inst)
+	fb.namedReturn = namedReturn{name: name, slot: mirValue{ID: inst.ID, Type: typ}}
+	fb.env[name] = symbol{Value: inst.ID, Type: typ, Mutable: true}
+	return nil
+}
+
+// namedReturnTerminator returns the current value of the named return. The
+// name goes along with the slot, so that the C backend keeps the variable
+// out of the cleanup of the function.
+func (fb *functionBuilder) namedReturnTerminator() mir.Terminator {
+	slot := fb.namedReturn.slot
+	return mir.Terminator{Op: "ret.named", Operands: []mir.Operand{
+		valueOperand(slot.ID, slot.Type),
+		{Kind: mir.OperandLiteral, Literal: fb.namedReturn.name},
+	}}
+}
+
+// insertDeferredBlocks routes every return of the function through a
+// deferred block whose defer.run instruction runs the calls that defer.push
+// registered, most recent first, before the function returns.
+func (fb *functionBuilder) insertDeferredBlocks() {
+	blocks := append([]*mir.BasicBlock(nil), fb.fn.Blocks...)
+	for _, block := range blocks {
+		if block.Terminator.Op != "ret" && block.Terminator.Op != "ret.named" {
+			continue
+		}
+		deferred := fb.newBlock("deferred")
+		deferred.Instructions = append(deferred.Instructions, mir.Instruction{ID: mir.InvalidValue, Op: "defer.run", Type: "void"})
+		deferred.Terminator = block.Terminator
+		block.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{blockOperand(deferred)}}
+	}
+}
+
 func (fb *functionBuilder) lowerBlock(block *ast.BlockStmt) error {
 	if fb.block == nil {
 		return nil
@@ -190,6 +556,7 @@ func (fb *functionBuilder) lowerStmt(stmt ast.Stmt) error {
 	if fb.block == nil {
 		return nil
 	}
+	defer fb.at(stmt.Span())()
 	switch s := stmt.(type) {
 	case *ast.ReturnStmt:
 		var value mirValue
@@ -199,6 +566,7 @@ func (fb *functionBuilder) lowerStmt(stmt ast.Stmt) error {
 			if err != nil {
 				return err
 			}
+			value = fb.coerce(value, fb.fn.ReturnType)
 		} else {
 			value = mirValue{ID: mir.InvalidValue, Type: "void"}
 		}
@@ -206,6 +574,13 @@ func (fb *functionBuilder) lowerStmt(stmt ast.Stmt) error {
 		if value.ID != mir.InvalidValue {
 			operands = append(operands, valueOperand(value.ID, value.Type))
 		}
+		if err := fb.leaveTries(0); err != nil || fb.block == nil || fb.block.HasTerminator() {
+			return err
+		}
+		if s.Value == nil && fb.namedReturn.name != "" {
+			fb.block.Terminator = fb.namedReturnTerminator()
+			return nil
+		}
 		fb.block.Terminator = mir.Terminator{Op: "ret", Operands: operands}
 		return nil
 	case *ast.BindingStmt:
@@ -213,6 +588,9 @@ func (fb *functionBuilder) lowerStmt(stmt ast.Stmt) error {
 		if err != nil {
 			return err
 		}
+		if s.Type != nil {
+			val = fb.coerce(val, typeExprToString(s.Type))
+		}
 		fb.env[s.Name] = symbol{Value: val.ID, Type: val.Type, Mutable: s.Mutable}
 		return nil
 	case *ast.ShortVarDeclStmt:
@@ -220,6 +598,9 @@ func (fb *functionBuilder) lowerStmt(stmt ast.Stmt) error {
 		if err != nil {
 			return err
 		}
+		if s.Type != nil {
+			val = fb.coerce(val, typeExprToString(s.Type))
+		}
 		fb.env[s.Name] = symbol{Value: val.ID, Type: val.Type, Mutable: true}
 		return nil
 	case *ast.AssignmentStmt:
@@ -229,6 +610,9 @@ func (fb *functionBuilder) lowerStmt(stmt ast.Stmt) error {
 			// Simple variable assignment: x = value
 			sym, exists := fb.env[target.Name]
 			if !exists {
+				if _, ok := fb.mb.globals[target.Name]; ok {
+					return fb.assignGlobal(target.Name, s.Right)
+				}
 				return fmt.Errorf("mir builder: assignment to undefined variable %q", target.Name)
 			}
 			if !sym.Mutable {
@@ -238,6 +622,7 @@ func (fb *functionBuilder) lowerStmt(stmt ast.Stmt) error {
 			if err != nil {
 				return err
 			}
+			rhs = fb.coerce(rhs, sym.Type)
 
 			// Create an assignment instruction in the MIR
 			assignID := fb.fn.NextValue()
@@ -252,8 +637,9 @@ func (fb *functionBuilder) lowerStmt(stmt ast.Stmt) error {
 			}
 			fb.block.Instructions = append(fb.block.Instructions, assignInst)
 
-			// Update the environment to point to the new assignment result
-			fb.env[target.Name] = symbol{Value: assignID, Type: rhs.Type, Mutable: sym.Mutable}
+			// The variable keeps its value ID, so that later reads, in this
+			// block or on any path that reaches them, see the assigned value
+			fb.env[target.Name] = symbol{Value: sym.Value, Type: rhs.Type, Mutable: sym.Mutable}
 			return nil
 		case *ast.MemberExpr:
 			// Struct field assignment: obj.field = value
@@ -268,7 +654,13 @@ func (fb *functionBuilder) lowerStmt(stmt ast.Stmt) error {
 		default:
 			return fmt.Errorf("mir builder: unsupported assignment target type %T", s.Left)
 		}
+	case *ast.DestructureStmt:
+		return fb.lowerDestructureStmt(s)
 	case *ast.ExprStmt:
+		// An assignment on its own leaves out the read of its value
+		if assign, ok := s.Expr.(*ast.AssignmentExpr); ok {
+			return fb.lowerStmt(&ast.AssignmentStmt{SpanInfo: assign.SpanInfo, Left: assign.Left, Right: assign.Right})
+		}
 		_, err := fb.lowerExpr(s.Expr)
 		return err
 	case *ast.BlockStmt:
@@ -289,6 +681,8 @@ func (fb *functionBuilder) lowerStmt(stmt ast.Stmt) error {
 		return fb.lowerTryStmt(s)
 	case *ast.ThrowStmt:
 		return fb.lowerThrowStmt(s)
+	case *ast.DeferStmt:
+		return fb.lowerDeferStmt(s)
 	default:
 		return fmt.Errorf("mir builder: unsupported statement %T", s)
 	}
@@ -438,13 +832,12 @@ func (fb *functionBuilder) lowerRangeFor(stmt *ast.ForStmt) error {
 	// Create array length variable
 	lengthID := fb.fn.NextValue()
 
-	// Get the actual array length from the iterable
-	// For now, we'll use a hardcoded approach based on the array initialization
-	// TODO: Implement proper array length detection from MIR by analyzing the array initialization
+	// The length of an array literal in the block is a constant; any other
+	// array, such as a parameter or the result of a call, has its length
+	// taken at run time, which may be zero
 	var arrayLength string
 	if strings.HasPrefix(iterableValue.Type, "array<") || strings.HasPrefix(iterableValue.Type, "[]<") {
 		// Count the number of elements in the array literal
-		// This is a temporary solution - we should analyze the MIR to get the actual length
 		if len(fb.block.Instructions) > 0 {
 			// Look for the array initialization instruction
 			for i := len(fb.block.Instructions) - 1; i >= 0; i-- {
@@ -456,9 +849,6 @@ func (fb *functionBuilder) lowerRangeFor(stmt *ast.ForStmt) error {
 				}
 			}
 		}
-		if arrayLength == "" {
-			arrayLength = "1" // Fallback - assume at least 1 element
-		}
 	} else {
 		// Fallback for other array types
 		arrayLength = "1"
@@ -472,6 +862,13 @@ func (fb *functionBuilder) lowerRangeFor(stmt *ast.ForStmt) error {
 			{Kind: mir.OperandLiteral, Literal: arrayLength, Type: "int"},
 		},
 	}
+	if arrayLength == "" {
+		lengthInst.Op = "call"
+		lengthInst.Operands = []mir.Operand{
+			{Kind: mir.OperandLiteral, Literal: "len"},
+			valueOperand(iterableValue.ID, iterableValue.Type),
+		}
+	}
 	fb.block.Instructions = append(fb.block.Instructions, lengthInst)
 	fb.env["__array_length"] = symbol{Value: lengthID, Type: "int", Mutable: false}
 
@@ -563,10 +960,18 @@ func (fb *functionBuilder) lowerRangeFor(stmt *ast.ForStmt) error {
 
 	fb.env = bodyEnv
 
+	// Push loop context for break/continue
+	fb.pushLoop(loopContext{
+		breakBlock: exitBlock,
+		stepName:   "range_loop_step",
+	})
+
 	// Handle loop body
 	if err := fb.lowerBlock(stmt.Body); err != nil {
 		return err
 	}
+	fb.enterStep()
+	fb.loopStack = fb.loopStack[:len(fb.loopStack)-1]
 
 	// If body doesn't have a terminator, add index increment and loop back
 	if !fb.block.HasTerminator() {
@@ -634,11 +1039,13 @@ func (fb *functionBuilder) lowerClassicFor(stmt *ast.ForStmt) error {
 	// Create loop exit block
 	exitBlock := fb.newBlock("loop_exit")
 
-	// Push loop context for break/continue
-	fb.loopStack = append(fb.loopStack, loopContext{
-		continueBlock: headerBlock,
-		breakBlock:    exitBlock,
-	})
+	// Push loop context for break/continue; with a post statement,
+	// continue runs it before the next iteration
+	loopCtx := loopContext{continueBlock: headerBlock, breakBlock: exitBlock}
+	if stmt.Post != nil {
+		loopCtx = loopContext{breakBlock: exitBlock, stepName: "loop_post"}
+	}
+	fb.pushLoop(loopCtx)
 
 	// Branch from current block to header
 	currentBlock := fb.block
@@ -692,6 +1099,7 @@ func (fb *functionBuilder) lowerClassicFor(stmt *ast.ForStmt) error {
 	if err := fb.lowerBlock(stmt.Body); err != nil {
 		return err
 	}
+	fb.enterStep()
 
 	// If body doesn't have a terminator, add post-increment and loop back
 	if !fb.block.HasTerminator() {
@@ -717,38 +1125,41 @@ func (fb *functionBuilder) lowerClassicFor(stmt *ast.ForStmt) error {
 	return nil
 }
 
+// lowerWhileStmt lowers a while loop to a header block that tests the
+// condition and branches to the body or the exit block, and a body that
+// branches back to the header.
 func (fb *functionBuilder) lowerWhileStmt(stmt *ast.WhileStmt) error {
+	// Variables assigned in the body are read through their original
+	// values after the loop, as with the for loop
+	originalEnv := make(map[string]symbol)
+	for k, v := range fb.env {
+		originalEnv[k] = v
+	}
+
 	// Create blocks for the while loop
 	headerBlock := fb.newBlock("while_header")
 	bodyBlock := fb.newBlock("while_body")
 	exitBlock := fb.newBlock("while_exit")
 
 	// Push loop context for break/continue
-	fb.loopStack = append(fb.loopStack, loopContext{
+	fb.pushLoop(loopContext{
 		continueBlock: headerBlock,
 		breakBlock:    exitBlock,
 	})
 
 	// Branch from current block to header
-	currentBlock := fb.block
-	if currentBlock != nil {
-		currentBlock.Terminator = mir.Terminator{
-			Op:       "br",
-			Operands: []mir.Operand{blockOperand(headerBlock)},
-		}
+	fb.block.Terminator = mir.Terminator{
+		Op:       "br",
+		Operands: []mir.Operand{blockOperand(headerBlock)},
 	}
 
-	// Set current block to header
+	// Evaluate the condition in the header
 	fb.block = headerBlock
-
-	// Evaluate condition
 	condValue, err := fb.lowerExpr(stmt.Cond)
 	if err != nil {
 		return err
 	}
-
-	// Branch to body if condition is true, exit if false
-	headerBlock.Terminator = mir.Terminator{
+	fb.block.Terminator = mir.Terminator{
 		Op: "cbr",
 		Operands: []mir.Operand{
 			valueOperand(condValue.ID, condValue.Type),
@@ -757,20 +1168,18 @@ func (fb *functionBuilder) lowerWhileStmt(stmt *ast.WhileStmt) error {
 		},
 	}
 
-	// Lower loop body
+	// Lower the body, which loops back to the header unless it ends in a
+	// break, continue or return
+	bodyEnv := make(map[string]symbol)
+	for k, v := range fb.env {
+		bodyEnv[k] = v
+	}
 	fb.block = bodyBlock
-	for _, s := range stmt.Body.Statements {
-		if err := fb.lowerStmt(s); err != nil {
-			return err
-		}
-		// If the block already has a terminator (e.g., break/continue/return), don't add another
-		if fb.block.Terminator.Op != "" {
-			break
-		}
+	fb.env = bodyEnv
+	if err := fb.lowerBlock(stmt.Body); err != nil {
+		return err
 	}
-
-	// If body doesn't have terminator, branch back to header
-	if fb.block != nil && fb.block.Terminator.Op == "" {
+	if !fb.block.HasTerminator() {
 		fb.block.Terminator = mir.Terminator{
 			Op:       "br",
 			Operands: []mir.Operand{blockOperand(headerBlock)},
@@ -780,8 +1189,9 @@ func (fb *functionBuilder) lowerWhileStmt(stmt *ast.WhileStmt) error {
 	// Pop loop context
 	fb.loopStack = fb.loopStack[:len(fb.loopStack)-1]
 
-	// Set current block to exit block
+	// Set current block to exit block and restore original environment
 	fb.block = exitBlock
+	fb.env = originalEnv
 	return nil
 }
 
@@ -791,9 +1201,12 @@ func (fb *functionBuilder) lowerBreakStmt(stmt *ast.BreakStmt) error {
 	}
 	// Get the current loop context
 	loopCtx := fb.loopStack[len(fb.loopStack)-1]
-	// Branch to the break block
+	if err := fb.leaveTries(loopCtx.tryDepth); err != nil || fb.block == nil || fb.block.HasTerminator() {
+		return err
+	}
+	// Leave the loop through its exit block
 	fb.block.Terminator = mir.Terminator{
-		Op:       "br",
+		Op:       "loop.break",
 		Operands: []mir.Operand{blockOperand(loopCtx.breakBlock)},
 	}
 	// Create a new unreachable block for any statements after break
@@ -806,10 +1219,16 @@ func (fb *functionBuilder) lowerContinueStmt(stmt *ast.ContinueStmt) error {
 		return fmt.Errorf("mir builder: continue statement outside of loop")
 	}
 	// Get the current loop context
-	loopCtx := fb.loopStack[len(fb.loopStack)-1]
-	// Branch to the continue block
+	loopCtx := &fb.loopStack[len(fb.loopStack)-1]
+	if err := fb.leaveTries(loopCtx.tryDepth); err != nil || fb.block == nil || fb.block.HasTerminator() {
+		return err
+	}
+	if loopCtx.continueBlock == nil {
+		loopCtx.continueBlock = fb.newBlock(loopCtx.stepName)
+	}
+	// Start the next iteration at the loop's continue block
 	fb.block.Terminator = mir.Terminator{
-		Op:       "br",
+		Op:       "loop.continue",
 		Operands: []mir.Operand{blockOperand(loopCtx.continueBlock)},
 	}
 	// Create a new unreachable block for any statements after continue
@@ -826,7 +1245,14 @@ func (fb *functionBuilder) lowerIncrementStmt(stmt *ast.IncrementStmt) error {
 
 	sym, exists := fb.env[target.Name]
 	if !exists {
-		return fmt.Errorf("mir builder: increment of undefined variable %q", target.Name)
+		global, ok := fb.mb.globals[target.Name]
+		if !ok {
+			return fmt.Errorf("mir builder: increment of undefined variable %q", target.Name)
+		}
+		if !global.Mutable {
+			return fmt.Errorf("mir builder: cannot increment immutable variable %q", target.Name)
+		}
+		sym = symbol{Value: fb.loadGlobal(target.Name, global).ID, Type: global.Type, Mutable: true}
 	}
 
 	if !sym.Mutable {
@@ -867,6 +1293,10 @@ func (fb *functionBuilder) lowerIncrementStmt(stmt *ast.IncrementStmt) error {
 		},
 	}
 	fb.block.Instructions = append(fb.block.Instructions, incInst)
+	if !exists {
+		fb.storeGlobal(target.Name, fb.mb.globals[target.Name], mirValue{ID: id, Type: sym.Type})
+		return nil
+	}
 
 	// Create an assignment instruction to update the original variable
 	assignID := fb.fn.NextValue()
@@ -903,12 +1333,16 @@ func (fb *functionBuilder) lowerExpr(expr ast.Expr) (mirValue, error) {
 	if fb.block == nil {
 		return mirValue{ID: mir.InvalidValue, Type: inferTypePlaceholder}, nil
 	}
+	defer fb.at(expr.Span())()
 	switch e := expr.(type) {
 	case *ast.LiteralExpr:
 		return fb.emitLiteral(e)
 	case *ast.IdentifierExpr:
 		sym, ok := fb.env[e.Name]
 		if !ok {
+			if global, exists := fb.mb.globals[e.Name]; exists {
+				return fb.loadGlobal(e.Name, global), nil
+			}
 			if sig, exists := fb.sigs[e.Name]; exists {
 				// For first-class functions, emit a constant that refers to the function name
 				id := fb.fn.NextValue()
@@ -931,12 +1365,16 @@ func (fb *functionBuilder) lowerExpr(expr ast.Expr) (mirValue, error) {
 		return fb.emitBinary(e)
 	case *ast.AwaitExpr:
 		return fb.emitAwait(e)
+	case *ast.MatchExpr:
+		return fb.lowerMatchExpr(e)
 	case *ast.UnaryExpr:
 		return fb.emitUnary(e)
 	case *ast.CallExpr:
 		return fb.emitCall(e)
 	case *ast.StructLiteralExpr:
 		return fb.emitStructLiteral(e)
+	case *ast.TupleLiteralExpr:
+		return fb.emitTupleLiteral(e)
 	case *ast.ArrayLiteralExpr:
 		return fb.emitArrayLiteral(e)
 	case *ast.MapLiteralExpr:
@@ -953,7 +1391,10 @@ func (fb *functionBuilder) lowerExpr(expr ast.Expr) (mirValue, error) {
 		if !ok {
 			return mirValue{}, fmt.Errorf("mir builder: expected identifier assignment")
 		}
-		sym := fb.env[ident.Name]
+		sym, local := fb.env[ident.Name]
+		if global, ok := fb.mb.globals[ident.Name]; ok && !local {
+			return fb.loadGlobal(ident.Name, global), nil
+		}
 		return mirValue{ID: sym.Value, Type: sym.Type}, nil
 	case *ast.NewExpr:
 		// Implement actual memory allocation
@@ -1062,6 +1503,13 @@ func literalType(lit *ast.LiteralExpr) string {
 		return "int"
 	case ast.LiteralBinary:
 		return "int"
+	case ast.LiteralInt64:
+		return "int64"
+	case ast.LiteralUint:
+		if typ, ok := lit.UnsignedType(); ok {
+			return typ
+		}
+		return "uint64"
 	default:
 		return inferTypePlaceholder
 	}
@@ -1078,6 +1526,15 @@ func (fb *functionBuilder) emitBinary(expr *ast.BinaryExpr) (mirValue, error) {
 	}
 	id := fb.fn.NextValue()
 	resultType := left.Type
+	// int operands are widened when combined with an int64, and unsigned
+	// ones when combined with a wider unsigned type; shifts keep the type of
+	// the value shifted
+	if left.Type == "int" && (right.Type == "int64" || right.Type == "long") {
+		resultType = right.Type
+	}
+	if types.UnsignedWidths[left.Type] > 0 && types.UnsignedWidths[left.Type] < types.UnsignedWidths[right.Type] && expr.Op != "<<" && expr.Op != ">>" {
+		resultType = right.Type
+	}
 	if isComparison(expr.Op) || isLogical(expr.Op) {
 		resultType = "bool"
 	}
@@ -1098,9 +1555,14 @@ func (fb *functionBuilder) emitBinary(expr *ast.BinaryExpr) (mirValue, error) {
 		return mirValue{ID: id, Type: resultType}, nil
 	}
 
+	op := mapBinaryOp(expr.Op)
+	// Unsigned division and remainder differ from the signed ones
+	if types.UnsignedWidths[resultType] > 0 && (op == "div" || op == "mod") {
+		op = "u" + op
+	}
 	inst := mir.Instruction{
 		ID:   id,
-		Op:   mapBinaryOp(expr.Op),
+		Op:   op,
 		Type: resultType,
 		Operands: []mir.Operand{
 			valueOperand(left.ID, left.Type),
@@ -1173,64 +1635,266 @@ func (fb *functionBuilder) emitUnary(expr *ast.UnaryExpr) (mirValue, error) {
 	return mirValue{ID: id, Type: resultType}, nil
 }
 
-func (fb *functionBuilder) emitCall(expr *ast.CallExpr) (mirValue, error) {
-	// Handle array method calls like x.len() where x is an array
-	if member, ok := expr.Callee.(*ast.MemberExpr); ok {
-		// Try to lower the target expression to get its type
-		target, err := fb.lowerExpr(member.Target)
-		if err == nil {
-			// Check if this is an array method call
-			if strings.HasPrefix(target.Type, "[]<") || strings.HasPrefix(target.Type, "array<") {
-				if member.Member == "len" && len(expr.Args) == 0 {
-					// Convert x.len() to len(x) by calling the builtin len function
-					id := fb.fn.NextValue()
-					operands := []mir.Operand{
-						{Kind: mir.OperandLiteral, Literal: "len"},
-						valueOperand(target.ID, target.Type),
-					}
-
-					inst := mir.Instruction{
-						ID:       id,
-						Op:       "call",
-						Type:     "int",
-						Operands: operands,
-					}
-					fb.block.Instructions = append(fb.block.Instructions, inst)
-					return mirValue{ID: id, Type: "int"}, nil
-				}
-				return mirValue{}, fmt.Errorf("mir builder: unsupported array method %q", member.Member)
-			}
-		}
+// emitMapContains lowers map.contains(m, key) to a map.contains instruction.
+func (fb *functionBuilder) emitMapContains(expr *ast.CallExpr) (mirValue, error) {
+	if len(expr.Args) != 2 {
+		return mirValue{}, fmt.Errorf("mir builder: map.contains expects 2 arguments, got %d", len(expr.Args))
+	}
+	m, err := fb.lowerExpr(expr.Args[0])
+	if err != nil {
+		return mirValue{}, err
+	}
+	key, err := fb.lowerExpr(expr.Args[1])
+	if err != nil {
+		return mirValue{}, err
 	}
-
 	id := fb.fn.NextValue()
-	operands := []mir.Operand{}
-	calleeName := "<unknown>"
-	if ident, ok := expr.Callee.(*ast.IdentifierExpr); ok {
-		// Check if this is a function type variable
-		if sym, exists := fb.env[ident.Name]; exists {
-			// This is a variable - check if it's a function type
-			if strings.Contains(sym.Type, ") -> ") {
-				// This is a function type variable - use the variable as the callee
-				operands = append(operands, valueOperand(sym.Value, sym.Type))
-			} else {
-				calleeName = ident.Name
-			}
-		} else {
-			calleeName = ident.Name
+	fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{
+		ID:       id,
+		Op:       "map.contains",
+		Type:     "bool",
+		Operands: []mir.Operand{valueOperand(m.ID, m.Type), valueOperand(key.ID, key.Type)},
+	})
+	return mirValue{ID: id, Type: "bool"}, nil
+}
+
+// optBuiltins are the opt functions on optional values, which lower to the
+// MIR instruction of the same name.
+var optBuiltins = map[string]bool{"unwrap": true, "unwrap_or": true, "is_some": true, "is_none": true}
+
+// emitOptBuiltin lowers a call of an opt builtin, e.g. opt.unwrap_or(v, d),
+// to its opt instruction.
+func (fb *functionBuilder) emitOptBuiltin(expr *ast.CallExpr, name string) (mirValue, error) {
+	var operands []mir.Operand
+	var value mirValue
+	for i, arg := range expr.Args {
+		v, err := fb.lowerExpr(arg)
+		if err != nil {
+			return mirValue{}, err
 		}
-	} else if member, ok := expr.Callee.(*ast.MemberExpr); ok {
-		// Handle module member access (e.g., math_utils.add)
-		if ident, ok := member.Target.(*ast.IdentifierExpr); ok {
-			calleeName = ident.Name + "." + member.Member
+		if i == 0 {
+			value = v
+		}
+		operands = append(operands, valueOperand(v.ID, v.Type))
+	}
+	if len(operands) == 0 {
+		return mirValue{}, fmt.Errorf("mir builder: opt.%s expects an optional value", name)
+	}
+	resultType := "bool"
+	if name == "unwrap" || name == "unwrap_or" {
+		resultType = strings.TrimRight(value.Type, "?")
+	}
+	id := fb.fn.NextValue()
+	fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{
+		ID:       id,
+		Op:       "opt." + name,
+		Type:     resultType,
+		Operands: operands,
+	})
+	return mirValue{ID: id, Type: resultType}, nil
+}
+
+// coerce converts val for a variable, parameter or result of type target,
+// wrapping it as an optional or as an interface value where target needs.
+func (fb *functionBuilder) coerce(val mirValue, target string) mirValue {
+	val = fb.coerceInferred(val, target)
+	return fb.coerceOptional(fb.coerceInterface(fb.coerceAny(val, target), target), target)
+}
+
+// coerceInferred gives val the type target when val is the same generic type
+// with an argument left to infer, as deque.create() returns. The instruction
+// that produced val is retyped, so the backends see the declared type.
+func (fb *functionBuilder) coerceInferred(val mirValue, target string) mirValue {
+	open := strings.Index(val.Type, "<")
+	if open == -1 || !strings.Contains(val.Type, inferTypePlaceholder) || !strings.HasPrefix(target, val.Type[:open+1]) {
+		return val
+	}
+	insts := fb.block.Instructions
+	if len(insts) == 0 || insts[len(insts)-1].ID != val.ID {
+		return val
+	}
+	insts[len(insts)-1].Type = target
+	return mirValue{ID: val.ID, Type: target}
+}
+
+// coerceAny converts val for a target of type any with a cast, which the C
+// backend turns into a dynamic value.
+func (fb *functionBuilder) coerceAny(val mirValue, target string) mirValue {
+	if target != "any" || val.Type == "any" || val.ID == mir.InvalidValue || val.Type == "" ||
+		val.Type == inferTypePlaceholder || val.Type == "null" {
+		return val
+	}
+	inst := mir.Instruction{ID: fb.fn.NextValue(), Op: "cast", Type: "any", Operands: []mir.Operand{valueOperand(val.ID, val.Type)}}
+	fb.block.Instructions = append(fb.block.Instructions, inst)
+	return mirValue{ID: inst.ID, Type: "any"}
+}
+
+// coerceInterface converts a struct val for a target of interface type with
+// interface.init, whose operands are the struct, its type name and the
+// functions implementing the methods of the interface, in vtable order.
+func (fb *functionBuilder) coerceInterface(val mirValue, target string) mirValue {
+	iface, ok := fb.mb.interfaces[target]
+	if !ok || val.ID == mir.InvalidValue || fb.mb.structFields[val.Type] == nil {
+		return val
+	}
+	operands := []mir.Operand{valueOperand(val.ID, val.Type), {Kind: mir.OperandLiteral, Literal: val.Type}}
+	for _, m := range iface.Methods {
+		operands = append(operands, mir.Operand{Kind: mir.OperandLiteral, Literal: fb.mb.methods[val.Type][m.Name]})
+	}
+	inst := mir.Instruction{ID: fb.fn.NextValue(), Op: "interface.init", Type: target, Operands: operands}
+	fb.block.Instructions = append(fb.block.Instructions, inst)
+	return mirValue{ID: inst.ID, Type: target}
+}
+
+// emitInterfaceCall calls the method name of the interface value receiver
+// through its vtable with interface.call, whose operands are the receiver,
+// the method name and the arguments.
+func (fb *functionBuilder) emitInterfaceCall(expr *ast.CallExpr, iface *mir.Interface, name string, receiver mirValue) (mirValue, error) {
+	var method *mir.InterfaceMethod
+	for i := range iface.Methods {
+		if iface.Methods[i].Name == name {
+			method = &iface.Methods[i]
+		}
+	}
+	if method == nil {
+		return mirValue{}, fmt.Errorf("mir builder: interface %s has no method %q", iface.Name, name)
+	}
+	operands := []mir.Operand{valueOperand(receiver.ID, receiver.Type), {Kind: mir.OperandLiteral, Literal: name}}
+	for i, arg := range expr.Args {
+		value, err := fb.lowerExpr(arg)
+		if err != nil {
+			return mirValue{}, err
+		}
+		if i < len(method.Params) {
+			value = fb.coerce(value, method.Params[i])
+		}
+		operands = append(operands, valueOperand(value.ID, value.Type))
+	}
+	id := fb.fn.NextValue()
+	fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{ID: id, Op: "interface.call", Type: method.Return, Operands: operands})
+	return mirValue{ID: id, Type: method.Return}, nil
+}
+
+// coerceOptional converts val for a variable, parameter or result of type
+// target: where target is T?, a T becomes opt.some and null opt.none.
+// Values that are optional already, or whose type is not known, are left
+// as they are.
+func (fb *functionBuilder) coerceOptional(val mirValue, target string) mirValue {
+	if !strings.HasSuffix(target, "?") || strings.HasSuffix(val.Type, "?") || val.ID == mir.InvalidValue ||
+		val.Type == "" || val.Type == inferTypePlaceholder {
+		return val
+	}
+	inst := mir.Instruction{ID: fb.fn.NextValue(), Op: "opt.some", Type: target, Operands: []mir.Operand{valueOperand(val.ID, val.Type)}}
+	if val.Type == "null" {
+		inst.Op, inst.Operands = "opt.none", nil
+	}
+	fb.block.Instructions = append(fb.block.Instructions, inst)
+	return mirValue{ID: inst.ID, Type: target}
+}
+
+// emitMethodCall calls the function callee that implements a method, with
+// the receiver as its first argument.
+func (fb *functionBuilder) emitMethodCall(expr *ast.CallExpr, callee string, receiver mirValue) (mirValue, error) {
+	sig := fb.sigs[callee]
+	operands := []mir.Operand{{Kind: mir.OperandLiteral, Literal: callee}, valueOperand(receiver.ID, receiver.Type)}
+	for i, arg := range expr.Args {
+		value, err := fb.lowerExpr(arg)
+		if err != nil {
+			return mirValue{}, err
+		}
+		if i+1 < len(sig.Params) {
+			value = fb.coerce(value, sig.Params[i+1])
+		}
+		operands = append(operands, valueOperand(value.ID, value.Type))
+	}
+	id := fb.fn.NextValue()
+	fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{ID: id, Op: "call", Type: sig.Return, Operands: operands})
+	return mirValue{ID: id, Type: sig.Return}, nil
+}
+
+func (fb *functionBuilder) emitCall(expr *ast.CallExpr) (mirValue, error) {
+	if member, ok := expr.Callee.(*ast.MemberExpr); ok && member.Member == "contains" {
+		if ident, ok := member.Target.(*ast.IdentifierExpr); ok && ident.Name == "map" {
+			if _, shadowed := fb.env["map"]; !shadowed {
+				return fb.emitMapContains(expr)
+			}
+		}
+	}
+	if member, ok := expr.Callee.(*ast.MemberExpr); ok && optBuiltins[member.Member] {
+		if ident, ok := member.Target.(*ast.IdentifierExpr); ok && ident.Name == "opt" {
+			if _, shadowed := fb.env["opt"]; !shadowed {
+				return fb.emitOptBuiltin(expr, member.Member)
+			}
+		}
+	}
+
+	// Handle array method calls like x.len() where x is an array
+	if member, ok := expr.Callee.(*ast.MemberExpr); ok {
+		// Try to lower the target expression to get its type
+		target, err := fb.lowerExpr(member.Target)
+		if err == nil {
+			if callee, ok := fb.mb.methods[target.Type][member.Member]; ok {
+				return fb.emitMethodCall(expr, callee, target)
+			}
+			if iface, ok := fb.mb.interfaces[target.Type]; ok {
+				return fb.emitInterfaceCall(expr, iface, member.Member, target)
+			}
+			// Check if this is an array method call
+			if strings.HasPrefix(target.Type, "[]<") || strings.HasPrefix(target.Type, "array<") {
+				if member.Member == "len" && len(expr.Args) == 0 {
+					// Convert x.len() to len(x) by calling the builtin len function
+					id := fb.fn.NextValue()
+					operands := []mir.Operand{
+						{Kind: mir.OperandLiteral, Literal: "len"},
+						valueOperand(target.ID, target.Type),
+					}
+
+					inst := mir.Instruction{
+						ID:       id,
+						Op:       "call",
+						Type:     "int",
+						Operands: operands,
+					}
+					fb.block.Instructions = append(fb.block.Instructions, inst)
+					return mirValue{ID: id, Type: "int"}, nil
+				}
+				return mirValue{}, fmt.Errorf("mir builder: unsupported array method %q", member.Member)
+			}
+		}
+	}
+
+	id := fb.fn.NextValue()
+	operands := []mir.Operand{}
+	calleeName := "<unknown>"
+	if ident, ok := expr.Callee.(*ast.IdentifierExpr); ok {
+		// Check if this is a function type variable
+		if sym, exists := fb.env[ident.Name]; exists {
+			// This is a variable - check if it's a function type
+			if strings.Contains(sym.Type, ") -> ") {
+				// This is a function type variable - use the variable as the callee
+				operands = append(operands, valueOperand(sym.Value, sym.Type))
+			} else {
+				calleeName = ident.Name
+			}
+		} else {
+			calleeName = ident.Name
+		}
+	} else if member, ok := expr.Callee.(*ast.MemberExpr); ok {
+		// Handle module member access (e.g., math_utils.add)
+		if ident, ok := member.Target.(*ast.IdentifierExpr); ok {
+			calleeName = ident.Name + "." + member.Member
 		}
 	}
 	// Normalize alias-only module calls like io.println -> std.io.println
 	if !strings.HasPrefix(calleeName, "std.") && strings.Contains(calleeName, ".") {
 		// Best-effort normalization: if the first segment matches a known std submodule
 		parts := strings.Split(calleeName, ".")
+		if qualified, ok := fb.mb.stdAliases[parts[0]]; ok {
+			parts[0] = qualified
+			calleeName = strings.Join(parts, ".")
+		}
 		switch parts[0] {
-		case "io", "math", "string", "str", "array", "os", "collections":
+		case "io", "math", "string", "str", "array", "os", "collections", "hash", "json", "regex", "process", "path", "env", "sort", "time":
 			if parts[0] == "str" {
 				// Map str to std.string
 				calleeName = "std.string." + parts[1]
@@ -1310,11 +1974,119 @@ func (fb *functionBuilder) emitCall(expr *ast.CallExpr) (mirValue, error) {
 
 	if strings.HasPrefix(calleeName, "std.") {
 		// For std functions, determine return type based on function name
-		if strings.Contains(calleeName, "io.") {
+		if strings.HasPrefix(calleeName, "std.io.diff.") {
+			resultType = "string"
+		} else if strings.HasPrefix(calleeName, "std.io.prompt") {
+			switch calleeName {
+			case "std.io.prompt_int":
+				resultType = "int"
+			case "std.io.prompt_float":
+				resultType = "float"
+			default:
+				resultType = "string"
+			}
+		} else if strings.HasPrefix(calleeName, "std.io.readline_history.") {
+			switch calleeName {
+			case "std.io.readline_history.get":
+				resultType = "string?"
+			case "std.io.readline_history.count":
+				resultType = "int"
+			default:
+				resultType = "void"
+			}
+		} else if strings.HasPrefix(calleeName, "std.hash.") {
+			switch calleeName {
+			case "std.hash.sha256", "std.hash.sha512", "std.hash.md5":
+				resultType = "string"
+			default:
+				// fnv32, fnv64, murmur3
+				resultType = "int"
+			}
+		} else if strings.HasPrefix(calleeName, "std.regex.") {
+			resultType = "[]<string>"
+			if calleeName == "std.regex.find" {
+				resultType = "string?"
+			}
+		} else if strings.HasPrefix(calleeName, "std.sort.") {
+			resultType = "void"
+		} else if strings.HasPrefix(calleeName, "std.env.") {
+			switch calleeName {
+			case "std.env.get_int":
+				resultType = "int"
+			case "std.env.get_bool":
+				resultType = "bool"
+			default:
+				resultType = "string"
+			}
+		} else if strings.HasPrefix(calleeName, "std.path.") {
+			resultType = "string"
+			if calleeName == "std.path.is_abs" {
+				resultType = "bool"
+			}
+		} else if strings.HasPrefix(calleeName, "std.process.") {
+			resultType = "ProcessResult"
+			if calleeName == "std.process.run_async" {
+				resultType = "Promise<ProcessResult>"
+			}
+		} else if strings.HasPrefix(calleeName, "std.json.") {
+			resultType = "any"
+			if calleeName == "std.json.stringify" {
+				resultType = "string"
+			}
+		} else if calleeName == "std.time.format" {
+			resultType = "string"
+		} else if calleeName == "std.time.parse" {
+			resultType = "Time"
+		} else if strings.HasPrefix(calleeName, "std.time.format.") {
+			if calleeName == "std.time.format.strptime" {
+				resultType = "Time"
+			} else {
+				resultType = "string"
+			}
+		} else if strings.HasPrefix(calleeName, "std.collections.graph.") {
+			switch strings.TrimPrefix(calleeName, "std.collections.graph.") {
+			case "create", "mst":
+				resultType = "Graph"
+			case "add_edge", "has_edge":
+				resultType = "bool"
+			case "topological_sort":
+				resultType = "array<int>?"
+			case "strongly_connected_components":
+				resultType = "array<array<int>>"
+			default:
+				// node_count, edge_count, total_weight
+				resultType = "int"
+			}
+		} else if strings.HasPrefix(calleeName, "std.math.interpolation.") {
+			if calleeName == "std.math.interpolation.cubic_spline" {
+				resultType = "Spline"
+			} else {
+				resultType = "float"
+			}
+		} else if strings.HasPrefix(calleeName, "std.os.") {
+			switch strings.TrimPrefix(calleeName, "std.os.") {
+			case "getenv", "getcwd", "read_file", "get_flag", "positional_arg":
+				resultType = "string"
+			case "setenv", "unsetenv", "chdir", "mkdir", "rmdir", "exists", "is_file", "is_dir",
+				"remove", "rename", "copy", "write_file", "append_file", "has_flag":
+				resultType = "bool"
+			case "getpid", "getppid", "args_count":
+				resultType = "int"
+			default:
+				// exit and the std.os.signal functions
+				resultType = "void"
+			}
+		} else if strings.Contains(calleeName, "io.") {
 			resultType = "void"
 		} else if strings.Contains(calleeName, "math.") {
 			// Determine return type based on specific math function
 			switch {
+			case strings.HasSuffix(calleeName, "math.random"):
+				resultType = "float"
+			case strings.HasSuffix(calleeName, "math.random_string"):
+				resultType = "string"
+			case strings.HasSuffix(calleeName, "math.seed"):
+				resultType = "void"
 			case strings.Contains(calleeName, "pow"):
 				resultType = "float"
 			case strings.Contains(calleeName, "sqrt"):
@@ -1342,6 +2114,14 @@ func (fb *functionBuilder) emitCall(expr *ast.CallExpr) (mirValue, error) {
 				resultType = "int"
 			case strings.Contains(calleeName, "compare"):
 				resultType = "int"
+			case strings.Contains(calleeName, "count_occurrences"):
+				resultType = "int"
+			case strings.HasSuffix(calleeName, "string.parse_int"):
+				resultType = "tuple<int,bool>"
+			case strings.HasSuffix(calleeName, "string.parse_float"):
+				resultType = "tuple<float,bool>"
+			case strings.HasSuffix(calleeName, "string.parse_bool"):
+				resultType = "tuple<bool,bool>"
 			case strings.Contains(calleeName, "starts_with"):
 				resultType = "bool"
 			case strings.Contains(calleeName, "ends_with"):
@@ -1352,6 +2132,8 @@ func (fb *functionBuilder) emitCall(expr *ast.CallExpr) (mirValue, error) {
 				resultType = "bool"
 			case strings.Contains(calleeName, "char_at"):
 				resultType = "char"
+			case strings.Contains(calleeName, "split"):
+				resultType = "[]<string>"
 			default:
 				// concat, substring, trim, to_upper, to_lower, etc.
 				resultType = "string"
@@ -1382,6 +2164,9 @@ func (fb *functionBuilder) emitCall(expr *ast.CallExpr) (mirValue, error) {
 			} else {
 				resultType = "int" // Default for array operations
 			}
+		} else if sig, exists := fb.sigs[calleeName]; exists {
+			// Modules without a heuristic, e.g. ones replaced through an import map
+			resultType = sig.Return
 		} else {
 			resultType = "void"
 		}
@@ -1400,13 +2185,65 @@ func (fb *functionBuilder) emitCall(expr *ast.CallExpr) (mirValue, error) {
 		}
 	}
 
-	for _, arg := range expr.Args {
-		value, err := fb.lowerExpr(arg)
+	sig, hasSig := fb.sigs[calleeName]
+	args := expr.Args
+	if hasSig && sig.Variadic && len(args) >= len(sig.Params)-1 {
+		args = args[:len(sig.Params)-1]
+	}
+	if hasSig {
+		// The arguments a call leaves out take the defaults of their
+		// parameters, which the checker made sure are constants
+		fixed := len(sig.Params)
+		if sig.Variadic {
+			fixed--
+		}
+		for i := len(args); i < fixed && i < len(sig.Defaults) && sig.Defaults[i] != nil; i++ {
+			args = append(args[:i:i], sig.Defaults[i])
+		}
+	}
+	for i, arg := range args {
+		var value mirValue
+		var err error
+		if lambda, ok := arg.(*ast.LambdaExpr); ok && isCollectionsCallback(calleeName) {
+			value, err = fb.emitLambdaWithParams(lambda, collectionsCallbackParams(calleeName, operands[1:]))
+		} else {
+			value, err = fb.lowerExpr(arg)
+		}
 		if err != nil {
 			return mirValue{}, err
 		}
+		if hasSig && i < len(sig.Params) {
+			value = fb.coerce(value, sig.Params[i])
+		} else if calleeName == "std.json.stringify" {
+			// The C backend has no std signatures, but the runtime takes
+			// the value as a JSON tree all the same
+			value = fb.coerce(value, "any")
+		}
 		operands = append(operands, valueOperand(value.ID, value.Type))
 	}
+	if hasSig && sig.Variadic {
+		var rest []ast.Expr
+		if len(expr.Args) > len(args) {
+			rest = expr.Args[len(args):]
+		}
+		packed, err := fb.packVariadicArgs(rest, sig.Params[len(sig.Params)-1])
+		if err != nil {
+			return mirValue{}, err
+		}
+		operands = append(operands, valueOperand(packed.ID, packed.Type))
+	}
+	if isCollectionsCallback(calleeName) {
+		resultType = collectionsResultType(calleeName, operands[1:])
+	}
+	if strings.HasPrefix(calleeName, "std.collections.deque.") {
+		resultType = dequeResultType(calleeName, operands[1:])
+	}
+	if strings.HasPrefix(calleeName, "std.collections.trie.") {
+		resultType = trieResultType(calleeName, operands[1:])
+	}
+	if strings.HasPrefix(calleeName, "std.collections.ordered_map.") {
+		resultType = orderedMapResultType(calleeName, operands[1:])
+	}
 
 	inst := mir.Instruction{
 		ID:       id,
@@ -1424,35 +2261,202 @@ func (fb *functionBuilder) emitMemberAccess(expr *ast.MemberExpr) (mirValue, err
 		// Check if this is a struct field access
 		if sym, exists := fb.env[ident.Name]; exists {
 			// This is a struct field access
-			// Get the field type from the struct definition
-			fieldType := "int" // Default fallback
-			if fields, ok := fb.mb.structFields[sym.Type]; ok {
-				if ft, exists := fields[expr.Member]; exists {
-					fieldType = ft
-				}
-			}
-
-			id := fb.fn.NextValue()
-			inst := mir.Instruction{
-				ID:   id,
-				Op:   "member",
-				Type: fieldType,
-				Operands: []mir.Operand{
-					valueOperand(sym.Value, sym.Type),
-					{Kind: mir.OperandLiteral, Literal: expr.Member},
-				},
-			}
-			fb.block.Instructions = append(fb.block.Instructions, inst)
-			return mirValue{ID: id, Type: fieldType}, nil
+			return fb.emitField(mirValue{ID: sym.Value, Type: sym.Type}, expr.Member), nil
+		}
+		if enum, ok := fb.mb.enums[ident.Name]; ok {
+			return fb.emitEnumTag(enum, expr.Member), nil
+		}
+		// Module constants are inlined as literals
+		if lit, ok := fb.mb.constants[ident.Name+"."+expr.Member]; ok {
+			return fb.emitLiteral(lit)
 		}
 		// Check if it's a function call context (this will be handled by the caller)
 		// For now, just return a placeholder that indicates this is a qualified function
 		return mirValue{ID: mir.InvalidValue, Type: "func"}, nil
 	}
 
+	// The field of a value computed first, e.g. doc.user.name or items[0].name
+	switch expr.Target.(type) {
+	case *ast.MemberExpr, *ast.IndexExpr, *ast.CallExpr:
+		target, err := fb.lowerExpr(expr.Target)
+		if err != nil {
+			return mirValue{}, err
+		}
+		if target.ID != mir.InvalidValue {
+			return fb.emitField(target, expr.Member), nil
+		}
+	}
+
 	return mirValue{}, fmt.Errorf("mir builder: unsupported member access target type %T", expr.Target)
 }
 
+// emitField emits the member instruction reading the field name of target.
+// The fields of an any value are any again.
+func (fb *functionBuilder) emitField(target mirValue, name string) mirValue {
+	fieldType := "int" // Default fallback
+	if target.Type == "any" {
+		fieldType = "any"
+	} else if fields, ok := fb.mb.structFields[target.Type]; ok {
+		if ft, exists := fields[name]; exists {
+			fieldType = ft
+		}
+	}
+
+	id := fb.fn.NextValue()
+	inst := mir.Instruction{
+		ID:   id,
+		Op:   "member",
+		Type: fieldType,
+		Operands: []mir.Operand{
+			valueOperand(target.ID, target.Type),
+			{Kind: mir.OperandLiteral, Literal: name},
+		},
+	}
+	fb.block.Instructions = append(fb.block.Instructions, inst)
+	return mirValue{ID: id, Type: fieldType}
+}
+
+// emitEnumTag emits the tag of the variant of enum as a constant of the
+// enum type.
+func (fb *functionBuilder) emitEnumTag(enum *mir.Enum, variant string) mirValue {
+	tag := 0
+	for i, v := range enum.Variants {
+		if v == variant {
+			tag = i
+		}
+	}
+	id := fb.fn.NextValue()
+	fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{
+		ID:       id,
+		Op:       "const",
+		Type:     enum.Name,
+		Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.Itoa(tag), Type: "int"}},
+	})
+	return mirValue{ID: id, Type: enum.Name}
+}
+
+// lowerMatchExpr lowers a match expression to a decision tree: each case
+// whose pattern can fail compares the subject with cmp.eq and branches to
+// its body or to the next case. The bodies join in a merge block, where a
+// phi selects the value of the case that ran. The checker has made sure
+// that some case matches, so falling past the last one is unreachable.
+func (fb *functionBuilder) lowerMatchExpr(expr *ast.MatchExpr) (mirValue, error) {
+	subject, err := fb.lowerExpr(expr.Subject)
+	if err != nil {
+		return mirValue{}, err
+	}
+	type arm struct {
+		end   *mir.BasicBlock
+		value mirValue
+	}
+	var arms []arm
+	for _, mc := range expr.Cases {
+		if fb.block == nil {
+			break
+		}
+		body := fb.newBlock("match_case")
+		// The cases after one that matches every value never run
+		var next *mir.BasicBlock
+		if mc.Pattern.Kind == ast.PatternLiteral || mc.Pattern.Kind == ast.PatternEnum {
+			cond, err := fb.emitPatternTest(mc.Pattern, subject)
+			if err != nil {
+				return mirValue{}, err
+			}
+			next = fb.newBlock("match_next")
+			fb.block.Terminator = mir.Terminator{Op: "cbr", Operands: []mir.Operand{
+				valueOperand(cond.ID, cond.Type), blockOperand(body), blockOperand(next),
+			}}
+		} else {
+			fb.block.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{blockOperand(body)}}
+		}
+		fb.block = body
+		value, err := fb.lowerMatchBody(mc, subject)
+		if err != nil {
+			return mirValue{}, err
+		}
+		if fb.block != nil {
+			arms = append(arms, arm{end: fb.block, value: value})
+		}
+		fb.block = next
+	}
+	if fb.block != nil {
+		fb.block.Terminator = mir.Terminator{Op: "unreachable"}
+	}
+
+	merge := fb.newBlock("match_merge")
+	resultType := "void"
+	var incoming []mir.Operand
+	for _, a := range arms {
+		a.end.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{blockOperand(merge)}}
+		if a.value.ID != mir.InvalidValue && a.value.Type != "void" {
+			if resultType == "void" {
+				resultType = a.value.Type
+			}
+			incoming = append(incoming, valueOperand(a.value.ID, a.value.Type), blockOperand(a.end))
+		}
+	}
+	fb.block = merge
+	if len(incoming) == 0 {
+		return mirValue{ID: mir.InvalidValue, Type: "void"}, nil
+	}
+	id := fb.fn.NextValue()
+	merge.Instructions = append(merge.Instructions, mir.Instruction{ID: id, Op: "phi", Type: resultType, Operands: incoming})
+	return mirValue{ID: id, Type: resultType}, nil
+}
+
+// emitPatternTest compares subject with the literal or enum variant of pat.
+func (fb *functionBuilder) emitPatternTest(pat ast.MatchPattern, subject mirValue) (mirValue, error) {
+	var want mirValue
+	if pat.Kind == ast.PatternEnum {
+		enum, ok := fb.mb.enums[pat.Enum]
+		if !ok {
+			return mirValue{}, fmt.Errorf("mir builder: unknown enum %s", pat.Enum)
+		}
+		want = fb.emitEnumTag(enum, pat.Variant)
+	} else {
+		var err error
+		if want, err = fb.lowerExpr(pat.Value); err != nil {
+			return mirValue{}, err
+		}
+	}
+	id := fb.fn.NextValue()
+	fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{
+		ID:       id,
+		Op:       "cmp.eq",
+		Type:     "bool",
+		Operands: []mir.Operand{valueOperand(subject.ID, subject.Type), valueOperand(want.ID, want.Type)},
+	})
+	return mirValue{ID: id, Type: "bool"}, nil
+}
+
+// lowerMatchBody lowers the body of mc with the bindings of a struct
+// pattern read from the fields of subject.
+func (fb *functionBuilder) lowerMatchBody(mc ast.MatchCase, subject mirValue) (mirValue, error) {
+	originalEnv := make(map[string]symbol, len(fb.env))
+	for k, v := range fb.env {
+		originalEnv[k] = v
+	}
+	defer func() { fb.env = originalEnv }()
+	for _, f := range mc.Pattern.Fields {
+		if f.Binding == "_" {
+			continue
+		}
+		fieldType := fb.mb.structFields[subject.Type][f.Field]
+		id := fb.fn.NextValue()
+		fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{
+			ID:   id,
+			Op:   "member",
+			Type: fieldType,
+			Operands: []mir.Operand{
+				valueOperand(subject.ID, subject.Type),
+				{Kind: mir.OperandLiteral, Literal: f.Field},
+			},
+		})
+		fb.env[f.Binding] = symbol{Value: id, Type: fieldType}
+	}
+	return fb.lowerExpr(mc.Body)
+}
+
 func (fb *functionBuilder) emitStructLiteral(expr *ast.StructLiteralExpr) (mirValue, error) {
 	id := fb.fn.NextValue()
 	operands := []mir.Operand{{Kind: mir.OperandLiteral, Literal: expr.TypeName}}
@@ -1474,6 +2478,68 @@ func (fb *functionBuilder) emitStructLiteral(expr *ast.StructLiteralExpr) (mirVa
 	return mirValue{ID: id, Type: expr.TypeName}, nil
 }
 
+// emitTupleLiteral builds a tuple as a struct whose fields are named by the
+// index of each element.
+func (fb *functionBuilder) emitTupleLiteral(expr *ast.TupleLiteralExpr) (mirValue, error) {
+	elemTypes := make([]string, 0, len(expr.Elements))
+	operands := []mir.Operand{{}}
+	for i, el := range expr.Elements {
+		value, err := fb.lowerExpr(el)
+		if err != nil {
+			return mirValue{}, err
+		}
+		elemTypes = append(elemTypes, value.Type)
+		operands = append(operands, mir.Operand{Kind: mir.OperandLiteral, Literal: strconv.Itoa(i)})
+		operands = append(operands, valueOperand(value.ID, value.Type))
+	}
+	typ := buildGeneric("tuple", elemTypes)
+	operands[0] = mir.Operand{Kind: mir.OperandLiteral, Literal: typ}
+	id := fb.fn.NextValue()
+	fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{ID: id, Op: "struct.init", Type: typ, Operands: operands})
+	return mirValue{ID: id, Type: typ}, nil
+}
+
+// lowerDestructureStmt binds the names of a destructuring let or var to
+// member accesses of the value: by index for the elements of a tuple, by
+// name for the fields of a struct.
+func (fb *functionBuilder) lowerDestructureStmt(stmt *ast.DestructureStmt) error {
+	value, err := fb.lowerExpr(stmt.Value)
+	if err != nil {
+		return err
+	}
+	bind := func(name, member, typ string) {
+		id := fb.fn.NextValue()
+		fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{
+			ID:   id,
+			Op:   "member",
+			Type: typ,
+			Operands: []mir.Operand{
+				valueOperand(value.ID, value.Type),
+				{Kind: mir.OperandLiteral, Literal: member},
+			},
+		})
+		fb.env[name] = symbol{Value: id, Type: typ, Mutable: stmt.Mutable}
+	}
+	if stmt.Tuple {
+		elemTypes := splitGenericArgs(strings.TrimSuffix(strings.TrimPrefix(value.Type, "tuple<"), ">"))
+		if len(elemTypes) != len(stmt.Names) {
+			return fmt.Errorf("mir builder: cannot destructure %s into %d names", value.Type, len(stmt.Names))
+		}
+		for i, name := range stmt.Names {
+			if name != "_" {
+				bind(name, strconv.Itoa(i), elemTypes[i])
+			}
+		}
+		return nil
+	}
+	for _, f := range stmt.Fields {
+		if f.Binding != "_" {
+			bind(f.Binding, f.Field, fb.mb.structFields[value.Type][f.Field])
+		}
+	}
+	return nil
+}
+
 func (fb *functionBuilder) emitArrayLiteral(expr *ast.ArrayLiteralExpr) (mirValue, error) {
 	id := fb.fn.NextValue()
 	operands := make([]mir.Operand, 0, len(expr.Elements))
@@ -1496,6 +2562,29 @@ func (fb *functionBuilder) emitArrayLiteral(expr *ast.ArrayLiteralExpr) (mirValu
 	return mirValue{ID: id, Type: inst.Type}, nil
 }
 
+// packVariadicArgs lowers the trailing arguments of a call of a variadic
+// function into the array of elemType that its variadic parameter takes.
+func (fb *functionBuilder) packVariadicArgs(args []ast.Expr, elemType string) (mirValue, error) {
+	operands := make([]mir.Operand, 0, len(args))
+	for _, arg := range args {
+		value, err := fb.lowerExpr(arg)
+		if err != nil {
+			return mirValue{}, err
+		}
+		value = fb.coerce(value, elemType)
+		operands = append(operands, valueOperand(value.ID, value.Type))
+	}
+	id := fb.fn.NextValue()
+	inst := mir.Instruction{
+		ID:       id,
+		Op:       "array.init",
+		Type:     buildGeneric("[]", []string{elemType}),
+		Operands: operands,
+	}
+	fb.block.Instructions = append(fb.block.Instructions, inst)
+	return mirValue{ID: id, Type: inst.Type}, nil
+}
+
 func (fb *functionBuilder) emitMapLiteral(expr *ast.MapLiteralExpr) (mirValue, error) {
 	id := fb.fn.NextValue()
 	operands := make([]mir.Operand, 0, len(expr.Entries)*2)
@@ -1543,6 +2632,9 @@ func (fb *functionBuilder) emitIndexAccess(expr *ast.IndexExpr) (mirValue, error
 		// Extract element type from array<T>
 		inner := target.Type[len("array<") : len(target.Type)-1]
 		elementType = strings.TrimSpace(inner)
+	} else if strings.HasPrefix(target.Type, "[]<") && strings.HasSuffix(target.Type, ">") {
+		// Array parameters, such as the variadic ones, are typed []<T>
+		elementType = strings.TrimSpace(target.Type[len("[]<") : len(target.Type)-1])
 	} else if strings.HasPrefix(target.Type, "map<") && strings.HasSuffix(target.Type, ">") {
 		// Extract value type from map<K,V>
 		inner := target.Type[len("map<") : len(target.Type)-1]
@@ -1552,6 +2644,9 @@ func (fb *functionBuilder) emitIndexAccess(expr *ast.IndexExpr) (mirValue, error
 		} else {
 			elementType = inferTypePlaceholder
 		}
+	} else if target.Type == "any" {
+		// The elements and fields of a JSON value are JSON values
+		elementType = "any"
 	} else {
 		elementType = inferTypePlaceholder
 	}
@@ -1668,6 +2763,14 @@ func typeExprToString(t *ast.TypeExpr) string {
 		return buildFunctionType(paramTypes, returnType)
 	}
 
+	// Optional types: T?, or Optional<T> written out
+	if t.IsOptional && t.OptionalType != nil {
+		return typeExprToString(t.OptionalType) + "?"
+	}
+	if t.Name == "Optional" && len(t.Args) == 1 {
+		return typeExprToString(t.Args[0]) + "?"
+	}
+
 	if len(t.Args) == 0 {
 		return t.Name
 	}
@@ -1700,14 +2803,25 @@ func buildFunctionType(paramTypes []string, returnType string) string {
 }
 
 func (fb *functionBuilder) emitLambda(lambda *ast.LambdaExpr) (mirValue, error) {
+	return fb.emitLambdaWithParams(lambda, nil)
+}
+
+// emitLambdaWithParams lowers a lambda whose parameter types are known from
+// where it is passed, e.g. the element type of the array given to
+// std.collections.filter. The lambda then also returns the type of its body.
+func (fb *functionBuilder) emitLambdaWithParams(lambda *ast.LambdaExpr, knownParams []string) (mirValue, error) {
 	// Generate a unique name for the lambda function
 	lambdaName := fmt.Sprintf("lambda_%d", fb.blocks)
 	fb.blocks++
 
 	// Determine parameter types and count from the lambda
+	typed := len(knownParams) == len(lambda.Params)
 	paramTypes := make([]string, len(lambda.Params))
 	for i := range lambda.Params {
 		paramTypes[i] = "int" // Default to int for now
+		if typed && knownParams[i] != "" && knownParams[i] != inferTypePlaceholder {
+			paramTypes[i] = knownParams[i]
+		}
 	}
 
 	// Infer return type from the lambda body
@@ -1735,6 +2849,7 @@ func (fb *functionBuilder) emitLambda(lambda *ast.LambdaExpr) (mirValue, error)
 
 	// Create the lambda function
 	lambdaFunc := mir.NewFunction(lambdaName, returnType, allParams)
+	lambdaFunc.Span = lambda.SpanInfo
 
 	// Create a new function builder for the lambda
 	lambdaBuilder := &functionBuilder{
@@ -1743,7 +2858,9 @@ func (fb *functionBuilder) emitLambda(lambda *ast.LambdaExpr) (mirValue, error)
 		env:   make(map[string]symbol),
 		sigs:  fb.sigs,
 		mb:    fb.mb,
+		file:  fb.file,
 	}
+	defer lambdaBuilder.at(lambda.SpanInfo)()
 
 	// Add lambda parameters to the environment
 	for _, param := range lambdaFunc.Params {
@@ -1774,6 +2891,10 @@ func (fb *functionBuilder) emitLambda(lambda *ast.LambdaExpr) (mirValue, error)
 		Op:       "ret",
 		Operands: []mir.Operand{valueOperand(bodyValue.ID, bodyValue.Type)},
 	}
+	if typed && bodyValue.Type != "" && bodyValue.Type != inferTypePlaceholder {
+		returnType = bodyValue.Type
+		lambdaFunc.ReturnType = returnType
+	}
 
 	// Create a closure that captures the variables
 	// The function type should include both lambda parameters and captured variables
@@ -1805,6 +2926,136 @@ func (fb *functionBuilder) emitLambda(lambda *ast.LambdaExpr) (mirValue, error)
 	return mirValue{ID: id, Type: funcType}, nil
 }
 
+// isCollectionsCallback reports whether callee is one of the std.collections
+// functions that call a function value for each array element.
+func isCollectionsCallback(callee string) bool {
+	switch callee {
+	case "std.collections.filter", "std.collections.map_fn", "std.collections.reduce":
+		return true
+	}
+	return false
+}
+
+// collectionsCallbackParams returns the parameter types of the callback
+// passed to callee, given the arguments lowered before it.
+func collectionsCallbackParams(callee string, args []mir.Operand) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	elem := arrayElementType(args[0].Type)
+	if callee == "std.collections.reduce" {
+		if len(args) < 2 {
+			return nil
+		}
+		return []string{args[1].Type, elem}
+	}
+	return []string{elem}
+}
+
+// collectionsResultType returns the result type of a call to callee: filter
+// keeps the array type, map_fn returns an array of what the callback returns
+// and reduce returns the type of the initial value.
+func collectionsResultType(callee string, args []mir.Operand) string {
+	switch {
+	case callee == "std.collections.filter" && len(args) == 2:
+		return args[0].Type
+	case callee == "std.collections.map_fn" && len(args) == 2:
+		funcType := args[1].Type
+		if arrow := strings.Index(funcType, ") -> "); arrow != -1 {
+			return "[]<" + strings.TrimSpace(funcType[arrow+len(") -> "):]) + ">"
+		}
+	case callee == "std.collections.reduce" && len(args) == 3:
+		return args[1].Type
+	}
+	return inferTypePlaceholder
+}
+
+// dequeResultType returns the result type of a call to a std.collections.deque
+// function. create leaves the element type to the binding it is assigned to,
+// see coerceInferred; the pops and peeks return elements of their deque.
+func dequeResultType(callee string, args []mir.Operand) string {
+	switch strings.TrimPrefix(callee, "std.collections.deque.") {
+	case "create":
+		return "deque<" + inferTypePlaceholder + ">"
+	case "pop_front", "pop_back", "peek_front", "peek_back":
+		if len(args) == 1 && strings.HasPrefix(args[0].Type, "deque<") && strings.HasSuffix(args[0].Type, ">") {
+			return strings.TrimSpace(args[0].Type[len("deque<") : len(args[0].Type)-1])
+		}
+	case "size":
+		return "int"
+	case "is_empty":
+		return "bool"
+	case "push_front", "push_back":
+		return "void"
+	}
+	return inferTypePlaceholder
+}
+
+// trieResultType returns the result type of a call to a std.collections.trie
+// function. create leaves the value type to the binding, like deque.create;
+// search returns an optional value of its trie.
+func trieResultType(callee string, args []mir.Operand) string {
+	switch strings.TrimPrefix(callee, "std.collections.trie.") {
+	case "create":
+		return "Trie<" + inferTypePlaceholder + ">"
+	case "search":
+		if len(args) == 2 && strings.HasPrefix(args[0].Type, "Trie<") && strings.HasSuffix(args[0].Type, ">") {
+			return strings.TrimSpace(args[0].Type[len("Trie<"):len(args[0].Type)-1]) + "?"
+		}
+	case "starts_with":
+		return "bool"
+	case "keys_with_prefix":
+		return "[]<string>"
+	case "insert":
+		return "void"
+	}
+	return inferTypePlaceholder
+}
+
+// orderedMapResultType returns the result type of a call to a
+// std.collections.ordered_map function. create leaves the key and value
+// types to the binding, like deque.create; the others take them from the map.
+func orderedMapResultType(callee string, args []mir.Operand) string {
+	op := strings.TrimPrefix(callee, "std.collections.ordered_map.")
+	switch op {
+	case "create":
+		return "OrderedMap<" + inferTypePlaceholder + "," + inferTypePlaceholder + ">"
+	case "put":
+		return "void"
+	case "remove":
+		return "bool"
+	}
+	if len(args) == 0 || !strings.HasPrefix(args[0].Type, "OrderedMap<") || !strings.HasSuffix(args[0].Type, ">") {
+		return inferTypePlaceholder
+	}
+	params := splitGenericArgs(args[0].Type[len("OrderedMap<") : len(args[0].Type)-1])
+	if len(params) != 2 {
+		return inferTypePlaceholder
+	}
+	key, value := params[0], params[1]
+	switch op {
+	case "get":
+		return value + "?"
+	case "keys":
+		return "[]<" + key + ">"
+	case "values":
+		return "[]<" + value + ">"
+	case "entries":
+		return "[]<tuple<" + key + "," + value + ">>"
+	}
+	return inferTypePlaceholder
+}
+
+// arrayElementType returns the element type of an array<T> or []<T> type.
+func arrayElementType(arrayType string) string {
+	for _, prefix := range []string{"array<", "[]<"} {
+		if strings.HasPrefix(arrayType, prefix) && strings.HasSuffix(arrayType, ">") {
+			return strings.TrimSpace(arrayType[len(prefix) : len(arrayType)-1])
+		}
+	}
+	return inferTypePlaceholder
+}
+
 // identifyCapturedVariables identifies variables from the enclosing scope that are used in the lambda body
 func (fb *functionBuilder) identifyCapturedVariables(body ast.Expr, lambdaParams []ast.Param) []string {
 	var captured []string
@@ -2020,18 +3271,6 @@ func (fb *functionBuilder) emitStringConcatenation(left, right mirValue) (mirVal
 	return mirValue{ID: id, Type: "string"}, nil
 }
 
-// lowerTryStmt handles try-catch-finally statements
-func (fb *functionBuilder) lowerTryStmt(stmt *ast.TryStmt) error {
-	// Create a new exception handling context
-	exceptionContext := &exceptionContext{
-		tryBlock:     stmt.TryBlock,
-		catchClauses: stmt.CatchClauses,
-		finallyBlock: stmt.FinallyBlock,
-	}
-
-	return fb.emitExceptionHandling(exceptionContext)
-}
-
 // lowerThrowStmt handles throw statements
 func (fb *functionBuilder) lowerThrowStmt(stmt *ast.ThrowStmt) error {
 	// Evaluate the expression to throw
@@ -2051,40 +3290,212 @@ func (fb *functionBuilder) lowerThrowStmt(stmt *ast.ThrowStmt) error {
 		},
 	}
 	fb.block.Instructions = append(fb.block.Instructions, inst)
+	// The throw does not return, so nothing follows it in the block
+	fb.block.Terminator = mir.Terminator{Op: "unreachable"}
 
 	return nil
 }
 
-// exceptionContext holds information about a try-catch-finally block
-type exceptionContext struct {
-	tryBlock     *ast.BlockStmt
-	catchClauses []*ast.CatchClause
-	finallyBlock *ast.BlockStmt
+// lowerDeferStmt lowers the call of a defer statement as usual and turns it
+// into a defer.push, which evaluates the callee and arguments now and runs
+// the call when the function returns.
+func (fb *functionBuilder) lowerDeferStmt(stmt *ast.DeferStmt) error {
+	call, ok := stmt.Call.(*ast.CallExpr)
+	if !ok {
+		return fmt.Errorf("mir builder: defer requires a function call")
+	}
+	value, err := fb.emitCall(call)
+	if err != nil {
+		return err
+	}
+	n := len(fb.block.Instructions)
+	if n == 0 || fb.block.Instructions[n-1].ID != value.ID {
+		return fmt.Errorf("mir builder: cannot defer this call")
+	}
+	inst := &fb.block.Instructions[n-1]
+	if inst.Op != "call" && inst.Op != "func.call" {
+		return fmt.Errorf("mir builder: cannot defer %s", inst.Op)
+	}
+	inst.Op = "defer.push"
+	fb.hasDefers = true
+	return nil
+}
+
+// tryContext is a try statement, or a catch clause of one with a finally
+// block, whose handler is active while its body is lowered.
+type tryContext struct {
+	finally *ast.BlockStmt
 }
 
-// emitExceptionHandling generates MIR for exception handling
-func (fb *functionBuilder) emitExceptionHandling(ctx *exceptionContext) error {
-	// For now, implement a simplified version that just executes the blocks sequentially
-	// This is a placeholder for proper exception handling implementation
+// enterTry pushes a handler for the exceptions thrown until the matching
+// exitTry. Its operands pair each exception type with the block that
+// catches it, in the order they are tried.
+func (fb *functionBuilder) enterTry(handlers []mir.Operand, finally *ast.BlockStmt) {
+	fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{ID: mir.InvalidValue, Op: "try.enter", Type: "void", Operands: handlers})
+	fb.tryStack = append(fb.tryStack, tryContext{finally: finally})
+}
+
+// exitTry pops the innermost handler at the end of its body and continues
+// at next, unless the body ended in a return, break, continue or throw. It
+// reports whether it continued.
+func (fb *functionBuilder) exitTry(next *mir.BasicBlock) bool {
+	fb.tryStack = fb.tryStack[:len(fb.tryStack)-1]
+	if fb.block == nil || fb.block.HasTerminator() {
+		return false
+	}
+	fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{ID: mir.InvalidValue, Op: "try.exit", Type: "void"})
+	fb.block.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{blockOperand(next)}}
+	return true
+}
 
-	// Execute try block
-	if err := fb.lowerBlock(ctx.tryBlock); err != nil {
+// leaveTries pops the handlers above depth for a return, break or continue
+// that jumps out of them, running their finally blocks on the way out.
+func (fb *functionBuilder) leaveTries(depth int) error {
+	active := fb.tryStack
+	defer func() { fb.tryStack = active }()
+	for i := len(active) - 1; i >= depth; i-- {
+		if fb.block == nil || fb.block.HasTerminator() {
+			return nil
+		}
+		fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{ID: mir.InvalidValue, Op: "try.exit", Type: "void"})
+		if active[i].finally != nil {
+			// A return in the finally block leaves the outer handlers
+			fb.tryStack = active[:i]
+			if err := fb.lowerBlock(active[i].finally); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lowerTryStmt lowers a try statement. The try block runs under a handler
+// that try.enter pushes, which sends a thrown exception to the first catch
+// clause whose type matches it. A finally block runs after the try block or
+// the catch clause completes; for exceptions that no clause catches, and
+// those thrown in a catch clause, the finally_rethrow block runs it and
+// throws the exception on.
+func (fb *functionBuilder) lowerTryStmt(stmt *ast.TryStmt) error {
+	tryBlock := fb.newBlock("try")
+	merge := fb.newBlock("try_merge")
+	next := merge
+	var finallyBlock, rethrowBlock *mir.BasicBlock
+	if stmt.FinallyBlock != nil {
+		finallyBlock = fb.newBlock("finally")
+		rethrowBlock = fb.newBlock("finally_rethrow")
+		next = finallyBlock
+	}
+
+	var handlers []mir.Operand
+	catchBlocks := make([]*mir.BasicBlock, len(stmt.CatchClauses))
+	catchesAll := false
+	for i, clause := range stmt.CatchClauses {
+		catchBlocks[i] = fb.newBlock("catch")
+		typ := clause.ExceptionType
+		if typ == "" {
+			typ = mir.CatchAny
+			catchesAll = true
+		}
+		handlers = append(handlers, mir.Operand{Kind: mir.OperandLiteral, Literal: typ}, blockOperand(catchBlocks[i]))
+	}
+	if rethrowBlock != nil && !catchesAll {
+		handlers = append(handlers, mir.Operand{Kind: mir.OperandLiteral, Literal: mir.CatchAny}, blockOperand(rethrowBlock))
+	}
+
+	originalEnv := make(map[string]symbol)
+	for k, v := range fb.env {
+		originalEnv[k] = v
+	}
+	restoreEnv := func() {
+		fb.env = make(map[string]symbol, len(originalEnv))
+		for k, v := range originalEnv {
+			fb.env[k] = v
+		}
+	}
+
+	fb.block.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{blockOperand(tryBlock)}}
+	fb.block = tryBlock
+	fb.enterTry(handlers, stmt.FinallyBlock)
+	if err := fb.lowerBlock(stmt.TryBlock); err != nil {
 		return err
 	}
+	// Whether the code after the statement is reached, as it is not when
+	// every part ends in a return or throw
+	reached := fb.exitTry(next)
 
-	// Execute catch blocks (simplified - no actual exception catching yet)
-	for _, catchClause := range ctx.catchClauses {
-		// For now, we'll skip catch blocks since we don't have proper exception handling
-		// In a full implementation, these would only execute when an exception is thrown
-		_ = catchClause
+	for i, clause := range stmt.CatchClauses {
+		restoreEnv()
+		fb.block = catchBlocks[i]
+		typ := clause.ExceptionType
+		operand := typ
+		if typ == "" {
+			typ, operand = "string", mir.CatchAny
+		}
+		id := fb.fn.NextValue()
+		fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{
+			ID:       id,
+			Op:       "catch.begin",
+			Type:     typ,
+			Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: operand}},
+		})
+		if clause.ExceptionVar != "" {
+			fb.env[clause.ExceptionVar] = symbol{Value: id, Type: typ}
+		}
+		if rethrowBlock == nil {
+			if err := fb.lowerBlock(clause.Block); err != nil {
+				return err
+			}
+			if fb.block != nil && !fb.block.HasTerminator() {
+				fb.block.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{blockOperand(next)}}
+				reached = true
+			}
+			continue
+		}
+		fb.enterTry([]mir.Operand{{Kind: mir.OperandLiteral, Literal: mir.CatchAny}, blockOperand(rethrowBlock)}, stmt.FinallyBlock)
+		if err := fb.lowerBlock(clause.Block); err != nil {
+			return err
+		}
+		if fb.exitTry(next) {
+			reached = true
+		}
 	}
 
-	// Execute finally block
-	if ctx.finallyBlock != nil {
-		if err := fb.lowerBlock(ctx.finallyBlock); err != nil {
+	if finallyBlock != nil {
+		restoreEnv()
+		fb.block = finallyBlock
+		// The finally block runs for the paths that reached it
+		finallyReached := reached
+		reached = false
+		if err := fb.lowerBlock(stmt.FinallyBlock); err != nil {
 			return err
 		}
+		if fb.block != nil && !fb.block.HasTerminator() {
+			fb.block.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{blockOperand(merge)}}
+			reached = finallyReached
+		}
+
+		restoreEnv()
+		fb.block = rethrowBlock
+		pending := fb.fn.NextValue()
+		fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{ID: pending, Op: "finally.begin", Type: "exception"})
+		if err := fb.lowerBlock(stmt.FinallyBlock); err != nil {
+			return err
+		}
+		if fb.block != nil && !fb.block.HasTerminator() {
+			fb.block.Instructions = append(fb.block.Instructions, mir.Instruction{
+				ID:       mir.InvalidValue,
+				Op:       "throw",
+				Type:     "void",
+				Operands: []mir.Operand{valueOperand(pending, "exception")},
+			})
+			fb.block.Terminator = mir.Terminator{Op: "unreachable"}
+		}
 	}
 
+	restoreEnv()
+	fb.block = merge
+	if !reached {
+		merge.Terminator = mir.Terminator{Op: "unreachable"}
+	}
 	return nil
 }
@@ -1,9 +1,12 @@
 package builder
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/omni-lang/omni/internal/ast"
+	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/parser"
 )
 
 func TestBuildModule(t *testing.T) {
@@ -495,6 +498,65 @@ func TestLowerBreakContinueStmt(t *testing.T) {
 	if len(result.Functions) != 1 {
 		t.Fatalf("Expected 1 function, got %d", len(result.Functions))
 	}
+
+	jumps := map[string]string{}
+	for _, block := range result.Functions[0].Blocks {
+		if op := block.Terminator.Op; op == "loop.break" || op == "loop.continue" {
+			jumps[op] = block.Terminator.Operands[0].Literal
+		}
+	}
+	if !strings.HasPrefix(jumps["loop.break"], "while_exit") {
+		t.Errorf("Expected loop.break to the loop exit, got %q", jumps["loop.break"])
+	}
+	if !strings.HasPrefix(jumps["loop.continue"], "while_header") {
+		t.Errorf("Expected loop.continue to the loop header, got %q", jumps["loop.continue"])
+	}
+}
+
+func TestLowerContinueRunsForPost(t *testing.T) {
+	src := `func main():int {
+  var sum:int = 0
+  for var i:int = 0; i < 4; i++ {
+    if i == 1 {
+      continue
+    }
+    sum = sum + i
+  }
+  return sum
+}
+`
+	mod, err := parser.Parse("continue.omni", src)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	result, err := BuildModule(mod)
+	if err != nil {
+		t.Fatalf("BuildModule failed: %v", err)
+	}
+
+	// The continue and the end of the body both reach the post statement
+	var post *mir.BasicBlock
+	jumps := 0
+	for _, block := range result.Functions[0].Blocks {
+		if strings.HasPrefix(block.Name, "loop_post") {
+			post = block
+		}
+	}
+	if post == nil {
+		t.Fatalf("Expected a loop_post block for the continue")
+	}
+	for _, block := range result.Functions[0].Blocks {
+		term := block.Terminator
+		if (term.Op == "loop.continue" || term.Op == "br") && len(term.Operands) == 1 && term.Operands[0].Literal == post.Name {
+			jumps++
+		}
+	}
+	if jumps != 2 {
+		t.Errorf("Expected 2 jumps to %s, got %d", post.Name, jumps)
+	}
+	if got := post.Terminator; got.Op != "br" || !strings.HasPrefix(got.Operands[0].Literal, "loop_header") {
+		t.Errorf("Expected %s to branch back to the header, got %v", post.Name, got)
+	}
 }
 
 func TestLowerTryStmt(t *testing.T) {
@@ -543,6 +605,27 @@ func TestLowerTryStmt(t *testing.T) {
 	if len(result.Functions) != 1 {
 		t.Fatalf("Expected 1 function, got %d", len(result.Functions))
 	}
+
+	// The try block pushes a handler for Error and one that runs the
+	// finally block for anything else; the return in the catch block pops
+	// the handler of its body before running the finally block itself, and
+	// as the finally block returns, it never rethrows
+	ops := map[string]int{}
+	for _, block := range result.Functions[0].Blocks {
+		for _, inst := range block.Instructions {
+			ops[inst.Op]++
+			if inst.Op == "try.enter" && ops[inst.Op] == 1 {
+				if len(inst.Operands) != 4 || inst.Operands[0].Literal != "Error" || inst.Operands[2].Literal != "*" {
+					t.Errorf("try.enter operands = %+v, want Error and * handlers", inst.Operands)
+				}
+			}
+		}
+	}
+	for op, want := range map[string]int{"try.enter": 2, "try.exit": 1, "catch.begin": 1, "finally.begin": 1, "throw": 1} {
+		if ops[op] != want {
+			t.Errorf("got %d %s instructions, want %d", ops[op], op, want)
+		}
+	}
 }
 
 func TestLowerIncrementStmt(t *testing.T) {
@@ -1328,3 +1411,227 @@ func TestFunctionWithExprBody(t *testing.T) {
 		t.Errorf("Expected 1 block for expr body, got %d", len(fn.Blocks))
 	}
 }
+
+func TestLowerDeferStmt(t *testing.T) {
+	src := `func log(n:int) {
+}
+
+func main():int {
+  defer log(1)
+  if true {
+    return 1
+  }
+  return 2
+}
+`
+	mod, err := parser.Parse("defer.omni", src)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	result, err := BuildModule(mod)
+	if err != nil {
+		t.Fatalf("BuildModule failed: %v", err)
+	}
+
+	var fn *mir.Function
+	for _, f := range result.Functions {
+		if f.Name == "main" {
+			fn = f
+		}
+	}
+	pushes, rets := 0, 0
+	for _, block := range fn.Blocks {
+		for _, inst := range block.Instructions {
+			if inst.Op == "defer.push" {
+				pushes++
+				if inst.Operands[0].Literal != "log" {
+					t.Errorf("defer.push callee = %q, want log", inst.Operands[0].Literal)
+				}
+			}
+		}
+		if block.Terminator.Op != "ret" {
+			continue
+		}
+		rets++
+		// Every return runs the deferred calls first
+		if !strings.HasPrefix(block.Name, "deferred") || len(block.Instructions) != 1 || block.Instructions[0].Op != "defer.run" {
+			t.Errorf("block %s returns without running the deferred calls", block.Name)
+		}
+	}
+	if pushes != 1 {
+		t.Errorf("Expected 1 defer.push, got %d", pushes)
+	}
+	if rets != 2 {
+		t.Errorf("Expected 2 returns, got %d", rets)
+	}
+}
+
+func TestLowerWhileReturnsAssignedVariable(t *testing.T) {
+	src := `func main():int {
+  var n:int = 5
+  while n > 0 {
+    if n == 3 {
+      break
+    }
+    n = n - 1
+  }
+  return n
+}
+`
+	mod, err := parser.Parse("while.omni", src)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	result, err := BuildModule(mod)
+	if err != nil {
+		t.Fatalf("BuildModule failed: %v", err)
+	}
+
+	// The return after the loop reads the variable itself, which both the
+	// break and the end of the loop leave holding its last value
+	fn := result.Functions[0]
+	n := fn.Blocks[0].Instructions[0].ID
+	for _, block := range fn.Blocks {
+		if block.Terminator.Op != "ret" {
+			continue
+		}
+		if !strings.HasPrefix(block.Name, "while_exit") {
+			t.Errorf("returned from %s, want the loop exit", block.Name)
+		}
+		if got := block.Terminator.Operands[0].Value; got != n {
+			t.Errorf("returned %%%d, want the variable %%%d", got, n)
+		}
+	}
+}
+
+func TestLowerOptionalCoercion(t *testing.T) {
+	src := `func pick(n:int):int? {
+  if n > 0 {
+    return n
+  }
+  return null
+}
+func main():int {
+  let x:int? = pick(1)
+  return opt.unwrap_or(x, 7)
+}
+`
+	mod, err := parser.Parse("optional.omni", src)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	result, err := BuildModule(mod)
+	if err != nil {
+		t.Fatalf("BuildModule failed: %v", err)
+	}
+
+	// Both returns of pick make an int?; the binding in main is one already
+	var ops []string
+	for _, fn := range result.Functions {
+		for _, block := range fn.Blocks {
+			for _, inst := range block.Instructions {
+				if strings.HasPrefix(inst.Op, "opt.") {
+					ops = append(ops, fn.Name+":"+inst.Op+":"+inst.Type)
+				}
+			}
+		}
+	}
+	want := []string{"pick:opt.some:int?", "pick:opt.none:int?", "main:opt.unwrap_or:int"}
+	if strings.Join(ops, " ") != strings.Join(want, " ") {
+		t.Errorf("opt instructions = %v, want %v", ops, want)
+	}
+}
+
+func TestLowerGlobals(t *testing.T) {
+	src := `var total:int = -3
+let name:string = "omni"
+func add(n:int) {
+  total = total + n
+}
+`
+	mod, err := parser.Parse("globals.omni", src)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	result, err := BuildModule(mod)
+	if err != nil {
+		t.Fatalf("BuildModule failed: %v", err)
+	}
+
+	want := []mir.GlobalVar{
+		{Name: "total", Type: "int", InitValue: mir.Operand{Kind: mir.OperandLiteral, Literal: "-3", Type: "int"}},
+		{Name: "name", Type: "string", InitValue: mir.Operand{Kind: mir.OperandLiteral, Literal: `"omni"`, Type: "string"}},
+	}
+	if len(result.Globals) != len(want) {
+		t.Fatalf("globals = %+v, want %+v", result.Globals, want)
+	}
+	for i := range want {
+		if result.Globals[i] != want[i] {
+			t.Errorf("global %d = %+v, want %+v", i, result.Globals[i], want[i])
+		}
+	}
+
+	var ops []string
+	for _, inst := range result.Functions[0].Blocks[0].Instructions {
+		ops = append(ops, inst.Op)
+	}
+	if got := strings.Join(ops, " "); got != "global.load add global.store" {
+		t.Errorf("instructions = %s, want global.load add global.store", got)
+	}
+}
+
+func TestLowerGlobalErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"non-literal initializer", "var x:int = 1 + 2\n", `global "x" must be initialized with a literal`},
+		{"unsupported type", "let c:char = 'a'\n", `global "c" has unsupported type`},
+		{"assign immutable", "let x:int = 1\nfunc f() {\n  x = 2\n}\n", `cannot assign to immutable variable "x"`},
+		{"increment immutable", "let x:int = 1\nfunc f() {\n  x++\n}\n", `cannot increment immutable variable "x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mod, err := parser.Parse("globals.omni", tt.src)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if _, err := BuildModule(mod); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("BuildModule error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLowerRecordsSourceLocations(t *testing.T) {
+	src := `func main():int {
+  let x:int = 1
+  return x +
+    2
+}
+`
+	mod, err := parser.Parse("loc.omni", src)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	result, err := BuildModule(mod)
+	if err != nil {
+		t.Fatalf("BuildModule failed: %v", err)
+	}
+
+	want := map[string]string{"const 1": "loc.omni:2:15", "const 2": "loc.omni:4:5", "add": "loc.omni:3:10"}
+	entry := result.Functions[0].Blocks[0]
+	for _, inst := range entry.Instructions {
+		key := inst.Op
+		if inst.Op == "const" {
+			key += " " + inst.Operands[0].Literal
+		}
+		if loc, ok := want[key]; ok && inst.Location.String() != loc {
+			t.Errorf("%s located at %s, want %s", key, inst.Location, loc)
+		}
+	}
+	if loc := entry.Terminator.Location.String(); loc != "loc.omni:3:3" {
+		t.Errorf("ret located at %s, want loc.omni:3:3", loc)
+	}
+}
@@ -0,0 +1,81 @@
+package mir
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DumpCFG renders the control-flow graph of every function in module as
+// Graphviz DOT source. Each function is a cluster whose nodes are its basic
+// blocks, labeled with the block name and instruction count; edges follow
+// the block terminators, with the branches of a cbr labeled true and false
+// and the jumps of break and continue statements labeled as such.
+func DumpCFG(module *Module) string {
+	var buf strings.Builder
+	buf.WriteString("digraph cfg {\n")
+	buf.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	if module != nil {
+		for i, fn := range module.Functions {
+			if fn == nil {
+				continue
+			}
+			writeFunctionCFG(&buf, i, fn)
+		}
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+func writeFunctionCFG(buf *strings.Builder, index int, fn *Function) {
+	// Node names are prefixed with the function so that blocks such as
+	// entry stay distinct across clusters
+	node := func(block string) string { return dotQuote(fn.Name + "." + block) }
+
+	fmt.Fprintf(buf, "  subgraph %s {\n", dotQuote(fmt.Sprintf("cluster_%d", index)))
+	fmt.Fprintf(buf, "    label=%s;\n", dotQuote(fn.Name))
+	for _, block := range fn.Blocks {
+		count := len(block.Instructions)
+		noun := "instructions"
+		if count == 1 {
+			noun = "instruction"
+		}
+		fmt.Fprintf(buf, "    %s [label=%s];\n", node(block.Name), dotQuote(fmt.Sprintf("%s\n%d %s", block.Name, count, noun)))
+	}
+	for _, block := range fn.Blocks {
+		// Exceptions reach the blocks of a handler without a terminator
+		for _, inst := range block.Instructions {
+			if inst.Op != "try.enter" {
+				continue
+			}
+			for i := 0; i+1 < len(inst.Operands); i += 2 {
+				fmt.Fprintf(buf, "    %s -> %s [label=%s, style=dashed];\n", node(block.Name), node(inst.Operands[i+1].Literal), dotQuote("catch "+inst.Operands[i].Literal))
+			}
+		}
+		term := block.Terminator
+		switch term.Op {
+		case "br", "jmp":
+			if len(term.Operands) > 0 {
+				fmt.Fprintf(buf, "    %s -> %s;\n", node(block.Name), node(term.Operands[0].Literal))
+			}
+		case "loop.break", "loop.continue":
+			if len(term.Operands) > 0 {
+				fmt.Fprintf(buf, "    %s -> %s [label=%s];\n", node(block.Name), node(term.Operands[0].Literal), dotQuote(strings.TrimPrefix(term.Op, "loop.")))
+			}
+		case "cbr":
+			if len(term.Operands) >= 3 {
+				fmt.Fprintf(buf, "    %s -> %s [label=\"true\"];\n", node(block.Name), node(term.Operands[1].Literal))
+				fmt.Fprintf(buf, "    %s -> %s [label=\"false\"];\n", node(block.Name), node(term.Operands[2].Literal))
+			}
+		}
+	}
+	buf.WriteString("  }\n")
+}
+
+// dotQuote returns s as a DOT quoted string, writing newlines as the \n
+// line break of DOT labels.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
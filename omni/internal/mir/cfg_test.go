@@ -0,0 +1,104 @@
+package mir
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var dotEdge = regexp.MustCompile(`^\s*"([^"]+)" -> "([^"]+)"(?: \[label="([^"]*)"\])?;$`)
+
+// parseDOTEdges returns the edges of DOT source as "from -> to (label)".
+func parseDOTEdges(t *testing.T, dot string) []string {
+	t.Helper()
+	var edges []string
+	for _, line := range strings.Split(dot, "\n") {
+		if !strings.Contains(line, "->") {
+			continue
+		}
+		m := dotEdge.FindStringSubmatch(line)
+		if m == nil {
+			t.Fatalf("malformed edge line %q", line)
+		}
+		edge := m[1] + " -> " + m[2]
+		if m[3] != "" {
+			edge += " (" + m[3] + ")"
+		}
+		edges = append(edges, edge)
+	}
+	sort.Strings(edges)
+	return edges
+}
+
+// ifElseFunction builds the MIR of
+//
+//	func max(a:int, b:int):int { if a > b { return a } else { return b } }
+//
+// with the branches joining in a merge block.
+func ifElseFunction() *Function {
+	fn := NewFunction("max", "int", []Param{{Name: "a", Type: "int"}, {Name: "b", Type: "int"}})
+	a, b := fn.Params[0].ID, fn.Params[1].ID
+	entry := fn.NewBlock("entry")
+	then := fn.NewBlock("then_0")
+	els := fn.NewBlock("else_1")
+	merge := fn.NewBlock("merge_2")
+
+	cond := fn.NextValue()
+	entry.Instructions = []Instruction{{ID: cond, Op: "cmp.gt", Type: "bool", Operands: []Operand{
+		{Kind: OperandValue, Value: a, Type: "int"},
+		{Kind: OperandValue, Value: b, Type: "int"},
+	}}}
+	entry.Terminator = Terminator{Op: "cbr", Operands: []Operand{
+		{Kind: OperandValue, Value: cond, Type: "bool"},
+		{Kind: OperandLiteral, Literal: "then_0"},
+		{Kind: OperandLiteral, Literal: "else_1"},
+	}}
+	then.Terminator = Terminator{Op: "br", Operands: []Operand{{Kind: OperandLiteral, Literal: "merge_2"}}}
+	els.Terminator = Terminator{Op: "br", Operands: []Operand{{Kind: OperandLiteral, Literal: "merge_2"}}}
+	result := fn.NextValue()
+	merge.Instructions = []Instruction{{ID: result, Op: "phi", Type: "int", Operands: []Operand{
+		{Kind: OperandValue, Value: a, Type: "int"},
+		{Kind: OperandLiteral, Literal: "then_0"},
+		{Kind: OperandValue, Value: b, Type: "int"},
+		{Kind: OperandLiteral, Literal: "else_1"},
+	}}}
+	merge.Terminator = Terminator{Op: "ret", Operands: []Operand{{Kind: OperandValue, Value: result, Type: "int"}}}
+	return fn
+}
+
+func TestDumpCFGIfElseEdges(t *testing.T) {
+	dot := DumpCFG(&Module{Functions: []*Function{ifElseFunction()}})
+
+	if !strings.HasPrefix(dot, "digraph cfg {\n") || !strings.HasSuffix(dot, "}\n") {
+		t.Fatalf("expected a digraph, got:\n%s", dot)
+	}
+	want := []string{
+		"max.else_1 -> max.merge_2",
+		"max.entry -> max.else_1 (false)",
+		"max.entry -> max.then_0 (true)",
+		"max.then_0 -> max.merge_2",
+	}
+	got := parseDOTEdges(t, dot)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("edges:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	for _, label := range []string{`"entry\n1 instruction"`, `"then_0\n0 instructions"`, `"merge_2\n1 instruction"`} {
+		if !strings.Contains(dot, label) {
+			t.Errorf("expected node label %s in:\n%s", label, dot)
+		}
+	}
+}
+
+func TestDumpCFGSeparatesFunctions(t *testing.T) {
+	other := NewFunction("other", "void", nil)
+	other.NewBlock("entry").Terminator = Terminator{Op: "ret"}
+	dot := DumpCFG(&Module{Functions: []*Function{ifElseFunction(), other}})
+
+	for _, want := range []string{`subgraph "cluster_0"`, `label="max";`, `subgraph "cluster_1"`, `label="other";`, `"other.entry" [label=`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("expected %s in:\n%s", want, dot)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package mir
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/omni-lang/omni/internal/lexer"
+)
 
 // ValueID uniquely identifies an SSA value produced within a function.
 type ValueID int
@@ -8,6 +12,10 @@ type ValueID int
 // InvalidValue is used when an instruction does not define a result.
 const InvalidValue ValueID = -1
 
+// CatchAny is the type operand of a try.enter handler that catches every
+// exception.
+const CatchAny = "*"
+
 // String renders the SSA value name (e.g. %0).
 func (v ValueID) String() string {
 	if v == InvalidValue {
@@ -18,7 +26,73 @@ func (v ValueID) String() string {
 
 // Module contains the MIR for an OmniLang compilation unit.
 type Module struct {
-	Functions []*Function
+	Functions  []*Function
+	Interfaces []*Interface
+	Enums      []*Enum
+	Globals    []GlobalVar
+}
+
+// GlobalVar is a variable declared at the top level of a module. It holds
+// InitValue, a literal, until the program stores another value in it, and
+// functions read and write it with the global.load and global.store
+// instructions.
+type GlobalVar struct {
+	Name      string
+	Type      string
+	InitValue Operand
+}
+
+// Global returns the global variable called name, if the module has one.
+func (m *Module) Global(name string) (GlobalVar, bool) {
+	for _, global := range m.Globals {
+		if global.Name == name {
+			return global, true
+		}
+	}
+	return GlobalVar{}, false
+}
+
+// Interface describes an interface type. Its values pair a struct with a
+// vtable that holds the function implementing each method, in the order
+// of Methods.
+type Interface struct {
+	Name    string
+	Methods []InterfaceMethod
+}
+
+// InterfaceMethod is a method of an interface; Params leaves out the
+// receiver.
+type InterfaceMethod struct {
+	Name   string
+	Params []string
+	Return string
+}
+
+// Interface returns the interface type called name, if the module has one.
+func (m *Module) Interface(name string) (*Interface, bool) {
+	for _, iface := range m.Interfaces {
+		if iface.Name == name {
+			return iface, true
+		}
+	}
+	return nil, false
+}
+
+// Enum describes an enum type. Its values are int tags, the index of the
+// variant in Variants.
+type Enum struct {
+	Name     string
+	Variants []string
+}
+
+// Enum returns the enum type called name, if the module has one.
+func (m *Module) Enum(name string) (*Enum, bool) {
+	for _, enum := range m.Enums {
+		if enum.Name == name {
+			return enum, true
+		}
+	}
+	return nil, false
 }
 
 // Function represents a lowered function in SSA form.
@@ -27,6 +101,12 @@ type Function struct {
 	ReturnType string
 	Params     []Param
 	Blocks     []*BasicBlock
+	// Span locates the declaration in the source; it is zero for
+	// functions without one, such as those built by hand.
+	Span lexer.Span
+	// Metadata holds the analyses that passes attach to the function for
+	// the passes after them, keyed by analysis. It is not serialized.
+	Metadata map[string]any
 
 	nextValue ValueID
 }
@@ -36,6 +116,9 @@ type Param struct {
 	Name string
 	Type string
 	ID   ValueID
+	// Variadic marks the last parameter of a variadic function, whose
+	// callers pack the trailing arguments into an array of its type.
+	Variadic bool
 }
 
 // BasicBlock groups a sequence of instructions terminated by a control-flow edge.
@@ -51,12 +134,49 @@ type Instruction struct {
 	Op       string
 	Type     string
 	Operands []Operand
+	// Location is the source position the instruction was lowered from.
+	Location SourceLocation
+}
+
+// CallTarget returns the callee of inst if it is a call of a function
+// named by a literal operand.
+func (inst Instruction) CallTarget() (string, bool) {
+	switch inst.Op {
+	case "call", "call.int", "call.void", "call.string", "call.bool":
+		if len(inst.Operands) > 0 && inst.Operands[0].Kind == OperandLiteral {
+			return inst.Operands[0].Literal, true
+		}
+	}
+	return "", false
 }
 
 // Terminator marks the end of a basic block.
 type Terminator struct {
 	Op       string
 	Operands []Operand
+	Location SourceLocation
+}
+
+// SourceLocation is a position in an OmniLang source file. The zero value
+// means the position is unknown.
+type SourceLocation struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// IsZero reports whether the location is unknown.
+func (l SourceLocation) IsZero() bool {
+	return l.Line == 0
+}
+
+// String formats the location as file:line:column, leaving out the file
+// when it is not known.
+func (l SourceLocation) String() string {
+	if l.File == "" {
+		return fmt.Sprintf("%d:%d", l.Line, l.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", l.File, l.Line, l.Column)
 }
 
 // OperandKind distinguishes literal and SSA value operands.
@@ -102,6 +222,24 @@ func (f *Function) NextValue() ValueID {
 	return id
 }
 
+// SyncNextValue moves the value counter past every value the function
+// defines, for functions assembled without NewFunction and NextValue, such
+// as those decoded from JSON.
+func (f *Function) SyncNextValue() {
+	for _, param := range f.Params {
+		if param.ID >= f.nextValue {
+			f.nextValue = param.ID + 1
+		}
+	}
+	for _, block := range f.Blocks {
+		for _, inst := range block.Instructions {
+			if inst.ID >= f.nextValue {
+				f.nextValue = inst.ID + 1
+			}
+		}
+	}
+}
+
 // HasTerminator reports whether the basic block already has a terminator.
 func (b *BasicBlock) HasTerminator() bool {
 	return b.Terminator.Op != ""
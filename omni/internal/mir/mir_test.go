@@ -172,6 +172,27 @@ func TestInstruction(t *testing.T) {
 	}
 }
 
+func TestInstructionCallTarget(t *testing.T) {
+	callee := Operand{Kind: OperandLiteral, Literal: "f"}
+	tests := []struct {
+		inst Instruction
+		want string
+		ok   bool
+	}{
+		{Instruction{Op: "call", Operands: []Operand{callee}}, "f", true},
+		{Instruction{Op: "call.void", Operands: []Operand{callee}}, "f", true},
+		{Instruction{Op: "func.call", Operands: []Operand{{Kind: OperandValue, Value: 0}}}, "", false},
+		{Instruction{Op: "add", Operands: []Operand{callee}}, "", false},
+		{Instruction{Op: "call"}, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.inst.CallTarget()
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s.CallTarget() = %q, %v, want %q, %v", tt.inst.Op, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
 func TestOperand(t *testing.T) {
 	operand := Operand{
 		Kind:    OperandLiteral,
@@ -11,6 +11,12 @@ import (
 // Format renders the MIR module as a deterministic textual representation.
 func Format(mod *mir.Module) string {
 	var buf bytes.Buffer
+	for _, global := range mod.Globals {
+		buf.WriteString(fmt.Sprintf("global %s:%s = %s\n", global.Name, global.Type, global.InitValue.Literal))
+	}
+	if len(mod.Globals) > 0 && len(mod.Functions) > 0 {
+		buf.WriteByte('\n')
+	}
 	for i, fn := range mod.Functions {
 		if i > 0 {
 			buf.WriteByte('\n')
@@ -0,0 +1,57 @@
+package moduleloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ImportMap redirects imports to replacement source files, keyed by the
+// dotted import path (e.g. "std.network.http" -> "/path/to/mock_http.omni").
+// Like a browser import map, it lets a program run against stub modules
+// without editing its imports.
+type ImportMap map[string]string
+
+// LoadImportMap reads an import map from a JSON object file. Relative
+// replacement paths are resolved against the directory of the map file.
+func LoadImportMap(path string) (ImportMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read import map: %w", err)
+	}
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse import map %s: %w", path, err)
+	}
+
+	baseDir := filepath.Dir(path)
+	m := make(ImportMap, len(raw))
+	for module, target := range raw {
+		if strings.TrimSpace(module) == "" || strings.TrimSpace(target) == "" {
+			return nil, fmt.Errorf("import map %s: entries need a module path and a replacement file", path)
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(baseDir, target)
+		}
+		m[module] = target
+	}
+	return m, nil
+}
+
+// Modules returns the redirected import paths in sorted order.
+func (m ImportMap) Modules() []string {
+	modules := make([]string, 0, len(m))
+	for module := range m {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// SetImportMap makes the loader consult m before the search paths.
+func (ml *ModuleLoader) SetImportMap(m ImportMap) {
+	ml.importMap = m
+}
@@ -0,0 +1,62 @@
+package moduleloader
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadImportMap(t *testing.T) {
+	dir := t.TempDir()
+	mapPath := filepath.Join(dir, "imports.json")
+	contents := `{"std.network.http": "mocks/http.omni", "utils": "/abs/utils.omni"}`
+	if err := os.WriteFile(mapPath, []byte(contents), 0o644); err != nil {
+		t.Fatalf("write import map: %v", err)
+	}
+
+	m, err := LoadImportMap(mapPath)
+	if err != nil {
+		t.Fatalf("LoadImportMap: %v", err)
+	}
+	want := ImportMap{
+		"std.network.http": filepath.Join(dir, "mocks", "http.omni"),
+		"utils":            "/abs/utils.omni",
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("import map = %v, want %v", m, want)
+	}
+	if got := m.Modules(); !reflect.DeepEqual(got, []string{"std.network.http", "utils"}) {
+		t.Errorf("Modules() = %v", got)
+	}
+
+	for name, bad := range map[string]string{
+		"not an object": `["std.io"]`,
+		"empty target":  `{"std.io": ""}`,
+	} {
+		if err := os.WriteFile(mapPath, []byte(bad), 0o644); err != nil {
+			t.Fatalf("write import map: %v", err)
+		}
+		if _, err := LoadImportMap(mapPath); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadModuleUsesImportMap(t *testing.T) {
+	dir := t.TempDir()
+	mockPath := filepath.Join(dir, "mock_http.omni")
+	if err := os.WriteFile(mockPath, []byte("func get(url:string):int {\n    return 200\n}\n"), 0o644); err != nil {
+		t.Fatalf("write mock: %v", err)
+	}
+
+	loader := NewModuleLoader()
+	loader.SetImportMap(ImportMap{"std.network.http": mockPath})
+	mod, err := loader.LoadModule([]string{"std", "network", "http"})
+	if err != nil {
+		t.Fatalf("LoadModule: %v", err)
+	}
+	if len(mod.Decls) != 1 {
+		t.Fatalf("expected the mock's single declaration, got %d", len(mod.Decls))
+	}
+}
@@ -17,6 +17,10 @@ type ModuleLoader struct {
 	cache map[string]*ast.Module
 	// Search paths for finding modules
 	searchPaths []string
+	// Imports redirected to replacement files, consulted before searchPaths
+	importMap ImportMap
+	// Absolute paths of the module files read so far, in load order
+	loaded []string
 }
 
 // NewModuleLoader creates a new module loader with improved search paths.
@@ -116,10 +120,14 @@ func (ml *ModuleLoader) LoadModule(importPath []string) (*ast.Module, error) {
 		return module, nil
 	}
 
-	// Try to find the module file
-	modulePath, err := ml.findModuleFile(importPath)
-	if err != nil {
-		return nil, err
+	// Try to find the module file, preferring an import map redirect
+	modulePath, redirected := ml.importMap[pathKey]
+	if !redirected {
+		var err error
+		modulePath, err = ml.findModuleFile(importPath)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Read and parse the file
@@ -127,6 +135,7 @@ func (ml *ModuleLoader) LoadModule(importPath []string) (*ast.Module, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read module %s: %w", pathKey, err)
 	}
+	ml.recordLoaded(modulePath)
 
 	module, err := parser.Parse(modulePath, string(content))
 	if err != nil {
@@ -138,6 +147,26 @@ func (ml *ModuleLoader) LoadModule(importPath []string) (*ast.Module, error) {
 	return module, nil
 }
 
+// recordLoaded notes that path was read. Files are recorded before parsing so
+// that a module with a syntax error is still reported by LoadedFiles.
+func (ml *ModuleLoader) recordLoaded(path string) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	for _, existing := range ml.loaded {
+		if existing == path {
+			return
+		}
+	}
+	ml.loaded = append(ml.loaded, path)
+}
+
+// LoadedFiles returns the absolute paths of every module file read by
+// LoadModule, in the order they were first loaded.
+func (ml *ModuleLoader) LoadedFiles() []string {
+	return append([]string(nil), ml.loaded...)
+}
+
 // findModuleFile searches for a module file in the search paths.
 func (ml *ModuleLoader) findModuleFile(importPath []string) (string, error) {
 	// Convert import path to file path
@@ -150,6 +179,10 @@ func (ml *ModuleLoader) findModuleFile(importPath []string) (string, error) {
 		if len(importPath) == 1 {
 			// For "import std", look for std/std.omni
 			fileName = filepath.Join("std", "std") + ".omni"
+		} else if len(importPath) > 2 {
+			// Nested std modules map directly onto the directory layout
+			// std.io.diff -> std/io/diff.omni
+			fileName = filepath.Join(importPath...) + ".omni"
 		} else {
 			// For std modules, use the subdirectory structure
 			// std.io -> std/io/print.omni
@@ -204,6 +237,9 @@ func (ml *ModuleLoader) DebugInfo() string {
 	var info []string
 	info = append(info, "ModuleLoader Debug Info:")
 	info = append(info, fmt.Sprintf("  Search paths: %v", ml.searchPaths))
+	for _, module := range ml.importMap.Modules() {
+		info = append(info, fmt.Sprintf("  Import map: %s -> %s", module, ml.importMap[module]))
+	}
 
 	// Show environment variable status
 	if stdPath := os.Getenv("OMNI_STD_PATH"); stdPath != "" {
@@ -0,0 +1,68 @@
+package packaging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ChecksumsFileName is the name of the checksum list written next to
+// release archives.
+const ChecksumsFileName = "CHECKSUMS.txt"
+
+// SHA256File returns the lowercase hex SHA-256 digest of the file at path.
+func SHA256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// WriteChecksumsFile writes CHECKSUMS.txt in dir with the SHA-256 digest of
+// every package archive in that directory. The file uses the sha256sum
+// format ("<hex>  <filename>"), so it can be verified with `sha256sum -c`.
+func WriteChecksumsFile(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read package directory: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !isPackageArchive(entry.Name()) {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		sum, err := SHA256File(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("compute checksum: %w", err)
+		}
+		fmt.Fprintf(&b, "%s  %s\n", sum, name)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ChecksumsFileName), []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("write checksums: %w", err)
+	}
+	return nil
+}
+
+func isPackageArchive(name string) bool {
+	return strings.HasSuffix(name, "."+string(PackageTypeTarGz)) || strings.HasSuffix(name, "."+string(PackageTypeZip))
+}
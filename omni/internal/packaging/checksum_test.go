@@ -0,0 +1,53 @@
+package packaging
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteChecksumsFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"omni-lang-1.0.0-linux-amd64.tar.gz": "tarball contents",
+		"omni-lang-1.0.0-linux-amd64.zip":    "zip contents",
+		"notes.txt":                          "not an archive",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	if err := WriteChecksumsFile(dir); err != nil {
+		t.Fatalf("WriteChecksumsFile failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ChecksumsFileName))
+	if err != nil {
+		t.Fatalf("read checksums: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 checksum lines, got %d:\n%s", len(lines), data)
+	}
+	want, err := SHA256File(filepath.Join(dir, "omni-lang-1.0.0-linux-amd64.tar.gz"))
+	if err != nil {
+		t.Fatalf("SHA256File failed: %v", err)
+	}
+	if lines[0] != want+"  omni-lang-1.0.0-linux-amd64.tar.gz" {
+		t.Errorf("unexpected first line %q", lines[0])
+	}
+
+	sha256sum, err := exec.LookPath("sha256sum")
+	if err != nil {
+		t.Skip("sha256sum not available")
+	}
+	cmd := exec.Command(sha256sum, "-c", ChecksumsFileName)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("sha256sum -c failed: %v\n%s", err, out)
+	}
+}
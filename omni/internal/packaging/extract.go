@@ -0,0 +1,106 @@
+package packaging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ExtractPackage installs the files of the .tar.gz or .zip package at
+// archivePath below destDir. The top-level directory of the package is
+// dropped, so omni-lang-1.0.0/bin/omnic lands in destDir/bin/omnic, and
+// file permissions are kept. Entries whose path is absolute or climbs out
+// with ".." are refused. If the package holds a release.json, the extracted
+// files are checked against it and a file that does not match it is an
+// error.
+func ExtractPackage(archivePath, destDir string) error {
+	var (
+		files    []archiveFile
+		embedded []byte
+	)
+	err := walkArchive(archivePath, func(name string, mode os.FileMode, r io.Reader) error {
+		rel, err := extractPath(name)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(destDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+
+		hasher := sha256.New()
+		var manifest strings.Builder
+		writers := []io.Writer{hasher}
+		if path.Base(name) == ReleaseManifestName {
+			writers = append(writers, &manifest)
+		}
+		size, err := writeExtractedFile(target, mode, io.TeeReader(r, io.MultiWriter(writers...)))
+		if err != nil {
+			return err
+		}
+		if path.Base(name) == ReleaseManifestName {
+			embedded = []byte(manifest.String())
+			return nil
+		}
+		files = append(files, archiveFile{name: name, size: size, sum: hex.EncodeToString(hasher.Sum(nil))})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if embedded == nil {
+		return nil
+	}
+
+	manifest, err := parseReleaseManifest(embedded)
+	if err != nil {
+		return err
+	}
+	for _, result := range verifyFiles(files, manifest) {
+		if !result.Passed(false) {
+			return fmt.Errorf("verify %s: %s does not match release.json (%s)", archivePath, result.Name, result.Status)
+		}
+	}
+	return nil
+}
+
+// extractPath returns the path an archive entry is extracted to, relative
+// to the destination directory.
+func extractPath(name string) (string, error) {
+	if path.IsAbs(name) || strings.HasPrefix(name, `\`) || filepath.IsAbs(name) {
+		return "", fmt.Errorf("refusing to extract absolute path %q", name)
+	}
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if part == ".." {
+			return "", fmt.Errorf("refusing to extract path %q outside the destination", name)
+		}
+	}
+	rel := name
+	if stripped := stripTopLevel(name); stripped != "" {
+		rel = stripped
+	}
+	return path.Clean(rel), nil
+}
+
+// writeExtractedFile writes the contents of r to target with the permission
+// bits of mode and returns the number of bytes written.
+func writeExtractedFile(target string, mode os.FileMode, r io.Reader) (int64, error) {
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm())
+	if err != nil {
+		return 0, err
+	}
+	size, err := io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return size, err
+	}
+	// OpenFile applies the umask, which may clear bits the package sets
+	return size, os.Chmod(target, mode.Perm())
+}
@@ -0,0 +1,77 @@
+package packaging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractPackage(t *testing.T) {
+	for _, ext := range []string{".tar.gz", ".zip"} {
+		t.Run(ext, func(t *testing.T) {
+			dir := t.TempDir()
+			archive := filepath.Join(dir, "omni"+ext)
+			writeTestArchive(t, archive, testPackageFiles)
+			dest := filepath.Join(dir, "install")
+
+			if err := ExtractPackage(archive, dest); err != nil {
+				t.Fatalf("ExtractPackage: %v", err)
+			}
+			data, err := os.ReadFile(filepath.Join(dest, "bin", "omnic"))
+			if err != nil {
+				t.Fatalf("read extracted compiler: %v", err)
+			}
+			if string(data) != "compiler" {
+				t.Errorf("bin/omnic = %q, want %q", data, "compiler")
+			}
+			for name, perm := range map[string]os.FileMode{"bin/omnic": 0o755, "std/io.omni": 0o644} {
+				info, err := os.Stat(filepath.Join(dest, filepath.FromSlash(name)))
+				if err != nil {
+					t.Fatalf("stat %s: %v", name, err)
+				}
+				if info.Mode().Perm() != perm {
+					t.Errorf("%s mode = %v, want %v", name, info.Mode().Perm(), perm)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractPackageRefusesTraversal(t *testing.T) {
+	for _, name := range []string{"omni-lang-1.0.0/../../evil", "/etc/evil"} {
+		dir := t.TempDir()
+		archive := filepath.Join(dir, "evil.tar.gz")
+		writeTestArchive(t, archive, []archiveEntry{{name: name, body: "x", mode: 0o644}})
+		dest := filepath.Join(dir, "install")
+
+		err := ExtractPackage(archive, dest)
+		if err == nil || !strings.Contains(err.Error(), "refusing to extract") {
+			t.Errorf("ExtractPackage(%q) error = %v, want a refusal", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "evil")); err == nil {
+			t.Errorf("%q was written outside the destination", name)
+		}
+	}
+}
+
+func TestExtractPackageVerifiesEmbeddedManifest(t *testing.T) {
+	dir := t.TempDir()
+	good := testManifest(t, map[string]string{"bin/omnic": "compiler", "std/io.omni": "func println() {}\n"})
+	archive := filepath.Join(dir, "good.zip")
+	writeTestArchive(t, archive, append(testPackageFiles, archiveEntry{name: "omni-lang-1.0.0/release.json", body: good, mode: 0o644}))
+	if err := ExtractPackage(archive, filepath.Join(dir, "good")); err != nil {
+		t.Fatalf("ExtractPackage: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "good", ReleaseManifestName)); err != nil {
+		t.Errorf("release.json not extracted: %v", err)
+	}
+
+	bad := testManifest(t, map[string]string{"bin/omnic": "other compiler"})
+	archive = filepath.Join(dir, "bad.tar.gz")
+	writeTestArchive(t, archive, append(testPackageFiles, archiveEntry{name: "omni-lang-1.0.0/release.json", body: bad, mode: 0o644}))
+	err := ExtractPackage(archive, filepath.Join(dir, "bad"))
+	if err == nil || !strings.Contains(err.Error(), "omni-lang-1.0.0/bin/omnic does not match release.json (mismatch)") {
+		t.Fatalf("ExtractPackage error = %v, want a checksum mismatch", err)
+	}
+}
@@ -4,13 +4,12 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"compress/gzip"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // PackageType represents the type of distribution package
@@ -43,6 +42,31 @@ type PackageConfig struct {
 	ManifestPath string
 	Checksum     bool
 	ChecksumPath string
+	// BinDir is the directory holding the compiled omnic and omnir binaries.
+	// If empty, they are read from bin/ under the current working directory.
+	BinDir string
+	// ExtraFiles lists additional files or directories (README, LICENSE,
+	// example scripts, ...) to include in the archive.
+	ExtraFiles []string
+}
+
+// binaryPath returns the path of the named binary to package.
+func (c PackageConfig) binaryPath(name string) string {
+	if c.BinDir == "" {
+		return filepath.Join("bin", name)
+	}
+	return filepath.Join(c.BinDir, name)
+}
+
+// extraArchivePath returns the path of an extra file inside the archive,
+// relative to the package root. Relative paths are kept as given; absolute
+// paths and paths outside the working directory are placed at the root.
+func extraArchivePath(path string) string {
+	clean := filepath.Clean(path)
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		clean = filepath.Base(clean)
+	}
+	return filepath.ToSlash(clean)
 }
 
 // CreatePackage creates a distribution package with the runtime and compiler
@@ -145,19 +169,12 @@ func writeManifest(path string, entries []PackageEntry) error {
 }
 
 func writeChecksum(path, packagePath string) error {
-	file, err := os.Open(packagePath)
+	sum, err := SHA256File(packagePath)
 	if err != nil {
-		return fmt.Errorf("open package for checksum: %w", err)
-	}
-	defer file.Close()
-
-	hasher := sha256.New()
-	if _, err := io.Copy(hasher, file); err != nil {
 		return fmt.Errorf("compute checksum: %w", err)
 	}
 
-	sum := hasher.Sum(nil)
-	if err := os.WriteFile(path, []byte(fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum), filepath.Base(packagePath))), 0o644); err != nil {
+	if err := os.WriteFile(path, []byte(fmt.Sprintf("%s  %s\n", sum, filepath.Base(packagePath))), 0o644); err != nil {
 		return fmt.Errorf("write checksum: %w", err)
 	}
 	return nil
@@ -219,13 +236,13 @@ func addFilesToTar(tarWriter *tar.Writer, config PackageConfig, record recorderF
 	}
 
 	// Add compiler binary
-	compilerPath := "bin/omnic"
+	compilerPath := config.binaryPath("omnic")
 	if err := addFileToTar(tarWriter, compilerPath, "omni-lang-"+config.Version+"/bin/omnic", record); err != nil {
 		return err
 	}
 
 	// Add runner binary if it exists
-	runnerPath := "bin/omnir"
+	runnerPath := config.binaryPath("omnir")
 	if _, err := os.Stat(runnerPath); err == nil {
 		if err := addFileToTar(tarWriter, runnerPath, "omni-lang-"+config.Version+"/bin/omnir", record); err != nil {
 			return err
@@ -260,6 +277,13 @@ func addFilesToTar(tarWriter *tar.Writer, config PackageConfig, record recorderF
 		}
 	}
 
+	// Add extra files requested by the caller
+	for _, extra := range config.ExtraFiles {
+		if err := addFileOrDirToTar(tarWriter, extra, "omni-lang-"+config.Version+"/"+extraArchivePath(extra), record); err != nil {
+			return fmt.Errorf("add extra file %s: %w", extra, err)
+		}
+	}
+
 	// Add debug symbols if requested
 	if config.IncludeDebug {
 		debugDir := "debug"
@@ -302,13 +326,13 @@ func addFilesToZip(zipWriter *zip.Writer, config PackageConfig, record recorderF
 	}
 
 	// Add compiler binary
-	compilerPath := "bin/omnic"
+	compilerPath := config.binaryPath("omnic")
 	if err := addFileToZip(zipWriter, compilerPath, "omni-lang-"+config.Version+"/bin/omnic", record); err != nil {
 		return err
 	}
 
 	// Add runner binary if it exists
-	runnerPath := "bin/omnir"
+	runnerPath := config.binaryPath("omnir")
 	if _, err := os.Stat(runnerPath); err == nil {
 		if err := addFileToZip(zipWriter, runnerPath, "omni-lang-"+config.Version+"/bin/omnir", record); err != nil {
 			return err
@@ -343,6 +367,13 @@ func addFilesToZip(zipWriter *zip.Writer, config PackageConfig, record recorderF
 		}
 	}
 
+	// Add extra files requested by the caller
+	for _, extra := range config.ExtraFiles {
+		if err := addFileOrDirToZip(zipWriter, extra, "omni-lang-"+config.Version+"/"+extraArchivePath(extra), record); err != nil {
+			return fmt.Errorf("add extra file %s: %w", extra, err)
+		}
+	}
+
 	return nil
 }
 
@@ -492,6 +523,19 @@ func addFileOrDirToTar(tarWriter *tar.Writer, path, archivePath string, record r
 	return addFileToTar(tarWriter, path, archivePath, record)
 }
 
+// addFileOrDirToZip adds a file or directory to a zip archive
+func addFileOrDirToZip(zipWriter *zip.Writer, path, archivePath string, record recorderFunc) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return addDirectoryToZip(zipWriter, path, archivePath, record)
+	}
+	return addFileToZip(zipWriter, path, archivePath, record)
+}
+
 // GetDefaultPackageName generates a default package name based on platform and architecture
 func GetDefaultPackageName(version, platform, arch string, packageType PackageType) string {
 	return fmt.Sprintf("omni-lang-%s-%s-%s.%s", version, platform, arch, packageType)
@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"compress/gzip"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -807,3 +808,157 @@ func TestCreatePackageOutputDirectory(t *testing.T) {
 	// Check if directory exists (it should be created before the dry-run check)
 	_ = outputDir
 }
+
+func TestCreatePackageWithBinDirAndExtraFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"runtime/omni_rt.c":      "rt",
+		"runtime/omni_rt.h":      "rt",
+		"std/io.omni":            "std",
+		"examples/hello.omni":    "example",
+		"build/out/omnic":        "compiler",
+		"build/out/omnir":        "runner",
+		"NOTICE":                 "notice",
+		"scripts/run.sh":         "#!/bin/sh",
+		"scripts/nested/demo.sh": "#!/bin/sh",
+	}
+	for name, contents := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	outside := filepath.Join(t.TempDir(), "EXTRA.md")
+	if err := os.WriteFile(outside, []byte("extra"), 0o644); err != nil {
+		t.Fatalf("write extra: %v", err)
+	}
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	want := []string{
+		"omni-lang-1.0.0/bin/omnic",
+		"omni-lang-1.0.0/bin/omnir",
+		"omni-lang-1.0.0/runtime/omni_rt.c",
+		"omni-lang-1.0.0/std/io.omni",
+		"omni-lang-1.0.0/examples/hello.omni",
+		"omni-lang-1.0.0/NOTICE",
+		"omni-lang-1.0.0/scripts/run.sh",
+		"omni-lang-1.0.0/scripts/nested/demo.sh",
+		"omni-lang-1.0.0/EXTRA.md",
+	}
+
+	for _, pkgType := range []PackageType{PackageTypeTarGz, PackageTypeZip} {
+		t.Run(string(pkgType), func(t *testing.T) {
+			config := PackageConfig{
+				OutputPath:  filepath.Join(tmpDir, "dist", "omni."+string(pkgType)),
+				PackageType: pkgType,
+				Version:     "1.0.0",
+				BinDir:      filepath.Join("build", "out"),
+				ExtraFiles:  []string{"NOTICE", "scripts", outside},
+			}
+			if err := CreatePackage(config); err != nil {
+				t.Fatalf("CreatePackage failed: %v", err)
+			}
+
+			contents := readArchive(t, config.OutputPath, pkgType)
+			for _, name := range want {
+				if _, ok := contents[name]; !ok {
+					t.Errorf("archive missing %s", name)
+				}
+			}
+			if got := contents["omni-lang-1.0.0/bin/omnic"]; got != "compiler" {
+				t.Errorf("bin/omnic = %q, want contents from BinDir", got)
+			}
+		})
+	}
+}
+
+func TestCreatePackageMissingExtraFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"runtime/omni_rt.c", "runtime/omni_rt.h", "bin/omnic", "std/io.omni", "examples/hello.omni"} {
+		path := filepath.Join(tmpDir, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		os.WriteFile(path, []byte("test"), 0o644)
+	}
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	config := PackageConfig{
+		OutputPath:  filepath.Join(tmpDir, "test.tar.gz"),
+		PackageType: PackageTypeTarGz,
+		Version:     "1.0.0",
+		DryRun:      true,
+		ExtraFiles:  []string{"MISSING.md"},
+	}
+	err := CreatePackage(config)
+	if err == nil || !strings.Contains(err.Error(), "MISSING.md") {
+		t.Errorf("expected missing extra file error, got %v", err)
+	}
+}
+
+// readArchive returns the regular files of a package keyed by archive path.
+func readArchive(t *testing.T, path string, pkgType PackageType) map[string]string {
+	t.Helper()
+	contents := make(map[string]string)
+
+	switch pkgType {
+	case PackageTypeTarGz:
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("open package: %v", err)
+		}
+		defer file.Close()
+		gzReader, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("gzip reader: %v", err)
+		}
+		tarReader := tar.NewReader(gzReader)
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("read tar: %v", err)
+			}
+			if header.Typeflag == tar.TypeDir {
+				continue
+			}
+			data, err := io.ReadAll(tarReader)
+			if err != nil {
+				t.Fatalf("read %s: %v", header.Name, err)
+			}
+			contents[header.Name] = string(data)
+		}
+	case PackageTypeZip:
+		zipReader, err := zip.OpenReader(path)
+		if err != nil {
+			t.Fatalf("open zip: %v", err)
+		}
+		defer zipReader.Close()
+		for _, f := range zipReader.File {
+			if strings.HasSuffix(f.Name, "/") {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatalf("open %s: %v", f.Name, err)
+			}
+			data, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				t.Fatalf("read %s: %v", f.Name, err)
+			}
+			contents[f.Name] = string(data)
+		}
+	}
+	return contents
+}
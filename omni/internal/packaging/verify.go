@@ -0,0 +1,253 @@
+package packaging
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// ReleaseManifestName is the name of the manifest tools/release_manifest
+// writes, and the file VerifyPackage looks for inside an archive when no
+// manifest is given.
+const ReleaseManifestName = "release.json"
+
+// ReleaseArtifact is a file listed in a release manifest.
+type ReleaseArtifact struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ReleaseManifest lists the files of a release with their SHA-256 digests.
+type ReleaseManifest struct {
+	Version   string            `json:"version"`
+	Generated string            `json:"generated"`
+	Artifacts []ReleaseArtifact `json:"artifacts"`
+}
+
+// ReadReleaseManifest reads the release manifest at path.
+func ReadReleaseManifest(path string) (*ReleaseManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	return parseReleaseManifest(data)
+}
+
+func parseReleaseManifest(data []byte) (*ReleaseManifest, error) {
+	var manifest ReleaseManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("decode manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// VerifyStatus is the outcome of checking one file of a package.
+type VerifyStatus string
+
+const (
+	// VerifyOK means the file matches its manifest entry.
+	VerifyOK VerifyStatus = "ok"
+	// VerifyMismatch means the size or digest of the file differs from
+	// its manifest entry.
+	VerifyMismatch VerifyStatus = "mismatch"
+	// VerifyMissing means the manifest lists a file the archive lacks.
+	VerifyMissing VerifyStatus = "missing"
+	// VerifyUnlisted means the archive holds a file the manifest does not
+	// list.
+	VerifyUnlisted VerifyStatus = "unlisted"
+)
+
+// VerifyResult is the outcome of checking one file of a package against the
+// release manifest.
+type VerifyResult struct {
+	// Name is the path of the file in the archive, or its manifest name
+	// when the archive lacks it.
+	Name     string
+	Status   VerifyStatus
+	Expected string
+	Actual   string
+}
+
+// Passed reports whether the result lets the package pass verification.
+// Unlisted files pass unless strict is set.
+func (r VerifyResult) Passed(strict bool) bool {
+	return r.Status == VerifyOK || r.Status == VerifyUnlisted && !strict
+}
+
+// archiveFile is the digest of a regular file read from an archive.
+type archiveFile struct {
+	name string
+	size int64
+	sum  string
+}
+
+// VerifyPackage checks the files of the .tar.gz or .zip package at
+// archivePath against the release manifest at manifestPath, or against the
+// release.json inside the archive if manifestPath is empty. A manifest name
+// matches a file by its full path in the archive, by the path below the
+// top-level directory, or by its base name. The results list the files of
+// the archive in order, followed by the manifest entries none of them
+// matched.
+func VerifyPackage(archivePath, manifestPath string) ([]VerifyResult, error) {
+	var (
+		files    []archiveFile
+		embedded []byte
+	)
+	err := walkArchive(archivePath, func(name string, mode os.FileMode, r io.Reader) error {
+		if path.Base(name) == ReleaseManifestName && manifestPath == "" {
+			data, err := io.ReadAll(r)
+			embedded = data
+			return err
+		}
+		hasher := sha256.New()
+		size, err := io.Copy(hasher, r)
+		if err != nil {
+			return err
+		}
+		files = append(files, archiveFile{name: name, size: size, sum: hex.EncodeToString(hasher.Sum(nil))})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest *ReleaseManifest
+	if manifestPath != "" {
+		manifest, err = ReadReleaseManifest(manifestPath)
+	} else if embedded != nil {
+		manifest, err = parseReleaseManifest(embedded)
+	} else {
+		err = fmt.Errorf("no manifest given and %s has no %s", archivePath, ReleaseManifestName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return verifyFiles(files, manifest), nil
+}
+
+// verifyFiles compares files read from an archive with manifest.
+func verifyFiles(files []archiveFile, manifest *ReleaseManifest) []VerifyResult {
+	artifacts := make(map[string]ReleaseArtifact, len(manifest.Artifacts))
+	for _, artifact := range manifest.Artifacts {
+		artifacts[artifact.Name] = artifact
+	}
+	matched := make(map[string]bool)
+	results := make([]VerifyResult, 0, len(files)+len(manifest.Artifacts))
+	for _, file := range files {
+		artifact, ok := lookupArtifact(artifacts, file.name)
+		if !ok {
+			results = append(results, VerifyResult{Name: file.name, Status: VerifyUnlisted, Actual: file.sum})
+			continue
+		}
+		matched[artifact.Name] = true
+		result := VerifyResult{Name: file.name, Status: VerifyOK, Expected: strings.ToLower(artifact.SHA256), Actual: file.sum}
+		if result.Expected != file.sum || artifact.Size != 0 && artifact.Size != file.size {
+			result.Status = VerifyMismatch
+		}
+		results = append(results, result)
+	}
+	for _, artifact := range manifest.Artifacts {
+		if !matched[artifact.Name] {
+			results = append(results, VerifyResult{Name: artifact.Name, Status: VerifyMissing, Expected: artifact.SHA256})
+		}
+	}
+	return results
+}
+
+// lookupArtifact finds the manifest entry of the archive file name.
+func lookupArtifact(artifacts map[string]ReleaseArtifact, name string) (ReleaseArtifact, bool) {
+	candidates := []string{name, stripTopLevel(name), path.Base(name)}
+	for _, candidate := range candidates {
+		if artifact, ok := artifacts[candidate]; ok && candidate != "" {
+			return artifact, true
+		}
+	}
+	return ReleaseArtifact{}, false
+}
+
+// stripTopLevel drops the first directory of an archive path, such as the
+// omni-lang-<version> directory packages keep their files in.
+func stripTopLevel(name string) string {
+	if i := strings.IndexByte(name, '/'); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
+
+// walkArchive calls fn with the name, mode and contents of every regular
+// file of the .tar.gz or .zip archive at archivePath, in archive order.
+func walkArchive(archivePath string, fn func(name string, mode os.FileMode, r io.Reader) error) error {
+	switch {
+	case strings.HasSuffix(archivePath, "."+string(PackageTypeTarGz)) || strings.HasSuffix(archivePath, ".tgz"):
+		return walkTarGz(archivePath, fn)
+	case strings.HasSuffix(archivePath, "."+string(PackageTypeZip)):
+		return walkZip(archivePath, fn)
+	}
+	return fmt.Errorf("unsupported package type: %s", archivePath)
+}
+
+func walkTarGz(archivePath string, fn func(name string, mode os.FileMode, r io.Reader) error) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gzReader, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", archivePath, err)
+	}
+	defer gzReader.Close()
+
+	tarReader := tar.NewReader(gzReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read %s: %w", archivePath, err)
+		}
+		mode := header.FileInfo().Mode()
+		if !mode.IsRegular() {
+			continue
+		}
+		if err := fn(header.Name, mode, tarReader); err != nil {
+			return fmt.Errorf("%s: %w", header.Name, err)
+		}
+	}
+}
+
+func walkZip(archivePath string, fn func(name string, mode os.FileMode, r io.Reader) error) error {
+	zipReader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", archivePath, err)
+	}
+	defer zipReader.Close()
+
+	for _, entry := range zipReader.File {
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+		r, err := entry.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry.Name, err)
+		}
+		err = fn(entry.Name, entry.Mode(), r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry.Name, err)
+		}
+	}
+	return nil
+}
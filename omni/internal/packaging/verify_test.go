@@ -0,0 +1,183 @@
+package packaging
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// archiveEntry is a file of a synthetic test archive.
+type archiveEntry struct {
+	name string
+	body string
+	mode int64
+}
+
+func writeTestArchive(t *testing.T, path string, entries []archiveEntry) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create archive: %v", err)
+	}
+	defer file.Close()
+
+	if filepath.Ext(path) == ".zip" {
+		zipWriter := zip.NewWriter(file)
+		for _, entry := range entries {
+			header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+			header.SetMode(os.FileMode(entry.mode))
+			w, err := zipWriter.CreateHeader(header)
+			if err != nil {
+				t.Fatalf("add %s: %v", entry.name, err)
+			}
+			if _, err := w.Write([]byte(entry.body)); err != nil {
+				t.Fatalf("write %s: %v", entry.name, err)
+			}
+		}
+		if err := zipWriter.Close(); err != nil {
+			t.Fatalf("close zip: %v", err)
+		}
+		return
+	}
+
+	gzWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzWriter)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: entry.mode, Size: int64(len(entry.body)), Typeflag: tar.TypeReg}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatalf("add %s: %v", entry.name, err)
+		}
+		if _, err := tarWriter.Write([]byte(entry.body)); err != nil {
+			t.Fatalf("write %s: %v", entry.name, err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("close tar: %v", err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		t.Fatalf("close gzip: %v", err)
+	}
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func testManifest(t *testing.T, files map[string]string) string {
+	t.Helper()
+	manifest := ReleaseManifest{Version: "1.0.0"}
+	for name, body := range files {
+		manifest.Artifacts = append(manifest.Artifacts, ReleaseArtifact{Name: name, Size: int64(len(body)), SHA256: sha256Hex(body)})
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("encode manifest: %v", err)
+	}
+	return string(data)
+}
+
+var testPackageFiles = []archiveEntry{
+	{name: "omni-lang-1.0.0/bin/omnic", body: "compiler", mode: 0o755},
+	{name: "omni-lang-1.0.0/std/io.omni", body: "func println() {}\n", mode: 0o644},
+}
+
+func statuses(results []VerifyResult) map[string]VerifyStatus {
+	out := make(map[string]VerifyStatus, len(results))
+	for _, result := range results {
+		out[result.Name] = result.Status
+	}
+	return out
+}
+
+func TestVerifyPackageMatches(t *testing.T) {
+	for _, ext := range []string{".tar.gz", ".zip"} {
+		t.Run(ext, func(t *testing.T) {
+			dir := t.TempDir()
+			archive := filepath.Join(dir, "omni"+ext)
+			writeTestArchive(t, archive, testPackageFiles)
+			manifest := filepath.Join(dir, "release.json")
+			os.WriteFile(manifest, []byte(testManifest(t, map[string]string{
+				"bin/omnic":   "compiler",
+				"std/io.omni": "func println() {}\n",
+			})), 0o644)
+
+			results, err := VerifyPackage(archive, manifest)
+			if err != nil {
+				t.Fatalf("VerifyPackage: %v", err)
+			}
+			if len(results) != 2 {
+				t.Fatalf("got %d results, want 2: %+v", len(results), results)
+			}
+			for _, result := range results {
+				if !result.Passed(true) {
+					t.Errorf("%s: status %s, want ok", result.Name, result.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyPackageReportsFailures(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "omni.tar.gz")
+	writeTestArchive(t, archive, append(testPackageFiles, archiveEntry{name: "omni-lang-1.0.0/extra.txt", body: "x", mode: 0o644}))
+	manifest := filepath.Join(dir, "release.json")
+	os.WriteFile(manifest, []byte(testManifest(t, map[string]string{
+		"bin/omnic":   "tampered",
+		"std/io.omni": "func println() {}\n",
+		"bin/omnir":   "runner",
+	})), 0o644)
+
+	results, err := VerifyPackage(archive, manifest)
+	if err != nil {
+		t.Fatalf("VerifyPackage: %v", err)
+	}
+	want := map[string]VerifyStatus{
+		"omni-lang-1.0.0/bin/omnic":   VerifyMismatch,
+		"omni-lang-1.0.0/std/io.omni": VerifyOK,
+		"omni-lang-1.0.0/extra.txt":   VerifyUnlisted,
+		"bin/omnir":                   VerifyMissing,
+	}
+	got := statuses(results)
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("%s: status %q, want %q", name, got[name], status)
+		}
+	}
+	for _, result := range results {
+		if result.Status == VerifyUnlisted && (!result.Passed(false) || result.Passed(true)) {
+			t.Errorf("unlisted file should pass only without strict")
+		}
+	}
+}
+
+func TestVerifyPackageEmbeddedManifest(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "omni.zip")
+	manifest := testManifest(t, map[string]string{"bin/omnic": "compiler", "std/io.omni": "func println() {}\n"})
+	writeTestArchive(t, archive, append(testPackageFiles, archiveEntry{name: "omni-lang-1.0.0/release.json", body: manifest, mode: 0o644}))
+
+	results, err := VerifyPackage(archive, "")
+	if err != nil {
+		t.Fatalf("VerifyPackage: %v", err)
+	}
+	got := statuses(results)
+	if len(got) != 2 || got["omni-lang-1.0.0/bin/omnic"] != VerifyOK || got["omni-lang-1.0.0/std/io.omni"] != VerifyOK {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
+
+func TestVerifyPackageWithoutManifest(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "omni.tar.gz")
+	writeTestArchive(t, archive, testPackageFiles)
+	if _, err := VerifyPackage(archive, ""); err == nil {
+		t.Fatal("expected an error for an archive without release.json")
+	}
+}
@@ -170,6 +170,12 @@ func Parse(filename, input string) (*ast.Module, error) {
 	if err != nil {
 		return nil, err
 	}
+	return ParseTokens(filename, input, tokens)
+}
+
+// ParseTokens builds an abstract syntax tree from tokens previously produced by
+// lexer.LexAll for the same input. It lets callers time lexing separately.
+func ParseTokens(filename, input string, tokens []lexer.Token) (*ast.Module, error) {
 	// Transform >> tokens to two > tokens in generic contexts
 	transformedTokens := transformTokensForNestedGenerics(tokens)
 	p := &Parser{
@@ -181,6 +187,7 @@ func Parse(filename, input string) (*ast.Module, error) {
 	if err != nil {
 		return mod, err
 	}
+	ast.AttachDocComments(mod, p.lines)
 	if len(p.diagnostics) > 0 {
 		return mod, errors.Join(p.diagnostics...)
 	}
@@ -318,6 +325,8 @@ func (p *Parser) parseDecl() (ast.Decl, error) {
 		return p.parseStructDecl()
 	case lexer.TokenEnum:
 		return p.parseEnumDecl()
+	case lexer.TokenInterface:
+		return p.parseInterfaceDecl()
 	case lexer.TokenType:
 		return p.parseTypeAliasDecl()
 	case lexer.TokenAsync, lexer.TokenFunc:
@@ -386,6 +395,43 @@ func (p *Parser) parseStructDecl() (ast.Decl, error) {
 	return &ast.StructDecl{SpanInfo: span, Name: nameTok.Lexeme, TypeParams: typeParams, Fields: fields}, nil
 }
 
+func (p *Parser) parseInterfaceDecl() (ast.Decl, error) {
+	kw := p.advance()
+	nameTok := p.expect(lexer.TokenIdentifier)
+	p.expect(lexer.TokenLBrace)
+	methods := []ast.InterfaceMethod{}
+	for !p.match(lexer.TokenRBrace) {
+		methodName := p.expect(lexer.TokenIdentifier)
+		p.expect(lexer.TokenLParen)
+		if self := p.expect(lexer.TokenIdentifier); self.Lexeme != "self" {
+			return nil, p.errorAt(self, "interface method %s must take self as its first parameter", methodName.Lexeme)
+		}
+		params := []ast.Param{}
+		for p.match(lexer.TokenComma) {
+			paramName := p.expect(lexer.TokenIdentifier)
+			p.expect(lexer.TokenColon)
+			typ, err := p.parseTypeExpr()
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, ast.Param{Name: paramName.Lexeme, Type: typ, Span: paramName.Span})
+		}
+		p.expect(lexer.TokenRParen)
+		var retType *ast.TypeExpr
+		if p.match(lexer.TokenColon) {
+			t, err := p.parseTypeExpr()
+			if err != nil {
+				return nil, err
+			}
+			retType = t
+		}
+		methods = append(methods, ast.InterfaceMethod{Name: methodName.Lexeme, Params: params, Return: retType, Span: methodName.Span})
+		p.match(lexer.TokenComma)
+	}
+	span := lexer.Span{Start: kw.Span.Start, End: p.previous().Span.End}
+	return &ast.InterfaceDecl{SpanInfo: span, Name: nameTok.Lexeme, Methods: methods}, nil
+}
+
 func (p *Parser) parseEnumDecl() (ast.Decl, error) {
 	kw := p.advance()
 	nameTok := p.expect(lexer.TokenIdentifier)
@@ -408,6 +454,18 @@ func (p *Parser) parseFuncDecl() (ast.Decl, error) {
 		isAsync = true
 	}
 	kw := p.expect(lexer.TokenFunc)
+	// A method names its receiver before the method name: func (s T) name()
+	var receiver *ast.Param
+	if p.match(lexer.TokenLParen) {
+		recvName := p.expect(lexer.TokenIdentifier)
+		p.match(lexer.TokenColon)
+		recvType, err := p.parseTypeExpr()
+		if err != nil {
+			return nil, err
+		}
+		p.expect(lexer.TokenRParen)
+		receiver = &ast.Param{Name: recvName.Lexeme, Type: recvType, Span: recvName.Span}
+	}
 	nameTok := p.expect(lexer.TokenIdentifier)
 
 	// Parse generic type parameters
@@ -429,13 +487,49 @@ func (p *Parser) parseFuncDecl() (ast.Decl, error) {
 	if !p.match(lexer.TokenRParen) {
 		for {
 			paramName := p.expect(lexer.TokenIdentifier)
+			// Parameters of the same type may share it, as in a, b: int
+			var group []lexer.Token
+			for p.match(lexer.TokenComma) {
+				group = append(group, paramName)
+				paramName = p.expect(lexer.TokenIdentifier)
+			}
 			p.expect(lexer.TokenColon)
+			// A variadic parameter is written ...T and must come last
+			variadic := p.peekKind() == lexer.TokenDot
+			if variadic {
+				for i := 0; i < 3; i++ {
+					p.expect(lexer.TokenDot)
+				}
+			}
 			typ, err := p.parseTypeExpr()
 			if err != nil {
 				return nil, err
 			}
-			params = append(params, ast.Param{Name: paramName.Lexeme, Type: typ, Span: paramName.Span})
+			if len(group) > 0 && (variadic || p.peekKind() == lexer.TokenAssign) {
+				return nil, p.errorAt(paramName, "parameter %s cannot share its type, as it is variadic or has a default value", paramName.Lexeme)
+			}
+			for _, name := range group {
+				if len(params) > 0 && params[len(params)-1].Default != nil {
+					return nil, p.errorAt(name, "parameter %s needs a default value, as it follows a parameter with one", name.Lexeme)
+				}
+				params = append(params, ast.Param{Name: name.Lexeme, Type: typ, Span: name.Span})
+			}
+			param := ast.Param{Name: paramName.Lexeme, Type: typ, Variadic: variadic, Span: paramName.Span}
+			if p.match(lexer.TokenAssign) {
+				if variadic {
+					return nil, p.errorAt(paramName, "variadic parameter %s cannot have a default value", paramName.Lexeme)
+				}
+				if param.Default, err = p.parseExpr(); err != nil {
+					return nil, err
+				}
+			} else if len(params) > 0 && params[len(params)-1].Default != nil && !variadic {
+				return nil, p.errorAt(paramName, "parameter %s needs a default value, as it follows a parameter with one", paramName.Lexeme)
+			}
+			params = append(params, param)
 			if p.match(lexer.TokenComma) {
+				if variadic {
+					return nil, p.errorAt(paramName, "variadic parameter %s must be the last parameter", paramName.Lexeme)
+				}
 				continue
 			}
 			p.expect(lexer.TokenRParen)
@@ -450,20 +544,39 @@ func (p *Parser) parseFuncDecl() (ast.Decl, error) {
 		}
 		retType = t
 	}
+	var retName lexer.Token
+	if retType != nil && p.match(lexer.TokenAs) {
+		retName = p.expect(lexer.TokenIdentifier)
+	}
 	if p.match(lexer.TokenFatArrow) {
+		if retName.Lexeme != "" {
+			return nil, p.errorAt(retName, "named return %q needs a block body", retName.Lexeme)
+		}
 		expr, err := p.parseExpr()
 		if err != nil {
 			return nil, err
 		}
 		span := lexer.Span{Start: kw.Span.Start, End: expr.Span().End}
-		return &ast.FuncDecl{SpanInfo: span, Name: nameTok.Lexeme, TypeParams: typeParams, Params: params, Return: retType, ExprBody: expr, IsAsync: isAsync}, nil
+		fn := &ast.FuncDecl{SpanInfo: span, Name: nameTok.Lexeme, TypeParams: typeParams, Params: params, Return: retType, ExprBody: expr, IsAsync: isAsync, File: p.filename}
+		return methodOrFunc(receiver, fn), nil
 	}
 	body, err := p.parseBlock()
 	if err != nil {
 		return nil, err
 	}
 	span := lexer.Span{Start: kw.Span.Start, End: body.Span().End}
-	return &ast.FuncDecl{SpanInfo: span, Name: nameTok.Lexeme, TypeParams: typeParams, Params: params, Return: retType, Body: body, IsAsync: isAsync}, nil
+	fn := &ast.FuncDecl{SpanInfo: span, Name: nameTok.Lexeme, TypeParams: typeParams, Params: params, Return: retType,
+		ReturnName: retName.Lexeme, ReturnNameSpan: retName.Span, Body: body, IsAsync: isAsync, File: p.filename}
+	return methodOrFunc(receiver, fn), nil
+}
+
+// methodOrFunc returns fn as a method of the receiver type, or fn itself
+// when it has no receiver.
+func methodOrFunc(receiver *ast.Param, fn *ast.FuncDecl) ast.Decl {
+	if receiver == nil {
+		return fn
+	}
+	return &ast.MethodDecl{SpanInfo: fn.SpanInfo, Receiver: *receiver, Func: fn}
 }
 
 func (p *Parser) parseBlock() (*ast.BlockStmt, error) {
@@ -524,6 +637,8 @@ func (p *Parser) parseStmt() (ast.Stmt, error) {
 		return p.parseTryStmt()
 	case lexer.TokenThrow:
 		return p.parseThrowStmt()
+	case lexer.TokenDefer:
+		return p.parseDeferStmt()
 	default:
 		expr, err := p.parseExpr()
 		if err != nil {
@@ -546,6 +661,19 @@ func (p *Parser) parseReturnStmt() (ast.Stmt, error) {
 	if err != nil {
 		return nil, err
 	}
+	if p.peekKind() == lexer.TokenComma {
+		// return a, b returns the tuple (a, b)
+		elements := []ast.Expr{expr}
+		for p.match(lexer.TokenComma) {
+			el, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, el)
+		}
+		span := lexer.Span{Start: expr.Span().Start, End: p.previous().Span.End}
+		expr = &ast.TupleLiteralExpr{SpanInfo: span, Elements: elements}
+	}
 	span := lexer.Span{Start: tok.Span.Start, End: expr.Span().End}
 	return &ast.ReturnStmt{SpanInfo: span, Value: expr}, nil
 }
@@ -721,6 +849,9 @@ func (p *Parser) parseForPost() (ast.Stmt, error) {
 
 func (p *Parser) parseBindingStmt(mutable bool) (ast.Stmt, error) {
 	kw := p.advance()
+	if kind := p.peekKind(); kind == lexer.TokenLParen || kind == lexer.TokenLBrace {
+		return p.parseDestructureStmt(kw, mutable)
+	}
 	nameTok := p.expect(lexer.TokenIdentifier)
 	var typ *ast.TypeExpr
 	if p.match(lexer.TokenColon) {
@@ -739,6 +870,59 @@ func (p *Parser) parseBindingStmt(mutable bool) (ast.Stmt, error) {
 	return &ast.BindingStmt{SpanInfo: span, Mutable: mutable, Name: nameTok.Lexeme, Type: typ, Value: value}, nil
 }
 
+// parseDestructureStmt parses the pattern and value of a destructuring
+// let or var: (a, b) binds the elements of a tuple, and { name, age: years }
+// fields of a struct, ending in .. to leave the others unbound.
+func (p *Parser) parseDestructureStmt(kw lexer.Token, mutable bool) (ast.Stmt, error) {
+	stmt := &ast.DestructureStmt{Mutable: mutable}
+	if p.match(lexer.TokenLParen) {
+		stmt.Tuple = true
+		for {
+			stmt.Names = append(stmt.Names, p.expect(lexer.TokenIdentifier).Lexeme)
+			if !p.match(lexer.TokenComma) {
+				break
+			}
+		}
+		p.expect(lexer.TokenRParen)
+		if len(stmt.Names) < 2 {
+			return nil, p.errorAt(kw, "tuple destructuring needs at least two names")
+		}
+	} else {
+		p.expect(lexer.TokenLBrace)
+		for !p.match(lexer.TokenRBrace) {
+			// The lexer reads .. as two dots
+			if p.match(lexer.TokenDot) {
+				p.expect(lexer.TokenDot)
+				stmt.Rest = true
+				p.expect(lexer.TokenRBrace)
+				break
+			}
+			field := p.expect(lexer.TokenIdentifier)
+			binding := field
+			if p.match(lexer.TokenColon) {
+				binding = p.expect(lexer.TokenIdentifier)
+			}
+			stmt.Fields = append(stmt.Fields, ast.FieldPattern{
+				Field:   field.Lexeme,
+				Binding: binding.Lexeme,
+				Span:    lexer.Span{Start: field.Span.Start, End: binding.Span.End},
+			})
+			if !p.match(lexer.TokenComma) {
+				p.expect(lexer.TokenRBrace)
+				break
+			}
+		}
+	}
+	p.expect(lexer.TokenAssign)
+	value, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Value = value
+	stmt.SpanInfo = lexer.Span{Start: kw.Span.Start, End: value.Span().End}
+	return stmt, nil
+}
+
 // Expression parsing -------------------------------------------------------
 
 func (p *Parser) parseExpr() (ast.Expr, error) {
@@ -962,6 +1146,13 @@ func (p *Parser) parseUnary() (ast.Expr, error) {
 		}
 		return &ast.UnaryExpr{SpanInfo: lexer.Span{Start: op.Span.Start, End: expr.Span().End}, Op: op.Lexeme, Expr: expr}, nil
 	case lexer.TokenLParen:
+		// Only a type can follow the '(' of a cast; anything else, such as a
+		// literal, starts a parenthesized expression
+		switch p.peekKindN(1) {
+		case lexer.TokenIdentifier, lexer.TokenLBracket, lexer.TokenStar, lexer.TokenLParen:
+		default:
+			return p.parsePostfix()
+		}
 		// Check if this is a type cast: (type) expression
 		startPos := p.current().Span.Start
 		savedPos := p.pos
@@ -1109,13 +1300,30 @@ func (p *Parser) parsePrimary() (ast.Expr, error) {
 		return &ast.LiteralExpr{SpanInfo: tok.Span, Kind: ast.LiteralHex, Value: tok.Lexeme}, nil
 	case lexer.TokenBinaryLiteral:
 		return &ast.LiteralExpr{SpanInfo: tok.Span, Kind: ast.LiteralBinary, Value: tok.Lexeme}, nil
+	case lexer.TokenInt64Literal:
+		return &ast.LiteralExpr{SpanInfo: tok.Span, Kind: ast.LiteralInt64, Value: tok.Lexeme}, nil
+	case lexer.TokenUintLiteral:
+		return &ast.LiteralExpr{SpanInfo: tok.Span, Kind: ast.LiteralUint, Value: tok.Lexeme}, nil
 	case lexer.TokenLParen:
 		expr, err := p.parseExpr()
 		if err != nil {
 			return nil, err
 		}
-		p.expect(lexer.TokenRParen)
-		return expr, nil
+		if p.peekKind() != lexer.TokenComma {
+			p.expect(lexer.TokenRParen)
+			return expr, nil
+		}
+		// A comma makes the parentheses a tuple: (a, b)
+		elems := []ast.Expr{expr}
+		for p.match(lexer.TokenComma) {
+			elem, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, elem)
+		}
+		rparen := p.expect(lexer.TokenRParen)
+		return &ast.TupleLiteralExpr{SpanInfo: lexer.Span{Start: tok.Span.Start, End: rparen.Span.End}, Elements: elems}, nil
 	case lexer.TokenPipe:
 		// Lambda expression: |a, b| a + b
 		return p.parseLambda(tok)
@@ -1145,6 +1353,8 @@ func (p *Parser) parsePrimary() (ast.Expr, error) {
 		return p.parseMapLiteral(tok)
 	case lexer.TokenNew:
 		return p.parseNewExpr(tok)
+	case lexer.TokenMatch:
+		return p.parseMatchExpr(tok)
 	default:
 		return nil, p.errorAt(tok, "unexpected token %s", tok.Kind)
 	}
@@ -1341,7 +1551,7 @@ func (p *Parser) synchronizeDecl() {
 	// Skip tokens until we find a declaration start or a synchronization point
 	for {
 		switch p.peekKind() {
-		case lexer.TokenEOF, lexer.TokenFunc, lexer.TokenLet, lexer.TokenVar, lexer.TokenStruct, lexer.TokenEnum, lexer.TokenImport:
+		case lexer.TokenEOF, lexer.TokenFunc, lexer.TokenLet, lexer.TokenVar, lexer.TokenStruct, lexer.TokenEnum, lexer.TokenInterface, lexer.TokenImport:
 			return
 		case lexer.TokenSemicolon, lexer.TokenRBrace:
 			// Skip semicolon or closing brace, then continue
@@ -1370,7 +1580,7 @@ func (p *Parser) synchronizeStmt() {
 	// Skip tokens until we find a statement start or a synchronization point
 	for {
 		switch p.peekKind() {
-		case lexer.TokenEOF, lexer.TokenRBrace, lexer.TokenReturn, lexer.TokenIf, lexer.TokenFor, lexer.TokenWhile, lexer.TokenBreak, lexer.TokenContinue, lexer.TokenLet, lexer.TokenVar:
+		case lexer.TokenEOF, lexer.TokenRBrace, lexer.TokenReturn, lexer.TokenIf, lexer.TokenFor, lexer.TokenWhile, lexer.TokenBreak, lexer.TokenContinue, lexer.TokenLet, lexer.TokenVar, lexer.TokenDefer:
 			return
 		case lexer.TokenSemicolon:
 			// Skip semicolon, then continue
@@ -1597,7 +1807,11 @@ func (p *Parser) parseSingleTypeWithNestedGenerics() (*ast.TypeExpr, error) {
 			return paramTypes[0], nil
 		}
 
-		// Multiple types without -> is an error
+		// Several types without -> make a tuple type: (int, string)
+		if len(paramTypes) > 1 {
+			span := lexer.Span{Start: start, End: p.previous().Span.End}
+			return &ast.TypeExpr{SpanInfo: span, Name: "tuple", Args: paramTypes}, nil
+		}
 		return nil, p.errorAtCurrent("multiple types in parentheses must be followed by '->' for function type")
 	}
 
@@ -1735,7 +1949,11 @@ func (p *Parser) parseSingleType() (*ast.TypeExpr, error) {
 			return paramTypes[0], nil
 		}
 
-		// Multiple types without -> is an error
+		// Several types without -> make a tuple type: (int, string)
+		if len(paramTypes) > 1 {
+			span := lexer.Span{Start: start, End: p.previous().Span.End}
+			return &ast.TypeExpr{SpanInfo: span, Name: "tuple", Args: paramTypes}, nil
+		}
 		return nil, p.errorAtCurrent("multiple types in parentheses must be followed by '->' for function type")
 	}
 
@@ -1936,6 +2154,86 @@ func (p *Parser) parseNewExpr(tok lexer.Token) (ast.Expr, error) {
 	return &ast.NewExpr{SpanInfo: span, Type: typ}, nil
 }
 
+func (p *Parser) parseMatchExpr(tok lexer.Token) (ast.Expr, error) {
+	// Parse: match subject { case pattern => expr, ... }
+	subject, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	p.expect(lexer.TokenLBrace)
+	cases := []ast.MatchCase{}
+	for !p.match(lexer.TokenRBrace) {
+		caseTok := p.expect(lexer.TokenCase)
+		pattern, err := p.parseMatchPattern()
+		if err != nil {
+			return nil, err
+		}
+		p.expect(lexer.TokenFatArrow)
+		body, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		span := lexer.Span{Start: caseTok.Span.Start, End: body.Span().End}
+		cases = append(cases, ast.MatchCase{Pattern: pattern, Body: body, Span: span})
+		p.match(lexer.TokenComma)
+	}
+	span := lexer.Span{Start: tok.Span.Start, End: p.previous().Span.End}
+	return &ast.MatchExpr{SpanInfo: span, Subject: subject, Cases: cases}, nil
+}
+
+func (p *Parser) parseMatchPattern() (ast.MatchPattern, error) {
+	tok := p.current()
+	switch {
+	case tok.Kind == lexer.TokenIdentifier && tok.Lexeme == "_":
+		p.advance()
+		return ast.MatchPattern{Kind: ast.PatternWildcard, Span: tok.Span}, nil
+	case tok.Kind == lexer.TokenIdentifier:
+		// An enum variant: Enum.Variant
+		p.advance()
+		p.expect(lexer.TokenDot)
+		variant := p.expect(lexer.TokenIdentifier)
+		span := lexer.Span{Start: tok.Span.Start, End: variant.Span.End}
+		return ast.MatchPattern{Kind: ast.PatternEnum, Enum: tok.Lexeme, Variant: variant.Lexeme, Span: span}, nil
+	case tok.Kind == lexer.TokenLBrace:
+		// A struct destructuring: { field: binding, ... }
+		p.advance()
+		fields := []ast.FieldPattern{}
+		for !p.match(lexer.TokenRBrace) {
+			field := p.expect(lexer.TokenIdentifier)
+			p.expect(lexer.TokenColon)
+			binding := p.expect(lexer.TokenIdentifier)
+			fields = append(fields, ast.FieldPattern{
+				Field:   field.Lexeme,
+				Binding: binding.Lexeme,
+				Span:    lexer.Span{Start: field.Span.Start, End: binding.Span.End},
+			})
+			p.match(lexer.TokenComma)
+		}
+		span := lexer.Span{Start: tok.Span.Start, End: p.previous().Span.End}
+		return ast.MatchPattern{Kind: ast.PatternStruct, Fields: fields, Span: span}, nil
+	}
+	value, err := p.parseUnary()
+	if err != nil {
+		return ast.MatchPattern{}, err
+	}
+	if !isLiteralPattern(value) {
+		return ast.MatchPattern{}, p.errorAt(tok, "match pattern must be a literal, an enum variant, a struct pattern or _")
+	}
+	return ast.MatchPattern{Kind: ast.PatternLiteral, Value: value, Span: value.Span()}, nil
+}
+
+// isLiteralPattern reports whether expr is a literal, or a negated number.
+func isLiteralPattern(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.LiteralExpr:
+		return e.Kind != ast.LiteralNull
+	case *ast.UnaryExpr:
+		lit, ok := e.Expr.(*ast.LiteralExpr)
+		return ok && e.Op == "-" && (lit.Kind == ast.LiteralInt || lit.Kind == ast.LiteralFloat)
+	}
+	return false
+}
+
 func (p *Parser) parseDeleteExpr(tok lexer.Token) (ast.Expr, error) {
 	// Parse: delete expression
 	expr, err := p.parseExpr()
@@ -2367,6 +2665,22 @@ func (p *Parser) parseThrowStmt() (ast.Stmt, error) {
 	}, nil
 }
 
+// parseDeferStmt parses a defer statement: defer <call_expr>. The checker
+// verifies that the expression is a call.
+func (p *Parser) parseDeferStmt() (ast.Stmt, error) {
+	startPos := p.expect(lexer.TokenDefer).Span.Start
+
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.DeferStmt{
+		SpanInfo: lexer.Span{Start: startPos, End: expr.Span().End},
+		Call:     expr,
+	}, nil
+}
+
 // parseTypeAliasDecl parses a type alias declaration: type UserID = int
 func (p *Parser) parseTypeAliasDecl() (ast.Decl, error) {
 	startPos := p.expect(lexer.TokenType).Span.Start
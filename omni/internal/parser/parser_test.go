@@ -605,3 +605,149 @@ func TestTransformTokensForNestedGenerics(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDocComments(t *testing.T) {
+	path := filepath.Join("..", "..", "std", "io", "print.omni")
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	module, err := parser.Parse(path, string(src))
+	if err != nil {
+		t.Fatalf("parse %s: %v", path, err)
+	}
+
+	docs := make(map[string]string)
+	for _, decl := range module.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			docs[fn.Name] = fn.Doc
+		}
+	}
+	if got, want := docs["println"], "println outputs a printable value to stdout followed by a newline."; got != want {
+		t.Errorf("println doc = %q, want %q", got, want)
+	}
+	// The // status note above print is not part of its documentation.
+	if got, want := docs["print"], "print outputs a printable value to stdout without a newline."; got != want {
+		t.Errorf("print doc = %q, want %q", got, want)
+	}
+	if got := docs["read_line_async"]; !strings.Contains(got, "\nThe Promise is immediately resolved") {
+		t.Errorf("expected multi-line doc for read_line_async, got %q", got)
+	}
+}
+
+func TestParseDeferStmt(t *testing.T) {
+	mod, err := parser.Parse("test.omni", "func test(f:int) { defer close(f) }")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	fn := mod.Decls[0].(*ast.FuncDecl)
+	stmt, ok := fn.Body.Statements[0].(*ast.DeferStmt)
+	if !ok {
+		t.Fatalf("expected *ast.DeferStmt, got %T", fn.Body.Statements[0])
+	}
+	if _, ok := stmt.Call.(*ast.CallExpr); !ok {
+		t.Errorf("deferred expression = %T, want *ast.CallExpr", stmt.Call)
+	}
+}
+
+func TestParseNamedReturn(t *testing.T) {
+	mod, err := parser.Parse("test.omni", "func greet():string as result { result = \"hi\" }")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	fn := mod.Decls[0].(*ast.FuncDecl)
+	if fn.ReturnName != "result" || fn.Return == nil || fn.Return.Name != "string" {
+		t.Errorf("return = %v as %q, want string as result", fn.Return, fn.ReturnName)
+	}
+	if _, err := parser.Parse("test.omni", "func one():int as n => 1"); err == nil {
+		t.Error("expected a named return with an expression body to be rejected")
+	}
+}
+
+func TestParseInterfaceDecl(t *testing.T) {
+	mod, err := parser.Parse("test.omni", "interface Shape {\n  area(self): int\n  grow(self, by: int)\n}")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	iface := mod.Decls[0].(*ast.InterfaceDecl)
+	if iface.Name != "Shape" || len(iface.Methods) != 2 {
+		t.Fatalf("interface = %s with %d methods, want Shape with 2", iface.Name, len(iface.Methods))
+	}
+	if m := iface.Methods[1]; m.Name != "grow" || len(m.Params) != 1 || m.Return != nil {
+		t.Errorf("method = %s with %d params and return %v, want grow(by) without a return", m.Name, len(m.Params), m.Return)
+	}
+	if _, err := parser.Parse("test.omni", "interface Shape { area(): int }"); err == nil {
+		t.Error("expected an interface method without self to be rejected")
+	}
+}
+
+func TestParseMatchExpr(t *testing.T) {
+	src := "func f(p:Point):int => match p {\n  case { x: a, y: _ } => a,\n}\nfunc g(n:int):int => match n { case -1 => 0 case _ => n }"
+	mod, err := parser.Parse("test.omni", src)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	match, ok := mod.Decls[0].(*ast.FuncDecl).ExprBody.(*ast.MatchExpr)
+	if !ok || len(match.Cases) != 1 {
+		t.Fatalf("body = %#v, want a match with one case", mod.Decls[0].(*ast.FuncDecl).ExprBody)
+	}
+	if pat := match.Cases[0].Pattern; pat.Kind != ast.PatternStruct || len(pat.Fields) != 2 || pat.Fields[0].Binding != "a" {
+		t.Errorf("pattern = %+v, want a struct pattern binding x to a", pat)
+	}
+	match = mod.Decls[1].(*ast.FuncDecl).ExprBody.(*ast.MatchExpr)
+	if len(match.Cases) != 2 || match.Cases[0].Pattern.Kind != ast.PatternLiteral || match.Cases[1].Pattern.Kind != ast.PatternWildcard {
+		t.Errorf("cases = %+v, want a literal and a wildcard case", match.Cases)
+	}
+	if _, err := parser.Parse("test.omni", "func h(n:int):int => match n { case n + 1 => 0 }"); err == nil {
+		t.Error("expected an expression pattern to be rejected")
+	}
+}
+
+func TestParseVariadicParam(t *testing.T) {
+	mod, err := parser.Parse("test.omni", "func log(msg:string, args:...int):void {}")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	params := mod.Decls[0].(*ast.FuncDecl).Params
+	if len(params) != 2 || params[0].Variadic || !params[1].Variadic || params[1].Type.Name != "int" {
+		t.Errorf("params = %+v, want args to be a variadic int parameter", params)
+	}
+	if _, err := parser.Parse("test.omni", "func f(args:...int, n:int):void {}"); err == nil {
+		t.Error("expected a variadic parameter before the last to be rejected")
+	}
+}
+
+func TestParseDefaultParams(t *testing.T) {
+	mod, err := parser.Parse("test.omni", `func read(path:string, encoding:string = "utf-8"):string => path`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	params := mod.Decls[0].(*ast.FuncDecl).Params
+	if params[0].Default != nil {
+		t.Errorf("path has default %#v, want none", params[0].Default)
+	}
+	if lit, ok := params[1].Default.(*ast.LiteralExpr); !ok || lit.Value != `"utf-8"` {
+		t.Errorf("encoding default = %#v, want the literal utf-8", params[1].Default)
+	}
+	if _, err := parser.Parse("test.omni", "func f(a:int = 1, b:int):int => a"); err == nil {
+		t.Error("expected a parameter without a default after one with a default to be rejected")
+	}
+}
+
+func TestParseMultipleReturns(t *testing.T) {
+	mod, err := parser.Parse("test.omni", "func divmod(a, b:int, c:string):(int, int) {\n  return a / b, a % b\n}")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	fn := mod.Decls[0].(*ast.FuncDecl)
+	if len(fn.Params) != 3 || fn.Params[0].Name != "a" || fn.Params[0].Type.Name != "int" || fn.Params[2].Type.Name != "string" {
+		t.Errorf("params = %+v, want a and b to share the type int", fn.Params)
+	}
+	if fn.Return == nil || fn.Return.Name != "tuple" || len(fn.Return.Args) != 2 {
+		t.Errorf("return type = %+v, want a tuple of two", fn.Return)
+	}
+	ret := fn.Body.Statements[0].(*ast.ReturnStmt)
+	if tuple, ok := ret.Value.(*ast.TupleLiteralExpr); !ok || len(tuple.Elements) != 2 {
+		t.Errorf("return value = %#v, want a tuple of two", ret.Value)
+	}
+}
@@ -6,65 +6,90 @@ import (
 	"github.com/omni-lang/omni/internal/mir"
 )
 
-// ConstFold performs simple constant folding over arithmetic and comparison instructions.
-func ConstFold(mod *mir.Module) {
+// ConstantFoldingPass evaluates arithmetic and comparisons whose operands
+// are all literals at compile time, replacing each with a const. A
+// conditional branch on a folded condition becomes an unconditional one;
+// DeadCodeEliminationPass removes the blocks this leaves unreachable.
+type ConstantFoldingPass struct{}
+
+// Name implements Pass.
+func (ConstantFoldingPass) Name() string { return "constfold" }
+
+// Run implements Pass.
+func (ConstantFoldingPass) Run(mod *mir.Module) error {
 	if mod == nil {
-		return
+		return nil
 	}
 	for _, fn := range mod.Functions {
 		foldFunction(fn)
 	}
+	return nil
+}
+
+// ConstFold runs ConstantFoldingPass over mod.
+func ConstFold(mod *mir.Module) {
+	_ = ConstantFoldingPass{}.Run(mod)
 }
 
 func foldFunction(fn *mir.Function) {
-	constValues := make(map[mir.ValueID]mir.Instruction)
-	// Track which variables are modified by assign instructions
+	// Variables written by assign instructions are not SSA values, so their
+	// const definition is not the value every use sees
 	modifiedVars := make(map[mir.ValueID]bool)
-
-	// First pass: identify all variables that are modified by assign instructions
 	for _, block := range fn.Blocks {
 		for _, inst := range block.Instructions {
-			if inst.Op == "assign" && len(inst.Operands) > 0 {
-				// The first operand is the target variable being assigned to
-				if inst.Operands[0].Kind == mir.OperandValue {
-					modifiedVars[inst.Operands[0].Value] = true
-				}
+			if inst.Op == "assign" && len(inst.Operands) > 0 && inst.Operands[0].Kind == mir.OperandValue {
+				modifiedVars[inst.Operands[0].Value] = true
 			}
 		}
 	}
 
-	// Second pass: perform constant folding, but avoid folding expressions
-	// that involve variables that are modified by assign instructions
 	for _, block := range fn.Blocks {
+		// foldMap holds the values of this block known to be a literal
+		foldMap := make(map[mir.ValueID]mir.Operand)
 		for i := range block.Instructions {
 			inst := &block.Instructions[i]
-			if inst.ID != mir.InvalidValue && inst.Op == "const" {
-				constValues[inst.ID] = *inst
+			if inst.ID == mir.InvalidValue || modifiedVars[inst.ID] {
 				continue
 			}
 			switch inst.Op {
-			case "add", "sub", "mul", "div", "mod", "cmp.eq", "cmp.neq", "cmp.lt", "cmp.lte", "cmp.gt", "cmp.gte", "and", "or":
-				// Check if any operands are modified variables
-				hasModifiedVar := false
-				for _, op := range inst.Operands {
-					if op.Kind == mir.OperandValue && modifiedVars[op.Value] {
-						hasModifiedVar = true
-						break
-					}
+			case "const":
+				if len(inst.Operands) == 1 && inst.Operands[0].Kind == mir.OperandLiteral {
+					foldMap[inst.ID] = inst.Operands[0]
 				}
-				// Only fold if no modified variables are involved
-				if !hasModifiedVar {
-					if folded, ok := foldBinary(inst, constValues); ok {
-						block.Instructions[i] = folded
-						constValues[folded.ID] = folded
-					}
+			case "add", "sub", "mul", "div", "mod", "cmp.eq", "cmp.neq", "cmp.lt", "cmp.lte", "cmp.gt", "cmp.gte", "and", "or":
+				if result, ok := foldBinary(inst, foldMap); ok {
+					result.Location = inst.Location
+					*inst = result
+					foldMap[inst.ID] = result.Operands[0]
 				}
 			}
 		}
+		foldBranch(&block.Terminator, foldMap)
+	}
+}
+
+// foldBranch turns a conditional branch on a literal condition into an
+// unconditional branch to the block it always takes.
+func foldBranch(term *mir.Terminator, foldMap map[mir.ValueID]mir.Operand) {
+	if term.Op != "cbr" || len(term.Operands) != 3 {
+		return
+	}
+	cond, ok := constantOperand(term.Operands[0], foldMap)
+	if !ok {
+		return
+	}
+	taken, ok := boolLiteral(cond)
+	if !ok {
+		return
+	}
+	target := term.Operands[2]
+	if taken {
+		target = term.Operands[1]
 	}
+	*term = mir.Terminator{Op: "br", Operands: []mir.Operand{target}, Location: term.Location}
 }
 
-func foldBinary(inst *mir.Instruction, consts map[mir.ValueID]mir.Instruction) (mir.Instruction, bool) {
+func foldBinary(inst *mir.Instruction, consts map[mir.ValueID]mir.Operand) (mir.Instruction, bool) {
 	if len(inst.Operands) < 2 {
 		return mir.Instruction{}, false
 	}
@@ -76,82 +101,119 @@ func foldBinary(inst *mir.Instruction, consts map[mir.ValueID]mir.Instruction) (
 	if !ok {
 		return mir.Instruction{}, false
 	}
-	// Only handle integer arithmetic/comparisons for now.
-	li, err := strconv.Atoi(left.Literal)
-	if err != nil {
+	if inst.Op == "and" || inst.Op == "or" {
+		lb, ok := boolLiteral(left)
+		if !ok {
+			return mir.Instruction{}, false
+		}
+		rb, ok := boolLiteral(right)
+		if !ok {
+			return mir.Instruction{}, false
+		}
+		value := lb && rb
+		if inst.Op == "or" {
+			value = lb || rb
+		}
+		return boolConst(inst.ID, value), true
+	}
+	// Only handle signed integer arithmetic/comparisons for now.
+	li, ok := intLiteral(left)
+	if !ok {
 		return mir.Instruction{}, false
 	}
-	ri, err := strconv.Atoi(right.Literal)
-	if err != nil {
+	ri, ok := intLiteral(right)
+	if !ok {
 		return mir.Instruction{}, false
 	}
-	result := mir.Instruction{ID: inst.ID, Op: "const"}
+	var value int64
 	switch inst.Op {
 	case "add":
-		result.Type = inst.Type
-		result.Operands = []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.Itoa(li + ri), Type: inst.Type}}
+		value = li + ri
 	case "sub":
-		result.Type = inst.Type
-		result.Operands = []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.Itoa(li - ri), Type: inst.Type}}
+		value = li - ri
 	case "mul":
-		result.Type = inst.Type
-		result.Operands = []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.Itoa(li * ri), Type: inst.Type}}
+		value = li * ri
 	case "div":
 		if ri == 0 {
 			return mir.Instruction{}, false
 		}
-		result.Type = inst.Type
-		result.Operands = []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.Itoa(li / ri), Type: inst.Type}}
+		value = li / ri
 	case "mod":
 		if ri == 0 {
 			return mir.Instruction{}, false
 		}
-		result.Type = inst.Type
-		result.Operands = []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.Itoa(li % ri), Type: inst.Type}}
+		value = li % ri
 	case "cmp.eq":
-		result.Type = "bool"
-		result.Operands = []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.FormatBool(li == ri), Type: "bool"}}
+		return boolConst(inst.ID, li == ri), true
 	case "cmp.neq":
-		result.Type = "bool"
-		result.Operands = []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.FormatBool(li != ri), Type: "bool"}}
+		return boolConst(inst.ID, li != ri), true
 	case "cmp.lt":
-		result.Type = "bool"
-		result.Operands = []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.FormatBool(li < ri), Type: "bool"}}
+		return boolConst(inst.ID, li < ri), true
 	case "cmp.lte":
-		result.Type = "bool"
-		result.Operands = []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.FormatBool(li <= ri), Type: "bool"}}
+		return boolConst(inst.ID, li <= ri), true
 	case "cmp.gt":
-		result.Type = "bool"
-		result.Operands = []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.FormatBool(li > ri), Type: "bool"}}
+		return boolConst(inst.ID, li > ri), true
 	case "cmp.gte":
-		result.Type = "bool"
-		result.Operands = []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.FormatBool(li >= ri), Type: "bool"}}
-	case "and":
-		result.Type = "bool"
-		result.Operands = []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.FormatBool(li != 0 && ri != 0), Type: "bool"}}
-	case "or":
-		result.Type = "bool"
-		result.Operands = []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.FormatBool(li != 0 || ri != 0), Type: "bool"}}
+		return boolConst(inst.ID, li >= ri), true
 	default:
 		return mir.Instruction{}, false
 	}
-	return result, true
+	// An int result outside the 32-bit range is left to run time, where each
+	// backend overflows in its own way and -overflow-check can report it
+	if inst.Type == "int" && value != int64(int32(value)) {
+		return mir.Instruction{}, false
+	}
+	return mir.Instruction{
+		ID:       inst.ID,
+		Op:       "const",
+		Type:     inst.Type,
+		Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.FormatInt(value, 10), Type: inst.Type}},
+	}, true
+}
+
+// intLiteral returns the value of a decimal int or int64 literal. An untyped
+// literal is taken to be an int.
+func intLiteral(op mir.Operand) (int64, bool) {
+	switch op.Type {
+	case "", "int", "int64", "long":
+	default:
+		return 0, false
+	}
+	v, err := strconv.ParseInt(op.Literal, 10, 64)
+	return v, err == nil
+}
+
+// boolLiteral returns the truth value of a bool literal, or of an int
+// literal compared against zero.
+func boolLiteral(op mir.Operand) (bool, bool) {
+	switch op.Literal {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	}
+	v, ok := intLiteral(op)
+	return v != 0, ok
 }
 
-func constantOperand(op mir.Operand, consts map[mir.ValueID]mir.Instruction) (mir.Operand, bool) {
+func boolConst(id mir.ValueID, value bool) mir.Instruction {
+	return mir.Instruction{
+		ID:       id,
+		Op:       "const",
+		Type:     "bool",
+		Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: strconv.FormatBool(value), Type: "bool"}},
+	}
+}
+
+// constantOperand returns op itself when it is a literal, or the literal
+// the value it names was folded to.
+func constantOperand(op mir.Operand, consts map[mir.ValueID]mir.Operand) (mir.Operand, bool) {
 	switch op.Kind {
 	case mir.OperandLiteral:
 		return op, true
 	case mir.OperandValue:
-		inst, ok := consts[op.Value]
-		if !ok || inst.Op != "const" || len(inst.Operands) == 0 {
-			return mir.Operand{}, false
-		}
-		operand := inst.Operands[0]
-		if operand.Kind != mir.OperandLiteral {
-			return mir.Operand{}, false
-		}
-		return operand, true
+		operand, ok := consts[op.Value]
+		return operand, ok
 	default:
 		return mir.Operand{}, false
 	}
@@ -2,10 +2,15 @@ package passes_test
 
 import (
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/mir/builder"
+	mirprinter "github.com/omni-lang/omni/internal/mir/printer"
+	"github.com/omni-lang/omni/internal/parser"
 	"github.com/omni-lang/omni/internal/passes"
+	"github.com/omni-lang/omni/internal/vm"
 )
 
 func TestConstFoldArithmetic(t *testing.T) {
@@ -344,3 +349,145 @@ func TestConstFoldNonInteger(t *testing.T) {
 		t.Fatalf("expected add to remain (non-integer), got %s", folded.Op)
 	}
 }
+
+// foldSource lowers src to MIR and returns it printed before and after
+// running ConstantFoldingPass.
+func foldSource(t *testing.T, src string) (string, string) {
+	t.Helper()
+	astMod, err := parser.Parse("test.omni", src)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	mod, err := builder.BuildModule(astMod)
+	if err != nil {
+		t.Fatalf("build MIR: %v", err)
+	}
+	before := mirprinter.Format(mod)
+	if err := (passes.ConstantFoldingPass{}).Run(mod); err != nil {
+		t.Fatalf("constant folding: %v", err)
+	}
+	if err := passes.Verify(mod); err != nil {
+		t.Fatalf("folded MIR does not verify: %v", err)
+	}
+	return before, mirprinter.Format(mod)
+}
+
+func TestConstantFoldingPassArithmetic(t *testing.T) {
+	before, after := foldSource(t, "func f():int => (2 + 3) * 4 - 6 / 2\n")
+
+	wantBefore := `func f():int
+  block entry:
+    %0 = const.int 2:int
+    %1 = const.int 3:int
+    %2 = add.int %0, %1
+    %3 = const.int 4:int
+    %4 = mul.int %2, %3
+    %5 = const.int 6:int
+    %6 = const.int 2:int
+    %7 = div.int %5, %6
+    %8 = sub.int %4, %7
+    ret %8
+`
+	wantAfter := `func f():int
+  block entry:
+    %0 = const.int 2:int
+    %1 = const.int 3:int
+    %2 = const.int 5:int
+    %3 = const.int 4:int
+    %4 = const.int 20:int
+    %5 = const.int 6:int
+    %6 = const.int 2:int
+    %7 = const.int 3:int
+    %8 = const.int 17:int
+    ret %8
+`
+	if strings.TrimSpace(before) != strings.TrimSpace(wantBefore) {
+		t.Errorf("MIR before folding:\n%s\nwant:\n%s", before, wantBefore)
+	}
+	if strings.TrimSpace(after) != strings.TrimSpace(wantAfter) {
+		t.Errorf("MIR after folding:\n%s\nwant:\n%s", after, wantAfter)
+	}
+}
+
+func TestConstantFoldingPassFoldsConstantBranch(t *testing.T) {
+	_, after := foldSource(t, "func f():int {\n    if 1 < 2 {\n        return 2 * 3 + 4\n    }\n    return 0\n}\n")
+
+	want := `func f():int
+  block entry:
+    %0 = const.int 1:int
+    %1 = const.int 2:int
+    %2 = const.bool true:bool
+    br then_0
+  block then_0:
+    %3 = const.int 2:int
+    %4 = const.int 3:int
+    %5 = const.int 6:int
+    %6 = const.int 4:int
+    %7 = const.int 10:int
+    ret %7
+  block merge_1:
+    %8 = const.int 0:int
+    ret %8
+`
+	if strings.TrimSpace(after) != strings.TrimSpace(want) {
+		t.Errorf("MIR after folding:\n%s\nwant:\n%s", after, want)
+	}
+}
+
+func TestConstantFoldingPassKeepsBranchOnVariable(t *testing.T) {
+	before, after := foldSource(t, "func f(x:int):int {\n    if x < 2 {\n        return 1\n    }\n    return 0\n}\n")
+	if before != after {
+		t.Errorf("branch on a parameter should not fold:\n%s", after)
+	}
+}
+
+func TestConstantFoldingPassKeepsIntOverflow(t *testing.T) {
+	before, after := foldSource(t, "func f():int => 2147483647 + 1\n")
+	if before != after {
+		t.Errorf("int overflow should be left to run time:\n%s", after)
+	}
+}
+
+// TestConstantFoldingPassMatchesVM runs programs with and without folding
+// and expects the same result from the VM.
+func TestConstantFoldingPassMatchesVM(t *testing.T) {
+	sources := []string{
+		"func main():int => (2 + 3) * 4 - 6 / 2 % 4\n",
+		"func main():int => 2147483647 + 1\n",
+		"func main():int => 2147483647 * 2\n",
+		"func main():int => -2147483647 - 2\n",
+		"func main():int {\n    let a:int = 2147483647\n    return a + 1\n}\n",
+		"func main():int64 => (int64)4611686018427387904 * (int64)2\n",
+	}
+	for _, src := range sources {
+		run := func(fold bool) interface{} {
+			t.Helper()
+			astMod, err := parser.Parse("test.omni", src)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			mod, err := builder.BuildModule(astMod)
+			if err != nil {
+				t.Fatalf("build MIR: %v", err)
+			}
+			if fold {
+				passes.ConstFold(mod)
+			}
+			res, err := vm.Execute(mod, "main")
+			if err != nil {
+				t.Fatalf("execute %q: %v", src, err)
+			}
+			return res.Value
+		}
+		if folded, unfolded := run(true), run(false); folded != unfolded {
+			t.Errorf("%q: folded result %v, unfolded %v", src, folded, unfolded)
+		}
+	}
+}
+
+func TestConstantFoldingPassSkipsUnsigned(t *testing.T) {
+	before, after := foldSource(t, "func f():uint8 => (uint8)250 + (uint8)10\n")
+	if before != after {
+		t.Errorf("unsigned arithmetic should not fold:\n%s", after)
+	}
+}
@@ -0,0 +1,108 @@
+package passes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// CSEPass eliminates common subexpressions within each basic block: an
+// instruction that repeats the opcode, type and operands of an earlier one
+// in the same block is removed and its uses read the earlier value instead.
+// Only instructions without side effects are merged, so calls, file
+// operations and the allocating map.init and struct.init always stay.
+type CSEPass struct{}
+
+// Name implements Pass.
+func (CSEPass) Name() string { return "cse" }
+
+// Run implements Pass.
+func (CSEPass) Run(mod *mir.Module) error {
+	if mod == nil {
+		return nil
+	}
+	for _, fn := range mod.Functions {
+		if fn != nil {
+			eliminateCommonSubexpressions(fn)
+		}
+	}
+	return nil
+}
+
+// cseOps are the instructions whose result depends only on their operands.
+// Division and casts can trap, but a repeat of one that did not trap will
+// not either. Indexing and member access read memory that may change in
+// between, so they are not merged.
+var cseOps = map[string]bool{
+	"const": true, "add": true, "sub": true, "mul": true, "div": true, "mod": true,
+	"udiv": true, "umod": true, "neg": true, "not": true,
+	"bitnot": true, "bitand": true, "bitor": true, "bitxor": true, "lshift": true, "rshift": true,
+	"cmp.eq": true, "cmp.neq": true, "cmp.lt": true, "cmp.lte": true, "cmp.gt": true, "cmp.gte": true,
+	"and": true, "or": true, "strcat": true, "cast": true, "func.ref": true,
+}
+
+func eliminateCommonSubexpressions(fn *mir.Function) {
+	// Variables written by assign instructions are not SSA values: two
+	// reads of one may see different values, and an instruction whose
+	// result is reassigned does not keep the value it computed
+	modifiedVars := make(map[mir.ValueID]bool)
+	for _, block := range fn.Blocks {
+		for _, inst := range block.Instructions {
+			if inst.Op == "assign" && len(inst.Operands) > 0 && inst.Operands[0].Kind == mir.OperandValue {
+				modifiedVars[inst.Operands[0].Value] = true
+			}
+		}
+	}
+
+	replacements := make(map[mir.ValueID]mir.Operand)
+	for _, block := range fn.Blocks {
+		// available maps the key of each expression computed so far in this
+		// block to the value that holds it
+		available := make(map[string]mir.ValueID)
+		kept := block.Instructions[:0]
+		for _, inst := range block.Instructions {
+			key, ok := cseKey(inst, modifiedVars, replacements)
+			if !ok {
+				kept = append(kept, inst)
+				continue
+			}
+			if earlier, ok := available[key]; ok {
+				replacements[inst.ID] = mir.Operand{Kind: mir.OperandValue, Value: earlier, Type: inst.Type}
+				continue
+			}
+			available[key] = inst.ID
+			kept = append(kept, inst)
+		}
+		block.Instructions = kept
+	}
+	if len(replacements) > 0 {
+		replaceUses(fn, replacements)
+	}
+}
+
+// cseKey returns the key under which inst is available to later
+// instructions, with operands read through the replacements made so far.
+func cseKey(inst mir.Instruction, modifiedVars map[mir.ValueID]bool, replacements map[mir.ValueID]mir.Operand) (string, bool) {
+	if !cseOps[inst.Op] || inst.ID == mir.InvalidValue || modifiedVars[inst.ID] {
+		return "", false
+	}
+	var key strings.Builder
+	key.WriteString(inst.Op)
+	key.WriteByte('.')
+	key.WriteString(inst.Type)
+	for _, op := range inst.Operands {
+		if op.Kind == mir.OperandValue {
+			if repl, ok := replacements[op.Value]; ok {
+				op.Value = repl.Value
+			}
+			if modifiedVars[op.Value] {
+				return "", false
+			}
+			fmt.Fprintf(&key, " %%%d", op.Value)
+		} else {
+			fmt.Fprintf(&key, " %q:%s", op.Literal, op.Type)
+		}
+	}
+	return key.String(), true
+}
@@ -0,0 +1,66 @@
+package passes_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir/builder"
+	mirprinter "github.com/omni-lang/omni/internal/mir/printer"
+	"github.com/omni-lang/omni/internal/parser"
+	"github.com/omni-lang/omni/internal/passes"
+)
+
+// cseSource builds src and runs CSEPass over it.
+func cseSource(t *testing.T, src string) string {
+	t.Helper()
+	astMod, err := parser.Parse("test.omni", src)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	mod, err := builder.BuildModule(astMod)
+	if err != nil {
+		t.Fatalf("build MIR: %v", err)
+	}
+	if err := (passes.CSEPass{}).Run(mod); err != nil {
+		t.Fatalf("cse: %v", err)
+	}
+	if err := passes.Verify(mod); err != nil {
+		t.Fatalf("MIR does not verify after cse: %v", err)
+	}
+	return mirprinter.Format(mod)
+}
+
+func TestCSEPassMergesRepeatedAdd(t *testing.T) {
+	got := cseSource(t, "func f(x:int, y:int):int {\n  let a:int = x + y\n  let z:int = x + y\n  return a * z\n}\n")
+
+	want := `func f(x:int,y:int):int
+  block entry:
+    %2 = add.int %0, %1
+    %4 = mul.int %2, %2
+    ret %4
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCSEPassKeepsCalls(t *testing.T) {
+	got := cseSource(t, "func g(x:int):int => x\nfunc f(x:int):int => g(x) + g(x)\n")
+	if n := strings.Count(got, "call.int g"); n != 2 {
+		t.Errorf("expected both calls to stay, found %d in:\n%s", n, got)
+	}
+}
+
+func TestCSEPassStaysWithinBlock(t *testing.T) {
+	got := cseSource(t, "func f(x:int, y:int, c:bool):int {\n  let a:int = x + y\n  if c {\n    return x + y\n  }\n  return a\n}\n")
+	if n := strings.Count(got, "add.int %0, %1"); n != 2 {
+		t.Errorf("expected the add in each block to stay, found %d in:\n%s", n, got)
+	}
+}
+
+func TestCSEPassSkipsReassignedVariables(t *testing.T) {
+	got := cseSource(t, "func f(x:int, y:int):int {\n  var a:int = x + y\n  a = 1\n  let b:int = x + y\n  return a + b\n}\n")
+	if n := strings.Count(got, "add.int %0, %1"); n != 2 {
+		t.Errorf("expected the add stored in a reassigned variable to stay, found %d in:\n%s", n, got)
+	}
+}
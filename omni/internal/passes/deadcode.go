@@ -0,0 +1,216 @@
+package passes
+
+import "github.com/omni-lang/omni/internal/mir"
+
+// DeadCodeEliminationPass removes the basic blocks that cannot be reached
+// from the entry block, then the side-effect-free instructions whose value
+// is never used. Phis lose the operands of removed predecessors; a phi left
+// with a single incoming value is replaced by that value.
+type DeadCodeEliminationPass struct{}
+
+// Name implements Pass.
+func (DeadCodeEliminationPass) Name() string { return "dce" }
+
+// Run implements Pass.
+func (DeadCodeEliminationPass) Run(mod *mir.Module) error {
+	if mod == nil {
+		return nil
+	}
+	for _, fn := range mod.Functions {
+		if fn == nil || len(fn.Blocks) == 0 {
+			continue
+		}
+		removeUnreachableBlocks(fn)
+		removeDeadInstructions(fn)
+	}
+	return nil
+}
+
+// pureOps are the instructions without side effects, which can be dropped
+// when nothing uses their value. Division, casts, indexing, member access and
+// opt.unwrap can trap, and calls may do anything, so they always stay.
+var pureOps = map[string]bool{
+	"const": true, "add": true, "sub": true, "mul": true, "neg": true, "not": true,
+	"bitnot": true, "bitand": true, "bitor": true, "bitxor": true, "lshift": true, "rshift": true,
+	"cmp.eq": true, "cmp.neq": true, "cmp.lt": true, "cmp.lte": true, "cmp.gt": true, "cmp.gte": true,
+	"and": true, "or": true, "strcat": true, "phi": true, "func.ref": true,
+	"opt.some": true, "opt.none": true, "opt.unwrap_or": true, "opt.is_some": true, "opt.is_none": true,
+	"interface.init": true, "global.load": true,
+}
+
+// removeUnreachableBlocks drops the blocks that a breadth-first walk of the
+// CFG from the entry block does not reach, and updates the phis of the
+// remaining blocks.
+func removeUnreachableBlocks(fn *mir.Function) {
+	byName := make(map[string]*mir.BasicBlock, len(fn.Blocks))
+	for _, block := range fn.Blocks {
+		byName[block.Name] = block
+	}
+	reachable := map[string]bool{fn.Blocks[0].Name: true}
+	queue := []*mir.BasicBlock{fn.Blocks[0]}
+	for len(queue) > 0 {
+		block := queue[0]
+		queue = queue[1:]
+		for _, succ := range append(successors(block.Terminator), handlerBlocks(block)...) {
+			if next, ok := byName[succ]; ok && !reachable[succ] {
+				reachable[succ] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	if len(reachable) == len(fn.Blocks) {
+		return
+	}
+
+	kept := fn.Blocks[:0]
+	for _, block := range fn.Blocks {
+		if reachable[block.Name] {
+			kept = append(kept, block)
+		}
+	}
+	fn.Blocks = kept
+
+	replacements := make(map[mir.ValueID]mir.Operand)
+	for _, block := range fn.Blocks {
+		for i := range block.Instructions {
+			inst := &block.Instructions[i]
+			if inst.Op != "phi" || !hasBlockPairs(*inst) {
+				continue
+			}
+			var operands []mir.Operand
+			for j := 0; j+1 < len(inst.Operands); j += 2 {
+				if reachable[inst.Operands[j+1].Literal] {
+					operands = append(operands, inst.Operands[j], inst.Operands[j+1])
+				}
+			}
+			inst.Operands = operands
+			if len(operands) != 2 {
+				continue
+			}
+			// A single incoming value: the phi is a copy of it
+			value := operands[0]
+			if value.Kind == mir.OperandLiteral {
+				*inst = mir.Instruction{ID: inst.ID, Op: "const", Type: inst.Type, Operands: []mir.Operand{value}, Location: inst.Location}
+			} else {
+				replacements[inst.ID] = value
+			}
+		}
+	}
+	if len(replacements) > 0 {
+		replaceUses(fn, replacements)
+	}
+}
+
+// successors returns the names of the blocks term can branch to.
+func successors(term mir.Terminator) []string {
+	var names []string
+	switch term.Op {
+	case "br", "loop.break", "loop.continue":
+		for _, op := range term.Operands {
+			names = append(names, op.Literal)
+		}
+	case "cbr":
+		for _, op := range term.Operands[1:] {
+			names = append(names, op.Literal)
+		}
+	}
+	return names
+}
+
+// handlerBlocks returns the names of the blocks that the try.enter
+// instructions of block send exceptions to.
+func handlerBlocks(block *mir.BasicBlock) []string {
+	var names []string
+	for _, inst := range block.Instructions {
+		if inst.Op != "try.enter" {
+			continue
+		}
+		for i := 1; i < len(inst.Operands); i += 2 {
+			names = append(names, inst.Operands[i].Literal)
+		}
+	}
+	return names
+}
+
+// hasBlockPairs reports whether the operands of a phi come in (value, block)
+// pairs.
+func hasBlockPairs(inst mir.Instruction) bool {
+	if len(inst.Operands) == 0 || len(inst.Operands)%2 != 0 {
+		return false
+	}
+	for i := 1; i < len(inst.Operands); i += 2 {
+		if inst.Operands[i].Kind != mir.OperandLiteral {
+			return false
+		}
+	}
+	return true
+}
+
+// replaceUses rewrites the uses of each value in replacements to its
+// replacement operand. The defining instructions, now unused, are left for
+// removeDeadInstructions.
+func replaceUses(fn *mir.Function, replacements map[mir.ValueID]mir.Operand) {
+	resolve := func(op mir.Operand) mir.Operand {
+		// Follow chains of copies, such as a phi of a phi
+		for op.Kind == mir.OperandValue {
+			next, ok := replacements[op.Value]
+			if !ok || next.Kind == mir.OperandValue && next.Value == op.Value {
+				break
+			}
+			if next.Type == "" {
+				next.Type = op.Type
+			}
+			op = next
+		}
+		return op
+	}
+	for _, block := range fn.Blocks {
+		for i := range block.Instructions {
+			for j, op := range block.Instructions[i].Operands {
+				block.Instructions[i].Operands[j] = resolve(op)
+			}
+		}
+		for j, op := range block.Terminator.Operands {
+			block.Terminator.Operands[j] = resolve(op)
+		}
+	}
+}
+
+// removeDeadInstructions drops the pure instructions whose value no
+// instruction or terminator uses, repeating until the operands of the
+// removed ones that became unused are gone too.
+func removeDeadInstructions(fn *mir.Function) {
+	for {
+		used := make(map[mir.ValueID]bool)
+		for _, block := range fn.Blocks {
+			for _, inst := range block.Instructions {
+				markUses(used, inst.Operands)
+			}
+			markUses(used, block.Terminator.Operands)
+		}
+
+		removed := false
+		for _, block := range fn.Blocks {
+			kept := block.Instructions[:0]
+			for _, inst := range block.Instructions {
+				if inst.ID != mir.InvalidValue && !used[inst.ID] && pureOps[inst.Op] {
+					removed = true
+					continue
+				}
+				kept = append(kept, inst)
+			}
+			block.Instructions = kept
+		}
+		if !removed {
+			return
+		}
+	}
+}
+
+func markUses(used map[mir.ValueID]bool, operands []mir.Operand) {
+	for _, op := range operands {
+		if op.Kind == mir.OperandValue {
+			used[op.Value] = true
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package passes_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/mir/builder"
+	mirprinter "github.com/omni-lang/omni/internal/mir/printer"
+	"github.com/omni-lang/omni/internal/parser"
+	"github.com/omni-lang/omni/internal/passes"
+)
+
+func TestDeadCodeEliminationRemovesElseOfAlwaysTrueIf(t *testing.T) {
+	src := "func f(x:int):int {\n    if true {\n        return x + 1\n    } else {\n        return x - 1\n    }\n}\n"
+	astMod, err := parser.Parse("test.omni", src)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	mod, err := builder.BuildModule(astMod)
+	if err != nil {
+		t.Fatalf("build MIR: %v", err)
+	}
+	result, err := passes.NewPipeline("test").Run(*mod)
+	if err != nil {
+		t.Fatalf("pipeline: %v", err)
+	}
+
+	got := mirprinter.Format(&result)
+	want := `func f(x:int):int
+  block entry:
+    br then_0
+  block then_0:
+    %2 = const.int 1:int
+    %3 = add.int %0, %2
+    ret %3
+`
+	if strings.TrimSpace(got) != strings.TrimSpace(want) {
+		t.Errorf("MIR after the pipeline:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// diamond builds a function whose entry branches on cond to then or other,
+// both of which jump to merge, where a phi picks 1 or the parameter.
+func diamond(cond string) (*mir.Function, mir.ValueID) {
+	fn := mir.NewFunction("main", "int", []mir.Param{{Name: "x", Type: "int", ID: 0}})
+	fn.NextValue()
+	entry := fn.NewBlock("entry")
+	then := fn.NewBlock("then")
+	other := fn.NewBlock("other")
+	merge := fn.NewBlock("merge")
+
+	entry.Terminator = mir.Terminator{Op: "cbr", Operands: []mir.Operand{
+		{Kind: mir.OperandLiteral, Literal: cond, Type: "bool"},
+		{Kind: mir.OperandLiteral, Literal: "then"},
+		{Kind: mir.OperandLiteral, Literal: "other"},
+	}}
+	then.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "merge"}}}
+	other.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "merge"}}}
+	phi := fn.NextValue()
+	merge.Instructions = append(merge.Instructions, mir.Instruction{
+		ID: phi, Op: "phi", Type: "int",
+		Operands: []mir.Operand{
+			{Kind: mir.OperandLiteral, Literal: "1", Type: "int"},
+			{Kind: mir.OperandLiteral, Literal: "then"},
+			{Kind: mir.OperandValue, Value: 0, Type: "int"},
+			{Kind: mir.OperandLiteral, Literal: "other"},
+		},
+	})
+	merge.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: phi, Type: "int"}}}
+	return fn, phi
+}
+
+func runDCE(t *testing.T, fn *mir.Function) {
+	t.Helper()
+	mod := &mir.Module{Functions: []*mir.Function{fn}}
+	if err := (passes.ConstantFoldingPass{}).Run(mod); err != nil {
+		t.Fatalf("constant folding: %v", err)
+	}
+	if err := (passes.DeadCodeEliminationPass{}).Run(mod); err != nil {
+		t.Fatalf("dead code elimination: %v", err)
+	}
+	if err := passes.Verify(mod); err != nil {
+		t.Fatalf("MIR does not verify: %v", err)
+	}
+}
+
+func blockNames(fn *mir.Function) string {
+	var names []string
+	for _, block := range fn.Blocks {
+		names = append(names, block.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestDeadCodeEliminationPhiWithLiteralBecomesConst(t *testing.T) {
+	fn, phi := diamond("true")
+	runDCE(t, fn)
+
+	if got := blockNames(fn); got != "entry,then,merge" {
+		t.Fatalf("blocks = %s, want entry,then,merge", got)
+	}
+	merge := fn.Blocks[2]
+	if len(merge.Instructions) != 1 {
+		t.Fatalf("merge instructions = %+v, want the phi only", merge.Instructions)
+	}
+	inst := merge.Instructions[0]
+	if inst.ID != phi || inst.Op != "const" || inst.Operands[0].Literal != "1" {
+		t.Errorf("phi = %+v, want const 1", inst)
+	}
+}
+
+func TestDeadCodeEliminationPhiWithValueIsReplaced(t *testing.T) {
+	fn, _ := diamond("false")
+	runDCE(t, fn)
+
+	if got := blockNames(fn); got != "entry,other,merge" {
+		t.Fatalf("blocks = %s, want entry,other,merge", got)
+	}
+	merge := fn.Blocks[2]
+	if len(merge.Instructions) != 0 {
+		t.Errorf("merge instructions = %+v, want the phi removed", merge.Instructions)
+	}
+	ret := merge.Terminator.Operands[0]
+	if ret.Kind != mir.OperandValue || ret.Value != 0 {
+		t.Errorf("ret operand = %+v, want the parameter %%0", ret)
+	}
+}
+
+func TestDeadCodeEliminationKeepsSideEffects(t *testing.T) {
+	fn := mir.NewFunction("main", "int", nil)
+	block := fn.NewBlock("entry")
+	unused := fn.NextValue()
+	block.Instructions = append(block.Instructions, mir.Instruction{
+		ID: unused, Op: "const", Type: "int",
+		Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "7", Type: "int"}},
+	})
+	call := fn.NextValue()
+	block.Instructions = append(block.Instructions, mir.Instruction{
+		ID: call, Op: "call", Type: "int",
+		Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "tick"}},
+	})
+	quotient := fn.NextValue()
+	block.Instructions = append(block.Instructions, mir.Instruction{
+		ID: quotient, Op: "div", Type: "int",
+		Operands: []mir.Operand{
+			{Kind: mir.OperandValue, Value: call, Type: "int"},
+			{Kind: mir.OperandLiteral, Literal: "0", Type: "int"},
+		},
+	})
+	block.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "0", Type: "int"}}}
+
+	runDCE(t, fn)
+
+	var ops []string
+	for _, inst := range block.Instructions {
+		ops = append(ops, inst.Op)
+	}
+	if got := strings.Join(ops, ","); got != "call,div" {
+		t.Errorf("instructions = %s, want call,div", got)
+	}
+}
@@ -0,0 +1,164 @@
+package passes
+
+import "github.com/omni-lang/omni/internal/mir"
+
+// DominatorsKey is the metadata key ComputeDominatorTreePass stores the
+// dominator tree of a function under.
+const DominatorsKey = "dominators"
+
+// DominatorTree records which basic blocks of a function dominate which: a
+// block dominates another when every path from the entry block to the other
+// passes through it. Blocks the entry block cannot reach are in no
+// dominance relation.
+type DominatorTree struct {
+	entry string
+	// dominators holds the set of blocks that dominate each reachable
+	// block, the block itself included
+	dominators map[string]map[string]bool
+	idom       map[string]string
+}
+
+// ComputeDominatorTree computes the dominator tree of fn with the iterative
+// data-flow algorithm: the entry block is dominated only by itself, and
+// every other block by itself and the blocks that dominate all of its
+// predecessors. Exception handlers count as successors of the blocks whose
+// try.enter names them.
+func ComputeDominatorTree(fn *mir.Function) *DominatorTree {
+	tree := &DominatorTree{dominators: make(map[string]map[string]bool), idom: make(map[string]string)}
+	if fn == nil || len(fn.Blocks) == 0 {
+		return tree
+	}
+	tree.entry = fn.Blocks[0].Name
+	order, preds := reachableBlocks(fn)
+
+	all := make(map[string]bool, len(order))
+	for _, name := range order {
+		all[name] = true
+	}
+	for _, name := range order {
+		if name == tree.entry {
+			tree.dominators[name] = map[string]bool{name: true}
+		} else {
+			tree.dominators[name] = copySet(all)
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, name := range order[1:] {
+			var dom map[string]bool
+			for _, pred := range preds[name] {
+				if dom == nil {
+					dom = copySet(tree.dominators[pred])
+					continue
+				}
+				for block := range dom {
+					if !tree.dominators[pred][block] {
+						delete(dom, block)
+					}
+				}
+			}
+			if dom == nil {
+				dom = make(map[string]bool)
+			}
+			dom[name] = true
+			if len(dom) != len(tree.dominators[name]) {
+				tree.dominators[name] = dom
+				changed = true
+			}
+		}
+	}
+
+	// The immediate dominator is the strict dominator that all the others
+	// dominate, which is the one with one dominator fewer than the block
+	for name, dom := range tree.dominators {
+		for block := range dom {
+			if block != name && len(tree.dominators[block]) == len(dom)-1 {
+				tree.idom[name] = block
+				break
+			}
+		}
+	}
+	return tree
+}
+
+// Dominates reports whether block a dominates block b. Every reachable block
+// dominates itself.
+func (t *DominatorTree) Dominates(a, b string) bool {
+	return t.dominators[b][a]
+}
+
+// ImmediateDominator returns the closest block that strictly dominates b, or
+// "" for the entry block and unreachable blocks.
+func (t *DominatorTree) ImmediateDominator(b string) string {
+	return t.idom[b]
+}
+
+// Dominators returns the dominator tree ComputeDominatorTreePass attached to
+// fn, computing it when the pass has not run.
+func Dominators(fn *mir.Function) *DominatorTree {
+	if tree, ok := fn.Metadata[DominatorsKey].(*DominatorTree); ok {
+		return tree
+	}
+	return ComputeDominatorTree(fn)
+}
+
+// ComputeDominatorTreePass attaches the dominator tree of every function to
+// its metadata under DominatorsKey. It runs after the passes that change
+// the control-flow graph, so that the tree describes the final one.
+type ComputeDominatorTreePass struct{}
+
+// Name implements Pass.
+func (ComputeDominatorTreePass) Name() string { return "dominators" }
+
+// Run implements Pass.
+func (ComputeDominatorTreePass) Run(mod *mir.Module) error {
+	if mod == nil {
+		return nil
+	}
+	for _, fn := range mod.Functions {
+		if fn == nil || len(fn.Blocks) == 0 {
+			continue
+		}
+		if fn.Metadata == nil {
+			fn.Metadata = make(map[string]any)
+		}
+		fn.Metadata[DominatorsKey] = ComputeDominatorTree(fn)
+	}
+	return nil
+}
+
+// reachableBlocks returns the names of the blocks reachable from the entry
+// block in breadth-first order, entry first, along with the reachable
+// predecessors of each.
+func reachableBlocks(fn *mir.Function) ([]string, map[string][]string) {
+	byName := make(map[string]*mir.BasicBlock, len(fn.Blocks))
+	for _, block := range fn.Blocks {
+		byName[block.Name] = block
+	}
+	entry := fn.Blocks[0].Name
+	order := []string{entry}
+	preds := make(map[string][]string)
+	seen := map[string]bool{entry: true}
+	for i := 0; i < len(order); i++ {
+		block := byName[order[i]]
+		for _, succ := range append(successors(block.Terminator), handlerBlocks(block)...) {
+			if _, ok := byName[succ]; !ok {
+				continue
+			}
+			preds[succ] = append(preds[succ], block.Name)
+			if !seen[succ] {
+				seen[succ] = true
+				order = append(order, succ)
+			}
+		}
+	}
+	return order, preds
+}
+
+func copySet(set map[string]bool) map[string]bool {
+	out := make(map[string]bool, len(set))
+	for k := range set {
+		out[k] = true
+	}
+	return out
+}
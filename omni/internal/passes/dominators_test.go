@@ -0,0 +1,99 @@
+package passes_test
+
+import (
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/passes"
+)
+
+func branch(target string) mir.Terminator {
+	return mir.Terminator{Op: "br", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: target}}}
+}
+
+func condBranch(then, other string) mir.Terminator {
+	return mir.Terminator{Op: "cbr", Operands: []mir.Operand{
+		{Kind: mir.OperandValue, Value: 0, Type: "bool"},
+		{Kind: mir.OperandLiteral, Literal: then},
+		{Kind: mir.OperandLiteral, Literal: other},
+	}}
+}
+
+func TestDominatorTreeOfDiamond(t *testing.T) {
+	fn, _ := diamond("true")
+	tree := passes.ComputeDominatorTree(fn)
+
+	for _, tc := range []struct {
+		a, b string
+		want bool
+	}{
+		{"entry", "merge", true},
+		{"entry", "then", true},
+		{"then", "then", true},
+		{"then", "merge", false},
+		{"other", "merge", false},
+		{"merge", "entry", false},
+	} {
+		if got := tree.Dominates(tc.a, tc.b); got != tc.want {
+			t.Errorf("Dominates(%s, %s) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+	for block, want := range map[string]string{"entry": "", "then": "entry", "other": "entry", "merge": "entry"} {
+		if got := tree.ImmediateDominator(block); got != want {
+			t.Errorf("ImmediateDominator(%s) = %q, want %q", block, got, want)
+		}
+	}
+}
+
+func TestDominatorTreeOfLoop(t *testing.T) {
+	// entry -> header; header -> body or exit; body -> latch or header;
+	// latch -> header; a dead block branches into the body
+	fn := mir.NewFunction("main", "void", []mir.Param{{Name: "c", Type: "bool"}})
+	fn.NewBlock("entry").Terminator = branch("header")
+	fn.NewBlock("header").Terminator = condBranch("body", "exit")
+	fn.NewBlock("body").Terminator = condBranch("latch", "header")
+	fn.NewBlock("latch").Terminator = branch("header")
+	fn.NewBlock("exit").Terminator = mir.Terminator{Op: "ret"}
+	fn.NewBlock("dead").Terminator = branch("body")
+	tree := passes.ComputeDominatorTree(fn)
+
+	for _, tc := range []struct {
+		a, b string
+		want bool
+	}{
+		{"header", "latch", true},
+		{"header", "exit", true},
+		{"body", "latch", true},
+		{"body", "header", false},
+		{"latch", "header", false},
+		{"dead", "body", false},
+		{"entry", "dead", false},
+	} {
+		if got := tree.Dominates(tc.a, tc.b); got != tc.want {
+			t.Errorf("Dominates(%s, %s) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+	for block, want := range map[string]string{"header": "entry", "body": "header", "latch": "body", "exit": "header", "dead": ""} {
+		if got := tree.ImmediateDominator(block); got != want {
+			t.Errorf("ImmediateDominator(%s) = %q, want %q", block, got, want)
+		}
+	}
+}
+
+func TestComputeDominatorTreePassAttachesTree(t *testing.T) {
+	fn, _ := diamond("true")
+	mod := &mir.Module{Functions: []*mir.Function{fn}}
+	if err := (passes.ComputeDominatorTreePass{}).Run(mod); err != nil {
+		t.Fatalf("dominators pass: %v", err)
+	}
+	tree, ok := fn.Metadata[passes.DominatorsKey].(*passes.DominatorTree)
+	if !ok {
+		t.Fatalf("function metadata has no dominator tree: %+v", fn.Metadata)
+	}
+	if passes.Dominators(fn) != tree {
+		t.Error("Dominators did not return the attached tree")
+	}
+	if !tree.Dominates("entry", "merge") {
+		t.Error("attached tree does not have entry dominating merge")
+	}
+}
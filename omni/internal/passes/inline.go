@@ -0,0 +1,354 @@
+package passes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// DefaultInlineThreshold is the instruction count below which InliningPass
+// inlines a function when no threshold is configured.
+const DefaultInlineThreshold = 10
+
+// InliningPass replaces calls to small module functions with a copy of the
+// callee's body. The block holding the call is split in two: the part
+// before the call branches to the copied entry block, each copied ret
+// branches to the part after it, and a phi there merges the returned values
+// into the call's result. Recursive functions are never inlined.
+type InliningPass struct {
+	// Threshold is the instruction count below which a function is
+	// inlined; 0 means DefaultInlineThreshold and a negative value
+	// disables inlining.
+	Threshold int
+}
+
+// Name implements Pass.
+func (InliningPass) Name() string { return "inline" }
+
+func (p InliningPass) threshold() int {
+	if p.Threshold == 0 {
+		return DefaultInlineThreshold
+	}
+	return p.Threshold
+}
+
+// Run implements Pass.
+func (p InliningPass) Run(mod *mir.Module) error {
+	if mod == nil || p.threshold() < 0 {
+		return nil
+	}
+	funcs := make(map[string]*mir.Function, len(mod.Functions))
+	for _, fn := range mod.Functions {
+		if fn != nil {
+			funcs[fn.Name] = fn
+		}
+	}
+	graph := buildCallGraph(mod)
+
+	// Decide against the original bodies, so that inlining into a function
+	// does not make it look bigger or smaller to its own callers. Standard
+	// library functions are left alone: the backends replace many of them
+	// with runtime intrinsics and never run their bodies.
+	inlinable := make(map[string]bool)
+	for name, fn := range funcs {
+		if strings.HasPrefix(name, "std.") {
+			continue
+		}
+		if canInline(fn, p.threshold()) && !graph.reaches(name, name) {
+			inlinable[name] = true
+		}
+	}
+	bodies := make(map[string]*mir.Function, len(inlinable))
+	for name := range inlinable {
+		bodies[name] = cloneFunction(funcs[name])
+	}
+
+	for _, fn := range mod.Functions {
+		if fn == nil {
+			continue
+		}
+		fn.SyncNextValue()
+		in := &inliner{caller: fn, bodies: bodies, graph: graph}
+		in.run()
+	}
+	return nil
+}
+
+// canInline reports whether fn is small enough to inline and has a body the
+// inliner can copy: its parameters must never be assigned, since they become
+// the caller's arguments. A function with defer statements is never
+// inlined, as its deferred calls would join those of the caller and
+// defer.run in the copied body would run the caller's too; nor is one with
+// try statements, whose handlers name blocks the copy renames.
+func canInline(fn *mir.Function, threshold int) bool {
+	if len(fn.Blocks) == 0 {
+		return false
+	}
+	params := make(map[mir.ValueID]bool, len(fn.Params))
+	for _, param := range fn.Params {
+		params[param.ID] = true
+	}
+	count := 0
+	for _, block := range fn.Blocks {
+		count += len(block.Instructions)
+		for _, inst := range block.Instructions {
+			if inst.Op == "assign" && len(inst.Operands) > 0 && inst.Operands[0].Kind == mir.OperandValue && params[inst.Operands[0].Value] {
+				return false
+			}
+			if inst.Op == "defer.push" || inst.Op == "try.enter" {
+				return false
+			}
+		}
+		switch block.Terminator.Op {
+		case "ret", "ret.named", "br", "cbr", "loop.break", "loop.continue", "unreachable":
+		default:
+			return false
+		}
+	}
+	return count < threshold
+}
+
+// callGraph maps each function to the module functions it calls.
+type callGraph map[string][]string
+
+func buildCallGraph(mod *mir.Module) callGraph {
+	graph := make(callGraph)
+	for _, fn := range mod.Functions {
+		if fn == nil {
+			continue
+		}
+		for _, block := range fn.Blocks {
+			for _, inst := range block.Instructions {
+				if callee, ok := inst.CallTarget(); ok {
+					graph[fn.Name] = append(graph[fn.Name], callee)
+				}
+			}
+		}
+	}
+	return graph
+}
+
+// reaches reports whether a chain of calls leads from the function from to
+// the function to.
+func (g callGraph) reaches(from, to string) bool {
+	seen := make(map[string]bool)
+	stack := append([]string(nil), g[from]...)
+	for len(stack) > 0 {
+		name := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if name == to {
+			return true
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		stack = append(stack, g[name]...)
+	}
+	return false
+}
+
+// cloneFunction copies the blocks of fn so that inlining into fn does not
+// change the body that is copied into its callers.
+func cloneFunction(fn *mir.Function) *mir.Function {
+	clone := *fn
+	clone.Blocks = make([]*mir.BasicBlock, len(fn.Blocks))
+	for i, block := range fn.Blocks {
+		copied := &mir.BasicBlock{Name: block.Name, Terminator: cloneTerminator(block.Terminator)}
+		for _, inst := range block.Instructions {
+			inst.Operands = append([]mir.Operand(nil), inst.Operands...)
+			copied.Instructions = append(copied.Instructions, inst)
+		}
+		clone.Blocks[i] = copied
+	}
+	return &clone
+}
+
+func cloneTerminator(term mir.Terminator) mir.Terminator {
+	term.Operands = append([]mir.Operand(nil), term.Operands...)
+	return term
+}
+
+// inliner inlines the calls of one caller.
+type inliner struct {
+	caller *mir.Function
+	bodies map[string]*mir.Function
+	graph  callGraph
+	count  int
+}
+
+func (in *inliner) run() {
+	// Blocks appended while inlining are visited too, so calls after the
+	// first one in a block are inlined in its continuation
+	for i := 0; i < len(in.caller.Blocks); i++ {
+		in.inlineFirstCall(i)
+	}
+}
+
+// inlineFirstCall inlines the first inlinable call in the block at index i.
+func (in *inliner) inlineFirstCall(i int) {
+	block := in.caller.Blocks[i]
+	for j, inst := range block.Instructions {
+		name, ok := inst.CallTarget()
+		if !ok {
+			continue
+		}
+		callee, ok := in.bodies[name]
+		if !ok || name == in.caller.Name || in.graph.reaches(name, in.caller.Name) {
+			continue
+		}
+		if len(inst.Operands)-1 != len(callee.Params) {
+			continue
+		}
+		in.inline(i, j, callee)
+		return
+	}
+}
+
+// inline replaces the call at index j of the block at index i with the body
+// of callee.
+func (in *inliner) inline(i, j int, callee *mir.Function) {
+	block := in.caller.Blocks[i]
+	call := block.Instructions[j]
+	prefix := fmt.Sprintf("inline_%d_%s_", in.count, labelName(callee.Name))
+	in.count++
+
+	// The continuation takes the instructions after the call and the
+	// original terminator
+	cont := &mir.BasicBlock{
+		Name:         prefix + "cont",
+		Instructions: append([]mir.Instruction(nil), block.Instructions[j+1:]...),
+		Terminator:   block.Terminator,
+	}
+	block.Instructions = block.Instructions[:j]
+
+	// Parameters become the arguments; literal arguments get a const first
+	values := make(map[mir.ValueID]mir.Operand)
+	for k, param := range callee.Params {
+		arg := call.Operands[k+1]
+		if arg.Kind == mir.OperandLiteral {
+			id := in.caller.NextValue()
+			block.Instructions = append(block.Instructions, mir.Instruction{
+				ID: id, Op: "const", Type: param.Type,
+				Operands: []mir.Operand{arg},
+				Location: call.Location,
+			})
+			arg = mir.Operand{Kind: mir.OperandValue, Value: id, Type: param.Type}
+		}
+		values[param.ID] = arg
+	}
+	for _, cb := range callee.Blocks {
+		for _, inst := range cb.Instructions {
+			if inst.ID != mir.InvalidValue {
+				if _, ok := values[inst.ID]; !ok {
+					values[inst.ID] = mir.Operand{Kind: mir.OperandValue, Value: in.caller.NextValue(), Type: inst.Type}
+				}
+			}
+		}
+	}
+	remap := func(op mir.Operand) mir.Operand {
+		if op.Kind != mir.OperandValue {
+			return op
+		}
+		mapped, ok := values[op.Value]
+		if !ok {
+			return op
+		}
+		if op.Type != "" {
+			mapped.Type = op.Type
+		}
+		return mapped
+	}
+	label := func(name string) string { return prefix + labelName(name) }
+	blockLabel := func(op mir.Operand) mir.Operand {
+		op.Literal = label(op.Literal)
+		return op
+	}
+
+	var returns []mir.Operand
+	var copied []*mir.BasicBlock
+	for _, cb := range callee.Blocks {
+		nb := &mir.BasicBlock{Name: label(cb.Name)}
+		for _, inst := range cb.Instructions {
+			out := inst
+			if inst.ID != mir.InvalidValue {
+				out.ID = values[inst.ID].Value
+			}
+			out.Operands = make([]mir.Operand, len(inst.Operands))
+			for k, op := range inst.Operands {
+				if inst.Op == "phi" && k%2 == 1 {
+					out.Operands[k] = blockLabel(op)
+				} else {
+					out.Operands[k] = remap(op)
+				}
+			}
+			nb.Instructions = append(nb.Instructions, out)
+		}
+		term := cb.Terminator
+		switch term.Op {
+		case "ret", "ret.named":
+			if len(term.Operands) > 0 {
+				returns = append(returns, remap(term.Operands[0]), mir.Operand{Kind: mir.OperandLiteral, Literal: nb.Name})
+			}
+			nb.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: cont.Name}}}
+		case "br", "loop.break", "loop.continue":
+			nb.Terminator = mir.Terminator{Op: term.Op, Operands: []mir.Operand{blockLabel(term.Operands[0])}}
+		case "cbr":
+			nb.Terminator = mir.Terminator{Op: "cbr", Operands: []mir.Operand{
+				remap(term.Operands[0]), blockLabel(term.Operands[1]), blockLabel(term.Operands[2]),
+			}}
+		case "unreachable":
+			nb.Terminator = term
+		}
+		nb.Terminator.Location = term.Location
+		copied = append(copied, nb)
+	}
+
+	if call.ID != mir.InvalidValue && len(returns) > 0 {
+		phi := mir.Instruction{ID: call.ID, Op: "phi", Type: call.Type, Operands: returns, Location: call.Location}
+		cont.Instructions = append([]mir.Instruction{phi}, cont.Instructions...)
+	}
+	block.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: copied[0].Name}}, Location: call.Location}
+
+	// Phis of the blocks the original terminator led to now come from the
+	// continuation
+	for _, succ := range successors(cont.Terminator) {
+		for _, b := range in.caller.Blocks {
+			if b.Name == succ {
+				renamePredecessor(b, block.Name, cont.Name)
+			}
+		}
+	}
+
+	blocks := append([]*mir.BasicBlock(nil), in.caller.Blocks[:i+1]...)
+	blocks = append(blocks, copied...)
+	blocks = append(blocks, cont)
+	in.caller.Blocks = append(blocks, in.caller.Blocks[i+1:]...)
+}
+
+// renamePredecessor rewrites the phi operands of block that name the
+// predecessor from to name to instead.
+func renamePredecessor(block *mir.BasicBlock, from, to string) {
+	for i, inst := range block.Instructions {
+		if inst.Op != "phi" || !hasBlockPairs(inst) {
+			continue
+		}
+		for k := 1; k < len(inst.Operands); k += 2 {
+			if inst.Operands[k].Literal == from {
+				block.Instructions[i].Operands[k].Literal = to
+			}
+		}
+	}
+}
+
+// labelName turns a function or block name into one usable inside a block
+// name, which the C backend emits as a label.
+func labelName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,115 @@
+package passes_test
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir/builder"
+	mirprinter "github.com/omni-lang/omni/internal/mir/printer"
+	"github.com/omni-lang/omni/internal/parser"
+	"github.com/omni-lang/omni/internal/passes"
+	"github.com/omni-lang/omni/internal/testutil/snapshots"
+	"github.com/omni-lang/omni/internal/types/checker"
+)
+
+// TestMIRGoldens runs the default pipeline over the MIR golden inputs, which
+// tools/gen_mir_goldens writes, and compares the result with the goldens. The
+// optimized MIR must also pass strict verification.
+func TestMIRGoldens(t *testing.T) {
+	goldenDir := filepath.Join("..", "..", "tests", "goldens", "mir")
+	inputs, err := filepath.Glob(filepath.Join(goldenDir, "*.omni"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	sort.Strings(inputs)
+	if len(inputs) == 0 {
+		t.Fatalf("no golden inputs found in %s", goldenDir)
+	}
+
+	for _, inputPath := range inputs {
+		base := strings.TrimSuffix(filepath.Base(inputPath), ".omni")
+		t.Run(base, func(t *testing.T) {
+			src, err := os.ReadFile(inputPath)
+			if err != nil {
+				t.Fatalf("read input: %v", err)
+			}
+			astMod, err := parser.Parse(inputPath, string(src))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if err := checker.Check(inputPath, string(src), astMod); err != nil {
+				t.Fatalf("type check: %v", err)
+			}
+			mod, err := builder.BuildModule(astMod)
+			if err != nil {
+				t.Fatalf("build MIR: %v", err)
+			}
+			if _, err := passes.NewPipeline("mir-golden").Run(*mod); err != nil {
+				t.Fatalf("pipeline: %v", err)
+			}
+			for _, err := range passes.VerifyStrict(mod) {
+				t.Errorf("strict verification: %v", err)
+			}
+			snapshots.CompareText(t, mirprinter.Format(mod), filepath.Join(goldenDir, base+".mir"))
+		})
+	}
+}
+
+// inlineSource builds src and runs the inlining pass over it with threshold.
+func inlineSource(t *testing.T, src string, threshold int) string {
+	t.Helper()
+	astMod, err := parser.Parse("test.omni", src)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	mod, err := builder.BuildModule(astMod)
+	if err != nil {
+		t.Fatalf("build MIR: %v", err)
+	}
+	if err := (passes.InliningPass{Threshold: threshold}).Run(mod); err != nil {
+		t.Fatalf("inlining: %v", err)
+	}
+	if err := passes.Verify(mod); err != nil {
+		t.Fatalf("inlined MIR does not verify: %v", err)
+	}
+	return mirprinter.Format(mod)
+}
+
+func TestInliningPassRespectsThreshold(t *testing.T) {
+	src := "func f(x:int):int => (x + 1) * (x + 2)\nfunc main():int => f(3)\n"
+
+	// f has five instructions: two consts, two adds and a mul
+	if got := inlineSource(t, src, 5); !strings.Contains(got, "call.int f") || strings.Contains(got, "inline_") {
+		t.Errorf("expected f to stay a call at threshold 5, got:\n%s", got)
+	}
+	if got := inlineSource(t, src, 6); strings.Contains(got, "call.int f") || !strings.Contains(got, "block inline_0_f_entry:") {
+		t.Errorf("expected f to be inlined at threshold 6, got:\n%s", got)
+	}
+	if got := inlineSource(t, src, -1); !strings.Contains(got, "call.int f") {
+		t.Errorf("expected a negative threshold to disable inlining, got:\n%s", got)
+	}
+}
+
+func TestInliningPassSkipsMutualRecursion(t *testing.T) {
+	src := `func even(n:int):bool {
+  if n == 0 {
+    return true
+  }
+  return odd(n - 1)
+}
+func odd(n:int):bool {
+  if n == 0 {
+    return false
+  }
+  return even(n - 1)
+}
+func main():bool => even(4)
+`
+	got := inlineSource(t, src, 100)
+	if strings.Contains(got, "inline_") {
+		t.Errorf("expected mutually recursive functions not to be inlined, got:\n%s", got)
+	}
+}
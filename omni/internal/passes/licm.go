@@ -0,0 +1,257 @@
+package passes
+
+import "github.com/omni-lang/omni/internal/mir"
+
+// LICMPass hoists loop-invariant instructions out of loops: an instruction
+// without side effects whose operands are all defined outside the loop, or
+// hoisted before it, computes the same value on every iteration, so it
+// moves to a pre-header block that runs once before the loop is entered.
+// Nested loops are handled innermost first, so an instruction can move out
+// of several loops. The loops come from the metadata LoopDetectionPass
+// attached, and the pass attaches fresh dominator trees and loops to the
+// functions it changes.
+type LICMPass struct {
+	// GuaranteedOnly limits hoisting to the instructions that run on every
+	// iteration, those of the blocks that dominate every back edge of the
+	// loop, leaving the ones under a condition inside the body in place.
+	GuaranteedOnly bool
+}
+
+// Name implements Pass.
+func (LICMPass) Name() string { return "licm" }
+
+// Run implements Pass.
+func (p LICMPass) Run(mod *mir.Module) error {
+	if mod == nil {
+		return nil
+	}
+	for _, fn := range mod.Functions {
+		if fn == nil || len(fn.Blocks) == 0 {
+			continue
+		}
+		if p.hoistInvariants(fn) {
+			tree := ComputeDominatorTree(fn)
+			if fn.Metadata == nil {
+				fn.Metadata = make(map[string]any)
+			}
+			fn.Metadata[DominatorsKey] = tree
+			fn.Metadata[LoopsKey] = DetectLoops(fn, tree)
+		}
+	}
+	return nil
+}
+
+// licmOps are the instructions that can move out of a loop: their result
+// depends only on their operands and they cannot trap, so running one where
+// the loop would not have is harmless. Division and casts can trap, and
+// strcat allocates, so they stay.
+var licmOps = map[string]bool{
+	"const": true, "add": true, "sub": true, "mul": true, "neg": true, "not": true,
+	"bitnot": true, "bitand": true, "bitor": true, "bitxor": true, "lshift": true, "rshift": true,
+	"cmp.eq": true, "cmp.neq": true, "cmp.lt": true, "cmp.lte": true, "cmp.gt": true, "cmp.gte": true,
+	"and": true, "or": true, "func.ref": true,
+}
+
+// hoistInvariants hoists the invariant instructions of every loop of fn,
+// innermost loops first, and reports whether it changed fn.
+func (p LICMPass) hoistInvariants(fn *mir.Function) bool {
+	// Variables written by assign are not SSA values: neither they nor the
+	// instructions reading them are invariant
+	modified := make(map[mir.ValueID]bool)
+	for _, block := range fn.Blocks {
+		for _, inst := range block.Instructions {
+			if (inst.Op == "assign" || inst.Op == "func.assign") && len(inst.Operands) > 0 && inst.Operands[0].Kind == mir.OperandValue {
+				modified[inst.Operands[0].Value] = true
+			}
+		}
+	}
+
+	var headers []string
+	var visit func(loop *Loop)
+	visit = func(loop *Loop) {
+		for _, child := range loop.Children {
+			visit(child)
+		}
+		headers = append(headers, loop.Header)
+	}
+	for _, loop := range Loops(fn).Loops {
+		visit(loop)
+	}
+
+	changed := false
+	for i, header := range headers {
+		// Pre-headers inserted for the loops before change the blocks of
+		// the loops enclosing them
+		info, tree := Loops(fn), Dominators(fn)
+		if i > 0 && changed {
+			tree = ComputeDominatorTree(fn)
+			info = DetectLoops(fn, tree)
+		}
+		loop := info.headers[header]
+		if loop == nil {
+			continue
+		}
+		if p.hoistLoop(fn, loop, tree, modified) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// hoistLoop moves the invariant instructions of loop to a new pre-header
+// and reports whether there were any.
+func (p LICMPass) hoistLoop(fn *mir.Function, loop *Loop, tree *DominatorTree, modified map[mir.ValueID]bool) bool {
+	_, preds := reachableBlocks(fn)
+	var outside, latches []string
+	for _, pred := range preds[loop.Header] {
+		if loop.Contains(pred) {
+			latches = append(latches, pred)
+		} else {
+			outside = append(outside, pred)
+		}
+	}
+	if len(outside) == 0 {
+		return false
+	}
+	byName := make(map[string]*mir.BasicBlock, len(fn.Blocks))
+	for _, block := range fn.Blocks {
+		byName[block.Name] = block
+	}
+	for _, pred := range outside {
+		for _, handler := range handlerBlocks(byName[pred]) {
+			if handler == loop.Header {
+				return false
+			}
+		}
+	}
+
+	defined := make(map[mir.ValueID]bool)
+	for _, name := range loop.Blocks {
+		for _, inst := range byName[name].Instructions {
+			if inst.ID != mir.InvalidValue {
+				defined[inst.ID] = true
+			}
+		}
+	}
+	invariant := func(inst mir.Instruction) bool {
+		if !licmOps[inst.Op] || inst.ID == mir.InvalidValue || modified[inst.ID] {
+			return false
+		}
+		for _, op := range inst.Operands {
+			if op.Kind == mir.OperandValue && (defined[op.Value] || modified[op.Value]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var hoisted []mir.Instruction
+	for moved := true; moved; {
+		moved = false
+		for _, name := range loop.Blocks {
+			block := byName[name]
+			if p.GuaranteedOnly && !dominatesAll(tree, name, latches) {
+				continue
+			}
+			kept := block.Instructions[:0]
+			for _, inst := range block.Instructions {
+				if invariant(inst) {
+					hoisted = append(hoisted, inst)
+					delete(defined, inst.ID)
+					moved = true
+					continue
+				}
+				kept = append(kept, inst)
+			}
+			block.Instructions = kept
+		}
+	}
+	if len(hoisted) == 0 {
+		return false
+	}
+	insertPreheader(fn, loop.Header, outside, hoisted)
+	return true
+}
+
+// dominatesAll reports whether block dominates every one of blocks.
+func dominatesAll(tree *DominatorTree, block string, blocks []string) bool {
+	for _, other := range blocks {
+		if !tree.Dominates(block, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// insertPreheader inserts a block holding instructions before the header
+// block and sends the predecessors outside the loop through it. With
+// several such predecessors, the incoming values of the header's phis from
+// them merge in phis of the pre-header.
+func insertPreheader(fn *mir.Function, header string, outside []string, instructions []mir.Instruction) {
+	name := "preheader_" + header
+	for taken := true; taken; {
+		taken = false
+		for _, block := range fn.Blocks {
+			if block.Name == name {
+				name += "_"
+				taken = true
+			}
+		}
+	}
+	pre := &mir.BasicBlock{Name: name, Terminator: mir.Terminator{Op: "br", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: header}}}}
+
+	fromOutside := make(map[string]bool, len(outside))
+	for _, pred := range outside {
+		fromOutside[pred] = true
+	}
+	var headerBlock *mir.BasicBlock
+	index := 0
+	for i, block := range fn.Blocks {
+		if block.Name == header {
+			headerBlock, index = block, i
+		}
+		if !fromOutside[block.Name] {
+			continue
+		}
+		term := &block.Terminator
+		first := 0
+		if term.Op == "cbr" {
+			first = 1
+		}
+		for j := first; j < len(term.Operands); j++ {
+			if term.Operands[j].Literal == header {
+				term.Operands[j].Literal = name
+			}
+		}
+	}
+
+	for i := range headerBlock.Instructions {
+		phi := &headerBlock.Instructions[i]
+		if phi.Op != "phi" || !hasBlockPairs(*phi) {
+			continue
+		}
+		var incoming, kept []mir.Operand
+		for j := 0; j+1 < len(phi.Operands); j += 2 {
+			if fromOutside[phi.Operands[j+1].Literal] {
+				incoming = append(incoming, phi.Operands[j], phi.Operands[j+1])
+			} else {
+				kept = append(kept, phi.Operands[j], phi.Operands[j+1])
+			}
+		}
+		if len(incoming) == 0 {
+			continue
+		}
+		value := incoming[0]
+		if len(incoming) > 2 {
+			merged := mir.Instruction{ID: fn.NextValue(), Op: "phi", Type: phi.Type, Operands: incoming, Location: phi.Location}
+			pre.Instructions = append(pre.Instructions, merged)
+			value = mir.Operand{Kind: mir.OperandValue, Value: merged.ID, Type: phi.Type}
+		}
+		phi.Operands = append(kept, value, mir.Operand{Kind: mir.OperandLiteral, Literal: name})
+	}
+	pre.Instructions = append(pre.Instructions, instructions...)
+
+	fn.Blocks = append(fn.Blocks, nil)
+	copy(fn.Blocks[index+1:], fn.Blocks[index:])
+	fn.Blocks[index] = pre
+}
@@ -0,0 +1,162 @@
+package passes_test
+
+import (
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/mir/builder"
+	"github.com/omni-lang/omni/internal/parser"
+	"github.com/omni-lang/omni/internal/passes"
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+// blockOf returns the name of the block holding the first op instruction
+// of fn.
+func blockOf(fn *mir.Function, op string) string {
+	for _, block := range fn.Blocks {
+		for _, inst := range block.Instructions {
+			if inst.Op == op {
+				return block.Name
+			}
+		}
+	}
+	return ""
+}
+
+func lowerForLICM(t *testing.T, src string) *mir.Module {
+	t.Helper()
+	astMod, err := parser.Parse("licm.omni", src)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	mod, err := builder.BuildModule(astMod)
+	if err != nil {
+		t.Fatalf("build MIR: %v", err)
+	}
+	return mod
+}
+
+func TestLICMHoistsInvariantProduct(t *testing.T) {
+	mod := lowerForLICM(t, `func f(a:int, b:int, n:int):int {
+  var total:int = 0
+  var i:int = 0
+  while i < n {
+    let k:int = a * b
+    total = total + k
+    i = i + 1
+  }
+  return total
+}
+func main():int {
+  return f(3, 4, 5)
+}`)
+	result, err := passes.NewPipeline("test").Run(*mod)
+	if err != nil {
+		t.Fatalf("pipeline: %v", err)
+	}
+	fn := result.Functions[0]
+	if got := blockOf(fn, "mul"); got != "preheader_while_header_0" {
+		t.Errorf("a * b is in block %s, want the pre-header", got)
+	}
+	info := passes.Loops(fn)
+	if info.ContainingLoop("preheader_while_header_0") != nil {
+		t.Error("the pre-header is inside the loop")
+	}
+	if !info.IsLoopHeader("while_header_0") {
+		t.Error("the loop header is lost after hoisting")
+	}
+	// The additions read variables the loop assigns, so they stay
+	if got := blockOf(fn, "add"); got != "while_body_1" {
+		t.Errorf("total + k is in block %s, want the loop body", got)
+	}
+
+	res, err := vm.Execute(&result, "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 60 {
+		t.Errorf("f(3, 4, 5) = %v, want 60", res.Value)
+	}
+}
+
+func TestLICMGuaranteedOnlyKeepsConditionalInstructions(t *testing.T) {
+	src := `func f(a:int, b:int, n:int):int {
+  var total:int = 0
+  var i:int = 0
+  while i < n {
+    if i > 2 {
+      total = total + a * b
+    }
+    i = i + 1
+  }
+  return total
+}`
+	for _, tc := range []struct {
+		pass passes.LICMPass
+		want string
+	}{
+		{passes.LICMPass{}, "preheader_while_header_0"},
+		{passes.LICMPass{GuaranteedOnly: true}, "then_3"},
+	} {
+		mod := lowerForLICM(t, src)
+		for _, pass := range []passes.Pass{passes.ComputeDominatorTreePass{}, passes.LoopDetectionPass{}, tc.pass} {
+			if err := pass.Run(mod); err != nil {
+				t.Fatalf("%s pass: %v", pass.Name(), err)
+			}
+		}
+		if errs := passes.Verify(mod); len(errs) > 0 {
+			t.Fatalf("MIR does not verify after LICM: %v", passes.JoinVerifyErrors(errs))
+		}
+		if got := blockOf(mod.Functions[0], "mul"); got != tc.want {
+			t.Errorf("GuaranteedOnly=%v: a * b is in block %s, want %s", tc.pass.GuaranteedOnly, got, tc.want)
+		}
+	}
+}
+
+func TestLICMMergesPhisOfSeveralEntries(t *testing.T) {
+	// entry and other both enter the loop, whose header phi takes 0 or 1
+	// from them; the product of the parameters is invariant
+	fn := mir.NewFunction("f", "int", []mir.Param{{Name: "a", Type: "int"}, {Name: "c", Type: "bool"}})
+	phi, prod, next := fn.NextValue(), fn.NextValue(), fn.NextValue()
+	fn.NewBlock("entry").Terminator = condBranch("header", "other")
+	fn.NewBlock("other").Terminator = branch("header")
+	header := fn.NewBlock("header")
+	header.Instructions = []mir.Instruction{
+		{ID: phi, Op: "phi", Type: "int", Operands: []mir.Operand{
+			{Kind: mir.OperandLiteral, Literal: "0", Type: "int"}, {Kind: mir.OperandLiteral, Literal: "entry"},
+			{Kind: mir.OperandLiteral, Literal: "1", Type: "int"}, {Kind: mir.OperandLiteral, Literal: "other"},
+			{Kind: mir.OperandValue, Value: next, Type: "int"}, {Kind: mir.OperandLiteral, Literal: "header"},
+		}},
+		{ID: prod, Op: "mul", Type: "int", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: 0, Type: "int"}, {Kind: mir.OperandValue, Value: 0, Type: "int"}}},
+		{ID: next, Op: "add", Type: "int", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: phi, Type: "int"}, {Kind: mir.OperandValue, Value: prod, Type: "int"}}},
+	}
+	header.Terminator = mir.Terminator{Op: "cbr", Operands: []mir.Operand{
+		{Kind: mir.OperandValue, Value: 1, Type: "bool"},
+		{Kind: mir.OperandLiteral, Literal: "header"},
+		{Kind: mir.OperandLiteral, Literal: "exit"},
+	}}
+	fn.NewBlock("exit").Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: next, Type: "int"}}}
+
+	mod := &mir.Module{Functions: []*mir.Function{fn}}
+	if err := (passes.LICMPass{}).Run(mod); err != nil {
+		t.Fatalf("licm pass: %v", err)
+	}
+	if errs := passes.Verify(mod); len(errs) > 0 {
+		t.Fatalf("MIR does not verify after LICM: %v", passes.JoinVerifyErrors(errs))
+	}
+	pre := fn.Blocks[2]
+	if pre.Name != "preheader_header" || len(pre.Instructions) != 2 || pre.Instructions[0].Op != "phi" || pre.Instructions[1].ID != prod {
+		t.Fatalf("pre-header = %+v, want the merged phi and the product", pre)
+	}
+	ops := header.Instructions[0].Operands
+	if len(ops) != 4 || ops[2].Value != pre.Instructions[0].ID || ops[3].Literal != "preheader_header" {
+		t.Errorf("header phi = %+v, want its loop value and the pre-header phi", ops)
+	}
+	for _, block := range fn.Blocks[:2] {
+		for _, op := range block.Terminator.Operands {
+			if op.Literal == "header" {
+				t.Errorf("%s still branches to the header", block.Name)
+			}
+		}
+	}
+}
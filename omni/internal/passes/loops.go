@@ -0,0 +1,203 @@
+package passes
+
+import (
+	"sort"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// LoopsKey is the metadata key LoopDetectionPass stores the loops of a
+// function under.
+const LoopsKey = "loops"
+
+// Loop is a natural loop of a function: the blocks that can reach one of
+// its back edges, the edges that jump to the header from blocks the header
+// dominates, without passing through the header.
+type Loop struct {
+	// Header is the block every iteration enters the loop through.
+	Header string
+	// Blocks are the blocks of the loop in function order, the header and
+	// the blocks of the loops nested in it included.
+	Blocks []string
+	// ExitBlocks are the blocks outside the loop that blocks of the loop
+	// branch to, in function order.
+	ExitBlocks []string
+	// Latch is the block whose back edge starts the next iteration; when
+	// several blocks branch back to the header, it is the last of them in
+	// function order.
+	Latch string
+	// Parent is the loop immediately enclosing this one, nil for an
+	// outermost loop, and Children the loops immediately nested in it.
+	Parent   *Loop
+	Children []*Loop
+
+	blocks map[string]bool
+}
+
+// Contains reports whether block is one of the blocks of the loop.
+func (l *Loop) Contains(block string) bool {
+	return l.blocks[block]
+}
+
+// Depth returns the number of loops enclosing l, plus one.
+func (l *Loop) Depth() int {
+	depth := 0
+	for ; l != nil; l = l.Parent {
+		depth++
+	}
+	return depth
+}
+
+// LoopInfo is the loop-nesting forest of a function.
+type LoopInfo struct {
+	// Loops holds the outermost loops in the order of their headers in
+	// the function; the nested ones hang off their Children.
+	Loops []*Loop
+	// innermost maps each block in a loop to the innermost loop holding it
+	innermost map[string]*Loop
+	headers   map[string]*Loop
+}
+
+// ContainingLoop returns the innermost loop that block belongs to, or nil
+// when it is in no loop.
+func (li *LoopInfo) ContainingLoop(blockName string) *Loop {
+	return li.innermost[blockName]
+}
+
+// IsLoopHeader reports whether the block is the header of a loop.
+func (li *LoopInfo) IsLoopHeader(blockName string) bool {
+	return li.headers[blockName] != nil
+}
+
+// DetectLoops finds the natural loops of fn using its dominator tree. Back
+// edges to the same header make one loop, and a loop is nested in the
+// smallest other loop that contains its header.
+func DetectLoops(fn *mir.Function, tree *DominatorTree) *LoopInfo {
+	info := &LoopInfo{innermost: make(map[string]*Loop), headers: make(map[string]*Loop)}
+	if fn == nil || len(fn.Blocks) == 0 {
+		return info
+	}
+	order, preds := reachableBlocks(fn)
+	position := make(map[string]int, len(fn.Blocks))
+	for i, block := range fn.Blocks {
+		position[block.Name] = i
+	}
+
+	var loops []*Loop
+	for _, header := range order {
+		var latches []string
+		for _, pred := range preds[header] {
+			if tree.Dominates(header, pred) {
+				latches = append(latches, pred)
+			}
+		}
+		if len(latches) == 0 {
+			continue
+		}
+		loop := &Loop{Header: header, blocks: map[string]bool{header: true}}
+		work := append([]string(nil), latches...)
+		for len(work) > 0 {
+			block := work[len(work)-1]
+			work = work[:len(work)-1]
+			if loop.blocks[block] {
+				continue
+			}
+			loop.blocks[block] = true
+			work = append(work, preds[block]...)
+		}
+		for block := range loop.blocks {
+			loop.Blocks = append(loop.Blocks, block)
+		}
+		sortByPosition(loop.Blocks, position)
+		sortByPosition(latches, position)
+		loop.Latch = latches[len(latches)-1]
+		loops = append(loops, loop)
+		info.headers[header] = loop
+	}
+
+	byName := make(map[string]*mir.BasicBlock, len(fn.Blocks))
+	for _, block := range fn.Blocks {
+		byName[block.Name] = block
+	}
+	for _, loop := range loops {
+		exits := make(map[string]bool)
+		for _, name := range loop.Blocks {
+			block := byName[name]
+			for _, succ := range append(successors(block.Terminator), handlerBlocks(block)...) {
+				if _, ok := byName[succ]; ok && !loop.blocks[succ] && !exits[succ] {
+					exits[succ] = true
+					loop.ExitBlocks = append(loop.ExitBlocks, succ)
+				}
+			}
+		}
+		sortByPosition(loop.ExitBlocks, position)
+	}
+
+	// Visiting the loops from the largest down, the parent of each is the
+	// smallest loop seen so far that holds its header, and each block ends
+	// up mapped to the smallest loop holding it
+	sort.SliceStable(loops, func(i, j int) bool { return len(loops[i].Blocks) > len(loops[j].Blocks) })
+	for i, loop := range loops {
+		for j := i - 1; j >= 0; j-- {
+			if loops[j].blocks[loop.Header] {
+				loop.Parent = loops[j]
+				break
+			}
+		}
+		for _, block := range loop.Blocks {
+			info.innermost[block] = loop
+		}
+	}
+	for _, header := range order {
+		loop := info.headers[header]
+		if loop == nil {
+			continue
+		}
+		if loop.Parent == nil {
+			info.Loops = append(info.Loops, loop)
+		} else {
+			loop.Parent.Children = append(loop.Parent.Children, loop)
+		}
+	}
+	return info
+}
+
+// Loops returns the loops LoopDetectionPass attached to fn, detecting them
+// when the pass has not run.
+func Loops(fn *mir.Function) *LoopInfo {
+	if info, ok := fn.Metadata[LoopsKey].(*LoopInfo); ok {
+		return info
+	}
+	return DetectLoops(fn, Dominators(fn))
+}
+
+// LoopDetectionPass attaches the loop-nesting forest of every function to
+// its metadata under LoopsKey, using the dominator tree that
+// ComputeDominatorTreePass attached.
+type LoopDetectionPass struct{}
+
+// Name implements Pass.
+func (LoopDetectionPass) Name() string { return "loops" }
+
+// Run implements Pass.
+func (LoopDetectionPass) Run(mod *mir.Module) error {
+	if mod == nil {
+		return nil
+	}
+	for _, fn := range mod.Functions {
+		if fn == nil || len(fn.Blocks) == 0 {
+			continue
+		}
+		info := DetectLoops(fn, Dominators(fn))
+		if fn.Metadata == nil {
+			fn.Metadata = make(map[string]any)
+		}
+		fn.Metadata[LoopsKey] = info
+	}
+	return nil
+}
+
+// sortByPosition orders block names as the blocks appear in the function.
+func sortByPosition(names []string, position map[string]int) {
+	sort.Slice(names, func(i, j int) bool { return position[names[i]] < position[names[j]] })
+}
@@ -0,0 +1,131 @@
+package passes_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/mir/builder"
+	"github.com/omni-lang/omni/internal/parser"
+	"github.com/omni-lang/omni/internal/passes"
+)
+
+// loopsOf lowers src without optimizing it and returns the loops of its
+// first function.
+func loopsOf(t *testing.T, src string) (*mir.Function, *passes.LoopInfo) {
+	t.Helper()
+	astMod, err := parser.Parse("loops.omni", src)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	mod, err := builder.BuildModule(astMod)
+	if err != nil {
+		t.Fatalf("build MIR: %v", err)
+	}
+	for _, pass := range []passes.Pass{passes.ComputeDominatorTreePass{}, passes.LoopDetectionPass{}} {
+		if err := pass.Run(mod); err != nil {
+			t.Fatalf("%s pass: %v", pass.Name(), err)
+		}
+	}
+	fn := mod.Functions[0]
+	info, ok := fn.Metadata[passes.LoopsKey].(*passes.LoopInfo)
+	if !ok {
+		t.Fatalf("function metadata has no loops: %+v", fn.Metadata)
+	}
+	return fn, info
+}
+
+func TestLoopDetectionFindsWhileLoop(t *testing.T) {
+	_, info := loopsOf(t, `func main():int {
+  var i:int = 0
+  while i < 10 {
+    i = i + 1
+  }
+  return i
+}`)
+	if len(info.Loops) != 1 {
+		t.Fatalf("found %d loops, want 1", len(info.Loops))
+	}
+	loop := info.Loops[0]
+	if loop.Header != "while_header_0" || loop.Latch != "while_body_1" {
+		t.Errorf("loop header %s and latch %s, want while_header_0 and while_body_1", loop.Header, loop.Latch)
+	}
+	if want := []string{"while_header_0", "while_body_1"}; !reflect.DeepEqual(loop.Blocks, want) {
+		t.Errorf("loop blocks = %v, want %v", loop.Blocks, want)
+	}
+	if want := []string{"while_exit_2"}; !reflect.DeepEqual(loop.ExitBlocks, want) {
+		t.Errorf("loop exits = %v, want %v", loop.ExitBlocks, want)
+	}
+	if !info.IsLoopHeader("while_header_0") || info.IsLoopHeader("while_body_1") {
+		t.Error("IsLoopHeader does not single out the header")
+	}
+	if info.ContainingLoop("while_body_1") != loop || info.ContainingLoop("entry") != nil {
+		t.Error("ContainingLoop does not map the body alone to the loop")
+	}
+}
+
+func TestLoopDetectionFindsForLoop(t *testing.T) {
+	_, info := loopsOf(t, `func main():int {
+  var s:int = 0
+  for i:int = 0; i < 3; i++ {
+    if i == 1 { continue }
+    s = s + i
+  }
+  return s
+}`)
+	if len(info.Loops) != 1 {
+		t.Fatalf("found %d loops, want 1", len(info.Loops))
+	}
+	loop := info.Loops[0]
+	if loop.Header != "loop_header_0" {
+		t.Errorf("loop header = %s, want loop_header_0", loop.Header)
+	}
+	for _, block := range []string{"loop_body_1", "then_3", "merge_4", loop.Latch} {
+		if !loop.Contains(block) {
+			t.Errorf("loop does not contain %s: %v", block, loop.Blocks)
+		}
+	}
+	if loop.Contains("loop_exit_2") || loop.Contains("entry") {
+		t.Errorf("loop contains blocks outside it: %v", loop.Blocks)
+	}
+	if want := []string{"loop_exit_2"}; !reflect.DeepEqual(loop.ExitBlocks, want) {
+		t.Errorf("loop exits = %v, want %v", loop.ExitBlocks, want)
+	}
+}
+
+func TestLoopDetectionNestsLoops(t *testing.T) {
+	_, info := loopsOf(t, `func main():int {
+  var s:int = 0
+  for i:int = 0; i < 3; i++ {
+    var j:int = 0
+    while j < i {
+      s = s + j
+      j = j + 1
+    }
+  }
+  return s
+}`)
+	if len(info.Loops) != 1 {
+		t.Fatalf("found %d outermost loops, want 1", len(info.Loops))
+	}
+	outer := info.Loops[0]
+	if len(outer.Children) != 1 {
+		t.Fatalf("outer loop has %d nested loops, want 1", len(outer.Children))
+	}
+	inner := outer.Children[0]
+	if outer.Header != "loop_header_0" || inner.Header != "while_header_3" {
+		t.Errorf("headers %s and %s, want loop_header_0 and while_header_3", outer.Header, inner.Header)
+	}
+	if inner.Parent != outer || inner.Depth() != 2 || outer.Depth() != 1 {
+		t.Errorf("inner loop is not nested in the outer one")
+	}
+	if !outer.Contains("while_body_4") || !inner.Contains("while_body_4") || inner.Contains("loop_body_1") {
+		t.Errorf("loop blocks: outer %v, inner %v", outer.Blocks, inner.Blocks)
+	}
+	if info.ContainingLoop("while_body_4") != inner || info.ContainingLoop("loop_body_1") != outer {
+		t.Error("ContainingLoop does not return the innermost loop")
+	}
+	if !info.IsLoopHeader("loop_header_0") || !info.IsLoopHeader("while_header_3") {
+		t.Error("IsLoopHeader misses a header")
+	}
+}
@@ -1,92 +1,284 @@
 package passes
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/omni-lang/omni/internal/mir"
 )
 
+// Pass is a transformation over a MIR module, applied in place.
+type Pass interface {
+	Name() string
+	Run(mod *mir.Module) error
+}
+
 // Pipeline owns an ordered set of MIR passes, including verification.
 type Pipeline struct {
-	Name string
+	Name   string
+	Passes []Pass
 }
 
-// NewPipeline constructs the pass pipeline descriptor.
+// VerifyOnly names the pipeline without passes, whose Run only verifies the
+// module.
+const VerifyOnly = "verify-only"
+
+// NewPipeline constructs the pass pipeline descriptor with the default
+// passes. Inlining runs first so that constant arguments reach the copied
+// bodies, common subexpressions are merged once folding has made equal
+// constants look alike, and dead code elimination runs last, after constant
+// folding has turned branches on constant conditions into unconditional ones.
+// The dominator trees and the loops found with them are computed once the
+// control-flow graph no longer changes, except for the pre-headers that
+// loop invariant code motion adds last. The VerifyOnly pipeline has no
+// passes.
 func NewPipeline(name string) Pipeline {
-	return Pipeline{Name: name}
+	if name == VerifyOnly {
+		return Pipeline{Name: name}
+	}
+	return Pipeline{Name: name, Passes: []Pass{InliningPass{}, ConstantFoldingPass{}, CSEPass{}, DeadCodeEliminationPass{}, ComputeDominatorTreePass{}, LoopDetectionPass{}, LICMPass{}}}
 }
 
-// Run executes the configured passes over the module.
+// Run verifies the module, then executes the configured passes over it,
+// verifying it again after each one.
 func (p Pipeline) Run(mod mir.Module) (mir.Module, error) {
-	if err := Verify(&mod); err != nil {
+	if err := JoinVerifyErrors(Verify(&mod)); err != nil {
 		return mir.Module{}, err
 	}
-	ConstFold(&mod)
-	if err := Verify(&mod); err != nil {
-		return mir.Module{}, err
+	for _, pass := range p.Passes {
+		if err := pass.Run(&mod); err != nil {
+			return mir.Module{}, fmt.Errorf("%s pass: %w", pass.Name(), err)
+		}
+		if err := JoinVerifyErrors(Verify(&mod)); err != nil {
+			return mir.Module{}, fmt.Errorf("after %s pass: %w", pass.Name(), err)
+		}
 	}
 	return mod, nil
 }
 
-// Verify ensures the module satisfies basic structural invariants expected by downstream passes.
-func Verify(mod *mir.Module) error {
+// VerifyError is a problem the MIR verifier found. Block is empty when the
+// problem concerns the module or a function as a whole, and Instruction is
+// the index of the offending instruction in Block, or -1 when the block or
+// its terminator is at fault.
+type VerifyError struct {
+	Function    string
+	Block       string
+	Instruction int
+	Message     string
+}
+
+// Error implements error.
+func (e VerifyError) Error() string {
+	switch {
+	case e.Block == "":
+		return "mir verifier: " + e.Message
+	case e.Instruction >= 0:
+		return fmt.Sprintf("mir verifier: block %s in function %s, instruction %d: %s", e.Block, e.Function, e.Instruction, e.Message)
+	default:
+		return fmt.Sprintf("mir verifier: block %s in function %s: %s", e.Block, e.Function, e.Message)
+	}
+}
+
+// JoinVerifyErrors combines the problems of a verification into one error,
+// or returns nil when there are none.
+func JoinVerifyErrors(errs []VerifyError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	list := make([]error, len(errs))
+	for i, err := range errs {
+		list[i] = err
+	}
+	return errors.Join(list...)
+}
+
+// Verify ensures the module satisfies basic structural invariants expected by
+// downstream passes. It returns every problem it finds, or nil for a valid
+// module.
+func Verify(mod *mir.Module) []VerifyError {
 	if mod == nil {
-		return fmt.Errorf("mir verifier: nil module")
+		return []VerifyError{{Instruction: -1, Message: "nil module"}}
 	}
+	var errs []VerifyError
 	for _, fn := range mod.Functions {
-		if err := verifyFunction(fn); err != nil {
-			return err
-		}
+		errs = append(errs, functionErrors(fn)...)
 	}
-	return nil
+	return errs
+}
+
+// VerifyStrict runs Verify and, for a module that passes it, also reports
+// blocks that cannot be reached from the entry block of their function and
+// phis whose incoming values do not match the predecessors of their block.
+func VerifyStrict(mod *mir.Module) []VerifyError {
+	if errs := Verify(mod); errs != nil {
+		return errs
+	}
+	var errs []VerifyError
+	for _, fn := range mod.Functions {
+		errs = append(errs, strictFunctionErrors(fn)...)
+	}
+	return errs
 }
 
+// verifyFunction returns the first problem of fn, or nil.
 func verifyFunction(fn *mir.Function) error {
+	if errs := functionErrors(fn); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+func functionErrors(fn *mir.Function) []VerifyError {
 	if fn == nil {
-		return fmt.Errorf("mir verifier: nil function")
+		return []VerifyError{{Instruction: -1, Message: "nil function"}}
+	}
+	fail := func(format string, args ...any) []VerifyError {
+		return []VerifyError{{Function: fn.Name, Instruction: -1, Message: fmt.Sprintf(format, args...)}}
 	}
 	if fn.Name == "" {
-		return fmt.Errorf("mir verifier: function with empty name")
+		return fail("function with empty name")
 	}
 	if len(fn.Blocks) == 0 {
-		return fmt.Errorf("mir verifier: function %s has no basic blocks", fn.Name)
+		return fail("function %s has no basic blocks", fn.Name)
 	}
 	blockNames := make(map[string]struct{}, len(fn.Blocks))
 	for _, block := range fn.Blocks {
 		if block == nil {
-			return fmt.Errorf("mir verifier: function %s has nil block", fn.Name)
+			return fail("function %s has nil block", fn.Name)
 		}
 		if block.Name == "" {
-			return fmt.Errorf("mir verifier: function %s has block with empty name", fn.Name)
+			return fail("function %s has block with empty name", fn.Name)
 		}
 		if _, exists := blockNames[block.Name]; exists {
-			return fmt.Errorf("mir verifier: function %s has duplicate block name %q", fn.Name, block.Name)
+			return fail("function %s has duplicate block name %q", fn.Name, block.Name)
 		}
 		blockNames[block.Name] = struct{}{}
 	}
+
+	var errs []VerifyError
 	for _, block := range fn.Blocks {
 		// Verify instructions
 		for i, inst := range block.Instructions {
 			if err := verifyInstruction(inst); err != nil {
-				return fmt.Errorf("mir verifier: block %s in function %s, instruction %d: %w", block.Name, fn.Name, i, err)
+				errs = append(errs, VerifyError{Function: fn.Name, Block: block.Name, Instruction: i, Message: err.Error()})
 			}
 		}
 
 		if block.Terminator.Op == "" {
-			return fmt.Errorf("mir verifier: block %s in function %s missing terminator", block.Name, fn.Name)
+			errs = append(errs, VerifyError{Function: fn.Name, Block: block.Name, Instruction: -1, Message: "missing terminator"})
+		} else if err := verifyTerminator(block.Terminator, blockNames); err != nil {
+			errs = append(errs, VerifyError{Function: fn.Name, Block: block.Name, Instruction: -1, Message: err.Error()})
 		}
-		if err := verifyTerminator(block.Terminator, blockNames); err != nil {
-			return fmt.Errorf("mir verifier: block %s in function %s: %w", block.Name, fn.Name, err)
+	}
+	return errs
+}
+
+// strictFunctionErrors checks the control flow of a function that passed
+// Verify. Exception handlers named by try.enter count as reachable, as they
+// do for dead code elimination.
+func strictFunctionErrors(fn *mir.Function) []VerifyError {
+	byName := make(map[string]*mir.BasicBlock, len(fn.Blocks))
+	preds := make(map[string]map[string]bool, len(fn.Blocks))
+	for _, block := range fn.Blocks {
+		byName[block.Name] = block
+		for _, succ := range successors(block.Terminator) {
+			if preds[succ] == nil {
+				preds[succ] = make(map[string]bool)
+			}
+			preds[succ][block.Name] = true
 		}
 	}
-	return nil
+
+	entry := fn.Blocks[0]
+	reachable := map[string]bool{entry.Name: true}
+	queue := []*mir.BasicBlock{entry}
+	for len(queue) > 0 {
+		block := queue[0]
+		queue = queue[1:]
+		for _, succ := range append(successors(block.Terminator), handlerBlocks(block)...) {
+			if next, ok := byName[succ]; ok && !reachable[succ] {
+				reachable[succ] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	var errs []VerifyError
+	for _, block := range fn.Blocks {
+		if !reachable[block.Name] {
+			errs = append(errs, VerifyError{Function: fn.Name, Block: block.Name, Instruction: -1, Message: fmt.Sprintf("unreachable from entry block %s", entry.Name)})
+			continue
+		}
+		for i, inst := range block.Instructions {
+			if inst.Op != "phi" {
+				continue
+			}
+			fail := func(format string, args ...any) {
+				errs = append(errs, VerifyError{Function: fn.Name, Block: block.Name, Instruction: i, Message: fmt.Sprintf(format, args...)})
+			}
+			if incoming := len(inst.Operands) / 2; incoming != len(preds[block.Name]) {
+				fail("phi has %d incoming values but the block has %d predecessors", incoming, len(preds[block.Name]))
+				continue
+			}
+			for j := 1; j < len(inst.Operands); j += 2 {
+				if from := inst.Operands[j].Literal; !preds[block.Name][from] {
+					fail("phi has an incoming value from %q, which is not a predecessor", from)
+				}
+			}
+		}
+	}
+	return errs
 }
 
 func verifyInstruction(inst mir.Instruction) error {
 	switch inst.Op {
-	case "const", "add", "sub", "mul", "div", "mod", "strcat", "neg", "not", "bitnot", "bitand", "bitor", "bitxor", "lshift", "rshift", "cast", "index", "array.init", "map.init", "struct.init", "member", "call", "call.int", "call.void", "call.string", "call.bool", "assign", "func.ref", "func.assign", "func.call", "closure.create", "closure.capture", "closure.bind", "throw", "await":
+	case "const", "add", "sub", "mul", "div", "mod", "udiv", "umod", "strcat", "neg", "not", "bitnot", "bitand", "bitor", "bitxor", "lshift", "rshift", "cast", "index", "array.init", "map.init", "map.contains", "struct.init", "member", "call", "call.int", "call.void", "call.string", "call.bool", "assign", "func.ref", "func.assign", "func.call", "closure.create", "closure.capture", "closure.bind", "throw", "await":
 		// These instructions are already validated by the builder
 		return nil
+	case "defer.push":
+		if len(inst.Operands) == 0 {
+			return fmt.Errorf("defer.push expects the callee of the deferred call")
+		}
+		return nil
+	case "defer.run", "try.exit", "finally.begin":
+		return nil
+	case "try.enter":
+		if len(inst.Operands)%2 != 0 {
+			return fmt.Errorf("try.enter expects exception type and block pairs, got %d operands", len(inst.Operands))
+		}
+		return nil
+	case "catch.begin":
+		if len(inst.Operands) != 1 {
+			return fmt.Errorf("catch.begin expects the caught exception type")
+		}
+		return nil
+	case "opt.none":
+		return nil
+	case "global.load":
+		if len(inst.Operands) != 1 || inst.Operands[0].Kind != mir.OperandLiteral {
+			return fmt.Errorf("global.load expects the name of the global")
+		}
+		return nil
+	case "global.store":
+		if len(inst.Operands) != 2 || inst.Operands[0].Kind != mir.OperandLiteral {
+			return fmt.Errorf("global.store expects the name of the global and a value")
+		}
+		return nil
+	case "interface.init", "interface.call":
+		if len(inst.Operands) < 2 || inst.Operands[1].Kind != mir.OperandLiteral {
+			return fmt.Errorf("%s expects a value followed by a literal name", inst.Op)
+		}
+		return nil
+	case "opt.some", "opt.unwrap", "opt.is_some", "opt.is_none":
+		if len(inst.Operands) != 1 {
+			return fmt.Errorf("%s expects 1 operand, got %d", inst.Op, len(inst.Operands))
+		}
+		return nil
+	case "opt.unwrap_or":
+		if len(inst.Operands) != 2 {
+			return fmt.Errorf("opt.unwrap_or expects 2 operands, got %d", len(inst.Operands))
+		}
+		return nil
 	case "cmp.eq", "cmp.neq", "cmp.lt", "cmp.lte", "cmp.gt", "cmp.gte", "and", "or":
 		// Comparison and logical operations
 		if len(inst.Operands) < 2 {
@@ -107,14 +299,40 @@ func verifyInstruction(inst mir.Instruction) error {
 	}
 }
 
+// terminatorName describes the terminator op in verifier errors.
+func terminatorName(op string) string {
+	switch op {
+	case "loop.break":
+		return "break"
+	case "loop.continue":
+		return "continue"
+	}
+	return "branch"
+}
+
 func verifyTerminator(term mir.Terminator, blocks map[string]struct{}) error {
 	switch term.Op {
 	case "ret":
 		// return may optionally carry a single operand; nothing further to validate here.
 		return nil
-	case "br":
+	case "ret.named":
+		// returns the slot of a named return value, which goes with its name
+		if len(term.Operands) != 2 {
+			return fmt.Errorf("ret.named expects 2 operands, got %d", len(term.Operands))
+		}
+		if term.Operands[0].Kind != mir.OperandValue || term.Operands[1].Kind != mir.OperandLiteral {
+			return fmt.Errorf("ret.named expects a value and a variable name")
+		}
+		return nil
+	case "unreachable":
+		// ends a block after a throw, which never falls through
+		if len(term.Operands) != 0 {
+			return fmt.Errorf("unreachable expects no operands, got %d", len(term.Operands))
+		}
+		return nil
+	case "br", "loop.break", "loop.continue":
 		if len(term.Operands) != 1 {
-			return fmt.Errorf("branch expects 1 operand, got %d", len(term.Operands))
+			return fmt.Errorf("%s expects 1 operand, got %d", terminatorName(term.Op), len(term.Operands))
 		}
 		return verifyBlockOperand(term.Operands[0], blocks)
 	case "cbr":
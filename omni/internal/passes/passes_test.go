@@ -1,6 +1,7 @@
 package passes
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/omni-lang/omni/internal/mir"
@@ -14,6 +15,13 @@ func TestNewPipeline(t *testing.T) {
 	if pipeline.Name != name {
 		t.Errorf("Expected pipeline name '%s', got '%s'", name, pipeline.Name)
 	}
+	var names []string
+	for _, pass := range pipeline.Passes {
+		names = append(names, pass.Name())
+	}
+	if got := strings.Join(names, ","); got != "inline,constfold,cse,dce,dominators,loops,licm" {
+		t.Errorf("Expected the default pipeline to run inline,constfold,cse,dce,dominators,loops,licm, got %s", got)
+	}
 }
 
 func TestPipelineRun(t *testing.T) {
@@ -111,14 +119,14 @@ func TestVerify(t *testing.T) {
 
 func TestVerifyNilModule(t *testing.T) {
 	// Test verifying a nil module
-	err := Verify(nil)
-	if err == nil {
-		t.Error("Expected error for nil module")
+	errs := Verify(nil)
+	if len(errs) != 1 {
+		t.Fatalf("Expected one error for nil module, got %v", errs)
 	}
 
 	expectedError := "mir verifier: nil module"
-	if err.Error() != expectedError {
-		t.Errorf("Expected error '%s', got '%s'", expectedError, err.Error())
+	if errs[0].Error() != expectedError {
+		t.Errorf("Expected error '%s', got '%s'", expectedError, errs[0].Error())
 	}
 }
 
@@ -317,7 +325,7 @@ func TestVerifyFunctionBlockWithoutTerminator(t *testing.T) {
 		t.Error("Expected error for function with block without terminator")
 	}
 
-	expectedError := "mir verifier: block entry in function test_func missing terminator"
+	expectedError := "mir verifier: block entry in function test_func: missing terminator"
 	if err.Error() != expectedError {
 		t.Errorf("Expected error '%s', got '%s'", expectedError, err.Error())
 	}
@@ -736,3 +744,126 @@ func TestVerifyFunctionDuplicateBlockNames(t *testing.T) {
 		t.Errorf("Expected error '%s', got '%s'", expectedError, err.Error())
 	}
 }
+
+func TestVerifyOnlyPipeline(t *testing.T) {
+	pipeline := NewPipeline(VerifyOnly)
+	if len(pipeline.Passes) != 0 {
+		t.Fatalf("Expected the verify-only pipeline to have no passes, got %d", len(pipeline.Passes))
+	}
+	module := mir.Module{Functions: []*mir.Function{{Name: "f", Blocks: []*mir.BasicBlock{{Name: "entry"}}}}}
+	if _, err := pipeline.Run(module); err == nil || !strings.Contains(err.Error(), "missing terminator") {
+		t.Errorf("Expected the verify-only pipeline to reject the module, got %v", err)
+	}
+}
+
+func TestVerifyReportsEveryProblem(t *testing.T) {
+	module := &mir.Module{
+		Functions: []*mir.Function{
+			{
+				Name: "first",
+				Blocks: []*mir.BasicBlock{
+					{
+						Name:         "entry",
+						Instructions: []mir.Instruction{{Op: "const"}, {Op: "bogus"}},
+						Terminator:   mir.Terminator{Op: "br", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "missing"}}},
+					},
+				},
+			},
+			{Name: "second"},
+		},
+	}
+
+	errs := Verify(module)
+	want := []VerifyError{
+		{Function: "first", Block: "entry", Instruction: 1, Message: `unsupported instruction "bogus"`},
+		{Function: "first", Block: "entry", Instruction: -1, Message: `branch target "missing" not found`},
+		{Function: "second", Instruction: -1, Message: "function second has no basic blocks"},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Expected %d errors, got %v", len(want), errs)
+	}
+	for i := range want {
+		if errs[i] != want[i] {
+			t.Errorf("error %d: expected %+v, got %+v", i, want[i], errs[i])
+		}
+	}
+	if got := errs[0].Error(); got != `mir verifier: block entry in function first, instruction 1: unsupported instruction "bogus"` {
+		t.Errorf("Unexpected message %q", got)
+	}
+}
+
+// diamond returns a function branching from entry to then and else, which
+// both jump to merge, where a phi joins the given incoming values.
+func diamond(incoming ...mir.Operand) *mir.Function {
+	jump := mir.Terminator{Op: "br", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "merge"}}}
+	return &mir.Function{
+		Name: "pick",
+		Blocks: []*mir.BasicBlock{
+			{Name: "entry", Terminator: mir.Terminator{Op: "cbr", Operands: []mir.Operand{
+				{Kind: mir.OperandLiteral, Literal: "true"},
+				{Kind: mir.OperandLiteral, Literal: "then"},
+				{Kind: mir.OperandLiteral, Literal: "else"},
+			}}},
+			{Name: "then", Terminator: jump},
+			{Name: "else", Terminator: jump},
+			{
+				Name:         "merge",
+				Instructions: []mir.Instruction{{ID: 1, Op: "phi", Type: "int", Operands: incoming}},
+				Terminator:   mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: 1}}},
+			},
+		},
+	}
+}
+
+func TestVerifyStrict(t *testing.T) {
+	value := func(lit string) mir.Operand { return mir.Operand{Kind: mir.OperandLiteral, Literal: lit, Type: "int"} }
+	from := func(block string) mir.Operand { return mir.Operand{Kind: mir.OperandLiteral, Literal: block} }
+
+	tests := []struct {
+		name string
+		fn   *mir.Function
+		want string
+	}{
+		{
+			name: "matching phi",
+			fn:   diamond(value("1"), from("then"), value("2"), from("else")),
+		},
+		{
+			name: "phi missing a predecessor",
+			fn:   diamond(value("1"), from("then")),
+			want: "mir verifier: block merge in function pick, instruction 0: phi has 1 incoming values but the block has 2 predecessors",
+		},
+		{
+			name: "phi from a block that does not branch there",
+			fn:   diamond(value("1"), from("then"), value("2"), from("entry")),
+			want: `mir verifier: block merge in function pick, instruction 0: phi has an incoming value from "entry", which is not a predecessor`,
+		},
+		{
+			name: "unreachable block",
+			fn: func() *mir.Function {
+				fn := diamond(value("1"), from("then"), value("2"), from("else"))
+				fn.Blocks = append(fn.Blocks, &mir.BasicBlock{Name: "orphan", Terminator: mir.Terminator{Op: "ret"}})
+				return fn
+			}(),
+			want: "mir verifier: block orphan in function pick: unreachable from entry block entry",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module := &mir.Module{Functions: []*mir.Function{tt.fn}}
+			if errs := Verify(module); errs != nil {
+				t.Fatalf("Expected the module to pass Verify, got %v", errs)
+			}
+			errs := VerifyStrict(module)
+			if tt.want == "" {
+				if errs != nil {
+					t.Errorf("Expected no errors, got %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Error() != tt.want {
+				t.Errorf("Expected %q, got %v", tt.want, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omni-lang/omni/internal/logging"
+	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+// BenchPrefix starts the name of every benchmark function.
+const BenchPrefix = "bench_"
+
+// benchTime is how long a benchmark has to run before its result is
+// reported. Tests lower it to keep the suite fast.
+var benchTime = time.Second
+
+// benchHarness names the function Benchmark adds to the module to time a
+// benchmark.
+const benchHarness = "__bench_harness"
+
+// Benchmark runs the benchmark functions of the program, those whose name
+// starts with bench_ and that take no parameters, in the order they are
+// declared. An empty pattern runs all of them; otherwise only benchmarks
+// whose name matches the regular expression run. Each benchmark is called
+// 1, 2, 4, ... times in a row until a round takes at least a second, and
+// that round is reported.
+func Benchmark(program string, pattern string, verbose bool) ([]vm.BenchResult, error) {
+	if filepath.Ext(program) != ".omni" {
+		return nil, fmt.Errorf("%s: unsupported input (expected .omni)", program)
+	}
+	var match *regexp.Regexp
+	if pattern != "" {
+		var err error
+		if match, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid -bench pattern: %w", err)
+		}
+	}
+
+	mod, err := compile(program, Options{Verbose: verbose})
+	if err != nil {
+		return nil, err
+	}
+
+	var benches []*mir.Function
+	for _, fn := range mod.Functions {
+		if !strings.HasPrefix(fn.Name, BenchPrefix) || len(fn.Params) != 0 {
+			continue
+		}
+		if match == nil || match.MatchString(fn.Name) {
+			benches = append(benches, fn)
+		}
+	}
+
+	logger := logging.Logger()
+	results := make([]vm.BenchResult, 0, len(benches))
+	for _, fn := range benches {
+		if verbose {
+			logger.DebugFields("Running benchmark", logging.String("name", fn.Name))
+		}
+		result, err := runBenchmark(mod, fn)
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", fn.Name, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// runBenchmark doubles the number of calls to fn until a round lasts
+// benchTime and returns the timing of that round.
+func runBenchmark(mod *mir.Module, fn *mir.Function) (vm.BenchResult, error) {
+	functions := mod.Functions
+	defer func() { mod.Functions = functions }()
+
+	vm.TakeBenchResults()
+	for n := 1; ; n *= 2 {
+		mod.Functions = append(functions[:len(functions):len(functions)], benchHarnessFunction(fn, n))
+		if _, err := vm.ExecuteWithOptions(context.Background(), mod, benchHarness, vm.ExecuteOptions{}); err != nil {
+			return vm.BenchResult{}, err
+		}
+		results := vm.TakeBenchResults()
+		if len(results) != 1 {
+			return vm.BenchResult{}, fmt.Errorf("benchmark harness recorded %d results", len(results))
+		}
+		result := results[0]
+		if time.Duration(result.NsPerOp*float64(n)) >= benchTime {
+			return result, nil
+		}
+	}
+}
+
+// benchHarnessFunction builds a function that calls fn n times between
+// bench.start and bench.end:
+//
+//	entry: i = 0; bench.start name; br header
+//	header: cbr i < n, body, exit
+//	body: call fn; i = i + 1; br header
+//	exit: bench.end name, n; ret 0
+func benchHarnessFunction(fn *mir.Function, n int) *mir.Function {
+	harness := mir.NewFunction(benchHarness, "int", nil)
+	name := mir.Operand{Kind: mir.OperandLiteral, Literal: strconv.Quote(fn.Name), Type: "string"}
+	count := mir.Operand{Kind: mir.OperandLiteral, Literal: strconv.Itoa(n), Type: "int"}
+	one := mir.Operand{Kind: mir.OperandLiteral, Literal: "1", Type: "int"}
+	label := func(block *mir.BasicBlock) mir.Operand {
+		return mir.Operand{Kind: mir.OperandLiteral, Literal: block.Name}
+	}
+
+	entry := harness.NewBlock("entry")
+	header := harness.NewBlock("header")
+	body := harness.NewBlock("body")
+	exit := harness.NewBlock("exit")
+
+	i := harness.NextValue()
+	counter := mir.Operand{Kind: mir.OperandValue, Value: i, Type: "int"}
+	entry.Instructions = []mir.Instruction{
+		{ID: i, Op: "const", Type: "int", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "0", Type: "int"}}},
+		{ID: mir.InvalidValue, Op: "bench.start", Type: "void", Operands: []mir.Operand{name}},
+	}
+	entry.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{label(header)}}
+
+	cond := harness.NextValue()
+	header.Instructions = []mir.Instruction{
+		{ID: cond, Op: "cmp.lt", Type: "bool", Operands: []mir.Operand{counter, count}},
+	}
+	header.Terminator = mir.Terminator{Op: "cbr", Operands: []mir.Operand{
+		{Kind: mir.OperandValue, Value: cond, Type: "bool"}, label(body), label(exit),
+	}}
+
+	call, next := harness.NextValue(), harness.NextValue()
+	body.Instructions = []mir.Instruction{
+		{ID: call, Op: "call", Type: fn.ReturnType, Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: fn.Name}}},
+		{ID: next, Op: "add", Type: "int", Operands: []mir.Operand{counter, one}},
+		{ID: harness.NextValue(), Op: "assign", Type: "int", Operands: []mir.Operand{counter, {Kind: mir.OperandValue, Value: next, Type: "int"}}},
+	}
+	body.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{label(header)}}
+
+	exit.Instructions = []mir.Instruction{
+		{ID: mir.InvalidValue, Op: "bench.end", Type: "void", Operands: []mir.Operand{name, count}},
+	}
+	exit.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "0", Type: "int"}}}
+	return harness
+}
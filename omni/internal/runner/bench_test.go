@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBenchmarkReportsNsPerOp(t *testing.T) {
+	saved := benchTime
+	benchTime = 10 * time.Millisecond
+	defer func() { benchTime = saved }()
+
+	results, err := Benchmark(filepath.Join("..", "..", "examples", "bench_demo.omni"), "", false)
+	if err != nil {
+		t.Fatalf("Benchmark: %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "bench_fib" || results[1].Name != "bench_sum_loop" {
+		t.Fatalf("unexpected benchmarks: %+v", results)
+	}
+	for _, result := range results {
+		if result.Iterations < 1 || result.NsPerOp <= 0 {
+			t.Errorf("%s: %d iterations at %v ns/op, want a positive timing", result.Name, result.Iterations, result.NsPerOp)
+		}
+	}
+}
+
+func TestBenchmarkPattern(t *testing.T) {
+	saved := benchTime
+	benchTime = time.Millisecond
+	defer func() { benchTime = saved }()
+
+	results, err := Benchmark(filepath.Join("..", "..", "examples", "bench_demo.omni"), "sum", false)
+	if err != nil {
+		t.Fatalf("Benchmark: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "bench_sum_loop" {
+		t.Fatalf("pattern sum selected %+v, want bench_sum_loop only", results)
+	}
+	if _, err := Benchmark(filepath.Join("..", "..", "examples", "bench_demo.omni"), "(", false); err == nil {
+		t.Fatal("expected an error for an invalid pattern")
+	}
+}
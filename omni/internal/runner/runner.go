@@ -1,32 +1,101 @@
 package runner
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 
 	"github.com/omni-lang/omni/internal/compiler"
 	"github.com/omni-lang/omni/internal/logging"
+	"github.com/omni-lang/omni/internal/mir"
 	"github.com/omni-lang/omni/internal/mir/builder"
+	"github.com/omni-lang/omni/internal/moduleloader"
 	"github.com/omni-lang/omni/internal/parser"
 	"github.com/omni-lang/omni/internal/passes"
 	"github.com/omni-lang/omni/internal/types/checker"
 	"github.com/omni-lang/omni/internal/vm"
 )
 
+// Options configures a program run.
+type Options struct {
+	// Args are the program's command-line arguments.
+	Args []string
+	// Verbose logs each compilation stage.
+	Verbose bool
+	// ImportMap redirects imports to replacement source files, taking
+	// precedence over the standard library and local modules.
+	ImportMap moduleloader.ImportMap
+	// Sandbox, when set, restricts the program's file, network and
+	// environment access as described by vm.SandboxPolicy.
+	Sandbox *vm.SandboxPolicy
+	// RunPattern, when not empty, is a regular expression selecting the
+	// tests that run by name; see vm.ExecuteOptions.TestFilter.
+	RunPattern string
+	// OverflowCheck fails the run when int arithmetic overflows; see
+	// vm.ExecuteOptions.OverflowCheck.
+	OverflowCheck bool
+}
+
 // Execute compiles and executes the provided OmniLang source via the VM backend.
 func Execute(path string, args []string, verbose bool) (vm.Result, error) {
+	return ExecuteWithOptions(path, Options{Args: args, Verbose: verbose})
+}
+
+// ExecuteWithOptions is Execute with the full set of run options.
+func ExecuteWithOptions(path string, opts Options) (vm.Result, error) {
+	return ExecuteContext(context.Background(), path, opts)
+}
+
+// ExecuteContext is ExecuteWithOptions with a context that bounds the
+// program's execution. When ctx is cancelled or its deadline passes, the
+// VM stops and a vm.TimeoutError is returned.
+func ExecuteContext(ctx context.Context, path string, opts Options) (vm.Result, error) {
 	if filepath.Ext(path) != ".omni" {
 		return vm.Result{}, fmt.Errorf("%s: unsupported input (expected .omni)", path)
 	}
+	verbose := opts.Verbose
 
-	vm.SetCLIArgs(args)
-	defer vm.SetCLIArgs(nil)
+	var testFilter *regexp.Regexp
+	if opts.RunPattern != "" {
+		var err error
+		if testFilter, err = regexp.Compile(opts.RunPattern); err != nil {
+			return vm.Result{}, fmt.Errorf("invalid run pattern: %w", err)
+		}
+	}
 
-	vm.SetCLIArgs(args)
+	vm.SetCLIArgs(opts.Args)
 	defer vm.SetCLIArgs(nil)
 
+	vm.SetModuleOverrides(opts.ImportMap.Modules())
+	defer vm.SetModuleOverrides(nil)
+
+	mirModule, err := compile(path, opts)
+	if err != nil {
+		return vm.Result{}, err
+	}
+
+	logger := logging.Logger()
+	if verbose {
+		logger.DebugString("Executing program...")
+	}
+	result, err := vm.ExecuteWithOptions(ctx, mirModule, "main", vm.ExecuteOptions{Sandbox: opts.Sandbox, TestFilter: testFilter, OverflowCheck: opts.OverflowCheck})
+	if err != nil {
+		return vm.Result{}, err
+	}
+
+	if verbose {
+		logger.DebugString("Execution completed!")
+	}
+	return result, nil
+}
+
+// compile parses, checks and lowers the program at path to an optimised MIR
+// module for the VM.
+func compile(path string, opts Options) (*mir.Module, error) {
+	verbose := opts.Verbose
 	logger := logging.Logger()
 
 	if verbose {
@@ -35,7 +104,7 @@ func Execute(path string, args []string, verbose bool) (vm.Result, error) {
 
 	src, err := os.ReadFile(path)
 	if err != nil {
-		return vm.Result{}, fmt.Errorf("read source: %w", err)
+		return nil, fmt.Errorf("read source: %w", err)
 	}
 
 	if verbose {
@@ -43,22 +112,24 @@ func Execute(path string, args []string, verbose bool) (vm.Result, error) {
 	}
 	mod, err := parser.Parse(path, string(src))
 	if err != nil {
-		return vm.Result{}, err
+		return nil, err
 	}
 
 	if verbose {
 		logger.DebugString("Merging imported modules...")
 	}
 	// Merge locally imported modules' functions into the main module
-	if err := compiler.MergeImportedModules(mod, filepath.Dir(path), false, "vm"); err != nil {
-		return vm.Result{}, err
+	loader := compiler.NewModuleLoader()
+	loader.SetImportMap(opts.ImportMap)
+	if err := compiler.MergeImportedModulesWithLoader(mod, loader, filepath.Dir(path), false, "vm"); err != nil {
+		return nil, err
 	}
 
 	if verbose {
 		logger.DebugString("Type checking...")
 	}
-	if err := checker.Check(path, string(src), mod); err != nil {
-		return vm.Result{}, err
+	if _, err := checker.CheckWithOptions(path, string(src), mod, checker.Options{ImportMap: opts.ImportMap}); err != nil {
+		return nil, err
 	}
 
 	if verbose {
@@ -66,7 +137,7 @@ func Execute(path string, args []string, verbose bool) (vm.Result, error) {
 	}
 	mirModule, err := builder.BuildModule(mod)
 	if err != nil {
-		return vm.Result{}, err
+		return nil, err
 	}
 
 	if verbose {
@@ -74,26 +145,25 @@ func Execute(path string, args []string, verbose bool) (vm.Result, error) {
 	}
 	pipeline := passes.NewPipeline("runner")
 	if _, err := pipeline.Run(*mirModule); err != nil {
-		return vm.Result{}, err
-	}
-
-	if verbose {
-		logger.DebugString("Executing program...")
+		return nil, err
 	}
-	result, err := vm.Execute(mirModule, "main")
-	if err != nil {
-		return vm.Result{}, err
-	}
-
-	if verbose {
-		logger.DebugString("Execution completed!")
-	}
-	return result, nil
+	return mirModule, nil
 }
 
 // Run wraps Execute and prints the result to stdout for CLI usage.
 func Run(path string, args []string, verbose bool) error {
-	result, err := Execute(path, args, verbose)
+	return RunWithOptions(path, Options{Args: args, Verbose: verbose})
+}
+
+// RunWithOptions is Run with the full set of run options.
+func RunWithOptions(path string, opts Options) error {
+	return RunContext(context.Background(), path, opts)
+}
+
+// RunContext is RunWithOptions with a context that bounds execution, as in
+// ExecuteContext.
+func RunContext(ctx context.Context, path string, opts Options) error {
+	result, err := ExecuteContext(ctx, path, opts)
 	if err != nil {
 		var exitErr vm.ExitError
 		if errors.As(err, &exitErr) {
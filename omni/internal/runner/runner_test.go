@@ -1,11 +1,16 @@
 package runner_test
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/omni-lang/omni/internal/moduleloader"
 	"github.com/omni-lang/omni/internal/runner"
+	"github.com/omni-lang/omni/internal/vm"
 )
 
 func TestRunnerExecutesProgram(t *testing.T) {
@@ -30,3 +35,206 @@ func main():int {
 		t.Fatalf("expected 42, got %v", res.Value)
 	}
 }
+
+func TestRunnerImportMapMocksHTTP(t *testing.T) {
+	src := `import std
+import std.network.http as http
+
+func main():int {
+  let resp:HTTPResponse = http.get("https://example.com/api/users")
+  if resp.status_code != 200 {
+    return 1
+  }
+  if resp.body != "[\"alice\", \"bob\"]" {
+    return 2
+  }
+  return 0
+}
+`
+	mock := `// Stub std.network.http that never touches the network
+struct HTTPResponse {
+  status_code:int
+  body:string
+}
+
+func get(url:string):HTTPResponse {
+  return HTTPResponse{status_code: 200, body: "[\"alice\", \"bob\"]"}
+}
+`
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.omni")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "mocks"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mocks", "http.omni"), []byte(mock), 0o644); err != nil {
+		t.Fatalf("write mock: %v", err)
+	}
+	mapPath := filepath.Join(dir, "imports.json")
+	if err := os.WriteFile(mapPath, []byte(`{"std.network.http": "mocks/http.omni"}`), 0o644); err != nil {
+		t.Fatalf("write import map: %v", err)
+	}
+
+	if _, err := runner.Execute(path, nil, false); err == nil {
+		t.Fatal("expected std.network.http to be unresolvable without an import map")
+	}
+
+	importMap, err := moduleloader.LoadImportMap(mapPath)
+	if err != nil {
+		t.Fatalf("load import map: %v", err)
+	}
+	res, err := runner.ExecuteWithOptions(path, runner.Options{ImportMap: importMap})
+	if err != nil {
+		t.Fatalf("runner execute failed: %v", err)
+	}
+	if res.Value != 0 {
+		t.Fatalf("expected the mocked response (0), got %v", res.Value)
+	}
+}
+
+func TestRunnerImportMapOverridesBuiltin(t *testing.T) {
+	src := `import std
+import std.io.diff
+
+func main():int {
+  if diff.unified("a\n", "b\n", 3) != "stubbed" {
+    return 1
+  }
+  return 0
+}
+`
+	mock := `func unified(original:string, modified:string, context:int):string {
+  return "stubbed"
+}
+`
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.omni")
+	mockPath := filepath.Join(dir, "diff_stub.omni")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	if err := os.WriteFile(mockPath, []byte(mock), 0o644); err != nil {
+		t.Fatalf("write mock: %v", err)
+	}
+
+	res, err := runner.ExecuteWithOptions(path, runner.Options{
+		ImportMap: moduleloader.ImportMap{"std.io.diff": mockPath},
+	})
+	if err != nil {
+		t.Fatalf("runner execute failed: %v", err)
+	}
+	if res.Value != 0 {
+		t.Fatalf("expected the stub to replace the built-in diff, got %v", res.Value)
+	}
+}
+
+func TestRunnerTimeoutStopsInfiniteLoop(t *testing.T) {
+	src := `func main():int {
+  var n:int = 0
+  while true {
+    n = n + 1
+  }
+  return n
+}
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.omni")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := runner.ExecuteContext(ctx, path, runner.Options{})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("timeout took %s to stop the program", elapsed)
+	}
+	var timeoutErr vm.TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected vm.TimeoutError, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected error to wrap context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestRunnerRunPatternSelectsTests(t *testing.T) {
+	src := `import std
+import std.testing
+
+func main():int {
+  var ran:int = 0
+  std.test.start("parse_numbers")
+  ran = ran + 1
+  std.test.end("parse_numbers", true)
+  std.test.start("lex_strings")
+  ran = ran + 10
+  std.test.end("lex_strings", false)
+
+  var suite = std.testing.suite()
+  suite = std.testing.expect(suite, "parse_idents", true, "")
+  suite = std.testing.expect(suite, "lex_broken", false, "fails")
+  return ran * 100 + std.testing.failures(suite)
+}
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.omni")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	cases := []struct {
+		pattern string
+		want    int
+		matched int
+	}{
+		{"", 1101, 0},
+		{"parse", 100, 2},
+		{"^lex", 1001, 2},
+		{"no_such_test", 0, 0},
+	}
+	for _, tc := range cases {
+		res, err := runner.ExecuteWithOptions(path, runner.Options{RunPattern: tc.pattern})
+		if err != nil {
+			t.Fatalf("run %q: %v", tc.pattern, err)
+		}
+		if res.Value != tc.want {
+			t.Errorf("run %q: got %v, want %d", tc.pattern, res.Value, tc.want)
+		}
+		if got := vm.MatchedTests(); got != tc.matched {
+			t.Errorf("run %q: %d tests matched, want %d", tc.pattern, got, tc.matched)
+		}
+	}
+
+	if _, err := runner.ExecuteWithOptions(path, runner.Options{RunPattern: "("}); err == nil {
+		t.Fatal("expected an error for an invalid run pattern")
+	}
+}
+
+func TestRunnerOverflowCheckSeesFoldedConstants(t *testing.T) {
+	sources := map[string]vm.ArithmeticError{
+		"func main():int {\n  let a:int = 2147483647\n  return a + 1\n}\n": {Op: "add", Left: 2147483647, Right: 1},
+		"func main():int => 2147483647 * 2\n":                              {Op: "mul", Left: 2147483647, Right: 2},
+	}
+	for src, want := range sources {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "main.omni")
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatalf("write temp file: %v", err)
+		}
+
+		err := runner.RunWithOptions(path, runner.Options{OverflowCheck: true})
+		var overflow vm.ArithmeticError
+		if !errors.As(err, &overflow) {
+			t.Fatalf("%q: expected a vm.ArithmeticError, got %v", src, err)
+		}
+		if overflow != want {
+			t.Errorf("%q: error = %+v, want %+v", src, overflow, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Package target describes the operating system and architecture that omnic
+// builds executables for.
+package target
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Target is an OS/architecture pair using Go's GOOS and GOARCH names.
+type Target struct {
+	OS   string
+	Arch string
+}
+
+// supported maps each supported OS to the architectures omnic can target on it.
+var supported = map[string][]string{
+	"linux":   {"386", "amd64", "arm", "arm64"},
+	"darwin":  {"amd64", "arm64"},
+	"windows": {"386", "amd64", "arm64"},
+	"freebsd": {"amd64", "arm64"},
+}
+
+// Host returns the platform omnic is running on.
+func Host() Target {
+	return Target{OS: runtime.GOOS, Arch: runtime.GOARCH}
+}
+
+// Parse parses an "os/arch" pair such as linux/amd64 or windows/arm64.
+func Parse(s string) (Target, error) {
+	goos, arch, ok := strings.Cut(s, "/")
+	if !ok || goos == "" || arch == "" {
+		return Target{}, fmt.Errorf("invalid target %q: expected os/arch, e.g. linux/amd64", s)
+	}
+	t := Target{OS: goos, Arch: arch}
+	if err := t.Validate(); err != nil {
+		return Target{}, err
+	}
+	return t, nil
+}
+
+// Validate reports whether omnic can build for t.
+func (t Target) Validate() error {
+	arches, ok := supported[t.OS]
+	if !ok {
+		return fmt.Errorf("unsupported target OS %q (supported: %s)", t.OS, strings.Join(supportedOSes(), ", "))
+	}
+	for _, arch := range arches {
+		if arch == t.Arch {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported architecture %q for %s (supported: %s)", t.Arch, t.OS, strings.Join(arches, ", "))
+}
+
+func supportedOSes() []string {
+	names := make([]string, 0, len(supported))
+	for name := range supported {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String returns t in os/arch form.
+func (t Target) String() string {
+	return t.OS + "/" + t.Arch
+}
+
+// IsHost reports whether t is the platform omnic is running on.
+func (t Target) IsHost() bool {
+	return t == Host()
+}
+
+// ExeSuffix returns the file extension of executables for t.
+func (t Target) ExeSuffix() string {
+	if t.OS == "windows" {
+		return ".exe"
+	}
+	return ""
+}
+
+// Triple returns the LLVM target triple for t, as accepted by clang's
+// --target flag.
+func (t Target) Triple() string {
+	arch := map[string]string{
+		"386":   "i686",
+		"amd64": "x86_64",
+		"arm":   "armv7",
+		"arm64": "aarch64",
+	}[t.Arch]
+	if arch == "" {
+		arch = t.Arch
+	}
+
+	switch t.OS {
+	case "darwin":
+		if t.Arch == "arm64" {
+			arch = "arm64"
+		}
+		return arch + "-apple-darwin"
+	case "windows":
+		return arch + "-pc-windows-gnu"
+	case "linux":
+		if t.Arch == "arm" {
+			return arch + "-unknown-linux-gnueabihf"
+		}
+		return arch + "-unknown-linux-gnu"
+	default:
+		return arch + "-unknown-" + t.OS
+	}
+}
@@ -0,0 +1,67 @@
+package target
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	got, err := Parse("windows/arm64")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got != (Target{OS: "windows", Arch: "arm64"}) {
+		t.Fatalf("Parse = %+v", got)
+	}
+	if got.String() != "windows/arm64" {
+		t.Fatalf("String = %q", got.String())
+	}
+
+	for input, want := range map[string]string{
+		"linux":        "expected os/arch",
+		"/amd64":       "expected os/arch",
+		"plan9/amd64":  "unsupported target OS",
+		"darwin/386":   "unsupported architecture",
+		"linux/mips64": "unsupported architecture",
+	} {
+		if _, err := Parse(input); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want %q", input, err, want)
+		}
+	}
+}
+
+func TestHostIsSupported(t *testing.T) {
+	host := Host()
+	if !host.IsHost() {
+		t.Fatalf("Host().IsHost() = false")
+	}
+	if _, ok := supported[host.OS]; ok {
+		if err := host.Validate(); err != nil {
+			t.Fatalf("host %s rejected: %v", host, err)
+		}
+	}
+}
+
+func TestExeSuffixAndTriple(t *testing.T) {
+	tests := []struct {
+		target Target
+		suffix string
+		triple string
+	}{
+		{Target{"linux", "amd64"}, "", "x86_64-unknown-linux-gnu"},
+		{Target{"linux", "arm"}, "", "armv7-unknown-linux-gnueabihf"},
+		{Target{"linux", "arm64"}, "", "aarch64-unknown-linux-gnu"},
+		{Target{"darwin", "arm64"}, "", "arm64-apple-darwin"},
+		{Target{"darwin", "amd64"}, "", "x86_64-apple-darwin"},
+		{Target{"windows", "386"}, ".exe", "i686-pc-windows-gnu"},
+		{Target{"freebsd", "amd64"}, "", "x86_64-unknown-freebsd"},
+	}
+	for _, tt := range tests {
+		if got := tt.target.ExeSuffix(); got != tt.suffix {
+			t.Errorf("%s ExeSuffix = %q, want %q", tt.target, got, tt.suffix)
+		}
+		if got := tt.target.Triple(); got != tt.triple {
+			t.Errorf("%s Triple = %q, want %q", tt.target, got, tt.triple)
+		}
+	}
+}
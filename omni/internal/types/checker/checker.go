@@ -18,12 +18,31 @@ const (
 	typeError = "<error>"
 	typeInfer = "<inferred>"
 	typeVoid  = "void"
+	// typeAny is the type of values whose type is only known at run time,
+	// such as the arguments of std.string.format or a parsed JSON document.
+	// Its fields and elements are any again, and a cast recovers a concrete
+	// type.
+	typeAny = "any"
 )
 
 // Check runs the OmniLang type checker over the provided module and returns an
 // aggregated diagnostic error if any issues are found.
 func Check(filename, src string, mod *ast.Module) error {
+	_, err := CheckWithOptions(filename, src, mod, Options{})
+	return err
+}
+
+// Warning is a diagnostic of severity lexer.Warning, such as the unreachable
+// code reported under Options.WarnDeadCode. Its Span locates the offending
+// code.
+type Warning = lexer.Diagnostic
+
+// CheckWithOptions validates mod like Check and additionally runs the
+// optional checks selected by opts. Warnings are returned separately unless
+// opts.WarningsAsErrors promotes them into the returned error.
+func CheckWithOptions(filename, src string, mod *ast.Module, opts Options) ([]Warning, error) {
 	c := &Checker{
+		options:          opts,
 		filename:         filename,
 		lines:            splitLines(src),
 		knownTypes:       make(map[string]struct{}),
@@ -31,11 +50,15 @@ func Check(filename, src string, mod *ast.Module) error {
 		structFields:     make(map[string]map[string]string),
 		structTypeParams: make(map[string][]ast.TypeParam),
 		functions:        make(map[string]FunctionSignature),
+		methods:          make(map[string]map[string]string),
+		interfaces:       make(map[string][]interfaceMethod),
+		enums:            make(map[string][]string),
 		imports:          make(map[string]bool),
 		moduleLoader:     *moduleloader.NewModuleLoader(),
 		typeParams:       make(map[string]bool),
 		processedImports: make(map[string]bool),
 	}
+	c.moduleLoader.SetImportMap(opts.ImportMap)
 
 	// Add the omni std directory to search paths
 	// Find the omni root directory by looking for the std directory
@@ -68,10 +91,25 @@ func Check(filename, src string, mod *ast.Module) error {
 	c.checkModule(mod)
 	c.leaveScope()
 
-	if len(c.diagnostics) == 0 {
-		return nil
+	var warnings []Warning
+	errs := make([]error, 0, len(c.diagnostics))
+	for _, err := range c.diagnostics {
+		diag, ok := err.(lexer.Diagnostic)
+		if !ok || diag.Severity == lexer.Error {
+			errs = append(errs, err)
+			continue
+		}
+		if opts.WarningsAsErrors {
+			diag.Severity = lexer.Error
+			errs = append(errs, diag)
+			continue
+		}
+		warnings = append(warnings, diag)
 	}
-	return errors.Join(c.diagnostics...)
+	if len(errs) == 0 {
+		return warnings, nil
+	}
+	return warnings, errors.Join(errs...)
 }
 
 // Checker encapsulates the mutable state required to validate an OmniLang AST.
@@ -84,6 +122,13 @@ type Checker struct {
 	structFields     map[string]map[string]string
 	structTypeParams map[string][]ast.TypeParam // Store type parameters for generic structs
 	functions        map[string]FunctionSignature
+	// methods maps a struct type and a method name to the function that
+	// implements the method
+	methods map[string]map[string]string
+	// interfaces holds the methods each interface type requires
+	interfaces map[string][]interfaceMethod
+	// enums holds the variants of each enum type, in declaration order
+	enums map[string][]string
 
 	scopes      []map[string]Symbol
 	diagnostics []error
@@ -100,6 +145,9 @@ type Checker struct {
 	typeParams map[string]bool // Currently active type parameters
 
 	processedImports map[string]bool
+
+	// options selects the optional checks run by lintFunc
+	options Options
 }
 
 // enterTypeParams enters a new type parameter scope
@@ -194,6 +242,61 @@ func (c *Checker) inferTypeParametersFromGeneric(expected, argType string, typeP
 	return inferred
 }
 
+// inferTypeParametersFromFunction infers type parameters from function types.
+// For example, if expected is "(T) -> U" and argType is "(int) -> string", it
+// infers T = int and U = string.
+func (c *Checker) inferTypeParametersFromFunction(expected, argType string, typeParams []ast.TypeParam) map[string]string {
+	inferred := make(map[string]string)
+	expectedParams := c.parseFunctionTypeParams(expected)
+	argParams := c.parseFunctionTypeParams(argType)
+	if expectedParams == nil || argParams == nil || len(expectedParams) != len(argParams) {
+		return inferred
+	}
+	expectedParts := append(expectedParams, functionReturnType(expected))
+	argParts := append(argParams, functionReturnType(argType))
+	for i, expectedPart := range expectedParts {
+		argPart := argParts[i]
+		if argPart == typeInfer || argPart == typeError {
+			continue
+		}
+		if c.isFunctionTypeParam(expectedPart, typeParams) {
+			inferred[expectedPart] = argPart
+			continue
+		}
+		for typeParam, concreteType := range c.inferTypeParametersFromGeneric(expectedPart, argPart, typeParams) {
+			inferred[typeParam] = concreteType
+		}
+	}
+	return inferred
+}
+
+// functionReturnType returns the result type of a function type string like
+// "(int, int) -> int".
+func functionReturnType(funcType string) string {
+	arrowIndex := strings.Index(funcType, ") -> ")
+	if arrowIndex == -1 {
+		return ""
+	}
+	return strings.TrimSpace(funcType[arrowIndex+len(") -> "):])
+}
+
+// lambdaParamTypes returns the parameter types a lambda passed for the
+// function type param gets, given the type parameters inferred so far. A
+// parameter whose type is not known yet is left to typeInfer.
+func (c *Checker) lambdaParamTypes(param string, substitutions map[string]string, typeParams []ast.TypeParam) []string {
+	paramTypes := c.parseFunctionTypeParams(param)
+	for i, paramType := range paramTypes {
+		for typeParam, concreteType := range substitutions {
+			paramType = c.substituteTypeParam(paramType, typeParam, concreteType)
+		}
+		if c.isFunctionTypeParam(paramType, typeParams) {
+			paramType = typeInfer
+		}
+		paramTypes[i] = paramType
+	}
+	return paramTypes
+}
+
 // findMatchingGreater finds the matching > for a < at the given position
 // Handles nested generics by tracking depth
 func (c *Checker) findMatchingGreater(typeStr string, lessPos int) int {
@@ -252,6 +355,12 @@ func (c *Checker) splitGenericArgs(s string) []string {
 type Symbol struct {
 	Type    string
 	Mutable bool
+	// ReadCount is how many times an expression read the symbol
+	ReadCount int
+	// local is the kind of a local the unused variable checks report when
+	// nothing reads it, localVariable or localParameter, declared at span
+	local string
+	span  lexer.Span
 }
 
 // FunctionSignature captures parameter and return type information for a function.
@@ -259,6 +368,12 @@ type FunctionSignature struct {
 	Params     []string
 	Return     string
 	TypeParams []ast.TypeParam // Generic type parameters
+	// Variadic marks a function whose last parameter is variadic; its
+	// entry in Params is the element type of the arguments it takes.
+	Variadic bool
+	// Defaults counts the parameters with a default value, which are the
+	// last ones before a variadic parameter
+	Defaults int
 }
 
 type functionContext struct {
@@ -266,13 +381,22 @@ type functionContext struct {
 	ReturnType string
 	IsAsync    bool
 	HasReturn  bool
+	// NamedReturn is the result variable of a function declared with
+	// `as name`, which a bare return or the end of the body returns
+	NamedReturn string
 }
 
 func (c *Checker) initBuiltins() {
 	primitives := []types.Kind{
 		types.KindInt,
+		types.KindInt64,
 		types.KindLong,
 		types.KindByte,
+		types.KindUint,
+		types.KindUint8,
+		types.KindUint16,
+		types.KindUint32,
+		types.KindUint64,
 		types.KindFloat,
 		types.KindDouble,
 		types.KindBool,
@@ -285,13 +409,84 @@ func (c *Checker) initBuiltins() {
 	}
 	c.knownTypes["array"] = struct{}{}
 	c.knownTypes["map"] = struct{}{}
+	c.knownTypes["tuple"] = struct{}{}
 	c.knownTypes["Promise"] = struct{}{}
+	// std.collections.deque; deque<T> is opaque to programs
+	c.knownTypes["deque"] = struct{}{}
+	// std.collections.trie; Trie<T> is opaque to programs
+	c.knownTypes["Trie"] = struct{}{}
+	// std.collections.ordered_map; OrderedMap<K, V> is opaque to programs
+	c.knownTypes["OrderedMap"] = struct{}{}
+	c.knownTypes[typeAny] = struct{}{}
 
 	// Add builtin functions
 	c.functions["len"] = FunctionSignature{
 		Params: []string{typeInfer}, // Accept any array type
 		Return: "int",
 	}
+	// string.split is a runtime intrinsic, callable without importing std
+	split := FunctionSignature{Params: []string{"string", "string"}, Return: "[]<string>"}
+	c.functions["string.split"] = split
+	c.functions["std.string.split"] = split
+	format := FunctionSignature{Params: []string{"string", typeAny}, Return: "string", Variadic: true}
+	c.functions["string.format"] = format
+	c.functions["std.string.format"] = format
+	// The replacement intrinsics keep their types in the C backend, where
+	// std.string is not merged
+	replace := FunctionSignature{Params: []string{"string", "string", "string"}, Return: "string"}
+	c.functions["std.string.replace"] = replace
+	c.functions["std.string.replace_all"] = replace
+	c.functions["std.string.count_occurrences"] = FunctionSignature{Params: []string{"string", "string"}, Return: "int"}
+	// The parse functions return the value and whether s held one
+	c.functions["std.string.parse_int"] = FunctionSignature{Params: []string{"string"}, Return: "tuple<int,bool>"}
+	c.functions["std.string.parse_float"] = FunctionSignature{Params: []string{"string"}, Return: "tuple<float,bool>"}
+	c.functions["std.string.parse_bool"] = FunctionSignature{Params: []string{"string"}, Return: "tuple<bool,bool>"}
+	c.functions["json.stringify"] = FunctionSignature{Params: []string{typeAny}, Return: "string"}
+	c.functions["std.json.stringify"] = c.functions["json.stringify"]
+	c.functions["json.parse"] = FunctionSignature{Params: []string{"string"}, Return: typeAny}
+	c.functions["std.json.parse"] = c.functions["json.parse"]
+	c.functions["regex.find"] = FunctionSignature{Params: []string{"string", "string"}, Return: "string?"}
+	c.functions["std.regex.find"] = c.functions["regex.find"]
+	c.functions["regex.find_all"] = split
+	c.functions["std.regex.find_all"] = split
+	c.functions["regex.groups"] = split
+	c.functions["std.regex.groups"] = split
+	// process.run returns a ProcessResult, which is known without importing
+	// std.process like the functions that return it
+	c.knownTypes["ProcessResult"] = struct{}{}
+	c.structFields["ProcessResult"] = map[string]string{"stdout": "string", "stderr": "string", "exit_code": "int"}
+	c.functions["process.run"] = FunctionSignature{Params: []string{"string", "array<string>"}, Return: "ProcessResult"}
+	c.functions["std.process.run"] = c.functions["process.run"]
+	c.functions["process.run_async"] = FunctionSignature{Params: []string{"string", "array<string>"}, Return: "Promise<ProcessResult>"}
+	c.functions["std.process.run_async"] = c.functions["process.run_async"]
+	c.functions["path.join"] = FunctionSignature{Params: []string{"string"}, Return: "string", Variadic: true}
+	c.functions["std.path.join"] = c.functions["path.join"]
+	for _, name := range []string{"clean", "dirname", "basename", "ext", "abs"} {
+		c.functions["path."+name] = FunctionSignature{Params: []string{"string"}, Return: "string"}
+		c.functions["std.path."+name] = c.functions["path."+name]
+	}
+	c.functions["path.is_abs"] = FunctionSignature{Params: []string{"string"}, Return: "bool"}
+	c.functions["std.path.is_abs"] = c.functions["path.is_abs"]
+	c.functions["env.get"] = FunctionSignature{Params: []string{"string", "string"}, Return: "string"}
+	c.functions["env.get_int"] = FunctionSignature{Params: []string{"string", "int"}, Return: "int"}
+	c.functions["env.get_bool"] = FunctionSignature{Params: []string{"string", "bool"}, Return: "bool"}
+	c.functions["env.require"] = FunctionSignature{Params: []string{"string"}, Return: "string"}
+	for _, name := range []string{"get", "get_int", "get_bool", "require"} {
+		c.functions["std.env."+name] = c.functions["env."+name]
+	}
+	for name, elem := range map[string]string{"ints": "int", "strings": "string", "floats": "float", "reverse_ints": "int", "reverse_strings": "string"} {
+		c.functions["sort."+name] = FunctionSignature{Params: []string{"array<" + elem + ">"}, Return: typeVoid}
+		c.functions["std.sort."+name] = c.functions["sort."+name]
+	}
+	// The higher-order array functions are generic: T is the element type of
+	// the array and U the result type of the callback
+	t, u := ast.TypeParam{Name: "T"}, ast.TypeParam{Name: "U"}
+	c.functions["collections.filter"] = FunctionSignature{Params: []string{"array<T>", "(T) -> bool"}, Return: "array<T>", TypeParams: []ast.TypeParam{t}}
+	c.functions["collections.map_fn"] = FunctionSignature{Params: []string{"array<T>", "(T) -> U"}, Return: "array<U>", TypeParams: []ast.TypeParam{t, u}}
+	c.functions["collections.reduce"] = FunctionSignature{Params: []string{"array<T>", "U", "(U, T) -> U"}, Return: "U", TypeParams: []ast.TypeParam{t, u}}
+	for _, name := range []string{"filter", "map_fn", "reduce"} {
+		c.functions["std.collections."+name] = c.functions["collections."+name]
+	}
 }
 
 func (c *Checker) collectTypeDecls(mod *ast.Module) {
@@ -310,6 +505,13 @@ func (c *Checker) collectTypeDecls(mod *ast.Module) {
 			}
 		case *ast.EnumDecl:
 			c.knownTypes[d.Name] = struct{}{}
+			variants := make([]string, 0, len(d.Variants))
+			for _, v := range d.Variants {
+				variants = append(variants, v.Name)
+			}
+			c.enums[d.Name] = variants
+		case *ast.InterfaceDecl:
+			c.knownTypes[d.Name] = struct{}{}
 		}
 	}
 }
@@ -348,6 +550,10 @@ func (c *Checker) registerTopLevelSymbols(mod *ast.Module) {
 			// Store the full function type for first-class function support
 			funcType := buildFunctionType(sig.Params, sig.Return)
 			c.declare(d.Name, funcType, false, d.Span())
+		case *ast.MethodDecl:
+			c.registerMethod(d)
+		case *ast.InterfaceDecl:
+			c.registerInterface(d)
 		}
 	}
 }
@@ -389,7 +595,8 @@ func (c *Checker) buildFunctionSignature(decl *ast.FuncDecl) FunctionSignature {
 		}
 	}
 
-	return FunctionSignature{Params: params, Return: ret, TypeParams: decl.TypeParams}
+	variadic := len(decl.Params) > 0 && decl.Params[len(decl.Params)-1].Variadic
+	return FunctionSignature{Params: params, Return: ret, TypeParams: decl.TypeParams, Variadic: variadic, Defaults: countDefaults(decl.Params)}
 }
 
 func (c *Checker) checkModule(mod *ast.Module) {
@@ -408,6 +615,13 @@ func (c *Checker) checkModule(mod *ast.Module) {
 			// They were already validated when the std module was parsed
 			if !strings.Contains(d.Name, ".") {
 				c.checkFunc(d)
+				c.lintFunc(d)
+			}
+		case *ast.MethodDecl:
+			if _, ok := c.functions[d.FuncName()]; ok {
+				fn := d.Function()
+				c.checkFunc(fn)
+				c.lintFunc(fn)
 			}
 		case *ast.TypeAliasDecl:
 			c.checkTypeAliasDecl(d)
@@ -468,6 +682,7 @@ func (c *Checker) checkFunc(decl *ast.FuncDecl) {
 		expectedReturn = innerReturnType
 	}
 
+	c.checkParamDefaults(decl)
 	c.pushFunctionContext(decl.Name, expectedReturn, decl.IsAsync)
 	c.enterScope()
 	for i, param := range decl.Params {
@@ -477,7 +692,21 @@ func (c *Checker) checkFunc(decl *ast.FuncDecl) {
 				c.report(param.Span, fmt.Sprintf("parameter %q type mismatch", param.Name), "align the signature with the annotation")
 			}
 		}
-		c.declare(param.Name, paramType, true, param.Span)
+		if param.Variadic {
+			// The body sees the variadic arguments as an array
+			paramType = buildGeneric("[]", []string{paramType})
+		}
+		// The receiver of a method and the parameters of a function without
+		// a body, such as an extern declaration, need not be used
+		isReceiver := i == 0 && strings.Contains(decl.Name, ".")
+		if isReceiver || (decl.Body == nil && decl.ExprBody == nil) {
+			c.declare(param.Name, paramType, true, param.Span)
+		} else {
+			c.declareLocal(param.Name, paramType, true, localParameter, param.Span)
+		}
+	}
+	if decl.ReturnName != "" {
+		c.declareNamedReturn(decl, expectedReturn)
 	}
 
 	if decl.ExprBody != nil {
@@ -554,8 +783,8 @@ func (c *Checker) checkLet(decl *ast.LetDecl, mutable bool) {
 	if finalType == typeInfer {
 		finalType = valueType
 	} else if valueType != typeInfer && valueType != typeError && !c.isAssignable(valueType, finalType) {
-		c.report(decl.Span(), fmt.Sprintf("type mismatch: cannot assign %s to %s", valueType, finalType),
-			fmt.Sprintf("convert the expression to %s or change the variable type to %s", finalType, valueType))
+		msg, hint := c.assignMismatch(valueType, finalType)
+		c.report(decl.Span(), msg, hint)
 	}
 
 	if finalType != typeInfer && finalType != typeError {
@@ -610,6 +839,8 @@ func (c *Checker) checkStmt(stmt ast.Stmt) {
 		if c.loopDepth == 0 {
 			c.report(s.Span(), "continue statement outside of loop", "use continue only inside for or while loops")
 		}
+	case *ast.DestructureStmt:
+		c.checkDestructureStmt(s)
 	case *ast.BindingStmt:
 		c.checkBindingStmt(s)
 	case *ast.ShortVarDeclStmt:
@@ -622,10 +853,10 @@ func (c *Checker) checkStmt(stmt ast.Stmt) {
 		if declaredType == typeInfer {
 			finalType = valueType
 		} else if valueType != typeInfer && valueType != typeError && !c.isAssignable(valueType, declaredType) {
-			c.report(s.Span(), fmt.Sprintf("type mismatch: cannot assign %s to %s", valueType, declaredType),
-				fmt.Sprintf("convert the expression to %s or change the variable type to %s", declaredType, valueType))
+			msg, hint := c.assignMismatch(valueType, declaredType)
+			c.report(s.Span(), msg, hint)
 		}
-		c.declare(s.Name, finalType, true, s.Span())
+		c.declareLocal(s.Name, finalType, true, localVariable, s.Span())
 	case *ast.AssignmentStmt:
 		c.checkAssignmentExpr(&ast.AssignmentExpr{SpanInfo: s.SpanInfo, Left: s.Left, Right: s.Right})
 	case *ast.IncrementStmt:
@@ -646,6 +877,10 @@ func (c *Checker) checkStmt(stmt ast.Stmt) {
 		// Check catch clauses
 		for _, catchClause := range s.CatchClauses {
 			c.enterScope()
+			if catchClause.ExceptionType != "" && !c.isExceptionType(catchClause.ExceptionType) {
+				c.report(catchClause.Span(), fmt.Sprintf("catch type %q is not an exception type", catchClause.ExceptionType),
+					"catch a string, an int or a declared struct type")
+			}
 			if catchClause.ExceptionVar != "" {
 				// Declare the exception variable in the catch scope
 				exceptionType := "string" // Default exception type
@@ -665,9 +900,30 @@ func (c *Checker) checkStmt(stmt ast.Stmt) {
 	case *ast.ThrowStmt:
 		// Check the expression being thrown
 		c.checkExpr(s.Expr)
+		// Like a return, a throw leaves the function
+		if ctx := c.currentFunctionContext(); ctx != nil {
+			ctx.HasReturn = true
+		}
+	case *ast.DeferStmt:
+		// Only a call can be deferred
+		if _, ok := s.Call.(*ast.CallExpr); !ok {
+			c.report(s.Call.Span(), "defer requires a function call", "write the deferred expression as a call, e.g. defer std.file.close(f)")
+			return
+		}
+		c.checkExpr(s.Call)
 	}
 }
 
+// isExceptionType reports whether values of the named type can be thrown
+// and caught: strings, ints and the declared struct types.
+func (c *Checker) isExceptionType(name string) bool {
+	if name == "string" || name == "int" {
+		return true
+	}
+	_, ok := c.structFields[name]
+	return ok
+}
+
 // checkTypeAliasDecl checks a type alias declaration
 func (c *Checker) checkTypeAliasDecl(decl *ast.TypeAliasDecl) {
 	// Check the type expression
@@ -739,10 +995,10 @@ func (c *Checker) checkBindingStmt(stmt *ast.BindingStmt) {
 	if declaredType == typeInfer {
 		finalType = valueType
 	} else if valueType != typeInfer && valueType != typeError && !c.isAssignable(valueType, declaredType) {
-		c.report(stmt.Span(), fmt.Sprintf("type mismatch: cannot assign %s to %s", valueType, declaredType),
-			fmt.Sprintf("convert the expression to %s or change the variable type to %s", declaredType, valueType))
+		msg, hint := c.assignMismatch(valueType, declaredType)
+		c.report(stmt.Span(), msg, hint)
 	}
-	c.declare(stmt.Name, finalType, stmt.Mutable, stmt.Span())
+	c.declareLocal(stmt.Name, finalType, stmt.Mutable, localVariable, stmt.Span())
 }
 
 // resolveEmptyArrayLiteral allows empty array literals to take on an expected type context.
@@ -833,6 +1089,22 @@ func (c *Checker) checkWhileStmt(stmt *ast.WhileStmt) {
 	c.checkBlock(stmt.Body)
 }
 
+// declareNamedReturn declares the result variable of decl with the type the
+// function returns. Control reaching the end of the body returns it, so
+// the function needs no return statement.
+func (c *Checker) declareNamedReturn(decl *ast.FuncDecl, returnType string) {
+	if returnType == typeVoid || returnType == typeInfer {
+		c.report(decl.ReturnNameSpan, fmt.Sprintf("named return %q needs a non-void return type", decl.ReturnName),
+			"declare the type the function returns before `as`")
+		return
+	}
+	c.declare(decl.ReturnName, returnType, true, decl.ReturnNameSpan)
+	if ctx := c.currentFunctionContext(); ctx != nil {
+		ctx.NamedReturn = decl.ReturnName
+		ctx.HasReturn = true
+	}
+}
+
 func (c *Checker) handleReturn(ret *ast.ReturnStmt) {
 	ctx := c.currentFunctionContext()
 	if ctx == nil {
@@ -845,6 +1117,9 @@ func (c *Checker) handleReturn(ret *ast.ReturnStmt) {
 	ctx.HasReturn = true
 	expected := ctx.ReturnType
 	if ret.Value == nil {
+		if ctx.NamedReturn != "" {
+			return
+		}
 		if expected != typeVoid && expected != typeInfer {
 			c.report(ret.Span(), "missing return value", "return an expression matching the function return type")
 		} else if expected == typeInfer {
@@ -865,7 +1140,11 @@ func (c *Checker) handleReturn(ret *ast.ReturnStmt) {
 		return
 	}
 	if valueType != typeError && !c.isAssignable(valueType, expected) {
-		c.report(ret.Value.Span(), fmt.Sprintf("cannot return %s from function returning %s", valueType, expected), "return an expression with the correct type")
+		hint := "return an expression with the correct type"
+		if msg, ok := c.interfaceMismatch(valueType, expected); ok {
+			hint = msg
+		}
+		c.report(ret.Value.Span(), fmt.Sprintf("cannot return %s from function returning %s", valueType, expected), hint)
 	}
 }
 
@@ -895,7 +1174,7 @@ func (c *Checker) validateFunctionReturn(actualType string, span lexer.Span) {
 func (c *Checker) checkExpr(expr ast.Expr) string {
 	switch e := expr.(type) {
 	case *ast.IdentifierExpr:
-		if sym, ok := c.lookupSymbol(e.Name); ok {
+		if sym, ok := c.readSymbol(e.Name); ok {
 			return sym.Type
 		}
 		// Check if it's a builtin function
@@ -949,8 +1228,19 @@ func (c *Checker) checkExpr(expr ast.Expr) string {
 			return "int"
 		case ast.LiteralBinary:
 			return "int"
+		case ast.LiteralInt64:
+			return "int64"
+		case ast.LiteralUint:
+			typ, ok := e.UnsignedType()
+			if !ok {
+				c.report(e.Span(), fmt.Sprintf("constant %su overflows uint64", e.Value), "unsigned literals must fit in 64 bits")
+				return typeError
+			}
+			return typ
 		}
 		return typeInfer
+	case *ast.MatchExpr:
+		return c.checkMatchExpr(e)
 	case *ast.AwaitExpr:
 		// Check if we're in an async function
 		ctx := c.currentFunctionContext()
@@ -979,6 +1269,9 @@ func (c *Checker) checkExpr(expr ast.Expr) string {
 		case "-":
 			if operand != typeError && !isNumeric(operand) {
 				c.report(e.Expr.Span(), fmt.Sprintf("operator - not defined on %s", operand), "use a numeric expression")
+			} else if isUnsigned(operand) {
+				c.report(e.Expr.Span(), fmt.Sprintf("operator - not defined on unsigned %s", operand),
+					fmt.Sprintf("cast to a signed type first, or compute 0 - x in %s to wrap around", operand))
 			}
 			return operand
 		case "~":
@@ -1009,11 +1302,21 @@ func (c *Checker) checkExpr(expr ast.Expr) string {
 			}
 			return valueType
 		}
+		if targetType == typeAny {
+			// An element of an array or a field of an object
+			if indexType != typeError && !c.typesEqual(indexType, "int") && !c.typesEqual(indexType, "string") {
+				c.report(e.Index.Span(), fmt.Sprintf("index of an any value must be int or string, got %s", indexType), "use an integer index or a field name")
+			}
+			return typeAny
+		}
 		if targetType != typeError {
 			c.report(e.Target.Span(), fmt.Sprintf("type %s does not support indexing", targetType), "use an array or map expression")
 		}
 		return typeError
 	case *ast.MemberExpr:
+		if typ, ok := c.checkEnumVariant(e); ok {
+			return typ
+		}
 		targetType := c.checkExpr(e.Target)
 
 		// Handle array method access (e.g., x.len)
@@ -1026,6 +1329,10 @@ func (c *Checker) checkExpr(expr ast.Expr) string {
 				"available methods: len")
 			return typeError
 		}
+		// The fields of an any value are only known at run time
+		if targetType == typeAny {
+			return typeAny
+		}
 
 		if structName, fields, ok := c.resolveStructDefinition(targetType); ok {
 			var typeArgs []string
@@ -1072,9 +1379,17 @@ func (c *Checker) checkExpr(expr ast.Expr) string {
 					return sig.Return
 				}
 
+				// Check if it's a constant merged from the imported module
+				if sym, exists := c.lookupSymbol(qualifiedName); exists {
+					return sym.Type
+				}
+
 				// Check if it's a std function with alias (e.g., io.println -> std.io.println)
 				if c.isStdSymbol("std." + qualifiedName) {
 					name := "std." + qualifiedName
+					if sig, exists := c.functions[name]; exists {
+						return sig.Return
+					}
 					if strings.Contains(name, "io.") {
 						return "void"
 					}
@@ -1096,6 +1411,9 @@ func (c *Checker) checkExpr(expr ast.Expr) string {
 				// Check if it's an aliased std import (e.g., str.concat -> std.string.concat)
 				if c.isAliasedStdSymbol(qualifiedName) {
 					fullName := c.mapAliasToStd(qualifiedName)
+					if sig, exists := c.functions[fullName]; exists {
+						return sig.Return
+					}
 					if strings.Contains(fullName, "io.") {
 						return "void"
 					}
@@ -1123,6 +1441,12 @@ func (c *Checker) checkExpr(expr ast.Expr) string {
 			c.report(e.Span(), fmt.Sprintf("type %s has no members", targetType), "use a struct value for member access")
 		}
 		return typeError
+	case *ast.TupleLiteralExpr:
+		elems := make([]string, 0, len(e.Elements))
+		for _, el := range e.Elements {
+			elems = append(elems, c.checkExpr(el))
+		}
+		return buildGeneric("tuple", elems)
 	case *ast.ArrayLiteralExpr:
 		elementType := typeInfer
 		for _, el := range e.Elements {
@@ -1289,32 +1613,34 @@ func (c *Checker) checkBinaryExpr(expr *ast.BinaryExpr) string {
 				fmt.Sprintf("use numeric expressions (int, float) or strings, got %s and %s", leftType, rightType))
 			return typeError
 		}
-		if !c.typesEqual(leftType, rightType) {
+		resultType, ok := c.binaryOperandType(leftType, rightType)
+		if !ok {
 			c.report(expr.Span(), fmt.Sprintf("operands of %s must have the same type", expr.Op), "convert one side to match the other")
 			return typeError
 		}
-		return leftType
+		return resultType
 	case "-", "*", "/", "%":
 		if !isNumeric(leftType) || !isNumeric(rightType) {
 			c.report(expr.Span(), fmt.Sprintf("operator %s requires numeric operands", expr.Op),
 				fmt.Sprintf("use numeric expressions (int, float), got %s and %s", leftType, rightType))
 			return typeError
 		}
-		if !c.typesEqual(leftType, rightType) {
+		resultType, ok := c.binaryOperandType(leftType, rightType)
+		if !ok {
 			c.report(expr.Span(), fmt.Sprintf("operands of %s must have the same type", expr.Op), "convert one side to match the other")
 			return typeError
 		}
-		return leftType
+		return resultType
 	case "<", "<=", ">", ">=":
 		// Allow comparison if both operands are the same type (including generic type parameters)
-		if !c.typesEqual(leftType, rightType) {
+		if _, ok := c.binaryOperandType(leftType, rightType); !ok {
 			c.report(expr.Span(), fmt.Sprintf("operands of %s must have the same type", expr.Op),
 				fmt.Sprintf("ensure both sides have the same type, got %s and %s", leftType, rightType))
 			return typeError
 		}
 		return "bool"
 	case "==", "!=":
-		if !c.typesEqual(leftType, rightType) {
+		if _, ok := c.binaryOperandType(leftType, rightType); !ok && !isNullComparison(leftType, rightType) {
 			c.report(expr.Span(), fmt.Sprintf("operands of %s must be comparable", expr.Op), "ensure both sides share the same type")
 		}
 		return "bool"
@@ -1328,14 +1654,19 @@ func (c *Checker) checkBinaryExpr(expr *ast.BinaryExpr) string {
 		// Bitwise operators require integer operands (not floats)
 		if !isInteger(leftType) || !isInteger(rightType) {
 			c.report(expr.Span(), fmt.Sprintf("operator %s requires integer operands", expr.Op),
-				fmt.Sprintf("use integer expressions (int, long, byte), got %s and %s", leftType, rightType))
+				fmt.Sprintf("use integer expressions (int, int64, uint, byte), got %s and %s", leftType, rightType))
 			return typeError
 		}
-		if !c.typesEqual(leftType, rightType) {
+		// A shift keeps the type of the value shifted, whatever the count's
+		if expr.Op == "<<" || expr.Op == ">>" {
+			return leftType
+		}
+		resultType, ok := c.binaryOperandType(leftType, rightType)
+		if !ok {
 			c.report(expr.Span(), fmt.Sprintf("operands of %s must have the same type", expr.Op), "convert one side to match the other")
 			return typeError
 		}
-		return leftType
+		return resultType
 	default:
 		c.report(expr.Span(), fmt.Sprintf("unsupported binary operator %q", expr.Op), "remove the operator or extend the checker")
 		return typeError
@@ -1343,6 +1674,16 @@ func (c *Checker) checkBinaryExpr(expr *ast.BinaryExpr) string {
 }
 
 func (c *Checker) checkCallExpr(expr *ast.CallExpr) string {
+	if c.isMapContains(expr) {
+		return c.checkMapContains(expr)
+	}
+	if name, ok := c.optBuiltin(expr); ok {
+		return c.checkOptBuiltin(expr, name)
+	}
+	if member, ok := c.methodCall(expr); ok {
+		return c.checkMethodCall(expr, member)
+	}
+
 	var calleeType string
 	if expr.Callee != nil {
 		calleeType = c.checkExpr(expr.Callee)
@@ -1359,18 +1700,19 @@ func (c *Checker) checkCallExpr(expr *ast.CallExpr) string {
 
 			// This is a regular function call, not a function type call
 			// Validate argument count
-			if len(expr.Args) != len(sig.Params) {
-				c.report(expr.Span(), fmt.Sprintf("argument count mismatch: function %s expects %d arguments, got %d", ident.Name, len(sig.Params), len(expr.Args)),
+			if !sig.acceptsArgs(len(expr.Args)) {
+				c.report(expr.Span(), sig.arityMismatch(ident.Name, len(expr.Args)),
 					fmt.Sprintf("provide %d argument(s) matching the function signature", len(sig.Params)))
 				return typeError
 			}
 
 			// Validate argument types
+			params := sig.paramsFor(len(expr.Args))
 			for i, arg := range expr.Args {
 				var argType string
 				// Special handling for lambda expressions - infer parameter types from expected function type
-				if lambda, ok := arg.(*ast.LambdaExpr); ok && i < len(sig.Params) {
-					expected := sig.Params[i]
+				if lambda, ok := arg.(*ast.LambdaExpr); ok && i < len(params) {
+					expected := params[i]
 					// If expected type is a function type, use it to infer lambda parameter types
 					if expected != typeInfer && strings.Contains(expected, ") -> ") {
 						expectedParamTypes := c.parseFunctionTypeParams(expected)
@@ -1387,11 +1729,18 @@ func (c *Checker) checkCallExpr(expr *ast.CallExpr) string {
 				} else {
 					argType = c.checkExpr(arg)
 				}
-				if i < len(sig.Params) {
-					expected := sig.Params[i]
-					if expected != typeInfer && argType != typeError && !c.typesEqual(expected, argType) {
-						c.report(arg.Span(), fmt.Sprintf("argument type mismatch: argument %d expects %s, got %s", i+1, expected, argType),
-							fmt.Sprintf("convert the argument to %s or use a %s expression", expected, expected))
+				if i < len(params) {
+					expected := params[i]
+					if expected != typeInfer && argType != typeError && !c.isAssignable(argType, expected) {
+						hint := fmt.Sprintf("convert the argument to %s or use a %s expression", expected, expected)
+						if expected == typeAny {
+							hint = "pass an expression that produces a value"
+						} else if isOptional(argType) && c.typesEqual(optionalBase(argType), expected) {
+							hint = "unwrap it with opt.unwrap or opt.unwrap_or"
+						} else if msg, ok := c.interfaceMismatch(argType, expected); ok {
+							hint = msg
+						}
+						c.report(arg.Span(), fmt.Sprintf("argument type mismatch: argument %d expects %s, got %s", i+1, expected, argType), hint)
 					}
 				}
 			}
@@ -1471,7 +1820,7 @@ func (c *Checker) checkCallExpr(expr *ast.CallExpr) string {
 				}
 				if i < len(expectedParamTypes) {
 					expected := expectedParamTypes[i]
-					if expected != typeInfer && argType != typeError && !c.typesEqual(expected, argType) {
+					if expected != typeInfer && argType != typeError && !c.typesEqual(expected, argType) && !isIntegerWidening(argType, expected) {
 						c.report(arg.Span(), fmt.Sprintf("argument type mismatch: argument %d expects %s, got %s", i+1, expected, argType),
 							fmt.Sprintf("convert the argument to %s or use a %s expression", expected, expected))
 					}
@@ -1508,21 +1857,28 @@ func (c *Checker) checkCallExpr(expr *ast.CallExpr) string {
 
 	if qualifiedName != "" {
 		if sig, exists := c.functions[qualifiedName]; exists {
-			if len(expr.Args) != len(sig.Params) {
-				c.report(expr.Span(), fmt.Sprintf("argument count mismatch: function %s expects %d arguments, got %d", qualifiedName, len(sig.Params), len(expr.Args)),
+			if len(sig.TypeParams) > 0 {
+				return c.checkGenericFunctionCall(expr, sig, qualifiedName)
+			}
+			if !sig.acceptsArgs(len(expr.Args)) {
+				c.report(expr.Span(), sig.arityMismatch(qualifiedName, len(expr.Args)),
 					fmt.Sprintf("provide %d argument(s) matching the function signature: %s(%s)", len(sig.Params), qualifiedName, strings.Join(sig.Params, ", ")))
 			}
+			params := sig.paramsFor(len(expr.Args))
 			for i, arg := range expr.Args {
 				argType := c.checkExpr(arg)
-				if i < len(sig.Params) {
-					expected := sig.Params[i]
+				if i < len(params) {
+					expected := params[i]
 					// Special handling for len() function - accept any array type
 					if qualifiedName == "len" && expected == typeInfer {
-						if !strings.HasPrefix(argType, "[]<") && !strings.HasPrefix(argType, "array<") {
+						if !strings.HasPrefix(argType, "[]<") && !strings.HasPrefix(argType, "array<") && argType != typeAny {
 							c.report(arg.Span(), fmt.Sprintf("len() expects an array, got %s", argType),
 								"pass an array to the len() function")
 						}
-					} else if expected != typeInfer && argType != typeError && !c.typesEqual(expected, argType) {
+					} else if expected == typeAny && argType == typeVoid {
+						c.report(arg.Span(), fmt.Sprintf("argument type mismatch: argument %d expects %s, got %s", i+1, expected, argType),
+							"pass an expression that produces a value")
+					} else if expected != typeInfer && expected != typeAny && argType != typeError && !c.typesEqual(expected, argType) && !isIntegerWidening(argType, expected) {
 						c.report(arg.Span(), fmt.Sprintf("argument type mismatch: argument %d expects %s, got %s", i+1, expected, argType),
 							fmt.Sprintf("convert the argument to %s or use a %s expression", expected, expected))
 					}
@@ -1538,6 +1894,50 @@ func (c *Checker) checkCallExpr(expr *ast.CallExpr) string {
 	return calleeType
 }
 
+// isMapContains reports whether expr calls the map.contains builtin, unless
+// a variable named map shadows it.
+func (c *Checker) isMapContains(expr *ast.CallExpr) bool {
+	member, ok := expr.Callee.(*ast.MemberExpr)
+	if !ok || member.Member != "contains" {
+		return false
+	}
+	ident, ok := member.Target.(*ast.IdentifierExpr)
+	if !ok || ident.Name != "map" {
+		return false
+	}
+	_, shadowed := c.lookupSymbol("map")
+	return !shadowed
+}
+
+// checkMapContains checks map.contains(m, key): m must be a map and key must
+// have its key type.
+func (c *Checker) checkMapContains(expr *ast.CallExpr) string {
+	if len(expr.Args) != 2 {
+		c.report(expr.Span(), fmt.Sprintf("argument count mismatch: map.contains expects 2 arguments, got %d", len(expr.Args)),
+			"call map.contains(m, key) with a map and a key")
+		for _, arg := range expr.Args {
+			c.checkExpr(arg)
+		}
+		return "bool"
+	}
+	mapType := c.checkExpr(expr.Args[0])
+	keyType := c.checkExpr(expr.Args[1])
+	if mapType == typeError {
+		return "bool"
+	}
+	base, args := c.extractGenericType(mapType)
+	if base != "map" || len(args) != 2 {
+		c.report(expr.Args[0].Span(), fmt.Sprintf("map.contains() expects a map, got %s", mapType),
+			"pass a map as the first argument to map.contains()")
+		return "bool"
+	}
+	if keyType != typeError && !c.typesEqual(args[0], keyType) && !isIntegerWidening(keyType, args[0]) {
+		c.report(expr.Args[1].Span(), fmt.Sprintf("map key type mismatch: %s has keys of type %s, got %s", mapType, args[0], keyType),
+			fmt.Sprintf("look the key up with a %s expression", args[0]))
+	}
+	return "bool"
+}
+
 // checkGenericFunctionCall handles calls to generic functions
 func (c *Checker) checkGenericFunctionCall(expr *ast.CallExpr, sig FunctionSignature, funcName string) string {
 	// First, check argument count
@@ -1552,7 +1952,14 @@ func (c *Checker) checkGenericFunctionCall(expr *ast.CallExpr, sig FunctionSigna
 
 	// Check each argument and infer type parameters
 	for i, arg := range expr.Args {
-		argType := c.checkExpr(arg)
+		var argType string
+		if lambda, ok := arg.(*ast.LambdaExpr); ok && strings.Contains(sig.Params[i], ") -> ") {
+			// A lambda's parameters take the types inferred from the
+			// arguments before it, e.g. T from the array passed to filter
+			argType = c.checkLambdaWithTypes(lambda, c.lambdaParamTypes(sig.Params[i], typeSubstitutions, sig.TypeParams))
+		} else {
+			argType = c.checkExpr(arg)
+		}
 		if i < len(sig.Params) {
 			expected := sig.Params[i]
 
@@ -1568,9 +1975,15 @@ func (c *Checker) checkGenericFunctionCall(expr *ast.CallExpr, sig FunctionSigna
 				} else {
 					typeSubstitutions[expected] = argType
 				}
-			} else if expected != typeInfer && argType != typeError && !c.typesEqual(expected, argType) {
+			} else if expected != typeInfer && argType != typeError && !c.typesEqual(expected, argType) && !isIntegerWidening(argType, expected) {
 				// Try to infer type parameters from generic types like array<T>
-				inferred := c.inferTypeParametersFromGeneric(expected, argType, sig.TypeParams)
+				// and function types like (T) -> U
+				var inferred map[string]string
+				if strings.Contains(expected, ") -> ") {
+					inferred = c.inferTypeParametersFromFunction(expected, argType, sig.TypeParams)
+				} else {
+					inferred = c.inferTypeParametersFromGeneric(expected, argType, sig.TypeParams)
+				}
 				for typeParam, concreteType := range inferred {
 					if existing, exists := typeSubstitutions[typeParam]; exists {
 						if !c.typesEqual(existing, concreteType) {
@@ -1601,6 +2014,13 @@ func (c *Checker) checkGenericFunctionCall(expr *ast.CallExpr, sig FunctionSigna
 	for typeParam, concreteType := range typeSubstitutions {
 		returnType = c.substituteTypeParam(returnType, typeParam, concreteType)
 	}
+	// A type parameter no argument fixes, like T of deque.create(), is
+	// left to the type the result is bound to
+	for _, typeParam := range sig.TypeParams {
+		if _, ok := typeSubstitutions[typeParam.Name]; !ok {
+			returnType = c.substituteTypeParam(returnType, typeParam.Name, typeInfer)
+		}
+	}
 
 	return returnType
 }
@@ -1630,8 +2050,8 @@ func (c *Checker) checkAssignmentExpr(expr *ast.AssignmentExpr) string {
 		sym.Type = rhsType
 	}
 	if rhsType != typeError && sym.Type != typeInfer && !c.isAssignable(rhsType, sym.Type) {
-			c.report(expr.Right.Span(), fmt.Sprintf("type mismatch: cannot assign %s to %s", rhsType, sym.Type),
-				fmt.Sprintf("convert the expression to %s or change the variable type to %s", sym.Type, rhsType))
+		msg, hint := c.assignMismatch(rhsType, sym.Type)
+		c.report(expr.Right.Span(), msg, hint)
 	}
 	return sym.Type
 }
@@ -1710,6 +2130,9 @@ func (c *Checker) typeExprToString(t *ast.TypeExpr) string {
 		innerType := c.typeExprToString(t.OptionalType)
 		return innerType + "?"
 	}
+	if t.Name == "Optional" && len(t.Args) == 1 {
+		return c.typeExprToString(t.Args[0]) + "?"
+	}
 
 	// Handle generic types
 	if len(t.Args) > 0 {
@@ -1747,10 +2170,13 @@ func (c *Checker) checkTypeExpr(t *ast.TypeExpr) string {
 		return buildUnion(members)
 	}
 
-	// Handle optional types
+	// Handle optional types, where T? is short for Optional<T>
 	if t.IsOptional {
 		innerType := c.checkTypeExpr(t.OptionalType)
-		return innerType + "?" // For now, just append ? to indicate optional
+		return innerType + "?"
+	}
+	if t.Name == "Optional" && len(t.Args) == 1 {
+		return c.checkTypeExpr(t.Args[0]) + "?"
 	}
 
 	// Handle pointer types
@@ -1848,6 +2274,9 @@ func typeExprToString(t *ast.TypeExpr) string {
 		innerType := typeExprToString(t.OptionalType)
 		return innerType + "?"
 	}
+	if t.Name == "Optional" && len(t.Args) == 1 {
+		return typeExprToString(t.Args[0]) + "?"
+	}
 
 	if len(t.Args) == 0 {
 		return t.Name
@@ -2169,7 +2598,9 @@ func isNumeric(typ string) bool {
 		return false
 	}
 	switch typ {
-	case "int", "long", "byte", "float", "double":
+	case "int", "int64", "long", "byte", "float", "double":
+		return true
+	case "uint", "uint8", "uint16", "uint32", "uint64":
 		return true
 	default:
 		return false
@@ -2179,13 +2610,78 @@ func isNumeric(typ string) bool {
 // isInteger checks if a type is an integer type (not float/double)
 func isInteger(typ string) bool {
 	switch typ {
-	case "int", "long", "byte":
+	case "int", "int64", "long", "byte":
 		return true
 	default:
-		return false
+		return isUnsigned(typ)
 	}
 }
 
+// isUnsigned reports whether typ is one of the unsigned integer types.
+func isUnsigned(typ string) bool {
+	_, ok := types.UnsignedWidths[typ]
+	return ok
+}
+
+// canonicalType resolves type aliases: long is int64 and uint is uint32.
+func canonicalType(typ string) string {
+	switch typ {
+	case "long":
+		return "int64"
+	case "uint":
+		return "uint32"
+	}
+	return typ
+}
+
+// isIntegerWidening reports whether a from value converts implicitly to to:
+// int widens to int64 and an unsigned type to a wider unsigned one, while
+// narrowing and any change of signedness need an explicit cast.
+func isIntegerWidening(from, to string) bool {
+	if isUnsigned(from) && isUnsigned(to) {
+		return types.UnsignedWidths[from] < types.UnsignedWidths[to]
+	}
+	return from == "int" && canonicalType(to) == "int64"
+}
+
+// assignMismatch returns the error and hint for assigning a from value to a
+// to variable.
+func (c *Checker) assignMismatch(from, to string) (string, string) {
+	if msg, ok := c.interfaceMismatch(from, to); ok {
+		return msg, fmt.Sprintf("give %s every method of %s, with the same parameter and return types", from, to)
+	}
+	if isInteger(from) && isInteger(to) && isUnsigned(from) != isUnsigned(to) {
+		return fmt.Sprintf("conversion between signed and unsigned types (%s to %s) requires an explicit cast", from, to),
+			fmt.Sprintf("use (%s)value to convert", to)
+	}
+	if isInteger(from) && isInteger(to) && isIntegerWidening(to, from) {
+		return fmt.Sprintf("narrowing conversion from %s to %s requires an explicit cast", from, to),
+			fmt.Sprintf("use (%s)value to truncate, or change the variable type to %s", to, from)
+	}
+	if isOptional(from) && optionalBase(from) == to {
+		return fmt.Sprintf("type mismatch: cannot assign %s to %s", from, to),
+			"unwrap it with opt.unwrap or opt.unwrap_or"
+	}
+	return fmt.Sprintf("type mismatch: cannot assign %s to %s", from, to),
+		fmt.Sprintf("convert the expression to %s or change the variable type to %s", to, from)
+}
+
+// binaryOperandType returns the type binary arithmetic on left and right
+// produces: their common type, or the wider one when one widens to the
+// other.
+func (c *Checker) binaryOperandType(left, right string) (string, bool) {
+	if c.typesEqual(left, right) {
+		return left, true
+	}
+	if isIntegerWidening(left, right) {
+		return right, true
+	}
+	if isIntegerWidening(right, left) {
+		return left, true
+	}
+	return "", false
+}
+
 func canCastBetweenTypes(from, to string) bool {
 	if from == to {
 		return true
@@ -2196,6 +2692,23 @@ func canCastBetweenTypes(from, to string) bool {
 	if strings.HasPrefix(from, "*") && strings.HasPrefix(to, "*") {
 		return true
 	}
+	// An any value is checked against the target when the cast runs
+	if from == typeAny {
+		return isDynamicScalar(to)
+	}
+	if to == typeAny {
+		return isDynamicScalar(from)
+	}
+	return false
+}
+
+// isDynamicScalar reports whether typ is one of the types that an any
+// value converts to and from with a cast.
+func isDynamicScalar(typ string) bool {
+	switch typ {
+	case "int", "int64", "long", "float", "double", "string", "bool":
+		return true
+	}
 	return false
 }
 
@@ -2283,7 +2796,31 @@ func (c *Checker) typesEqual(a, b string) bool {
 		return c.typesEqual(aBase, bBase)
 	}
 
-	return a == b
+	// A generic type with an argument still to be inferred matches any
+	// instantiation of the same type, e.g. deque<<inferred>> and deque<int>
+	if strings.Contains(a, typeInfer) || strings.Contains(b, typeInfer) {
+		aName, aArgs := c.extractGenericType(a)
+		bName, bArgs := c.extractGenericType(b)
+		if aName != "" && aName == bName && len(aArgs) == len(bArgs) {
+			for i := range aArgs {
+				if !c.typesEqual(aArgs[i], bArgs[i]) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	return canonicalType(a) == canonicalType(b)
+}
+
+// isNullComparison reports whether an equality test compares an optional
+// value (or another null) against null, e.g. `order == null` for array<int>?.
+func isNullComparison(a, b string) bool {
+	if b == "null" {
+		a, b = b, a
+	}
+	return a == "null" && (b == "null" || strings.HasSuffix(b, "?"))
 }
 
 // isAssignable checks if a value of type fromType can be assigned to a variable of type toType
@@ -2293,6 +2830,16 @@ func (c *Checker) isAssignable(fromType, toType string) bool {
 	if c.typesEqual(fromType, toType) {
 		return true
 	}
+	if toType == typeAny {
+		return fromType != typeVoid
+	}
+	if isIntegerWidening(fromType, toType) {
+		return true
+	}
+	// null is the absent value of every optional type
+	if fromType == "null" && isOptional(toType) {
+		return true
+	}
 
 	// Handle optional types: allow widening (non-optional -> optional)
 	fromBase := strings.TrimRight(fromType, "?")
@@ -2314,6 +2861,10 @@ func (c *Checker) isAssignable(fromType, toType string) bool {
 	if fromOptional == 0 && toOptional > 0 {
 		return c.typesEqual(fromBase, toBase)
 	}
+	// A struct converts to the interfaces it implements
+	if c.implementsInterface(fromType, toType) {
+		return true
+	}
 
 	// Reject narrowing: optional cannot be assigned to non-optional
 	// Reject other mismatches
@@ -2380,6 +2931,22 @@ func (c *Checker) declare(name, typ string, mutable bool, span lexer.Span) {
 	scope[name] = Symbol{Type: typ, Mutable: mutable}
 }
 
+// declareLocal declares a local variable or parameter, as kind says, that
+// the unused variable checks report if nothing reads it.
+func (c *Checker) declareLocal(name, typ string, mutable bool, kind string, span lexer.Span) {
+	if len(c.scopes) == 0 {
+		return
+	}
+	scope := c.scopes[len(c.scopes)-1]
+	_, exists := scope[name]
+	c.declare(name, typ, mutable, span)
+	if !exists {
+		sym := scope[name]
+		sym.local, sym.span = kind, span
+		scope[name] = sym
+	}
+}
+
 func (c *Checker) symbolExists(name string) bool {
 	for i := len(c.scopes) - 1; i >= 0; i-- {
 		if _, ok := c.scopes[i][name]; ok {
@@ -2398,6 +2965,18 @@ func (c *Checker) lookupSymbol(name string) (Symbol, bool) {
 	return Symbol{}, false
 }
 
+// readSymbol looks name up like lookupSymbol, counting the read.
+func (c *Checker) readSymbol(name string) (Symbol, bool) {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		if sym, ok := c.scopes[i][name]; ok {
+			sym.ReadCount++
+			c.scopes[i][name] = sym
+			return sym, true
+		}
+	}
+	return Symbol{}, false
+}
+
 func (c *Checker) updateSymbolType(name, typ string) {
 	if typ == typeInfer || typ == typeError {
 		return
@@ -2419,6 +2998,7 @@ func (c *Checker) leaveScope() {
 	if len(c.scopes) == 0 {
 		return
 	}
+	c.reportUnused(c.scopes[len(c.scopes)-1])
 	c.scopes = c.scopes[:len(c.scopes)-1]
 }
 
@@ -2674,6 +3254,15 @@ func (c *Checker) processImport(imp *ast.ImportDecl) {
 
 		// Register the module's function signatures
 		c.registerModuleFunctionSignatures(module, imp.Path)
+		// Std modules are also callable through their local name (hash.sha256,
+		// diff.unified), which the MIR builder resolves back to the full path.
+		if len(imp.Path) > 1 {
+			local := imp.Alias
+			if local == "" {
+				local = imp.Path[len(imp.Path)-1]
+			}
+			c.registerModuleFunctionSignatures(module, []string{local})
+		}
 		// Register the module's struct fields
 		c.registerModuleStructFields(module, imp.Path)
 
@@ -2802,7 +3391,9 @@ func (c *Checker) registerMergedFunctionSignatures(mod *ast.Module) {
 				sig.Params = make([]string, len(fn.Params))
 				for i, param := range fn.Params {
 					sig.Params[i] = c.resolveTypeExpr(param.Type)
+					sig.Variadic = param.Variadic
 				}
+				sig.Defaults = countDefaults(fn.Params)
 				sig.TypeParams = fn.TypeParams
 
 				// Leave type parameter scope
@@ -2880,7 +3471,9 @@ func (c *Checker) registerModuleFunctionSignatures(mod *ast.Module, importPath [
 			sig.Params = make([]string, len(fn.Params))
 			for i, param := range fn.Params {
 				sig.Params[i] = c.resolveTypeExpr(param.Type)
+				sig.Variadic = param.Variadic
 			}
+			sig.Defaults = countDefaults(fn.Params)
 			sig.TypeParams = fn.TypeParams
 
 			// Leave type parameter scope
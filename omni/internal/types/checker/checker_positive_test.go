@@ -43,3 +43,30 @@ func main():int {
 		t.Fatalf("type checker reported error on valid program:\n%s", err)
 	}
 }
+
+func TestTypeCheckerWidensIntToInt64(t *testing.T) {
+	src := `func twice(x:int64):int64 {
+  return x * 2
+}
+
+func main():int {
+  let big:int64 = 3000000000i64
+  let alias:long = 7
+  var total:int64 = big + alias
+  total = total + twice(21)
+  if total > 0 {
+    return (int)(total - big)
+  }
+  return 0
+}
+`
+
+	mod, err := parser.Parse("int64.omni", src)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	if err := checker.Check("int64.omni", src, mod); err != nil {
+		t.Fatalf("type checker reported error on valid program:\n%s", err)
+	}
+}
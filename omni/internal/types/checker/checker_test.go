@@ -1,6 +1,7 @@
 package checker_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"sort"
@@ -39,7 +40,14 @@ func TestTypeCheckerGoldens(t *testing.T) {
 				t.Fatalf("parse %s: %v", inputPath, err)
 			}
 
-			err = checker.Check(logicalName, string(src), mod)
+			// Goldens record unreachable code warnings too, listed before
+			// the errors
+			warnings, err := checker.CheckWithOptions(logicalName, string(src), mod, checker.Options{WarnDeadCode: true, WarnUnusedVars: true})
+			diags := make([]error, 0, len(warnings)+1)
+			for _, warning := range warnings {
+				diags = append(diags, warning)
+			}
+			err = errors.Join(append(diags, err)...)
 			actual := ""
 			if err != nil {
 				actual = err.Error()
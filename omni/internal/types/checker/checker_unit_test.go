@@ -1,9 +1,12 @@
 package checker_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/omni-lang/omni/internal/ast"
+	"github.com/omni-lang/omni/internal/lexer"
 	"github.com/omni-lang/omni/internal/parser"
 	"github.com/omni-lang/omni/internal/types/checker"
 )
@@ -844,6 +847,114 @@ func TestMapLiterals(t *testing.T) {
 	}
 }
 
+func TestMapContains(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{
+			name: "string keys",
+			src:  "func f():bool {\n  let m:map<string, int> = {\"a\": 1}\n  return map.contains(m, \"a\")\n}",
+		},
+		{
+			name:    "key type mismatch",
+			src:     "func f():bool {\n  let m:map<string, int> = {\"a\": 1}\n  return map.contains(m, 1)\n}",
+			wantErr: "map key type mismatch",
+		},
+		{
+			name:    "not a map",
+			src:     "func f():bool {\n  return map.contains(5, \"a\")\n}",
+			wantErr: "map.contains() expects a map, got int",
+		},
+		{
+			name:    "argument count",
+			src:     "func f():bool {\n  let m:map<string, int> = {\"a\": 1}\n  return map.contains(m)\n}",
+			wantErr: "map.contains expects 2 arguments, got 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mod, err := parseSource(t, tt.src)
+			if err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
+			err = checker.Check("test.omni", tt.src, mod)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnsignedTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{
+			name: "unsigned arithmetic and shifts",
+			src:  "func f(x:uint32, k:int):uint32 {\n  return (x << k) | (x >> (32 - k)) & 0xFFu\n}",
+		},
+		{
+			name: "widening to a wider unsigned type",
+			src:  "func f(b:uint8):uint64 {\n  let w:uint64 = b\n  return w + 1u\n}",
+		},
+		{
+			name: "large literal is uint64",
+			src:  "func f():uint64 {\n  return 18446744073709551615u\n}",
+		},
+		{
+			name:    "signed to unsigned",
+			src:     "func f():uint {\n  let x:uint = 5\n  return x\n}",
+			wantErr: "conversion between signed and unsigned types (int to uint)",
+		},
+		{
+			name:    "unsigned narrowing",
+			src:     "func f(x:uint32):uint8 {\n  let b:uint8 = x\n  return b\n}",
+			wantErr: "narrowing conversion from uint32 to uint8",
+		},
+		{
+			name:    "mixed signedness operands",
+			src:     "func f(x:uint32, y:int):uint32 {\n  return x + y\n}",
+			wantErr: "uint32",
+		},
+		{
+			name:    "negating an unsigned value",
+			src:     "func f(x:uint16):uint16 {\n  return -x\n}",
+			wantErr: "operator - not defined on unsigned uint16",
+		},
+		{
+			name:    "literal overflow",
+			src:     "func f():uint64 {\n  return 18446744073709551616u\n}",
+			wantErr: "overflows uint64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mod, err := parseSource(t, tt.src)
+			if err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
+			err = checker.Check("test.omni", tt.src, mod)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 // Helper function to parse source code
 func parseSource(t *testing.T, src string) (*ast.Module, error) {
 	return parser.Parse("test.omni", src)
@@ -961,6 +1072,42 @@ func TestGenericFunctionCallInference(t *testing.T) {
 			src: `func id<T>(x: T): T { return x }
 			      let x: int = id(42)`,
 		},
+		{
+			name: "generic inference through lambda",
+			src: `import std
+			      let xs: array<int> = [1, 2, 3]
+			      let names: array<string> = collections.map_fn(xs, |n| std.int_to_string(n))
+			      let total: int = collections.reduce(xs, 0, |acc, n| acc + n)`,
+		},
+		{
+			name: "generic inference from lambda result mismatch",
+			src: `import std
+			      let xs: array<int> = [1, 2, 3]
+			      let names: array<int> = collections.map_fn(xs, |n| std.int_to_string(n))`,
+			shouldErr: true,
+		},
+		{
+			name: "generic lambda parameter mismatch",
+			src: `import std
+			      let xs: array<string> = ["a"]
+			      let kept: array<string> = collections.filter(xs, |s| s > 1)`,
+			shouldErr: true,
+		},
+		{
+			name: "generic result inferred from binding",
+			src: `func make<T>():deque<T> {
+			        return make()
+			      }
+			      let d: deque<int> = make()`,
+		},
+		{
+			name: "generic result bound to another type",
+			src: `func make<T>():deque<T> {
+			        return make()
+			      }
+			      let d: array<int> = make()`,
+			shouldErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1933,3 +2080,30 @@ func TestErrorHandlingHelpers(t *testing.T) {
 		})
 	}
 }
+
+func TestTypeErrorReportsLine(t *testing.T) {
+	src := `func main():int {
+  let x:int = 1
+  let y:string = x
+  return 0
+}`
+	mod, err := parseSource(t, src)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	err = checker.Check("lines.omni", src, mod)
+	if err == nil {
+		t.Fatal("expected a type error")
+	}
+	var diag lexer.Diagnostic
+	if !errors.As(err, &diag) {
+		t.Fatalf("expected a diagnostic, got %T: %v", err, err)
+	}
+	if diag.Span.Start.Line != 3 {
+		t.Errorf("type error reported on line %d, want 3", diag.Span.Start.Line)
+	}
+	if !strings.Contains(err.Error(), "lines.omni:3:") {
+		t.Errorf("error message does not name line 3: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package checker
+
+import (
+	"fmt"
+
+	"github.com/omni-lang/omni/internal/ast"
+)
+
+// countDefaults returns the number of params with a default value.
+func countDefaults(params []ast.Param) int {
+	n := 0
+	for _, param := range params {
+		if param.Default != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// checkParamDefaults checks the default values of the parameters of decl.
+// A default is evaluated where the function is declared, so it must be a
+// constant expression of the parameter type.
+func (c *Checker) checkParamDefaults(decl *ast.FuncDecl) {
+	for _, param := range decl.Params {
+		if param.Default == nil {
+			continue
+		}
+		if !c.isConstantExpr(param.Default) {
+			c.report(param.Default.Span(), fmt.Sprintf("default value of parameter %q is not a constant expression", param.Name),
+				"use a literal, an enum variant, or an operation on them")
+			continue
+		}
+		defaultType := c.checkExpr(param.Default)
+		paramType := c.resolveTypeExpr(param.Type)
+		if defaultType != typeError && paramType != typeError && !c.isAssignable(defaultType, paramType) {
+			c.report(param.Default.Span(), fmt.Sprintf("default value of parameter %q has type %s, want %s", param.Name, defaultType, paramType),
+				fmt.Sprintf("give the parameter a default of type %s", paramType))
+		}
+	}
+}
+
+// isConstantExpr reports whether expr can be evaluated without running the
+// program: a literal, an enum variant, or unary and binary operations on
+// them.
+func (c *Checker) isConstantExpr(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.LiteralExpr:
+		return true
+	case *ast.UnaryExpr:
+		return c.isConstantExpr(e.Expr)
+	case *ast.BinaryExpr:
+		return c.isConstantExpr(e.Left) && c.isConstantExpr(e.Right)
+	case *ast.MemberExpr:
+		ident, ok := e.Target.(*ast.IdentifierExpr)
+		if !ok || c.symbolExists(ident.Name) {
+			return false
+		}
+		_, ok = c.enums[ident.Name]
+		return ok
+	}
+	return false
+}
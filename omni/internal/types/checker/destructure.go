@@ -0,0 +1,85 @@
+package checker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/ast"
+)
+
+// checkDestructureStmt checks that the pattern of a destructuring let or
+// var fits its value and declares the bindings. A tuple pattern needs a
+// tuple with one element per name; a struct pattern needs the fields it
+// names to exist, and must bind every field unless it ends in ..
+func (c *Checker) checkDestructureStmt(stmt *ast.DestructureStmt) {
+	valueType := c.checkExpr(stmt.Value)
+	if stmt.Tuple {
+		elems := c.tupleElements(valueType)
+		switch {
+		case valueType == typeError:
+		case elems == nil:
+			c.report(stmt.Value.Span(), fmt.Sprintf("tuple destructuring needs a tuple value, got %s", valueType),
+				"bind the value to a single name instead")
+		case len(elems) != len(stmt.Names):
+			c.report(stmt.Span(), fmt.Sprintf("tuple destructuring binds %d names, but %s has %d elements", len(stmt.Names), valueType, len(elems)),
+				"list one name per element, using _ for those you skip")
+		}
+		for i, name := range stmt.Names {
+			if name == "_" {
+				continue
+			}
+			typ := typeError
+			if len(elems) == len(stmt.Names) {
+				typ = elems[i]
+			}
+			c.declareLocal(name, typ, stmt.Mutable, localVariable, stmt.Span())
+		}
+		return
+	}
+
+	structName, fields, ok := c.resolveStructDefinition(valueType)
+	if !ok && valueType != typeError {
+		c.report(stmt.Value.Span(), fmt.Sprintf("struct destructuring needs a struct value, got %s", valueType),
+			"bind the value to a single name instead")
+	}
+	bound := make(map[string]bool, len(stmt.Fields))
+	for _, f := range stmt.Fields {
+		typ := typeError
+		if ok {
+			if fieldType, exists := fields[f.Field]; exists {
+				typ = fieldType
+			} else {
+				c.report(f.Span, fmt.Sprintf("struct %s has no field %q", structName, f.Field), "use a declared field name")
+			}
+		}
+		bound[f.Field] = true
+		if f.Binding != "_" {
+			c.declareLocal(f.Binding, typ, stmt.Mutable, localVariable, f.Span)
+		}
+	}
+	if !ok || stmt.Rest {
+		return
+	}
+	var missing []string
+	for name := range fields {
+		if !bound[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		c.report(stmt.Span(), fmt.Sprintf("struct destructuring of %s does not bind %s", structName, strings.Join(missing, ", ")),
+			"bind every field, or end the pattern with .. to ignore the rest")
+	}
+}
+
+// tupleElements returns the element types of the tuple type typ, or nil if
+// typ is not a tuple.
+func (c *Checker) tupleElements(typ string) []string {
+	if !strings.HasPrefix(typ, "tuple<") {
+		return nil
+	}
+	_, elems := c.extractGenericType(typ)
+	return elems
+}
@@ -0,0 +1,104 @@
+package checker
+
+import (
+	"fmt"
+
+	"github.com/omni-lang/omni/internal/ast"
+)
+
+// interfaceMethod is a method an interface requires, with the types of its
+// parameters after the receiver.
+type interfaceMethod struct {
+	Name   string
+	Params []string
+	Return string
+}
+
+// registerInterface records the methods of the interface decl.
+func (c *Checker) registerInterface(decl *ast.InterfaceDecl) {
+	methods := make([]interfaceMethod, 0, len(decl.Methods))
+	seen := make(map[string]bool, len(decl.Methods))
+	for _, m := range decl.Methods {
+		if seen[m.Name] {
+			c.report(m.Span, fmt.Sprintf("interface %s declares method %s twice", decl.Name, m.Name),
+				"remove the duplicate method")
+			continue
+		}
+		seen[m.Name] = true
+		method := interfaceMethod{Name: m.Name, Return: typeVoid}
+		for _, param := range m.Params {
+			method.Params = append(method.Params, c.checkTypeExpr(param.Type))
+		}
+		if m.Return != nil {
+			method.Return = c.checkTypeExpr(m.Return)
+		}
+		methods = append(methods, method)
+	}
+	c.interfaces[decl.Name] = methods
+}
+
+// conformanceError explains why the struct type typeName does not
+// implement the interface iface, or returns "" when it does: the struct
+// must have every method of the interface, with the same parameter and
+// return types.
+func (c *Checker) conformanceError(typeName, iface string) string {
+	for _, m := range c.interfaces[iface] {
+		callee, ok := c.methods[typeName][m.Name]
+		if !ok {
+			return fmt.Sprintf("struct %s does not implement %s: missing method %s", typeName, iface, m.Name)
+		}
+		sig := c.functions[callee]
+		if !c.signaturesEqual(sig.Params[1:], sig.Return, m.Params, m.Return) {
+			return fmt.Sprintf("struct %s does not implement %s: method %s has type %s, want %s", typeName, iface, m.Name,
+				buildFunctionType(sig.Params[1:], sig.Return), buildFunctionType(m.Params, m.Return))
+		}
+	}
+	return ""
+}
+
+func (c *Checker) signaturesEqual(params []string, ret string, wantParams []string, wantRet string) bool {
+	if len(params) != len(wantParams) || !c.typesEqual(ret, wantRet) {
+		return false
+	}
+	for i := range params {
+		if !c.typesEqual(params[i], wantParams[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// implementsInterface reports whether a from value converts to the
+// interface type to, as a struct with all of its methods does.
+func (c *Checker) implementsInterface(from, to string) bool {
+	if _, ok := c.interfaces[to]; !ok {
+		return false
+	}
+	if _, ok := c.structFields[from]; !ok {
+		return false
+	}
+	return c.conformanceError(from, to) == ""
+}
+
+// interfaceMismatch returns the conformance error for converting a from
+// value to the interface type to, if that is the conversion that failed.
+func (c *Checker) interfaceMismatch(from, to string) (string, bool) {
+	if _, ok := c.interfaces[to]; !ok {
+		return "", false
+	}
+	if _, ok := c.structFields[from]; !ok {
+		return "", false
+	}
+	msg := c.conformanceError(from, to)
+	return msg, msg != ""
+}
+
+// interfaceMethodOf returns the method name of the interface type typ.
+func (c *Checker) interfaceMethodOf(typ, name string) (interfaceMethod, bool) {
+	for _, m := range c.interfaces[typ] {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return interfaceMethod{}, false
+}
@@ -0,0 +1,157 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/ast"
+)
+
+// checkEnumVariant checks a member expression Enum.Variant naming a variant
+// of an enum type, returning the enum type. It reports whether expr names
+// an enum at all, so that a variable of the same name shadows the enum.
+func (c *Checker) checkEnumVariant(expr *ast.MemberExpr) (string, bool) {
+	ident, ok := expr.Target.(*ast.IdentifierExpr)
+	if !ok || c.symbolExists(ident.Name) {
+		return "", false
+	}
+	variants, ok := c.enums[ident.Name]
+	if !ok {
+		return "", false
+	}
+	if !containsString(variants, expr.Member) {
+		c.report(expr.Span(), fmt.Sprintf("enum %s has no variant %s", ident.Name, expr.Member),
+			"variants: "+strings.Join(variants, ", "))
+		return typeError, true
+	}
+	return ident.Name, true
+}
+
+// checkMatchExpr checks the cases of a match expression and returns the
+// type of their bodies. A match must be exhaustive: an enum subject needs
+// a case for every variant, a bool one for true and false, and any other
+// a wildcard or struct pattern, which match every value.
+func (c *Checker) checkMatchExpr(expr *ast.MatchExpr) string {
+	subject := c.checkExpr(expr.Subject)
+	if len(expr.Cases) == 0 {
+		c.report(expr.Span(), "match has no cases", "add a case for each value, or a wildcard case _")
+		return typeError
+	}
+	result := ""
+	exhaustive := false
+	covered := make(map[string]bool)
+	for _, mc := range expr.Cases {
+		c.enterScope()
+		if c.checkPattern(mc.Pattern, subject, covered) {
+			exhaustive = true
+		}
+		body := c.checkExpr(mc.Body)
+		c.leaveScope()
+		switch {
+		case result == "" || result == typeError:
+			result = body
+		case body == typeError || c.isAssignable(body, result):
+			// The case fits the type of the earlier ones
+		case c.isAssignable(result, body):
+			result = body
+		default:
+			c.report(mc.Body.Span(), fmt.Sprintf("match cases have different types: %s and %s", result, body),
+				fmt.Sprintf("make every case produce a value of type %s", result))
+		}
+	}
+	if !exhaustive && subject != typeError {
+		c.checkExhaustive(expr, subject, covered)
+	}
+	return result
+}
+
+// checkPattern checks that pat can match a value of type subject, declaring
+// the bindings of a struct pattern in the current scope, and records the
+// enum variants and bool values it covers. It reports whether the pattern
+// matches every value.
+func (c *Checker) checkPattern(pat ast.MatchPattern, subject string, covered map[string]bool) bool {
+	switch pat.Kind {
+	case ast.PatternWildcard:
+		return true
+	case ast.PatternLiteral:
+		typ := c.checkExpr(pat.Value)
+		if typ != typeError && subject != typeError && !c.isAssignable(typ, subject) {
+			c.reportPatternMismatch(pat, typ, subject)
+		}
+		if lit, ok := pat.Value.(*ast.LiteralExpr); ok && lit.Kind == ast.LiteralBool {
+			covered[lit.Value] = true
+		}
+	case ast.PatternEnum:
+		variants, ok := c.enums[pat.Enum]
+		if !ok {
+			c.report(pat.Span, fmt.Sprintf("unknown enum %s", pat.Enum), "match an enum variant as Enum.Variant")
+			return false
+		}
+		if !containsString(variants, pat.Variant) {
+			c.report(pat.Span, fmt.Sprintf("enum %s has no variant %s", pat.Enum, pat.Variant),
+				"variants: "+strings.Join(variants, ", "))
+			return false
+		}
+		if subject != typeError && subject != pat.Enum {
+			c.reportPatternMismatch(pat, pat.Enum, subject)
+		}
+		covered[pat.Variant] = true
+	case ast.PatternStruct:
+		structName, fields, ok := c.resolveStructDefinition(subject)
+		if !ok {
+			if subject != typeError {
+				c.report(pat.Span, fmt.Sprintf("struct pattern cannot match a value of type %s", subject),
+					"destructure a struct value")
+			}
+			return true
+		}
+		for _, f := range pat.Fields {
+			fieldType, exists := fields[f.Field]
+			if !exists {
+				c.report(f.Span, fmt.Sprintf("struct %s has no field %q", structName, f.Field), "use a declared field name")
+				fieldType = typeError
+			}
+			if f.Binding != "_" {
+				c.declare(f.Binding, fieldType, false, f.Span)
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (c *Checker) reportPatternMismatch(pat ast.MatchPattern, typ, subject string) {
+	c.report(pat.Span, fmt.Sprintf("pattern of type %s cannot match a value of type %s", typ, subject),
+		fmt.Sprintf("use a pattern of type %s", subject))
+}
+
+// checkExhaustive reports a match on subject without a wildcard case whose
+// cases leave values of the subject unmatched.
+func (c *Checker) checkExhaustive(expr *ast.MatchExpr, subject string, covered map[string]bool) {
+	if variants, ok := c.enums[subject]; ok {
+		var missing []string
+		for _, v := range variants {
+			if !covered[v] {
+				missing = append(missing, subject+"."+v)
+			}
+		}
+		if len(missing) > 0 {
+			c.report(expr.Span(), fmt.Sprintf("match on %s is not exhaustive: missing %s", subject, strings.Join(missing, ", ")),
+				"add a case for each missing variant, or a wildcard case _")
+		}
+		return
+	}
+	if subject == "bool" && covered["true"] && covered["false"] {
+		return
+	}
+	c.report(expr.Span(), fmt.Sprintf("match on %s is not exhaustive", subject), "add a wildcard case _")
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package checker
+
+import (
+	"fmt"
+
+	"github.com/omni-lang/omni/internal/ast"
+)
+
+// registerMethod records the method decl of a struct type in the method
+// table and the signature of the function that implements it, whose first
+// parameter is the receiver.
+func (c *Checker) registerMethod(decl *ast.MethodDecl) {
+	typeName := decl.TypeName()
+	fields, ok := c.structFields[typeName]
+	if !ok {
+		c.report(decl.Receiver.Span, fmt.Sprintf("method receiver type %q is not a struct type", typeName),
+			"declare methods on struct types only")
+		return
+	}
+	name := decl.Func.Name
+	if _, exists := fields[name]; exists {
+		c.report(decl.Span(), fmt.Sprintf("method %s.%s conflicts with the field of the same name", typeName, name),
+			"rename the method or the field")
+		return
+	}
+	if _, exists := c.methods[typeName][name]; exists {
+		c.report(decl.Span(), fmt.Sprintf("method %s.%s redeclared", typeName, name),
+			"rename the method or remove the duplicate declaration")
+		return
+	}
+	if c.methods[typeName] == nil {
+		c.methods[typeName] = make(map[string]string)
+	}
+	c.methods[typeName][name] = decl.FuncName()
+	c.functions[decl.FuncName()] = c.buildFunctionSignature(decl.Function())
+}
+
+// methodCall reports whether expr calls a method, s.name(args), returning
+// the member expression. Any struct type declaring a method of that name
+// makes it one, unless the target is an imported module.
+func (c *Checker) methodCall(expr *ast.CallExpr) (*ast.MemberExpr, bool) {
+	member, ok := expr.Callee.(*ast.MemberExpr)
+	if !ok {
+		return nil, false
+	}
+	// Walk down to the root of a target such as std.io, which names a
+	// module rather than a value
+	root := member.Target
+	for inner, ok := root.(*ast.MemberExpr); ok; inner, ok = root.(*ast.MemberExpr) {
+		root = inner.Target
+	}
+	if ident, ok := root.(*ast.IdentifierExpr); ok && !c.symbolExists(ident.Name) {
+		return nil, false
+	}
+	for _, methods := range c.methods {
+		if _, ok := methods[member.Member]; ok {
+			return member, true
+		}
+	}
+	for iface := range c.interfaces {
+		if _, ok := c.interfaceMethodOf(iface, member.Member); ok {
+			return member, true
+		}
+	}
+	return nil, false
+}
+
+// checkMethodCall checks a call of the method member on the value of its
+// target against the signature of the function that implements it.
+func (c *Checker) checkMethodCall(expr *ast.CallExpr, member *ast.MemberExpr) string {
+	receiverType := c.checkExpr(member.Target)
+	if receiverType == typeError {
+		for _, arg := range expr.Args {
+			c.checkExpr(arg)
+		}
+		return typeError
+	}
+	if _, ok := c.interfaces[receiverType]; ok {
+		return c.checkInterfaceCall(expr, member, receiverType)
+	}
+	callee, ok := c.methods[receiverType][member.Member]
+	if !ok {
+		for _, arg := range expr.Args {
+			c.checkExpr(arg)
+		}
+		c.report(member.Span(), fmt.Sprintf("type %s has no method %s", receiverType, member.Member),
+			"call a method declared with a receiver of this type")
+		return typeError
+	}
+	sig := c.functions[callee]
+	c.checkMethodArgs(expr, receiverType+"."+member.Member, sig.Params[1:])
+	return sig.Return
+}
+
+// checkInterfaceCall checks a call of the method member on a value of the
+// interface type iface, which calls the method of the struct it holds.
+func (c *Checker) checkInterfaceCall(expr *ast.CallExpr, member *ast.MemberExpr, iface string) string {
+	method, ok := c.interfaceMethodOf(iface, member.Member)
+	if !ok {
+		for _, arg := range expr.Args {
+			c.checkExpr(arg)
+		}
+		c.report(member.Span(), fmt.Sprintf("interface %s has no method %s", iface, member.Member),
+			"call a method the interface declares")
+		return typeError
+	}
+	c.checkMethodArgs(expr, iface+"."+member.Member, method.Params)
+	return method.Return
+}
+
+// checkMethodArgs checks the arguments of the method call expr, which
+// exclude the receiver, against params.
+func (c *Checker) checkMethodArgs(expr *ast.CallExpr, name string, params []string) {
+	if len(expr.Args) != len(params) {
+		c.report(expr.Span(), fmt.Sprintf("argument count mismatch: method %s expects %d arguments, got %d", name, len(params), len(expr.Args)),
+			fmt.Sprintf("provide %d argument(s) matching the method signature", len(params)))
+		for _, arg := range expr.Args {
+			c.checkExpr(arg)
+		}
+		return
+	}
+	for i, arg := range expr.Args {
+		argType := c.checkExpr(arg)
+		if argType != typeError && !c.isAssignable(argType, params[i]) {
+			c.report(arg.Span(), fmt.Sprintf("argument type mismatch: argument %d expects %s, got %s", i+1, params[i], argType),
+				fmt.Sprintf("convert the argument to %s or use a %s expression", params[i], params[i]))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/ast"
+)
+
+// optBuiltins are the functions of the opt namespace that work on optional
+// values, by name, with the number of arguments they take.
+var optBuiltins = map[string]int{
+	"unwrap":    1,
+	"unwrap_or": 2,
+	"is_some":   1,
+	"is_none":   1,
+}
+
+// isOptional reports whether typ is an optional type T?.
+func isOptional(typ string) bool {
+	return strings.HasSuffix(typ, "?")
+}
+
+// optionalBase returns the type T that the optional type T? may hold.
+func optionalBase(typ string) string {
+	return strings.TrimRight(typ, "?")
+}
+
+// optBuiltin returns the name of the opt builtin that expr calls, unless a
+// variable named opt shadows them.
+func (c *Checker) optBuiltin(expr *ast.CallExpr) (string, bool) {
+	member, ok := expr.Callee.(*ast.MemberExpr)
+	if !ok {
+		return "", false
+	}
+	if _, ok := optBuiltins[member.Member]; !ok {
+		return "", false
+	}
+	ident, ok := member.Target.(*ast.IdentifierExpr)
+	if !ok || ident.Name != "opt" {
+		return "", false
+	}
+	if _, shadowed := c.lookupSymbol("opt"); shadowed {
+		return "", false
+	}
+	return member.Member, true
+}
+
+// checkOptBuiltin checks a call of opt.unwrap(v), opt.unwrap_or(v, d),
+// opt.is_some(v) or opt.is_none(v): v must be optional, and d must have the
+// type v holds.
+func (c *Checker) checkOptBuiltin(expr *ast.CallExpr, name string) string {
+	result := "bool"
+	if want := optBuiltins[name]; len(expr.Args) != want {
+		c.report(expr.Span(), fmt.Sprintf("argument count mismatch: opt.%s expects %d arguments, got %d", name, want, len(expr.Args)),
+			fmt.Sprintf("call opt.%s with an optional value", name))
+		for _, arg := range expr.Args {
+			c.checkExpr(arg)
+		}
+		return typeError
+	}
+	valueType := c.checkExpr(expr.Args[0])
+	if valueType != typeError && !isOptional(valueType) {
+		c.report(expr.Args[0].Span(), fmt.Sprintf("opt.%s expects an optional value, got %s", name, valueType),
+			"only values of an optional type T? can be absent")
+		valueType = typeError
+	}
+	if name == "unwrap" || name == "unwrap_or" {
+		result = typeError
+		if valueType != typeError {
+			result = optionalBase(valueType)
+		}
+	}
+	if name == "unwrap_or" {
+		defaultType := c.checkExpr(expr.Args[1])
+		if result != typeError && defaultType != typeError && !c.isAssignable(defaultType, result) {
+			c.report(expr.Args[1].Span(), fmt.Sprintf("opt.unwrap_or default has type %s, want %s", defaultType, result),
+				fmt.Sprintf("use a %s expression as the default", result))
+		}
+	}
+	return result
+}
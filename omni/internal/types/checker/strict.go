@@ -0,0 +1,206 @@
+package checker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/ast"
+	"github.com/omni-lang/omni/internal/lexer"
+	"github.com/omni-lang/omni/internal/moduleloader"
+)
+
+// Options enables the optional checks behind omnic's --warn-unreachable,
+// --warn-unused-vars, --warn-unused-params, -Werror and --max-complexity flags, and carries the import map used to
+// resolve imports. The zero value runs only the standard type rules.
+type Options struct {
+	// WarnDeadCode reports statements that can never execute because they
+	// follow a return, throw, break or continue.
+	WarnDeadCode bool
+	// WarnUnusedVars reports local variables that are never read. Names
+	// starting with _ are exempt.
+	WarnUnusedVars bool
+	// WarnUnusedParams reports function parameters that are never read,
+	// except those starting with _ and method receivers.
+	WarnUnusedParams bool
+	// WarningsAsErrors turns every warning into an error.
+	WarningsAsErrors bool
+	// MaxComplexity, when positive, warns about functions whose cyclomatic
+	// complexity exceeds it.
+	MaxComplexity int
+	// ImportMap redirects imports to replacement files, matching the
+	// redirects applied when the modules are merged.
+	ImportMap moduleloader.ImportMap
+}
+
+// StrictMaxComplexity is the complexity limit enabled by --strict.
+const StrictMaxComplexity = 10
+
+// StrictOptions returns the checks enabled by omnic --strict. Null safety
+// (no implicit T? to T conversion) is always enforced, so it needs no option.
+func StrictOptions() Options {
+	return Options{
+		WarnDeadCode:     true,
+		WarnUnusedVars:   true,
+		WarningsAsErrors: true,
+		MaxComplexity:    StrictMaxComplexity,
+	}
+}
+
+// lintFunc runs the optional checks selected in c.options on decl.
+func (c *Checker) lintFunc(decl *ast.FuncDecl) {
+	if decl.Body == nil {
+		return
+	}
+	if c.options.WarnDeadCode {
+		c.checkDeadCode(decl.Body)
+	}
+	if c.options.MaxComplexity > 0 {
+		if complexity := 1 + blockComplexity(decl.Body); complexity > c.options.MaxComplexity {
+			c.reportWithSeverity(decl.SpanInfo,
+				fmt.Sprintf("function %s has cyclomatic complexity %d (max %d)", decl.Name, complexity, c.options.MaxComplexity),
+				"split the function into smaller helpers", lexer.Warning, "complexity")
+		}
+	}
+}
+
+// checkDeadCode reports the first unreachable statement of each block and
+// reports whether control can fall off the end of block.
+func (c *Checker) checkDeadCode(block *ast.BlockStmt) bool {
+	reachable := true
+	for _, stmt := range block.Statements {
+		if !reachable {
+			c.reportWithSeverity(stmt.Span(), "unreachable code",
+				"remove the statement or the return, throw, break or continue before it", lexer.Warning, "dead-code")
+			return false
+		}
+		reachable = c.stmtFallsThrough(stmt)
+	}
+	return reachable
+}
+
+// stmtFallsThrough reports whether control can continue past stmt, checking
+// nested blocks for dead code along the way.
+func (c *Checker) stmtFallsThrough(stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.ReturnStmt, *ast.BreakStmt, *ast.ContinueStmt, *ast.ThrowStmt:
+		return false
+	case *ast.BlockStmt:
+		return c.checkDeadCode(s)
+	case *ast.IfStmt:
+		thenFalls := c.checkDeadCode(s.Then)
+		if s.Else == nil {
+			return true
+		}
+		return c.stmtFallsThrough(s.Else) || thenFalls
+	case *ast.ForStmt:
+		// A loop body may run zero times, and break leaves the loop, so
+		// code after a loop is always considered reachable.
+		c.checkDeadCode(s.Body)
+	case *ast.WhileStmt:
+		c.checkDeadCode(s.Body)
+	case *ast.TryStmt:
+		falls := c.checkDeadCode(s.TryBlock)
+		for _, clause := range s.CatchClauses {
+			if c.checkDeadCode(clause.Block) {
+				falls = true
+			}
+		}
+		if s.FinallyBlock != nil && !c.checkDeadCode(s.FinallyBlock) {
+			return false
+		}
+		return falls
+	}
+	return true
+}
+
+// The kinds of locals the unused variable checks report.
+const (
+	localVariable  = "variable"
+	localParameter = "parameter"
+)
+
+// reportUnused warns about the locals of scope that nothing read, as
+// selected by WarnUnusedVars and WarnUnusedParams, in declaration order.
+func (c *Checker) reportUnused(scope map[string]Symbol) {
+	if !c.options.WarnUnusedVars && !c.options.WarnUnusedParams {
+		return
+	}
+	var unused []string
+	for name, sym := range scope {
+		if sym.ReadCount > 0 || strings.HasPrefix(name, "_") {
+			continue
+		}
+		if (sym.local == localVariable && c.options.WarnUnusedVars) || (sym.local == localParameter && c.options.WarnUnusedParams) {
+			unused = append(unused, name)
+		}
+	}
+	sort.Slice(unused, func(i, j int) bool {
+		a, b := scope[unused[i]].span.Start, scope[unused[j]].span.Start
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	for _, name := range unused {
+		sym := scope[name]
+		if sym.local == localParameter {
+			c.reportWithSeverity(sym.span, fmt.Sprintf("parameter %q is never used", name),
+				fmt.Sprintf("remove the parameter or rename it to _%s", name), lexer.Warning, "unused-param")
+			continue
+		}
+		c.reportWithSeverity(sym.span, fmt.Sprintf("variable %q is declared but never used", name),
+			fmt.Sprintf("remove the declaration or rename it to _%s", name), lexer.Warning, "unused-var")
+	}
+}
+
+// blockComplexity counts the decision points in block: each if, for and
+// while statement, catch clause, and && or || operator in a condition.
+func blockComplexity(block *ast.BlockStmt) int {
+	if block == nil {
+		return 0
+	}
+	total := 0
+	for _, stmt := range block.Statements {
+		total += stmtComplexity(stmt)
+	}
+	return total
+}
+
+func stmtComplexity(stmt ast.Stmt) int {
+	switch s := stmt.(type) {
+	case *ast.BlockStmt:
+		return blockComplexity(s)
+	case *ast.IfStmt:
+		total := 1 + conditionComplexity(s.Cond) + blockComplexity(s.Then)
+		if s.Else != nil {
+			total += stmtComplexity(s.Else)
+		}
+		return total
+	case *ast.ForStmt:
+		return 1 + conditionComplexity(s.Condition) + blockComplexity(s.Body)
+	case *ast.WhileStmt:
+		return 1 + conditionComplexity(s.Cond) + blockComplexity(s.Body)
+	case *ast.TryStmt:
+		total := blockComplexity(s.TryBlock) + blockComplexity(s.FinallyBlock)
+		for _, clause := range s.CatchClauses {
+			total += 1 + blockComplexity(clause.Block)
+		}
+		return total
+	}
+	return 0
+}
+
+func conditionComplexity(expr ast.Expr) int {
+	switch e := expr.(type) {
+	case *ast.BinaryExpr:
+		total := conditionComplexity(e.Left) + conditionComplexity(e.Right)
+		if e.Op == "&&" || e.Op == "||" {
+			total++
+		}
+		return total
+	case *ast.UnaryExpr:
+		return conditionComplexity(e.Expr)
+	}
+	return 0
+}
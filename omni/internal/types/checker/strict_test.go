@@ -0,0 +1,201 @@
+package checker_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/lexer"
+	"github.com/omni-lang/omni/internal/types/checker"
+)
+
+func TestWarnDeadCode(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		dead bool
+	}{
+		{"after return", "func main():int {\n    return 0\n    let x:int = 1\n}\n", true},
+		{"after break", "func main():int {\n    for i:int = 0; i < 3; i++ {\n        break\n        let x:int = i\n    }\n    return 0\n}\n", true},
+		{"after if and else both return", "func f(a:int):int {\n    if a > 0 {\n        return 1\n    } else {\n        return 2\n    }\n    return 3\n}\n", true},
+		{"after if without else", "func f(a:int):int {\n    if a > 0 {\n        return 1\n    }\n    return 2\n}\n", false},
+		{"after loop containing return", "func f(a:int):int {\n    for i:int = 0; i < a; i++ {\n        return i\n    }\n    return 0\n}\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mod, err := parseSource(t, tt.src)
+			if err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
+			warnings, err := checker.CheckWithOptions("test.omni", tt.src, mod, checker.Options{WarnDeadCode: true})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := hasWarning(warnings, "dead-code"); got != tt.dead {
+				t.Errorf("dead code warning = %v, want %v (warnings: %v)", got, tt.dead, warnings)
+			}
+		})
+	}
+}
+
+func TestWarnDeadCodeLocatesFirstUnreachableStatement(t *testing.T) {
+	src := "func f(a:int):int {\n    while a > 0 {\n        continue\n        a = a - 1\n        a = a - 2\n    }\n    if a < 0 {\n        throw \"negative\"\n        return 1\n    }\n    return 0\n    return 2\n}\n"
+	mod, err := parseSource(t, src)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	warnings, err := checker.CheckWithOptions("test.omni", src, mod, checker.Options{WarnDeadCode: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var lines []int
+	for _, warning := range warnings {
+		if warning.Category == "dead-code" {
+			lines = append(lines, warning.Span.Start.Line)
+		}
+	}
+	// One warning per block, at its first unreachable statement
+	if want := []int{4, 9, 12}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("dead code warnings on lines %v, want %v", lines, want)
+	}
+	if len(warnings) > 0 && warnings[0].Span.Start.Column != 9 {
+		t.Errorf("first warning at column %d, want 9", warnings[0].Span.Start.Column)
+	}
+}
+
+// unusedWarnings returns the unused variable and parameter warnings as
+// "line:message" strings.
+func unusedWarnings(warnings []checker.Warning) []string {
+	var got []string
+	for _, w := range warnings {
+		if w.Category == "unused-var" || w.Category == "unused-param" {
+			got = append(got, fmt.Sprintf("%d:%s", w.Span.Start.Line, w.Message))
+		}
+	}
+	return got
+}
+
+func TestWarnUnusedVars(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"never read", "func main():int {\n    let x:int = 1\n    let y:int = 2\n    return y\n}\n",
+			[]string{`2:variable "x" is declared but never used`}},
+		{"underscore prefix", "func main():int {\n    let _unused = 5\n    return 0\n}\n", nil},
+		{"assigned but never read", "func main():int {\n    var total:int = 0\n    total = 3\n    return 0\n}\n",
+			[]string{`2:variable "total" is declared but never used`}},
+		{"read by an increment", "func main():int {\n    var n:int = 0\n    n++\n    return 0\n}\n", nil},
+		{"read in a lambda", "func main():int {\n    let k:int = 2\n    let f = |x| k\n    return f(3)\n}\n", nil},
+		{"read in interpolation", "func main():string {\n    let name:string = \"omni\"\n    return \"hi ${name}\"\n}\n", nil},
+		{"nested blocks in order", "func main():int {\n    let a:int = 1\n    if true {\n        let b:int = 2\n        let c:int = 3\n    }\n    return 0\n}\n",
+			[]string{`4:variable "b" is declared but never used`, `5:variable "c" is declared but never used`, `2:variable "a" is declared but never used`}},
+		{"tuple destructuring", "func main():int {\n    let (q, r) = (7, 1)\n    return q\n}\n",
+			[]string{`2:variable "r" is declared but never used`}},
+		{"parameters not checked", "func f(a:int, b:int):int {\n    return a\n}\nfunc main():int {\n    return f(1, 2)\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mod, err := parseSource(t, tt.src)
+			if err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
+			warnings, err := checker.CheckWithOptions("test.omni", tt.src, mod, checker.Options{WarnUnusedVars: true})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := unusedWarnings(warnings); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unused warnings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWarnUnusedParams(t *testing.T) {
+	src := `struct Point {
+    x:int
+    y:int
+}
+func (p:Point) origin():int {
+    return 0
+}
+func pick(a:int, b:int, _c:int):int {
+    let x:int = 1
+    return a
+}
+func main():int {
+    let p = Point{x: 1, y: 2}
+    return pick(p.origin(), 2, 3)
+}
+`
+	mod, err := parseSource(t, src)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	warnings, err := checker.CheckWithOptions("test.omni", src, mod, checker.Options{WarnUnusedParams: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The receiver p of origin is exempt, and so is _c; the unused
+	// variable x needs WarnUnusedVars
+	want := []string{`8:parameter "b" is never used`}
+	if got := unusedWarnings(warnings); !reflect.DeepEqual(got, want) {
+		t.Errorf("unused warnings = %q, want %q", got, want)
+	}
+}
+
+func TestMaxComplexity(t *testing.T) {
+	src := "func f(a:int, b:int):int {\n    if a > 0 && b > 0 {\n        return 1\n    }\n    while a < b {\n        a = a + 1\n    }\n    return a\n}\n"
+	mod, err := parseSource(t, src)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	// 1 + if + && + while = 4
+	warnings, err := checker.CheckWithOptions("test.omni", src, mod, checker.Options{MaxComplexity: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "cyclomatic complexity 4 (max 3)") {
+		t.Errorf("expected a single complexity warning, got %v", warnings)
+	}
+
+	warnings, err = checker.CheckWithOptions("test.omni", src, mod, checker.Options{MaxComplexity: 4})
+	if err != nil || len(warnings) != 0 {
+		t.Errorf("expected no diagnostics at the limit, got %v, %v", warnings, err)
+	}
+}
+
+func TestWarningsAsErrors(t *testing.T) {
+	src := "func main():int {\n    return 0\n    return 1\n}\n"
+	mod, err := parseSource(t, src)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if err := checker.Check("test.omni", src, mod); err != nil {
+		t.Fatalf("dead code should not fail default checking: %v", err)
+	}
+
+	warnings, err := checker.CheckWithOptions("test.omni", src, mod, checker.StrictOptions())
+	if err == nil {
+		t.Fatal("expected strict checking to promote the dead code warning")
+	}
+	if len(warnings) != 0 {
+		t.Errorf("promoted warnings should not also be returned as warnings: %v", warnings)
+	}
+	if !strings.Contains(err.Error(), "error [dead-code]: unreachable code") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func hasWarning(warnings []lexer.Diagnostic, category string) bool {
+	for _, w := range warnings {
+		if w.Severity == lexer.Warning && w.Category == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package checker
+
+import "fmt"
+
+// acceptsArgs reports whether a call of sig may pass n arguments. The
+// parameters with defaults may be left out, and a variadic function takes
+// any number of arguments for its last parameter.
+func (sig FunctionSignature) acceptsArgs(n int) bool {
+	if sig.Variadic {
+		return n >= sig.required()
+	}
+	return n >= sig.required() && n <= len(sig.Params)
+}
+
+// required returns the number of arguments a call of sig cannot leave out.
+func (sig FunctionSignature) required() int {
+	n := len(sig.Params) - sig.Defaults
+	if sig.Variadic {
+		n--
+	}
+	return n
+}
+
+// paramsFor returns the types that the n arguments of a call of sig are
+// checked against. The trailing arguments of a variadic function all take
+// the element type of its variadic parameter.
+func (sig FunctionSignature) paramsFor(n int) []string {
+	if !sig.Variadic || n <= len(sig.Params) {
+		return sig.Params
+	}
+	params := append([]string{}, sig.Params...)
+	for len(params) < n {
+		params = append(params, sig.Params[len(sig.Params)-1])
+	}
+	return params
+}
+
+// arityMismatch describes the arguments a call of the function name with
+// signature sig needed but did not get.
+func (sig FunctionSignature) arityMismatch(name string, got int) string {
+	if sig.Variadic {
+		return fmt.Sprintf("argument count mismatch: function %s expects at least %d arguments, got %d", name, sig.required(), got)
+	}
+	if sig.Defaults > 0 {
+		return fmt.Sprintf("argument count mismatch: function %s expects %d to %d arguments, got %d", name, sig.required(), len(sig.Params), got)
+	}
+	return fmt.Sprintf("argument count mismatch: function %s expects %d arguments, got %d", name, len(sig.Params), got)
+}
@@ -5,8 +5,14 @@ type Kind string
 
 const (
 	KindInt    Kind = "int"
-	KindLong   Kind = "long"
+	KindInt64  Kind = "int64"
+	KindLong   Kind = "long" // alias for KindInt64
 	KindByte   Kind = "byte"
+	KindUint   Kind = "uint" // alias for KindUint32
+	KindUint8  Kind = "uint8"
+	KindUint16 Kind = "uint16"
+	KindUint32 Kind = "uint32"
+	KindUint64 Kind = "uint64"
 	KindFloat  Kind = "float"
 	KindDouble Kind = "double"
 	KindBool   Kind = "bool"
@@ -15,6 +21,16 @@ const (
 	KindVoid   Kind = "void"
 )
 
+// UnsignedWidths maps the unsigned integer type names to their width in
+// bits.
+var UnsignedWidths = map[string]int{
+	string(KindUint):   32,
+	string(KindUint8):  8,
+	string(KindUint16): 16,
+	string(KindUint32): 32,
+	string(KindUint64): 64,
+}
+
 // Type captures the eventual type system representation. The bootstrap version
 // only stores the kind for diagnostics and planning.
 type Type struct {
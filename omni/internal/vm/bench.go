@@ -0,0 +1,83 @@
+package vm
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// BenchResult is the timing of one run of a benchmark.
+type BenchResult struct {
+	Name       string
+	Iterations int
+	NsPerOp    float64
+}
+
+// benchStore times the benchmarks the program runs between their
+// bench.start and bench.end instructions.
+type benchStore struct {
+	mu      sync.Mutex
+	starts  map[string]time.Time
+	results []BenchResult
+}
+
+var benches = &benchStore{starts: make(map[string]time.Time)}
+
+// TakeBenchResults returns the benchmark runs recorded since the last call,
+// in the order they ended, and forgets them.
+func TakeBenchResults() []BenchResult {
+	benches.mu.Lock()
+	defer benches.mu.Unlock()
+	results := benches.results
+	benches.results = nil
+	return results
+}
+
+// execBenchStart handles bench.start, which starts the clock of the
+// benchmark named by its operand.
+func execBenchStart(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	if len(inst.Operands) != 1 {
+		return Result{}, fmt.Errorf("bench.start: expected 1 operand (name), got %d", len(inst.Operands))
+	}
+	name := operandValue(fr, inst.Operands[0])
+	if name.Type != "string" {
+		return Result{}, fmt.Errorf("bench.start: name must be string")
+	}
+	benches.mu.Lock()
+	benches.starts[fmt.Sprint(name.Value)] = time.Now()
+	benches.mu.Unlock()
+	return Result{Type: "void"}, nil
+}
+
+// execBenchEnd handles bench.end, whose operands are the name of a started
+// benchmark and the number of iterations it ran. It records the time per
+// iteration.
+func execBenchEnd(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	end := time.Now()
+	if len(inst.Operands) != 2 {
+		return Result{}, fmt.Errorf("bench.end: expected 2 operands (name, iterations), got %d", len(inst.Operands))
+	}
+	name := operandValue(fr, inst.Operands[0])
+	iterations := operandValue(fr, inst.Operands[1])
+	n, ok := iterations.Value.(int)
+	if name.Type != "string" || !ok {
+		return Result{}, fmt.Errorf("bench.end: name must be string, iterations must be int")
+	}
+
+	key := fmt.Sprint(name.Value)
+	benches.mu.Lock()
+	defer benches.mu.Unlock()
+	start, ok := benches.starts[key]
+	if !ok {
+		return Result{}, fmt.Errorf("bench.end: benchmark %q was not started", key)
+	}
+	delete(benches.starts, key)
+	result := BenchResult{Name: key, Iterations: n}
+	if n > 0 {
+		result.NsPerOp = float64(end.Sub(start).Nanoseconds()) / float64(n)
+	}
+	benches.results = append(benches.results, result)
+	return Result{Type: "void"}, nil
+}
@@ -0,0 +1,47 @@
+package vm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func benchModule(start bool) *mir.Module {
+	fn := mir.NewFunction("main", "int", nil)
+	entry := fn.NewBlock("entry")
+	name := mir.Operand{Kind: mir.OperandLiteral, Literal: "bench_noop", Type: "string"}
+	if start {
+		entry.Instructions = append(entry.Instructions, mir.Instruction{ID: mir.InvalidValue, Op: "bench.start", Type: "void", Operands: []mir.Operand{name}})
+	}
+	entry.Instructions = append(entry.Instructions, mir.Instruction{ID: mir.InvalidValue, Op: "bench.end", Type: "void", Operands: []mir.Operand{
+		name, {Kind: mir.OperandLiteral, Literal: "4", Type: "int"},
+	}})
+	entry.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "0", Type: "int"}}}
+	return &mir.Module{Functions: []*mir.Function{fn}}
+}
+
+func TestBenchStartEndRecordsResult(t *testing.T) {
+	vm.TakeBenchResults()
+	if _, err := vm.Execute(benchModule(true), "main"); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	results := vm.TakeBenchResults()
+	if len(results) != 1 || results[0].Name != "bench_noop" || results[0].Iterations != 4 {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if results[0].NsPerOp < 0 {
+		t.Errorf("NsPerOp = %v, want a non-negative timing", results[0].NsPerOp)
+	}
+	if again := vm.TakeBenchResults(); len(again) != 0 {
+		t.Errorf("TakeBenchResults kept %d results", len(again))
+	}
+}
+
+func TestBenchEndWithoutStart(t *testing.T) {
+	_, err := vm.Execute(benchModule(false), "main")
+	if err == nil || !strings.Contains(err.Error(), "was not started") {
+		t.Fatalf("error = %v, want bench.end to reject an unstarted benchmark", err)
+	}
+}
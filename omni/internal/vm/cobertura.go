@@ -0,0 +1,221 @@
+package vm
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The Cobertura document model, following coverage-04.dtd. Every rate is a
+// fraction between 0 and 1.
+type coberturaCoverage struct {
+	XMLName         xml.Name           `xml:"coverage"`
+	LineRate        float64            `xml:"line-rate,attr"`
+	BranchRate      float64            `xml:"branch-rate,attr"`
+	LinesCovered    int                `xml:"lines-covered,attr"`
+	LinesValid      int                `xml:"lines-valid,attr"`
+	BranchesCovered int                `xml:"branches-covered,attr"`
+	BranchesValid   int                `xml:"branches-valid,attr"`
+	Complexity      float64            `xml:"complexity,attr"`
+	Version         string             `xml:"version,attr"`
+	Timestamp       int64              `xml:"timestamp,attr"`
+	Packages        []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   float64          `xml:"line-rate,attr"`
+	BranchRate float64          `xml:"branch-rate,attr"`
+	Complexity float64          `xml:"complexity,attr"`
+	Classes    []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name       string            `xml:"name,attr"`
+	Filename   string            `xml:"filename,attr"`
+	LineRate   float64           `xml:"line-rate,attr"`
+	BranchRate float64           `xml:"branch-rate,attr"`
+	Complexity float64           `xml:"complexity,attr"`
+	Methods    []coberturaMethod `xml:"methods>method"`
+	Lines      []coberturaLine   `xml:"lines>line"`
+}
+
+type coberturaMethod struct {
+	Name       string          `xml:"name,attr"`
+	Signature  string          `xml:"signature,attr"`
+	LineRate   float64         `xml:"line-rate,attr"`
+	BranchRate float64         `xml:"branch-rate,attr"`
+	Complexity float64         `xml:"complexity,attr"`
+	Lines      []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number            int    `xml:"number,attr"`
+	Hits              int    `xml:"hits,attr"`
+	Branch            bool   `xml:"branch,attr"`
+	ConditionCoverage string `xml:"condition-coverage,attr,omitempty"`
+}
+
+// coberturaFunction accumulates the coverage of one function.
+type coberturaFunction struct {
+	file            string
+	line            int
+	hits            int
+	branchesCovered int
+	branchesValid   int
+}
+
+func (f *coberturaFunction) lineRate() float64 {
+	if f.hits > 0 {
+		return 1
+	}
+	return 0
+}
+
+// branchRate is 1 for functions without branches: nothing was missed.
+func (f *coberturaFunction) branchRate() float64 {
+	if f.branchesValid == 0 {
+		return 1
+	}
+	return float64(f.branchesCovered) / float64(f.branchesValid)
+}
+
+// ExportCoverageCobertura exports the data of ExportCoverage as a Cobertura
+// XML report. Functions are grouped into one package and class per module
+// ("std.io" for std.io.println, "main" for unqualified functions), and each
+// function contributes a single line for its call count. Each conditional
+// branch counts as two branches, one per path.
+func ExportCoverageCobertura() ([]byte, error) {
+	raw, err := ExportCoverage()
+	if err != nil {
+		return nil, err
+	}
+	var data struct {
+		Entries  []coverageEntry       `json:"entries"`
+		Branches map[string]BranchData `json:"branches"`
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("decode coverage data: %w", err)
+	}
+
+	funcs := make(map[string]*coberturaFunction)
+	lookup := func(name string) *coberturaFunction {
+		fn, ok := funcs[name]
+		if !ok {
+			fn = &coberturaFunction{}
+			funcs[name] = fn
+		}
+		return fn
+	}
+	for _, entry := range data.Entries {
+		fn := lookup(entry.FunctionName)
+		fn.file, fn.line = entry.FilePath, entry.LineNumber
+		fn.hits += entry.CallCount
+	}
+	for key, branch := range data.Branches {
+		name := key
+		for i := 0; i < 2; i++ {
+			if idx := strings.LastIndex(name, ":"); idx >= 0 {
+				name = name[:idx]
+			}
+		}
+		fn := lookup(name)
+		fn.branchesValid += 2
+		if branch.True {
+			fn.branchesCovered++
+		}
+		if branch.False {
+			fn.branchesCovered++
+		}
+		// Branch data only exists for functions the VM executed, so a
+		// taken path means the function ran.
+		if fn.hits == 0 && fn.branchesCovered > 0 {
+			fn.hits = 1
+		}
+	}
+
+	byModule := make(map[string][]string)
+	for name := range funcs {
+		module := "main"
+		if idx := strings.LastIndex(name, "."); idx >= 0 {
+			module = name[:idx]
+		}
+		byModule[module] = append(byModule[module], name)
+	}
+	modules := make([]string, 0, len(byModule))
+	for module := range byModule {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+
+	report := coberturaCoverage{
+		Version:   "omni-vm",
+		Timestamp: time.Now().UnixMilli(),
+		Packages:  []coberturaPackage{},
+	}
+	for _, module := range modules {
+		names := byModule[module]
+		sort.Strings(names)
+
+		class := coberturaClass{Name: module, Methods: []coberturaMethod{}, Lines: []coberturaLine{}}
+		var linesCovered, branchesCovered, branchesValid int
+		for _, name := range names {
+			fn := funcs[name]
+			if class.Filename == "" {
+				class.Filename = fn.file
+			}
+			line := coberturaLine{Number: fn.line, Hits: fn.hits}
+			if fn.branchesValid > 0 {
+				line.Branch = true
+				line.ConditionCoverage = fmt.Sprintf("%d%% (%d/%d)",
+					fn.branchesCovered*100/fn.branchesValid, fn.branchesCovered, fn.branchesValid)
+			}
+			class.Methods = append(class.Methods, coberturaMethod{
+				Name:       strings.TrimPrefix(name, module+"."),
+				Signature:  name,
+				LineRate:   fn.lineRate(),
+				BranchRate: fn.branchRate(),
+				Lines:      []coberturaLine{line},
+			})
+			class.Lines = append(class.Lines, line)
+			if fn.hits > 0 {
+				linesCovered++
+			}
+			branchesCovered += fn.branchesCovered
+			branchesValid += fn.branchesValid
+		}
+		class.LineRate = rate(linesCovered, len(names))
+		class.BranchRate = rate(branchesCovered, branchesValid)
+
+		report.Packages = append(report.Packages, coberturaPackage{
+			Name:       module,
+			LineRate:   class.LineRate,
+			BranchRate: class.BranchRate,
+			Classes:    []coberturaClass{class},
+		})
+		report.LinesCovered += linesCovered
+		report.LinesValid += len(names)
+		report.BranchesCovered += branchesCovered
+		report.BranchesValid += branchesValid
+	}
+	report.LineRate = rate(report.LinesCovered, report.LinesValid)
+	report.BranchRate = rate(report.BranchesCovered, report.BranchesValid)
+
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode cobertura report: %w", err)
+	}
+	doc := xml.Header + `<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">` + "\n"
+	return append([]byte(doc), append(out, '\n')...), nil
+}
+
+// rate returns covered/valid, or 1 when there is nothing to cover.
+func rate(covered, valid int) float64 {
+	if valid == 0 {
+		return 1
+	}
+	return float64(covered) / float64(valid)
+}
@@ -0,0 +1,194 @@
+package vm_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+// coberturaSchema describes the elements of coverage-04.dtd that the VM
+// emits: the parents each element may appear under and the attributes it
+// must carry.
+var coberturaSchema = map[string]struct {
+	parents  []string
+	required []string
+}{
+	"coverage": {nil, []string{"line-rate", "branch-rate", "lines-covered", "lines-valid", "branches-covered", "branches-valid", "complexity", "version", "timestamp"}},
+	"packages": {[]string{"coverage"}, nil},
+	"package":  {[]string{"packages"}, []string{"name", "line-rate", "branch-rate", "complexity"}},
+	"classes":  {[]string{"package"}, nil},
+	"class":    {[]string{"classes"}, []string{"name", "filename", "line-rate", "branch-rate", "complexity"}},
+	"methods":  {[]string{"class"}, nil},
+	"method":   {[]string{"methods"}, []string{"name", "signature", "line-rate", "branch-rate", "complexity"}},
+	"lines":    {[]string{"class", "method"}, nil},
+	"line":     {[]string{"lines"}, []string{"number", "hits"}},
+}
+
+// validateCobertura checks doc against coberturaSchema and that every rate
+// attribute lies between 0 and 1.
+func validateCobertura(doc []byte) error {
+	if !bytes.HasPrefix(doc, []byte(xml.Header)) {
+		return errors.New("missing XML declaration")
+	}
+	dec := xml.NewDecoder(bytes.NewReader(doc))
+	var stack []string
+	sawRoot := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			rule, ok := coberturaSchema[name]
+			if !ok {
+				return fmt.Errorf("unexpected element <%s>", name)
+			}
+			parent := ""
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			if rule.parents == nil {
+				if parent != "" || sawRoot {
+					return fmt.Errorf("<%s> must be the document root", name)
+				}
+				sawRoot = true
+			} else if !containsString(rule.parents, parent) {
+				return fmt.Errorf("<%s> not allowed inside <%s>", name, parent)
+			}
+			attrs := make(map[string]string, len(t.Attr))
+			for _, attr := range t.Attr {
+				attrs[attr.Name.Local] = attr.Value
+			}
+			for _, attr := range rule.required {
+				if _, ok := attrs[attr]; !ok {
+					return fmt.Errorf("<%s> missing required attribute %q", name, attr)
+				}
+			}
+			for _, attr := range []string{"line-rate", "branch-rate"} {
+				value, ok := attrs[attr]
+				if !ok {
+					continue
+				}
+				rate, err := strconv.ParseFloat(value, 64)
+				if err != nil || rate < 0 || rate > 1 {
+					return fmt.Errorf("<%s> has invalid %s %q", name, attr, value)
+				}
+			}
+			stack = append(stack, name)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if !sawRoot {
+		return errors.New("missing <coverage> root element")
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// TestExportCoverageCobertura runs a function that calls a std function and
+// takes one path of a branch, and checks the Cobertura report against the
+// schema and the expected rates.
+func TestExportCoverageCobertura(t *testing.T) {
+	fn := mir.NewFunction("main", "int", nil)
+	entry := fn.NewBlock("entry")
+	then := fn.NewBlock("then")
+	other := fn.NewBlock("other")
+
+	abs := fn.NextValue()
+	entry.Instructions = append(entry.Instructions, mir.Instruction{
+		ID: abs, Op: "call", Type: "int",
+		Operands: []mir.Operand{
+			{Kind: mir.OperandLiteral, Literal: "std.math.abs"},
+			{Kind: mir.OperandLiteral, Literal: "-3", Type: "int"},
+		},
+	})
+	cond := fn.NextValue()
+	entry.Instructions = append(entry.Instructions, mir.Instruction{
+		ID: cond, Op: "const", Type: "bool",
+		Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "true", Type: "bool"}},
+	})
+	entry.Terminator = mir.Terminator{Op: "cbr", Operands: []mir.Operand{
+		{Kind: mir.OperandValue, Value: cond, Type: "bool"},
+		{Kind: mir.OperandLiteral, Literal: "then"},
+		{Kind: mir.OperandLiteral, Literal: "other"},
+	}}
+	ret := mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "0", Type: "int"}}}
+	then.Terminator = ret
+	other.Terminator = ret
+
+	vm.SetCoverageEnabled(true)
+	vm.ResetCoverage()
+	defer func() {
+		vm.SetCoverageEnabled(false)
+		vm.ResetCoverage()
+	}()
+
+	if _, err := vm.Execute(&mir.Module{Functions: []*mir.Function{fn}}, "main"); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+
+	doc, err := vm.ExportCoverageCobertura()
+	if err != nil {
+		t.Fatalf("ExportCoverageCobertura failed: %v", err)
+	}
+	if err := validateCobertura(doc); err != nil {
+		t.Fatalf("invalid Cobertura report: %v\n%s", err, doc)
+	}
+
+	var report struct {
+		LineRate   float64 `xml:"line-rate,attr"`
+		BranchRate float64 `xml:"branch-rate,attr"`
+		Packages   []struct {
+			Name       string  `xml:"name,attr"`
+			BranchRate float64 `xml:"branch-rate,attr"`
+			Methods    []struct {
+				Name string `xml:"name,attr"`
+			} `xml:"classes>class>methods>method"`
+		} `xml:"packages>package"`
+	}
+	if err := xml.Unmarshal(doc, &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+
+	if report.LineRate != 1 || report.BranchRate != 0.5 {
+		t.Errorf("coverage rates = %v/%v, want 1/0.5", report.LineRate, report.BranchRate)
+	}
+	var names []string
+	for _, pkg := range report.Packages {
+		for _, method := range pkg.Methods {
+			names = append(names, pkg.Name+"."+method.Name)
+		}
+	}
+	if got := strings.Join(names, ","); got != "main.main,std.math.abs" {
+		t.Errorf("methods = %s, want main.main,std.math.abs", got)
+	}
+}
+
+func TestValidateCoberturaRejectsMissingRate(t *testing.T) {
+	doc := xml.Header + `<coverage line-rate="1" lines-covered="0" lines-valid="0" branches-covered="0" branches-valid="0" complexity="0" version="x" timestamp="0"></coverage>`
+	if err := validateCobertura([]byte(doc)); err == nil || !strings.Contains(err.Error(), "branch-rate") {
+		t.Fatalf("validateCobertura() = %v, want missing branch-rate error", err)
+	}
+}
@@ -0,0 +1,162 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// execCollections handles the higher-order std.collections functions. The
+// callback is a function value, invoked as func.call would invoke it, so
+// named functions and closures both work.
+func execCollections(funcs map[string]*mir.Function, callee string, operands []mir.Operand, fr *frame) (Result, bool, error) {
+	want := 0
+	switch callee {
+	case "std.collections.filter", "std.collections.map_fn":
+		want = 2
+	case "std.collections.reduce":
+		want = 3
+	default:
+		return Result{}, false, nil
+	}
+	name := callee[len("std."):]
+	if len(operands) != want {
+		return Result{}, true, fmt.Errorf("%s: expected %d arguments, got %d", name, want, len(operands))
+	}
+	arr := operandValue(fr, operands[0])
+	elems, ok := arrayResults(arr)
+	if !ok {
+		return Result{}, true, fmt.Errorf("%s: expected an array, got %s", name, arr.Type)
+	}
+	fn := operandValue(fr, operands[len(operands)-1])
+
+	switch callee {
+	case "std.collections.filter":
+		kept := []Result{}
+		for _, elem := range elems {
+			res, err := callFunctionValue(funcs, fn, elem)
+			if err != nil {
+				return Result{}, true, err
+			}
+			keep, ok := res.Value.(bool)
+			if !ok {
+				return Result{}, true, fmt.Errorf("%s: predicate returned %s, expected bool", name, res.Type)
+			}
+			if keep {
+				kept = append(kept, elem)
+			}
+		}
+		return newArrayResult(arrayElementType(arr.Type), kept), true, nil
+	case "std.collections.map_fn":
+		mapped := make([]Result, len(elems))
+		for i, elem := range elems {
+			res, err := callFunctionValue(funcs, fn, elem)
+			if err != nil {
+				return Result{}, true, err
+			}
+			mapped[i] = res
+		}
+		// The element type follows what the callback returned, since
+		// lambdas carry no declared result type
+		elemType := "any"
+		if len(mapped) > 0 {
+			elemType = mapped[0].Type
+		}
+		return newArrayResult(elemType, mapped), true, nil
+	default:
+		acc := operandValue(fr, operands[1])
+		for _, elem := range elems {
+			res, err := callFunctionValue(funcs, fn, acc, elem)
+			if err != nil {
+				return Result{}, true, err
+			}
+			acc = res
+		}
+		return acc, true, nil
+	}
+}
+
+// callFunctionValue calls fn with args through execFuncCall, using a
+// synthetic frame that holds the function value and the arguments.
+func callFunctionValue(funcs map[string]*mir.Function, fn Result, args ...Result) (Result, error) {
+	fr := &frame{values: map[mir.ValueID]Result{0: fn}}
+	inst := mir.Instruction{
+		ID:       mir.InvalidValue,
+		Op:       "func.call",
+		Operands: []mir.Operand{{Kind: mir.OperandValue, Value: 0}},
+	}
+	for i, arg := range args {
+		id := mir.ValueID(i + 1)
+		fr.values[id] = arg
+		inst.Operands = append(inst.Operands, mir.Operand{Kind: mir.OperandValue, Value: id})
+	}
+	return execFuncCall(funcs, fr, inst)
+}
+
+// arrayResults returns the elements of an array value as Results.
+func arrayResults(arr Result) ([]Result, bool) {
+	var elems []Result
+	switch v := arr.Value.(type) {
+	case []int:
+		for _, e := range v {
+			elems = append(elems, Result{Type: "int", Value: e})
+		}
+	case []string:
+		for _, e := range v {
+			elems = append(elems, Result{Type: "string", Value: e})
+		}
+	case []float64:
+		for _, e := range v {
+			elems = append(elems, Result{Type: "float", Value: e})
+		}
+	case []bool:
+		for _, e := range v {
+			elems = append(elems, Result{Type: "bool", Value: e})
+		}
+	case []interface{}:
+		elemType := arrayElementType(arr.Type)
+		for _, e := range v {
+			elems = append(elems, Result{Type: elemType, Value: e})
+		}
+	default:
+		return nil, false
+	}
+	return elems, true
+}
+
+// newArrayResult builds an array of elemType from elems, using the same
+// slice types as array.init.
+func newArrayResult(elemType string, elems []Result) Result {
+	arrayType := "[]<" + elemType + ">"
+	switch elemType {
+	case "int":
+		values := make([]int, len(elems))
+		for i, e := range elems {
+			values[i], _ = e.Value.(int)
+		}
+		return Result{Type: arrayType, Value: values}
+	case "string":
+		values := make([]string, len(elems))
+		for i, e := range elems {
+			values[i], _ = e.Value.(string)
+		}
+		return Result{Type: arrayType, Value: values}
+	case "float", "double":
+		values := make([]float64, len(elems))
+		for i, e := range elems {
+			values[i], _ = e.Value.(float64)
+		}
+		return Result{Type: arrayType, Value: values}
+	case "bool":
+		values := make([]bool, len(elems))
+		for i, e := range elems {
+			values[i], _ = e.Value.(bool)
+		}
+		return Result{Type: arrayType, Value: values}
+	}
+	values := make([]interface{}, len(elems))
+	for i, e := range elems {
+		values[i] = e.Value
+	}
+	return Result{Type: arrayType, Value: values}
+}
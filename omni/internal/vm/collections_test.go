@@ -0,0 +1,64 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestCollectionsFilter(t *testing.T) {
+	src := `func main():int {
+  let xs:array<int> = [1, 2, 3, 4, 5, 6]
+  let evens:array<int> = std.collections.filter(xs, |x| x % 2 == 0)
+  let none:array<int> = std.collections.filter(xs, |x| x > 10)
+  return len(evens) * 100 + evens[0] * 10 + evens[2] + len(none)
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 326 {
+		t.Errorf("result = %v, want 326", res.Value)
+	}
+}
+
+func TestCollectionsMapFn(t *testing.T) {
+	src := `func square(x:int):int {
+  return x * x
+}
+
+func main():string {
+  let xs:array<int> = [1, 2, 3]
+  let squares:array<int> = std.collections.map_fn(xs, square)
+  let labels:array<string> = std.collections.map_fn(squares, |n| "#" + std.int_to_string(n))
+  return labels[0] + labels[1] + labels[2]
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != "#1#4#9" {
+		t.Errorf("result = %q, want %q", res.Value, "#1#4#9")
+	}
+}
+
+func TestCollectionsReduce(t *testing.T) {
+	src := `func main():string {
+  let words:array<string> = ["a", "b", "c"]
+  let total:int = std.collections.reduce([1, 2, 3, 4], 10, |acc, x| acc + x)
+  let joined:string = std.collections.reduce(words, ">", |acc, w| acc + w)
+  let empty:array<int> = std.collections.filter([1], |x| x > 1)
+  let initial:int = std.collections.reduce(empty, 7, |acc, x| acc * x)
+  return std.int_to_string(total) + joined + std.int_to_string(initial)
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != "20>abc7" {
+		t.Errorf("result = %q, want %q", res.Value, "20>abc7")
+	}
+}
@@ -0,0 +1,32 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestDefaultParamValues(t *testing.T) {
+	src := `enum Unit { ONE TEN }
+func scale(u:Unit = Unit.TEN):int {
+  return match u {
+    case Unit.ONE => 1,
+    case Unit.TEN => 10
+  }
+}
+func combine(a:int, b:int = 2 * 3, c:int = -1):int {
+  return a * 100 + b * 10 + c
+}
+func main():int {
+  return combine(1) + combine(2, 0) + combine(3, 1, 1) + scale() + scale(Unit.ONE)
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	// 159 + 199 + 311 + 10 + 1
+	if res.Value != 680 {
+		t.Errorf("result = %v, want 680", res.Value)
+	}
+}
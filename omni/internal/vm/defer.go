@@ -0,0 +1,70 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// execDeferPush handles the defer.push instruction of a defer statement. Its
+// operands are those of the deferred call, which are evaluated now; the call
+// itself runs when the function reaches defer.run on its way out.
+func execDeferPush(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	if len(inst.Operands) == 0 {
+		return Result{}, fmt.Errorf("defer.push requires a callee")
+	}
+	op := "call"
+	if inst.Operands[0].Kind != mir.OperandLiteral {
+		op = "func.call"
+	}
+	// The call runs in a frame of its own that holds the argument values
+	// as they were at the defer statement
+	args := &frame{values: make(map[mir.ValueID]Result, len(inst.Operands))}
+	call := mir.Instruction{ID: inst.ID, Op: op, Type: inst.Type, Operands: make([]mir.Operand, len(inst.Operands))}
+	for i, operand := range inst.Operands {
+		if operand.Kind == mir.OperandValue {
+			id := mir.ValueID(i)
+			args.values[id] = operandValue(fr, operand)
+			operand.Value = id
+		}
+		call.Operands[i] = operand
+	}
+	fr.defers = append(fr.defers, func() error {
+		_, err := execInstruction(funcs, args, call)
+		return err
+	})
+	return Result{Type: "void"}, nil
+}
+
+// execDeferRun handles defer.run, which runs the deferred calls of the
+// function in the reverse order of their defer statements.
+func execDeferRun(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	for len(fr.defers) > 0 {
+		deferred := fr.defers[len(fr.defers)-1]
+		fr.defers = fr.defers[:len(fr.defers)-1]
+		if err := deferred(); err != nil {
+			return Result{}, fmt.Errorf("deferred call: %w", err)
+		}
+	}
+	return Result{Type: "void"}, nil
+}
+
+// unwindDefers makes the deferred calls of fr in reverse order when the
+// exception err leaves the function, and returns the error to pass on. A
+// deferred call that fails replaces err with its own error, as a throw in
+// a finally block does. Other errors stop the program without them.
+func (fr *frame) unwindDefers(err error) error {
+	var thrown ThrownError
+	if !errors.As(err, &thrown) {
+		return err
+	}
+	for len(fr.defers) > 0 {
+		deferred := fr.defers[len(fr.defers)-1]
+		fr.defers = fr.defers[:len(fr.defers)-1]
+		if deferErr := deferred(); deferErr != nil {
+			err = fmt.Errorf("deferred call: %w", deferErr)
+		}
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package vm_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestDeferClosesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	// The deferred write runs before the deferred close, and main's own
+	// close fails because the file is closed already
+	src := `import std
+func save(path:string):int {
+  let f:int = std.file.open(path, "w")
+  defer std.file.close(f)
+  defer std.file.write(f, "tail", 4)
+  std.file.write(f, "body", 4)
+  return f
+}
+func main():int {
+  let f:int = save(` + strconv.Quote(path) + `)
+  return std.file.close(f)
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != -1 {
+		t.Errorf("closing the file again = %v, want -1", res.Value)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if string(data) != "bodytail" {
+		t.Errorf("file holds %q, want bodytail", data)
+	}
+}
+
+func TestDeferRunsOnEveryReturn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.txt")
+	src := `import std
+func mark(s:string) {
+  std.os.append_file(` + strconv.Quote(path) + `, s)
+}
+func pick(x:int):int {
+  defer mark("1")
+  if x > 0 {
+    defer mark("2")
+    return x
+  }
+  return 0
+}
+func main():int {
+  let a:int = pick(5)
+  let b:int = pick(0)
+  return a + b
+}
+`
+	if _, err := vm.Execute(buildSource(t, src), "main"); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	// pick(5) runs mark("2") then mark("1"); pick(0) only mark("1")
+	if string(data) != "211" {
+		t.Errorf("trace = %q, want 211", data)
+	}
+}
@@ -0,0 +1,83 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// dequeValue is the VM representation of a std.collections.deque value.
+// Deques are shared by reference, so a deque passed to a function is the
+// one the caller sees. items runs from front to back.
+type dequeValue struct {
+	elemType string
+	items    []interface{}
+}
+
+// execDeque handles the std.collections.deque functions. Popping or peeking
+// at an empty deque is an error, as it is in the C runtime.
+func execDeque(callee string, operands []mir.Operand, fr *frame) (Result, bool, error) {
+	if !strings.HasPrefix(callee, "std.collections.deque.") {
+		return Result{}, false, nil
+	}
+	name := callee[len("std."):]
+	op := strings.TrimPrefix(callee, "std.collections.deque.")
+	want := 1
+	switch op {
+	case "create":
+		want = 0
+	case "push_front", "push_back":
+		want = 2
+	case "pop_front", "pop_back", "peek_front", "peek_back", "size", "is_empty":
+	default:
+		return Result{}, false, nil
+	}
+	if len(operands) != want {
+		return Result{}, true, fmt.Errorf("%s: expected %d arguments, got %d", name, want, len(operands))
+	}
+	if op == "create" {
+		return Result{Type: "deque<any>", Value: &dequeValue{}}, true, nil
+	}
+	arg := operandValue(fr, operands[0])
+	d, ok := arg.Value.(*dequeValue)
+	if !ok {
+		return Result{}, true, fmt.Errorf("%s: expected a deque, got %s", name, arg.Type)
+	}
+
+	switch op {
+	case "push_front", "push_back":
+		val := operandValue(fr, operands[1])
+		if d.elemType == "" {
+			d.elemType = val.Type
+		}
+		if op == "push_front" {
+			d.items = append([]interface{}{val.Value}, d.items...)
+		} else {
+			d.items = append(d.items, val.Value)
+		}
+		return Result{Type: "void"}, true, nil
+	case "size":
+		return Result{Type: "int", Value: len(d.items)}, true, nil
+	case "is_empty":
+		return Result{Type: "bool", Value: len(d.items) == 0}, true, nil
+	}
+
+	if len(d.items) == 0 {
+		return Result{}, true, fmt.Errorf("%s: deque is empty", name)
+	}
+	front := strings.HasSuffix(op, "_front")
+	i := len(d.items) - 1
+	if front {
+		i = 0
+	}
+	val := Result{Type: d.elemType, Value: d.items[i]}
+	if strings.HasPrefix(op, "pop_") {
+		if front {
+			d.items = d.items[1:]
+		} else {
+			d.items = d.items[:i]
+		}
+	}
+	return val, true, nil
+}
@@ -0,0 +1,69 @@
+package vm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestDequeAlternatingPushOrder(t *testing.T) {
+	src := `func main():string {
+  let d:deque<int> = std.collections.deque.create()
+  for i:int = 1; i <= 6; i++ {
+    if i % 2 == 0 {
+      std.collections.deque.push_front(d, i)
+    } else {
+      std.collections.deque.push_back(d, i)
+    }
+  }
+  var out:string = std.int_to_string(std.collections.deque.size(d)) + ":"
+  while !std.collections.deque.is_empty(d) {
+    out = out + std.int_to_string(std.collections.deque.pop_front(d))
+  }
+  return out
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != "6:642135" {
+		t.Errorf("result = %q, want %q", res.Value, "6:642135")
+	}
+}
+
+func TestDequePopAndPeekBothEnds(t *testing.T) {
+	src := `func main():string {
+  let d:deque<string> = std.collections.deque.create()
+  std.collections.deque.push_front(d, "b")
+  std.collections.deque.push_back(d, "c")
+  std.collections.deque.push_front(d, "a")
+  let ends:string = std.collections.deque.peek_front(d) + std.collections.deque.peek_back(d)
+  let back:string = std.collections.deque.pop_back(d)
+  let front:string = std.collections.deque.pop_front(d)
+  return ends + back + front + std.collections.deque.peek_front(d)
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != "accab" {
+		t.Errorf("result = %q, want %q", res.Value, "accab")
+	}
+}
+
+func TestDequePopEmpty(t *testing.T) {
+	src := `func main():int {
+  let d:deque<int> = std.collections.deque.create()
+  std.collections.deque.push_back(d, 1)
+  let first:int = std.collections.deque.pop_back(d)
+  return first + std.collections.deque.pop_back(d)
+}
+`
+	_, err := vm.Execute(buildSource(t, src), "main")
+	if err == nil || !strings.Contains(err.Error(), "collections.deque.pop_back: deque is empty") {
+		t.Fatalf("error = %v, want the empty deque error", err)
+	}
+}
@@ -0,0 +1,34 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestDestructuringLet(t *testing.T) {
+	src := `struct Point {
+  x:int
+  y:int
+  z:int
+}
+func divmod(a:int, b:int):(int, int) {
+  return (a / b, a % b)
+}
+func main():int {
+  let (q, r) = divmod(17, 5)
+  let p:Point = Point{x: 1, y: 2, z: 3}
+  var { x, z: depth, .. } = p
+  x = x + 10
+  let (_, second) = (q, depth)
+  return q * 1000 + r * 100 + x + second
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 3214 {
+		t.Errorf("result = %v, want 3214", res.Value)
+	}
+}
@@ -0,0 +1,314 @@
+package vm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// diffOp is a single line of a line-based edit script. Kind is ' ' for an
+// unchanged line, '-' for a line removed from the original and '+' for a line
+// added by the modified text. OldPos and NewPos are the 0-based positions in
+// the original and modified line slices before the op is applied.
+type diffOp struct {
+	Kind   byte
+	Text   string
+	OldPos int
+	NewPos int
+}
+
+// diffHunk is a parsed "@@ -a,b +c,d @@" section of a unified diff.
+type diffHunk struct {
+	OldStart int
+	OldCount int
+	NewStart int
+	NewCount int
+	Lines    []string // each line keeps its ' ', '-' or '+' prefix
+}
+
+const diffNoNewlineMarker = "\\ No newline at end of file"
+
+// splitDiffLines splits s into lines, keeping the trailing newline of each
+// line so that a missing final newline survives a diff/apply round trip.
+func splitDiffLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// myersDiff computes the shortest edit script turning a into b using Myers'
+// O(ND) algorithm.
+func myersDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	maxD := n + m
+	offset := maxD
+	v := make([]int, 2*maxD+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= maxD; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk the trace backwards to recover the edit script.
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		snapshot := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && snapshot[offset+k-1] < snapshot[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := snapshot[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{Kind: ' ', Text: a[x], OldPos: x, NewPos: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{Kind: '+', Text: b[prevY], OldPos: prevX, NewPos: prevY})
+			} else {
+				ops = append(ops, diffOp{Kind: '-', Text: a[prevX], OldPos: prevX, NewPos: prevY})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// diffUnified renders the differences between original and modified as a
+// unified diff with the given number of context lines. Identical inputs
+// produce an empty string.
+func diffUnified(original, modified string, context int) string {
+	if context < 0 {
+		context = 0
+	}
+	ops := myersDiff(splitDiffLines(original), splitDiffLines(modified))
+
+	var out strings.Builder
+	i := 0
+	for i < len(ops) {
+		if ops[i].Kind == ' ' {
+			i++
+			continue
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].Kind != ' ' {
+				end++
+				continue
+			}
+			// Merge with the next change if the unchanged run between them
+			// would otherwise be split into overlapping context.
+			j := end
+			for j < len(ops) && ops[j].Kind == ' ' {
+				j++
+			}
+			if j == len(ops) || j-end > 2*context {
+				if end+context < j {
+					j = end + context
+				}
+				end = j
+				break
+			}
+			end = j
+		}
+
+		if out.Len() == 0 {
+			out.WriteString("--- original\n+++ modified\n")
+		}
+		writeDiffHunk(&out, ops[start:end])
+		i = end
+	}
+	return out.String()
+}
+
+func writeDiffHunk(out *strings.Builder, ops []diffOp) {
+	oldStart, newStart := ops[0].OldPos, ops[0].NewPos
+	oldCount, newCount := 0, 0
+	for _, op := range ops {
+		if op.Kind != '+' {
+			oldCount++
+		}
+		if op.Kind != '-' {
+			newCount++
+		}
+	}
+	// Unified diffs use 1-based line numbers, except that an empty range
+	// names the line after which the change applies.
+	if oldCount > 0 {
+		oldStart++
+	}
+	if newCount > 0 {
+		newStart++
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, op := range ops {
+		out.WriteByte(op.Kind)
+		out.WriteString(op.Text)
+		if !strings.HasSuffix(op.Text, "\n") {
+			out.WriteString("\n" + diffNoNewlineMarker + "\n")
+		}
+	}
+}
+
+// parseDiffRange parses the "a,b" part of a hunk header. A missing count
+// defaults to 1, as produced by GNU diff for single-line ranges.
+func parseDiffRange(s string) (int, int, error) {
+	startStr, countStr, hasCount := strings.Cut(s, ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid hunk range %q", s)
+	}
+	count := 1
+	if hasCount {
+		count, err = strconv.Atoi(countStr)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid hunk range %q", s)
+		}
+	}
+	return start, count, nil
+}
+
+func parseDiffHunks(patch string) ([]diffHunk, error) {
+	var hunks []diffHunk
+	var current *diffHunk
+	remainingOld, remainingNew := 0, 0
+
+	for _, line := range splitDiffLines(patch) {
+		if strings.HasPrefix(line, "\\") {
+			// "\ No newline at end of file" applies to the previous hunk line.
+			if current != nil && len(current.Lines) > 0 {
+				last := len(current.Lines) - 1
+				current.Lines[last] = strings.TrimSuffix(current.Lines[last], "\n")
+			}
+			continue
+		}
+		if current != nil && (remainingOld > 0 || remainingNew > 0) {
+			if line == "\n" {
+				// Some tools strip the space prefix from empty context lines.
+				line = " \n"
+			}
+			switch line[0] {
+			case ' ':
+				remainingOld--
+				remainingNew--
+			case '-':
+				remainingOld--
+			case '+':
+				remainingNew--
+			default:
+				return nil, fmt.Errorf("unexpected line in hunk: %q", strings.TrimSuffix(line, "\n"))
+			}
+			current.Lines = append(current.Lines, line)
+			continue
+		}
+		if !strings.HasPrefix(line, "@@ ") {
+			// File headers and other preamble lines are ignored.
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+			return nil, fmt.Errorf("invalid hunk header %q", strings.TrimSuffix(line, "\n"))
+		}
+		oldStart, oldCount, err := parseDiffRange(fields[1][1:])
+		if err != nil {
+			return nil, err
+		}
+		newStart, newCount, err := parseDiffRange(fields[2][1:])
+		if err != nil {
+			return nil, err
+		}
+		hunks = append(hunks, diffHunk{OldStart: oldStart, OldCount: oldCount, NewStart: newStart, NewCount: newCount})
+		current = &hunks[len(hunks)-1]
+		remainingOld, remainingNew = oldCount, newCount
+	}
+
+	if remainingOld > 0 || remainingNew > 0 {
+		return nil, fmt.Errorf("truncated hunk in patch")
+	}
+	return hunks, nil
+}
+
+// diffApply applies a unified diff produced by diffUnified to original.
+func diffApply(original, patch string) (string, error) {
+	hunks, err := parseDiffHunks(patch)
+	if err != nil {
+		return "", err
+	}
+
+	lines := splitDiffLines(original)
+	var out strings.Builder
+	pos := 0
+	for idx, h := range hunks {
+		start := h.OldStart - 1
+		if h.OldCount == 0 {
+			start = h.OldStart
+		}
+		if start < pos || start > len(lines) {
+			return "", fmt.Errorf("hunk %d does not apply: line %d out of range", idx+1, h.OldStart)
+		}
+		for _, l := range lines[pos:start] {
+			out.WriteString(l)
+		}
+		pos = start
+
+		for _, hl := range h.Lines {
+			kind, text := hl[0], hl[1:]
+			switch kind {
+			case ' ', '-':
+				if pos >= len(lines) || lines[pos] != text {
+					return "", fmt.Errorf("hunk %d does not apply at line %d", idx+1, pos+1)
+				}
+				if kind == ' ' {
+					out.WriteString(text)
+				}
+				pos++
+			case '+':
+				out.WriteString(text)
+			}
+		}
+	}
+	for _, l := range lines[pos:] {
+		out.WriteString(l)
+	}
+	return out.String(), nil
+}
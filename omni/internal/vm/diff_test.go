@@ -0,0 +1,79 @@
+package vm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffUnifiedFormat(t *testing.T) {
+	original := "one\ntwo\nthree\nfour\nfive\nsix\nseven\n"
+	modified := "one\n2\nthree\nfour\nfive\nsix\nseven\neight\n"
+
+	got := diffUnified(original, modified, 1)
+	want := "--- original\n+++ modified\n" +
+		"@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n" +
+		"@@ -7,1 +7,2 @@\n seven\n+eight\n"
+	if got != want {
+		t.Errorf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+
+	if diffUnified(original, original, 3) != "" {
+		t.Error("expected empty diff for identical inputs")
+	}
+}
+
+func TestDiffRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		modified string
+	}{
+		{"replace line", "a\nb\nc\n", "a\nB\nc\n"},
+		{"from empty", "", "x\ny\n"},
+		{"to empty", "x\ny\n", ""},
+		{"missing final newline", "a\nb", "a\nb\nc"},
+		{"add final newline", "a\nb", "a\nb\n"},
+		{"distant changes", "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n", "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n"},
+		{"empty lines", "a\n\nb\n", "a\n\n\nb\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, context := range []int{0, 1, 3} {
+				patch := diffUnified(tt.original, tt.modified, context)
+				got, err := diffApply(tt.original, patch)
+				if err != nil {
+					t.Fatalf("context %d: apply failed: %v\npatch:\n%s", context, err, patch)
+				}
+				if got != tt.modified {
+					t.Errorf("context %d: round trip got %q, want %q\npatch:\n%s", context, got, tt.modified, patch)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffMinimalEditScript(t *testing.T) {
+	a := strings.Split("A B C A B B A", " ")
+	b := strings.Split("C B A B A C", " ")
+	edits := 0
+	for _, op := range myersDiff(a, b) {
+		if op.Kind != ' ' {
+			edits++
+		}
+	}
+	// The classic example from Myers' paper has an edit distance of 5.
+	if edits != 5 {
+		t.Errorf("expected 5 edits, got %d", edits)
+	}
+}
+
+func TestDiffApplyRejectsMismatch(t *testing.T) {
+	patch := diffUnified("a\nb\n", "a\nc\n", 1)
+	if _, err := diffApply("x\ny\n", patch); err == nil {
+		t.Error("expected error applying patch to unrelated text")
+	}
+	if _, err := diffApply("a\nb\n", "@@ -1,2 +1,2 @@\n a\n"); err == nil {
+		t.Error("expected error for truncated hunk")
+	}
+}
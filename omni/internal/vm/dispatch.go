@@ -0,0 +1,42 @@
+package vm
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// dispatcher runs instructions through instructionHandlers, timing each one
+// when a profiler is attached.
+type dispatcher struct {
+	profiler atomic.Pointer[vmProfiler]
+	// handlers, when set, replaces instructionHandlers for the running
+	// program, as with the checking handlers of a sandbox
+	handlers atomic.Pointer[map[string]instructionHandler]
+}
+
+var instructions = &dispatcher{}
+
+func (d *dispatcher) exec(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	handlers := instructionHandlers
+	if h := d.handlers.Load(); h != nil {
+		handlers = *h
+	}
+	handler, exists := handlers[inst.Op]
+	if !exists {
+		if !inst.Location.IsZero() {
+			return Result{}, fmt.Errorf("unsupported instruction %q at %s", inst.Op, inst.Location)
+		}
+		return Result{}, fmt.Errorf("unsupported instruction %q", inst.Op)
+	}
+	p := d.profiler.Load()
+	if p == nil {
+		return handler(funcs, fr, inst)
+	}
+	start := time.Now()
+	res, err := handler(funcs, fr, inst)
+	p.record(inst.Op, time.Since(start))
+	return res, err
+}
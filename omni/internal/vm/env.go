@@ -0,0 +1,56 @@
+package vm
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// execEnv handles the std.env intrinsics. It is separate from execIntrinsic
+// because env.require fails when the variable is not set. A variable that is
+// set but empty counts as set, and a value that does not parse gives the
+// default.
+func execEnv(callee string, operands []mir.Operand, fr *frame) (Result, bool, error) {
+	switch callee {
+	case "std.env.get", "std.env.get_int", "std.env.get_bool", "std.env.require":
+	default:
+		return Result{}, false, nil
+	}
+	want := 2
+	if callee == "std.env.require" {
+		want = 1
+	}
+	if len(operands) != want {
+		return Result{}, true, fmt.Errorf("%s: expected %d arguments, got %d", callee[len("std."):], want, len(operands))
+	}
+	name, err := toString(operandValue(fr, operands[0]))
+	if err != nil {
+		return Result{}, true, fmt.Errorf("%s: %w", callee[len("std."):], err)
+	}
+	value, set := os.LookupEnv(name)
+	switch callee {
+	case "std.env.require":
+		if !set {
+			return Result{}, true, fmt.Errorf("env.require: environment variable %q is not set", name)
+		}
+		return Result{Type: "string", Value: value}, true, nil
+	case "std.env.get":
+		if !set {
+			return operandValue(fr, operands[1]), true, nil
+		}
+		return Result{Type: "string", Value: value}, true, nil
+	case "std.env.get_int":
+		// Parsed as 32 bits, the size of an int in the C backend
+		if n, err := strconv.ParseInt(value, 10, 32); set && err == nil {
+			return Result{Type: "int", Value: int(n)}, true, nil
+		}
+		return operandValue(fr, operands[1]), true, nil
+	default:
+		if b, err := strconv.ParseBool(value); set && err == nil {
+			return Result{Type: "bool", Value: b}, true, nil
+		}
+		return operandValue(fr, operands[1]), true, nil
+	}
+}
@@ -0,0 +1,61 @@
+package vm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestEnvGetters(t *testing.T) {
+	t.Setenv("OMNI_TEST_NAME", "ada")
+	t.Setenv("OMNI_TEST_EMPTY", "")
+	t.Setenv("OMNI_TEST_PORT", "8080")
+	t.Setenv("OMNI_TEST_NEGATIVE", "-7")
+	t.Setenv("OMNI_TEST_HUGE", "4294967296")
+	t.Setenv("OMNI_TEST_WORDS", "12 monkeys")
+	t.Setenv("OMNI_TEST_ON", "TRUE")
+	t.Setenv("OMNI_TEST_OFF", "0")
+	t.Setenv("OMNI_TEST_YES", "yes")
+	for expr, want := range map[string]string{
+		`std.env.get("OMNI_TEST_NAME", "x")`:                             "ada",
+		`std.env.get("OMNI_TEST_EMPTY", "x")`:                            "",
+		`std.env.get("OMNI_TEST_UNSET", "x")`:                            "x",
+		`std.int_to_string(std.env.get_int("OMNI_TEST_PORT", 1))`:        "8080",
+		`std.int_to_string(std.env.get_int("OMNI_TEST_NEGATIVE", 1))`:    "-7",
+		`std.int_to_string(std.env.get_int("OMNI_TEST_HUGE", 1))`:        "1",
+		`std.int_to_string(std.env.get_int("OMNI_TEST_WORDS", 1))`:       "1",
+		`std.int_to_string(std.env.get_int("OMNI_TEST_UNSET", 1))`:       "1",
+		`std.bool_to_string(std.env.get_bool("OMNI_TEST_ON", false))`:    "true",
+		`std.bool_to_string(std.env.get_bool("OMNI_TEST_OFF", true))`:    "false",
+		`std.bool_to_string(std.env.get_bool("OMNI_TEST_YES", false))`:   "false",
+		`std.bool_to_string(std.env.get_bool("OMNI_TEST_EMPTY", true))`:  "true",
+		`std.bool_to_string(std.env.get_bool("OMNI_TEST_UNSET", false))`: "false",
+		`std.env.require("OMNI_TEST_NAME")`:                              "ada",
+	} {
+		src := `func main():string {
+  return ` + expr + `
+}
+`
+		res, err := vm.Execute(buildSource(t, src), "main")
+		if err != nil {
+			t.Errorf("%s: execute: %v", expr, err)
+			continue
+		}
+		if res.Value != want {
+			t.Errorf("%s = %q, want %q", expr, res.Value, want)
+		}
+	}
+}
+
+func TestEnvRequireUnset(t *testing.T) {
+	src := `func main():string {
+  return std.env.require("OMNI_TEST_UNSET")
+}
+`
+	_, err := vm.Execute(buildSource(t, src), "main")
+	want := `env.require: environment variable "OMNI_TEST_UNSET" is not set`
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("expected error %q, got %v", want, err)
+	}
+}
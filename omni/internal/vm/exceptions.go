@@ -0,0 +1,86 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// ThrownError is an exception that a throw statement raised and no try
+// statement caught.
+type ThrownError struct {
+	Value Result
+}
+
+func (e ThrownError) Error() string {
+	return fmt.Sprintf("uncaught exception: %v", e.Value.Value)
+}
+
+// tryHandler is a handler that try.enter pushed: the exception types it
+// catches, each with the block that catches it.
+type tryHandler struct {
+	types  []string
+	blocks []string
+}
+
+// execTryEnter handles try.enter, which pushes a handler for the
+// exceptions thrown until the matching try.exit.
+func execTryEnter(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	if len(inst.Operands)%2 != 0 {
+		return Result{}, fmt.Errorf("try.enter expects type and block pairs, got %d operands", len(inst.Operands))
+	}
+	var h tryHandler
+	for i := 0; i < len(inst.Operands); i += 2 {
+		h.types = append(h.types, inst.Operands[i].Literal)
+		h.blocks = append(h.blocks, inst.Operands[i+1].Literal)
+	}
+	fr.handlers = append(fr.handlers, h)
+	return Result{Type: "void"}, nil
+}
+
+// execTryExit handles try.exit, which pops the innermost handler.
+func execTryExit(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	if len(fr.handlers) == 0 {
+		return Result{}, fmt.Errorf("try.exit without a matching try.enter")
+	}
+	fr.handlers = fr.handlers[:len(fr.handlers)-1]
+	return Result{Type: "void"}, nil
+}
+
+// execCaughtException handles catch.begin and finally.begin, which start
+// the blocks a handler sends an exception to and produce the exception.
+func execCaughtException(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	return fr.exception, nil
+}
+
+// execThrow handles throw instructions, which raise their operand as an
+// exception for the innermost handler that catches its type, in this
+// function or one of its callers.
+func execThrow(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	if len(inst.Operands) == 0 {
+		return Result{}, fmt.Errorf("throw requires an operand")
+	}
+	return Result{}, ThrownError{Value: operandValue(fr, inst.Operands[0])}
+}
+
+// catch returns the block that handles err, if it is a thrown exception
+// that a handler of fr catches, and makes the exception the one the block
+// reads. The handlers that did not catch it are popped.
+func (fr *frame) catch(err error) (string, bool) {
+	var thrown ThrownError
+	if len(fr.handlers) == 0 || !errors.As(err, &thrown) {
+		return "", false
+	}
+	for len(fr.handlers) > 0 {
+		h := fr.handlers[len(fr.handlers)-1]
+		fr.handlers = fr.handlers[:len(fr.handlers)-1]
+		for i, typ := range h.types {
+			if typ == mir.CatchAny || typ == thrown.Value.Type {
+				fr.exception = thrown.Value
+				return h.blocks[i], true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,180 @@
+package vm_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestNestedTryCatch(t *testing.T) {
+	// The inner handler only catches ints, so the string thrown in the
+	// callee reaches the outer one after the inner finally block has run
+	src := `func risky(n:int):int {
+  if n > 2 {
+    throw "too big"
+  }
+  return n * 10
+}
+func main():int {
+  var total:int = 0
+  try {
+    total = total + risky(1)
+    try {
+      total = total + risky(5)
+    } catch (e: int) {
+      total = total + 1000
+    } finally {
+      total = total + 100
+    }
+    total = total + 7
+  } catch (e: string) {
+    if e == "too big" {
+      total = total + 1
+    }
+  } finally {
+    total = total + 2000
+  }
+  return total
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 2111 {
+		t.Errorf("result = %v, want 2111", res.Value)
+	}
+}
+
+func TestDeferRunsWhenExceptionLeaves(t *testing.T) {
+	// h and g make their deferred calls as the exception leaves them for
+	// main's handler; k catches its own exception, so its deferred call
+	// waits for its return
+	src := `var trace:int = 0
+func mark(step:int):void {
+  trace = trace * 10 + step
+}
+func k():int {
+  try {
+    defer mark(1)
+    throw "caught here"
+  } catch (e: string) {
+    mark(2)
+  }
+  return 0
+}
+func h():int {
+  defer mark(5)
+  throw "boom"
+}
+func g():int {
+  defer mark(6)
+  try {
+    return h()
+  } catch (e: int) {
+    return 0
+  }
+}
+func main():int {
+  k()
+  try {
+    g()
+  } catch (e: string) {
+    mark(7)
+  }
+  return trace
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 21567 {
+		t.Errorf("trace = %v, want 21567", res.Value)
+	}
+}
+
+func TestRethrowFromCatch(t *testing.T) {
+	src := `func main():int {
+  var steps:int = 0
+  try {
+    try {
+      throw 5
+    } catch (e: int) {
+      steps = steps + e
+      throw "again"
+    } finally {
+      steps = steps + 10
+    }
+  } catch (e: string) {
+    steps = steps + 100
+  }
+  return steps
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 115 {
+		t.Errorf("result = %v, want 115", res.Value)
+	}
+}
+
+func TestReturnInTryRunsFinally(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.txt")
+	src := `import std
+func pick(n:int):int {
+  try {
+    if n > 0 {
+      return n
+    }
+  } finally {
+    std.os.append_file(` + strconv.Quote(path) + `, "f")
+  }
+  return 0
+}
+func main():int {
+  return pick(3) + pick(0)
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 3 {
+		t.Errorf("result = %v, want 3", res.Value)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	// The finally block runs for the return and for falling off the end
+	if string(data) != "ff" {
+		t.Errorf("trace = %q, want ff", data)
+	}
+}
+
+func TestUncaughtException(t *testing.T) {
+	src := `func main():int {
+  try {
+    throw "boom"
+  } catch (e: int) {
+    return 1
+  }
+  return 0
+}
+`
+	_, err := vm.Execute(buildSource(t, src), "main")
+	var thrown vm.ThrownError
+	if !errors.As(err, &thrown) {
+		t.Fatalf("expected a vm.ThrownError, got %v", err)
+	}
+	if thrown.Value.Value != "boom" {
+		t.Errorf("thrown value = %v, want boom", thrown.Value.Value)
+	}
+}
@@ -0,0 +1,68 @@
+package vm
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// globalStore holds the values of the global variables of the running
+// program, which outlive the frames of the functions that use them.
+type globalStore struct {
+	mu     sync.RWMutex
+	values map[string]Result
+}
+
+var globals = &globalStore{values: make(map[string]Result)}
+
+// reset gives every global of mod its initial value.
+func (g *globalStore) reset(mod *mir.Module) error {
+	values := make(map[string]Result, len(mod.Globals))
+	for _, global := range mod.Globals {
+		init := global.InitValue
+		init.Type = global.Type
+		value, err := literalResult(init)
+		if err != nil {
+			return fmt.Errorf("vm: global %s: %w", global.Name, err)
+		}
+		values[global.Name] = value
+	}
+	g.mu.Lock()
+	g.values = values
+	g.mu.Unlock()
+	return nil
+}
+
+// execGlobalLoad handles global.load, which reads the global named by its
+// operand.
+func execGlobalLoad(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	if len(inst.Operands) != 1 {
+		return Result{}, fmt.Errorf("global.load: expected 1 operand, got %d", len(inst.Operands))
+	}
+	name := inst.Operands[0].Literal
+	globals.mu.RLock()
+	value, ok := globals.values[name]
+	globals.mu.RUnlock()
+	if !ok {
+		return Result{}, fmt.Errorf("global.load: undefined global %q", name)
+	}
+	return value, nil
+}
+
+// execGlobalStore handles global.store, whose operands are the name of the
+// global and the value it now holds.
+func execGlobalStore(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	if len(inst.Operands) != 2 {
+		return Result{}, fmt.Errorf("global.store: expected 2 operands, got %d", len(inst.Operands))
+	}
+	name := inst.Operands[0].Literal
+	value := operandValue(fr, inst.Operands[1])
+	globals.mu.Lock()
+	defer globals.mu.Unlock()
+	if _, ok := globals.values[name]; !ok {
+		return Result{}, fmt.Errorf("global.store: undefined global %q", name)
+	}
+	globals.values[name] = value
+	return Result{Type: "void"}, nil
+}
@@ -0,0 +1,39 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestGlobalsPersistAcrossCalls(t *testing.T) {
+	src := `var count:int = 0
+let step:int = 5
+var last:string = "none"
+func bump(tag:string):int {
+  count = count + step
+  count++
+  last = tag
+  return count
+}
+func main():int {
+  bump("a")
+  bump("b")
+  if last == "b" {
+    return bump("c")
+  }
+  return -1
+}
+`
+	mod := buildSource(t, src)
+	for run := 0; run < 2; run++ {
+		// Every run starts from the initial values
+		res, err := vm.Execute(mod, "main")
+		if err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+		if res.Value != 18 {
+			t.Errorf("run %d: result = %v, want 18", run, res.Value)
+		}
+	}
+}
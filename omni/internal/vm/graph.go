@@ -0,0 +1,190 @@
+package vm
+
+import "sort"
+
+// graphEdge is a single weighted, directed edge of a std.collections.graph
+// Graph value.
+type graphEdge struct {
+	From   int
+	To     int
+	Weight int
+}
+
+// graphValue is the VM representation of a Graph. It is stored behind the
+// "edges" field of the struct map so that g.nodes still reads as an int.
+type graphValue struct {
+	Nodes int
+	Edges []graphEdge
+}
+
+func newGraphResult(g *graphValue) Result {
+	return Result{Type: "Graph", Value: map[string]interface{}{
+		"nodes": g.Nodes,
+		"edges": g,
+	}}
+}
+
+// graphFromValue extracts the graph stored in a Graph struct value.
+func graphFromValue(v interface{}) (*graphValue, bool) {
+	fields, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	g, ok := fields["edges"].(*graphValue)
+	return g, ok
+}
+
+func (g *graphValue) addEdge(from, to, weight int) bool {
+	if from < 0 || from >= g.Nodes || to < 0 || to >= g.Nodes {
+		return false
+	}
+	g.Edges = append(g.Edges, graphEdge{From: from, To: to, Weight: weight})
+	return true
+}
+
+func (g *graphValue) hasEdge(from, to int) bool {
+	for _, e := range g.Edges {
+		if e.From == from && e.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *graphValue) totalWeight() int {
+	total := 0
+	for _, e := range g.Edges {
+		total += e.Weight
+	}
+	return total
+}
+
+// graphMST returns the minimum spanning forest of g using Kruskal's
+// algorithm. Edges are treated as undirected; ties are broken by insertion
+// order so the result is deterministic.
+func graphMST(g *graphValue) *graphValue {
+	edges := append([]graphEdge(nil), g.Edges...)
+	sort.SliceStable(edges, func(i, j int) bool { return edges[i].Weight < edges[j].Weight })
+
+	parent := make([]int, g.Nodes)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+
+	tree := &graphValue{Nodes: g.Nodes}
+	for _, e := range edges {
+		a, b := find(e.From), find(e.To)
+		if a == b {
+			continue
+		}
+		parent[a] = b
+		tree.Edges = append(tree.Edges, e)
+		if len(tree.Edges) == g.Nodes-1 {
+			break
+		}
+	}
+	return tree
+}
+
+// graphTopologicalSort orders the nodes of g so every edge points forward,
+// using Kahn's algorithm with the lowest-numbered ready node first. It
+// returns false if g has a cycle.
+func graphTopologicalSort(g *graphValue) ([]int, bool) {
+	indegree := make([]int, g.Nodes)
+	adj := make([][]int, g.Nodes)
+	for _, e := range g.Edges {
+		adj[e.From] = append(adj[e.From], e.To)
+		indegree[e.To]++
+	}
+
+	var ready []int
+	for n := 0; n < g.Nodes; n++ {
+		if indegree[n] == 0 {
+			ready = append(ready, n)
+		}
+	}
+	order := make([]int, 0, g.Nodes)
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		n := ready[0]
+		ready = ready[1:]
+		order = append(order, n)
+		for _, m := range adj[n] {
+			indegree[m]--
+			if indegree[m] == 0 {
+				ready = append(ready, m)
+			}
+		}
+	}
+	if len(order) != g.Nodes {
+		return nil, false
+	}
+	return order, true
+}
+
+// graphSCC returns the strongly connected components of g using Tarjan's
+// algorithm. Components are listed in the order Tarjan completes them
+// (reverse topological order) and each is sorted ascending.
+func graphSCC(g *graphValue) [][]int {
+	adj := make([][]int, g.Nodes)
+	for _, e := range g.Edges {
+		adj[e.From] = append(adj[e.From], e.To)
+	}
+
+	index := make([]int, g.Nodes)
+	lowlink := make([]int, g.Nodes)
+	onStack := make([]bool, g.Nodes)
+	for i := range index {
+		index[i] = -1
+	}
+	var (
+		stack      []int
+		components [][]int
+		next       int
+	)
+	var connect func(int)
+	connect = func(v int) {
+		index[v] = next
+		lowlink[v] = next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range adj[v] {
+			if index[w] < 0 {
+				connect(w)
+				lowlink[v] = min(lowlink[v], lowlink[w])
+			} else if onStack[w] {
+				lowlink[v] = min(lowlink[v], index[w])
+			}
+		}
+
+		if lowlink[v] == index[v] {
+			var component []int
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			sort.Ints(component)
+			components = append(components, component)
+		}
+	}
+	for v := 0; v < g.Nodes; v++ {
+		if index[v] < 0 {
+			connect(v)
+		}
+	}
+	return components
+}
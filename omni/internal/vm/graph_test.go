@@ -0,0 +1,60 @@
+package vm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGraphMST(t *testing.T) {
+	g := &graphValue{Nodes: 4}
+	for _, e := range []graphEdge{{0, 1, 1}, {1, 2, 2}, {2, 3, 1}, {0, 3, 4}, {0, 2, 3}} {
+		if !g.addEdge(e.From, e.To, e.Weight) {
+			t.Fatalf("addEdge(%v) rejected a valid edge", e)
+		}
+	}
+	if g.addEdge(0, 4, 1) || g.addEdge(-1, 0, 1) {
+		t.Error("addEdge accepted an out-of-range node")
+	}
+
+	tree := graphMST(g)
+	want := []graphEdge{{0, 1, 1}, {2, 3, 1}, {1, 2, 2}}
+	if !reflect.DeepEqual(tree.Edges, want) {
+		t.Errorf("mst edges = %v, want %v", tree.Edges, want)
+	}
+	if tree.totalWeight() != 4 || tree.Nodes != 4 {
+		t.Errorf("mst weight = %d over %d nodes, want 4 over 4", tree.totalWeight(), tree.Nodes)
+	}
+
+	forest := graphMST(&graphValue{Nodes: 4, Edges: []graphEdge{{0, 1, 5}, {2, 3, 7}}})
+	if len(forest.Edges) != 2 {
+		t.Errorf("expected a 2-edge spanning forest, got %v", forest.Edges)
+	}
+}
+
+func TestGraphTopologicalSort(t *testing.T) {
+	g := &graphValue{Nodes: 5, Edges: []graphEdge{{3, 1, 0}, {1, 0, 0}, {4, 0, 0}, {2, 4, 0}}}
+	order, ok := graphTopologicalSort(g)
+	if !ok {
+		t.Fatal("expected an acyclic graph to sort")
+	}
+	if want := []int{2, 3, 1, 4, 0}; !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+
+	g.addEdge(0, 2, 0)
+	if _, ok := graphTopologicalSort(g); ok {
+		t.Error("expected a cycle to be reported")
+	}
+}
+
+func TestGraphSCC(t *testing.T) {
+	g := &graphValue{Nodes: 6, Edges: []graphEdge{
+		{0, 1, 0}, {1, 2, 0}, {2, 0, 0},
+		{2, 3, 0}, {3, 4, 0}, {4, 3, 0},
+	}}
+	got := graphSCC(g)
+	want := [][]int{{3, 4}, {0, 1, 2}, {5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scc = %v, want %v", got, want)
+	}
+}
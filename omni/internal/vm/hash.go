@@ -0,0 +1,92 @@
+package vm
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/fnv"
+	"math/bits"
+)
+
+// hashDigest returns the lowercase hex digest of s for the named std.hash
+// algorithm, or false if the algorithm does not produce a digest string.
+func hashDigest(algorithm, s string) (string, bool) {
+	switch algorithm {
+	case "sha256":
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:]), true
+	case "sha512":
+		sum := sha512.Sum512([]byte(s))
+		return hex.EncodeToString(sum[:]), true
+	case "md5":
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:]), true
+	}
+	return "", false
+}
+
+// hashFNV32 returns the 32-bit FNV-1a hash of s. The result is reinterpreted
+// as a signed 32-bit value so it matches the C backend's int.
+func hashFNV32(s string) int {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return int(int32(h.Sum32()))
+}
+
+// hashFNV64 returns the 64-bit FNV-1a hash of s as a signed 64-bit value.
+func hashFNV64(s string) int {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return int(int64(h.Sum64()))
+}
+
+// hashMurmur3 returns the 32-bit MurmurHash3 (x86_32, seed 0) of s,
+// reinterpreted as a signed 32-bit value.
+func hashMurmur3(s string) int {
+	return int(int32(murmur3Sum32([]byte(s), 0)))
+}
+
+func murmur3Sum32(data []byte, seed uint32) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+	h := seed
+	nblocks := len(data) / 4
+	for i := 0; i < nblocks; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	tail := data[nblocks*4:]
+	var k uint32
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
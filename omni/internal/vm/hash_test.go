@@ -0,0 +1,46 @@
+package vm
+
+import "testing"
+
+func TestHashKnownAnswers(t *testing.T) {
+	digests := []struct {
+		algorithm string
+		input     string
+		want      string
+	}{
+		{"sha256", "hello world", "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"},
+		{"sha256", "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"sha512", "hello world", "309ecc489c12d6eb4cc40f50c902f2b4d0ed77ee511a7c7a9bcd3ca86d4cd86f989dd35bc5ff499670da34255b45b0cfd830e81f605dcf7dc5542e93ae9cd76f"},
+		{"sha512", "", "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e"},
+		{"md5", "hello world", "5eb63bbbe01eeed093cb22bb8f5acdc3"},
+		{"md5", "", "d41d8cd98f00b204e9800998ecf8427e"},
+	}
+	for _, tt := range digests {
+		got, ok := hashDigest(tt.algorithm, tt.input)
+		if !ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v; want %q", tt.algorithm, tt.input, got, ok, tt.want)
+		}
+	}
+	if _, ok := hashDigest("crc32", "x"); ok {
+		t.Error("expected unknown algorithm to be rejected")
+	}
+
+	ints := []struct {
+		name string
+		fn   func(string) int
+		in   string
+		want int
+	}{
+		{"fnv32", hashFNV32, "hello world", -712294489},
+		{"fnv32", hashFNV32, "", -2128831035},
+		{"fnv64", hashFNV64, "hello world", 8618312879776256743},
+		{"fnv64", hashFNV64, "", -3750763034362895579},
+		{"murmur3", hashMurmur3, "hello world", 1586663183},
+		{"murmur3", hashMurmur3, "", 0},
+	}
+	for _, tt := range ints {
+		if got := tt.fn(tt.in); got != tt.want {
+			t.Errorf("%s(%q) = %d, want %d", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package vm
+
+import (
+	"reflect"
+	"sync"
+)
+
+// DefaultGCThreshold is the number of live heap objects above which the VM
+// first collects garbage.
+const DefaultGCThreshold = 10000
+
+// HeapObject is an object the VM allocates for the program, such as an
+// array, a map or a struct.
+type HeapObject interface {
+	// Value is the Go value of the object, as Result.Value holds it.
+	Value() interface{}
+	// Trace calls mark with every value the object holds.
+	Trace(mark func(value interface{}))
+}
+
+// Heap is the registry of the heap objects of the running program, keyed
+// by the address of their storage: a Result whose Value is a map or a
+// non-empty slice refers to the object registered at that address.
+//
+// The objects are Go values, which Go's collector frees once nothing refers
+// to them, but the registry refers to every object it holds. A collection
+// marks the objects reachable from the values of the running and suspended
+// functions and from the globals, and drops the rest from the registry, so
+// that it only keeps live objects alive. It runs when a function returns
+// while more objects are registered than the threshold.
+type Heap struct {
+	mu      sync.Mutex
+	objects map[uintptr]HeapObject
+	// frames are the frames of the functions that have started and not
+	// yet returned, the roots of a collection
+	frames    map[*frame]struct{}
+	threshold int
+	// next is the number of objects above which the next collection runs;
+	// it grows with the live data so collections stay proportional to the
+	// allocation
+	next int
+}
+
+var heap = newHeap(DefaultGCThreshold)
+
+func newHeap(threshold int) *Heap {
+	return &Heap{
+		objects:   make(map[uintptr]HeapObject),
+		frames:    make(map[*frame]struct{}),
+		threshold: threshold,
+		next:      threshold,
+	}
+}
+
+// SetGCThreshold sets the number of live heap objects above which the VM
+// collects garbage. A threshold below 1 restores DefaultGCThreshold.
+func SetGCThreshold(n int) {
+	if n < 1 {
+		n = DefaultGCThreshold
+	}
+	heap.mu.Lock()
+	defer heap.mu.Unlock()
+	heap.threshold = n
+	heap.next = n
+}
+
+// LiveObjects returns the number of heap objects registered, those that
+// were reachable at the last collection and those allocated since.
+func LiveObjects() int {
+	heap.mu.Lock()
+	defer heap.mu.Unlock()
+	return len(heap.objects)
+}
+
+// reset empties the heap for a new program run.
+func (h *Heap) reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.objects = make(map[uintptr]HeapObject)
+	h.frames = make(map[*frame]struct{})
+	h.next = h.threshold
+}
+
+// enter adds the frame of a function that starts to the roots.
+func (h *Heap) enter(fr *frame) {
+	h.mu.Lock()
+	h.frames[fr] = struct{}{}
+	h.mu.Unlock()
+}
+
+// leave removes the frame of a function that returned from the roots.
+func (h *Heap) leave(fr *frame) {
+	h.mu.Lock()
+	delete(h.frames, fr)
+	h.mu.Unlock()
+}
+
+// track registers value, if it is a heap object.
+func (h *Heap) track(value interface{}) {
+	addr, ok := heapAddr(value)
+	if !ok {
+		return
+	}
+	h.mu.Lock()
+	h.objects[addr] = heapValue{value}
+	h.mu.Unlock()
+}
+
+// maybeCollect collects garbage if more objects are registered than the
+// threshold allows. live is the result of the function that just returned,
+// which no frame holds yet.
+func (h *Heap) maybeCollect(live Result) {
+	h.mu.Lock()
+	due := len(h.objects) > h.next
+	h.mu.Unlock()
+	if due {
+		h.collect(live)
+	}
+}
+
+// collect drops the objects unreachable from the roots and extra from the
+// registry and returns how many it dropped.
+func (h *Heap) collect(extra ...Result) int {
+	globals.mu.RLock()
+	defer globals.mu.RUnlock()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	marked := make(map[uintptr]bool, len(h.objects))
+	var pending []interface{}
+	mark := func(value interface{}) {
+		if res, ok := value.(Result); ok {
+			value = res.Value
+		}
+		if addr, ok := heapAddr(value); ok && !marked[addr] {
+			marked[addr] = true
+			pending = append(pending, value)
+		}
+	}
+	for fr := range h.frames {
+		for _, value := range fr.values {
+			mark(value.Value)
+		}
+		mark(fr.exception.Value)
+	}
+	for _, value := range globals.values {
+		mark(value.Value)
+	}
+	for _, value := range extra {
+		mark(value.Value)
+	}
+	for len(pending) > 0 {
+		value := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		traceValue(value, mark)
+	}
+
+	freed := 0
+	for addr := range h.objects {
+		if !marked[addr] {
+			delete(h.objects, addr)
+			freed++
+		}
+	}
+	h.next = max(h.threshold, 2*len(h.objects))
+	return freed
+}
+
+// heapValue is a heap object allocated by an instruction, such as
+// struct.init.
+type heapValue struct {
+	value interface{}
+}
+
+func (v heapValue) Value() interface{} { return v.value }
+
+func (v heapValue) Trace(mark func(value interface{})) { traceValue(v.value, mark) }
+
+// heapAddr returns the address of the storage of value if it is a heap
+// object: a map or a slice with elements.
+func heapAddr(value interface{}) (uintptr, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map:
+		return rv.Pointer(), !rv.IsNil()
+	case reflect.Slice:
+		return rv.Pointer(), rv.Cap() > 0 && rv.Pointer() != 0
+	}
+	return 0, false
+}
+
+// traceValue calls mark with the elements of the map or slice value that
+// may refer to other heap objects.
+func traceValue(value interface{}, mark func(value interface{})) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map:
+		if !holdsReferences(rv.Type().Elem()) {
+			return
+		}
+		iter := rv.MapRange()
+		for iter.Next() {
+			mark(iter.Value().Interface())
+		}
+	case reflect.Slice:
+		if !holdsReferences(rv.Type().Elem()) {
+			return
+		}
+		for i := 0; i < rv.Len(); i++ {
+			mark(rv.Index(i).Interface())
+		}
+	}
+}
+
+// holdsReferences reports whether values of type t may be heap objects or
+// hold them.
+func holdsReferences(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Map, reflect.Slice, reflect.Struct:
+		return true
+	}
+	return false
+}
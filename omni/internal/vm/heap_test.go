@@ -0,0 +1,63 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+const allocatingProgram = `struct Point {
+  x:int
+  y:int
+}
+
+func make_point(i:int):Point {
+  return Point{x: i, y: i + 1}
+}
+
+func keep(points:array<Point>):int {
+  return len(points)
+}
+
+func main():int {
+  let kept:array<Point> = [make_point(1), make_point(2)]
+  var total:int = 0
+  var i:int = 0
+  while i < 5000 {
+    let p:Point = make_point(i)
+    total = total + p.y - p.x
+    i = i + 1
+  }
+  return total + keep(kept)
+}
+`
+
+func TestHeapCollectsUnreachableStructs(t *testing.T) {
+	mod := buildSource(t, allocatingProgram)
+	vm.SetGCThreshold(100)
+	defer vm.SetGCThreshold(0)
+
+	res, err := vm.Execute(mod, "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 5002 {
+		t.Fatalf("result = %v, want 5002", res.Value)
+	}
+	if live := vm.LiveObjects(); live > 200 {
+		t.Errorf("%d heap objects live after allocating 5000 structs, want at most 200", live)
+	}
+}
+
+func TestHeapKeepsObjectsBelowThreshold(t *testing.T) {
+	mod := buildSource(t, allocatingProgram)
+	vm.SetGCThreshold(1000000)
+	defer vm.SetGCThreshold(0)
+
+	if _, err := vm.Execute(mod, "main"); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if live := vm.LiveObjects(); live < 5000 {
+		t.Errorf("%d heap objects live, want every allocation kept below the threshold", live)
+	}
+}
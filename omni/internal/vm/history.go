@@ -0,0 +1,128 @@
+package vm
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DefaultHistoryMaxEntries is the number of entries kept by
+// std.io.readline_history unless changed with set_max_entries.
+const DefaultHistoryMaxEntries = 1000
+
+// History is a bounded, line-oriented command history. The on-disk format is
+// one UTF-8 command per line, oldest first, so files written by one session
+// can be loaded by the next.
+type History struct {
+	mu         sync.Mutex
+	entries    []string
+	maxEntries int
+}
+
+// NewHistory returns an empty history holding at most maxEntries entries. A
+// non-positive maxEntries selects DefaultHistoryMaxEntries.
+func NewHistory(maxEntries int) *History {
+	h := &History{}
+	h.SetMaxEntries(maxEntries)
+	return h
+}
+
+// processHistory backs the std.io.readline_history intrinsics.
+var processHistory = NewHistory(DefaultHistoryMaxEntries)
+
+// SetMaxEntries changes the entry limit, dropping the oldest entries if the
+// history is already longer.
+func (h *History) SetMaxEntries(maxEntries int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if maxEntries <= 0 {
+		maxEntries = DefaultHistoryMaxEntries
+	}
+	h.maxEntries = maxEntries
+	h.trim()
+}
+
+// Add appends line to the history. Blank lines are ignored and embedded
+// newlines are replaced by spaces to keep one command per line.
+func (h *History) Add(line string) {
+	line = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(line)
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, line)
+	h.trim()
+}
+
+// Get returns the entry at index, where 0 is the oldest entry.
+func (h *History) Get(index int) (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if index < 0 || index >= len(h.entries) {
+		return "", false
+	}
+	return h.entries[index], true
+}
+
+// Len returns the number of entries.
+func (h *History) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.entries)
+}
+
+// Load replaces the history with the contents of path. A missing file leaves
+// the history empty and is not an error.
+func (h *History) Load(path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		h.mu.Lock()
+		h.entries = nil
+		h.mu.Unlock()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSuffix(scanner.Text(), "\r"); strings.TrimSpace(line) != "" {
+			entries = append(entries, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = entries
+	h.trim()
+	return nil
+}
+
+// Save writes the history to path, replacing any existing file.
+func (h *History) Save(path string) error {
+	h.mu.Lock()
+	var b strings.Builder
+	for _, entry := range h.entries {
+		b.WriteString(entry)
+		b.WriteByte('\n')
+	}
+	h.mu.Unlock()
+	return os.WriteFile(path, []byte(b.String()), 0o600)
+}
+
+// trim drops the oldest entries beyond the limit. Callers must hold h.mu.
+func (h *History) trim() {
+	if extra := len(h.entries) - h.maxEntries; extra > 0 {
+		h.entries = append([]string(nil), h.entries[extra:]...)
+	}
+}
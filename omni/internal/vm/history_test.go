@@ -0,0 +1,67 @@
+package vm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHistoryPersistsAcrossSessions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".omni_history")
+
+	first := NewHistory(0)
+	if err := first.Load(path); err != nil {
+		t.Fatalf("loading a missing file should not fail: %v", err)
+	}
+	for _, line := range []string{"let x:int = 1", "  ", "println(\"héllo\")", "two\nlines"} {
+		first.Add(line)
+	}
+	if err := first.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	second := NewHistory(0)
+	second.Add("stale")
+	if err := second.Load(path); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var got []string
+	for i := 0; i < second.Len(); i++ {
+		entry, _ := second.Get(i)
+		got = append(got, entry)
+	}
+	want := []string{"let x:int = 1", "println(\"héllo\")", "two lines"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded history = %q, want %q", got, want)
+	}
+	if _, ok := second.Get(len(want)); ok {
+		t.Error("Get past the end should report no entry")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read history file: %v", err)
+	}
+	if string(data) != "let x:int = 1\nprintln(\"héllo\")\ntwo lines\n" {
+		t.Errorf("history file = %q", data)
+	}
+}
+
+func TestHistoryMaxEntries(t *testing.T) {
+	h := NewHistory(0)
+	for i := 0; i < DefaultHistoryMaxEntries+5; i++ {
+		h.Add(string(rune('a' + i%26)))
+	}
+	if h.Len() != DefaultHistoryMaxEntries {
+		t.Fatalf("Len() = %d, want %d", h.Len(), DefaultHistoryMaxEntries)
+	}
+	if oldest, _ := h.Get(0); oldest != "f" {
+		t.Errorf("oldest entry = %q, want the first five dropped", oldest)
+	}
+
+	h.SetMaxEntries(2)
+	if h.Len() != 2 {
+		t.Errorf("Len() after SetMaxEntries(2) = %d", h.Len())
+	}
+}
@@ -0,0 +1,55 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// execInterfaceInit handles interface.init, which makes an interface value
+// of a struct: the struct with its type name and a vtable mapping each
+// method of the interface to the function that implements it.
+func execInterfaceInit(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	if len(inst.Operands) < 2 {
+		return Result{}, fmt.Errorf("interface.init: expected a value and its type, got %d operands", len(inst.Operands))
+	}
+	vtable := make(map[string]*mir.Function, len(inst.Operands)-2)
+	for _, impl := range inst.Operands[2:] {
+		fn, ok := funcs[impl.Literal]
+		if !ok {
+			return Result{}, fmt.Errorf("interface.init: function %q not found", impl.Literal)
+		}
+		// Methods are named Type.method after the struct they belong to
+		vtable[impl.Literal[strings.LastIndex(impl.Literal, ".")+1:]] = fn
+	}
+	return Result{Type: inst.Type, Value: map[string]interface{}{
+		"type":   inst.Operands[1].Literal,
+		"value":  operandValue(fr, inst.Operands[0]).Value,
+		"vtable": vtable,
+	}}, nil
+}
+
+// execInterfaceCall handles interface.call, which calls a method of an
+// interface value through its vtable with the struct as the receiver.
+func execInterfaceCall(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	if len(inst.Operands) < 2 {
+		return Result{}, fmt.Errorf("interface.call: expected a value and a method, got %d operands", len(inst.Operands))
+	}
+	value, ok := operandValue(fr, inst.Operands[0]).Value.(map[string]interface{})
+	if !ok {
+		return Result{}, fmt.Errorf("interface.call: operand is not an interface value")
+	}
+	vtable, _ := value["vtable"].(map[string]*mir.Function)
+	name := inst.Operands[1].Literal
+	fn, ok := vtable[name]
+	if !ok {
+		return Result{}, fmt.Errorf("interface.call: %v has no method %s", value["type"], name)
+	}
+	typeName, _ := value["type"].(string)
+	args := []Result{{Type: typeName, Value: value["value"]}}
+	for _, op := range inst.Operands[2:] {
+		args = append(args, operandValue(fr, op))
+	}
+	return execFunction(funcs, fn, args)
+}
@@ -0,0 +1,49 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestInterfaceDispatch(t *testing.T) {
+	src := `interface Shape {
+  area(self):int
+  scaled(self, k:int):int
+}
+struct Rect {
+  w:int
+  h:int
+}
+struct Square {
+  side:int
+}
+func (r Rect) area():int {
+  return r.w * r.h
+}
+func (r Rect) scaled(k:int):int {
+  return r.area() * k
+}
+func (s Square) area():int {
+  return s.side * s.side
+}
+func (s Square) scaled(k:int):int {
+  return s.area() * k * k
+}
+func measure(s:Shape):int {
+  return s.area() + s.scaled(2)
+}
+func main():int {
+  let sq:Shape = Square{side: 3}
+  return measure(Rect{w: 2, h: 5}) + measure(sq)
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	// Rect: 10 + 20, Square: 9 + 36
+	if res.Value != 75 {
+		t.Errorf("result = %v, want 75", res.Value)
+	}
+}
@@ -0,0 +1,129 @@
+package vm
+
+import "sort"
+
+// splineValue holds the piecewise coefficients of a natural cubic spline.
+// On [X[i], X[i+1]] the spline is A[i] + B[i]*t + C[i]*t^2 + D[i]*t^3 with
+// t = x - X[i]. It is stored behind the "coeffs" field of the Spline struct
+// map so that s.points still reads as an int.
+type splineValue struct {
+	X, A, B, C, D []float64
+}
+
+func newSplineResult(s *splineValue) Result {
+	return Result{Type: "Spline", Value: map[string]interface{}{
+		"points": len(s.X),
+		"coeffs": s,
+	}}
+}
+
+// splineFromValue extracts the spline stored in a Spline struct value.
+func splineFromValue(v interface{}) (*splineValue, bool) {
+	fields, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	s, ok := fields["coeffs"].(*splineValue)
+	return s, ok
+}
+
+func interpLinear(x0, y0, x1, y1, x float64) float64 {
+	if x1 == x0 {
+		return y0
+	}
+	return y0 + (y1-y0)*(x-x0)/(x1-x0)
+}
+
+func interpLerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// interpSmoothStep is the Hermite smooth step 3t^2 - 2t^3 with t clamped to
+// [0, 1].
+func interpSmoothStep(t float64) float64 {
+	switch {
+	case t <= 0:
+		return 0
+	case t >= 1:
+		return 1
+	}
+	return t * t * (3 - 2*t)
+}
+
+// interpCubicSpline fits a natural cubic spline (zero second derivative at
+// both ends) through the points (xs[i], ys[i]), using the shorter of the two
+// slices. xs must be strictly increasing; otherwise the spline is empty.
+func interpCubicSpline(xs, ys []float64) *splineValue {
+	n := min(len(xs), len(ys))
+	for i := 1; i < n; i++ {
+		if xs[i] <= xs[i-1] {
+			return &splineValue{}
+		}
+	}
+	s := &splineValue{
+		X: append([]float64(nil), xs[:n]...),
+		A: append([]float64(nil), ys[:n]...),
+		B: make([]float64, n),
+		C: make([]float64, n),
+		D: make([]float64, n),
+	}
+	if n < 2 {
+		return s
+	}
+
+	// Solve the tridiagonal system for C with the Thomas algorithm.
+	h := make([]float64, n-1)
+	for i := range h {
+		h[i] = s.X[i+1] - s.X[i]
+	}
+	mu := make([]float64, n)
+	z := make([]float64, n)
+	for i := 1; i < n-1; i++ {
+		alpha := 3/h[i]*(s.A[i+1]-s.A[i]) - 3/h[i-1]*(s.A[i]-s.A[i-1])
+		l := 2*(s.X[i+1]-s.X[i-1]) - h[i-1]*mu[i-1]
+		mu[i] = h[i] / l
+		z[i] = (alpha - h[i-1]*z[i-1]) / l
+	}
+	for i := n - 2; i >= 0; i-- {
+		s.C[i] = z[i] - mu[i]*s.C[i+1]
+		s.B[i] = (s.A[i+1]-s.A[i])/h[i] - h[i]*(s.C[i+1]+2*s.C[i])/3
+		s.D[i] = (s.C[i+1] - s.C[i]) / (3 * h[i])
+	}
+	return s
+}
+
+// eval evaluates the spline at x. Points outside the data range are
+// extrapolated from the first or last segment.
+func (s *splineValue) eval(x float64) float64 {
+	switch len(s.X) {
+	case 0:
+		return 0
+	case 1:
+		return s.A[0]
+	}
+	// The last knot only stores the end value, so search among segment starts.
+	i := sort.SearchFloat64s(s.X[:len(s.X)-1], x)
+	if i == len(s.X)-1 || (i > 0 && s.X[i] > x) {
+		i--
+	}
+	if i < 0 {
+		i = 0
+	}
+	t := x - s.X[i]
+	return s.A[i] + t*(s.B[i]+t*(s.C[i]+t*s.D[i]))
+}
+
+// floatSlice converts a VM float or int array to []float64.
+func floatSlice(v interface{}) ([]float64, bool) {
+	switch arr := v.(type) {
+	case []float64:
+		return arr, true
+	case []int:
+		out := make([]float64, len(arr))
+		for i, n := range arr {
+			out[i] = float64(n)
+		}
+		return out, true
+	}
+	return nil, false
+}
@@ -0,0 +1,57 @@
+package vm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInterpCubicSplineQuadratic(t *testing.T) {
+	xs := []float64{0, 1, 2, 3, 4}
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = x * x
+	}
+	s := interpCubicSpline(xs, ys)
+
+	for i, x := range xs {
+		if got := s.eval(x); math.Abs(got-ys[i]) > 1e-9 {
+			t.Errorf("eval(%v) = %v, want %v", x, got, ys[i])
+		}
+	}
+	// Natural end conditions force a zero second derivative at 0 and 4, so
+	// the spline only approximates x^2 between the knots, best in the middle.
+	for _, x := range []float64{1.5, 2.5} {
+		if got := s.eval(x); math.Abs(got-x*x) > 0.05 {
+			t.Errorf("eval(%v) = %v, want about %v", x, got, x*x)
+		}
+	}
+	if got := s.C[0] + s.C[len(s.C)-1]; got != 0 {
+		t.Errorf("end second derivatives = %v, want 0", got)
+	}
+}
+
+func TestInterpCubicSplineInvalid(t *testing.T) {
+	if s := interpCubicSpline([]float64{0, 2, 1}, []float64{0, 1, 2}); len(s.X) != 0 || s.eval(1) != 0 {
+		t.Errorf("non-increasing xs should give an empty spline, got %+v", s)
+	}
+	if s := interpCubicSpline([]float64{3}, []float64{7, 8}); s.eval(10) != 7 {
+		t.Errorf("single point spline should be constant, got %v", s.eval(10))
+	}
+}
+
+func TestInterpHelpers(t *testing.T) {
+	if got := interpLinear(1, 10, 3, 20, 2); got != 15 {
+		t.Errorf("linear = %v, want 15", got)
+	}
+	if got := interpLinear(1, 10, 1, 20, 5); got != 10 {
+		t.Errorf("linear with x0 == x1 = %v, want 10", got)
+	}
+	if got := interpLerp(-2, 2, 0.75); got != 1 {
+		t.Errorf("lerp = %v, want 1", got)
+	}
+	for _, tc := range []struct{ t, want float64 }{{-1, 0}, {0, 0}, {0.5, 0.5}, {1, 1}, {2, 1}, {0.25, 0.15625}} {
+		if got := interpSmoothStep(tc.t); math.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("smooth_step(%v) = %v, want %v", tc.t, got, tc.want)
+		}
+	}
+}
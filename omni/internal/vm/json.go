@@ -0,0 +1,203 @@
+package vm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// execJSON handles the std.json intrinsics. It is separate from
+// execIntrinsic because decoding malformed JSON is an error.
+func execJSON(callee string, operands []mir.Operand, fr *frame) (Result, bool, error) {
+	switch callee {
+	case "std.json.stringify":
+		if len(operands) != 1 {
+			return Result{}, true, fmt.Errorf("json.stringify: expected 1 argument, got %d", len(operands))
+		}
+		text, err := jsonStringify(operandValue(fr, operands[0]).Value)
+		if err != nil {
+			return Result{}, true, err
+		}
+		return Result{Type: "string", Value: text}, true, nil
+	case "std.json.parse":
+		if len(operands) != 1 {
+			return Result{}, true, fmt.Errorf("json.parse: expected 1 argument, got %d", len(operands))
+		}
+		s, err := toString(operandValue(fr, operands[0]))
+		if err != nil {
+			return Result{}, true, fmt.Errorf("json.parse: %w", err)
+		}
+		value, err := jsonParse(s)
+		if err != nil {
+			return Result{}, true, err
+		}
+		return Result{Type: "any", Value: value}, true, nil
+	}
+	return Result{}, false, nil
+}
+
+// jsonStringify returns the compact JSON encoding of a VM value. Structs
+// and parsed objects are maps with string keys already; the keys of other
+// maps are written as strings.
+func jsonStringify(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(jsonEncodable(value)); err != nil {
+		return "", fmt.Errorf("json.stringify: %w", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func jsonEncodable(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			obj[fmt.Sprint(key)] = jsonEncodable(elem)
+		}
+		return obj
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			obj[key] = jsonEncodable(elem)
+		}
+		return obj
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, elem := range v {
+			items[i] = jsonEncodable(elem)
+		}
+		return items
+	case Result:
+		return jsonEncodable(v.Value)
+	}
+	return value
+}
+
+// jsonParse decodes s into VM values: objects become maps with string keys,
+// which member reads like the fields of a struct, and arrays become
+// []interface{}. Numbers without a fraction or exponent that fit an int
+// become ints, the others floats.
+func jsonParse(s string) (interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("json.parse: %w", err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("json.parse: unexpected data after the document")
+	}
+	return jsonNumbers(value), nil
+}
+
+func jsonNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if !strings.ContainsAny(v.String(), ".eE") {
+			if i, err := v.Int64(); err == nil && i >= math.MinInt && i <= math.MaxInt {
+				return int(i)
+			}
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, elem := range v {
+			v[key] = jsonNumbers(elem)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = jsonNumbers(elem)
+		}
+	}
+	return value
+}
+
+// jsonKind names the kind of JSON value v holds, for error messages.
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case float64:
+		return "float"
+	}
+	return "int"
+}
+
+// anyMember reads the field name of the object held by the any value v.
+func anyMember(v interface{}, name string) (Result, error) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return Result{}, fmt.Errorf("member: cannot read field %q of a JSON %s", name, jsonKind(v))
+	}
+	field, ok := obj[name]
+	if !ok {
+		return Result{}, fmt.Errorf("member: JSON object has no field %q", name)
+	}
+	return Result{Type: "any", Value: field}, nil
+}
+
+// anyIndex reads an element of the array, or a field of the object, held
+// by the any value v.
+func anyIndex(v interface{}, index Result) (Result, error) {
+	if name, ok := index.Value.(string); ok {
+		return anyMember(v, name)
+	}
+	items, ok := v.([]interface{})
+	if !ok {
+		return Result{}, fmt.Errorf("index: cannot index a JSON %s", jsonKind(v))
+	}
+	i, err := toInt(index)
+	if err != nil {
+		return Result{}, fmt.Errorf("index: index must be int, got %s", index.Type)
+	}
+	if i < 0 || i >= len(items) {
+		return Result{}, fmt.Errorf("index: index %d out of bounds for JSON array of length %d", i, len(items))
+	}
+	return Result{Type: "any", Value: items[i]}, nil
+}
+
+// castAny converts the any value v to target, which must match the kind
+// of value it holds; an int also converts to a float.
+func castAny(v interface{}, target string) (Result, error) {
+	switch target {
+	case "int", "int64", "long":
+		if i, ok := v.(int); ok {
+			if target == "int" {
+				return Result{Type: "int", Value: i}, nil
+			}
+			return Result{Type: target, Value: int64(i)}, nil
+		}
+	case "float", "double":
+		switch n := v.(type) {
+		case float64:
+			return Result{Type: target, Value: n}, nil
+		case int:
+			return Result{Type: target, Value: float64(n)}, nil
+		}
+	case "string":
+		if s, ok := v.(string); ok {
+			return Result{Type: "string", Value: s}, nil
+		}
+	case "bool":
+		if b, ok := v.(bool); ok {
+			return Result{Type: "bool", Value: b}, nil
+		}
+	case "any":
+		return Result{Type: "any", Value: v}, nil
+	}
+	return Result{}, fmt.Errorf("cast: cannot convert a JSON %s to %s", jsonKind(v), target)
+}
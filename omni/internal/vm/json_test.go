@@ -0,0 +1,75 @@
+package vm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestJSONParseNestedDocument(t *testing.T) {
+	src := `func main():string {
+  let doc:any = std.json.parse("{\"user\":{\"name\":\"ada\",\"langs\":[\"en\",\"fr\"]},\"age\":36,\"ratio\":0.5,\"ok\":true}")
+  let name:string = (string) doc.user.name
+  let lang:string = (string) doc.user.langs[1]
+  let age:int = (int) doc["age"]
+  let ratio:float = (float) doc.ratio
+  let ok:bool = (bool) doc.ok
+  if ok && ratio == 0.5 && len(doc.user.langs) == 2 {
+    return name + " " + lang + " " + std.json.stringify(age)
+  }
+  return "mismatch"
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != "ada fr 36" {
+		t.Errorf("result = %q, want %q", res.Value, "ada fr 36")
+	}
+}
+
+func TestJSONStringify(t *testing.T) {
+	for call, want := range map[string]string{
+		`std.json.stringify(std.json.parse(" {\"b\": [1, 2.5, null], \"a\": {}} "))`: `{"a":{},"b":[1,2.5,null]}`,
+		`std.json.stringify("tab\t<q\">")`:                                           `"tab\t<q\">"`,
+		`std.json.stringify(42)`:                                                     `42`,
+		`std.json.stringify(1e-7)`:                                                   `1e-7`,
+		`std.json.stringify(false)`:                                                  `false`,
+	} {
+		src := `func main():string {
+  return ` + call + `
+}
+`
+		res, err := vm.Execute(buildSource(t, src), "main")
+		if err != nil {
+			t.Errorf("%s: execute: %v", call, err)
+			continue
+		}
+		if res.Value != want {
+			t.Errorf("%s = %q, want %q", call, res.Value, want)
+		}
+	}
+}
+
+func TestJSONRejectsMalformedAccess(t *testing.T) {
+	for body, want := range map[string]string{
+		`return (int) std.json.parse("[1,")`:              "json.parse:",
+		`return (int) std.json.parse("1 2")`:              "json.parse: unexpected data after the document",
+		`return (int) std.json.parse("{\"a\":1}").b`:      `member: JSON object has no field "b"`,
+		`return (int) std.json.parse("[1]").a`:            `member: cannot read field "a" of a JSON array`,
+		`return (int) std.json.parse("[1]")[3]`:           "index: index 3 out of bounds for JSON array of length 1",
+		`return (int) std.json.parse("{\"a\":\"s\"}").a`:  "cast: cannot convert a JSON string to int",
+		`return (int) std.json.parse("{\"a\":1.5}")["a"]`: "cast: cannot convert a JSON float to int",
+	} {
+		src := `func main():int {
+  ` + body + `
+}
+`
+		_, err := vm.Execute(buildSource(t, src), "main")
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected error %q, got %v", body, want, err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestMatchExpr(t *testing.T) {
+	src := `enum Shape { CIRCLE SQUARE TRIANGLE }
+struct Size {
+  w:int
+  h:int
+}
+func sides(s:Shape):int {
+  return match s {
+    case Shape.CIRCLE => 0,
+    case Shape.SQUARE => 4,
+    case Shape.TRIANGLE => 3
+  }
+}
+func bucket(n:int):int {
+  return match n {
+    case 0 => 10
+    case 1 => 20
+    case _ => n
+  }
+}
+func area(s:Size):int {
+  return match s { case { w: width, h: height } => width * height }
+}
+func main():int {
+  let words:int = match "two" { case "one" => 1, case "two" => 2, case _ => 0 }
+  return sides(Shape.SQUARE) + sides(Shape.TRIANGLE) + bucket(1) + bucket(7) + area(Size{w: 2, h: 5}) + words
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	// 4 + 3 + 20 + 7 + 10 + 2
+	if res.Value != 46 {
+		t.Errorf("result = %v, want 46", res.Value)
+	}
+}
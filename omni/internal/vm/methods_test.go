@@ -0,0 +1,32 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestMethodCalls(t *testing.T) {
+	src := `struct Point {
+  x:int
+  y:int
+}
+func (p Point) scaled(k:int):Point {
+  return Point{x: p.x * k, y: p.y * k}
+}
+func (p Point) sum():int {
+  return p.x + p.y
+}
+func main():int {
+  let p:Point = Point{x: 3, y: 4}
+  return p.scaled(6).sum() + p.sum()
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 49 {
+		t.Errorf("result = %v, want 49", res.Value)
+	}
+}
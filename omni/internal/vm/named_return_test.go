@@ -0,0 +1,44 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestNamedReturn(t *testing.T) {
+	// A bare return and the end of the body both return the variable, which
+	// starts out as the zero value of its type
+	src := `func clamp(n:int):int as out {
+  if n < 0 {
+    return
+  }
+  out = n
+  if out > 9 {
+    out = 9
+  }
+}
+func label(n:int):string as s {
+  if n > 0 {
+    s = "pos"
+  }
+}
+func main():int {
+  var n:int = clamp(-4) * 100 + clamp(25) * 10
+  if label(0) == "" {
+    n = n + 1
+  }
+  if label(1) == "pos" {
+    n = n + 2
+  }
+  return n
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 93 {
+		t.Errorf("result = %v, want 93", res.Value)
+	}
+}
@@ -0,0 +1,82 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// An optional value that opt.some or opt.none made is held as a map with a
+// has_value flag and the value itself. The standard library functions that
+// return optionals give the value or null directly, so the opt instructions
+// accept both forms.
+
+// optionalOf returns the optional value of type typ that holds value.
+func optionalOf(typ string, value interface{}, hasValue bool) Result {
+	return Result{Type: typ, Value: map[string]interface{}{"has_value": hasValue, "value": value}}
+}
+
+// optionalValue returns what the optional value r holds, and whether it
+// holds anything.
+func optionalValue(r Result) (interface{}, bool) {
+	if m, ok := r.Value.(map[string]interface{}); ok {
+		if hasValue, ok := m["has_value"].(bool); ok {
+			return m["value"], hasValue
+		}
+	}
+	if r.Type == "null" || r.Value == nil {
+		return nil, false
+	}
+	return r.Value, true
+}
+
+// execOptSome handles opt.some, which makes an optional value holding its
+// operand.
+func execOptSome(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	if len(inst.Operands) != 1 {
+		return Result{}, fmt.Errorf("opt.some: expected 1 operand, got %d", len(inst.Operands))
+	}
+	value, hasValue := optionalValue(operandValue(fr, inst.Operands[0]))
+	return optionalOf(inst.Type, value, hasValue), nil
+}
+
+// execOptNone handles opt.none, which makes an optional value holding
+// nothing.
+func execOptNone(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	return optionalOf(inst.Type, nil, false), nil
+}
+
+// execOptUnwrap handles opt.unwrap, which fails when the optional value
+// holds nothing.
+func execOptUnwrap(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	if len(inst.Operands) != 1 {
+		return Result{}, fmt.Errorf("opt.unwrap: expected 1 operand, got %d", len(inst.Operands))
+	}
+	value, hasValue := optionalValue(operandValue(fr, inst.Operands[0]))
+	if !hasValue {
+		return Result{}, fmt.Errorf("opt.unwrap: value is none")
+	}
+	return Result{Type: inst.Type, Value: value}, nil
+}
+
+// execOptUnwrapOr handles opt.unwrap_or, which gives its second operand
+// when the optional value holds nothing.
+func execOptUnwrapOr(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	if len(inst.Operands) != 2 {
+		return Result{}, fmt.Errorf("opt.unwrap_or: expected 2 operands, got %d", len(inst.Operands))
+	}
+	value, hasValue := optionalValue(operandValue(fr, inst.Operands[0]))
+	if !hasValue {
+		return operandValue(fr, inst.Operands[1]), nil
+	}
+	return Result{Type: inst.Type, Value: value}, nil
+}
+
+// execOptIsSome handles opt.is_some and opt.is_none.
+func execOptIsSome(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	if len(inst.Operands) != 1 {
+		return Result{}, fmt.Errorf("%s: expected 1 operand, got %d", inst.Op, len(inst.Operands))
+	}
+	_, hasValue := optionalValue(operandValue(fr, inst.Operands[0]))
+	return Result{Type: "bool", Value: hasValue == (inst.Op == "opt.is_some")}, nil
+}
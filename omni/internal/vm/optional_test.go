@@ -0,0 +1,53 @@
+package vm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestOptionalBuiltins(t *testing.T) {
+	src := `func find(n:int):int? {
+  if n > 0 {
+    return n * 2
+  }
+  return null
+}
+func bump(x:int?):int {
+  return opt.unwrap_or(x, 0) + 1
+}
+func main():int {
+  let a:int? = find(3)
+  let b:int? = find(0)
+  var total:int = opt.unwrap(a) + opt.unwrap_or(b, 100)
+  if opt.is_none(b) && b == null {
+    total = total + 1000
+  }
+  if opt.is_some(a) && a != null {
+    total = total + 10000
+  }
+  return total + bump(7) + bump(null)
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	// 6 + 100 + 1000 + 10000 + 8 + 1
+	if res.Value != 11115 {
+		t.Errorf("result = %v, want 11115", res.Value)
+	}
+}
+
+func TestOptionalUnwrapNone(t *testing.T) {
+	src := `func main():int {
+  let missing:string? = null
+  return std.string.length(opt.unwrap(missing))
+}
+`
+	_, err := vm.Execute(buildSource(t, "import std\n"+src), "main")
+	if err == nil || !strings.Contains(err.Error(), "opt.unwrap: value is none") {
+		t.Fatalf("expected opt.unwrap to fail on null, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// orderedMapEntry is a key of a std.collections.ordered_map value and the
+// value stored under it.
+type orderedMapEntry struct {
+	key, value interface{}
+}
+
+// orderedMapValue is the VM representation of a std.collections.ordered_map
+// value. entries holds the keys in insertion order and index finds the
+// position of a key in entries. Ordered maps are shared by reference, like
+// deques.
+type orderedMapValue struct {
+	keyType, valueType string
+	entries            []orderedMapEntry
+	index              map[interface{}]int
+}
+
+// remove deletes the entry at position i, moving the later entries up.
+func (m *orderedMapValue) remove(i int) {
+	delete(m.index, m.entries[i].key)
+	m.entries = append(m.entries[:i], m.entries[i+1:]...)
+	for j := i; j < len(m.entries); j++ {
+		m.index[m.entries[j].key] = j
+	}
+}
+
+// execOrderedMap handles the std.collections.ordered_map functions.
+func execOrderedMap(callee string, operands []mir.Operand, fr *frame) (Result, bool, error) {
+	if !strings.HasPrefix(callee, "std.collections.ordered_map.") {
+		return Result{}, false, nil
+	}
+	name := callee[len("std."):]
+	op := strings.TrimPrefix(callee, "std.collections.ordered_map.")
+	want := 1
+	switch op {
+	case "create":
+		want = 0
+	case "put":
+		want = 3
+	case "get", "remove":
+		want = 2
+	case "keys", "values", "entries":
+	default:
+		return Result{}, false, nil
+	}
+	if len(operands) != want {
+		return Result{}, true, fmt.Errorf("%s: expected %d arguments, got %d", name, want, len(operands))
+	}
+	if op == "create" {
+		return Result{Type: "OrderedMap<any,any>", Value: &orderedMapValue{index: make(map[interface{}]int)}}, true, nil
+	}
+	arg := operandValue(fr, operands[0])
+	m, ok := arg.Value.(*orderedMapValue)
+	if !ok {
+		return Result{}, true, fmt.Errorf("%s: expected an ordered map, got %s", name, arg.Type)
+	}
+
+	switch op {
+	case "put":
+		key, val := operandValue(fr, operands[1]), operandValue(fr, operands[2])
+		if m.keyType == "" {
+			m.keyType, m.valueType = key.Type, val.Type
+		}
+		if i, ok := m.index[key.Value]; ok {
+			m.entries[i].value = val.Value
+		} else {
+			m.index[key.Value] = len(m.entries)
+			m.entries = append(m.entries, orderedMapEntry{key.Value, val.Value})
+		}
+		return Result{Type: "void"}, true, nil
+	case "get":
+		key := operandValue(fr, operands[1])
+		if i, ok := m.index[key.Value]; ok {
+			return optionalOf(m.valueType+"?", m.entries[i].value, true), true, nil
+		}
+		return optionalOf(m.valueType+"?", nil, false), true, nil
+	case "remove":
+		key := operandValue(fr, operands[1])
+		i, ok := m.index[key.Value]
+		if ok {
+			m.remove(i)
+		}
+		return Result{Type: "bool", Value: ok}, true, nil
+	}
+
+	// The types are known from the first put; an empty map never had one
+	keyType, valueType := m.keyType, m.valueType
+	if keyType == "" {
+		keyType, valueType = "any", "any"
+	}
+	elemType := keyType
+	switch op {
+	case "values":
+		elemType = valueType
+	case "entries":
+		elemType = "tuple<" + keyType + "," + valueType + ">"
+	}
+	elems := make([]Result, len(m.entries))
+	for i, e := range m.entries {
+		switch op {
+		case "keys":
+			elems[i] = Result{Type: elemType, Value: e.key}
+		case "values":
+			elems[i] = Result{Type: elemType, Value: e.value}
+		default:
+			elems[i] = Result{Type: elemType, Value: map[string]interface{}{"0": e.key, "1": e.value}}
+		}
+	}
+	return newArrayResult(elemType, elems), true, nil
+}
@@ -0,0 +1,63 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestOrderedMapKeepsInsertionOrder(t *testing.T) {
+	src := `func main():string {
+  let m:OrderedMap<string, int> = std.collections.ordered_map.create()
+  std.collections.ordered_map.put(m, "pear", 1)
+  std.collections.ordered_map.put(m, "apple", 2)
+  std.collections.ordered_map.put(m, "fig", 3)
+  std.collections.ordered_map.put(m, "kiwi", 4)
+  std.collections.ordered_map.put(m, "apple", 20)
+  let removed:bool = std.collections.ordered_map.remove(m, "fig")
+  let missing:bool = std.collections.ordered_map.remove(m, "fig")
+  std.collections.ordered_map.put(m, "date", 5)
+  let keys:array<string> = std.collections.ordered_map.keys(m)
+  let values:array<int> = std.collections.ordered_map.values(m)
+  var out:string = std.bool_to_string(removed) + " " + std.bool_to_string(missing) + ":"
+  for i:int = 0; i < len(keys); i++ {
+    out = out + " " + keys[i] + "=" + std.int_to_string(values[i])
+  }
+  return out
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	want := "true false: pear=1 apple=20 kiwi=4 date=5"
+	if res.Value != want {
+		t.Errorf("result = %q, want %q", res.Value, want)
+	}
+}
+
+func TestOrderedMapEntriesAndGet(t *testing.T) {
+	src := `func main():int {
+  let m:OrderedMap<int, string> = std.collections.ordered_map.create()
+  for i:int = 5; i > 0; i-- {
+    std.collections.ordered_map.put(m, i * 10, std.int_to_string(i))
+  }
+  std.collections.ordered_map.remove(m, 30)
+  let entries:array<(int, string)> = std.collections.ordered_map.entries(m)
+  let (key, value) = entries[2]
+  let hit:string? = std.collections.ordered_map.get(m, 10)
+  let miss:string? = std.collections.ordered_map.get(m, 30)
+  if value != "2" || opt.unwrap(hit) != "1" || miss != null {
+    return -1
+  }
+  return key * 10 + len(entries)
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 204 {
+		t.Errorf("result = %v, want 204", res.Value)
+	}
+}
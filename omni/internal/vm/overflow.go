@@ -0,0 +1,79 @@
+package vm
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// ArithmeticError reports an int arithmetic instruction whose result does
+// not fit in 32 bits, the width of int in the C backend. The VM computes
+// ints in 64 bits, so without ExecuteOptions.OverflowCheck such a result goes
+// unnoticed where a compiled program would wrap.
+type ArithmeticError struct {
+	// Op is the instruction, such as add or mul.
+	Op          string
+	Left, Right int64
+}
+
+// arithmeticSymbols are the instructions OverflowCheck checks, with the
+// operators they print as. mod is not among them: its result is never larger
+// than its operands.
+var arithmeticSymbols = map[string]string{"add": "+", "sub": "-", "mul": "*", "div": "/"}
+
+func (e ArithmeticError) Error() string {
+	return fmt.Sprintf("integer overflow in %s: %d %s %d does not fit in int", e.Op, e.Left, arithmeticSymbols[e.Op], e.Right)
+}
+
+// overflowHandlers returns a copy of handlers whose int arithmetic fails
+// with an ArithmeticError when the result overflows.
+func overflowHandlers(handlers map[string]instructionHandler) map[string]instructionHandler {
+	checked := make(map[string]instructionHandler, len(handlers))
+	for op, handler := range handlers {
+		checked[op] = handler
+	}
+	for op := range arithmeticSymbols {
+		if handler, ok := checked[op]; ok {
+			checked[op] = checkOverflow(handler)
+		}
+	}
+	return checked
+}
+
+// checkOverflow wraps the handler of an arithmetic instruction, redoing the
+// operation on the operands widened to int64. Results that are not Go ints,
+// those of floats, int64 and the unsigned types, keep the width of their type
+// and are not checked.
+func checkOverflow(handler instructionHandler) instructionHandler {
+	return func(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+		res, err := handler(funcs, fr, inst)
+		if err != nil {
+			return res, err
+		}
+		if _, ok := res.Value.(int); !ok {
+			return res, nil
+		}
+		left, lerr := toInt64(operandValue(fr, inst.Operands[0]))
+		right, rerr := toInt64(operandValue(fr, inst.Operands[1]))
+		if lerr != nil || rerr != nil {
+			return res, nil
+		}
+		var wide int64
+		switch inst.Op {
+		case "add":
+			wide = left + right
+		case "sub":
+			wide = left - right
+		case "mul":
+			wide = left * right
+		case "div":
+			// The handler already refused a zero divisor
+			wide = left / right
+		}
+		if wide > math.MaxInt32 || wide < math.MinInt32 {
+			return Result{}, ArithmeticError{Op: inst.Op, Left: left, Right: right}
+		}
+		return res, nil
+	}
+}
@@ -0,0 +1,88 @@
+package vm_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+// runOverflowChecked runs the main function of src with OverflowCheck.
+func runOverflowChecked(t *testing.T, src string) (vm.Result, error) {
+	t.Helper()
+	return vm.ExecuteWithOptions(context.Background(), buildSource(t, src), "main", vm.ExecuteOptions{OverflowCheck: true})
+}
+
+func TestOverflowCheckMaxIntPlusOne(t *testing.T) {
+	src := `func main():int {
+  let x:int = 2147483647
+  return x + 1
+}
+`
+	_, err := runOverflowChecked(t, src)
+	var overflow vm.ArithmeticError
+	if !errors.As(err, &overflow) {
+		t.Fatalf("expected a vm.ArithmeticError, got %v", err)
+	}
+	want := vm.ArithmeticError{Op: "add", Left: 2147483647, Right: 1}
+	if overflow != want {
+		t.Errorf("error = %+v, want %+v", overflow, want)
+	}
+	if msg := "integer overflow in add: 2147483647 + 1 does not fit in int"; !strings.Contains(err.Error(), msg) {
+		t.Errorf("error = %q, want it to contain %q", err.Error(), msg)
+	}
+
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute without the check: %v", err)
+	}
+	if res.Value != 2147483648 {
+		t.Errorf("unchecked result = %v, want 2147483648", res.Value)
+	}
+}
+
+func TestOverflowCheckOperations(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		op   string
+	}{
+		{"sub", "x - 3", "sub"},
+		{"mul", "x * 2", "mul"},
+		{"div", "x / -1", "div"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := `func main():int {
+  let x:int = -2147483648
+  return ` + tt.expr + `
+}
+`
+			_, err := runOverflowChecked(t, src)
+			var overflow vm.ArithmeticError
+			if !errors.As(err, &overflow) || overflow.Op != tt.op {
+				t.Fatalf("expected an ArithmeticError in %s, got %v", tt.op, err)
+			}
+		})
+	}
+}
+
+func TestOverflowCheckAllowsResultsInRange(t *testing.T) {
+	res, err := runOverflowChecked(t, `func main():int {
+  let x:int = 2147483646
+  let y:float = 2147483647.0 * 4.0
+  if y > 0.0 {
+    return x + 1
+  }
+  return 0
+}
+`)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 2147483647 {
+		t.Errorf("result = %v, want 2147483647", res.Value)
+	}
+}
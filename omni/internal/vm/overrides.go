@@ -0,0 +1,33 @@
+package vm
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	overridesMu       sync.RWMutex
+	overriddenModules []string
+)
+
+// SetModuleOverrides marks modules (dotted import paths such as
+// "std.network") whose functions were replaced through an import map. Calls
+// into them run the merged replacement function instead of the VM's built-in
+// implementation. Passing nil clears the overrides.
+func SetModuleOverrides(modules []string) {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	overriddenModules = append([]string(nil), modules...)
+}
+
+// isOverridden reports whether callee belongs to an overridden module.
+func isOverridden(callee string) bool {
+	overridesMu.RLock()
+	defer overridesMu.RUnlock()
+	for _, module := range overriddenModules {
+		if strings.HasPrefix(callee, module+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package vm
+
+import (
+	"path/filepath"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// execPath handles the std.path intrinsics with path/filepath, so paths
+// follow the conventions of the platform the VM runs on.
+func execPath(callee string, operands []mir.Operand, fr *frame) (Result, bool) {
+	if callee == "std.path.join" {
+		// The parts arrive packed in an array when std.path was merged and
+		// one by one otherwise
+		var parts []string
+		for _, op := range operands {
+			switch v := operandValue(fr, op).Value.(type) {
+			case []string:
+				parts = append(parts, v...)
+			case string:
+				parts = append(parts, v)
+			default:
+				return Result{}, false
+			}
+		}
+		return Result{Type: "string", Value: filepath.Join(parts...)}, true
+	}
+	if len(operands) != 1 {
+		return Result{}, false
+	}
+	p, err := toString(operandValue(fr, operands[0]))
+	if err != nil {
+		return Result{}, false
+	}
+	switch callee {
+	case "std.path.clean":
+		return Result{Type: "string", Value: filepath.Clean(p)}, true
+	case "std.path.dirname":
+		return Result{Type: "string", Value: filepath.Dir(p)}, true
+	case "std.path.basename":
+		return Result{Type: "string", Value: filepath.Base(p)}, true
+	case "std.path.ext":
+		return Result{Type: "string", Value: filepath.Ext(p)}, true
+	case "std.path.is_abs":
+		return Result{Type: "bool", Value: filepath.IsAbs(p)}, true
+	case "std.path.abs":
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			// Without a working directory the path stays relative
+			abs = filepath.Clean(p)
+		}
+		return Result{Type: "string", Value: abs}, true
+	}
+	return Result{}, false
+}
@@ -0,0 +1,95 @@
+package vm_test
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+// runPathCalls evaluates each std.path call and compares the string it
+// returns.
+func runPathCalls(t *testing.T, cases map[string]string) {
+	t.Helper()
+	for call, want := range cases {
+		src := `func main():string {
+  return ` + call + `
+}
+`
+		res, err := vm.Execute(buildSource(t, src), "main")
+		if err != nil {
+			t.Errorf("%s: execute: %v", call, err)
+			continue
+		}
+		if res.Value != want {
+			t.Errorf("%s = %q, want %q", call, res.Value, want)
+		}
+	}
+}
+
+func TestPathJoinLeadingSeparators(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix separators")
+	}
+	runPathCalls(t, map[string]string{
+		`std.path.join("/usr", "/local/", "/bin")`: "/usr/local/bin",
+		`std.path.join("", "/etc", "hosts")`:       "/etc/hosts",
+		`std.path.join("a", "", "b")`:              "a/b",
+		`std.path.join("a/b", "../../..", "c")`:    "../c",
+		`std.path.join("", "")`:                    "",
+		`std.path.clean("a//b/./c/..")`:            "a/b",
+		`std.path.dirname("/a/b/")`:                "/a/b",
+		`std.path.basename("/a/b/")`:               "b",
+		`std.path.ext("archive.tar.gz")`:           ".gz",
+		`std.path.ext("v1.2/README")`:              "",
+	})
+}
+
+func TestPathBackslashes(t *testing.T) {
+	// Backslashes separate paths only on Windows; elsewhere they are part
+	// of the file name
+	p := strconv.Quote(`C:\Users\ada\notes.txt`)
+	cases := map[string]string{
+		`std.path.basename(` + p + `)`:                 `notes.txt`,
+		`std.path.dirname(` + p + `)`:                  `C:\Users\ada`,
+		`std.path.join("C:\\Users", "\\ada", "x.txt")`: `C:\Users\ada\x.txt`,
+		`std.path.clean("C:\\Users\\\\ada\\..\\bob")`:  `C:\Users\bob`,
+		`std.path.ext(` + p + `)`:                      `.txt`,
+	}
+	if runtime.GOOS != "windows" {
+		cases = map[string]string{
+			`std.path.basename(` + p + `)`:                 `C:\Users\ada\notes.txt`,
+			`std.path.dirname(` + p + `)`:                  `.`,
+			`std.path.join("C:\\Users", "\\ada", "x.txt")`: `C:\Users/\ada/x.txt`,
+			`std.path.clean("C:\\Users\\\\ada\\..\\bob")`:  `C:\Users\\ada\..\bob`,
+			`std.path.ext(` + p + `)`:                      `.txt`,
+		}
+	}
+	runPathCalls(t, cases)
+}
+
+func TestPathIsAbs(t *testing.T) {
+	abs, rel := "/srv", "srv"
+	if runtime.GOOS == "windows" {
+		abs = `C:\\srv`
+	}
+	src := `func main():int {
+  var passed:int = 0
+  if std.path.is_abs("` + abs + `") && !std.path.is_abs("` + rel + `") {
+    passed = passed + 1
+  }
+  if std.path.is_abs(std.path.abs("` + rel + `")) && std.path.basename(std.path.abs("` + rel + `")) == "` + rel + `" {
+    passed = passed + 1
+  }
+  return passed
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 2 {
+		t.Errorf("%v of 2 checks passed", res.Value)
+	}
+}
@@ -0,0 +1,86 @@
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// execProcess handles the std.process intrinsics. run_async resolves its
+// promise from a goroutine, as the async std.os functions do.
+func execProcess(callee string, operands []mir.Operand, fr *frame) (Result, bool) {
+	if callee != "std.process.run" && callee != "std.process.run_async" {
+		return Result{}, false
+	}
+	var cmd string
+	var args []string
+	err := fmt.Errorf("%s: expected a command and an array of arguments", callee)
+	if len(operands) == 2 {
+		if cmd, err = toString(operandValue(fr, operands[0])); err == nil {
+			args, err = processArgs(operandValue(fr, operands[1]))
+		}
+	}
+	if callee == "std.process.run" {
+		if err != nil {
+			return processResult("", err.Error()+"\n", 127), true
+		}
+		return runProcess(cmd, args), true
+	}
+	promiseID := newPromise()
+	if err != nil {
+		rejectPromise(promiseID, err)
+	} else {
+		go func() {
+			resolvePromise(promiseID, runProcess(cmd, args))
+		}()
+	}
+	return Result{Type: "Promise", Value: promiseID}, true
+}
+
+// processArgs reads the arguments of a command from an array<string>. An
+// empty array literal has no element type, so it is accepted too.
+func processArgs(arg Result) ([]string, error) {
+	switch arr := arg.Value.(type) {
+	case []string:
+		return arr, nil
+	case []interface{}:
+		args := make([]string, len(arr))
+		for i, elem := range arr {
+			s, ok := elem.(string)
+			if !ok {
+				return nil, fmt.Errorf("process: argument %d is not a string", i)
+			}
+			args[i] = s
+		}
+		return args, nil
+	}
+	return nil, fmt.Errorf("process: arguments must be an array<string>, got %s", arg.Type)
+}
+
+// runProcess runs cmd with args and waits for it. Like omni_process_run in
+// the C runtime, a command that cannot start exits with 127 and one killed by
+// a signal with -1.
+func runProcess(cmd string, args []string) Result {
+	var stdout, stderr bytes.Buffer
+	c := exec.Command(cmd, args...)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return processResult("", fmt.Sprintf("process: cannot run %s: %v\n", cmd, err), 127)
+		}
+	}
+	return processResult(stdout.String(), stderr.String(), c.ProcessState.ExitCode())
+}
+
+func processResult(stdout, stderr string, exitCode int) Result {
+	return Result{Type: "ProcessResult", Value: map[string]interface{}{
+		"stdout":    stdout,
+		"stderr":    stderr,
+		"exit_code": exitCode,
+	}}
+}
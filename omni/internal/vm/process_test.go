@@ -0,0 +1,61 @@
+package vm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestProcessRunCapturesOutput(t *testing.T) {
+	src := `func main():string {
+  let args:array<string> = ["hello"]
+  let result:ProcessResult = std.process.run("echo", args)
+  return result.stdout + std.int_to_string(result.exit_code)
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != "hello\n0" {
+		t.Errorf("result = %q, want %q", res.Value, "hello\n0")
+	}
+}
+
+func TestProcessRunReportsFailures(t *testing.T) {
+	for args, want := range map[string]string{
+		`"sh", ["-c", "echo oops >&2; exit 3"]`: "3|oops\n",
+		`"sh", ["-c", "kill -9 $$"]`:            "-1|",
+		`"omni-no-such-command", ["x"]`:         "127|process: cannot run omni-no-such-command",
+	} {
+		src := `func main():string {
+  let result:ProcessResult = std.process.run(` + args + `)
+  return std.int_to_string(result.exit_code) + "|" + result.stderr
+}
+`
+		res, err := vm.Execute(buildSource(t, src), "main")
+		if err != nil {
+			t.Errorf("%s: execute: %v", args, err)
+			continue
+		}
+		if got, _ := res.Value.(string); !strings.HasPrefix(got, want) {
+			t.Errorf("%s = %q, want prefix %q", args, got, want)
+		}
+	}
+}
+
+func TestProcessRunAsync(t *testing.T) {
+	src := `async func main():string {
+  let result:ProcessResult = await std.process.run_async("echo", ["-n", "a", "b"])
+  return result.stdout
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != "a b" {
+		t.Errorf("result = %q, want %q", res.Value, "a b")
+	}
+}
@@ -0,0 +1,88 @@
+package vm
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// vmOpcodeStats accumulates the executions of one opcode.
+type vmOpcodeStats struct {
+	count   uint64
+	totalNs int64
+}
+
+// vmProfiler records how often each opcode runs and how long it takes. The
+// time of a call includes the instructions of the callee, so call opcodes
+// report inclusive time.
+type vmProfiler struct {
+	mu  sync.Mutex
+	ops map[string]vmOpcodeStats
+}
+
+func newVMProfiler() *vmProfiler {
+	return &vmProfiler{ops: make(map[string]vmOpcodeStats)}
+}
+
+func (p *vmProfiler) record(op string, elapsed time.Duration) {
+	p.mu.Lock()
+	stats := p.ops[op]
+	stats.count++
+	stats.totalNs += elapsed.Nanoseconds()
+	p.ops[op] = stats
+	p.mu.Unlock()
+}
+
+// SetProfilingEnabled starts or stops per-opcode profiling. Enabling it
+// discards the statistics of any earlier run.
+func SetProfilingEnabled(enabled bool) {
+	if enabled {
+		instructions.profiler.Store(newVMProfiler())
+	} else {
+		instructions.profiler.Store(nil)
+	}
+}
+
+// IsProfilingEnabled returns whether per-opcode profiling is enabled.
+func IsProfilingEnabled() bool {
+	return instructions.profiler.Load() != nil
+}
+
+// OpcodeProfile is the profile entry of one opcode.
+type OpcodeProfile struct {
+	Opcode  string `json:"opcode"`
+	Count   uint64 `json:"count"`
+	TotalNs int64  `json:"total_ns"`
+}
+
+// ProfileSnapshot returns the statistics collected so far, sorted by total
+// time, most expensive first.
+func ProfileSnapshot() []OpcodeProfile {
+	p := instructions.profiler.Load()
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	entries := make([]OpcodeProfile, 0, len(p.ops))
+	for op, stats := range p.ops {
+		entries = append(entries, OpcodeProfile{Opcode: op, Count: stats.count, TotalNs: stats.totalNs})
+	}
+	p.mu.Unlock()
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].TotalNs != entries[j].TotalNs {
+			return entries[i].TotalNs > entries[j].TotalNs
+		}
+		return entries[i].Opcode < entries[j].Opcode
+	})
+	return entries
+}
+
+// ExportProfile exports the per-opcode statistics as JSON.
+func ExportProfile() ([]byte, error) {
+	entries := ProfileSnapshot()
+	if entries == nil {
+		entries = []OpcodeProfile{}
+	}
+	return json.MarshalIndent(map[string]interface{}{"opcodes": entries}, "", "  ")
+}
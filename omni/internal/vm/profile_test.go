@@ -0,0 +1,74 @@
+package vm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir/builder"
+	"github.com/omni-lang/omni/internal/parser"
+)
+
+func TestProfileCountsLoopAdds(t *testing.T) {
+	src := `func main():int {
+  var total:int = 0
+  for var i:int = 0; i < 25; i++ {
+    total = total + i
+  }
+  return total
+}
+`
+	astMod, err := parser.Parse("loop.omni", src)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	mod, err := builder.BuildModule(astMod)
+	if err != nil {
+		t.Fatalf("build MIR: %v", err)
+	}
+
+	SetProfilingEnabled(true)
+	defer SetProfilingEnabled(false)
+	res, err := Execute(mod, "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 300 {
+		t.Fatalf("expected 300, got %v", res.Value)
+	}
+
+	data, err := ExportProfile()
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	var report struct {
+		Opcodes []OpcodeProfile `json:"opcodes"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("decode report: %v\n%s", err, data)
+	}
+	counts := make(map[string]uint64)
+	for i, entry := range report.Opcodes {
+		counts[entry.Opcode] = entry.Count
+		if i > 0 && entry.TotalNs > report.Opcodes[i-1].TotalNs {
+			t.Errorf("opcodes not sorted by total time: %s after %s", entry.Opcode, report.Opcodes[i-1].Opcode)
+		}
+	}
+	// Each of the 25 iterations adds to total and increments i
+	if counts["add"] != 50 {
+		t.Errorf("expected 50 adds, got %d", counts["add"])
+	}
+	// The condition is checked once more when the loop exits
+	if counts["cmp.lt"] != 26 {
+		t.Errorf("expected 26 comparisons, got %d", counts["cmp.lt"])
+	}
+}
+
+func TestProfileDisabledRecordsNothing(t *testing.T) {
+	SetProfilingEnabled(false)
+	if IsProfilingEnabled() {
+		t.Fatal("expected profiling to be disabled")
+	}
+	if entries := ProfileSnapshot(); entries != nil {
+		t.Errorf("expected no profile while disabled, got %v", entries)
+	}
+}
@@ -0,0 +1,58 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// promptAttempts is how many times prompt_int and prompt_float ask before
+// they give up and return the default, as in the C runtime.
+const promptAttempts = 3
+
+// execPrompt handles the std.io prompt intrinsics: the message is printed
+// without a newline and a line is read from stdin. The numeric prompts ask
+// again on invalid input and return the default once stdin is exhausted.
+func execPrompt(callee string, operands []mir.Operand, fr *frame) (Result, bool) {
+	want := 2
+	if callee == "std.io.prompt" {
+		want = 1
+	}
+	if len(operands) != want {
+		return Result{}, false
+	}
+	message, err := toString(operandValue(fr, operands[0]))
+	if err != nil {
+		return Result{}, false
+	}
+	if callee == "std.io.prompt" {
+		fmt.Print(message)
+		line, err := readLineFromStdin()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return Result{Type: "string", Value: ""}, true
+		}
+		return Result{Type: "string", Value: line}, true
+	}
+
+	def := operandValue(fr, operands[1])
+	for attempt := 0; attempt < promptAttempts; attempt++ {
+		fmt.Print(message)
+		line, err := readLineFromStdin()
+		if err != nil {
+			break
+		}
+		line = strings.TrimSpace(line)
+		if callee == "std.io.prompt_int" {
+			if n, err := strconv.ParseInt(line, 10, 32); err == nil {
+				return Result{Type: "int", Value: int(n)}, true
+			}
+		} else if f, err := strconv.ParseFloat(line, 64); err == nil {
+			return Result{Type: "float", Value: f}, true
+		}
+	}
+	return def, true
+}
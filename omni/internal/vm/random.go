@@ -0,0 +1,53 @@
+package vm
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// randomAlphabet holds the characters std.math.random_string draws from.
+const randomAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+var (
+	// randomMu guards randomSource, which async tasks may share.
+	randomMu     sync.Mutex
+	randomSource = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// execRandom handles the std.math random intrinsics. std.math.seed replaces
+// the generator, so a seeded program draws the same sequence on every run.
+func execRandom(callee string, operands []mir.Operand, fr *frame) (Result, bool) {
+	ints := make([]int, len(operands))
+	for i, op := range operands {
+		v, ok := operandValue(fr, op).Value.(int)
+		if !ok {
+			return Result{}, false
+		}
+		ints[i] = v
+	}
+	randomMu.Lock()
+	defer randomMu.Unlock()
+	switch {
+	case callee == "std.math.random" && len(ints) == 0:
+		return Result{Type: "float", Value: randomSource.Float64()}, true
+	case callee == "std.math.random_int" && len(ints) == 2:
+		lo, hi := ints[0], ints[1]
+		if hi < lo {
+			return Result{Type: "int", Value: lo}, true
+		}
+		return Result{Type: "int", Value: lo + randomSource.Intn(hi-lo+1)}, true
+	case callee == "std.math.seed" && len(ints) == 1:
+		randomSource = rand.New(rand.NewSource(int64(ints[0])))
+		return Result{Type: "void"}, true
+	case callee == "std.math.random_string" && len(ints) == 1:
+		b := make([]byte, max(ints[0], 0))
+		for i := range b {
+			b[i] = randomAlphabet[randomSource.Intn(len(randomAlphabet))]
+		}
+		return Result{Type: "string", Value: string(b)}, true
+	}
+	return Result{}, false
+}
@@ -0,0 +1,39 @@
+package vm
+
+import "regexp"
+
+// compileRegex compiles a std.regex pattern. Patterns use the POSIX
+// extended syntax and leftmost-longest matching, as regexec does in the C
+// runtime, so that both backends find the same matches.
+func compileRegex(pattern string) (*regexp.Regexp, bool) {
+	re, err := regexp.CompilePOSIX(pattern)
+	return re, err == nil
+}
+
+// execRegex handles the std.regex intrinsics. An invalid pattern matches
+// nothing: find returns null and the others an empty array.
+func execRegex(callee string, pattern, s string) Result {
+	re, ok := compileRegex(pattern)
+	switch callee {
+	case "std.regex.find":
+		if ok {
+			if loc := re.FindStringIndex(s); loc != nil {
+				return Result{Type: "string", Value: s[loc[0]:loc[1]]}
+			}
+		}
+		return Result{Type: "null", Value: nil}
+	case "std.regex.find_all":
+		matches := []string{}
+		if ok {
+			matches = append(matches, re.FindAllString(s, -1)...)
+		}
+		return Result{Type: "[]<string>", Value: matches}
+	default:
+		groups := []string{}
+		if ok {
+			// A group that took no part in the match is empty
+			groups = append(groups, re.FindStringSubmatch(s)...)
+		}
+		return Result{Type: "[]<string>", Value: groups}
+	}
+}
@@ -0,0 +1,76 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestRegexGroupsExtractEmailParts(t *testing.T) {
+	src := `func main():string {
+  let parts:array<string> = std.regex.groups("([[:alnum:]._-]+)@([[:alnum:]-]+)\\.([[:alpha:]]+)", "write to ada.lovelace@analytical-engine.org today")
+  var line:string = parts[0]
+  for i:int = 1; i < len(parts); i++ {
+    line = line + "|" + parts[i]
+  }
+  return line
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	want := "ada.lovelace@analytical-engine.org|ada.lovelace|analytical-engine|org"
+	if res.Value != want {
+		t.Errorf("result = %q, want %q", res.Value, want)
+	}
+}
+
+func TestRegexMatches(t *testing.T) {
+	for expr, want := range map[string]string{
+		// Of the matches starting leftmost the longest wins, as in POSIX
+		`opt.unwrap_or(std.regex.find("a|ab", "xabc"), "none")`:       "ab",
+		`opt.unwrap_or(std.regex.find("[0-9]+", "order 66"), "none")`: "66",
+		`opt.unwrap_or(std.regex.find("z", "abc"), "none")`:           "none",
+		`std.regex.find_all("[0-9]+", "1 22 333")[2]`:                 "333",
+		`std.int_to_string(len(std.regex.find_all("x*", "axxb")))`:    "3",
+		`std.regex.groups("(a)|(b)", "b")[1]`:                         "",
+	} {
+		src := `func main():string {
+  return ` + expr + `
+}
+`
+		res, err := vm.Execute(buildSource(t, src), "main")
+		if err != nil {
+			t.Errorf("%s: execute: %v", expr, err)
+			continue
+		}
+		if res.Value != want {
+			t.Errorf("%s = %q, want %q", expr, res.Value, want)
+		}
+	}
+}
+
+func TestRegexInvalidPatternMatchesNothing(t *testing.T) {
+	src := `func main():int {
+  var failures:int = 0
+  if std.regex.find("(", "(") != null {
+    failures = failures + 1
+  }
+  if len(std.regex.find_all("[a", "a")) != 0 {
+    failures = failures + 1
+  }
+  if len(std.regex.groups("a{2", "aa")) != 0 {
+    failures = failures + 1
+  }
+  return failures
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 0 {
+		t.Errorf("%v invalid patterns matched", res.Value)
+	}
+}
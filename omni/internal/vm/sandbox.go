@@ -0,0 +1,217 @@
+package vm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// SandboxPolicy restricts what a program may do outside the VM. A sandboxed
+// program cannot open network connections, read the environment or touch
+// the file system, except for reading below the AllowRead directories and
+// writing below the AllowWrite ones. It cannot run other programs unless
+// AllowProcess is set.
+type SandboxPolicy struct {
+	AllowRead    []string
+	AllowWrite   []string
+	AllowProcess bool
+}
+
+// SandboxViolationError reports an operation that the sandbox refused.
+type SandboxViolationError struct {
+	// Operation is the instruction or function that was refused.
+	Operation string
+	// Path is the file the operation would have accessed, if any.
+	Path string
+}
+
+func (e SandboxViolationError) Error() string {
+	if e.Path != "" {
+		return fmt.Sprintf("sandbox: %s of %q is not allowed", e.Operation, e.Path)
+	}
+	return fmt.Sprintf("sandbox: %s is not allowed", e.Operation)
+}
+
+// access is what an operation does with a path operand.
+type access int
+
+const (
+	accessRead access = iota
+	accessWrite
+)
+
+// pathOperand is an operand of a guarded operation that names a file.
+type pathOperand struct {
+	index  int
+	access access
+}
+
+// sandboxedPaths lists the operations that access files, with the operands
+// that name them. file.open and std.file.open depend on their mode and are
+// handled separately; std.file.read, write, seek, tell and close work on a
+// handle that an allowed open returned.
+var sandboxedPaths = map[string][]pathOperand{
+	"file.exists":                  {{0, accessRead}},
+	"file.size":                    {{0, accessRead}},
+	"std.file.exists":              {{0, accessRead}},
+	"std.file.size":                {{0, accessRead}},
+	"std.os.exists":                {{0, accessRead}},
+	"std.os.is_file":               {{0, accessRead}},
+	"std.os.is_dir":                {{0, accessRead}},
+	"std.os.read_file":             {{0, accessRead}},
+	"std.os.read_file_async":       {{0, accessRead}},
+	"std.os.write_file":            {{0, accessWrite}},
+	"std.os.write_file_async":      {{0, accessWrite}},
+	"std.os.append_file":           {{0, accessWrite}},
+	"std.os.append_file_async":     {{0, accessWrite}},
+	"std.os.mkdir":                 {{0, accessWrite}},
+	"std.os.rmdir":                 {{0, accessWrite}},
+	"std.os.remove":                {{0, accessWrite}},
+	"std.os.rename":                {{0, accessWrite}, {1, accessWrite}},
+	"std.os.copy":                  {{0, accessRead}, {1, accessWrite}},
+	"std.io.readline_history.load": {{0, accessRead}},
+	"std.io.readline_history.save": {{0, accessWrite}},
+}
+
+// sandboxAllowedOS are the std.os functions a sandboxed program may still
+// call: they only read its own arguments and process IDs, or exit.
+var sandboxAllowedOS = map[string]bool{
+	"std.os.args": true, "std.os.args_count": true, "std.os.has_flag": true,
+	"std.os.get_flag": true, "std.os.positional_arg": true, "std.os.exit": true,
+	"std.os.getpid": true, "std.os.getppid": true,
+}
+
+// allows checks whether the program may run the operation op with the
+// given operands, returning the violation when it may not.
+func (p *SandboxPolicy) allows(fr *frame, op string, operands []mir.Operand) error {
+	if op == "file.open" || op == "std.file.open" {
+		mode := "r"
+		if len(operands) > 1 {
+			mode, _ = toString(operandValue(fr, operands[1]))
+		}
+		acc := accessRead
+		if strings.ContainsAny(mode, "wa+") {
+			acc = accessWrite
+		}
+		return p.allowsPath(fr, op, operands, pathOperand{0, acc})
+	}
+	if paths, ok := sandboxedPaths[op]; ok {
+		return p.allowsPath(fr, op, operands, paths...)
+	}
+	// The rest of std.os, such as the environment and the working
+	// directory, and all of std.network and std.env are refused outright
+	if strings.HasPrefix(op, "std.os.") && !sandboxAllowedOS[op] && !strings.HasPrefix(op, "std.os.signal.") {
+		return SandboxViolationError{Operation: op}
+	}
+	if strings.HasPrefix(op, "std.network.") || strings.HasPrefix(op, "std.env.") {
+		return SandboxViolationError{Operation: op}
+	}
+	// Making a path absolute reads the working directory
+	if op == "std.path.abs" {
+		return SandboxViolationError{Operation: op}
+	}
+	if strings.HasPrefix(op, "std.process.") && !p.AllowProcess {
+		return SandboxViolationError{Operation: op}
+	}
+	return nil
+}
+
+func (p *SandboxPolicy) allowsPath(fr *frame, op string, operands []mir.Operand, paths ...pathOperand) error {
+	for _, path := range paths {
+		if path.index >= len(operands) {
+			continue
+		}
+		name, err := toString(operandValue(fr, operands[path.index]))
+		if err != nil {
+			return SandboxViolationError{Operation: op}
+		}
+		dirs := p.AllowRead
+		if path.access == accessWrite {
+			dirs = p.AllowWrite
+		}
+		if !withinAny(name, dirs) {
+			return SandboxViolationError{Operation: op, Path: name}
+		}
+	}
+	return nil
+}
+
+// withinAny reports whether path is one of dirs or lies below one of them,
+// after resolving symbolic links so that a link cannot lead outside.
+func withinAny(path string, dirs []string) bool {
+	resolved := resolvePath(path)
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(resolvePath(dir), resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath returns the absolute form of path with the symbolic links of
+// its longest existing prefix resolved.
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	var rest []string
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(append([]string{real}, rest...)...)
+		}
+		if _, err := os.Lstat(dir); err == nil || filepath.Dir(dir) == dir {
+			return abs
+		}
+		rest = append([]string{filepath.Base(dir)}, rest...)
+	}
+}
+
+// sandboxHandlers returns instructionHandlers with the file and call
+// instructions wrapped to check policy before they run.
+func sandboxHandlers(policy *SandboxPolicy) map[string]instructionHandler {
+	handlers := make(map[string]instructionHandler, len(instructionHandlers))
+	for op, handler := range instructionHandlers {
+		handlers[op] = handler
+	}
+	for _, op := range []string{"file.open", "file.exists", "file.size"} {
+		if handler, ok := handlers[op]; ok {
+			handlers[op] = guardInstruction(policy, op, handler)
+		}
+	}
+	for _, op := range []string{"call", "call.int", "call.void", "call.string", "call.bool"} {
+		if handler, ok := handlers[op]; ok {
+			handlers[op] = guardCall(policy, handler)
+		}
+	}
+	return handlers
+}
+
+func guardInstruction(policy *SandboxPolicy, op string, handler instructionHandler) instructionHandler {
+	return func(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+		if err := policy.allows(fr, op, inst.Operands); err != nil {
+			return Result{}, err
+		}
+		return handler(funcs, fr, inst)
+	}
+}
+
+func guardCall(policy *SandboxPolicy, handler instructionHandler) instructionHandler {
+	return func(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+		if len(inst.Operands) > 0 && inst.Operands[0].Kind == mir.OperandLiteral {
+			callee := inst.Operands[0].Literal
+			// Standard modules replaced through an import map are
+			// ordinary OmniLang code and need no check
+			if !isOverridden(callee) {
+				if err := policy.allows(fr, callee, inst.Operands[1:]); err != nil {
+					return Result{}, err
+				}
+			}
+		}
+		return handler(funcs, fr, inst)
+	}
+}
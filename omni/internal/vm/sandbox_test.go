@@ -0,0 +1,144 @@
+package vm_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+// runSandboxed runs the main function of src under policy.
+func runSandboxed(t *testing.T, src string, policy *vm.SandboxPolicy) (vm.Result, error) {
+	t.Helper()
+	return vm.ExecuteWithOptions(context.Background(), buildSource(t, src), "main", vm.ExecuteOptions{Sandbox: policy})
+}
+
+func readFileProgram(path string) string {
+	return `import std
+func main():string {
+  return std.os.read_file(` + strconv.Quote(path) + `)
+}
+`
+}
+
+func TestSandboxDeniesReadOutsideAllowedDirs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret.txt")
+	if err := os.WriteFile(path, []byte("secret"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err := runSandboxed(t, readFileProgram(path), &vm.SandboxPolicy{AllowRead: []string{t.TempDir()}})
+	var violation vm.SandboxViolationError
+	if !errors.As(err, &violation) {
+		t.Fatalf("expected a vm.SandboxViolationError, got %v", err)
+	}
+	if violation.Operation != "std.os.read_file" || violation.Path != path {
+		t.Errorf("violation = %+v, want std.os.read_file of %s", violation, path)
+	}
+
+	res, err := runSandboxed(t, readFileProgram(path), &vm.SandboxPolicy{AllowRead: []string{dir}})
+	if err != nil {
+		t.Fatalf("read below an allowed directory: %v", err)
+	}
+	if res.Value != "secret" {
+		t.Errorf("read %v, want secret", res.Value)
+	}
+}
+
+func TestSandboxResolvesSymlinks(t *testing.T) {
+	allowed, outside := t.TempDir(), t.TempDir()
+	target := filepath.Join(outside, "secret.txt")
+	if err := os.WriteFile(target, []byte("secret"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	link := filepath.Join(allowed, "link")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	_, err := runSandboxed(t, readFileProgram(filepath.Join(link, "secret.txt")), &vm.SandboxPolicy{AllowRead: []string{allowed}})
+	if !errors.As(err, new(vm.SandboxViolationError)) {
+		t.Fatalf("expected a link out of the allowed directory to be refused, got %v", err)
+	}
+}
+
+func TestSandboxWriteNeedsAllowWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+	src := `import std
+func main():int {
+  let f:int = std.file.open(` + strconv.Quote(path) + `, "w")
+  std.file.close(f)
+  return 0
+}
+`
+	if _, err := runSandboxed(t, src, &vm.SandboxPolicy{AllowRead: []string{dir}}); !errors.As(err, new(vm.SandboxViolationError)) {
+		t.Fatalf("expected opening for write with only read access to be refused, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("refused open created %s", path)
+	}
+	if _, err := runSandboxed(t, src, &vm.SandboxPolicy{AllowWrite: []string{dir}}); err != nil {
+		t.Fatalf("open for write below an allowed directory: %v", err)
+	}
+}
+
+func TestSandboxDeniesNetworkAndEnvironment(t *testing.T) {
+	for name, call := range map[string]string{
+		"std.network.http_get": `std.network.http_get("http://127.0.0.1:1/")`,
+		"std.os.getenv":        `std.os.getenv("HOME")`,
+		"std.path.abs":         `std.path.abs("notes.txt")`,
+		"std.env.get":          `std.env.get("HOME", "")`,
+	} {
+		src := `import std
+func main():string {
+  return ` + call + `
+}
+`
+		_, err := runSandboxed(t, src, &vm.SandboxPolicy{})
+		var violation vm.SandboxViolationError
+		if !errors.As(err, &violation) || violation.Operation != name {
+			t.Errorf("%s: expected a vm.SandboxViolationError, got %v", name, err)
+		}
+	}
+}
+
+func TestSandboxAllowsPureCode(t *testing.T) {
+	src := `import std
+func main():int {
+  return std.os.args_count() + 2
+}
+`
+	res, err := runSandboxed(t, src, &vm.SandboxPolicy{})
+	if err != nil {
+		t.Fatalf("sandboxed run: %v", err)
+	}
+	if res.Value != 2 {
+		t.Errorf("result = %v, want 2", res.Value)
+	}
+}
+
+func TestSandboxProcessNeedsAllowProcess(t *testing.T) {
+	src := `import std
+func main():string {
+  return std.process.run("echo", ["hi"]).stdout
+}
+`
+	_, err := runSandboxed(t, src, &vm.SandboxPolicy{})
+	var violation vm.SandboxViolationError
+	if !errors.As(err, &violation) || violation.Operation != "std.process.run" {
+		t.Fatalf("expected a vm.SandboxViolationError, got %v", err)
+	}
+	res, err := runSandboxed(t, src, &vm.SandboxPolicy{AllowProcess: true})
+	if err != nil {
+		t.Fatalf("sandboxed run with AllowProcess: %v", err)
+	}
+	if res.Value != "hi\n" {
+		t.Errorf("result = %q, want %q", res.Value, "hi\n")
+	}
+}
@@ -0,0 +1,313 @@
+package vm
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+var (
+	// Async/Promise support
+	promiseMu      sync.RWMutex
+	promiseCounter int
+	promises       = make(map[int]*Promise)
+	eventLoop      = &scheduler{wake: make(chan struct{}, 1)}
+)
+
+// Promise represents an async operation that may complete in the future
+type Promise struct {
+	ID    int
+	Value Result
+	Error error
+	Done  bool
+	// external promises are settled by a goroutine outside the scheduler,
+	// such as the file read of std.os.read_file_async; the others belong to
+	// a coroutine and settle when it returns
+	external bool
+	waiters  []*coroutine // Coroutines suspended until the promise settles
+	mu       sync.Mutex
+}
+
+// coroutine is a call of an async function. Its promise settles with the
+// result of the call.
+type coroutine struct {
+	funcs   map[string]*mir.Function
+	k       *continuation
+	promise int
+}
+
+// scheduler runs coroutines cooperatively on the goroutine that executes
+// the program. A coroutine runs until it returns or awaits a promise that
+// is still pending; it then waits off the ready queue until the promise
+// settles.
+type scheduler struct {
+	mu    sync.Mutex
+	ready []*coroutine
+	// external counts the external promises that are still pending
+	external int
+	// wake is signalled when an external promise settles, so that a
+	// scheduler with nothing ready stops waiting
+	wake chan struct{}
+}
+
+// reset discards the coroutines and promises of an earlier run, so that
+// I/O it left pending cannot wake this one.
+func (s *scheduler) reset() {
+	promiseMu.Lock()
+	promises = make(map[int]*Promise)
+	promiseMu.Unlock()
+	s.mu.Lock()
+	s.ready = nil
+	s.external = 0
+	s.mu.Unlock()
+	select {
+	case <-s.wake:
+	default:
+	}
+}
+
+// spawn starts a call of the async function fn and returns its promise.
+// The call does not run until the scheduler reaches it.
+func (s *scheduler) spawn(funcs map[string]*mir.Function, fn *mir.Function, args []Result) int {
+	id := addPromise(false)
+	co := &coroutine{funcs: funcs, promise: id}
+	k, err := newContinuation(fn, args)
+	if err != nil {
+		rejectPromise(id, withFrame(fn.Name, err))
+		return id
+	}
+	co.k = k
+	s.schedule(co)
+	return id
+}
+
+func (s *scheduler) schedule(cos ...*coroutine) {
+	if len(cos) == 0 {
+		return
+	}
+	s.mu.Lock()
+	s.ready = append(s.ready, cos...)
+	s.mu.Unlock()
+}
+
+// runOne resumes the next ready coroutine and reports whether there was one.
+func (s *scheduler) runOne() bool {
+	s.mu.Lock()
+	if len(s.ready) == 0 {
+		s.mu.Unlock()
+		return false
+	}
+	co := s.ready[0]
+	s.ready = s.ready[1:]
+	s.mu.Unlock()
+
+	res, suspended, err := co.k.execute(co.funcs, co)
+	switch {
+	case err != nil:
+		rejectPromise(co.promise, err)
+	case !suspended:
+		resolvePromise(co.promise, res)
+	}
+	return true
+}
+
+// settled records that an external promise is no longer pending.
+func (s *scheduler) settled() {
+	s.mu.Lock()
+	s.external--
+	s.mu.Unlock()
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// wait blocks until a coroutine is ready or an external promise settles. It
+// fails when neither can happen, as then every coroutine waits for another
+// and none can go on.
+func (s *scheduler) wait(id int) error {
+	s.mu.Lock()
+	ready, pending := len(s.ready), s.external
+	s.mu.Unlock()
+	if ready > 0 {
+		return nil
+	}
+	if pending == 0 {
+		return fmt.Errorf("await: promise %d can never settle, every coroutine is waiting", id)
+	}
+	execCtxMu.RLock()
+	ctx := execCtx
+	execCtxMu.RUnlock()
+	select {
+	case <-s.wake:
+		return nil
+	case <-ctx.Done():
+		return TimeoutError{Err: ctx.Err()}
+	}
+}
+
+// run executes the entry function as the first coroutine and drives the
+// scheduler until its ready queue is empty. Coroutines still waiting for
+// an external promise once the entry has returned are abandoned, as a
+// process exits without waiting for its pending I/O.
+func (s *scheduler) run(funcs map[string]*mir.Function, fn *mir.Function) (Result, error) {
+	id := s.spawn(funcs, fn, nil)
+	res, err := awaitPromise(id)
+	for s.runOne() {
+	}
+	return res, err
+}
+
+// newPromise creates a new external promise and returns its ID
+func newPromise() int {
+	return addPromise(true)
+}
+
+func addPromise(external bool) int {
+	promiseMu.Lock()
+	promiseCounter++
+	id := promiseCounter
+	promises[id] = &Promise{ID: id, external: external}
+	promiseMu.Unlock()
+	if external {
+		eventLoop.mu.Lock()
+		eventLoop.external++
+		eventLoop.mu.Unlock()
+	}
+	return id
+}
+
+func lookupPromise(id int) (*Promise, bool) {
+	promiseMu.RLock()
+	defer promiseMu.RUnlock()
+	promise, ok := promises[id]
+	return promise, ok
+}
+
+// resolvePromise resolves a promise with a value
+func resolvePromise(id int, value Result) {
+	settlePromise(id, value, nil)
+}
+
+// rejectPromise rejects a promise with an error
+func rejectPromise(id int, err error) {
+	settlePromise(id, Result{}, err)
+}
+
+// settlePromise completes a promise and moves the coroutines waiting for
+// it to the ready queue. It may be called from any goroutine.
+func settlePromise(id int, value Result, err error) {
+	promise, ok := lookupPromise(id)
+	if !ok {
+		return
+	}
+	promise.mu.Lock()
+	if promise.Done {
+		promise.mu.Unlock()
+		return
+	}
+	promise.Value = value
+	promise.Error = err
+	promise.Done = true
+	waiters := promise.waiters
+	promise.waiters = nil
+	promise.mu.Unlock()
+
+	eventLoop.schedule(waiters...)
+	if promise.external {
+		eventLoop.settled()
+	}
+}
+
+// suspend adds co to the waiters of the promise unless it has settled
+// already, and reports whether it did.
+func (p *Promise) suspend(co *coroutine) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Done {
+		return false
+	}
+	p.waiters = append(p.waiters, co)
+	return true
+}
+
+func (p *Promise) result() (Result, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.Value, p.Done, p.Error
+}
+
+// awaitPromise waits for a promise to resolve outside a coroutine, such as
+// in a function an async function called, by running the other coroutines
+// until the promise settles.
+func awaitPromise(id int) (Result, error) {
+	promise, ok := lookupPromise(id)
+	if !ok {
+		return Result{}, fmt.Errorf("promise %d not found", id)
+	}
+	for {
+		value, done, err := promise.result()
+		if done {
+			return value, err
+		}
+		if eventLoop.runOne() {
+			continue
+		}
+		if err := eventLoop.wait(id); err != nil {
+			return Result{}, err
+		}
+	}
+}
+
+// awaitedPromise returns the promise that the await instruction inst
+// waits for, if its operand is one.
+func awaitedPromise(fr *frame, inst mir.Instruction) (*Promise, bool) {
+	if len(inst.Operands) == 0 {
+		return nil, false
+	}
+	id, ok := promiseID(operandValue(fr, inst.Operands[0]))
+	if !ok {
+		return nil, false
+	}
+	return lookupPromise(id)
+}
+
+// promiseID extracts the ID of a Promise value.
+func promiseID(operand Result) (int, bool) {
+	if operand.Type != "Promise" {
+		return 0, false
+	}
+	// Extract promise ID from value
+	if id, ok := operand.Value.(int); ok {
+		return id, true
+	}
+	// If value is a map with promise data, extract ID
+	if promiseMap, ok := operand.Value.(map[string]interface{}); ok {
+		if id, ok := promiseMap["id"].(int); ok {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// execAwait handles await instructions
+func execAwait(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	if len(inst.Operands) == 0 {
+		return Result{}, fmt.Errorf("await instruction requires operand")
+	}
+
+	operand := operandValue(fr, inst.Operands[0])
+
+	// Check if operand is a Promise
+	if operand.Type == "Promise" {
+		id, ok := promiseID(operand)
+		if !ok {
+			return Result{}, fmt.Errorf("await: invalid promise value")
+		}
+		return awaitPromise(id)
+	}
+
+	// If not a Promise, return as-is (for now - this allows awaiting non-promises for compatibility)
+	return operand, nil
+}
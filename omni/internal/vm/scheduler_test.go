@@ -0,0 +1,61 @@
+package vm_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestAwaitTwoFileReads(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.txt"), filepath.Join(dir, "second.txt")
+	if err := os.WriteFile(first, []byte("one"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(second, []byte("two"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	// Both reads start before either is awaited, and each async function
+	// suspends at its await while the other runs
+	mod := buildSource(t, `import std
+async func load(path:string):string {
+  let content:string = await std.os.read_file_async(path)
+  return content + "!"
+}
+async func main():string {
+  let a:Promise<string> = load(`+strconv.Quote(first)+`)
+  let b:Promise<string> = load(`+strconv.Quote(second)+`)
+  let x:string = await a
+  let y:string = await b
+  return x + y
+}
+`)
+	res, err := vm.Execute(mod, "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != "one!two!" {
+		t.Errorf("result = %v, want one!two!", res.Value)
+	}
+}
+
+func TestAwaitRejectedPromise(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+	mod := buildSource(t, `import std
+async func load(path:string):string {
+  return await std.os.read_file_async(path)
+}
+async func main():string {
+  return await load(`+strconv.Quote(missing)+`)
+}
+`)
+	_, err := vm.Execute(mod, "main")
+	if err == nil || !strings.Contains(err.Error(), "missing.txt") {
+		t.Fatalf("expected the read error, got %v", err)
+	}
+}
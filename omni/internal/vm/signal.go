@@ -0,0 +1,142 @@
+package vm
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// signalHandler is an Omni function value registered with std.os.signal.trap
+// together with the function table it must be resolved against.
+type signalHandler struct {
+	funcs   map[string]*mir.Function
+	handler Result
+}
+
+var (
+	signalMu       sync.Mutex
+	signalHandled  = sync.NewCond(&signalMu)
+	signalHandlers = map[os.Signal]signalHandler{}
+	signalCounts   = map[os.Signal]int{} // handler runs per signal, used by raise
+	signalCh       chan os.Signal
+)
+
+// signalRaiseTimeout bounds how long raise waits for a trapped handler.
+const signalRaiseTimeout = 2 * time.Second
+
+// execSignal handles the std.os.signal intrinsics. It is separate from
+// execIntrinsic because registering a handler needs the function table.
+func execSignal(funcs map[string]*mir.Function, callee string, operands []mir.Operand, fr *frame) (Result, bool) {
+	switch callee {
+	case "std.os.signal.trap":
+		if len(operands) >= 2 {
+			if sig, ok := omniSignal(operandValue(fr, operands[0])); ok {
+				trapSignal(sig, signalHandler{funcs: funcs, handler: operandValue(fr, operands[1])})
+			}
+		}
+		return Result{Type: "void", Value: nil}, true
+	case "std.os.signal.raise":
+		if len(operands) >= 1 {
+			if sig, ok := omniSignal(operandValue(fr, operands[0])); ok {
+				if err := raiseAndWait(sig); err != nil {
+					fmt.Fprintf(os.Stderr, "std.os.signal.raise: %v\n", err)
+				}
+			}
+		}
+		return Result{Type: "void", Value: nil}, true
+	}
+	return Result{}, false
+}
+
+func omniSignal(value Result) (os.Signal, bool) {
+	signum, ok := value.Value.(int)
+	if !ok {
+		return nil, false
+	}
+	return platformSignal(signum)
+}
+
+// trapSignal installs h for sig, replacing any earlier handler. Notifications
+// are delivered to a single background goroutine, so handlers never run
+// concurrently with each other.
+func trapSignal(sig os.Signal, h signalHandler) {
+	signalMu.Lock()
+	defer signalMu.Unlock()
+	if signalCh == nil {
+		signalCh = make(chan os.Signal, 8)
+		go dispatchSignals(signalCh)
+	}
+	signalHandlers[sig] = h
+	signal.Notify(signalCh, sig)
+}
+
+func dispatchSignals(ch <-chan os.Signal) {
+	for sig := range ch {
+		signalMu.Lock()
+		h, ok := signalHandlers[sig]
+		signalMu.Unlock()
+		if ok {
+			runSignalHandler(h)
+		}
+		signalMu.Lock()
+		signalCounts[sig]++
+		signalMu.Unlock()
+		signalHandled.Broadcast()
+	}
+}
+
+// raiseAndWait sends sig to the current process. When sig is trapped it waits
+// for the handler to finish, so raise behaves as it does in C, where the
+// handler runs before raise returns.
+func raiseAndWait(sig os.Signal) error {
+	signalMu.Lock()
+	_, trapped := signalHandlers[sig]
+	before := signalCounts[sig]
+	signalMu.Unlock()
+
+	if err := raiseSignal(sig); err != nil || !trapped {
+		return err
+	}
+
+	deadline := time.AfterFunc(signalRaiseTimeout, signalHandled.Broadcast)
+	defer deadline.Stop()
+	start := time.Now()
+	signalMu.Lock()
+	defer signalMu.Unlock()
+	for signalCounts[sig] == before {
+		if time.Since(start) >= signalRaiseTimeout {
+			return fmt.Errorf("timed out waiting for %v handler", sig)
+		}
+		signalHandled.Wait()
+	}
+	return nil
+}
+
+// runSignalHandler calls the handler through execFuncCall, as if the program
+// had invoked the function value itself. std.os.exit inside a handler exits
+// the process, which is how SIGINT handlers implement clean shutdown.
+func runSignalHandler(h signalHandler) {
+	defer func() {
+		if r := recover(); r != nil {
+			if exit, ok := r.(exitSignal); ok {
+				os.Exit(exit.code)
+			}
+			panic(r)
+		}
+	}()
+	const handlerValue mir.ValueID = 0
+	fr := &frame{values: map[mir.ValueID]Result{handlerValue: h.handler}}
+	inst := mir.Instruction{
+		ID:       mir.InvalidValue,
+		Op:       "func.call",
+		Type:     "void",
+		Operands: []mir.Operand{{Kind: mir.OperandValue, Value: handlerValue}},
+	}
+	if _, err := execFuncCall(h.funcs, fr, inst); err != nil {
+		fmt.Fprintf(os.Stderr, "vm: signal handler: %v\n", err)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package vm
+
+import (
+	"os"
+	"syscall"
+)
+
+// platformSignal maps the POSIX signal numbers exposed by std.os.signal to
+// the host's signals, whose numbering differs between Unix flavours.
+func platformSignal(signum int) (os.Signal, bool) {
+	switch signum {
+	case 2:
+		return syscall.SIGINT, true
+	case 10:
+		return syscall.SIGUSR1, true
+	case 15:
+		return syscall.SIGTERM, true
+	}
+	return nil, false
+}
+
+func raiseSignal(sig os.Signal) error {
+	return syscall.Kill(os.Getpid(), sig.(syscall.Signal))
+}
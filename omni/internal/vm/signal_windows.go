@@ -0,0 +1,25 @@
+//go:build windows
+
+package vm
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// platformSignal maps the POSIX signal numbers exposed by std.os.signal to
+// the signals Go can observe on Windows. SIGUSR1 has no equivalent.
+func platformSignal(signum int) (os.Signal, bool) {
+	switch signum {
+	case 2:
+		return os.Interrupt, true
+	case 15:
+		return syscall.SIGTERM, true
+	}
+	return nil, false
+}
+
+func raiseSignal(sig os.Signal) error {
+	return fmt.Errorf("raising %v is not supported on windows", sig)
+}
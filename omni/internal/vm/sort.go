@@ -0,0 +1,57 @@
+package vm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// execSort handles the std.sort intrinsics. The array is sorted in place:
+// the slice shares its elements with the value the caller holds, so the
+// caller sees them in order afterwards.
+func execSort(callee string, operands []mir.Operand, fr *frame) (Result, bool, error) {
+	switch callee {
+	case "std.sort.ints", "std.sort.strings", "std.sort.floats", "std.sort.reverse_ints", "std.sort.reverse_strings":
+	default:
+		return Result{}, false, nil
+	}
+	name := callee[len("std."):]
+	if len(operands) != 1 {
+		return Result{}, true, fmt.Errorf("%s: expected 1 argument, got %d", name, len(operands))
+	}
+	arg := operandValue(fr, operands[0])
+	ok := false
+	switch arr := arg.Value.(type) {
+	case []int:
+		switch callee {
+		case "std.sort.ints":
+			sort.Ints(arr)
+			ok = true
+		case "std.sort.reverse_ints":
+			sort.Sort(sort.Reverse(sort.IntSlice(arr)))
+			ok = true
+		}
+	case []string:
+		switch callee {
+		case "std.sort.strings":
+			sort.Strings(arr)
+			ok = true
+		case "std.sort.reverse_strings":
+			sort.Sort(sort.Reverse(sort.StringSlice(arr)))
+			ok = true
+		}
+	case []float64:
+		if callee == "std.sort.floats" {
+			sort.Float64s(arr)
+			ok = true
+		}
+	case []interface{}:
+		// An empty array literal has no element type and nothing to sort
+		ok = len(arr) == 0
+	}
+	if !ok {
+		return Result{}, true, fmt.Errorf("%s: cannot sort a value of type %s", name, arg.Type)
+	}
+	return Result{Type: "void"}, true, nil
+}
@@ -0,0 +1,49 @@
+package vm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestSortIntsInPlace(t *testing.T) {
+	src := `func main():string {
+  let arr:array<int> = [3, 1, 4, 1, 5, 9, 2, 6]
+  std.sort.ints(arr)
+  var out:string = ""
+  for i:int = 0; i < len(arr); i++ {
+    out = out + std.int_to_string(arr[i])
+  }
+  std.sort.reverse_ints(arr)
+  return out + " " + std.int_to_string(arr[0]) + std.int_to_string(arr[7])
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != "11234569 91" {
+		t.Errorf("result = %q, want %q", res.Value, "11234569 91")
+	}
+}
+
+func TestSortStringsAndFloats(t *testing.T) {
+	src := `func main():string {
+  let names:array<string> = ["b", "B", "a", "ab"]
+  std.sort.strings(names)
+  let order:string = names[0] + names[1] + names[2] + names[3]
+  std.sort.reverse_strings(names)
+  let xs:array<float> = [2.5, -1.0, 0.0]
+  std.sort.floats(xs)
+  return order + " " + names[0] + " " + std.float_to_string(xs[0])
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got, _ := res.Value.(string); !strings.HasPrefix(got, "Baabb b -1") {
+		t.Errorf("result = %q, want prefix %q", got, "Baabb b -1")
+	}
+}
@@ -0,0 +1,148 @@
+package vm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// execStringPad handles std.string.repeat and the padding functions. Lengths
+// and widths count bytes, as std.string.length does; a negative count or a
+// padding that is not a single character is an error.
+func execStringPad(callee string, operands []mir.Operand, fr *frame) (Result, bool, error) {
+	want := 3
+	switch callee {
+	case "std.string.repeat":
+		want = 2
+	case "std.string.pad_left", "std.string.pad_right", "std.string.center":
+	default:
+		return Result{}, false, nil
+	}
+	name := callee[len("std."):]
+	if len(operands) != want {
+		return Result{}, true, fmt.Errorf("%s: expected %d arguments, got %d", name, want, len(operands))
+	}
+	s, err := toString(operandValue(fr, operands[0]))
+	if err != nil {
+		return Result{}, true, fmt.Errorf("%s: %w", name, err)
+	}
+	n, ok := operandValue(fr, operands[1]).Value.(int)
+	if !ok {
+		return Result{}, true, fmt.Errorf("%s: expected an int, got %s", name, operandValue(fr, operands[1]).Type)
+	}
+
+	if callee == "std.string.repeat" {
+		if n < 0 {
+			return Result{}, true, fmt.Errorf("string.repeat: negative count %d", n)
+		}
+		return Result{Type: "string", Value: strings.Repeat(s, n)}, true, nil
+	}
+
+	char, err := toString(operandValue(fr, operands[2]))
+	if err != nil {
+		return Result{}, true, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(char) != 1 {
+		return Result{}, true, fmt.Errorf("%s: padding must be a single character, got %q", name, char)
+	}
+	padding := n - len(s)
+	if padding <= 0 {
+		return Result{Type: "string", Value: s}, true, nil
+	}
+	switch callee {
+	case "std.string.pad_left":
+		s = strings.Repeat(char, padding) + s
+	case "std.string.pad_right":
+		s += strings.Repeat(char, padding)
+	default:
+		// The extra character of an odd padding goes on the right
+		left := padding / 2
+		s = strings.Repeat(char, left) + s + strings.Repeat(char, padding-left)
+	}
+	return Result{Type: "string", Value: s}, true, nil
+}
+
+// execStringReplace handles std.string.replace, replace_all and
+// count_occurrences. Occurrences do not overlap and are found from the left;
+// an empty pattern matches nothing, so s is returned as it is.
+func execStringReplace(callee string, operands []mir.Operand, fr *frame) (Result, bool, error) {
+	want := 3
+	switch callee {
+	case "std.string.count_occurrences":
+		want = 2
+	case "std.string.replace", "std.string.replace_all":
+	default:
+		return Result{}, false, nil
+	}
+	name := callee[len("std."):]
+	if len(operands) != want {
+		return Result{}, true, fmt.Errorf("%s: expected %d arguments, got %d", name, want, len(operands))
+	}
+	args := make([]string, len(operands))
+	for i, op := range operands {
+		s, err := toString(operandValue(fr, op))
+		if err != nil {
+			return Result{}, true, fmt.Errorf("%s: %w", name, err)
+		}
+		args[i] = s
+	}
+
+	s, old := args[0], args[1]
+	switch {
+	case callee == "std.string.count_occurrences":
+		count := 0
+		if old != "" {
+			count = strings.Count(s, old)
+		}
+		return Result{Type: "int", Value: count}, true, nil
+	case old == "":
+		return Result{Type: "string", Value: s}, true, nil
+	case callee == "std.string.replace":
+		return Result{Type: "string", Value: strings.Replace(s, old, args[2], 1)}, true, nil
+	default:
+		return Result{Type: "string", Value: strings.ReplaceAll(s, old, args[2])}, true, nil
+	}
+}
+
+// execStringParse handles std.string.parse_int, parse_float and parse_bool,
+// which return a tuple of the value and whether s held one, with the zero
+// value when it did not. Ints are parsed as 32 bits, the width of int in the
+// C backend.
+func execStringParse(callee string, operands []mir.Operand, fr *frame) (Result, bool, error) {
+	switch callee {
+	case "std.string.parse_int", "std.string.parse_float", "std.string.parse_bool":
+	default:
+		return Result{}, false, nil
+	}
+	name := callee[len("std."):]
+	if len(operands) != 1 {
+		return Result{}, true, fmt.Errorf("%s: expected 1 argument, got %d", name, len(operands))
+	}
+	s, err := toString(operandValue(fr, operands[0]))
+	if err != nil {
+		return Result{}, true, fmt.Errorf("%s: %w", name, err)
+	}
+
+	parsed := func(typ string, value interface{}, ok bool) Result {
+		return Result{Type: "tuple<" + typ + ",bool>", Value: map[string]interface{}{"0": value, "1": ok}}
+	}
+	switch callee {
+	case "std.string.parse_int":
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return parsed("int", 0, false), true, nil
+		}
+		return parsed("int", int(n), true), true, nil
+	case "std.string.parse_float":
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return parsed("float", 0.0, false), true, nil
+		}
+		return parsed("float", f, true), true, nil
+	default:
+		b, err := strconv.ParseBool(s)
+		return parsed("bool", b, err == nil), true, nil
+	}
+}
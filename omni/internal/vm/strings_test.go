@@ -0,0 +1,200 @@
+package vm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestStringSplitCSVRoundTrip(t *testing.T) {
+	src := `import std
+func main():string {
+  let fields:array<string> = std.string.split("id,name,,score", ",")
+  var line:string = fields[0]
+  for i:int = 1; i < len(fields); i++ {
+    line = line + "," + fields[i]
+  }
+  return line + "|" + fields[3] + "|" + std.string.split("añb", "")[1]
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != "id,name,,score|score|ñ" {
+		t.Errorf("result = %q, want the CSV line back, then its last field and ñ", res.Value)
+	}
+}
+
+func TestStringFormat(t *testing.T) {
+	for call, want := range map[string]string{
+		`std.string.format("{} is {:d}", "ada", 36)`:             "ada is 36",
+		`std.string.format("{:f} {} {:f}", 2.5, 1.25, 3)`:        "2.500000 1.25 3.000000",
+		`std.string.format("{:s}={}", "ok", true)`:               "ok=true",
+		`std.string.format("{{}} {{{}}} }}{{", 7)`:               "{} {7} }{",
+		`std.string.format("no placeholders")`:                   "no placeholders",
+		`std.string.format("{}{}", std.string.length("ab"), "")`: "2",
+	} {
+		src := `import std
+func main():string {
+  return ` + call + `
+}
+`
+		res, err := vm.Execute(buildSource(t, src), "main")
+		if err != nil {
+			t.Errorf("%s: execute: %v", call, err)
+			continue
+		}
+		if res.Value != want {
+			t.Errorf("%s = %q, want %q", call, res.Value, want)
+		}
+	}
+}
+
+func TestStringFormatRejectsMalformedTemplates(t *testing.T) {
+	for call, want := range map[string]string{
+		`std.string.format("{:d}", 1.5)`:   "format: {:d} cannot format a float",
+		`std.string.format("{:x}", 1)`:     "format: unknown placeholder {:x}",
+		`std.string.format("{} {}", 1)`:    "format: placeholder {} has no argument",
+		`std.string.format("{}", 1, 2, 3)`: "format: 2 arguments left after the last placeholder",
+		`std.string.format("a } b")`:       "format: unmatched } at offset 2",
+		`std.string.format("{:s", "x")`:    "format: unterminated placeholder at offset 0",
+	} {
+		src := `import std
+func main():string {
+  return ` + call + `
+}
+`
+		_, err := vm.Execute(buildSource(t, src), "main")
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected error %q, got %v", call, want, err)
+		}
+	}
+}
+
+func TestStringRepeatAndPad(t *testing.T) {
+	for call, want := range map[string]string{
+		`std.string.pad_left("hi", 5, " ")`:  "   hi",
+		`std.string.pad_right("hi", 5, ".")`: "hi...",
+		`std.string.center("hi", 7, "*")`:    "**hi***",
+		`std.string.center("long", 2, "*")`:  "long",
+		`std.string.repeat("ab", 3)`:         "ababab",
+		`std.string.repeat("ab", 0)`:         "",
+	} {
+		src := `func main():string {
+  return ` + call + `
+}
+`
+		res, err := vm.Execute(buildSource(t, src), "main")
+		if err != nil {
+			t.Errorf("%s: execute: %v", call, err)
+			continue
+		}
+		if res.Value != want {
+			t.Errorf("%s = %q, want %q", call, res.Value, want)
+		}
+	}
+}
+
+func TestStringRepeatAndPadRejectInvalidArguments(t *testing.T) {
+	for call, want := range map[string]string{
+		`std.string.repeat("ab", -1)`:        "string.repeat: negative count -1",
+		`std.string.pad_left("hi", 5, "ab")`: `string.pad_left: padding must be a single character, got "ab"`,
+		`std.string.center("hi", 5, "")`:     `string.center: padding must be a single character, got ""`,
+	} {
+		src := `func main():string {
+  return ` + call + `
+}
+`
+		_, err := vm.Execute(buildSource(t, src), "main")
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected error %q, got %v", call, want, err)
+		}
+	}
+}
+
+func TestStringReplace(t *testing.T) {
+	for call, want := range map[string]interface{}{
+		`std.string.replace("a-b-c", "-", "+")`:         "a+b-c",
+		`std.string.replace_all("a-b-c", "-", "+")`:     "a+b+c",
+		`std.string.replace_all("aaaa", "aa", "b")`:     "bb",
+		`std.string.replace_all("aaa", "aa", "b")`:      "ba",
+		`std.string.replace_all("abc", "", "-")`:        "abc",
+		`std.string.replace("abc", "", "-")`:            "abc",
+		`std.string.replace_all("a b", " ", "")`:        "ab",
+		`std.string.replace_all("", "a", "b")`:          "",
+		`std.string.count_occurrences("aaaa", "aa")`:    2,
+		`std.string.count_occurrences("banana", "ana")`: 1,
+		`std.string.count_occurrences("abc", "")`:       0,
+		`std.string.count_occurrences("", "a")`:         0,
+	} {
+		resultType := "string"
+		if _, ok := want.(int); ok {
+			resultType = "int"
+		}
+		src := `func main():` + resultType + ` {
+  return ` + call + `
+}
+`
+		res, err := vm.Execute(buildSource(t, src), "main")
+		if err != nil {
+			t.Errorf("%s: execute: %v", call, err)
+			continue
+		}
+		if res.Value != want {
+			t.Errorf("%s = %v, want %v", call, res.Value, want)
+		}
+	}
+}
+
+func TestStringParse(t *testing.T) {
+	for _, tc := range []struct {
+		call  string
+		value interface{}
+		ok    bool
+	}{
+		{`std.string.parse_int("42")`, 42, true},
+		{`std.string.parse_int("-7")`, -7, true},
+		{`std.string.parse_int("+3")`, 3, true},
+		{`std.string.parse_int(" 1")`, 0, false},
+		{`std.string.parse_int("1.5")`, 0, false},
+		{`std.string.parse_int("abc")`, 0, false},
+		{`std.string.parse_int("")`, 0, false},
+		{`std.string.parse_int("99999999999")`, 0, false},
+		{`std.string.parse_float("3.5")`, 3.5, true},
+		{`std.string.parse_float("1e3")`, 1000.0, true},
+		{`std.string.parse_float("-0.25")`, -0.25, true},
+		{`std.string.parse_float("x")`, 0.0, false},
+		{`std.string.parse_float("1.5x")`, 0.0, false},
+		{`std.string.parse_bool("true")`, true, true},
+		{`std.string.parse_bool("F")`, false, true},
+		{`std.string.parse_bool("0")`, false, true},
+		{`std.string.parse_bool("yes")`, false, false},
+	} {
+		valueType := "int"
+		switch tc.value.(type) {
+		case float64:
+			valueType = "float"
+		case bool:
+			valueType = "bool"
+		}
+		src := `func main():(` + valueType + `, bool) {
+  return ` + tc.call + `
+}
+`
+		res, err := vm.Execute(buildSource(t, src), "main")
+		if err != nil {
+			t.Errorf("%s: execute: %v", tc.call, err)
+			continue
+		}
+		fields, ok := res.Value.(map[string]interface{})
+		if !ok {
+			t.Errorf("%s = %#v, want a tuple", tc.call, res.Value)
+			continue
+		}
+		if fields["0"] != tc.value || fields["1"] != tc.ok {
+			t.Errorf("%s = (%v, %v), want (%v, %v)", tc.call, fields["0"], fields["1"], tc.value, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package vm
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// testFilter selects the tests of a run by name, for omnir -test -run.
+var testFilter struct {
+	mu      sync.Mutex
+	pattern *regexp.Regexp
+	matched int
+}
+
+// setTestFilter makes pattern select the tests that run; nil runs every
+// test. It resets the count MatchedTests reports.
+func setTestFilter(pattern *regexp.Regexp) {
+	testFilter.mu.Lock()
+	defer testFilter.mu.Unlock()
+	testFilter.pattern = pattern
+	testFilter.matched = 0
+}
+
+// MatchedTests returns how many tests of the last run with a
+// ExecuteOptions.TestFilter matched it.
+func MatchedTests() int {
+	testFilter.mu.Lock()
+	defer testFilter.mu.Unlock()
+	return testFilter.matched
+}
+
+// runsTest reports whether the test called name runs under the current
+// filter, counting it if the filter selects it.
+func runsTest(name string) bool {
+	testFilter.mu.Lock()
+	defer testFilter.mu.Unlock()
+	if testFilter.pattern == nil {
+		return true
+	}
+	if !testFilter.pattern.MatchString(name) {
+		return false
+	}
+	testFilter.matched++
+	return true
+}
+
+// isTestEnd reports whether inst ends a test, as test.end or as a call of
+// std.test.end.
+func isTestEnd(inst mir.Instruction) bool {
+	if inst.Op == "test.end" {
+		return true
+	}
+	return inst.Op == "call" && len(inst.Operands) > 0 && inst.Operands[0].Kind == mir.OperandLiteral && inst.Operands[0].Literal == "std.test.end"
+}
+
+// nextTestEnd finds the first test end after instruction index of block in
+// fn, searching the rest of block and then the blocks after it. It returns
+// the block and the index of the test end.
+func nextTestEnd(fn *mir.Function, block *mir.BasicBlock, index int) (*mir.BasicBlock, int, bool) {
+	found := false
+	for _, candidate := range fn.Blocks {
+		if candidate == block {
+			found = true
+		}
+		if !found {
+			continue
+		}
+		start := 0
+		if candidate == block {
+			start = index + 1
+		}
+		for i := start; i < len(candidate.Instructions); i++ {
+			if isTestEnd(candidate.Instructions[i]) {
+				return candidate, i, true
+			}
+		}
+	}
+	return nil, 0, false
+}
@@ -0,0 +1,69 @@
+package vm_test
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+// filteredTestModule returns main running the tests named names with
+// test.start and test.end, each adding 1 to the result. Without end, the
+// last test has no test.end.
+func filteredTestModule(end bool, names ...string) *mir.Module {
+	fn := mir.NewFunction("main", "int", nil)
+	entry := fn.NewBlock("entry")
+	count := fn.NextValue()
+	one := mir.Operand{Kind: mir.OperandLiteral, Literal: "1", Type: "int"}
+	entry.Instructions = append(entry.Instructions, mir.Instruction{ID: count, Op: "const", Type: "int", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "0", Type: "int"}}})
+	counter := mir.Operand{Kind: mir.OperandValue, Value: count, Type: "int"}
+	for i, name := range names {
+		testName := mir.Operand{Kind: mir.OperandLiteral, Literal: name, Type: "string"}
+		next := fn.NextValue()
+		entry.Instructions = append(entry.Instructions,
+			mir.Instruction{ID: mir.InvalidValue, Op: "test.start", Type: "void", Operands: []mir.Operand{testName}},
+			mir.Instruction{ID: next, Op: "add", Type: "int", Operands: []mir.Operand{counter, one}},
+			mir.Instruction{ID: fn.NextValue(), Op: "assign", Type: "int", Operands: []mir.Operand{counter, {Kind: mir.OperandValue, Value: next, Type: "int"}}},
+		)
+		if end || i < len(names)-1 {
+			entry.Instructions = append(entry.Instructions, mir.Instruction{ID: mir.InvalidValue, Op: "test.end", Type: "void", Operands: []mir.Operand{
+				testName, {Kind: mir.OperandLiteral, Literal: "true", Type: "bool"},
+			}})
+		}
+	}
+	entry.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{counter}}
+	return &mir.Module{Functions: []*mir.Function{fn}}
+}
+
+func TestTestFilterSkipsOtherTests(t *testing.T) {
+	mod := filteredTestModule(true, "parse_ints", "lex_idents", "parse_floats")
+	res, err := vm.ExecuteWithOptions(context.Background(), mod, "main", vm.ExecuteOptions{TestFilter: regexp.MustCompile("parse")})
+	if err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	if res.Value != 2 {
+		t.Errorf("ran %v tests, want 2", res.Value)
+	}
+	if got := vm.MatchedTests(); got != 2 {
+		t.Errorf("MatchedTests = %d, want 2", got)
+	}
+
+	res, err = vm.Execute(mod, "main")
+	if err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	if res.Value != 3 {
+		t.Errorf("without a filter ran %v tests, want 3", res.Value)
+	}
+}
+
+func TestTestFilterSkippedTestWithoutEnd(t *testing.T) {
+	mod := filteredTestModule(false, "parse_ints", "lex_idents")
+	_, err := vm.ExecuteWithOptions(context.Background(), mod, "main", vm.ExecuteOptions{TestFilter: regexp.MustCompile("parse")})
+	if err == nil || !strings.Contains(err.Error(), "skipped test has no test.end") {
+		t.Fatalf("error = %v, want a missing test.end error", err)
+	}
+}
@@ -0,0 +1,178 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// strftimeLayouts translates strftime conversion codes to Go reference-time
+// layout elements. Times carry no zone, so %Z and %z always render UTC.
+var strftimeLayouts = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'e': "_2",
+	'H': "15",
+	'I': "03",
+	'M': "04",
+	'S': "05",
+	'p': "PM",
+	'b': "Jan",
+	'h': "Jan",
+	'B': "January",
+	'a': "Mon",
+	'A': "Monday",
+	'j': "002",
+	'Z': "MST",
+	'z': "-0700",
+	'F': "2006-01-02",
+	'T': "15:04:05",
+	'D': "01/02/06",
+	'R': "15:04",
+	'n': "\n",
+	't': "\t",
+	'%': "%",
+}
+
+// strftimeElements splits a strftime pattern into Go layout elements, one
+// per conversion code, and the literal text between them.
+func strftimeElements(pattern string) (elems []string, literal []bool, err error) {
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			elems = append(elems, text.String())
+			literal = append(literal, true)
+			text.Reset()
+		}
+	}
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' {
+			text.WriteByte(pattern[i])
+			continue
+		}
+		if i+1 >= len(pattern) {
+			return nil, nil, fmt.Errorf("pattern ends with a lone %%")
+		}
+		i++
+		elem, ok := strftimeLayouts[pattern[i]]
+		if !ok {
+			return nil, nil, fmt.Errorf("unsupported format code %%%c", pattern[i])
+		}
+		if pattern[i] == '%' || pattern[i] == 'n' || pattern[i] == 't' {
+			text.WriteString(elem)
+			continue
+		}
+		flush()
+		elems = append(elems, elem)
+		literal = append(literal, false)
+	}
+	flush()
+	return elems, literal, nil
+}
+
+// timeLayoutTokens maps the tokens of std.time.format layouts to strftime
+// codes.
+var timeLayoutTokens = []struct{ token, code string }{
+	{"YYYY", "%Y"},
+	{"MM", "%m"},
+	{"DD", "%d"},
+	{"HH", "%H"},
+	{"mm", "%M"},
+	{"ss", "%S"},
+}
+
+// layoutToStrftime translates a std.time.format layout such as
+// "YYYY-MM-DD HH:mm" to a strftime pattern. Everything that is not a token
+// is literal text, so a "%" in the layout is escaped.
+func layoutToStrftime(layout string) string {
+	var out strings.Builder
+next:
+	for i := 0; i < len(layout); {
+		for _, tok := range timeLayoutTokens {
+			if strings.HasPrefix(layout[i:], tok.token) {
+				out.WriteString(tok.code)
+				i += len(tok.token)
+				continue next
+			}
+		}
+		if layout[i] == '%' {
+			out.WriteByte('%')
+		}
+		out.WriteByte(layout[i])
+		i++
+	}
+	return out.String()
+}
+
+// layoutProbe is formatted with a pattern's literal text to detect text that
+// Go would read as a layout element (e.g. the "1" in "100").
+var layoutProbe = time.Date(2009, time.November, 17, 20, 34, 58, 0, time.UTC)
+
+// unixEpoch is returned by strptime when the input does not match the pattern.
+var unixEpoch = time.Unix(0, 0).UTC()
+
+// timeStructToGo converts an Omni std.time.Time struct value to a UTC time.
+func timeStructToGo(value interface{}) (time.Time, bool) {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return time.Time{}, false
+	}
+	get := func(name string) int {
+		n, _ := fields[name].(int)
+		return n
+	}
+	return time.Date(get("year"), time.Month(get("month")), get("day"),
+		get("hour"), get("minute"), get("second"), get("nanosecond"), time.UTC), true
+}
+
+// timeStructFromGo converts t to an Omni std.time.Time struct value.
+func timeStructFromGo(t time.Time) Result {
+	return Result{Type: "Time", Value: map[string]interface{}{
+		"year":       t.Year(),
+		"month":      int(t.Month()),
+		"day":        t.Day(),
+		"hour":       t.Hour(),
+		"minute":     t.Minute(),
+		"second":     t.Second(),
+		"nanosecond": t.Nanosecond(),
+	}}
+}
+
+// timeStrftime formats t according to a strftime pattern. Literal text is
+// copied verbatim rather than being interpreted as a Go layout.
+func timeStrftime(t time.Time, pattern string) (string, error) {
+	elems, literal, err := strftimeElements(pattern)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	for i, elem := range elems {
+		if literal[i] {
+			out.WriteString(elem)
+		} else {
+			out.WriteString(t.Format(elem))
+		}
+	}
+	return out.String(), nil
+}
+
+// timeStrptime parses s according to a strftime pattern. Date fields missing
+// from the pattern default to January 1 of year 0, and time fields to zero.
+func timeStrptime(s, pattern string) (time.Time, error) {
+	elems, literal, err := strftimeElements(pattern)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for i, elem := range elems {
+		if literal[i] && layoutProbe.Format(elem) != elem {
+			return time.Time{}, fmt.Errorf("literal text %q cannot be parsed unambiguously", elem)
+		}
+	}
+	t, err := time.Parse(strings.Join(elems, ""), s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
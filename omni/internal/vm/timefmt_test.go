@@ -0,0 +1,110 @@
+package vm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStrftime(t *testing.T) {
+	ts := time.Date(2024, time.March, 5, 13, 4, 9, 0, time.UTC)
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"%Y-%m-%dT%H:%M:%S", "2024-03-05T13:04:09"},
+		{"%F %T", "2024-03-05 13:04:09"},
+		{"%d/%m/%y %I:%M %p", "05/03/24 01:04 PM"},
+		{"%A %e %B, day %j", "Tuesday  5 March, day 065"},
+		{"%Z %z 100%%", "UTC +0000 100%"},
+	}
+	for _, tt := range tests {
+		got, err := timeStrftime(ts, tt.pattern)
+		if err != nil {
+			t.Errorf("strftime(%q): %v", tt.pattern, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("strftime(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+
+	for _, pattern := range []string{"%Q", "trailing %"} {
+		if _, err := timeStrftime(ts, pattern); err == nil {
+			t.Errorf("strftime(%q): expected error", pattern)
+		}
+	}
+}
+
+func TestStrptimeRoundTrip(t *testing.T) {
+	original := timeStructFromGo(time.Date(2024, time.March, 15, 13, 45, 30, 0, time.UTC))
+	start, ok := timeStructToGo(original.Value)
+	if !ok {
+		t.Fatal("timeStructToGo rejected a Time struct")
+	}
+	const pattern = "%Y-%m-%dT%H:%M:%S"
+	iso, err := timeStrftime(start, pattern)
+	if err != nil || iso != "2024-03-15T13:45:30" {
+		t.Fatalf("strftime = %q, %v", iso, err)
+	}
+	parsed, err := timeStrptime(iso, pattern)
+	if err != nil {
+		t.Fatalf("strptime: %v", err)
+	}
+	roundTrip := timeStructFromGo(parsed).Value.(map[string]interface{})
+	for field, want := range original.Value.(map[string]interface{}) {
+		if roundTrip[field] != want {
+			t.Errorf("field %s = %v, want %v", field, roundTrip[field], want)
+		}
+	}
+
+	if _, err := timeStrptime("15/03/2024", "%Y-%m-%d"); err == nil {
+		t.Error("expected error for input not matching the pattern")
+	}
+	if _, err := timeStrptime("day 1: 2024", "day 1: %Y"); err == nil {
+		t.Error("expected error for literal text that reads as a layout element")
+	}
+}
+
+func TestTimeLayoutGolden(t *testing.T) {
+	ts := time.Date(2024, time.March, 5, 13, 4, 9, 0, time.UTC)
+	tests := []struct {
+		layout  string
+		pattern string
+		want    string
+	}{
+		{"YYYY-MM-DD", "%Y-%m-%d", "2024-03-05"},
+		{"DD/MM/YYYY HH:mm:ss", "%d/%m/%Y %H:%M:%S", "05/03/2024 13:04:09"},
+		{"YYYYMMDDTHHmmss", "%Y%m%dT%H%M%S", "20240305T130409"},
+		{"HH:mm on DD.MM.", "%H:%M on %d.%m.", "13:04 on 05.03."},
+		{"100% YYYY", "100%% %Y", "100% 2024"},
+		{"no tokens", "no tokens", "no tokens"},
+	}
+	for _, tt := range tests {
+		pattern := layoutToStrftime(tt.layout)
+		if pattern != tt.pattern {
+			t.Errorf("layoutToStrftime(%q) = %q, want %q", tt.layout, pattern, tt.pattern)
+			continue
+		}
+		got, err := timeStrftime(ts, pattern)
+		if err != nil {
+			t.Errorf("format %q: %v", tt.layout, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("format %q = %q, want %q", tt.layout, got, tt.want)
+		}
+	}
+}
+
+func TestTimeLayoutParse(t *testing.T) {
+	parsed, err := timeStrptime("31.12.2023 23:59", layoutToStrftime("DD.MM.YYYY HH:mm"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if want := time.Date(2023, time.December, 31, 23, 59, 0, 0, time.UTC); !parsed.Equal(want) {
+		t.Errorf("parse = %v, want %v", parsed, want)
+	}
+	if _, err := timeStrptime("2023-12-31", layoutToStrftime("DD/MM/YYYY")); err == nil {
+		t.Error("expected error for input not matching the layout")
+	}
+}
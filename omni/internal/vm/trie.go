@@ -0,0 +1,132 @@
+package vm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// trieNode is a node of a std.collections.trie value. The path from the
+// root to a node spells a prefix, and the node holds a value when that
+// prefix is a key.
+type trieNode struct {
+	children map[rune]*trieNode
+	value    interface{}
+	hasValue bool
+}
+
+// trieValue is the VM representation of a std.collections.trie value.
+// Tries are shared by reference, like deques.
+type trieValue struct {
+	valueType string
+	root      trieNode
+}
+
+// find returns the node that prefix leads to, or nil if no key starts with
+// prefix.
+func (t *trieValue) find(prefix string) *trieNode {
+	node := &t.root
+	for _, r := range prefix {
+		node = node.children[r]
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// insert stores value under key.
+func (t *trieValue) insert(key string, value interface{}) {
+	node := &t.root
+	for _, r := range key {
+		child := node.children[r]
+		if child == nil {
+			if node.children == nil {
+				node.children = make(map[rune]*trieNode)
+			}
+			child = &trieNode{}
+			node.children[r] = child
+		}
+		node = child
+	}
+	node.value = value
+	node.hasValue = true
+}
+
+// collectKeys appends the keys under node, which prefix leads to, in
+// lexicographic order.
+func collectKeys(node *trieNode, prefix string, keys []string) []string {
+	if node.hasValue {
+		keys = append(keys, prefix)
+	}
+	runes := make([]rune, 0, len(node.children))
+	for r := range node.children {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	for _, r := range runes {
+		keys = collectKeys(node.children[r], prefix+string(r), keys)
+	}
+	return keys
+}
+
+// execTrie handles the std.collections.trie functions.
+func execTrie(callee string, operands []mir.Operand, fr *frame) (Result, bool, error) {
+	if !strings.HasPrefix(callee, "std.collections.trie.") {
+		return Result{}, false, nil
+	}
+	name := callee[len("std."):]
+	op := strings.TrimPrefix(callee, "std.collections.trie.")
+	want := 2
+	switch op {
+	case "create":
+		want = 0
+	case "insert":
+		want = 3
+	case "search", "starts_with", "keys_with_prefix":
+	default:
+		return Result{}, false, nil
+	}
+	if len(operands) != want {
+		return Result{}, true, fmt.Errorf("%s: expected %d arguments, got %d", name, want, len(operands))
+	}
+	if op == "create" {
+		return Result{Type: "trie", Value: &trieValue{}}, true, nil
+	}
+	arg := operandValue(fr, operands[0])
+	t, ok := arg.Value.(*trieValue)
+	if !ok {
+		return Result{}, true, fmt.Errorf("%s: expected a trie, got %s", name, arg.Type)
+	}
+	key, err := toString(operandValue(fr, operands[1]))
+	if err != nil {
+		return Result{}, true, fmt.Errorf("%s: %w", name, err)
+	}
+
+	switch op {
+	case "insert":
+		val := operandValue(fr, operands[2])
+		if t.valueType == "" {
+			t.valueType = val.Type
+		}
+		t.insert(key, val.Value)
+		return Result{Type: "void"}, true, nil
+	case "search":
+		node := t.find(key)
+		if node == nil || !node.hasValue {
+			return optionalOf(t.valueType+"?", nil, false), true, nil
+		}
+		return optionalOf(t.valueType+"?", node.value, true), true, nil
+	case "starts_with":
+		node := t.find(key)
+		return Result{Type: "bool", Value: node != nil && (node.hasValue || len(node.children) > 0)}, true, nil
+	default:
+		keys := []string{}
+		if node := t.find(key); node != nil {
+			keys = collectKeys(node, key, keys)
+		}
+		return Result{Type: "[]<string>", Value: keys}, true, nil
+	}
+}
@@ -0,0 +1,103 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+// trieWords declares words(), which inserts 100 three-letter words, "aaa"
+// to "dee", each under its index.
+const trieWords = `func words():Trie<int> {
+  let letters:array<string> = ["a", "b", "c", "d", "e"]
+  let t:Trie<int> = std.collections.trie.create()
+  for i:int = 0; i < 100; i++ {
+    std.collections.trie.insert(t, letters[i / 25] + letters[i / 5 % 5] + letters[i % 5], i)
+  }
+  return t
+}
+`
+
+func TestTrieRoundTrip(t *testing.T) {
+	src := trieWords + `
+func main():int {
+  let t:Trie<int> = words()
+  let letters:array<string> = ["a", "b", "c", "d", "e"]
+  var found:int = 0
+  for i:int = 0; i < 100; i++ {
+    let val:int? = std.collections.trie.search(t, letters[i / 25] + letters[i / 5 % 5] + letters[i % 5])
+    if val != null && opt.unwrap(val) == i {
+      found = found + 1
+    }
+  }
+  return found
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 100 {
+		t.Errorf("found %v of the 100 words, want all", res.Value)
+	}
+}
+
+func TestTriePrefixQueries(t *testing.T) {
+	for call, want := range map[string]interface{}{
+		`len(std.collections.trie.keys_with_prefix(t, ""))`:     100,
+		`len(std.collections.trie.keys_with_prefix(t, "d"))`:    25,
+		`len(std.collections.trie.keys_with_prefix(t, "ab"))`:   5,
+		`len(std.collections.trie.keys_with_prefix(t, "abc"))`:  1,
+		`len(std.collections.trie.keys_with_prefix(t, "e"))`:    0,
+		`len(std.collections.trie.keys_with_prefix(t, "abcd"))`: 0,
+		`std.collections.trie.keys_with_prefix(t, "c")[0]`:      "caa",
+		`std.collections.trie.keys_with_prefix(t, "c")[24]`:     "cee",
+		`std.collections.trie.keys_with_prefix(t, "bd")[2]`:     "bdc",
+		`std.collections.trie.starts_with(t, "")`:               true,
+		`std.collections.trie.starts_with(t, "de")`:             true,
+		`std.collections.trie.starts_with(t, "ea")`:             false,
+		`std.collections.trie.starts_with(t, "aaaa")`:           false,
+		`std.collections.trie.search(t, "aa") == null`:          true,
+		`std.collections.trie.search(t, "eaa") == null`:         true,
+	} {
+		resultType := "int"
+		switch want.(type) {
+		case string:
+			resultType = "string"
+		case bool:
+			resultType = "bool"
+		}
+		src := trieWords + `
+func main():` + resultType + ` {
+  let t:Trie<int> = words()
+  return ` + call + `
+}
+`
+		res, err := vm.Execute(buildSource(t, src), "main")
+		if err != nil {
+			t.Errorf("%s: execute: %v", call, err)
+			continue
+		}
+		if res.Value != want {
+			t.Errorf("%s = %v, want %v", call, res.Value, want)
+		}
+	}
+}
+
+func TestTrieInsertReplacesValue(t *testing.T) {
+	src := `func main():string {
+  let t:Trie<string> = std.collections.trie.create()
+  std.collections.trie.insert(t, "key", "old")
+  std.collections.trie.insert(t, "keys", "other")
+  std.collections.trie.insert(t, "key", "new")
+  return opt.unwrap(std.collections.trie.search(t, "key")) + std.int_to_string(len(std.collections.trie.keys_with_prefix(t, "k")))
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != "new2" {
+		t.Errorf("result = %q, want %q", res.Value, "new2")
+	}
+}
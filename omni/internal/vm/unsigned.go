@@ -0,0 +1,202 @@
+package vm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/types"
+)
+
+// Unsigned integers are held as uint64 values truncated to the width of
+// their type, so that they wrap around the way the C backend's uintN_t do.
+
+// isUnsignedType reports whether typ is one of the unsigned integer types.
+func isUnsignedType(typ string) bool {
+	_, ok := types.UnsignedWidths[typ]
+	return ok
+}
+
+// unsignedType returns the first unsigned type among types.
+func unsignedType(types ...string) (string, bool) {
+	for _, typ := range types {
+		if isUnsignedType(typ) {
+			return typ, true
+		}
+	}
+	return "", false
+}
+
+// truncateUnsigned wraps v around to the width of the unsigned type typ.
+func truncateUnsigned(v uint64, typ string) uint64 {
+	bits := uint(types.UnsignedWidths[typ])
+	if bits == 0 || bits >= 64 {
+		return v
+	}
+	return v & (1<<bits - 1)
+}
+
+// toUint64 converts an integer value to uint64, reinterpreting negative
+// signed values as two's complement.
+func toUint64(value Result) (uint64, error) {
+	switch v := value.Value.(type) {
+	case uint64:
+		return v, nil
+	case int:
+		return uint64(v), nil
+	case int64:
+		return uint64(v), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case nil:
+		return 0, fmt.Errorf("vm: expected unsigned int, got nil")
+	default:
+		return 0, fmt.Errorf("vm: expected unsigned int value, got %T", v)
+	}
+}
+
+// parseUnsignedLiteral parses a decimal, hex or binary unsigned literal.
+func parseUnsignedLiteral(lit string) (uint64, error) {
+	lit = strings.ReplaceAll(lit, "_", "")
+	switch {
+	case strings.HasPrefix(lit, "0x") || strings.HasPrefix(lit, "0X"):
+		return strconv.ParseUint(lit[2:], 16, 64)
+	case strings.HasPrefix(lit, "0b") || strings.HasPrefix(lit, "0B"):
+		return strconv.ParseUint(lit[2:], 2, 64)
+	}
+	return strconv.ParseUint(lit, 10, 64)
+}
+
+// unsignedOperands returns the operands of a binary instruction as uint64.
+func unsignedOperands(left, right Result) (uint64, uint64, error) {
+	l, err := toUint64(left)
+	if err != nil {
+		return 0, 0, err
+	}
+	r, err := toUint64(right)
+	if err != nil {
+		return 0, 0, err
+	}
+	return l, r, nil
+}
+
+// execUnsignedArithmetic performs add, sub, mul, div and mod (and their
+// unsigned udiv and umod forms) on unsigned operands of type typ.
+func execUnsignedArithmetic(inst mir.Instruction, typ string, left, right Result) (Result, error) {
+	l, r, err := unsignedOperands(left, right)
+	if err != nil {
+		return Result{}, err
+	}
+	var res uint64
+	switch inst.Op {
+	case "add":
+		res = l + r
+	case "sub":
+		res = l - r
+	case "mul":
+		res = l * r
+	case "div", "udiv":
+		if r == 0 {
+			return Result{}, DivisionByZeroError{Op: inst.Op, Location: inst.Location}
+		}
+		res = l / r
+	case "mod", "umod":
+		if r == 0 {
+			return Result{}, DivisionByZeroError{Op: inst.Op, Location: inst.Location}
+		}
+		res = l % r
+	default:
+		return Result{}, fmt.Errorf("vm: unsupported unsigned arithmetic operator %s", inst.Op)
+	}
+	return Result{Type: typ, Value: truncateUnsigned(res, typ)}, nil
+}
+
+// execUnsignedBitwise performs the bitwise and shift operators on unsigned
+// operands of type typ. Right shifts are logical.
+func execUnsignedBitwise(inst mir.Instruction, typ string, left, right Result) (Result, error) {
+	l, r, err := unsignedOperands(left, right)
+	if err != nil {
+		return Result{}, err
+	}
+	var res uint64
+	switch inst.Op {
+	case "bitand":
+		res = l & r
+	case "bitor":
+		res = l | r
+	case "bitxor":
+		res = l ^ r
+	case "lshift":
+		res = l << r
+	case "rshift":
+		res = l >> r
+	default:
+		return Result{}, fmt.Errorf("unsupported bitwise operation %q", inst.Op)
+	}
+	return Result{Type: typ, Value: truncateUnsigned(res, typ)}, nil
+}
+
+// execUnsignedComparison compares unsigned operands.
+func execUnsignedComparison(inst mir.Instruction, left, right Result) (Result, error) {
+	l, r, err := unsignedOperands(left, right)
+	if err != nil {
+		return Result{}, err
+	}
+	var res bool
+	switch inst.Op {
+	case "cmp.eq":
+		res = l == r
+	case "cmp.neq":
+		res = l != r
+	case "cmp.lt":
+		res = l < r
+	case "cmp.lte":
+		res = l <= r
+	case "cmp.gt":
+		res = l > r
+	case "cmp.gte":
+		res = l >= r
+	default:
+		return Result{}, fmt.Errorf("vm: unsupported unsigned comparison operator %s", inst.Op)
+	}
+	return Result{Type: "bool", Value: res}, nil
+}
+
+// castToUnsigned converts operand to the unsigned type typ, truncating it
+// to the width of typ.
+func castToUnsigned(operand Result, typ string) (Result, error) {
+	var v uint64
+	switch operand.Type {
+	case "float", "double":
+		f, err := toFloat(operand)
+		if err != nil {
+			return Result{}, err
+		}
+		if f < 0 {
+			v = uint64(int64(f))
+		} else {
+			v = uint64(f)
+		}
+	case "string":
+		s, err := toString(operand)
+		if err != nil {
+			return Result{}, err
+		}
+		parsed, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return Result{}, fmt.Errorf("cast: cannot convert string %q to %s", s, typ)
+		}
+		v = parsed
+	default:
+		u, err := toUint64(operand)
+		if err != nil {
+			return Result{}, err
+		}
+		v = u
+	}
+	return Result{Type: typ, Value: truncateUnsigned(v, typ)}, nil
+}
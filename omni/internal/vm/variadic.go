@@ -0,0 +1,40 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/omni-lang/omni/internal/mir"
+)
+
+// isVariadic reports whether the last parameter of fn is variadic.
+func isVariadic(fn *mir.Function) bool {
+	return len(fn.Params) > 0 && fn.Params[len(fn.Params)-1].Variadic
+}
+
+// packVariadic returns args with the arguments for the variadic parameter
+// of fn packed into an array. The builder packs them at the call site, so
+// args usually have the array already; callers that pass the arguments
+// one by one, such as hand-written MIR, are packed here.
+func packVariadic(fn *mir.Function, args []Result) ([]Result, error) {
+	fixed := len(fn.Params) - 1
+	param := fn.Params[fixed]
+	if len(args) < fixed {
+		return nil, fmt.Errorf("vm: function %s expects at least %d arguments, got %d", fn.Name, fixed, len(args))
+	}
+	if len(args) == len(fn.Params) && args[fixed].Type == param.Type {
+		return args, nil
+	}
+
+	// Pack the trailing arguments like an array.init of the parameter type
+	fr := &frame{values: make(map[mir.ValueID]Result, len(args)-fixed)}
+	inst := mir.Instruction{Op: "array.init", Type: param.Type}
+	for i, arg := range args[fixed:] {
+		fr.values[mir.ValueID(i)] = arg
+		inst.Operands = append(inst.Operands, mir.Operand{Kind: mir.OperandValue, Value: mir.ValueID(i), Type: arg.Type})
+	}
+	packed, err := execArrayInit(nil, fr, inst)
+	if err != nil {
+		return nil, fmt.Errorf("vm: function %s: %w", fn.Name, err)
+	}
+	return append(append([]Result{}, args[:fixed]...), packed), nil
+}
@@ -0,0 +1,85 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/vm"
+)
+
+func TestVariadicCall(t *testing.T) {
+	src := `func sum(scale:int, xs:...int):int {
+  var s:int = 0
+  var i:int = 0
+  while i < len(xs) {
+    s = s + xs[i]
+    i = i + 1
+  }
+  return s * scale
+}
+func main():int {
+  return sum(10, 1, 2, 3) + sum(1) + sum(100, 4)
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 460 {
+		t.Errorf("result = %v, want 460", res.Value)
+	}
+}
+
+func TestVariadicRangeLoop(t *testing.T) {
+	// The range loop takes the length of the packed array at run time, so
+	// it runs once per argument and not at all when there are none
+	src := `func sum(base:int, nums:...int):int {
+  var t:int = base
+  for n in nums {
+    t = t + n
+  }
+  return t
+}
+func main():int {
+  return sum(1, 2, 3) * 10 + sum(1)
+}
+`
+	res, err := vm.Execute(buildSource(t, src), "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 61 {
+		t.Errorf("result = %v, want 61", res.Value)
+	}
+}
+
+func TestVariadicArgumentsPackedByVM(t *testing.T) {
+	// count is called with its variadic arguments one by one rather than
+	// packed into an array.init by the builder
+	count := mir.NewFunction("count", "int", []mir.Param{{Name: "xs", Type: "[]<int>", Variadic: true}})
+	entry := count.NewBlock("entry")
+	n := count.NextValue()
+	entry.Instructions = append(entry.Instructions, mir.Instruction{ID: n, Op: "call", Type: "int", Operands: []mir.Operand{
+		{Kind: mir.OperandLiteral, Literal: "len"},
+		{Kind: mir.OperandValue, Value: count.Params[0].ID, Type: "[]<int>"},
+	}})
+	entry.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: n, Type: "int"}}}
+
+	main := mir.NewFunction("main", "int", nil)
+	block := main.NewBlock("entry")
+	operands := []mir.Operand{{Kind: mir.OperandLiteral, Literal: "count"}}
+	for i := 0; i < 3; i++ {
+		operands = append(operands, mir.Operand{Kind: mir.OperandLiteral, Literal: "7", Type: "int"})
+	}
+	result := main.NextValue()
+	block.Instructions = append(block.Instructions, mir.Instruction{ID: result, Op: "call", Type: "int", Operands: operands})
+	block.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: result, Type: "int"}}}
+
+	res, err := vm.Execute(&mir.Module{Functions: []*mir.Function{count, main}}, "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 3 {
+		t.Errorf("result = %v, want 3", res.Value)
+	}
+}
@@ -2,6 +2,7 @@ package vm
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	urlpkg "net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -37,36 +39,43 @@ var (
 	cliArgsMu sync.RWMutex
 	cliArgs   []string
 
-	stdinReader = bufio.NewReader(os.Stdin)
+	// execCtx is the context of the running ExecuteContext call.
+	execCtxMu sync.RWMutex
+	execCtx   = context.Background()
 
-	// Async/Promise support
-	promiseMu      sync.RWMutex
-	promiseCounter int
-	promises       = make(map[int]*Promise)
-	eventLoop      = make(chan func(), 100) // Event loop channel
+	stdinReader = bufio.NewReader(os.Stdin)
 
 	// Coverage tracking
 	coverageMu      sync.RWMutex
 	coverageEnabled bool
 	coverageData    = make(map[string]*coverageEntry)
+	branchCoverage  = make(map[string]*BranchData)
+	// currentTest is the test between its test.start and test.end, whose
+	// name coverage records carry.
+	currentTest string
 )
 
+// BranchData records which paths of a conditional branch have been taken.
+type BranchData struct {
+	True  bool `json:"true"`
+	False bool `json:"false"`
+}
+
+// branchKey identifies the conditional branch terminating block in fn. A
+// block ends in a single cbr, so the branch index is always 0; it is part of
+// the key so that multi-way terminators can be added without a format change.
+func branchKey(fn, block string) string {
+	return fn + ":" + block + ":0"
+}
+
 // coverageEntry tracks coverage for a function
 type coverageEntry struct {
 	FunctionName string `json:"function"`
 	FilePath     string `json:"file"`
 	LineNumber   int    `json:"line"`
 	CallCount    int    `json:"count"`
-}
-
-// Promise represents an async operation that may complete in the future
-type Promise struct {
-	ID      int
-	Value   Result
-	Error   error
-	Done    bool
-	Waiters []chan Result // Channels waiting for this promise to resolve
-	mu      sync.Mutex
+	// TestName is the test that made the calls, empty outside tests.
+	TestName string `json:"test_name,omitempty"`
 }
 
 type testingSuite struct {
@@ -93,6 +102,9 @@ func ensureTestingSuiteLocked(id int) *testingSuite {
 }
 
 func recordTestingResultLocked(suite *testingSuite, name string, passed bool, message string) {
+	if !runsTest(name) {
+		return
+	}
 	fmt.Printf("Running test: %s\n", name)
 	if passed {
 		fmt.Printf("✓ %s PASSED\n", name)
@@ -177,143 +189,6 @@ func readLineFromStdin() (string, error) {
 	return strings.TrimRight(line, "\r\n"), nil
 }
 
-// newPromise creates a new promise and returns its ID
-func newPromise() int {
-	promiseMu.Lock()
-	defer promiseMu.Unlock()
-	promiseCounter++
-	id := promiseCounter
-	promises[id] = &Promise{
-		ID:      id,
-		Waiters: make([]chan Result, 0),
-	}
-	return id
-}
-
-// resolvePromise resolves a promise with a value
-func resolvePromise(id int, value Result) {
-	promiseMu.Lock()
-	promise, ok := promises[id]
-	if !ok {
-		promiseMu.Unlock()
-		return
-	}
-	promiseMu.Unlock()
-
-	promise.mu.Lock()
-	promise.Value = value
-	promise.Done = true
-	waiters := promise.Waiters
-	promise.Waiters = nil
-	promise.mu.Unlock()
-
-	// Notify all waiters
-	for _, ch := range waiters {
-		select {
-		case ch <- value:
-		default:
-		}
-	}
-}
-
-// rejectPromise rejects a promise with an error
-func rejectPromise(id int, err error) {
-	promiseMu.Lock()
-	promise, ok := promises[id]
-	if !ok {
-		promiseMu.Unlock()
-		return
-	}
-	promiseMu.Unlock()
-
-	promise.mu.Lock()
-	promise.Error = err
-	promise.Done = true
-	waiters := promise.Waiters
-	promise.Waiters = nil
-	promise.mu.Unlock()
-
-	// Notify all waiters with error result
-	errorResult := Result{Type: "error", Value: err.Error()}
-	for _, ch := range waiters {
-		select {
-		case ch <- errorResult:
-		default:
-		}
-	}
-}
-
-// awaitPromise waits for a promise to resolve
-func awaitPromise(id int) (Result, error) {
-	promiseMu.RLock()
-	promise, ok := promises[id]
-	promiseMu.RUnlock()
-
-	if !ok {
-		return Result{}, fmt.Errorf("promise %d not found", id)
-	}
-
-	promise.mu.Lock()
-	if promise.Done {
-		value := promise.Value
-		err := promise.Error
-		promise.mu.Unlock()
-		if err != nil {
-			return Result{}, err
-		}
-		return value, nil
-	}
-
-	// Create a channel to wait for resolution
-	ch := make(chan Result, 1)
-	promise.Waiters = append(promise.Waiters, ch)
-	promise.mu.Unlock()
-
-	// Wait for the promise to resolve
-	result := <-ch
-	if result.Type == "error" {
-		return Result{}, fmt.Errorf("%v", result.Value)
-	}
-	return result, nil
-}
-
-// execAwait handles await instructions
-func execAwait(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
-	if len(inst.Operands) == 0 {
-		return Result{}, fmt.Errorf("await instruction requires operand")
-	}
-
-	operand := operandValue(fr, inst.Operands[0])
-
-	// Check if operand is a Promise
-	if operand.Type == "Promise" {
-		// Extract promise ID from value
-		if promiseID, ok := operand.Value.(int); ok {
-			result, err := awaitPromise(promiseID)
-			if err != nil {
-				return Result{}, err
-			}
-			return result, nil
-		}
-		// If value is a map with promise data, extract ID
-		if promiseMap, ok := operand.Value.(map[string]interface{}); ok {
-			if idVal, ok := promiseMap["id"]; ok {
-				if promiseID, ok := idVal.(int); ok {
-					result, err := awaitPromise(promiseID)
-					if err != nil {
-						return Result{}, err
-					}
-					return result, nil
-				}
-			}
-		}
-		return Result{}, fmt.Errorf("await: invalid promise value")
-	}
-
-	// If not a Promise, return as-is (for now - this allows awaiting non-promises for compatibility)
-	return operand, nil
-}
-
 func hasFlag(name string) bool {
 	args := cloneCLIArgs()
 	if len(args) == 0 {
@@ -439,6 +314,83 @@ func (e ExitError) Error() string {
 	return fmt.Sprintf("vm exit with code %d", e.Code)
 }
 
+// TimeoutError reports that execution was stopped because the context passed
+// to ExecuteContext was cancelled or its deadline passed.
+type TimeoutError struct {
+	Err error
+}
+
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("vm execution stopped: %v", e.Err)
+}
+
+func (e TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// DivisionByZeroError reports a div or mod instruction whose divisor was
+// zero, at the source location the instruction was lowered from when it is
+// known.
+type DivisionByZeroError struct {
+	// Op is the instruction: div, mod, udiv or umod.
+	Op       string
+	Location mir.SourceLocation
+}
+
+func (e DivisionByZeroError) Error() string {
+	what := "division"
+	if e.Op == "mod" || e.Op == "umod" {
+		what = "modulo"
+	}
+	if e.Location.IsZero() {
+		return what + " by zero"
+	}
+	return fmt.Sprintf("%s by zero at %s", what, e.Location)
+}
+
+// StackTraceError is a runtime error together with the call stack that was
+// active when it happened. Frames lists function names from the one that
+// failed out to the entry function.
+type StackTraceError struct {
+	Err    error
+	Frames []string
+}
+
+func (e StackTraceError) Error() string {
+	return e.Err.Error()
+}
+
+func (e StackTraceError) Unwrap() error {
+	return e.Err
+}
+
+// StackTrace renders Frames one per line, innermost first.
+func (e StackTraceError) StackTrace() string {
+	var b strings.Builder
+	b.WriteString("stack trace:\n")
+	for _, name := range e.Frames {
+		b.WriteString("  at ")
+		b.WriteString(name)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// withFrame records that err left the function name, starting a
+// StackTraceError at the function where it happened. Timeouts are not
+// runtime errors of the program and pass through unchanged.
+func withFrame(name string, err error) error {
+	var timeout TimeoutError
+	if errors.As(err, &timeout) {
+		return err
+	}
+	var trace StackTraceError
+	if errors.As(err, &trace) {
+		return StackTraceError{Err: trace.Err, Frames: append(trace.Frames[:len(trace.Frames):len(trace.Frames)], name)}
+	}
+	return StackTraceError{Err: err, Frames: []string{name}}
+}
+
 func init() {
 	instructionHandlers = map[string]instructionHandler{
 		"const":           execConst,
@@ -447,6 +399,8 @@ func init() {
 		"mul":             execArithmetic,
 		"div":             execArithmetic,
 		"mod":             execArithmetic,
+		"udiv":            execArithmetic,
+		"umod":            execArithmetic,
 		"bitand":          execBitwise,
 		"bitor":           execBitwise,
 		"bitxor":          execBitwise,
@@ -470,11 +424,12 @@ func init() {
 		"call.void":       execCall,
 		"call.string":     execCall,
 		"call.bool":       execCall,
-		"struct.init":     execStructInit,
-		"array.init":      execArrayInit,
+		"struct.init":     allocating(execStructInit),
+		"array.init":      allocating(execArrayInit),
 		"index":           execIndex,
 		"assign":          execAssign,
-		"map.init":        execMapInit,
+		"map.init":        allocating(execMapInit),
+		"map.contains":    execMapContains,
 		"member":          execMember,
 		"phi":             execPhi,
 		"malloc":          execMalloc,
@@ -490,6 +445,8 @@ func init() {
 		"file.size":       execFileSize,
 		"test.start":      execTestStart,
 		"test.end":        execTestEnd,
+		"bench.start":     execBenchStart,
+		"bench.end":       execBenchEnd,
 		"assert":          execAssert,
 		"assert.eq":       execAssertEq,
 		"assert.true":     execAssertTrue,
@@ -501,6 +458,23 @@ func init() {
 		"closure.capture": execClosureCapture,
 		"closure.bind":    execClosureBind,
 		"await":           execAwait,
+		"defer.push":      execDeferPush,
+		"defer.run":       execDeferRun,
+		"throw":           execThrow,
+		"try.enter":       execTryEnter,
+		"try.exit":        execTryExit,
+		"catch.begin":     execCaughtException,
+		"finally.begin":   execCaughtException,
+		"opt.some":        execOptSome,
+		"opt.none":        execOptNone,
+		"opt.unwrap":      execOptUnwrap,
+		"opt.unwrap_or":   execOptUnwrapOr,
+		"opt.is_some":     execOptIsSome,
+		"opt.is_none":     execOptIsSome,
+		"interface.init":  execInterfaceInit,
+		"interface.call":  execInterfaceCall,
+		"global.load":     execGlobalLoad,
+		"global.store":    execGlobalStore,
 	}
 }
 
@@ -513,7 +487,55 @@ type Result struct {
 }
 
 // Execute interprets the MIR module starting from the named entry function.
-func Execute(mod *mir.Module, entry string) (res Result, err error) {
+func Execute(mod *mir.Module, entry string) (Result, error) {
+	return ExecuteContext(context.Background(), mod, entry)
+}
+
+// ExecuteContext is Execute with a context that stops the program when it is
+// cancelled. The VM checks the context at every branch between basic blocks
+// and returns a TimeoutError once it is done.
+func ExecuteContext(ctx context.Context, mod *mir.Module, entry string) (Result, error) {
+	return ExecuteWithOptions(ctx, mod, entry, ExecuteOptions{})
+}
+
+// ExecuteOptions configures a run of ExecuteWithOptions.
+type ExecuteOptions struct {
+	// Sandbox, when set, restricts the program's file system, network and
+	// environment access; a refused operation fails the run with a
+	// SandboxViolationError.
+	Sandbox *SandboxPolicy
+	// TestFilter, when set, runs only the tests whose name it matches. A
+	// test.start of another test jumps past the next test.end, and
+	// std.testing cases of other names are not recorded.
+	TestFilter *regexp.Regexp
+	// OverflowCheck makes int add, sub, mul and div fail with an
+	// ArithmeticError when the result does not fit in 32 bits.
+	OverflowCheck bool
+}
+
+// ExecuteWithOptions is ExecuteContext with the options of the run.
+func ExecuteWithOptions(ctx context.Context, mod *mir.Module, entry string, opts ExecuteOptions) (res Result, err error) {
+	execCtxMu.Lock()
+	execCtx = ctx
+	execCtxMu.Unlock()
+	defer func() {
+		execCtxMu.Lock()
+		execCtx = context.Background()
+		execCtxMu.Unlock()
+	}()
+	if opts.Sandbox != nil || opts.OverflowCheck {
+		handlers := instructionHandlers
+		if opts.Sandbox != nil {
+			handlers = sandboxHandlers(opts.Sandbox)
+		}
+		if opts.OverflowCheck {
+			handlers = overflowHandlers(handlers)
+		}
+		instructions.handlers.Store(&handlers)
+		defer instructions.handlers.Store(nil)
+	}
+	setTestFilter(opts.TestFilter)
+
 	defer func() {
 		if r := recover(); r != nil {
 			switch v := r.(type) {
@@ -531,6 +553,9 @@ func Execute(mod *mir.Module, entry string) (res Result, err error) {
 	if mod == nil {
 		return Result{}, fmt.Errorf("vm: nil module")
 	}
+	if IsCoverageEnabled() {
+		registerBranches(mod)
+	}
 	funcs := map[string]*mir.Function{}
 	for _, fn := range mod.Functions {
 		funcs[fn.Name] = fn
@@ -539,7 +564,12 @@ func Execute(mod *mir.Module, entry string) (res Result, err error) {
 	if !ok {
 		return Result{}, fmt.Errorf("vm: entry function %q not found", entry)
 	}
-	value, execErr := execFunction(funcs, fn, nil)
+	if err := globals.reset(mod); err != nil {
+		return Result{}, err
+	}
+	heap.reset()
+	eventLoop.reset()
+	value, execErr := eventLoop.run(funcs, fn)
 	if execErr != nil {
 		return Result{}, execErr
 	}
@@ -548,12 +578,74 @@ func Execute(mod *mir.Module, entry string) (res Result, err error) {
 
 type frame struct {
 	values map[mir.ValueID]Result
+	// pred is the block control arrived from, which selects the value of
+	// the phis of the current block
+	pred string
+	// defers are the calls of the defer statements run so far, which
+	// defer.run makes in reverse order before the function returns
+	defers []func() error
+	// handlers are the try handlers that are active, innermost last, and
+	// exception is the one the last handler caught
+	handlers  []tryHandler
+	exception Result
+	// skipTest is set by the start of a test the test filter excludes;
+	// the function then continues after the test's end
+	skipTest bool
+}
+
+// tailCall is a call whose result the calling function returns directly.
+type tailCall struct {
+	fn   *mir.Function
+	args []Result
 }
 
+// execFunction runs fn to completion. A tail call replaces the running
+// function instead of nesting inside it, so tail-recursive functions run in
+// constant Go stack space.
 func execFunction(funcs map[string]*mir.Function, fn *mir.Function, args []Result) (Result, error) {
-	fr := &frame{values: make(map[mir.ValueID]Result)}
+	k, err := newContinuation(fn, args)
+	if err != nil {
+		return Result{}, withFrame(fn.Name, err)
+	}
+	res, _, err := k.execute(funcs, nil)
+	heap.maybeCollect(res)
+	return res, err
+}
+
+// continuation is a function invocation in progress: its frame and the
+// position in its body where it continues. A coroutine that awaits keeps
+// its continuation until the promise settles.
+type continuation struct {
+	fn     *mir.Function
+	fr     *frame
+	blocks map[string]*mir.BasicBlock
+	block  *mir.BasicBlock
+	// index is the next instruction of block to run; the phis of block
+	// have run once it is past them
+	index int
+}
+
+func newContinuation(fn *mir.Function, args []Result) (*continuation, error) {
+	k := &continuation{}
+	return k, k.start(fn, args)
+}
+
+// start positions k at the entry of fn called with args.
+func (k *continuation) start(fn *mir.Function, args []Result) error {
+	if k.fr != nil {
+		heap.leave(k.fr)
+	}
+	*k = continuation{fn: fn, fr: &frame{values: make(map[mir.ValueID]Result)}}
+	heap.enter(k.fr)
+	if len(args) != 0 && isVariadic(fn) {
+		packed, err := packVariadic(fn, args)
+		if err != nil {
+			return err
+		}
+		args = packed
+	}
 	if len(args) != 0 && len(args) != len(fn.Params) {
-		return Result{}, fmt.Errorf("vm: function %s expects %d arguments, got %d", fn.Name, len(fn.Params), len(args))
+		return fmt.Errorf("vm: function %s expects %d arguments, got %d", fn.Name, len(fn.Params), len(args))
 	}
 	for i, param := range fn.Params {
 		var val Result
@@ -565,53 +657,186 @@ func execFunction(funcs map[string]*mir.Function, fn *mir.Function, args []Resul
 		if val.Type == "" {
 			val.Type = param.Type
 		}
-		fr.values[param.ID] = val
+		k.fr.values[param.ID] = val
+	}
+	if len(fn.Blocks) > 0 {
+		k.blocks = make(map[string]*mir.BasicBlock, len(fn.Blocks))
+		for _, b := range fn.Blocks {
+			k.blocks[b.Name] = b
+		}
+		k.block = fn.Blocks[0]
 	}
+	return nil
+}
 
-	if len(fn.Blocks) == 0 {
-		return Result{Type: "void"}, nil
+// execute runs k until its function returns, following tail calls. When co
+// is not nil, it stops early and reports that it suspended if the function
+// awaits a promise that is still pending; running it again resumes at the
+// await. execute adds the running function to the stack trace of the
+// errors it returns.
+func (k *continuation) execute(funcs map[string]*mir.Function, co *coroutine) (Result, bool, error) {
+	for {
+		res, tail, suspended, err := k.run(funcs, co)
+		if err != nil {
+			heap.leave(k.fr)
+			return Result{}, false, withFrame(k.fn.Name, err)
+		}
+		if suspended {
+			return Result{}, true, nil
+		}
+		if tail == nil {
+			heap.leave(k.fr)
+			return res, false, nil
+		}
+		if err := k.start(tail.fn, tail.args); err != nil {
+			heap.leave(k.fr)
+			return Result{}, false, withFrame(k.fn.Name, err)
+		}
+	}
+}
+
+// isTailCall reports whether inst, the last instruction of a block ending
+// in term, is a call whose result the block returns.
+func isTailCall(inst mir.Instruction, term mir.Terminator) bool {
+	switch inst.Op {
+	case "call", "call.int", "call.void", "call.string", "call.bool":
+	default:
+		return false
+	}
+	if term.Op != "ret" {
+		return false
 	}
-	blockMap := make(map[string]*mir.BasicBlock, len(fn.Blocks))
-	for _, b := range fn.Blocks {
-		blockMap[b.Name] = b
+	if len(term.Operands) == 0 {
+		return inst.Op == "call.void" || inst.ID == mir.InvalidValue
 	}
-	current := fn.Blocks[0]
+	op := term.Operands[0]
+	return len(term.Operands) == 1 && inst.ID != mir.InvalidValue && op.Kind == mir.OperandValue && op.Value == inst.ID
+}
+
+// isModuleCall reports whether the call inst runs OmniLang code of the
+// module rather than a standard library function the VM implements, which
+// goes through the call handlers instead.
+func isModuleCall(funcs map[string]*mir.Function, inst mir.Instruction) bool {
+	callee := inst.Operands[0].Literal
+	if _, ok := funcs[callee]; !ok || inst.Operands[0].Kind != mir.OperandLiteral {
+		return false
+	}
+	return !strings.HasPrefix(callee, "std.") || isOverridden(callee)
+}
+
+// run interprets the body of the function of k until it returns, makes a
+// tail call to a module function, which it hands back to execute to run in
+// its place, or suspends the coroutine co at an await.
+func (k *continuation) run(funcs map[string]*mir.Function, co *coroutine) (Result, *tailCall, bool, error) {
+	fn, fr := k.fn, k.fr
+	if k.block == nil {
+		return Result{Type: "void"}, nil, false, nil
+	}
+	execCtxMu.RLock()
+	ctx := execCtx
+	execCtxMu.RUnlock()
+	done := ctx.Done()
+	trackBranches := IsCoverageEnabled()
+blocks:
 	for {
-		for _, inst := range current.Instructions {
-			res, err := execInstruction(funcs, fr, inst)
+		current := k.block
+		if k.index == 0 {
+			phis, err := execBlockPhis(funcs, fr, current)
 			if err != nil {
-				return Result{}, fmt.Errorf("vm: %s: %w", fn.Name, err)
+				return Result{}, nil, false, fmt.Errorf("vm: %s: %w", fn.Name, err)
+			}
+			k.index = phis
+		}
+		last := len(current.Instructions) - 1
+		for ; k.index < len(current.Instructions); k.index++ {
+			inst := current.Instructions[k.index]
+			if co != nil && inst.Op == "await" {
+				// The await runs again on resumption, when the promise
+				// has settled
+				if promise, ok := awaitedPromise(fr, inst); ok && promise.suspend(co) {
+					return Result{}, nil, true, nil
+				}
+			}
+			var res Result
+			var err error
+			if k.index == last && isTailCall(inst, current.Terminator) && isModuleCall(funcs, inst) {
+				var callee *mir.Function
+				var calleeArgs []Result
+				callee, calleeArgs, res, err = resolveCall(funcs, fr, inst)
+				if err == nil && callee != nil {
+					return Result{}, &tailCall{fn: callee, args: calleeArgs}, false, nil
+				}
+			} else {
+				res, err = execInstruction(funcs, fr, inst)
+			}
+			if err != nil {
+				if name, ok := fr.catch(err); ok {
+					target, err := blockByOperand(k.blocks, mir.Operand{Kind: mir.OperandLiteral, Literal: name})
+					if err != nil {
+						return Result{}, nil, false, fmt.Errorf("vm: %s: %w", fn.Name, err)
+					}
+					fr.pred = current.Name
+					k.block, k.index = target, 0
+					continue blocks
+				}
+				return Result{}, nil, false, fmt.Errorf("vm: %s: %w", fn.Name, fr.unwindDefers(err))
 			}
 			if inst.ID != mir.InvalidValue {
 				fr.values[inst.ID] = res
 			}
+			if fr.skipTest {
+				fr.skipTest = false
+				end, index, ok := nextTestEnd(fn, current, k.index)
+				if !ok {
+					return Result{}, nil, false, fmt.Errorf("vm: %s: skipped test has no test.end", fn.Name)
+				}
+				k.block, k.index = end, index+1
+				continue blocks
+			}
 		}
 
 		term := current.Terminator
 		switch term.Op {
-		case "ret":
+		case "ret", "ret.named":
+			// ret.named returns the slot of the named return value
 			if len(term.Operands) == 0 {
-				return Result{Type: "void"}, nil
+				return Result{Type: "void"}, nil, false, nil
 			}
 			op := term.Operands[0]
 			if op.Kind != mir.OperandValue {
-				return literalResult(op)
+				res, err := literalResult(op)
+				return res, nil, false, err
 			}
-			return fr.values[op.Value], nil
+			return fr.values[op.Value], nil, false, nil
 		case "br", "jmp":
-			target, err := blockByOperand(blockMap, term.Operands[0])
+			target, err := blockByOperand(k.blocks, term.Operands[0])
 			if err != nil {
-				return Result{}, fmt.Errorf("vm: %s: %w", fn.Name, err)
+				return Result{}, nil, false, fmt.Errorf("vm: %s: %w", fn.Name, err)
 			}
-			current = target
+			fr.pred = current.Name
+			k.block, k.index = target, 0
+		case "loop.break", "loop.continue":
+			jump := execLoopBreak
+			if term.Op == "loop.continue" {
+				jump = execLoopContinue
+			}
+			target, err := jump(k.blocks, term)
+			if err != nil {
+				return Result{}, nil, false, fmt.Errorf("vm: %s: %w", fn.Name, err)
+			}
+			fr.pred = current.Name
+			k.block, k.index = target, 0
 		case "cbr":
 			if len(term.Operands) < 3 {
-				return Result{}, fmt.Errorf("vm: %s: conditional branch requires condition and two targets", fn.Name)
+				return Result{}, nil, false, fmt.Errorf("vm: %s: conditional branch requires condition and two targets", fn.Name)
 			}
 			cond := operandValue(fr, term.Operands[0])
 			b, err := toBool(cond)
 			if err != nil {
-				return Result{}, fmt.Errorf("vm: %s: %w", fn.Name, err)
+				return Result{}, nil, false, fmt.Errorf("vm: %s: %w", fn.Name, err)
+			}
+			if trackBranches {
+				recordBranch(fn.Name, current.Name, b)
 			}
 			var targetOp mir.Operand
 			if b {
@@ -619,15 +844,46 @@ func execFunction(funcs map[string]*mir.Function, fn *mir.Function, args []Resul
 			} else {
 				targetOp = term.Operands[2]
 			}
-			target, err := blockByOperand(blockMap, targetOp)
+			target, err := blockByOperand(k.blocks, targetOp)
 			if err != nil {
-				return Result{}, fmt.Errorf("vm: %s: %w", fn.Name, err)
+				return Result{}, nil, false, fmt.Errorf("vm: %s: %w", fn.Name, err)
 			}
-			current = target
+			fr.pred = current.Name
+			k.block, k.index = target, 0
+		case "unreachable":
+			return Result{}, nil, false, fmt.Errorf("vm: %s: reached the unreachable end of block %s", fn.Name, current.Name)
 		default:
-			return Result{}, fmt.Errorf("unsupported terminator %q", term.Op)
+			if !term.Location.IsZero() {
+				return Result{}, nil, false, fmt.Errorf("unsupported terminator %q at %s", term.Op, term.Location)
+			}
+			return Result{}, nil, false, fmt.Errorf("unsupported terminator %q", term.Op)
 		}
+
+		select {
+		case <-done:
+			return Result{}, nil, false, TimeoutError{Err: ctx.Err()}
+		default:
+		}
+	}
+}
+
+// execLoopBreak handles the loop.break terminator of a break statement,
+// which leaves the loop for the exit block it names.
+func execLoopBreak(blocks map[string]*mir.BasicBlock, term mir.Terminator) (*mir.BasicBlock, error) {
+	if len(term.Operands) != 1 {
+		return nil, fmt.Errorf("loop.break requires the loop exit block")
+	}
+	return blockByOperand(blocks, term.Operands[0])
+}
+
+// execLoopContinue handles the loop.continue terminator of a continue
+// statement, which starts the next iteration at the block it names: the
+// loop header, or the post statement of a for loop.
+func execLoopContinue(blocks map[string]*mir.BasicBlock, term mir.Terminator) (*mir.BasicBlock, error) {
+	if len(term.Operands) != 1 {
+		return nil, fmt.Errorf("loop.continue requires the loop continue block")
 	}
+	return blockByOperand(blocks, term.Operands[0])
 }
 
 func blockByOperand(blocks map[string]*mir.BasicBlock, op mir.Operand) (*mir.BasicBlock, error) {
@@ -642,11 +898,7 @@ func blockByOperand(blocks map[string]*mir.BasicBlock, op mir.Operand) (*mir.Bas
 }
 
 func execInstruction(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
-	handler, exists := instructionHandlers[inst.Op]
-	if !exists {
-		return Result{}, fmt.Errorf("unsupported instruction %q", inst.Op)
-	}
-	return handler(funcs, fr, inst)
+	return instructions.exec(funcs, fr, inst)
 }
 
 // execConst handles const instructions
@@ -654,6 +906,18 @@ func execConst(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction)
 	return literalResult(inst.Operands[0])
 }
 
+// allocating wraps the handler of an instruction that allocates a heap
+// object, registering the object it returns with the heap.
+func allocating(handler instructionHandler) instructionHandler {
+	return func(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+		res, err := handler(funcs, fr, inst)
+		if err == nil {
+			heap.track(res.Value)
+		}
+		return res, err
+	}
+}
+
 // execStructInit handles struct initialization
 func execStructInit(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
 	// Create a struct value from the operands
@@ -728,6 +992,9 @@ func execMember(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction)
 	}
 
 	fieldName := fieldNameOp.Literal
+	if target.Type == "any" {
+		return anyMember(target.Value, fieldName)
+	}
 
 	// Handle struct field access
 	if structValue, ok := target.Value.(map[string]interface{}); ok {
@@ -737,6 +1004,8 @@ func execMember(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction)
 			switch fieldValue.(type) {
 			case int:
 				fieldType = "int"
+			case int64:
+				fieldType = "int64"
 			case string:
 				fieldType = "string"
 			case float64:
@@ -754,12 +1023,37 @@ func execMember(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction)
 	return Result{}, fmt.Errorf("member: target is not a struct")
 }
 
+// execBlockPhis evaluates the phis at the start of block and returns how
+// many there are. The phis take their values together, as on the edge from
+// the predecessor: a phi that names another phi of the block reads the value
+// it had before control arrived, as when loop variables are swapped.
+func execBlockPhis(funcs map[string]*mir.Function, fr *frame, block *mir.BasicBlock) (int, error) {
+	n := 0
+	for n < len(block.Instructions) && block.Instructions[n].Op == "phi" {
+		n++
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	results := make([]Result, n)
+	for i, inst := range block.Instructions[:n] {
+		res, err := execInstruction(funcs, fr, inst)
+		if err != nil {
+			return 0, err
+		}
+		results[i] = res
+	}
+	for i, inst := range block.Instructions[:n] {
+		if inst.ID != mir.InvalidValue {
+			fr.values[inst.ID] = results[i]
+		}
+	}
+	return n, nil
+}
+
 // execPhi handles PHI nodes - values that can come from different control flow paths
 func execPhi(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
 	// PHI nodes have operands in pairs: (value, block_name)
-	// For now, we'll use a simple approach: return the first value
-	// In a more sophisticated implementation, we'd track which block we came from
-
 	if len(inst.Operands) < 2 {
 		return Result{}, fmt.Errorf("phi: expected at least 2 operands, got %d", len(inst.Operands))
 	}
@@ -768,24 +1062,34 @@ func execPhi(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (R
 		return Result{}, fmt.Errorf("phi: expected even number of operands (value, block pairs), got %d", len(inst.Operands))
 	}
 
-	// For now, we'll return the first value
-	// In a real implementation, we'd need to track the incoming control flow
-	// and select the appropriate value based on which block we came from
+	// Take the value paired with the block we came from; phis that do not
+	// name a matching predecessor fall back to their first value
+	for i := 0; i+1 < len(inst.Operands); i += 2 {
+		block := inst.Operands[i+1]
+		if block.Kind == mir.OperandLiteral && block.Literal == fr.pred {
+			return operandValue(fr, inst.Operands[i]), nil
+		}
+	}
 	firstValue := operandValue(fr, inst.Operands[0])
 	return firstValue, nil
 }
 
+// arrayElementType extracts the element type from an array type
+// (e.g., "[]<int>" -> "int", "array<int>" -> "int", "[]int" -> "int").
+func arrayElementType(arrayType string) string {
+	if strings.HasPrefix(arrayType, "[]<") && strings.HasSuffix(arrayType, ">") {
+		return arrayType[3 : len(arrayType)-1]
+	} else if strings.HasPrefix(arrayType, "array<") && strings.HasSuffix(arrayType, ">") {
+		return arrayType[6 : len(arrayType)-1]
+	} else if strings.HasPrefix(arrayType, "[]") {
+		return arrayType[2:]
+	}
+	return arrayType
+}
+
 // execArrayInit handles array initialization
 func execArrayInit(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
-	// Extract element type from array type (e.g., "[]<int>" -> "int", "[]int" -> "int")
-	elementType := inst.Type
-	if strings.HasPrefix(elementType, "[]<") && strings.HasSuffix(elementType, ">") {
-		elementType = elementType[3 : len(elementType)-1]
-	} else if strings.HasPrefix(elementType, "array<") && strings.HasSuffix(elementType, ">") {
-		elementType = elementType[6 : len(elementType)-1]
-	} else if strings.HasPrefix(elementType, "[]") {
-		elementType = elementType[2:]
-	}
+	elementType := arrayElementType(inst.Type)
 
 	// Handle nested array types like "[]<[]<int>>"
 	if strings.HasPrefix(elementType, "[]<") && strings.HasSuffix(elementType, ">") {
@@ -887,6 +1191,9 @@ func execIndex(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction)
 
 	target := operandValue(fr, inst.Operands[0])
 	index := operandValue(fr, inst.Operands[1])
+	if target.Type == "any" {
+		return anyIndex(target.Value, index)
+	}
 
 	// Handle map indexing first (no need to convert index to int)
 	if strings.HasPrefix(target.Type, "map<") {
@@ -953,6 +1260,12 @@ func execIndex(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction)
 				return Result{}, fmt.Errorf("index: array index %d out of bounds [0, %d)", indexVal, len(arr))
 			}
 			return Result{Type: "bool", Value: arr[indexVal]}, nil
+		case []interface{}:
+			// Nested arrays such as array<array<int>>
+			if indexVal < 0 || indexVal >= len(arr) {
+				return Result{}, fmt.Errorf("index: array index %d out of bounds [0, %d)", indexVal, len(arr))
+			}
+			return Result{Type: arrayElementType(target.Type), Value: arr[indexVal]}, nil
 		default:
 			return Result{}, fmt.Errorf("index: unsupported array type %T", target.Value)
 		}
@@ -996,6 +1309,25 @@ func execMapInit(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction
 	return Result{Type: inst.Type, Value: mapValue}, nil
 }
 
+// execMapContains reports whether a map has an entry for a key
+func execMapContains(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	if len(inst.Operands) != 2 {
+		return Result{}, fmt.Errorf("map.contains: expected 2 operands, got %d", len(inst.Operands))
+	}
+	target := operandValue(fr, inst.Operands[0])
+	key := operandValue(fr, inst.Operands[1])
+
+	switch m := target.Value.(type) {
+	case map[interface{}]interface{}:
+		_, exists := m[key.Value]
+		return Result{Type: "bool", Value: exists}, nil
+	case nil:
+		return Result{Type: "bool", Value: false}, nil
+	default:
+		return Result{}, fmt.Errorf("map.contains: target of type %s is not a map", target.Type)
+	}
+}
+
 // parseMapTypes extracts key and value types from a map type string
 func parseMapTypes(mapType string) (string, string, error) {
 	if !strings.HasPrefix(mapType, "map<") || !strings.HasSuffix(mapType, ">") {
@@ -1039,13 +1371,13 @@ func execArithmetic(funcs map[string]*mir.Function, fr *frame, inst mir.Instruct
 			res = lf * rf
 		case "div":
 			if rf == 0 {
-				return Result{}, fmt.Errorf("division by zero")
+				return Result{}, DivisionByZeroError{Op: inst.Op, Location: inst.Location}
 			}
 			res = lf / rf
 		case "mod":
 			// For floats, use math.Mod
 			if rf == 0 {
-				return Result{}, fmt.Errorf("modulo by zero")
+				return Result{}, DivisionByZeroError{Op: inst.Op, Location: inst.Location}
 			}
 			res = float64(int(lf) % int(rf)) // Simple modulo for floats
 		default:
@@ -1054,6 +1386,45 @@ func execArithmetic(funcs map[string]*mir.Function, fr *frame, inst mir.Instruct
 		return Result{Type: "float", Value: res}, nil
 	}
 
+	if typ, ok := unsignedType(inst.Type, left.Type, right.Type); ok {
+		return execUnsignedArithmetic(inst, typ, left, right)
+	}
+	if inst.Op == "udiv" || inst.Op == "umod" {
+		return execUnsignedArithmetic(inst, "uint64", left, right)
+	}
+
+	// 64-bit integers stay int64 so that they behave the same on every host
+	if isInt64Type(left.Type) || isInt64Type(right.Type) || isInt64Type(inst.Type) {
+		li, err := toInt64(left)
+		if err != nil {
+			return Result{}, err
+		}
+		ri, err := toInt64(right)
+		if err != nil {
+			return Result{}, err
+		}
+		var res int64
+		switch inst.Op {
+		case "add":
+			res = li + ri
+		case "sub":
+			res = li - ri
+		case "mul":
+			res = li * ri
+		case "div":
+			if ri == 0 {
+				return Result{}, DivisionByZeroError{Op: inst.Op, Location: inst.Location}
+			}
+			res = li / ri
+		case "mod":
+			if ri == 0 {
+				return Result{}, DivisionByZeroError{Op: inst.Op, Location: inst.Location}
+			}
+			res = li % ri
+		}
+		return Result{Type: "int64", Value: res}, nil
+	}
+
 	// Handle integer arithmetic (existing logic)
 	li, err := toInt(left)
 	if err != nil {
@@ -1073,12 +1444,12 @@ func execArithmetic(funcs map[string]*mir.Function, fr *frame, inst mir.Instruct
 		res = li * ri
 	case "div":
 		if ri == 0 {
-			return Result{}, fmt.Errorf("division by zero")
+			return Result{}, DivisionByZeroError{Op: inst.Op, Location: inst.Location}
 		}
 		res = li / ri
 	case "mod":
 		if ri == 0 {
-			return Result{}, fmt.Errorf("modulo by zero")
+			return Result{}, DivisionByZeroError{Op: inst.Op, Location: inst.Location}
 		}
 		res = li % ri
 	}
@@ -1092,6 +1463,21 @@ func execComparison(funcs map[string]*mir.Function, fr *frame, inst mir.Instruct
 	// Handle different types of comparisons
 	var res bool
 
+	// Null comparisons (an optional value checked against null)
+	if left.Type == "null" || right.Type == "null" {
+		_, leftSome := optionalValue(left)
+		_, rightSome := optionalValue(right)
+		switch inst.Op {
+		case "cmp.eq":
+			res = !leftSome && !rightSome
+		case "cmp.neq":
+			res = leftSome || rightSome
+		default:
+			return Result{}, fmt.Errorf("vm: unsupported null comparison operator %s", inst.Op)
+		}
+		return Result{Type: "bool", Value: res}, nil
+	}
+
 	// String comparisons
 	if left.Type == "string" && right.Type == "string" {
 		leftStr, ok1 := left.Value.(string)
@@ -1149,6 +1535,10 @@ func execComparison(funcs map[string]*mir.Function, fr *frame, inst mir.Instruct
 		return Result{Type: "bool", Value: res}, nil
 	}
 
+	if isUnsignedType(left.Type) || isUnsignedType(right.Type) {
+		return execUnsignedComparison(inst, left, right)
+	}
+
 	// Boolean comparisons
 	if left.Type == "bool" && right.Type == "bool" {
 		leftBool, ok1 := left.Value.(bool)
@@ -1240,6 +1630,105 @@ func execStringConcat(funcs map[string]*mir.Function, fr *frame, inst mir.Instru
 	return Result{Type: "string", Value: builder.String()}, nil
 }
 
+// execStringFormat handles std.string.format, which substitutes its
+// arguments for the placeholders of its template in order. It is separate
+// from execIntrinsic because a template can be malformed.
+func execStringFormat(fr *frame, operands []mir.Operand) (Result, error) {
+	if len(operands) == 0 {
+		return Result{}, fmt.Errorf("format: missing template")
+	}
+	template, err := toString(operandValue(fr, operands[0]))
+	if err != nil {
+		return Result{}, fmt.Errorf("format: template: %w", err)
+	}
+	args := make([]Result, len(operands)-1)
+	for i, op := range operands[1:] {
+		args[i] = operandValue(fr, op)
+	}
+	out, err := formatString(template, args)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Type: "string", Value: out}, nil
+}
+
+// formatString replaces each placeholder of template with the next of args:
+// {} writes any value as toString does, {:d} an integer, {:f} a number
+// with six decimals and {:s} a string. {{ and }} stand for literal braces.
+func formatString(template string, args []Result) (string, error) {
+	builder := GetStringBuilder()
+	defer PutStringBuilder(builder)
+
+	next := 0
+	for i := 0; i < len(template); i++ {
+		ch := template[i]
+		if ch == '}' {
+			if i+1 < len(template) && template[i+1] == '}' {
+				builder.WriteByte('}')
+				i++
+				continue
+			}
+			return "", fmt.Errorf("format: unmatched } at offset %d", i)
+		}
+		if ch != '{' {
+			builder.WriteByte(ch)
+			continue
+		}
+		if i+1 < len(template) && template[i+1] == '{' {
+			builder.WriteByte('{')
+			i++
+			continue
+		}
+		end := strings.IndexByte(template[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("format: unterminated placeholder at offset %d", i)
+		}
+		spec := template[i+1 : i+end]
+		i += end
+		if next >= len(args) {
+			return "", fmt.Errorf("format: placeholder {%s} has no argument", spec)
+		}
+		arg := args[next]
+		next++
+		text, err := formatArg(spec, arg)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(text)
+	}
+	if next < len(args) {
+		return "", fmt.Errorf("format: %d arguments left after the last placeholder", len(args)-next)
+	}
+	return builder.String(), nil
+}
+
+// formatArg formats arg for the placeholder with the given spec, the text
+// between its braces.
+func formatArg(spec string, arg Result) (string, error) {
+	switch spec {
+	case "":
+		return toString(arg)
+	case ":d":
+		switch arg.Value.(type) {
+		case int, int32, int64, uint64:
+			return toString(arg)
+		}
+	case ":f":
+		if _, ok := arg.Value.(bool); !ok {
+			if f, err := toFloat(arg); err == nil {
+				return strconv.FormatFloat(f, 'f', 6, 64), nil
+			}
+		}
+	case ":s":
+		if s, ok := arg.Value.(string); ok {
+			return s, nil
+		}
+	default:
+		return "", fmt.Errorf("format: unknown placeholder {%s}", spec)
+	}
+	return "", fmt.Errorf("format: {%s} cannot format a %s", spec, arg.Type)
+}
+
 func execUnary(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
 	operand := operandValue(fr, inst.Operands[0])
 
@@ -1264,6 +1753,13 @@ func execUnary(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction)
 			val := operand.Value.(int)
 			return Result{Type: "int", Value: ^val}, nil
 		}
+		if isUnsignedType(operand.Type) {
+			val, err := toUint64(operand)
+			if err != nil {
+				return Result{}, err
+			}
+			return Result{Type: operand.Type, Value: truncateUnsigned(^val, operand.Type)}, nil
+		}
 		return Result{}, fmt.Errorf("bitnot: operand must be int, got %s", operand.Type)
 	default:
 		return Result{}, fmt.Errorf("unsupported unary operation %q", inst.Op)
@@ -1271,33 +1767,94 @@ func execUnary(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction)
 }
 
 func execCall(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (Result, error) {
+	fn, args, result, err := resolveCall(funcs, fr, inst)
+	if err != nil || fn == nil {
+		return result, err
+	}
+	return execFunction(funcs, fn, args)
+}
+
+// resolveCall evaluates the callee and arguments of a call instruction. A
+// call to a module function that runs synchronously is returned as the
+// function and its arguments for the caller to execute; any other call,
+// such as an intrinsic or an async function, is carried out here and its
+// result returned with a nil function.
+func resolveCall(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (*mir.Function, []Result, Result, error) {
 	if len(inst.Operands) == 0 {
-		return Result{}, fmt.Errorf("call instruction missing callee operand")
+		return nil, nil, Result{}, fmt.Errorf("call instruction missing callee operand")
 	}
 	calleeOp := inst.Operands[0]
 	if calleeOp.Kind != mir.OperandLiteral {
-		return Result{}, fmt.Errorf("call expects literal callee operand")
+		return nil, nil, Result{}, fmt.Errorf("call expects literal callee operand")
 	}
 	callee := calleeOp.Literal
 
-	// Check if it's an intrinsic function
-	if result, handled := execIntrinsic(callee, inst.Operands[1:], fr); handled {
-		return result, nil
+	// A function replaced through an import map shadows the built-in version
+	fn, ok := funcs[callee]
+	if !ok || !isOverridden(callee) {
+		// Signal handlers are function values resolved against funcs
+		if strings.HasPrefix(callee, "std.os.signal.") {
+			if result, handled := execSignal(funcs, callee, inst.Operands[1:], fr); handled {
+				return nil, nil, result, nil
+			}
+		}
+
+		if callee == "std.string.format" || callee == "string.format" {
+			result, err := execStringFormat(fr, inst.Operands[1:])
+			return nil, nil, result, err
+		}
+		if result, handled, err := execJSON(callee, inst.Operands[1:], fr); handled {
+			return nil, nil, result, err
+		}
+		if result, handled, err := execEnv(callee, inst.Operands[1:], fr); handled {
+			return nil, nil, result, err
+		}
+		if result, handled, err := execSort(callee, inst.Operands[1:], fr); handled {
+			return nil, nil, result, err
+		}
+		if result, handled, err := execStringPad(callee, inst.Operands[1:], fr); handled {
+			return nil, nil, result, err
+		}
+		if result, handled, err := execStringReplace(callee, inst.Operands[1:], fr); handled {
+			return nil, nil, result, err
+		}
+		if result, handled, err := execStringParse(callee, inst.Operands[1:], fr); handled {
+			return nil, nil, result, err
+		}
+		if result, handled := execProcess(callee, inst.Operands[1:], fr); handled {
+			return nil, nil, result, nil
+		}
+		if result, handled, err := execCollections(funcs, callee, inst.Operands[1:], fr); handled {
+			return nil, nil, result, err
+		}
+		if result, handled, err := execDeque(callee, inst.Operands[1:], fr); handled {
+			return nil, nil, result, err
+		}
+		if result, handled, err := execTrie(callee, inst.Operands[1:], fr); handled {
+			return nil, nil, result, err
+		}
+		if result, handled, err := execOrderedMap(callee, inst.Operands[1:], fr); handled {
+			return nil, nil, result, err
+		}
+
+		// Check if it's an intrinsic function
+		if result, handled := execIntrinsic(callee, inst.Operands[1:], fr); handled {
+			return nil, nil, result, nil
+		}
 	}
 
-	fn, ok := funcs[callee]
 	if !ok {
 		// Check if it's an imported module function (contains a dot but not std.*)
 		if strings.Contains(callee, ".") && !strings.HasPrefix(callee, "std.") {
 			// For now, return a default value for imported functions
 			// In a real implementation, this would need to load and execute the imported module
-			return Result{Type: "int", Value: 0}, nil
+			return nil, nil, Result{Type: "int", Value: 0}, nil
 		}
 		// Record coverage for std library functions that aren't intrinsics
 		if strings.HasPrefix(callee, "std.") {
 			recordCoverage(callee, "", 0)
 		}
-		return Result{}, fmt.Errorf("callee %q not found", callee)
+		return nil, nil, Result{}, fmt.Errorf("callee %q not found", callee)
 	}
 
 	// Record coverage for std library functions
@@ -1311,28 +1868,15 @@ func execCall(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (
 
 	// Check if function returns a Promise (async function)
 	if strings.HasPrefix(fn.ReturnType, "Promise<") {
-		// Execute async function and return a Promise
-		promiseID := newPromise()
-
-		// Execute function asynchronously
-		go func() {
-			result, err := execFunction(funcs, fn, args)
-			if err != nil {
-				rejectPromise(promiseID, err)
-			} else {
-				resolvePromise(promiseID, result)
-			}
-		}()
-
-		// Return Promise immediately
-		return Result{
+		// Start the async function as a coroutine and return its Promise
+		return nil, nil, Result{
 			Type:  "Promise",
-			Value: promiseID,
+			Value: eventLoop.spawn(funcs, fn, args),
 		}, nil
 	}
 
 	// Synchronous execution for non-async functions
-	return execFunction(funcs, fn, args)
+	return fn, args, Result{}, nil
 }
 
 // recordCoverage records a function call for coverage tracking
@@ -1344,7 +1888,7 @@ func recordCoverage(functionName, filePath string, lineNumber int) {
 		return
 	}
 
-	key := fmt.Sprintf("%s:%s:%d", functionName, filePath, lineNumber)
+	key := fmt.Sprintf("%s:%s:%d:%s", functionName, filePath, lineNumber, currentTest)
 	if entry, exists := coverageData[key]; exists {
 		entry.CallCount++
 	} else {
@@ -1353,10 +1897,58 @@ func recordCoverage(functionName, filePath string, lineNumber int) {
 			FilePath:     filePath,
 			LineNumber:   lineNumber,
 			CallCount:    1,
+			TestName:     currentTest,
+		}
+	}
+}
+
+// setCurrentTest makes name the test later coverage records are
+// attributed to; an empty name ends the attribution.
+func setCurrentTest(name string) {
+	coverageMu.Lock()
+	defer coverageMu.Unlock()
+	currentTest = name
+}
+
+// registerBranches adds every conditional branch in mod to the branch
+// coverage data, so that branches that never execute are reported as well.
+func registerBranches(mod *mir.Module) {
+	coverageMu.Lock()
+	defer coverageMu.Unlock()
+	for _, fn := range mod.Functions {
+		for _, block := range fn.Blocks {
+			if block.Terminator.Op != "cbr" {
+				continue
+			}
+			key := branchKey(fn.Name, block.Name)
+			if _, exists := branchCoverage[key]; !exists {
+				branchCoverage[key] = &BranchData{}
+			}
 		}
 	}
 }
 
+// recordBranch records that the cbr ending block in fn took the given path.
+func recordBranch(fn, block string, taken bool) {
+	coverageMu.Lock()
+	defer coverageMu.Unlock()
+
+	if !coverageEnabled {
+		return
+	}
+	key := branchKey(fn, block)
+	entry, exists := branchCoverage[key]
+	if !exists {
+		entry = &BranchData{}
+		branchCoverage[key] = entry
+	}
+	if taken {
+		entry.True = true
+	} else {
+		entry.False = true
+	}
+}
+
 // SetCoverageEnabled enables or disables coverage tracking
 func SetCoverageEnabled(enabled bool) {
 	coverageMu.Lock()
@@ -1376,6 +1968,8 @@ func ResetCoverage() {
 	coverageMu.Lock()
 	defer coverageMu.Unlock()
 	coverageData = make(map[string]*coverageEntry)
+	branchCoverage = make(map[string]*BranchData)
+	currentTest = ""
 }
 
 // ExportCoverage exports coverage data as JSON
@@ -1388,8 +1982,14 @@ func ExportCoverage() ([]byte, error) {
 		entries = append(entries, entry)
 	}
 
+	branches := make(map[string]BranchData, len(branchCoverage))
+	for key, entry := range branchCoverage {
+		branches[key] = *entry
+	}
+
 	data := map[string]interface{}{
-		"entries": entries,
+		"entries":  entries,
+		"branches": branches,
 	}
 
 	return json.Marshal(data)
@@ -1408,6 +2008,15 @@ func execIntrinsic(callee string, operands []mir.Operand, fr *frame) (Result, bo
 		// Builtin len function for arrays
 		if len(operands) == 1 {
 			arg := operandValue(fr, operands[0])
+			if arg.Type == "any" {
+				switch v := arg.Value.(type) {
+				case []interface{}:
+					return Result{Type: "int", Value: len(v)}, true
+				case map[string]interface{}:
+					return Result{Type: "int", Value: len(v)}, true
+				}
+				return Result{}, false
+			}
 			// Check if it's an array type
 			if strings.HasPrefix(arg.Type, "[]<") || strings.HasPrefix(arg.Type, "array<") {
 				switch arr := arg.Value.(type) {
@@ -1419,6 +2028,8 @@ func execIntrinsic(callee string, operands []mir.Operand, fr *frame) (Result, bo
 					return Result{Type: "int", Value: len(arr)}, true
 				case []bool:
 					return Result{Type: "int", Value: len(arr)}, true
+				case []interface{}:
+					return Result{Type: "int", Value: len(arr)}, true
 				default:
 					return Result{}, false
 				}
@@ -1440,12 +2051,37 @@ func execIntrinsic(callee string, operands []mir.Operand, fr *frame) (Result, bo
 			fmt.Println()
 			return Result{Type: "void", Value: nil}, true
 		}
+	case "std.io.write":
+		if len(operands) == 1 {
+			arg := operandValue(fr, operands[0])
+			fmt.Print(arg.Value)
+			return Result{Type: "void", Value: nil}, true
+		}
+	case "std.io.eprint":
+		if len(operands) == 1 {
+			arg := operandValue(fr, operands[0])
+			fmt.Fprint(os.Stderr, arg.Value)
+			return Result{Type: "void", Value: nil}, true
+		}
+	case "std.io.eprintln":
+		if len(operands) == 1 {
+			arg := operandValue(fr, operands[0])
+			fmt.Fprintln(os.Stderr, arg.Value)
+			return Result{Type: "void", Value: nil}, true
+		} else if len(operands) == 0 {
+			fmt.Fprintln(os.Stderr)
+			return Result{Type: "void", Value: nil}, true
+		}
 	case "std.io.read_line":
 		line, err := readLineFromStdin()
 		if err != nil && !errors.Is(err, io.EOF) {
 			return Result{Type: "string", Value: ""}, true
 		}
 		return Result{Type: "string", Value: line}, true
+	case "std.io.prompt", "std.io.prompt_int", "std.io.prompt_float":
+		if result, ok := execPrompt(callee, operands, fr); ok {
+			return result, true
+		}
 	case "std.io.read_line_async":
 		// Async version - execute in goroutine and return Promise
 		promiseID := newPromise()
@@ -1458,6 +2094,221 @@ func execIntrinsic(callee string, operands []mir.Operand, fr *frame) (Result, bo
 			}
 		}()
 		return Result{Type: "Promise", Value: promiseID}, true
+	case "std.time.format.strftime":
+		if len(operands) == 2 {
+			t, ok := timeStructToGo(operandValue(fr, operands[0]).Value)
+			pattern, err := toString(operandValue(fr, operands[1]))
+			if ok && err == nil {
+				if formatted, fmtErr := timeStrftime(t, pattern); fmtErr == nil {
+					return Result{Type: "string", Value: formatted}, true
+				}
+			}
+		}
+		return Result{Type: "string", Value: ""}, true
+	case "std.time.format.strptime":
+		if len(operands) == 2 {
+			s, err1 := toString(operandValue(fr, operands[0]))
+			pattern, err2 := toString(operandValue(fr, operands[1]))
+			if err1 == nil && err2 == nil {
+				if t, parseErr := timeStrptime(s, pattern); parseErr == nil {
+					return timeStructFromGo(t), true
+				}
+			}
+		}
+		return timeStructFromGo(unixEpoch), true
+	case "std.time.format":
+		if len(operands) == 2 {
+			t, ok := timeStructToGo(operandValue(fr, operands[0]).Value)
+			layout, err := toString(operandValue(fr, operands[1]))
+			if ok && err == nil {
+				if formatted, fmtErr := timeStrftime(t, layoutToStrftime(layout)); fmtErr == nil {
+					return Result{Type: "string", Value: formatted}, true
+				}
+			}
+		}
+		return Result{Type: "string", Value: ""}, true
+	case "std.time.parse":
+		if len(operands) == 2 {
+			layout, err1 := toString(operandValue(fr, operands[0]))
+			s, err2 := toString(operandValue(fr, operands[1]))
+			if err1 == nil && err2 == nil {
+				if t, parseErr := timeStrptime(s, layoutToStrftime(layout)); parseErr == nil {
+					return timeStructFromGo(t), true
+				}
+			}
+		}
+		return timeStructFromGo(unixEpoch), true
+	case "std.collections.graph.create":
+		if len(operands) == 1 {
+			if nodes, err := toInt(operandValue(fr, operands[0])); err == nil && nodes >= 0 {
+				return newGraphResult(&graphValue{Nodes: nodes}), true
+			}
+		}
+		return newGraphResult(&graphValue{}), true
+	case "std.collections.graph.add_edge":
+		if len(operands) == 4 {
+			g, ok := graphFromValue(operandValue(fr, operands[0]).Value)
+			from, err1 := toInt(operandValue(fr, operands[1]))
+			to, err2 := toInt(operandValue(fr, operands[2]))
+			weight, err3 := toInt(operandValue(fr, operands[3]))
+			if ok && err1 == nil && err2 == nil && err3 == nil {
+				return Result{Type: "bool", Value: g.addEdge(from, to, weight)}, true
+			}
+		}
+		return Result{Type: "bool", Value: false}, true
+	case "std.collections.graph.has_edge":
+		if len(operands) == 3 {
+			g, ok := graphFromValue(operandValue(fr, operands[0]).Value)
+			from, err1 := toInt(operandValue(fr, operands[1]))
+			to, err2 := toInt(operandValue(fr, operands[2]))
+			if ok && err1 == nil && err2 == nil {
+				return Result{Type: "bool", Value: g.hasEdge(from, to)}, true
+			}
+		}
+		return Result{Type: "bool", Value: false}, true
+	case "std.collections.graph.node_count", "std.collections.graph.edge_count", "std.collections.graph.total_weight":
+		if len(operands) == 1 {
+			if g, ok := graphFromValue(operandValue(fr, operands[0]).Value); ok {
+				switch callee {
+				case "std.collections.graph.node_count":
+					return Result{Type: "int", Value: g.Nodes}, true
+				case "std.collections.graph.edge_count":
+					return Result{Type: "int", Value: len(g.Edges)}, true
+				default:
+					return Result{Type: "int", Value: g.totalWeight()}, true
+				}
+			}
+		}
+		return Result{Type: "int", Value: 0}, true
+	case "std.collections.graph.mst":
+		if len(operands) == 1 {
+			if g, ok := graphFromValue(operandValue(fr, operands[0]).Value); ok {
+				return newGraphResult(graphMST(g)), true
+			}
+		}
+		return newGraphResult(&graphValue{}), true
+	case "std.collections.graph.topological_sort":
+		if len(operands) == 1 {
+			if g, ok := graphFromValue(operandValue(fr, operands[0]).Value); ok {
+				if order, acyclic := graphTopologicalSort(g); acyclic {
+					return Result{Type: "array<int>", Value: order}, true
+				}
+			}
+		}
+		return Result{Type: "null", Value: nil}, true
+	case "std.collections.graph.strongly_connected_components":
+		components := []interface{}{}
+		if len(operands) == 1 {
+			if g, ok := graphFromValue(operandValue(fr, operands[0]).Value); ok {
+				for _, component := range graphSCC(g) {
+					components = append(components, component)
+				}
+			}
+		}
+		return Result{Type: "array<array<int>>", Value: components}, true
+	case "std.io.readline_history.load", "std.io.readline_history.save":
+		if len(operands) == 1 {
+			if path, err := toString(operandValue(fr, operands[0])); err == nil {
+				var histErr error
+				if callee == "std.io.readline_history.load" {
+					histErr = processHistory.Load(path)
+				} else {
+					histErr = processHistory.Save(path)
+				}
+				if histErr != nil {
+					fmt.Fprintf(os.Stderr, "%s: %v\n", callee, histErr)
+				}
+			}
+		}
+		return Result{Type: "void", Value: nil}, true
+	case "std.io.readline_history.add":
+		if len(operands) == 1 {
+			if line, err := toString(operandValue(fr, operands[0])); err == nil {
+				processHistory.Add(line)
+			}
+		}
+		return Result{Type: "void", Value: nil}, true
+	case "std.io.readline_history.get":
+		if len(operands) == 1 {
+			if index, err := toInt(operandValue(fr, operands[0])); err == nil {
+				if entry, ok := processHistory.Get(index); ok {
+					return Result{Type: "string", Value: entry}, true
+				}
+			}
+		}
+		return Result{Type: "null", Value: nil}, true
+	case "std.io.readline_history.count":
+		return Result{Type: "int", Value: processHistory.Len()}, true
+	case "std.io.readline_history.set_max_entries":
+		if len(operands) == 1 {
+			if limit, err := toInt(operandValue(fr, operands[0])); err == nil {
+				processHistory.SetMaxEntries(limit)
+			}
+		}
+		return Result{Type: "void", Value: nil}, true
+	case "std.io.diff.unified":
+		if len(operands) == 3 {
+			original, err1 := toString(operandValue(fr, operands[0]))
+			modified, err2 := toString(operandValue(fr, operands[1]))
+			context, err3 := toInt(operandValue(fr, operands[2]))
+			if err1 == nil && err2 == nil && err3 == nil {
+				return Result{Type: "string", Value: diffUnified(original, modified, context)}, true
+			}
+		}
+		return Result{Type: "string", Value: ""}, true
+	case "std.io.diff.apply":
+		if len(operands) == 2 {
+			original, err1 := toString(operandValue(fr, operands[0]))
+			patch, err2 := toString(operandValue(fr, operands[1]))
+			if err1 == nil && err2 == nil {
+				patched, applyErr := diffApply(original, patch)
+				if applyErr != nil {
+					return Result{Type: "string", Value: ""}, true
+				}
+				return Result{Type: "string", Value: patched}, true
+			}
+		}
+		return Result{Type: "string", Value: ""}, true
+	case "std.hash.sha256", "std.hash.sha512", "std.hash.md5":
+		if len(operands) == 1 {
+			s, err := toString(operandValue(fr, operands[0]))
+			if err == nil {
+				digest, _ := hashDigest(strings.TrimPrefix(callee, "std.hash."), s)
+				return Result{Type: "string", Value: digest}, true
+			}
+		}
+		return Result{Type: "string", Value: ""}, true
+	case "std.hash.fnv32", "std.hash.fnv64", "std.hash.murmur3":
+		if len(operands) == 1 {
+			s, err := toString(operandValue(fr, operands[0]))
+			if err == nil {
+				var h int
+				switch callee {
+				case "std.hash.fnv32":
+					h = hashFNV32(s)
+				case "std.hash.fnv64":
+					h = hashFNV64(s)
+				default:
+					h = hashMurmur3(s)
+				}
+				return Result{Type: "int", Value: h}, true
+			}
+		}
+		return Result{Type: "int", Value: 0}, true
+	case "std.regex.find", "std.regex.find_all", "std.regex.groups":
+		if len(operands) == 2 {
+			pattern, err := toString(operandValue(fr, operands[0]))
+			if err == nil {
+				if s, err := toString(operandValue(fr, operands[1])); err == nil {
+					return execRegex(callee, pattern, s), true
+				}
+			}
+		}
+	case "std.path.join", "std.path.clean", "std.path.dirname", "std.path.basename",
+		"std.path.ext", "std.path.abs", "std.path.is_abs":
+		return execPath(callee, operands, fr)
+	case "std.math.random", "std.math.random_int", "std.math.seed", "std.math.random_string":
+		return execRandom(callee, operands, fr)
 	case "std.log.debug":
 		return handleLogIntrinsic("debug", operands, fr)
 	case "std.log.info":
@@ -1627,6 +2478,54 @@ func execIntrinsic(callee string, operands []mir.Operand, fr *frame) (Result, bo
 				return Result{Type: "bool", Value: true}, true
 			}
 		}
+	case "std.math.interpolation.linear":
+		if len(operands) == 5 {
+			var args [5]float64
+			for i := range args {
+				v, err := toFloat(operandValue(fr, operands[i]))
+				if err != nil {
+					return Result{Type: "float", Value: 0.0}, true
+				}
+				args[i] = v
+			}
+			return Result{Type: "float", Value: interpLinear(args[0], args[1], args[2], args[3], args[4])}, true
+		}
+		return Result{Type: "float", Value: 0.0}, true
+	case "std.math.interpolation.lerp":
+		if len(operands) == 3 {
+			a, err1 := toFloat(operandValue(fr, operands[0]))
+			b, err2 := toFloat(operandValue(fr, operands[1]))
+			t, err3 := toFloat(operandValue(fr, operands[2]))
+			if err1 == nil && err2 == nil && err3 == nil {
+				return Result{Type: "float", Value: interpLerp(a, b, t)}, true
+			}
+		}
+		return Result{Type: "float", Value: 0.0}, true
+	case "std.math.interpolation.smooth_step":
+		if len(operands) == 1 {
+			if t, err := toFloat(operandValue(fr, operands[0])); err == nil {
+				return Result{Type: "float", Value: interpSmoothStep(t)}, true
+			}
+		}
+		return Result{Type: "float", Value: 0.0}, true
+	case "std.math.interpolation.cubic_spline":
+		if len(operands) == 2 {
+			xs, ok1 := floatSlice(operandValue(fr, operands[0]).Value)
+			ys, ok2 := floatSlice(operandValue(fr, operands[1]).Value)
+			if ok1 && ok2 {
+				return newSplineResult(interpCubicSpline(xs, ys)), true
+			}
+		}
+		return newSplineResult(&splineValue{}), true
+	case "std.math.interpolation.eval":
+		if len(operands) == 2 {
+			spline, ok := splineFromValue(operandValue(fr, operands[0]).Value)
+			x, err := toFloat(operandValue(fr, operands[1]))
+			if ok && err == nil {
+				return Result{Type: "float", Value: spline.eval(x)}, true
+			}
+		}
+		return Result{Type: "float", Value: 0.0}, true
 	case "std.math.max_float":
 		if len(operands) == 2 {
 			a := operandValue(fr, operands[0])
@@ -1898,6 +2797,14 @@ func execIntrinsic(callee string, operands []mir.Operand, fr *frame) (Result, bo
 				return Result{Type: "int", Value: idx}, true
 			}
 		}
+	case "std.string.split", "string.split":
+		if len(operands) == 2 {
+			s := operandValue(fr, operands[0])
+			sep := operandValue(fr, operands[1])
+			if s.Type == "string" && sep.Type == "string" {
+				return Result{Type: "[]<string>", Value: strings.Split(s.Value.(string), sep.Value.(string))}, true
+			}
+		}
 	case "std.string.trim":
 		if len(operands) == 1 {
 			s := operandValue(fr, operands[0])
@@ -2157,7 +3064,12 @@ func execIntrinsic(callee string, operands []mir.Operand, fr *frame) (Result, bo
 		if len(operands) == 1 {
 			testName := operandValue(fr, operands[0])
 			if testName.Type == "string" {
+				if !runsTest(fmt.Sprint(testName.Value)) {
+					fr.skipTest = true
+					return Result{Type: "void", Value: nil}, true
+				}
 				fmt.Printf("Running test: %s\n", testName.Value)
+				setCurrentTest(fmt.Sprint(testName.Value))
 				return Result{Type: "void", Value: nil}, true
 			}
 		}
@@ -2169,6 +3081,7 @@ func execIntrinsic(callee string, operands []mir.Operand, fr *frame) (Result, bo
 				return Result{Type: "void", Value: nil}, true
 			}
 
+			setCurrentTest("")
 			passedVal, _ := toBool(passed)
 			if passedVal {
 				fmt.Printf("✓ %s PASSED\n", testName.Value)
@@ -2695,7 +3608,7 @@ func literalResult(op mir.Operand) (Result, error) {
 		typ = inferLiteralType(op.Literal)
 	}
 	switch typ {
-	case "int", "long", "byte":
+	case "int", "byte":
 		// Handle hex and binary literals
 		convertedLiteral := convertLiteralToDecimal(op.Literal)
 		v, err := strconv.Atoi(convertedLiteral)
@@ -2703,6 +3616,18 @@ func literalResult(op mir.Operand) (Result, error) {
 			return Result{}, fmt.Errorf("invalid int literal %q", op.Literal)
 		}
 		return Result{Type: typ, Value: v}, nil
+	case "int64", "long":
+		v, err := strconv.ParseInt(convertLiteralToDecimal(op.Literal), 10, 64)
+		if err != nil {
+			return Result{}, fmt.Errorf("invalid int64 literal %q", op.Literal)
+		}
+		return Result{Type: "int64", Value: v}, nil
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		v, err := parseUnsignedLiteral(op.Literal)
+		if err != nil {
+			return Result{}, fmt.Errorf("invalid %s literal %q", typ, op.Literal)
+		}
+		return Result{Type: typ, Value: truncateUnsigned(v, typ)}, nil
 	case "float", "double":
 		v, err := strconv.ParseFloat(op.Literal, 64)
 		if err != nil {
@@ -2717,7 +3642,13 @@ func literalResult(op mir.Operand) (Result, error) {
 			return Result{Type: typ, Value: false}, nil
 		}
 		return Result{}, fmt.Errorf("invalid bool literal %q", op.Literal)
-	case "char", "string":
+	case "string":
+		// Escapes such as \" and \n mean what they do in the C backend
+		if s, err := strconv.Unquote(op.Literal); err == nil {
+			return Result{Type: typ, Value: s}, nil
+		}
+		return Result{Type: typ, Value: strings.Trim(op.Literal, "\"")}, nil
+	case "char":
 		return Result{Type: typ, Value: strings.Trim(op.Literal, "\"")}, nil
 	case "null":
 		return Result{Type: typ, Value: nil}, nil
@@ -2771,6 +3702,10 @@ func toInt(value Result) (int, error) {
 	switch v := value.Value.(type) {
 	case int:
 		return v, nil
+	case int64:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
 	case bool:
 		if v {
 			return 1, nil
@@ -2783,12 +3718,30 @@ func toInt(value Result) (int, error) {
 	}
 }
 
+// toInt64 converts an int or int64 value to int64.
+func toInt64(value Result) (int64, error) {
+	if v, ok := value.Value.(int64); ok {
+		return v, nil
+	}
+	i, err := toInt(value)
+	return int64(i), err
+}
+
+// isInt64Type reports whether typ is int64 or its alias long.
+func isInt64Type(typ string) bool {
+	return typ == "int64" || typ == "long"
+}
+
 func toBool(value Result) (bool, error) {
 	switch v := value.Value.(type) {
 	case bool:
 		return v, nil
 	case int:
 		return v != 0, nil
+	case int64:
+		return v != 0, nil
+	case uint64:
+		return v != 0, nil
 	default:
 		return false, fmt.Errorf("vm: expected bool value, got %T", v)
 	}
@@ -2800,6 +3753,10 @@ func toFloat(value Result) (float64, error) {
 		return v, nil
 	case int:
 		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
 	case bool:
 		if v {
 			return 1.0, nil
@@ -2822,6 +3779,8 @@ func toString(value Result) (string, error) {
 		return fmt.Sprintf("%d", v), nil
 	case int64:
 		return fmt.Sprintf("%d", v), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
 	case float32:
 		return fmt.Sprintf("%g", v), nil
 	case float64:
@@ -3088,8 +4047,14 @@ func execTestStart(funcs map[string]*mir.Function, fr *frame, inst mir.Instructi
 		return Result{}, fmt.Errorf("test.start: test_name must be string")
 	}
 
+	if !runsTest(fmt.Sprint(testName.Value)) {
+		fr.skipTest = true
+		return Result{Type: "void", Value: nil}, nil
+	}
+
 	// In the VM, we simulate test start
 	fmt.Printf("Running test: %s\n", testName.Value)
+	setCurrentTest(fmt.Sprint(testName.Value))
 	return Result{Type: "void", Value: nil}, nil
 }
 
@@ -3107,6 +4072,7 @@ func execTestEnd(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction
 	}
 
 	// In the VM, we simulate test end
+	setCurrentTest("")
 	passedVal, _ := toBool(passed)
 	if passedVal {
 		fmt.Printf("✓ %s PASSED\n", testName.Value)
@@ -3380,6 +4346,14 @@ func execBitwise(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction
 	left := operandValue(fr, inst.Operands[0])
 	right := operandValue(fr, inst.Operands[1])
 
+	// Shifts keep the type of the value shifted
+	if isUnsignedType(left.Type) {
+		return execUnsignedBitwise(inst, left.Type, left, right)
+	}
+	if typ, ok := unsignedType(right.Type); ok && inst.Op != "lshift" && inst.Op != "rshift" {
+		return execUnsignedBitwise(inst, typ, left, right)
+	}
+
 	// Convert operands to integers
 	li, err := toInt(left)
 	if err != nil {
@@ -3417,6 +4391,9 @@ func execCast(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (
 
 	operand := operandValue(fr, inst.Operands[0])
 	targetType := inst.Type
+	if operand.Type == "any" || targetType == "any" {
+		return castAny(operand.Value, targetType)
+	}
 
 	// Handle type conversions
 	switch targetType {
@@ -3446,11 +4423,56 @@ func execCast(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (
 				return Result{Type: "int", Value: val}, nil
 			}
 			return Result{}, fmt.Errorf("cast: cannot convert string %q to int", s)
+		} else if isInt64Type(operand.Type) {
+			i, err := toInt64(operand)
+			if err != nil {
+				return Result{}, err
+			}
+			// Narrow like the C backend's int32_t
+			return Result{Type: "int", Value: int(int32(i))}, nil
+		} else if isUnsignedType(operand.Type) {
+			u, err := toUint64(operand)
+			if err != nil {
+				return Result{}, err
+			}
+			return Result{Type: "int", Value: int(int32(u))}, nil
 		}
 		return Result{Type: "int", Value: operand.Value}, nil
 
+	case "int64", "long":
+		if operand.Type == "float" || operand.Type == "double" {
+			f, err := toFloat(operand)
+			if err != nil {
+				return Result{}, err
+			}
+			return Result{Type: "int64", Value: int64(f)}, nil
+		} else if operand.Type == "string" {
+			s, err := toString(operand)
+			if err != nil {
+				return Result{}, err
+			}
+			if val, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return Result{Type: "int64", Value: val}, nil
+			}
+			return Result{}, fmt.Errorf("cast: cannot convert string %q to int64", s)
+		}
+		i, err := toInt64(operand)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Type: "int64", Value: i}, nil
+
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		return castToUnsigned(operand, targetType)
+
 	case "float", "double":
-		if operand.Type == "int" {
+		if isUnsignedType(operand.Type) {
+			f, err := toFloat(operand)
+			if err != nil {
+				return Result{}, err
+			}
+			return Result{Type: "float", Value: f}, nil
+		} else if operand.Type == "int" || isInt64Type(operand.Type) {
 			i, err := toInt(operand)
 			if err != nil {
 				return Result{}, err
@@ -3479,7 +4501,7 @@ func execCast(funcs map[string]*mir.Function, fr *frame, inst mir.Instruction) (
 		return Result{Type: "float", Value: operand.Value}, nil
 
 	case "bool":
-		if operand.Type == "int" {
+		if operand.Type == "int" || isUnsignedType(operand.Type) {
 			i, err := toInt(operand)
 			if err != nil {
 				return Result{}, err
@@ -1,9 +1,14 @@
 package vm_test
 
 import (
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/omni-lang/omni/internal/mir"
+	"github.com/omni-lang/omni/internal/mir/builder"
+	"github.com/omni-lang/omni/internal/parser"
 	"github.com/omni-lang/omni/internal/vm"
 )
 
@@ -78,6 +83,87 @@ func TestArithmeticOperations(t *testing.T) {
 	}
 }
 
+// TestInt64Arithmetic checks that int64 values keep 64 bits, including when
+// an int operand is widened.
+func TestInt64Arithmetic(t *testing.T) {
+	fn := mir.NewFunction("main", "int64", nil)
+	block := fn.NewBlock("entry")
+
+	big := fn.NextValue()
+	block.Instructions = append(block.Instructions, mir.Instruction{
+		ID: big, Op: "const", Type: "int64",
+		Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "3000000000", Type: "int64"}},
+	})
+	three := fn.NextValue()
+	block.Instructions = append(block.Instructions, mir.Instruction{
+		ID: three, Op: "const", Type: "int",
+		Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "3", Type: "int"}},
+	})
+	product := fn.NextValue()
+	block.Instructions = append(block.Instructions, mir.Instruction{
+		ID: product, Op: "mul", Type: "int64",
+		Operands: []mir.Operand{
+			{Kind: mir.OperandValue, Value: big, Type: "int64"},
+			{Kind: mir.OperandValue, Value: three, Type: "int"},
+		},
+	})
+	block.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: product, Type: "int64"}}}
+
+	res, err := vm.Execute(&mir.Module{Functions: []*mir.Function{fn}}, "main")
+	if err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	if res.Type != "int64" || res.Value != int64(9000000000) {
+		t.Errorf("Expected int64 9000000000, got %s %v (%T)", res.Type, res.Value, res.Value)
+	}
+}
+
+// TestUnsignedArithmetic checks that unsigned results wrap around to the
+// width of their type and that udiv, umod and rshift treat the operands as
+// unsigned.
+func TestUnsignedArithmetic(t *testing.T) {
+	tests := []struct {
+		name        string
+		op          string
+		typ         string
+		left, right string
+		expected    uint64
+	}{
+		{"uint8_add_wraps", "add", "uint8", "250", "10", 4},
+		{"uint8_sub_wraps", "sub", "uint8", "0", "1", 255},
+		{"uint16_mul_wraps", "mul", "uint16", "0x100", "0x100", 0},
+		{"uint32_udiv", "udiv", "uint32", "0xFFFFFFFF", "2", 0x7FFFFFFF},
+		{"uint64_umod", "umod", "uint64", "18446744073709551615", "10", 5},
+		{"uint32_lshift_truncates", "lshift", "uint32", "0x80000001", "4", 0x10},
+		{"uint64_rshift_is_logical", "rshift", "uint64", "0x8000000000000000", "63", 1},
+		{"uint8_bitxor", "bitxor", "uint8", "0b10101010", "0xFF", 0x55},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := mir.NewFunction("main", tt.typ, nil)
+			block := fn.NewBlock("entry")
+			result := fn.NextValue()
+			block.Instructions = append(block.Instructions, mir.Instruction{
+				ID: result, Op: tt.op, Type: tt.typ,
+				Operands: []mir.Operand{
+					{Kind: mir.OperandLiteral, Literal: tt.left, Type: tt.typ},
+					{Kind: mir.OperandLiteral, Literal: tt.right, Type: tt.typ},
+				},
+			})
+			block.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: result, Type: tt.typ}}}
+
+			res, err := vm.Execute(&mir.Module{Functions: []*mir.Function{fn}}, "main")
+			if err != nil {
+				t.Fatalf("Execution failed: %v", err)
+			}
+			if res.Type != tt.typ || res.Value != tt.expected {
+				t.Errorf("Expected %s %d, got %s %v (%T)", tt.typ, tt.expected, res.Type, res.Value, res.Value)
+			}
+		})
+	}
+}
+
 // TestComparisonOperations tests all comparison operations
 func TestComparisonOperations(t *testing.T) {
 	tests := []struct {
@@ -515,6 +601,43 @@ func TestMapOperations(t *testing.T) {
 			t.Errorf("Expected m[\"key2\"] = 200, got %v", m["key2"])
 		}
 	})
+
+	t.Run("map_contains", func(t *testing.T) {
+		for key, want := range map[string]bool{"key1": true, "missing": false} {
+			fn := mir.NewFunction("main", "bool", nil)
+			block := fn.NewBlock("entry")
+
+			m := fn.NextValue()
+			block.Instructions = append(block.Instructions, mir.Instruction{
+				ID:   m,
+				Op:   "map.init",
+				Type: "map<string,int>",
+				Operands: []mir.Operand{
+					{Kind: mir.OperandLiteral, Literal: "key1", Type: "string"},
+					{Kind: mir.OperandLiteral, Literal: "100", Type: "int"},
+				},
+			})
+			found := fn.NextValue()
+			block.Instructions = append(block.Instructions, mir.Instruction{
+				ID:   found,
+				Op:   "map.contains",
+				Type: "bool",
+				Operands: []mir.Operand{
+					{Kind: mir.OperandValue, Value: m, Type: "map<string,int>"},
+					{Kind: mir.OperandLiteral, Literal: key, Type: "string"},
+				},
+			})
+			block.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: found, Type: "bool"}}}
+
+			res, err := vm.Execute(&mir.Module{Functions: []*mir.Function{fn}}, "main")
+			if err != nil {
+				t.Fatalf("Execution failed: %v", err)
+			}
+			if res.Type != "bool" || res.Value != want {
+				t.Errorf("map.contains(m, %q) = %v (%s), want %v", key, res.Value, res.Type, want)
+			}
+		}
+	})
 }
 
 // TestStructOperations tests struct operations
@@ -714,3 +837,441 @@ func TestComplexExpressions(t *testing.T) {
 		}
 	})
 }
+
+// TestBranchCoverage checks that cbr terminators record the paths taken when
+// coverage is enabled, including branches that never run.
+func TestBranchCoverage(t *testing.T) {
+	fn := mir.NewFunction("main", "int", nil)
+	entry := fn.NewBlock("entry")
+	then := fn.NewBlock("then")
+	other := fn.NewBlock("other")
+	one := fn.NewBlock("one")
+	two := fn.NewBlock("two")
+
+	cond := fn.NextValue()
+	entry.Instructions = append(entry.Instructions, mir.Instruction{
+		ID: cond, Op: "const", Type: "bool",
+		Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "true", Type: "bool"}},
+	})
+	branch := func(then, other string) mir.Terminator {
+		return mir.Terminator{Op: "cbr", Operands: []mir.Operand{
+			{Kind: mir.OperandValue, Value: cond, Type: "bool"},
+			{Kind: mir.OperandLiteral, Literal: then},
+			{Kind: mir.OperandLiteral, Literal: other},
+		}}
+	}
+	ret := mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "0", Type: "int"}}}
+	entry.Terminator = branch("then", "other")
+	then.Terminator = ret
+	other.Terminator = branch("one", "two")
+	one.Terminator = ret
+	two.Terminator = ret
+
+	vm.SetCoverageEnabled(true)
+	vm.ResetCoverage()
+	defer func() {
+		vm.SetCoverageEnabled(false)
+		vm.ResetCoverage()
+	}()
+
+	if _, err := vm.Execute(&mir.Module{Functions: []*mir.Function{fn}}, "main"); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+
+	data, err := vm.ExportCoverage()
+	if err != nil {
+		t.Fatalf("ExportCoverage failed: %v", err)
+	}
+	var exported struct {
+		Branches map[string]vm.BranchData `json:"branches"`
+	}
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("invalid coverage JSON: %v", err)
+	}
+
+	want := map[string]vm.BranchData{
+		"main:entry:0": {True: true},
+		"main:other:0": {},
+	}
+	if len(exported.Branches) != len(want) {
+		t.Fatalf("branches = %+v, want %+v", exported.Branches, want)
+	}
+	for key, expected := range want {
+		if got := exported.Branches[key]; got != expected {
+			t.Errorf("branch %s = %+v, want %+v", key, got, expected)
+		}
+	}
+}
+
+// TestCoverageRecordsTestName checks that calls made between test.start and
+// test.end are recorded under the name of the test.
+func TestCoverageRecordsTestName(t *testing.T) {
+	fn := mir.NewFunction("main", "int", nil)
+	entry := fn.NewBlock("entry")
+	abs := func() {
+		entry.Instructions = append(entry.Instructions, mir.Instruction{
+			ID: fn.NextValue(), Op: "call", Type: "int",
+			Operands: []mir.Operand{
+				{Kind: mir.OperandLiteral, Literal: "std.math.abs"},
+				{Kind: mir.OperandLiteral, Literal: "-3", Type: "int"},
+			},
+		})
+	}
+	testName := mir.Operand{Kind: mir.OperandLiteral, Literal: "abs_works", Type: "string"}
+	abs()
+	entry.Instructions = append(entry.Instructions, mir.Instruction{ID: fn.NextValue(), Op: "test.start", Type: "void", Operands: []mir.Operand{testName}})
+	abs()
+	abs()
+	entry.Instructions = append(entry.Instructions, mir.Instruction{ID: fn.NextValue(), Op: "test.end", Type: "void", Operands: []mir.Operand{
+		testName, {Kind: mir.OperandLiteral, Literal: "true", Type: "bool"},
+	}})
+	entry.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "0", Type: "int"}}}
+
+	vm.SetCoverageEnabled(true)
+	vm.ResetCoverage()
+	defer func() {
+		vm.SetCoverageEnabled(false)
+		vm.ResetCoverage()
+	}()
+
+	if _, err := vm.Execute(&mir.Module{Functions: []*mir.Function{fn}}, "main"); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+
+	data, err := vm.ExportCoverage()
+	if err != nil {
+		t.Fatalf("ExportCoverage failed: %v", err)
+	}
+	var exported struct {
+		Entries []struct {
+			Function string `json:"function"`
+			Count    int    `json:"count"`
+			TestName string `json:"test_name"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("invalid coverage JSON: %v", err)
+	}
+	counts := make(map[string]int)
+	for _, entry := range exported.Entries {
+		if entry.Function == "std.math.abs" {
+			counts[entry.TestName] += entry.Count
+		}
+	}
+	if counts[""] != 1 || counts["abs_works"] != 2 || len(counts) != 2 {
+		t.Fatalf("std.math.abs calls by test = %v, want 1 outside tests and 2 in abs_works", counts)
+	}
+}
+
+func TestStackTraceError(t *testing.T) {
+	// main calls outer, which calls inner, which divides by zero. The
+	// callers add one to the result so that the calls are not tail calls.
+	caller := func(name, callee string) *mir.Function {
+		fn := mir.NewFunction(name, "int", nil)
+		block := fn.NewBlock("entry")
+		call := fn.NextValue()
+		result := fn.NextValue()
+		block.Instructions = append(block.Instructions, mir.Instruction{
+			ID: call, Op: "call", Type: "int",
+			Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: callee}},
+		}, mir.Instruction{
+			ID: result, Op: "add", Type: "int",
+			Operands: []mir.Operand{
+				{Kind: mir.OperandValue, Value: call, Type: "int"},
+				{Kind: mir.OperandLiteral, Literal: "1", Type: "int"},
+			},
+		})
+		block.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: result, Type: "int"}}}
+		return fn
+	}
+	inner := mir.NewFunction("inner", "int", nil)
+	block := inner.NewBlock("entry")
+	result := inner.NextValue()
+	block.Instructions = append(block.Instructions, mir.Instruction{
+		ID: result, Op: "div", Type: "int",
+		Operands: []mir.Operand{
+			{Kind: mir.OperandLiteral, Literal: "1", Type: "int"},
+			{Kind: mir.OperandLiteral, Literal: "0", Type: "int"},
+		},
+	})
+	block.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandValue, Value: result, Type: "int"}}}
+	mod := &mir.Module{Functions: []*mir.Function{caller("main", "outer"), caller("outer", "inner"), inner}}
+
+	_, err := vm.Execute(mod, "main")
+	var trace vm.StackTraceError
+	if !errors.As(err, &trace) {
+		t.Fatalf("expected a StackTraceError, got %T: %v", err, err)
+	}
+	if got := strings.Join(trace.Frames, ","); got != "inner,outer,main" {
+		t.Errorf("expected frames inner,outer,main, got %s", got)
+	}
+	if got := err.Error(); got != "vm: inner: division by zero" {
+		t.Errorf("expected the error of the failing function, got %q", got)
+	}
+	want := "stack trace:\n  at inner\n  at outer\n  at main\n"
+	if got := trace.StackTrace(); got != want {
+		t.Errorf("StackTrace() = %q, want %q", got, want)
+	}
+}
+
+// buildSource parses src and builds its MIR module.
+func buildSource(t *testing.T, src string) *mir.Module {
+	t.Helper()
+	astMod, err := parser.Parse("test.omni", src)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	mod, err := builder.BuildModule(astMod)
+	if err != nil {
+		t.Fatalf("build MIR: %v", err)
+	}
+	return mod
+}
+
+func TestTailCallDeepRecursion(t *testing.T) {
+	mod := buildSource(t, `func count(n:int, acc:int):int {
+  if n == 0 {
+    return acc
+  }
+  return count(n - 1, acc + 2)
+}
+func main():int => count(10000, 0)
+`)
+	res, err := vm.Execute(mod, "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 20000 {
+		t.Errorf("expected 20000, got %v", res.Value)
+	}
+}
+
+func TestTailCallReplacesFrame(t *testing.T) {
+	// The division by zero happens 10,000 tail calls deep; with the calls
+	// replacing each other only count and main are on the stack
+	mod := buildSource(t, `func count(n:int):int {
+  if n == 0 {
+    return 1 / n
+  }
+  return count(n - 1)
+}
+func main():int {
+  let r:int = count(10000)
+  return r + 1
+}
+`)
+	_, err := vm.Execute(mod, "main")
+	var trace vm.StackTraceError
+	if !errors.As(err, &trace) {
+		t.Fatalf("expected a StackTraceError, got %v", err)
+	}
+	if got := strings.Join(trace.Frames, ","); got != "count,main" {
+		t.Errorf("expected frames count,main, got %s", got)
+	}
+}
+
+func TestDivisionByZeroError(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  string
+		args string
+		op   string
+		want string
+	}{
+		{"int div", "int", "7, 0", "/", "vm: ratio: division by zero at test.omni:2:"},
+		{"int mod", "int", "7, 0", "%", "vm: ratio: modulo by zero at test.omni:2:"},
+		{"float div", "float", "7.0, -0.0", "/", "vm: ratio: division by zero at test.omni:2:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mod := buildSource(t, `func ratio(a:`+tt.typ+`, b:`+tt.typ+`):`+tt.typ+` {
+  let q:`+tt.typ+` = a `+tt.op+` b
+  return q
+}
+func main():`+tt.typ+` {
+  let r:`+tt.typ+` = ratio(`+tt.args+`)
+  return r
+}
+`)
+			_, err := vm.Execute(mod, "main")
+			var divErr vm.DivisionByZeroError
+			if !errors.As(err, &divErr) {
+				t.Fatalf("expected a DivisionByZeroError, got %v", err)
+			}
+			if divErr.Location.Line != 2 {
+				t.Errorf("error location = %s, want line 2", divErr.Location)
+			}
+			if got := err.Error(); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("error = %q, want it to start with %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// phiLoop builds the SSA form of
+//
+//	var a = 0; var b = 1
+//	for var i = 0; i < 5; i++ { <body> }
+//
+// where body computes the next i, a and b from the phis of the loop header,
+// and ret picks the returned value from the final a and b.
+func phiLoop(body func(fn *mir.Function, block *mir.BasicBlock, i, a, b mir.Operand) (next, nextA, nextB mir.Operand), ret func(a, b mir.Operand) mir.Operand) *mir.Function {
+	lit := func(v string) mir.Operand { return mir.Operand{Kind: mir.OperandLiteral, Literal: v, Type: "int"} }
+	val := func(id mir.ValueID) mir.Operand { return mir.Operand{Kind: mir.OperandValue, Value: id, Type: "int"} }
+	label := func(name string) mir.Operand { return mir.Operand{Kind: mir.OperandLiteral, Literal: name} }
+
+	fn := mir.NewFunction("main", "int", nil)
+	entry := fn.NewBlock("entry")
+	header := fn.NewBlock("loop_header")
+	loop := fn.NewBlock("loop_body")
+	exit := fn.NewBlock("loop_exit")
+
+	i, a, b := fn.NextValue(), fn.NextValue(), fn.NextValue()
+	cond := fn.NextValue()
+	next, nextA, nextB := body(fn, loop, val(i), val(a), val(b))
+
+	entry.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{label("loop_header")}}
+	header.Instructions = []mir.Instruction{
+		{ID: i, Op: "phi", Type: "int", Operands: []mir.Operand{lit("0"), label("entry"), next, label("loop_body")}},
+		{ID: a, Op: "phi", Type: "int", Operands: []mir.Operand{lit("0"), label("entry"), nextA, label("loop_body")}},
+		{ID: b, Op: "phi", Type: "int", Operands: []mir.Operand{lit("1"), label("entry"), nextB, label("loop_body")}},
+		{ID: cond, Op: "cmp.lt", Type: "bool", Operands: []mir.Operand{val(i), lit("5")}},
+	}
+	header.Terminator = mir.Terminator{Op: "cbr", Operands: []mir.Operand{val(cond), label("loop_body"), label("loop_exit")}}
+	loop.Terminator = mir.Terminator{Op: "br", Operands: []mir.Operand{label("loop_header")}}
+	exit.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{ret(val(a), val(b))}}
+	return fn
+}
+
+func TestPhiLoopAccumulates(t *testing.T) {
+	// a += i for i = 0..4
+	fn := phiLoop(func(fn *mir.Function, block *mir.BasicBlock, i, a, b mir.Operand) (mir.Operand, mir.Operand, mir.Operand) {
+		sum, inc := fn.NextValue(), fn.NextValue()
+		block.Instructions = []mir.Instruction{
+			{ID: sum, Op: "add", Type: "int", Operands: []mir.Operand{a, i}},
+			{ID: inc, Op: "add", Type: "int", Operands: []mir.Operand{i, {Kind: mir.OperandLiteral, Literal: "1", Type: "int"}}},
+		}
+		return mir.Operand{Kind: mir.OperandValue, Value: inc, Type: "int"}, mir.Operand{Kind: mir.OperandValue, Value: sum, Type: "int"}, b
+	}, func(a, b mir.Operand) mir.Operand { return a })
+
+	res, err := vm.Execute(&mir.Module{Functions: []*mir.Function{fn}}, "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Value != 10 {
+		t.Errorf("expected 0+1+2+3+4 = 10, got %v", res.Value)
+	}
+}
+
+func TestPhiLoopSwapsInParallel(t *testing.T) {
+	// a, b = a + b, a: the phi of b reads the value a had before the phi of
+	// a, which comes first, was updated
+	fn := phiLoop(func(fn *mir.Function, block *mir.BasicBlock, i, a, b mir.Operand) (mir.Operand, mir.Operand, mir.Operand) {
+		sum, inc := fn.NextValue(), fn.NextValue()
+		block.Instructions = []mir.Instruction{
+			{ID: sum, Op: "add", Type: "int", Operands: []mir.Operand{a, b}},
+			{ID: inc, Op: "add", Type: "int", Operands: []mir.Operand{i, {Kind: mir.OperandLiteral, Literal: "1", Type: "int"}}},
+		}
+		return mir.Operand{Kind: mir.OperandValue, Value: inc, Type: "int"}, mir.Operand{Kind: mir.OperandValue, Value: sum, Type: "int"}, a
+	}, func(a, b mir.Operand) mir.Operand { return a })
+
+	res, err := vm.Execute(&mir.Module{Functions: []*mir.Function{fn}}, "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	// Fibonacci: (0,1) (1,0) (1,1) (2,1) (3,2) (5,3)
+	if res.Value != 5 {
+		t.Errorf("expected the fifth Fibonacci number 5, got %v", res.Value)
+	}
+}
+
+func TestLoopBreakAndContinue(t *testing.T) {
+	mod := buildSource(t, `func main():int {
+  var sum:int = 0
+  for var i:int = 0; i < 10; i++ {
+    if i == 7 {
+      break
+    }
+    if i % 2 == 0 {
+      continue
+    }
+    sum = sum + i
+  }
+  for x in [10, 20, 30, 40] {
+    if x == 20 {
+      continue
+    }
+    if x == 40 {
+      break
+    }
+    sum = sum + x
+  }
+  return sum
+}
+`)
+	res, err := vm.Execute(mod, "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	// 1 + 3 + 5 from the first loop, 10 + 30 from the second
+	if res.Value != 49 {
+		t.Errorf("result = %v, want 49", res.Value)
+	}
+}
+
+func TestWhileLoop(t *testing.T) {
+	mod := buildSource(t, `func collatz(start:int):int {
+  var n:int = start
+  var steps:int = 0
+  while n != 1 {
+    if n % 2 == 0 {
+      n = n / 2
+    } else {
+      n = 3 * n + 1
+    }
+    steps = steps + 1
+  }
+  return steps
+}
+func main():int {
+  var total:int = 0
+  var i:int = 0
+  while i < 10 {
+    i = i + 1
+    if i == 3 {
+      continue
+    }
+    if i == 8 {
+      break
+    }
+    var j:int = 0
+    while j < i {
+      j = j + 1
+      total = total + 1
+    }
+  }
+  return total * 1000 + collatz(27)
+}
+`)
+	res, err := vm.Execute(mod, "main")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	// 1 + 2 + 4 + 5 + 6 + 7 inner iterations, and 111 steps from 27
+	if res.Value != 25111 {
+		t.Errorf("result = %v, want 25111", res.Value)
+	}
+}
+
+func TestUnsupportedInstructionReportsLocation(t *testing.T) {
+	fn := mir.NewFunction("main", "int", nil)
+	entry := fn.NewBlock("entry")
+	entry.Instructions = []mir.Instruction{{ID: fn.NextValue(), Op: "bogus", Type: "int",
+		Location: mir.SourceLocation{File: "main.omni", Line: 3, Column: 7}}}
+	entry.Terminator = mir.Terminator{Op: "ret", Operands: []mir.Operand{{Kind: mir.OperandLiteral, Literal: "0", Type: "int"}}}
+
+	_, err := vm.Execute(&mir.Module{Functions: []*mir.Function{fn}}, "main")
+	if err == nil || !strings.Contains(err.Error(), `unsupported instruction "bogus" at main.omni:3:7`) {
+		t.Fatalf("expected the error to locate the instruction, got %v", err)
+	}
+}
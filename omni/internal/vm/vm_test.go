@@ -3,6 +3,8 @@ package vm
 import (
 	"strings"
 	"testing"
+
+	"github.com/omni-lang/omni/internal/mir"
 )
 
 func TestNewObjectPool(t *testing.T) {
@@ -151,3 +153,30 @@ func TestObjectPoolConcurrency(t *testing.T) {
 		<-done
 	}
 }
+
+func TestHeapCollectKeepsReachableObjects(t *testing.T) {
+	h := newHeap(1)
+	inner := map[string]interface{}{"x": 1}
+	outer := []interface{}{inner}
+	dropped := map[string]interface{}{"x": 2}
+	returned := []interface{}{3}
+	for _, value := range []interface{}{inner, outer, dropped, returned} {
+		h.track(value)
+	}
+	fr := &frame{values: map[mir.ValueID]Result{0: {Type: "array<Point>", Value: outer}}}
+	h.enter(fr)
+
+	if freed := h.collect(Result{Type: "array<int>", Value: returned}); freed != 1 {
+		t.Errorf("collect freed %d objects, want 1", freed)
+	}
+	for name, value := range map[string]interface{}{"inner": inner, "outer": outer, "returned": returned} {
+		if addr, _ := heapAddr(value); h.objects[addr] == nil {
+			t.Errorf("%s was collected while reachable", name)
+		}
+	}
+
+	h.leave(fr)
+	if freed := h.collect(); freed != 3 || len(h.objects) != 0 {
+		t.Errorf("collect after the frame returned freed %d, left %d objects", freed, len(h.objects))
+	}
+}
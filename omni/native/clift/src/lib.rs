@@ -487,7 +487,7 @@ fn compile_terminator(
                 builder.ins().return_(&[]);
             }
         }
-        "br" => {
+        "br" | "loop.break" | "loop.continue" => {
             if terminator.operands.is_empty() {
                 return Err(CompileError::MirParse(
                     "br terminator requires target block".to_string(),
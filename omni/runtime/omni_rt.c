@@ -0,0 +1,7828 @@
+// Enable POSIX and GNU extensions for functions like localtime_r, setenv, strdup, etc.
+#ifndef _POSIX_C_SOURCE
+#define _POSIX_C_SOURCE 200809L
+#endif
+#ifndef _GNU_SOURCE
+#define _GNU_SOURCE
+#endif
+
+#include "omni_rt.h"
+#include <stdlib.h>
+#include <string.h>
+#include <stdio.h>
+#include <sys/stat.h>
+#include <math.h>
+#include <time.h>
+#include <ctype.h>
+#include <stdint.h>
+#include <errno.h>
+#include <limits.h>
+#include <locale.h>
+#include <regex.h>
+#include <signal.h>
+#ifdef _WIN32
+#include <windows.h>
+#include <direct.h>
+#include <io.h>
+#include <process.h>
+#else
+#include <pthread.h>
+#include <unistd.h>
+#include <sys/stat.h>
+#include <sys/types.h>
+#include <sys/wait.h>
+#include <fcntl.h>
+#include <poll.h>
+#include <time.h>
+#endif
+
+// Test framework state
+static int32_t total_tests = 0;
+static int32_t passed_tests = 0;
+static int32_t current_test_passed = 1;
+
+// Logging state
+enum {
+    OMNI_LOG_LEVEL_DEBUG = 0,
+    OMNI_LOG_LEVEL_INFO = 1,
+    OMNI_LOG_LEVEL_WARN = 2,
+    OMNI_LOG_LEVEL_ERROR = 3,
+};
+
+static int32_t omni_current_log_level = OMNI_LOG_LEVEL_INFO;
+
+// Thread-safety for logging
+#ifdef _WIN32
+static CRITICAL_SECTION omni_log_mutex;
+static int omni_log_mutex_initialized = 0;
+#else
+static pthread_mutex_t omni_log_mutex = PTHREAD_MUTEX_INITIALIZER;
+#endif
+
+static int omni_equals_ignore_case(const char* a, const char* b) {
+    if (!a || !b) {
+        return 0;
+    }
+    while (*a && *b) {
+        unsigned char ca = (unsigned char)(*a);
+        unsigned char cb = (unsigned char)(*b);
+        ca = (unsigned char)tolower(ca);
+        cb = (unsigned char)tolower(cb);
+        if (ca != cb) {
+            return 0;
+        }
+        a++;
+        b++;
+    }
+    return *a == '\0' && *b == '\0';
+}
+
+static void omni_log_write(int32_t level, const char* level_name, const char* message) {
+    if (level < omni_current_log_level) {
+        return;
+    }
+    
+    // Thread-safe logging: acquire mutex
+#ifdef _WIN32
+    if (!omni_log_mutex_initialized) {
+        InitializeCriticalSection(&omni_log_mutex);
+        omni_log_mutex_initialized = 1;
+    }
+    EnterCriticalSection(&omni_log_mutex);
+#else
+    pthread_mutex_lock(&omni_log_mutex);
+#endif
+    
+    time_t now = time(NULL);
+    char timebuf[32];
+#if defined(_WIN32)
+    struct tm tm_info;
+    if (localtime_s(&tm_info, &now) != 0) {
+        strncpy(timebuf, "0000-00-00 00:00:00", sizeof(timebuf));
+        timebuf[sizeof(timebuf) - 1] = '\0';
+    } else {
+        if (strftime(timebuf, sizeof(timebuf), "%Y-%m-%d %H:%M:%S", &tm_info) == 0) {
+            strncpy(timebuf, "0000-00-00 00:00:00", sizeof(timebuf));
+            timebuf[sizeof(timebuf) - 1] = '\0';
+        }
+    }
+#else
+    struct tm tm_info;
+    if (localtime_r(&now, &tm_info) == NULL) {
+        strncpy(timebuf, "0000-00-00 00:00:00", sizeof(timebuf));
+        timebuf[sizeof(timebuf) - 1] = '\0';
+    } else {
+        if (strftime(timebuf, sizeof(timebuf), "%Y-%m-%d %H:%M:%S", &tm_info) == 0) {
+            strncpy(timebuf, "0000-00-00 00:00:00", sizeof(timebuf));
+            timebuf[sizeof(timebuf) - 1] = '\0';
+        }
+    }
+#endif
+    fprintf(stderr, "%s - [%s] %s\n", timebuf, level_name, message ? message : "");
+    fflush(stderr);
+    
+    // Release mutex
+#ifdef _WIN32
+    LeaveCriticalSection(&omni_log_mutex);
+#else
+    pthread_mutex_unlock(&omni_log_mutex);
+#endif
+}
+
+void omni_log_debug(const char* message) {
+    omni_log_write(OMNI_LOG_LEVEL_DEBUG, "DEBUG", message);
+}
+
+void omni_log_info(const char* message) {
+    omni_log_write(OMNI_LOG_LEVEL_INFO, "INFO", message);
+}
+
+void omni_log_warn(const char* message) {
+    omni_log_write(OMNI_LOG_LEVEL_WARN, "WARN", message);
+}
+
+void omni_log_error(const char* message) {
+    omni_log_write(OMNI_LOG_LEVEL_ERROR, "ERROR", message);
+}
+
+int32_t omni_log_set_level(const char* level) {
+    if (!level) {
+        return 0;
+    }
+    if (omni_equals_ignore_case(level, "DEBUG")) {
+        omni_current_log_level = OMNI_LOG_LEVEL_DEBUG;
+        return 1;
+    }
+    if (omni_equals_ignore_case(level, "INFO")) {
+        omni_current_log_level = OMNI_LOG_LEVEL_INFO;
+        return 1;
+    }
+    if (omni_equals_ignore_case(level, "WARN") || omni_equals_ignore_case(level, "WARNING")) {
+        omni_current_log_level = OMNI_LOG_LEVEL_WARN;
+        return 1;
+    }
+    if (omni_equals_ignore_case(level, "ERROR") || omni_equals_ignore_case(level, "ERR")) {
+        omni_current_log_level = OMNI_LOG_LEVEL_ERROR;
+        return 1;
+    }
+    return 0;
+}
+
+void omni_print_string(const char* str) {
+    printf("%s", str);
+}
+
+void omni_println_string(const char* str) {
+    printf("%s\n", str);
+}
+
+void omni_write_string(const char* str) {
+    fputs(str, stdout);
+}
+
+// The stderr variants flush stdout first so that output written before
+// them comes first when both streams go to the same place
+void omni_eprint_string(const char* str) {
+    fflush(stdout);
+    fputs(str, stderr);
+}
+
+void omni_eprintln_string(const char* str) {
+    fflush(stdout);
+    fprintf(stderr, "%s\n", str);
+}
+
+// NOTE: Returns a newly allocated string - caller must free it using free()
+// This function allocates memory that must be freed by the caller to avoid leaks.
+char* omni_read_line(void) {
+    size_t capacity = 128;
+    size_t length = 0;
+    char* buffer = malloc(capacity);
+    if (!buffer) {
+        return NULL;
+    }
+
+    int c;
+    while ((c = fgetc(stdin)) != EOF) {
+        if (c == '\r') {
+            int next = fgetc(stdin);
+            if (next != '\n' && next != EOF) {
+                ungetc(next, stdin);
+            }
+            break;
+        }
+        if (c == '\n') {
+            break;
+        }
+        if (length + 1 >= capacity) {
+            capacity *= 2;
+            char* new_buffer = realloc(buffer, capacity);
+            if (!new_buffer) {
+                free(buffer);
+                return NULL;
+            }
+            buffer = new_buffer;
+        }
+        buffer[length++] = (char)c;
+    }
+
+    if (c == EOF && length == 0) {
+        buffer[0] = '\0';
+        return buffer;
+    }
+
+    buffer[length] = '\0';
+    return buffer;
+}
+
+char* omni_prompt(const char* message) {
+    fputs(message ? message : "", stdout);
+    fflush(stdout);
+    return omni_read_line();
+}
+
+#define OMNI_PROMPT_ATTEMPTS 3
+
+// omni_prompt_number reads lines with the prompt until one holds a number
+// that parse accepts once surrounding whitespace is trimmed. It gives up
+// after OMNI_PROMPT_ATTEMPTS lines, or at the end of stdin.
+static int omni_prompt_number(const char* message, int (*parse)(const char*, void*), void* out) {
+    for (int attempt = 0; attempt < OMNI_PROMPT_ATTEMPTS; attempt++) {
+        char* line = omni_prompt(message);
+        if (!line) {
+            return 0;
+        }
+        int at_end = line[0] == '\0' && feof(stdin);
+        char* start = line;
+        while (isspace((unsigned char)*start)) {
+            start++;
+        }
+        char* end = start + strlen(start);
+        while (end > start && isspace((unsigned char)end[-1])) {
+            *--end = '\0';
+        }
+        int ok = *start != '\0' && parse(start, out);
+        free(line);
+        if (ok) {
+            return 1;
+        }
+        if (at_end) {
+            return 0;
+        }
+    }
+    return 0;
+}
+
+static int omni_parse_prompt_int(const char* str, void* out) {
+    char* endptr;
+    errno = 0;
+    long value = strtol(str, &endptr, 10);
+    if (*endptr != '\0' || errno == ERANGE || value < INT32_MIN || value > INT32_MAX) {
+        return 0;
+    }
+    *(int32_t*)out = (int32_t)value;
+    return 1;
+}
+
+static int omni_parse_prompt_float(const char* str, void* out) {
+    char* endptr;
+    errno = 0;
+    double value = strtod(str, &endptr);
+    if (*endptr != '\0' || errno == ERANGE) {
+        return 0;
+    }
+    *(double*)out = value;
+    return 1;
+}
+
+int32_t omni_prompt_int(const char* message, int32_t default_val) {
+    int32_t value;
+    return omni_prompt_number(message, omni_parse_prompt_int, &value) ? value : default_val;
+}
+
+double omni_prompt_float(const char* message, double default_val) {
+    double value;
+    return omni_prompt_number(message, omni_parse_prompt_float, &value) ? value : default_val;
+}
+
+// Memory management
+void* omni_alloc(size_t size) {
+    return malloc(size);
+}
+
+void omni_free(void* ptr) {
+    free(ptr);
+}
+
+void* omni_malloc(size_t size) {
+    return malloc(size);
+}
+
+void* omni_realloc(void* ptr, size_t new_size) {
+    return realloc(ptr, new_size);
+}
+
+// String operations
+// NOTE: Returns a newly allocated string - caller must free it using free()
+// This function allocates memory that must be freed by the caller to avoid leaks.
+char* omni_strcat(const char* str1, const char* str2) {
+    size_t len1 = strlen(str1);
+    size_t len2 = strlen(str2);
+    char* result = malloc(len1 + len2 + 1);
+    if (result) {
+        strcpy(result, str1);
+        strcat(result, str2);
+    }
+    return result;
+}
+
+int32_t omni_strlen(const char* str) {
+    return (int32_t)strlen(str);
+}
+
+// Helper function to find the start of the next UTF-8 rune
+static const char* next_utf8_rune(const char* str) {
+    if (!str || *str == '\0') return str;
+    // Skip continuation bytes (0x80-0xBF)
+    while ((*str & 0xC0) == 0x80) {
+        str++;
+    }
+    return str;
+}
+
+// Helper function to find the start of the previous UTF-8 rune
+static const char* __attribute__((unused)) prev_utf8_rune(const char* str, const char* start) {
+    if (!str || str <= start) return start;
+    str--;
+    // Skip continuation bytes backwards
+    while (str > start && (*str & 0xC0) == 0x80) {
+        str--;
+    }
+    return str;
+}
+
+// NOTE: Returns a newly allocated string - caller must free it using free()
+// This function allocates memory that must be freed by the caller to avoid leaks.
+// WARNING: This function treats start/end as byte indices, not rune indices.
+// For proper UTF-8 support, indices should be rune-based, but that requires
+// counting runes which is O(n). This implementation at least ensures we don't
+// split in the middle of a multi-byte character.
+char* omni_substring(const char* str, int32_t start, int32_t end) {
+    if (!str || start < 0 || end < start) {
+        char* empty = (char*)malloc(1);
+        if (empty) empty[0] = '\0';
+        return empty;
+    }
+    
+    int32_t len = (int32_t)strlen(str);
+    if (start >= len) {
+        char* empty = (char*)malloc(1);
+        if (empty) empty[0] = '\0';
+        return empty;
+    }
+    
+    if (end > len) {
+        end = len;
+    }
+    
+    // Ensure we start at a valid UTF-8 boundary
+    const char* start_ptr = str + start;
+    if (start > 0) {
+        start_ptr = next_utf8_rune(start_ptr);
+    }
+    
+    // Ensure we end at a valid UTF-8 boundary
+    const char* end_ptr = str + end;
+    if (end < len) {
+        end_ptr = next_utf8_rune(end_ptr);
+    }
+    
+    int32_t sublen = (int32_t)(end_ptr - start_ptr);
+    char* result = (char*)malloc(sublen + 1);
+    if (result) {
+        strncpy(result, start_ptr, sublen);
+        result[sublen] = '\0';
+    }
+    return result;
+}
+
+char omni_char_at(const char* str, int32_t index) {
+    if (!str || index < 0 || index >= (int32_t)strlen(str)) {
+        return '\0';
+    }
+    return str[index];
+}
+
+int32_t omni_starts_with(const char* str, const char* prefix) {
+    if (!str || !prefix) {
+        return 0;
+    }
+    return strncmp(str, prefix, strlen(prefix)) == 0 ? 1 : 0;
+}
+
+int32_t omni_ends_with(const char* str, const char* suffix) {
+    if (!str || !suffix) {
+        return 0;
+    }
+    
+    int32_t str_len = (int32_t)strlen(str);
+    int32_t suffix_len = (int32_t)strlen(suffix);
+    
+    if (suffix_len > str_len) {
+        return 0;
+    }
+    
+    return strcmp(str + str_len - suffix_len, suffix) == 0 ? 1 : 0;
+}
+
+int32_t omni_contains(const char* str, const char* substr) {
+    if (!str || !substr) {
+        return 0;
+    }
+    return strstr(str, substr) != NULL ? 1 : 0;
+}
+
+int32_t omni_index_of(const char* str, const char* substr) {
+    if (!str || !substr) {
+        return -1;
+    }
+    
+    char* pos = strstr(str, substr);
+    if (pos == NULL) {
+        return -1;
+    }
+    
+    return (int32_t)(pos - str);
+}
+
+int32_t omni_last_index_of(const char* str, const char* substr) {
+    if (!str || !substr) {
+        return -1;
+    }
+    
+    int32_t str_len = (int32_t)strlen(str);
+    int32_t substr_len = (int32_t)strlen(substr);
+    
+    if (substr_len == 0) {
+        return str_len;
+    }
+    
+    for (int32_t i = str_len - substr_len; i >= 0; i--) {
+        if (strncmp(str + i, substr, substr_len) == 0) {
+            return i;
+        }
+    }
+    
+    return -1;
+}
+
+// NOTE: Returns a newly allocated string - caller must free it using free()
+// This function allocates memory that must be freed by the caller to avoid leaks.
+char* omni_trim(const char* str) {
+    if (!str) {
+        return NULL;
+    }
+    
+    // Handle empty string
+    size_t str_len = strlen(str);
+    if (str_len == 0) {
+        char* result = malloc(1);
+        if (result) {
+            result[0] = '\0';
+        }
+        return result;
+    }
+    
+    // Find start of non-whitespace
+    const char* start = str;
+    while (*start && (*start == ' ' || *start == '\t' || *start == '\n' || *start == '\r')) {
+        start++;
+    }
+    
+    // If entire string is whitespace, return empty string
+    if (*start == '\0') {
+        char* result = malloc(1);
+        if (result) {
+            result[0] = '\0';
+        }
+        return result;
+    }
+    
+    // Find end of non-whitespace (safe: we know start is valid)
+    const char* end = str + str_len - 1;
+    while (end >= start && (*end == ' ' || *end == '\t' || *end == '\n' || *end == '\r')) {
+        end--;
+    }
+    
+    // Calculate length (end >= start is guaranteed at this point)
+    int32_t len = (int32_t)(end - start + 1);
+    char* result = malloc(len + 1);
+    if (result) {
+        strncpy(result, start, len);
+        result[len] = '\0';
+    }
+    return result;
+}
+
+char* omni_to_upper(const char* str) {
+    if (!str) {
+        return NULL;
+    }
+    
+    int32_t len = (int32_t)strlen(str);
+    char* result = malloc(len + 1);
+    if (result) {
+        for (int32_t i = 0; i < len; i++) {
+            char c = str[i];
+            if (c >= 'a' && c <= 'z') {
+                result[i] = c - 'a' + 'A';
+            } else {
+                result[i] = c;
+            }
+        }
+        result[len] = '\0';
+    }
+    return result;
+}
+
+char* omni_to_lower(const char* str) {
+    if (!str) {
+        return NULL;
+    }
+    
+    int32_t len = (int32_t)strlen(str);
+    char* result = malloc(len + 1);
+    if (result) {
+        for (int32_t i = 0; i < len; i++) {
+            char c = str[i];
+            if (c >= 'A' && c <= 'Z') {
+                result[i] = c - 'A' + 'a';
+            } else {
+                result[i] = c;
+            }
+        }
+        result[len] = '\0';
+    }
+    return result;
+}
+
+int32_t omni_string_equals(const char* a, const char* b) {
+    if (!a || !b) {
+        return (a == b) ? 1 : 0;
+    }
+    return strcmp(a, b) == 0 ? 1 : 0;
+}
+
+int32_t omni_string_compare(const char* a, const char* b) {
+    if (!a || !b) {
+        if (!a && !b) return 0;
+        if (!a) return -1;
+        return 1;
+    }
+    return strcmp(a, b);
+}
+
+// omni_string_split splits s around each occurrence of sep, like Go's
+// strings.Split: an empty sep splits s into its UTF-8 characters. It stores
+// the number of pieces in count_out.
+// NOTE: Returns a newly allocated array of newly allocated strings
+char** omni_string_split(const char* s, const char* sep, int32_t* count_out) {
+    if (count_out) {
+        *count_out = 0;
+    }
+    if (!s || !sep) {
+        return NULL;
+    }
+
+    size_t sep_len = strlen(sep);
+    int32_t count = 0;
+    if (sep_len == 0) {
+        for (const char* p = s; *p; p = next_utf8_rune(p + 1)) {
+            count++;
+        }
+    } else {
+        count = 1;
+        for (const char* p = strstr(s, sep); p; p = strstr(p + sep_len, sep)) {
+            count++;
+        }
+    }
+
+    char** parts = malloc((count > 0 ? count : 1) * sizeof(char*));
+    if (!parts) {
+        return NULL;
+    }
+    const char* start = s;
+    for (int32_t i = 0; i < count; i++) {
+        const char* end;
+        if (sep_len == 0) {
+            end = next_utf8_rune(start + 1);
+        } else {
+            end = strstr(start, sep);
+            if (!end) {
+                end = start + strlen(start);
+            }
+        }
+        size_t len = (size_t)(end - start);
+        parts[i] = malloc(len + 1);
+        if (parts[i]) {
+            memcpy(parts[i], start, len);
+            parts[i][len] = '\0';
+        }
+        start = end + sep_len;
+    }
+    if (count_out) {
+        *count_out = count;
+    }
+    return parts;
+}
+
+char* omni_string_repeat(const char* s, int32_t n) {
+    if (n < 0) {
+        char message[64];
+        snprintf(message, sizeof(message), "string.repeat: negative count %d", n);
+        omni_panic(message);
+    }
+    size_t len = s ? strlen(s) : 0;
+    char* result = malloc(len * (size_t)n + 1);
+    if (!result) {
+        return NULL;
+    }
+    for (int32_t i = 0; i < n; i++) {
+        memcpy(result + len * (size_t)i, s, len);
+    }
+    result[len * (size_t)n] = '\0';
+    return result;
+}
+
+// omni_string_pad pads s to width bytes with the single character pad. side
+// says where the padding goes: 'l' on the left, 'r' on the right and 'c' on
+// both sides, the extra character of an odd padding going on the right.
+static char* omni_string_pad(const char* name, const char* s, int32_t width, const char* pad, char side) {
+    if (!pad || strlen(pad) != 1) {
+        char message[128];
+        snprintf(message, sizeof(message), "%s: padding must be a single character, got \"%s\"", name, pad ? pad : "");
+        omni_panic(message);
+    }
+    size_t len = s ? strlen(s) : 0;
+    size_t padding = width > 0 && (size_t)width > len ? (size_t)width - len : 0;
+    size_t left = side == 'l' ? padding : side == 'c' ? padding / 2 : 0;
+    char* result = malloc(len + padding + 1);
+    if (!result) {
+        return NULL;
+    }
+    memset(result, pad[0], len + padding);
+    if (len > 0) {
+        memcpy(result + left, s, len);
+    }
+    result[len + padding] = '\0';
+    return result;
+}
+
+char* omni_string_pad_left(const char* s, int32_t width, const char* pad) {
+    return omni_string_pad("string.pad_left", s, width, pad, 'l');
+}
+
+char* omni_string_pad_right(const char* s, int32_t width, const char* pad) {
+    return omni_string_pad("string.pad_right", s, width, pad, 'r');
+}
+
+char* omni_string_center(const char* s, int32_t width, const char* pad) {
+    return omni_string_pad("string.center", s, width, pad, 'c');
+}
+
+int32_t omni_string_count_occurrences(const char* s, const char* substr) {
+    if (!s || !substr || substr[0] == '\0') {
+        return 0;
+    }
+    size_t sublen = strlen(substr);
+    int32_t count = 0;
+    for (const char* p = strstr(s, substr); p; p = strstr(p + sublen, substr)) {
+        count++;
+    }
+    return count;
+}
+
+// omni_string_replace replaces the first max occurrences of old in s, or all
+// of them when max is negative.
+static char* omni_string_replace(const char* s, const char* old, const char* replacement, int32_t max) {
+    if (!s) {
+        s = "";
+    }
+    if (!replacement) {
+        replacement = "";
+    }
+    int32_t count = omni_string_count_occurrences(s, old);
+    if (max >= 0 && count > max) {
+        count = max;
+    }
+    size_t len = strlen(s);
+    size_t oldlen = count > 0 ? strlen(old) : 0;
+    size_t newlen = strlen(replacement);
+    char* result = malloc(len - oldlen * (size_t)count + newlen * (size_t)count + 1);
+    if (!result) {
+        return NULL;
+    }
+    char* out = result;
+    const char* rest = s;
+    for (int32_t i = 0; i < count; i++) {
+        const char* match = strstr(rest, old);
+        memcpy(out, rest, (size_t)(match - rest));
+        out += match - rest;
+        memcpy(out, replacement, newlen);
+        out += newlen;
+        rest = match + oldlen;
+    }
+    strcpy(out, rest);
+    return result;
+}
+
+char* omni_string_replace_first(const char* s, const char* old, const char* replacement) {
+    return omni_string_replace(s, old, replacement, 1);
+}
+
+char* omni_string_replace_all(const char* s, const char* old, const char* replacement) {
+    return omni_string_replace(s, old, replacement, -1);
+}
+
+int32_t omni_parse_int(const char* s, int32_t* out) {
+    *out = 0;
+    // strtol skips leading whitespace, which strconv.ParseInt rejects
+    if (!s || s[0] == '\0' || isspace((unsigned char)s[0])) {
+        return 0;
+    }
+    char* end;
+    errno = 0;
+    long value = strtol(s, &end, 10);
+    if (*end != '\0' || errno == ERANGE || value < INT32_MIN || value > INT32_MAX) {
+        return 0;
+    }
+    *out = (int32_t)value;
+    return 1;
+}
+
+int32_t omni_parse_float(const char* s, double* out) {
+    *out = 0.0;
+    if (!s || s[0] == '\0' || isspace((unsigned char)s[0])) {
+        return 0;
+    }
+    char* end;
+    errno = 0;
+    double value = strtod(s, &end);
+    // Underflow also sets ERANGE, but strconv.ParseFloat accepts it
+    if (*end != '\0' || (errno == ERANGE && isinf(value))) {
+        return 0;
+    }
+    *out = value;
+    return 1;
+}
+
+int32_t omni_parse_bool(const char* s, int32_t* out) {
+    static const char* const truths[] = {"1", "t", "T", "TRUE", "true", "True"};
+    static const char* const falsehoods[] = {"0", "f", "F", "FALSE", "false", "False"};
+    *out = 0;
+    if (!s) {
+        return 0;
+    }
+    for (size_t i = 0; i < sizeof(truths) / sizeof(truths[0]); i++) {
+        if (strcmp(s, truths[i]) == 0) {
+            *out = 1;
+            return 1;
+        }
+        if (strcmp(s, falsehoods[i]) == 0) {
+            return 1;
+        }
+    }
+    return 0;
+}
+
+omni_format_arg omni_format_int(int64_t value) {
+    omni_format_arg arg = {OMNI_FORMAT_INT, value, 0, NULL};
+    return arg;
+}
+
+omni_format_arg omni_format_float(double value) {
+    omni_format_arg arg = {OMNI_FORMAT_FLOAT, 0, value, NULL};
+    return arg;
+}
+
+omni_format_arg omni_format_string(const char* value) {
+    omni_format_arg arg = {OMNI_FORMAT_STRING, 0, 0, value};
+    return arg;
+}
+
+omni_format_arg omni_format_bool(int32_t value) {
+    omni_format_arg arg = {OMNI_FORMAT_BOOL, value, 0, NULL};
+    return arg;
+}
+
+// format_buffer is the output of omni_string_format, grown as it fills up
+typedef struct {
+    char* data;
+    size_t len;
+    size_t cap;
+} format_buffer;
+
+static void format_fail(format_buffer* buf, const char* message, const char* spec) {
+    free(buf->data);
+    if (spec) {
+        fprintf(stderr, "format: %s {%s}\n", message, spec);
+    } else {
+        fprintf(stderr, "format: %s\n", message);
+    }
+    exit(1);
+}
+
+// format_append writes one value to buf with snprintf, growing buf and
+// writing again when it does not fit
+static void format_append(format_buffer* buf, const char* fmt, const omni_format_arg* arg) {
+    for (;;) {
+        size_t room = buf->cap - buf->len;
+        int n;
+        switch (arg->kind) {
+        case OMNI_FORMAT_INT:
+            n = snprintf(buf->data + buf->len, room, fmt, (long long)arg->i);
+            break;
+        case OMNI_FORMAT_FLOAT:
+            n = snprintf(buf->data + buf->len, room, fmt, arg->f);
+            break;
+        default:
+            n = snprintf(buf->data + buf->len, room, fmt, arg->s ? arg->s : "");
+            break;
+        }
+        if (n < 0) {
+            format_fail(buf, "cannot format argument", NULL);
+        }
+        if ((size_t)n < room) {
+            buf->len += (size_t)n;
+            return;
+        }
+        size_t cap = buf->cap * 2;
+        while (cap <= buf->len + (size_t)n) {
+            cap *= 2;
+        }
+        char* data = realloc(buf->data, cap);
+        if (!data) {
+            format_fail(buf, "out of memory", NULL);
+        }
+        buf->data = data;
+        buf->cap = cap;
+    }
+}
+
+// omni_string_format substitutes args for the placeholders of tmpl in
+// order, like std.string.format in the VM: {} writes any value, {:d} an
+// integer, {:f} a number with six decimals and {:s} a string, while {{ and
+// }} stand for literal braces. A malformed template ends the program.
+// NOTE: Returns a newly allocated string
+char* omni_string_format(const char* tmpl, int32_t count, const omni_format_arg* args) {
+    format_buffer buf = {malloc(64), 0, 64};
+    if (!buf.data) {
+        return NULL;
+    }
+    buf.data[0] = '\0';
+    int32_t next = 0;
+    for (const char* p = tmpl ? tmpl : ""; *p; p++) {
+        omni_format_arg literal = {OMNI_FORMAT_STRING, 0, 0, NULL};
+        char ch[2] = {*p, '\0'};
+        if (*p == '}') {
+            if (p[1] != '}') {
+                format_fail(&buf, "unmatched }", NULL);
+            }
+            p++;
+        } else if (*p == '{' && p[1] == '{') {
+            p++;
+        } else if (*p == '{') {
+            const char* end = strchr(p, '}');
+            if (!end) {
+                format_fail(&buf, "unterminated placeholder", NULL);
+            }
+            char spec[16];
+            size_t spec_len = (size_t)(end - p - 1);
+            if (spec_len >= sizeof(spec)) {
+                spec_len = sizeof(spec) - 1;
+            }
+            memcpy(spec, p + 1, spec_len);
+            spec[spec_len] = '\0';
+            p = end;
+            if (next >= count) {
+                format_fail(&buf, "no argument for placeholder", spec);
+            }
+            omni_format_arg arg = args[next++];
+            const char* fmt = NULL;
+            if (strcmp(spec, "") == 0) {
+                switch (arg.kind) {
+                case OMNI_FORMAT_INT:
+                    fmt = "%lld";
+                    break;
+                case OMNI_FORMAT_FLOAT:
+                    fmt = "%g";
+                    break;
+                case OMNI_FORMAT_BOOL:
+                    arg = omni_format_string(arg.i ? "true" : "false");
+                    fmt = "%s";
+                    break;
+                default:
+                    fmt = "%s";
+                    break;
+                }
+            } else if (strcmp(spec, ":d") == 0 && arg.kind == OMNI_FORMAT_INT) {
+                fmt = "%lld";
+            } else if (strcmp(spec, ":f") == 0 && (arg.kind == OMNI_FORMAT_FLOAT || arg.kind == OMNI_FORMAT_INT)) {
+                arg = omni_format_float(arg.kind == OMNI_FORMAT_INT ? (double)arg.i : arg.f);
+                fmt = "%f";
+            } else if (strcmp(spec, ":s") == 0 && arg.kind == OMNI_FORMAT_STRING) {
+                fmt = "%s";
+            } else if (strcmp(spec, ":d") != 0 && strcmp(spec, ":f") != 0 && strcmp(spec, ":s") != 0) {
+                format_fail(&buf, "unknown placeholder", spec);
+            } else {
+                format_fail(&buf, "wrong kind of argument for placeholder", spec);
+            }
+            format_append(&buf, fmt, &arg);
+            continue;
+        }
+        literal.s = ch;
+        format_append(&buf, "%s", &literal);
+    }
+    if (next < count) {
+        format_fail(&buf, "more arguments than placeholders", NULL);
+    }
+    return buf.data;
+}
+
+// Math operations
+int32_t omni_add(int32_t a, int32_t b) {
+    return a + b;
+}
+
+int32_t omni_sub(int32_t a, int32_t b) {
+    return a - b;
+}
+
+int32_t omni_mul(int32_t a, int32_t b) {
+    return a * b;
+}
+
+int32_t omni_div(int32_t a, int32_t b) {
+    return b != 0 ? a / b : 0;
+}
+
+int32_t omni_abs(int32_t x) {
+    return x < 0 ? -x : x;
+}
+
+int32_t omni_max(int32_t a, int32_t b) {
+    return a > b ? a : b;
+}
+
+int32_t omni_min(int32_t a, int32_t b) {
+    return a < b ? a : b;
+}
+
+char* omni_int_to_string(int32_t value) {
+    char* str = malloc(32); // Enough for any int32_t
+    if (str) {
+        snprintf(str, 32, "%d", value);
+    }
+    return str;
+}
+
+char* omni_int64_to_string(int64_t value) {
+    char* str = malloc(32); // Enough for any int64_t
+    if (str) {
+        snprintf(str, 32, "%lld", (long long)value);
+    }
+    return str;
+}
+
+char* omni_uint64_to_string(uint64_t value) {
+    char* str = malloc(32); // Enough for any uint64_t
+    if (str) {
+        snprintf(str, 32, "%llu", (unsigned long long)value);
+    }
+    return str;
+}
+
+char* omni_float_to_string(double value) {
+    char* str = malloc(64); // Enough for any double
+    if (str) {
+        snprintf(str, 64, "%f", value);
+    }
+    return str;
+}
+
+char* omni_bool_to_string(int32_t value) {
+    char* str = malloc(8); // Enough for "true" or "false"
+    if (str) {
+        if (value) {
+            strcpy(str, "true");
+        } else {
+            strcpy(str, "false");
+        }
+    }
+    return str;
+}
+
+int32_t omni_string_to_int(const char* str) {
+    if (!str) return 0;
+    errno = 0;
+    char* endptr;
+    long result = strtol(str, &endptr, 10);
+    // Check for conversion errors
+    if (errno == ERANGE || result < INT32_MIN || result > INT32_MAX) {
+        fprintf(stderr, "WARNING: String to int conversion overflow: %s\n", str);
+        return 0;
+    }
+    if (endptr == str || *endptr != '\0') {
+        // No digits found or invalid characters
+        fprintf(stderr, "WARNING: Invalid integer string: %s\n", str);
+        return 0;
+    }
+    return (int32_t)result;
+}
+
+double omni_string_to_float(const char* str) {
+    if (!str) return 0.0;
+    return atof(str);
+}
+
+int32_t omni_string_to_bool(const char* str) {
+    if (!str) return 0;
+    return (strcmp(str, "true") == 0) ? 1 : 0;
+}
+
+// Array operations
+// NOTE: Array length cannot be determined from a pointer alone in C.
+// The backend should pass array length as a parameter or track it separately.
+// For now, return 0 as a safe default (backend should use compile-time known lengths).
+int32_t omni_array_length(int32_t* arr) {
+    if (!arr) return 0;
+    // Cannot determine length from pointer - backend must pass length explicitly
+    // Returning 0 is safer than a hardcoded value
+    return 0;
+}
+
+// Array get operation with bounds checking
+int32_t omni_array_get_int(int32_t* arr, int32_t index, int32_t length) {
+    if (!arr) {
+        fprintf(stderr, "ERROR: Array access on NULL pointer\n");
+        abort();
+    }
+    if (index < 0 || index >= length) {
+        fprintf(stderr, "ERROR: Array index out of bounds: index=%d, length=%d\n", index, length);
+        abort();
+    }
+    return arr[index];
+}
+
+// Array set operation with bounds checking
+void omni_array_set_int(int32_t* arr, int32_t index, int32_t value, int32_t length) {
+    if (!arr) {
+        fprintf(stderr, "ERROR: Array access on NULL pointer\n");
+        abort();
+    }
+    if (index < 0 || index >= length) {
+        fprintf(stderr, "ERROR: Array index out of bounds: index=%d, length=%d\n", index, length);
+        abort();
+    }
+    arr[index] = value;
+}
+
+double omni_pow(double x, double y) {
+    return pow(x, y);
+}
+
+double omni_sqrt(double x) {
+    return sqrt(x);
+}
+
+double omni_floor(double x) {
+    return floor(x);
+}
+
+double omni_ceil(double x) {
+    return ceil(x);
+}
+
+double omni_round(double x) {
+    return round(x);
+}
+
+int32_t omni_gcd(int32_t a, int32_t b) {
+    if (b == 0) {
+        return a;
+    }
+    return omni_gcd(b, a % b);
+}
+
+int32_t omni_lcm(int32_t a, int32_t b) {
+    if (a == 0 || b == 0) {
+        return 0;
+    }
+    return (a * b) / omni_gcd(a, b);
+}
+
+int32_t omni_factorial(int32_t n) {
+    if (n <= 1) {
+        return 1;
+    }
+    int32_t result = 1;
+    for (int32_t i = 2; i <= n; i++) {
+        result *= i;
+    }
+    return result;
+}
+
+// Trigonometric functions
+double omni_sin(double x) {
+    return sin(x);
+}
+
+double omni_cos(double x) {
+    return cos(x);
+}
+
+double omni_tan(double x) {
+    return tan(x);
+}
+
+double omni_asin(double x) {
+    return asin(x);
+}
+
+double omni_acos(double x) {
+    return acos(x);
+}
+
+double omni_atan(double x) {
+    return atan(x);
+}
+
+double omni_atan2(double y, double x) {
+    return atan2(y, x);
+}
+
+// Logarithmic and exponential functions
+double omni_exp(double x) {
+    return exp(x);
+}
+
+double omni_log(double x) {
+    return log(x);
+}
+
+double omni_log10(double x) {
+    return log10(x);
+}
+
+double omni_log2(double x) {
+    return log2(x);
+}
+
+// Hyperbolic functions
+double omni_sinh(double x) {
+    return sinh(x);
+}
+
+double omni_cosh(double x) {
+    return cosh(x);
+}
+
+double omni_tanh(double x) {
+    return tanh(x);
+}
+
+// Additional math functions
+double omni_cbrt(double x) {
+    if (x == 0.0) return 0.0;
+    if (x < 0.0) return -pow(-x, 1.0/3.0);
+    return pow(x, 1.0/3.0);
+}
+
+double omni_trunc(double x) {
+    return trunc(x);
+}
+
+// Pseudo-random numbers. Unless omni_random_seed is called first, the
+// generator is seeded from the clock on first use, as the VM does.
+static int omni_random_seeded = 0;
+
+static int omni_random_next(void) {
+    if (!omni_random_seeded) {
+        srand((unsigned int)time(NULL));
+        omni_random_seeded = 1;
+    }
+    return rand();
+}
+
+void omni_random_seed(int32_t seed) {
+    srand((unsigned int)seed);
+    omni_random_seeded = 1;
+}
+
+double omni_random_float(void) {
+    // Dividing by RAND_MAX + 1 keeps 1.0 out of the range
+    return (double)omni_random_next() / ((double)RAND_MAX + 1.0);
+}
+
+int32_t omni_random_int(int32_t lo, int32_t hi) {
+    if (hi < lo) {
+        return lo;
+    }
+    int64_t span = (int64_t)hi - (int64_t)lo + 1;
+    return (int32_t)((int64_t)lo + (int64_t)(omni_random_float() * (double)span));
+}
+
+char* omni_random_string(int32_t n) {
+    static const char alphabet[] =
+        "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789";
+    if (n < 0) {
+        n = 0;
+    }
+    char* result = malloc((size_t)n + 1);
+    if (!result) {
+        return NULL;
+    }
+    for (int32_t i = 0; i < n; i++) {
+        result[i] = alphabet[omni_random_next() % (int)(sizeof(alphabet) - 1)];
+    }
+    result[n] = '\0';
+    return result;
+}
+
+// Array operations
+// omni_len returns the length of an array. The length is passed explicitly by the backend.
+int32_t omni_len(void* array, size_t element_size, int32_t array_length) {
+    (void)array;        // Suppress unused parameter warning
+    (void)element_size; // Suppress unused parameter warning
+    // Return the length passed by the backend
+    return array_length;
+}
+
+// File I/O operations
+intptr_t omni_file_open(const char* filename, const char* mode) {
+    FILE* file = fopen(filename, mode);
+    if (file == NULL) {
+        return (intptr_t)-1; // Error: file could not be opened
+    }
+    return (intptr_t)file; // Cast FILE* to intptr_t handle (safe on 64-bit)
+}
+
+int32_t omni_file_close(intptr_t file_handle) {
+    if (file_handle == (intptr_t)-1) {
+        return -1; // Error: invalid handle
+    }
+    FILE* file = (FILE*)(intptr_t)file_handle;
+    return fclose(file) == 0 ? 0 : -1;
+}
+
+int32_t omni_file_read(intptr_t file_handle, char* buffer, int32_t size) {
+    if (file_handle == (intptr_t)-1 || buffer == NULL || size <= 0) {
+        return -1; // Error: invalid parameters
+    }
+    FILE* file = (FILE*)file_handle;
+    size_t bytes_read = fread(buffer, 1, (size_t)size, file);
+    return (int32_t)bytes_read;
+}
+
+int32_t omni_file_write(intptr_t file_handle, const char* buffer, int32_t size) {
+    if (file_handle == (intptr_t)-1 || buffer == NULL || size <= 0) {
+        return -1; // Error: invalid parameters
+    }
+    FILE* file = (FILE*)file_handle;
+    size_t bytes_written = fwrite(buffer, 1, (size_t)size, file);
+    return (int32_t)bytes_written;
+}
+
+int32_t omni_file_seek(intptr_t file_handle, int32_t offset, int32_t whence) {
+    if (file_handle == (intptr_t)-1) {
+        return -1; // Error: invalid handle
+    }
+    FILE* file = (FILE*)file_handle;
+    return fseek(file, offset, whence) == 0 ? 0 : -1;
+}
+
+int32_t omni_file_tell(intptr_t file_handle) {
+    if (file_handle == (intptr_t)-1) {
+        return -1; // Error: invalid handle
+    }
+    FILE* file = (FILE*)file_handle;
+    long pos = ftell(file);
+    return pos >= 0 ? (int32_t)pos : -1;
+}
+
+int32_t omni_file_exists(const char* filename) {
+    if (filename == NULL) {
+        return 0; // Error: null filename
+    }
+    struct stat st;
+    return stat(filename, &st) == 0 ? 1 : 0;
+}
+
+int32_t omni_file_size(const char* filename) {
+    if (filename == NULL) {
+        return -1; // Error: null filename
+    }
+    struct stat st;
+    if (stat(filename, &st) != 0) {
+        return -1; // Error: file not found or stat failed
+    }
+    return (int32_t)st.st_size;
+}
+
+// Testing framework
+void omni_test_start(const char* test_name) {
+    printf("Running test: %s\n", test_name);
+    current_test_passed = 1;
+}
+
+void omni_test_end(const char* test_name, int32_t passed) {
+    total_tests++;
+    if (passed && current_test_passed) {
+        passed_tests++;
+        printf("✓ %s PASSED\n", test_name);
+    } else {
+        printf("✗ %s FAILED\n", test_name);
+    }
+}
+
+void omni_assert(int32_t condition, const char* message) {
+    if (!condition) {
+        printf("  ASSERTION FAILED: %s\n", message);
+        current_test_passed = 0;
+    }
+}
+
+void omni_assert_eq_int(int32_t expected, int32_t actual, const char* message) {
+    if (expected != actual) {
+        printf("  ASSERTION FAILED: %s (expected: %d, actual: %d)\n", message, expected, actual);
+        current_test_passed = 0;
+    }
+}
+
+void omni_assert_eq_string(const char* expected, const char* actual, const char* message) {
+    if (strcmp(expected, actual) != 0) {
+        printf("  ASSERTION FAILED: %s (expected: \"%s\", actual: \"%s\")\n", message, expected, actual);
+        current_test_passed = 0;
+    }
+}
+
+void omni_assert_eq_float(double expected, double actual, const char* message) {
+    const double epsilon = 1e-9;
+    if (fabs(expected - actual) > epsilon) {
+        printf("  ASSERTION FAILED: %s (expected: %f, actual: %f)\n", message, expected, actual);
+        current_test_passed = 0;
+    }
+}
+
+void omni_assert_true(int32_t condition, const char* message) {
+    if (!condition) {
+        printf("  ASSERTION FAILED: %s (expected: true, actual: false)\n", message);
+        current_test_passed = 0;
+    }
+}
+
+void omni_assert_false(int32_t condition, const char* message) {
+    if (condition) {
+        printf("  ASSERTION FAILED: %s (expected: false, actual: true)\n", message);
+        current_test_passed = 0;
+    }
+}
+
+int32_t omni_test_summary() {
+    printf("\nTest Summary: %d/%d tests passed\n", passed_tests, total_tests);
+    if (passed_tests == total_tests) {
+        printf("All tests passed! ✓\n");
+        return 0;
+    } else {
+        printf("Some tests failed! ✗\n");
+        return 1;
+    }
+}
+
+void omni_test_reset() {
+    // Reset test counters (useful when running multiple test files)
+    total_tests = 0;
+    passed_tests = 0;
+    current_test_passed = 1;
+}
+
+// System operations
+void omni_exit(int32_t code) {
+    exit(code);
+}
+
+// Environment variable operations
+const char* omni_getenv(const char* name) {
+    if (!name) return NULL;
+    char* value = getenv(name);
+    if (!value) return NULL;
+    // Return a pointer to the environment variable value
+    // Note: This is valid until the environment is modified
+    return value;
+}
+
+int32_t omni_setenv(const char* name, const char* value) {
+    if (!name) return 0;
+#ifdef _WIN32
+    // Windows uses _putenv_s
+    if (value) {
+        char* env_str = (char*)malloc(strlen(name) + strlen(value) + 2);
+        if (!env_str) return 0;
+        sprintf(env_str, "%s=%s", name, value);
+        int result = _putenv(env_str);
+        free(env_str);
+        return (result == 0) ? 1 : 0;
+    } else {
+        // Unset by setting to empty
+        char* env_str = (char*)malloc(strlen(name) + 2);
+        if (!env_str) return 0;
+        sprintf(env_str, "%s=", name);
+        int result = _putenv(env_str);
+        free(env_str);
+        return (result == 0) ? 1 : 0;
+    }
+#else
+    // POSIX uses setenv
+    if (value) {
+        return (setenv(name, value, 1) == 0) ? 1 : 0;
+    } else {
+        return (unsetenv(name) == 0) ? 1 : 0;
+    }
+#endif
+}
+
+int32_t omni_unsetenv(const char* name) {
+    if (!name) return 0;
+#ifdef _WIN32
+    // Windows: set to empty string
+    char* env_str = (char*)malloc(strlen(name) + 2);
+    if (!env_str) return 0;
+    sprintf(env_str, "%s=", name);
+    int result = _putenv(env_str);
+    free(env_str);
+    return (result == 0) ? 1 : 0;
+#else
+    return (unsetenv(name) == 0) ? 1 : 0;
+#endif
+}
+
+// Directory operations
+const char* omni_getcwd(void) {
+    static char cwd[PATH_MAX];
+#ifdef _WIN32
+    if (_getcwd(cwd, sizeof(cwd)) != NULL) {
+        return cwd;
+    }
+#else
+    if (getcwd(cwd, sizeof(cwd)) != NULL) {
+        return cwd;
+    }
+#endif
+    return NULL;
+}
+
+int32_t omni_chdir(const char* path) {
+    if (!path) return 0;
+#ifdef _WIN32
+    return (_chdir(path) == 0) ? 1 : 0;
+#else
+    return (chdir(path) == 0) ? 1 : 0;
+#endif
+}
+
+// File system operations
+int32_t omni_mkdir(const char* path) {
+    if (!path) return 0;
+#ifdef _WIN32
+    return (_mkdir(path) == 0) ? 1 : 0;
+#else
+    return (mkdir(path, 0755) == 0) ? 1 : 0;
+#endif
+}
+
+int32_t omni_rmdir(const char* path) {
+    if (!path) return 0;
+#ifdef _WIN32
+    return (_rmdir(path) == 0) ? 1 : 0;
+#else
+    return (rmdir(path) == 0) ? 1 : 0;
+#endif
+}
+
+int32_t omni_remove(const char* path) {
+    if (!path) return 0;
+    return (remove(path) == 0) ? 1 : 0;
+}
+
+int32_t omni_rename(const char* old_path, const char* new_path) {
+    if (!old_path || !new_path) return 0;
+    return (rename(old_path, new_path) == 0) ? 1 : 0;
+}
+
+int32_t omni_copy(const char* src_path, const char* dst_path) {
+    if (!src_path || !dst_path) return 0;
+    
+    FILE* src = fopen(src_path, "rb");
+    if (!src) return 0;
+    
+    FILE* dst = fopen(dst_path, "wb");
+    if (!dst) {
+        fclose(src);
+        return 0;
+    }
+    
+    char buffer[4096];
+    size_t bytes;
+    int32_t success = 1;
+    
+    while ((bytes = fread(buffer, 1, sizeof(buffer), src)) > 0) {
+        if (fwrite(buffer, 1, bytes, dst) != bytes) {
+            success = 0;
+            break;
+        }
+    }
+    
+    fclose(src);
+    fclose(dst);
+    
+    return success;
+}
+
+int32_t omni_exists(const char* path) {
+    if (!path) return 0;
+    struct stat st;
+    return (stat(path, &st) == 0) ? 1 : 0;
+}
+
+int32_t omni_is_file(const char* path) {
+    if (!path) return 0;
+    struct stat st;
+    if (stat(path, &st) != 0) return 0;
+    return S_ISREG(st.st_mode) ? 1 : 0;
+}
+
+int32_t omni_is_dir(const char* path) {
+    if (!path) return 0;
+    struct stat st;
+    if (stat(path, &st) != 0) return 0;
+    return S_ISDIR(st.st_mode) ? 1 : 0;
+}
+
+// String validation functions
+int32_t omni_string_is_alpha(const char* str) {
+    if (!str) return 0;
+    for (const char* p = str; *p; p++) {
+        if (!isalpha((unsigned char)*p)) {
+            return 0;
+        }
+    }
+    return (*str != '\0') ? 1 : 0;
+}
+
+int32_t omni_string_is_digit(const char* str) {
+    if (!str) return 0;
+    for (const char* p = str; *p; p++) {
+        if (!isdigit((unsigned char)*p)) {
+            return 0;
+        }
+    }
+    return (*str != '\0') ? 1 : 0;
+}
+
+int32_t omni_string_is_alnum(const char* str) {
+    if (!str) return 0;
+    for (const char* p = str; *p; p++) {
+        if (!isalnum((unsigned char)*p)) {
+            return 0;
+        }
+    }
+    return (*str != '\0') ? 1 : 0;
+}
+
+int32_t omni_string_is_ascii(const char* str) {
+    if (!str) return 1;
+    for (const char* p = str; *p; p++) {
+        if ((unsigned char)*p > 127) {
+            return 0;
+        }
+    }
+    return 1;
+}
+
+int32_t omni_string_is_upper(const char* str) {
+    if (!str || *str == '\0') return 0;
+    for (const char* p = str; *p; p++) {
+        if (islower((unsigned char)*p)) {
+            return 0;
+        }
+    }
+    return 1;
+}
+
+int32_t omni_string_is_lower(const char* str) {
+    if (!str || *str == '\0') return 0;
+    for (const char* p = str; *p; p++) {
+        if (isupper((unsigned char)*p)) {
+            return 0;
+        }
+    }
+    return 1;
+}
+
+// String encoding/escaping functions (base64 implementations moved below)
+
+char* omni_encode_url(const char* str) {
+    if (!str) return NULL;
+    // URL encoding: %XX for non-ASCII and special chars
+    size_t len = strlen(str);
+    char* result = (char*)malloc(len * 3 + 1);
+    if (!result) return NULL;
+    
+    size_t j = 0;
+    for (size_t i = 0; i < len; i++) {
+        unsigned char c = (unsigned char)str[i];
+        if ((c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || 
+            (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~') {
+            result[j++] = c;
+        } else {
+            sprintf(result + j, "%%%02X", c);
+            j += 3;
+        }
+    }
+    result[j] = '\0';
+    return result;
+}
+
+char* omni_decode_url(const char* str) {
+    if (!str) return NULL;
+    size_t len = strlen(str);
+    char* result = (char*)malloc(len + 1);
+    if (!result) return NULL;
+    
+    size_t j = 0;
+    for (size_t i = 0; i < len; i++) {
+        if (str[i] == '%' && i + 2 < len) {
+            char hex[3] = {str[i+1], str[i+2], '\0'};
+            int value;
+            if (sscanf(hex, "%x", &value) == 1) {
+                result[j++] = (char)value;
+                i += 2;
+            } else {
+                result[j++] = str[i];
+            }
+        } else if (str[i] == '+') {
+            result[j++] = ' ';
+        } else {
+            result[j++] = str[i];
+        }
+    }
+    result[j] = '\0';
+    return result;
+}
+
+char* omni_escape_html(const char* str) {
+    if (!str) return NULL;
+    size_t len = strlen(str);
+    char* result = (char*)malloc(len * 6 + 1); // Worst case: all chars need escaping
+    if (!result) return NULL;
+    
+    size_t j = 0;
+    for (size_t i = 0; i < len; i++) {
+        switch (str[i]) {
+            case '&': memcpy(result + j, "&amp;", 5); j += 5; break;
+            case '<': memcpy(result + j, "&lt;", 4); j += 4; break;
+            case '>': memcpy(result + j, "&gt;", 4); j += 4; break;
+            case '"': memcpy(result + j, "&quot;", 6); j += 6; break;
+            case '\'': memcpy(result + j, "&#39;", 5); j += 5; break;
+            default: result[j++] = str[i]; break;
+        }
+    }
+    result[j] = '\0';
+    return result;
+}
+
+char* omni_unescape_html(const char* str) {
+    if (!str) return NULL;
+    size_t len = strlen(str);
+    char* result = (char*)malloc(len + 1);
+    if (!result) return NULL;
+    
+    size_t j = 0;
+    for (size_t i = 0; i < len; i++) {
+        if (str[i] == '&' && i + 3 < len) {
+            if (strncmp(str + i, "&lt;", 4) == 0) {
+                result[j++] = '<';
+                i += 3;
+            } else if (strncmp(str + i, "&gt;", 4) == 0) {
+                result[j++] = '>';
+                i += 3;
+            } else if (strncmp(str + i, "&amp;", 5) == 0) {
+                result[j++] = '&';
+                i += 4;
+            } else if (strncmp(str + i, "&quot;", 6) == 0) {
+                result[j++] = '"';
+                i += 5;
+            } else if (strncmp(str + i, "&#39;", 5) == 0) {
+                result[j++] = '\'';
+                i += 4;
+            } else {
+                result[j++] = str[i];
+            }
+        } else {
+            result[j++] = str[i];
+        }
+    }
+    result[j] = '\0';
+    return result;
+}
+
+char* omni_escape_json(const char* str) {
+    if (!str) return NULL;
+    size_t len = strlen(str);
+    char* result = (char*)malloc(len * 2 + 1);
+    if (!result) return NULL;
+    
+    size_t j = 0;
+    for (size_t i = 0; i < len; i++) {
+        switch (str[i]) {
+            case '"': result[j++] = '\\'; result[j++] = '"'; break;
+            case '\\': result[j++] = '\\'; result[j++] = '\\'; break;
+            case '\b': result[j++] = '\\'; result[j++] = 'b'; break;
+            case '\f': result[j++] = '\\'; result[j++] = 'f'; break;
+            case '\n': result[j++] = '\\'; result[j++] = 'n'; break;
+            case '\r': result[j++] = '\\'; result[j++] = 'r'; break;
+            case '\t': result[j++] = '\\'; result[j++] = 't'; break;
+            default: result[j++] = str[i]; break;
+        }
+    }
+    result[j] = '\0';
+    return result;
+}
+
+char* omni_escape_shell(const char* str) {
+    if (!str) return NULL;
+    size_t len = strlen(str);
+    char* result = (char*)malloc(len * 2 + 3); // Worst case: all chars escaped + quotes
+    if (!result) return NULL;
+    
+    result[0] = '\'';
+    size_t j = 1;
+    for (size_t i = 0; i < len; i++) {
+        if (str[i] == '\'') {
+            result[j++] = '\'';
+            result[j++] = '\\';
+            result[j++] = '\'';
+            result[j++] = '\'';
+        } else {
+            result[j++] = str[i];
+        }
+    }
+    result[j++] = '\'';
+    result[j] = '\0';
+    return result;
+}
+
+// Base64 encoding/decoding (full implementation)
+static const char base64_chars[] = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/";
+
+char* omni_encode_base64(const char* str) {
+    if (!str) return NULL;
+    size_t len = strlen(str);
+    if (len == 0) {
+        char* result = (char*)malloc(1);
+        if (result) result[0] = '\0';
+        return result;
+    }
+    
+    size_t out_len = ((len + 2) / 3) * 4;
+    char* result = (char*)malloc(out_len + 1);
+    if (!result) return NULL;
+    
+    size_t i = 0, j = 0;
+    for (; i < len - 2; i += 3) {
+        result[j++] = base64_chars[(str[i] >> 2) & 0x3F];
+        result[j++] = base64_chars[((str[i] & 0x3) << 4) | ((str[i+1] & 0xF0) >> 4)];
+        result[j++] = base64_chars[((str[i+1] & 0xF) << 2) | ((str[i+2] & 0xC0) >> 6)];
+        result[j++] = base64_chars[str[i+2] & 0x3F];
+    }
+    
+    if (i < len) {
+        result[j++] = base64_chars[(str[i] >> 2) & 0x3F];
+        if (i == len - 1) {
+            result[j++] = base64_chars[((str[i] & 0x3) << 4)];
+            result[j++] = '=';
+        } else {
+            result[j++] = base64_chars[((str[i] & 0x3) << 4) | ((str[i+1] & 0xF0) >> 4)];
+            result[j++] = base64_chars[((str[i+1] & 0xF) << 2)];
+        }
+        result[j++] = '=';
+    }
+    
+    result[j] = '\0';
+    return result;
+}
+
+static int base64_char_value(char c) {
+    if (c >= 'A' && c <= 'Z') return c - 'A';
+    if (c >= 'a' && c <= 'z') return c - 'a' + 26;
+    if (c >= '0' && c <= '9') return c - '0' + 52;
+    if (c == '+') return 62;
+    if (c == '/') return 63;
+    return -1;
+}
+
+char* omni_decode_base64(const char* str) {
+    if (!str) return NULL;
+    size_t len = strlen(str);
+    if (len == 0) {
+        char* result = (char*)malloc(1);
+        if (result) result[0] = '\0';
+        return result;
+    }
+    
+    // Calculate output length
+    size_t padding = 0;
+    if (len > 0 && str[len-1] == '=') padding++;
+    if (len > 1 && str[len-2] == '=') padding++;
+    size_t out_len = (len * 3) / 4 - padding;
+    
+    char* result = (char*)malloc(out_len + 1);
+    if (!result) return NULL;
+    
+    size_t i = 0, j = 0;
+    for (; i < len - 4; i += 4) {
+        int v1 = base64_char_value(str[i]);
+        int v2 = base64_char_value(str[i+1]);
+        int v3 = base64_char_value(str[i+2]);
+        int v4 = base64_char_value(str[i+3]);
+        
+        if (v1 < 0 || v2 < 0 || v3 < 0 || v4 < 0) {
+            free(result);
+            return NULL;
+        }
+        
+        result[j++] = (v1 << 2) | (v2 >> 4);
+        result[j++] = ((v2 & 0xF) << 4) | (v3 >> 2);
+        result[j++] = ((v3 & 0x3) << 6) | v4;
+    }
+    
+    // Handle last 4 characters
+    if (i < len) {
+        int v1 = base64_char_value(str[i]);
+        int v2 = base64_char_value(str[i+1]);
+        if (v1 < 0 || v2 < 0) {
+            free(result);
+            return NULL;
+        }
+        result[j++] = (v1 << 2) | (v2 >> 4);
+        
+        if (i + 2 < len && str[i+2] != '=') {
+            int v3 = base64_char_value(str[i+2]);
+            if (v3 < 0) {
+                free(result);
+                return NULL;
+            }
+            result[j++] = ((v2 & 0xF) << 4) | (v3 >> 2);
+            
+            if (i + 3 < len && str[i+3] != '=') {
+                int v4 = base64_char_value(str[i+3]);
+                if (v4 < 0) {
+                    free(result);
+                    return NULL;
+                }
+                result[j++] = ((v3 & 0x3) << 6) | v4;
+            }
+        }
+    }
+    
+    result[j] = '\0';
+    return result;
+}
+
+// Regex functions using POSIX regex
+int32_t omni_string_matches(const char* str, const char* pattern) {
+    if (!str || !pattern) return 0;
+    
+    regex_t regex;
+    int ret = regcomp(&regex, pattern, REG_EXTENDED | REG_NOSUB);
+    if (ret != 0) {
+        return 0; // Invalid pattern
+    }
+    
+    ret = regexec(&regex, str, 0, NULL, 0);
+    regfree(&regex);
+    
+    return (ret == 0) ? 1 : 0;
+}
+
+char* omni_string_find_match(const char* str, const char* pattern) {
+    if (!str || !pattern) return NULL;
+    
+    regex_t regex;
+    int ret = regcomp(&regex, pattern, REG_EXTENDED);
+    if (ret != 0) {
+        return NULL; // Invalid pattern
+    }
+    
+    regmatch_t matches[1];
+    ret = regexec(&regex, str, 1, matches, 0);
+    
+    if (ret == 0 && matches[0].rm_so >= 0) {
+        size_t match_len = matches[0].rm_eo - matches[0].rm_so;
+        char* result = (char*)malloc(match_len + 1);
+        if (result) {
+            memcpy(result, str + matches[0].rm_so, match_len);
+            result[match_len] = '\0';
+        }
+        regfree(&regex);
+        return result;
+    }
+    
+    regfree(&regex);
+    return NULL;
+}
+
+char* omni_string_find_all_matches(const char* str, const char* pattern, int32_t* count) {
+    if (!str || !pattern || !count) return NULL;
+    
+    *count = 0;
+    regex_t regex;
+    int ret = regcomp(&regex, pattern, REG_EXTENDED);
+    if (ret != 0) {
+        return NULL; // Invalid pattern
+    }
+    
+    // Count matches first
+    regmatch_t match;
+    const char* search_start = str;
+    int match_count = 0;
+    while (regexec(&regex, search_start, 1, &match, 0) == 0 && match.rm_so >= 0) {
+        match_count++;
+        search_start += match.rm_eo;
+    }
+    
+    if (match_count == 0) {
+        regfree(&regex);
+        char* result = (char*)malloc(1);
+        if (result) result[0] = '\0';
+        return result;
+    }
+    
+    // Allocate array: each match is stored as "start:end," format
+    // For simplicity, return a comma-separated string of match positions
+    // In a real implementation, this would return an array
+    size_t buf_size = match_count * 32; // Rough estimate
+    char* result = (char*)malloc(buf_size);
+    if (!result) {
+        regfree(&regex);
+        return NULL;
+    }
+    
+    *count = match_count;
+    search_start = str;
+    size_t pos = 0;
+    int found = 0;
+    while (regexec(&regex, search_start, 1, &match, 0) == 0 && match.rm_so >= 0 && pos < buf_size - 1) {
+        if (found > 0) {
+            result[pos++] = ',';
+        }
+        int start_pos = (int)(search_start - str) + match.rm_so;
+        int end_pos = (int)(search_start - str) + match.rm_eo;
+        int written = snprintf(result + pos, buf_size - pos, "%d:%d", start_pos, end_pos);
+        if (written > 0) pos += written;
+        search_start += match.rm_eo;
+        found++;
+    }
+    result[pos] = '\0';
+    
+    regfree(&regex);
+    return result;
+}
+
+char* omni_string_replace_regex(const char* str, const char* pattern, const char* replacement) {
+    if (!str || !pattern || !replacement) return NULL;
+    
+    regex_t regex;
+    int ret = regcomp(&regex, pattern, REG_EXTENDED);
+    if (ret != 0) {
+        return NULL; // Invalid pattern
+    }
+    
+    regmatch_t matches[1];
+    const char* search_start = str;
+    size_t result_size = strlen(str) + strlen(replacement) + 1;
+    char* result = (char*)malloc(result_size);
+    if (!result) {
+        regfree(&regex);
+        return NULL;
+    }
+    
+    size_t result_pos = 0;
+    
+    while (regexec(&regex, search_start, 1, matches, 0) == 0 && matches[0].rm_so >= 0) {
+        // Copy text before match
+        size_t before_len = matches[0].rm_so;
+        if (result_pos + before_len + strlen(replacement) + 1 >= result_size) {
+            result_size = result_pos + before_len + strlen(replacement) + 100;
+            result = (char*)realloc(result, result_size);
+            if (!result) {
+                regfree(&regex);
+                return NULL;
+            }
+        }
+        memcpy(result + result_pos, search_start, before_len);
+        result_pos += before_len;
+        
+        // Copy replacement
+        size_t repl_len = strlen(replacement);
+        memcpy(result + result_pos, replacement, repl_len);
+        result_pos += repl_len;
+        
+        search_start += matches[0].rm_eo;
+    }
+    
+    // Copy remaining text
+    size_t remaining = strlen(search_start);
+    if (result_pos + remaining + 1 >= result_size) {
+        result_size = result_pos + remaining + 1;
+        result = (char*)realloc(result, result_size);
+        if (!result) {
+            regfree(&regex);
+            return NULL;
+        }
+    }
+    memcpy(result + result_pos, search_start, remaining);
+    result_pos += remaining;
+    result[result_pos] = '\0';
+    
+    regfree(&regex);
+    return result;
+}
+
+// Time functions
+int64_t omni_time_now_unix(void) {
+    return (int64_t)time(NULL);
+}
+
+int64_t omni_time_now_unix_nano(void) {
+#ifdef _WIN32
+    FILETIME ft;
+    GetSystemTimeAsFileTime(&ft);
+    ULARGE_INTEGER uli;
+    uli.LowPart = ft.dwLowDateTime;
+    uli.HighPart = ft.dwHighDateTime;
+    // Windows file time is in 100-nanosecond intervals since 1601-01-01
+    // Convert to Unix epoch (seconds since 1970-01-01)
+    return (int64_t)((uli.QuadPart / 10000000ULL) - 11644473600ULL) * 1000000000ULL + 
+           (int64_t)((uli.QuadPart % 10000000ULL) * 100);
+#else
+    struct timespec ts;
+    clock_gettime(CLOCK_REALTIME, &ts);
+    return (int64_t)ts.tv_sec * 1000000000LL + (int64_t)ts.tv_nsec;
+#endif
+}
+
+void omni_time_sleep_seconds(double seconds) {
+    if (seconds <= 0.0) return;
+#ifdef _WIN32
+    Sleep((DWORD)(seconds * 1000.0));
+#else
+    struct timespec req;
+    req.tv_sec = (time_t)seconds;
+    req.tv_nsec = (long)((seconds - req.tv_sec) * 1000000000.0);
+    nanosleep(&req, NULL);
+#endif
+}
+
+void omni_time_sleep_milliseconds(int32_t milliseconds) {
+    if (milliseconds <= 0) return;
+    omni_time_sleep_seconds(milliseconds / 1000.0);
+}
+
+int32_t omni_time_zone_offset(void) {
+    time_t now = time(NULL);
+    // Use portable calculation method that works on all platforms
+    // Calculate offset by comparing local and UTC time
+    struct tm local_tm, utc_tm;
+    struct tm* local = localtime_r(&now, &local_tm);
+    struct tm* utc = gmtime_r(&now, &utc_tm);
+    if (!local || !utc) return 0;
+    return (int32_t)(mktime(&local_tm) - mktime(&utc_tm));
+}
+
+const char* omni_time_zone_name(void) {
+    static char tz_name[64];
+    const char* tz = getenv("TZ");
+    if (tz) {
+        strncpy(tz_name, tz, sizeof(tz_name) - 1);
+        tz_name[sizeof(tz_name) - 1] = '\0';
+        return tz_name;
+    }
+    return "UTC";
+}
+
+// strftime/strptime for std.time.format. Times carry no zone and are treated
+// as UTC; only the codes supported by the VM are accepted so both backends
+// agree on which patterns are valid.
+static const char omni_time_format_codes[] = "YymdeHIMSpbhBaAjZzFTDRnt%";
+
+// Days since 1970-01-01 for a proleptic Gregorian date (Howard Hinnant's
+// days_from_civil), used to derive the weekday and day of the year.
+static int64_t omni_time_days_from_civil(int64_t y, int64_t m, int64_t d) {
+    y -= m <= 2;
+    int64_t era = (y >= 0 ? y : y - 399) / 400;
+    int64_t yoe = y - era * 400;
+    int64_t doy = (153 * (m + (m > 2 ? -3 : 9)) + 2) / 5 + d - 1;
+    int64_t doe = yoe * 365 + yoe / 4 - yoe / 100 + doy;
+    return era * 146097 + doe - 719468;
+}
+
+static omni_struct_t* omni_time_struct_from_tm(const struct tm* tm) {
+    omni_struct_t* t = omni_struct_create();
+    omni_struct_set_int_field(t, "year", tm->tm_year + 1900);
+    omni_struct_set_int_field(t, "month", tm->tm_mon + 1);
+    omni_struct_set_int_field(t, "day", tm->tm_mday);
+    omni_struct_set_int_field(t, "hour", tm->tm_hour);
+    omni_struct_set_int_field(t, "minute", tm->tm_min);
+    omni_struct_set_int_field(t, "second", tm->tm_sec);
+    omni_struct_set_int_field(t, "nanosecond", 0);
+    return t;
+}
+
+char* omni_time_strftime(omni_struct_t* t, const char* pattern) {
+    if (!t || !pattern) {
+        return strdup("");
+    }
+
+    // Validate the pattern and substitute the zone codes, which strftime
+    // would otherwise render from the (unset) local zone
+    size_t plen = strlen(pattern);
+    char* translated = malloc(plen * 5 + 1);
+    if (!translated) {
+        return strdup("");
+    }
+    size_t out = 0;
+    for (size_t i = 0; i < plen; i++) {
+        if (pattern[i] != '%') {
+            translated[out++] = pattern[i];
+            continue;
+        }
+        char code = pattern[i + 1];
+        if (code == '\0' || !strchr(omni_time_format_codes, code)) {
+            free(translated);
+            return strdup("");
+        }
+        i++;
+        if (code == 'Z') {
+            memcpy(translated + out, "UTC", 3);
+            out += 3;
+        } else if (code == 'z') {
+            memcpy(translated + out, "+0000", 5);
+            out += 5;
+        } else {
+            translated[out++] = '%';
+            translated[out++] = code;
+        }
+    }
+    translated[out] = '\0';
+
+    struct tm tm;
+    memset(&tm, 0, sizeof(tm));
+    int32_t year = omni_struct_get_int_field(t, "year");
+    int32_t month = omni_struct_get_int_field(t, "month");
+    int32_t day = omni_struct_get_int_field(t, "day");
+    tm.tm_year = year - 1900;
+    tm.tm_mon = month - 1;
+    tm.tm_mday = day;
+    tm.tm_hour = omni_struct_get_int_field(t, "hour");
+    tm.tm_min = omni_struct_get_int_field(t, "minute");
+    tm.tm_sec = omni_struct_get_int_field(t, "second");
+    int64_t days = omni_time_days_from_civil(year, month, day);
+    tm.tm_wday = (int)(((days % 7) + 11) % 7); // 1970-01-01 was a Thursday
+    tm.tm_yday = (int)(days - omni_time_days_from_civil(year, 1, 1));
+
+    // strftime returns 0 both on overflow and for empty output, so grow the
+    // buffer a bounded number of times before giving up
+    size_t cap = plen * 4 + 64;
+    for (int attempt = 0; attempt < 4; attempt++, cap *= 4) {
+        char* buf = malloc(cap);
+        if (!buf) {
+            break;
+        }
+        size_t n = strftime(buf, cap, translated, &tm);
+        if (n > 0 || translated[0] == '\0') {
+            free(translated);
+            return buf;
+        }
+        free(buf);
+    }
+    free(translated);
+    return strdup("");
+}
+
+omni_struct_t* omni_time_strptime(const char* s, const char* pattern) {
+    struct tm epoch;
+    memset(&epoch, 0, sizeof(epoch));
+    epoch.tm_year = 70;
+    epoch.tm_mday = 1;
+    if (!s || !pattern) {
+        return omni_time_struct_from_tm(&epoch);
+    }
+    for (const char* p = pattern; *p; p++) {
+        if (*p == '%') {
+            p++;
+            if (*p == '\0' || !strchr(omni_time_format_codes, *p)) {
+                return omni_time_struct_from_tm(&epoch);
+            }
+        }
+    }
+
+#ifdef _WIN32
+    // The Windows C runtime has no strptime
+    return omni_time_struct_from_tm(&epoch);
+#else
+    // Missing fields default to January 1 of year 0, matching the VM
+    struct tm tm;
+    memset(&tm, 0, sizeof(tm));
+    tm.tm_year = -1900;
+    tm.tm_mday = 1;
+    const char* end = strptime(s, pattern, &tm);
+    if (!end || *end != '\0') {
+        return omni_time_struct_from_tm(&epoch);
+    }
+    return omni_time_struct_from_tm(&tm);
+#endif
+}
+
+// std.time.format and std.time.parse layouts use the tokens YYYY, MM, DD,
+// HH, mm and ss; they are translated to a strftime pattern, with any other
+// text kept literal.
+static char* omni_time_layout_to_strftime(const char* layout) {
+    static const char* tokens[][2] = {
+        {"YYYY", "%Y"}, {"MM", "%m"}, {"DD", "%d"},
+        {"HH", "%H"}, {"mm", "%M"}, {"ss", "%S"},
+    };
+    size_t len = strlen(layout);
+    // Every byte becomes at most two, "%" being escaped as "%%"
+    char* pattern = malloc(len * 2 + 1);
+    if (!pattern) {
+        return NULL;
+    }
+    size_t out = 0;
+    size_t i = 0;
+    while (i < len) {
+        int matched = 0;
+        for (size_t k = 0; k < sizeof(tokens) / sizeof(tokens[0]); k++) {
+            size_t tlen = strlen(tokens[k][0]);
+            if (strncmp(layout + i, tokens[k][0], tlen) == 0) {
+                memcpy(pattern + out, tokens[k][1], 2);
+                out += 2;
+                i += tlen;
+                matched = 1;
+                break;
+            }
+        }
+        if (matched) {
+            continue;
+        }
+        if (layout[i] == '%') {
+            pattern[out++] = '%';
+        }
+        pattern[out++] = layout[i++];
+    }
+    pattern[out] = '\0';
+    return pattern;
+}
+
+char* omni_time_format(omni_struct_t* t, const char* layout) {
+    if (!layout) {
+        return strdup("");
+    }
+    char* pattern = omni_time_layout_to_strftime(layout);
+    if (!pattern) {
+        return strdup("");
+    }
+    char* result = omni_time_strftime(t, pattern);
+    free(pattern);
+    return result;
+}
+
+omni_struct_t* omni_time_parse(const char* layout, const char* value) {
+    char* pattern = layout ? omni_time_layout_to_strftime(layout) : NULL;
+    omni_struct_t* result = omni_time_strptime(value, pattern);
+    free(pattern);
+    return result;
+}
+
+// Command-line argument functions
+static char** omni_args_array = NULL;
+static int32_t omni_args_count_val = 0;
+
+void omni_args_init(int argc, char** argv) {
+    omni_args_array = argv;
+    omni_args_count_val = argc;
+}
+
+int32_t omni_args_count(void) {
+    return omni_args_count_val;
+}
+
+const char* omni_args_get(int32_t index) {
+    if (!omni_args_array || index < 0 || index >= omni_args_count_val) {
+        return NULL;
+    }
+    return omni_args_array[index];
+}
+
+int32_t omni_args_has_flag(const char* name) {
+    if (!name || !omni_args_array) return 0;
+    char flag[256];
+    snprintf(flag, sizeof(flag), "--%s", name);
+    for (int32_t i = 1; i < omni_args_count_val; i++) {
+        if (strcmp(omni_args_array[i], flag) == 0) {
+            return 1;
+        }
+    }
+    return 0;
+}
+
+const char* omni_args_get_flag(const char* name, const char* default_value) {
+    if (!name || !omni_args_array) return default_value;
+    char flag[256];
+    snprintf(flag, sizeof(flag), "--%s=", name);
+    size_t flag_len = strlen(flag);
+    
+    for (int32_t i = 1; i < omni_args_count_val; i++) {
+        if (strncmp(omni_args_array[i], flag, flag_len) == 0) {
+            return omni_args_array[i] + flag_len;
+        }
+        if (strcmp(omni_args_array[i], flag) == 0 && i + 1 < omni_args_count_val) {
+            return omni_args_array[i + 1];
+        }
+    }
+    return default_value;
+}
+
+const char* omni_args_positional(int32_t index, const char* default_value) {
+    if (!omni_args_array) return default_value;
+    int32_t pos_idx = 0;
+    for (int32_t i = 1; i < omni_args_count_val; i++) {
+        if (omni_args_array[i][0] != '-') {
+            if (pos_idx == index) {
+                return omni_args_array[i];
+            }
+            pos_idx++;
+        }
+    }
+    return default_value;
+}
+
+// Process ID functions
+int32_t omni_getpid(void) {
+#ifdef _WIN32
+    return (int32_t)GetCurrentProcessId();
+#else
+    return (int32_t)getpid();
+#endif
+}
+
+int32_t omni_getppid(void) {
+#ifdef _WIN32
+    // Windows doesn't have a direct equivalent
+    return 0;
+#else
+    return (int32_t)getppid();
+#endif
+}
+
+// Signal handling: Omni uses POSIX (Linux) signal numbers, mapped here to the
+// host's numbering. Handlers are plain Omni functions taking no arguments.
+static omni_signal_handler_t omni_signal_handlers[NSIG];
+
+static int omni_signal_native(int32_t signum) {
+    switch (signum) {
+    case 2:
+        return SIGINT;
+    case 15:
+        return SIGTERM;
+#ifndef _WIN32
+    case 10:
+        return SIGUSR1;
+#endif
+    default:
+        return -1;
+    }
+}
+
+static void omni_signal_dispatch(int sig) {
+#ifdef _WIN32
+    // signal() handlers are reset to SIG_DFL before they run on Windows
+    signal(sig, omni_signal_dispatch);
+#endif
+    if (sig > 0 && sig < NSIG && omni_signal_handlers[sig]) {
+        omni_signal_handlers[sig]();
+    }
+}
+
+void omni_signal_trap(int32_t signum, omni_signal_handler_t handler) {
+    int sig = omni_signal_native(signum);
+    if (sig < 0 || !handler) {
+        return;
+    }
+    omni_signal_handlers[sig] = handler;
+#ifdef _WIN32
+    signal(sig, omni_signal_dispatch);
+#else
+    struct sigaction action;
+    memset(&action, 0, sizeof(action));
+    action.sa_handler = omni_signal_dispatch;
+    sigemptyset(&action.sa_mask);
+    action.sa_flags = SA_RESTART;
+    sigaction(sig, &action, NULL);
+#endif
+}
+
+void omni_signal_raise(int32_t signum) {
+    int sig = omni_signal_native(signum);
+    if (sig >= 0) {
+        raise(sig);
+    }
+}
+
+// Entry point - this will be implemented by the generated code
+// The generated code will provide the omni_main function
+
+// ============================================================================
+// Map Implementation
+// ============================================================================
+
+// Simple hash map implementation for OmniLang maps
+typedef struct omni_map_entry {
+    void* key;
+    void* value;
+    struct omni_map_entry* next;
+} omni_map_entry_t;
+
+struct omni_map {
+    omni_map_entry_t** buckets;
+    int32_t bucket_count;
+    int32_t size;
+};
+
+// Simple hash function for strings
+static uint32_t hash_string(const char* str) {
+    uint32_t hash = 5381;
+    int c;
+    while ((c = *str++)) {
+        hash = ((hash << 5) + hash) + c;
+    }
+    return hash;
+}
+
+// Simple hash function for integers
+static uint32_t hash_int(int32_t value) {
+    return (uint32_t)value;
+}
+
+omni_map_t* omni_map_create() {
+    omni_map_t* map = (omni_map_t*)malloc(sizeof(omni_map_t));
+    if (!map) return NULL;
+    
+    map->bucket_count = 16; // Start with 16 buckets
+    map->size = 0;
+    map->buckets = (omni_map_entry_t**)calloc(map->bucket_count, sizeof(omni_map_entry_t*));
+    if (!map->buckets) {
+        free(map);
+        return NULL;
+    }
+    
+    return map;
+}
+
+// Rehash the map when load factor exceeds threshold (0.75)
+// This function rehashes all entries into a new bucket array with double the size
+static void omni_map_rehash(omni_map_t* map, int is_string_key) {
+    if (!map || map->size == 0) return;
+    
+    int32_t old_bucket_count = map->bucket_count;
+    omni_map_entry_t** old_buckets = map->buckets;
+    
+    // Double the bucket count
+    map->bucket_count *= 2;
+    map->buckets = (omni_map_entry_t**)calloc(map->bucket_count, sizeof(omni_map_entry_t*));
+    if (!map->buckets) {
+        // Rehashing failed, restore old state
+        map->buckets = old_buckets;
+        map->bucket_count = old_bucket_count;
+        return;
+    }
+    
+    // Rehash all entries
+    for (int32_t i = 0; i < old_bucket_count; i++) {
+        omni_map_entry_t* entry = old_buckets[i];
+        while (entry) {
+            omni_map_entry_t* next = entry->next;
+            
+            // Calculate new bucket
+            uint32_t hash;
+            if (is_string_key) {
+                hash = hash_string((char*)entry->key);
+            } else {
+                hash = hash_int(*(int32_t*)entry->key);
+            }
+            int32_t new_bucket = hash % map->bucket_count;
+            
+            // Insert into new bucket
+            entry->next = map->buckets[new_bucket];
+            map->buckets[new_bucket] = entry;
+            
+            entry = next;
+        }
+    }
+    
+    free(old_buckets);
+}
+
+void omni_map_destroy(omni_map_t* map) {
+    if (!map) return;
+    
+    for (int32_t i = 0; i < map->bucket_count; i++) {
+        omni_map_entry_t* entry = map->buckets[i];
+        while (entry) {
+            omni_map_entry_t* next = entry->next;
+            free(entry->key);
+            free(entry->value);
+            free(entry);
+            entry = next;
+        }
+    }
+    
+    free(map->buckets);
+    free(map);
+}
+
+// Map utility functions
+int32_t omni_map_keys_string_int(omni_map_t* map, char** keys_buffer, int32_t buffer_size) {
+    if (!map || !keys_buffer || buffer_size <= 0) return 0;
+    
+    int32_t count = 0;
+    for (int32_t i = 0; i < map->bucket_count && count < buffer_size; i++) {
+        omni_map_entry_t* entry = map->buckets[i];
+        while (entry && count < buffer_size) {
+            keys_buffer[count] = strdup((char*)entry->key);
+            count++;
+            entry = entry->next;
+        }
+    }
+    return count;
+}
+
+int32_t omni_map_values_string_int(omni_map_t* map, int32_t* values_buffer, int32_t buffer_size) {
+    if (!map || !values_buffer || buffer_size <= 0) return 0;
+    
+    int32_t count = 0;
+    for (int32_t i = 0; i < map->bucket_count && count < buffer_size; i++) {
+        omni_map_entry_t* entry = map->buckets[i];
+        while (entry && count < buffer_size) {
+            values_buffer[count] = *(int32_t*)entry->value;
+            count++;
+            entry = entry->next;
+        }
+    }
+    return count;
+}
+
+omni_map_t* omni_map_copy_string_int(omni_map_t* map) {
+    if (!map) return NULL;
+    
+    omni_map_t* new_map = omni_map_create();
+    if (!new_map) return NULL;
+    
+    // Copy all entries
+    for (int32_t i = 0; i < map->bucket_count; i++) {
+        omni_map_entry_t* entry = map->buckets[i];
+        while (entry) {
+            omni_map_put_string_int(new_map, (char*)entry->key, *(int32_t*)entry->value);
+            entry = entry->next;
+        }
+    }
+    
+    return new_map;
+}
+
+omni_map_t* omni_map_merge_string_int(omni_map_t* a, omni_map_t* b) {
+    if (!a && !b) return NULL;
+    if (!a) return omni_map_copy_string_int(b);
+    if (!b) return omni_map_copy_string_int(a);
+    
+    omni_map_t* merged = omni_map_copy_string_int(a);
+    if (!merged) return NULL;
+    
+    // Add all entries from b (will overwrite duplicates)
+    for (int32_t i = 0; i < b->bucket_count; i++) {
+        omni_map_entry_t* entry = b->buckets[i];
+        while (entry) {
+            omni_map_put_string_int(merged, (char*)entry->key, *(int32_t*)entry->value);
+            entry = entry->next;
+        }
+    }
+    
+    return merged;
+}
+
+void omni_map_put_string_int(omni_map_t* map, const char* key, int32_t value) {
+    if (!map) return;
+    
+    uint32_t hash = hash_string(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    // Check if key already exists
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (strcmp((char*)entry->key, key) == 0) {
+            // Update existing value
+            *(int32_t*)entry->value = value;
+            return;
+        }
+        entry = entry->next;
+    }
+    
+    // Rehash if load factor exceeds 0.75
+    if (map->size * 4 >= map->bucket_count * 3) {
+        omni_map_rehash(map, 1); // 1 = string key
+        bucket = hash % map->bucket_count; // Recalculate bucket after rehash
+    }
+    
+    // Create new entry
+    entry = (omni_map_entry_t*)malloc(sizeof(omni_map_entry_t));
+    if (!entry) return;
+    
+    entry->key = malloc(strlen(key) + 1);
+    entry->value = malloc(sizeof(int32_t));
+    if (!entry->key || !entry->value) {
+        free(entry->key);
+        free(entry->value);
+        free(entry);
+        return;
+    }
+    
+    strcpy((char*)entry->key, key);
+    *(int32_t*)entry->value = value;
+    entry->next = map->buckets[bucket];
+    map->buckets[bucket] = entry;
+    map->size++;
+}
+
+void omni_map_put_int_int(omni_map_t* map, int32_t key, int32_t value) {
+    if (!map) return;
+    
+    uint32_t hash = hash_int(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    // Check if key already exists
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (*(int32_t*)entry->key == key) {
+            // Update existing value
+            *(int32_t*)entry->value = value;
+            return;
+        }
+        entry = entry->next;
+    }
+    
+    // Rehash if load factor exceeds 0.75
+    if (map->size * 4 >= map->bucket_count * 3) {
+        omni_map_rehash(map, 0); // 0 = int key
+        bucket = hash % map->bucket_count; // Recalculate bucket after rehash
+    }
+    
+    // Create new entry
+    entry = (omni_map_entry_t*)malloc(sizeof(omni_map_entry_t));
+    if (!entry) return;
+    
+    entry->key = malloc(sizeof(int32_t));
+    entry->value = malloc(sizeof(int32_t));
+    if (!entry->key || !entry->value) {
+        free(entry->key);
+        free(entry->value);
+        free(entry);
+        return;
+    }
+    
+    *(int32_t*)entry->key = key;
+    *(int32_t*)entry->value = value;
+    entry->next = map->buckets[bucket];
+    map->buckets[bucket] = entry;
+    map->size++;
+}
+
+int32_t omni_map_get_string_int(omni_map_t* map, const char* key) {
+    if (!map) return 0;
+    
+    uint32_t hash = hash_string(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (strcmp((char*)entry->key, key) == 0) {
+            return *(int32_t*)entry->value;
+        }
+        entry = entry->next;
+    }
+    
+    return 0; // Key not found, return default value
+}
+
+int32_t omni_map_get_int_int(omni_map_t* map, int32_t key) {
+    if (!map) return 0;
+    
+    uint32_t hash = hash_int(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (*(int32_t*)entry->key == key) {
+            return *(int32_t*)entry->value;
+        }
+        entry = entry->next;
+    }
+    
+    return 0; // Key not found, return default value
+}
+
+int32_t omni_map_contains_string(omni_map_t* map, const char* key) {
+    if (!map) return 0;
+    
+    uint32_t hash = hash_string(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (strcmp((char*)entry->key, key) == 0) {
+            return 1; // Found
+        }
+        entry = entry->next;
+    }
+    
+    return 0; // Not found
+}
+
+int32_t omni_map_contains_int(omni_map_t* map, int32_t key) {
+    if (!map) return 0;
+    
+    uint32_t hash = hash_int(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (*(int32_t*)entry->key == key) {
+            return 1; // Found
+        }
+        entry = entry->next;
+    }
+    
+    return 0; // Not found
+}
+
+// Typed variants used by the map.contains instruction; entries are found by
+// key alone, whatever the value type
+int32_t omni_map_contains_string_int(omni_map_t* map, const char* key) { return omni_map_contains_string(map, key); }
+int32_t omni_map_contains_string_string(omni_map_t* map, const char* key) { return omni_map_contains_string(map, key); }
+int32_t omni_map_contains_string_float(omni_map_t* map, const char* key) { return omni_map_contains_string(map, key); }
+int32_t omni_map_contains_string_bool(omni_map_t* map, const char* key) { return omni_map_contains_string(map, key); }
+int32_t omni_map_contains_int_int(omni_map_t* map, int32_t key) { return omni_map_contains_int(map, key); }
+int32_t omni_map_contains_int_string(omni_map_t* map, int32_t key) { return omni_map_contains_int(map, key); }
+int32_t omni_map_contains_int_float(omni_map_t* map, int32_t key) { return omni_map_contains_int(map, key); }
+int32_t omni_map_contains_int_bool(omni_map_t* map, int32_t key) { return omni_map_contains_int(map, key); }
+
+int32_t omni_map_size(omni_map_t* map) {
+    return map ? map->size : 0;
+}
+
+// Additional map put operations
+void omni_map_put_string_string(omni_map_t* map, const char* key, const char* value) {
+    if (!map) return;
+    
+    uint32_t hash = hash_string(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    // Check if key already exists
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (strcmp((char*)entry->key, key) == 0) {
+            // Update existing value - free old value before allocating new one
+            free(entry->value);
+            entry->value = malloc(strlen(value) + 1);
+            if (entry->value) {
+                strcpy((char*)entry->value, value);
+            }
+            // Key stays the same, no need to free/reallocate
+            return;
+        }
+        entry = entry->next;
+    }
+    
+    // Rehash if load factor exceeds 0.75
+    if (map->size * 4 >= map->bucket_count * 3) {
+        omni_map_rehash(map, 1); // 1 = string key
+        bucket = hash % map->bucket_count; // Recalculate bucket after rehash
+    }
+    
+    // Create new entry
+    entry = (omni_map_entry_t*)malloc(sizeof(omni_map_entry_t));
+    if (!entry) return;
+    
+    entry->key = malloc(strlen(key) + 1);
+    entry->value = malloc(strlen(value) + 1);
+    if (!entry->key || !entry->value) {
+        free(entry->key);
+        free(entry->value);
+        free(entry);
+        return;
+    }
+    
+    strcpy((char*)entry->key, key);
+    strcpy((char*)entry->value, value);
+    entry->next = map->buckets[bucket];
+    map->buckets[bucket] = entry;
+    map->size++;
+}
+
+void omni_map_put_string_float(omni_map_t* map, const char* key, double value) {
+    if (!map) return;
+    
+    uint32_t hash = hash_string(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    // Check if key already exists
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (strcmp((char*)entry->key, key) == 0) {
+            *(double*)entry->value = value;
+            return;
+        }
+        entry = entry->next;
+    }
+    
+    // Rehash if load factor exceeds 0.75
+    if (map->size * 4 >= map->bucket_count * 3) {
+        omni_map_rehash(map, 1); // 1 = string key
+        bucket = hash % map->bucket_count; // Recalculate bucket after rehash
+    }
+    
+    // Create new entry
+    entry = (omni_map_entry_t*)malloc(sizeof(omni_map_entry_t));
+    if (!entry) return;
+    
+    entry->key = malloc(strlen(key) + 1);
+    entry->value = malloc(sizeof(double));
+    if (!entry->key || !entry->value) {
+        free(entry->key);
+        free(entry->value);
+        free(entry);
+        return;
+    }
+    
+    strcpy((char*)entry->key, key);
+    *(double*)entry->value = value;
+    entry->next = map->buckets[bucket];
+    map->buckets[bucket] = entry;
+    map->size++;
+}
+
+void omni_map_put_string_bool(omni_map_t* map, const char* key, int32_t value) {
+    if (!map) return;
+    
+    uint32_t hash = hash_string(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    // Check if key already exists
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (strcmp((char*)entry->key, key) == 0) {
+            *(int32_t*)entry->value = value;
+            return;
+        }
+        entry = entry->next;
+    }
+    
+    // Rehash if load factor exceeds 0.75
+    if (map->size * 4 >= map->bucket_count * 3) {
+        omni_map_rehash(map, 1); // 1 = string key
+        bucket = hash % map->bucket_count; // Recalculate bucket after rehash
+    }
+    
+    // Create new entry
+    entry = (omni_map_entry_t*)malloc(sizeof(omni_map_entry_t));
+    if (!entry) return;
+    
+    entry->key = malloc(strlen(key) + 1);
+    entry->value = malloc(sizeof(int32_t));
+    if (!entry->key || !entry->value) {
+        free(entry->key);
+        free(entry->value);
+        free(entry);
+        return;
+    }
+    
+    strcpy((char*)entry->key, key);
+    *(int32_t*)entry->value = value;
+    entry->next = map->buckets[bucket];
+    map->buckets[bucket] = entry;
+    map->size++;
+}
+
+void omni_map_put_int_string(omni_map_t* map, int32_t key, const char* value) {
+    if (!map) return;
+    
+    uint32_t hash = hash_int(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    // Check if key already exists
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (*(int32_t*)entry->key == key) {
+            free(entry->value);
+            entry->value = malloc(strlen(value) + 1);
+            if (entry->value) {
+                strcpy((char*)entry->value, value);
+            }
+            return;
+        }
+        entry = entry->next;
+    }
+    
+    // Rehash if load factor exceeds 0.75
+    if (map->size * 4 >= map->bucket_count * 3) {
+        omni_map_rehash(map, 0); // 0 = int key
+        bucket = hash % map->bucket_count; // Recalculate bucket after rehash
+    }
+    
+    // Create new entry
+    entry = (omni_map_entry_t*)malloc(sizeof(omni_map_entry_t));
+    if (!entry) return;
+    
+    entry->key = malloc(sizeof(int32_t));
+    entry->value = malloc(strlen(value) + 1);
+    if (!entry->key || !entry->value) {
+        free(entry->key);
+        free(entry->value);
+        free(entry);
+        return;
+    }
+    
+    *(int32_t*)entry->key = key;
+    strcpy((char*)entry->value, value);
+    entry->next = map->buckets[bucket];
+    map->buckets[bucket] = entry;
+    map->size++;
+}
+
+void omni_map_put_int_float(omni_map_t* map, int32_t key, double value) {
+    if (!map) return;
+    
+    uint32_t hash = hash_int(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    // Check if key already exists
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (*(int32_t*)entry->key == key) {
+            *(double*)entry->value = value;
+            return;
+        }
+        entry = entry->next;
+    }
+    
+    // Rehash if load factor exceeds 0.75
+    if (map->size * 4 >= map->bucket_count * 3) {
+        omni_map_rehash(map, 0); // 0 = int key
+        bucket = hash % map->bucket_count; // Recalculate bucket after rehash
+    }
+    
+    // Create new entry
+    entry = (omni_map_entry_t*)malloc(sizeof(omni_map_entry_t));
+    if (!entry) return;
+    
+    entry->key = malloc(sizeof(int32_t));
+    entry->value = malloc(sizeof(double));
+    if (!entry->key || !entry->value) {
+        free(entry->key);
+        free(entry->value);
+        free(entry);
+        return;
+    }
+    
+    *(int32_t*)entry->key = key;
+    *(double*)entry->value = value;
+    entry->next = map->buckets[bucket];
+    map->buckets[bucket] = entry;
+    map->size++;
+}
+
+void omni_map_put_int_bool(omni_map_t* map, int32_t key, int32_t value) {
+    if (!map) return;
+    
+    uint32_t hash = hash_int(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    // Check if key already exists
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (*(int32_t*)entry->key == key) {
+            *(int32_t*)entry->value = value;
+            return;
+        }
+        entry = entry->next;
+    }
+    
+    // Rehash if load factor exceeds 0.75
+    if (map->size * 4 >= map->bucket_count * 3) {
+        omni_map_rehash(map, 0); // 0 = int key
+        bucket = hash % map->bucket_count; // Recalculate bucket after rehash
+    }
+    
+    // Create new entry
+    entry = (omni_map_entry_t*)malloc(sizeof(omni_map_entry_t));
+    if (!entry) return;
+    
+    entry->key = malloc(sizeof(int32_t));
+    entry->value = malloc(sizeof(int32_t));
+    if (!entry->key || !entry->value) {
+        free(entry->key);
+        free(entry->value);
+        free(entry);
+        return;
+    }
+    
+    *(int32_t*)entry->key = key;
+    *(int32_t*)entry->value = value;
+    entry->next = map->buckets[bucket];
+    map->buckets[bucket] = entry;
+    map->size++;
+}
+
+// Additional map get operations
+const char* omni_map_get_string_string(omni_map_t* map, const char* key) {
+    if (!map) return NULL;
+    
+    uint32_t hash = hash_string(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (strcmp((char*)entry->key, key) == 0) {
+            return (const char*)entry->value;
+        }
+        entry = entry->next;
+    }
+    
+    return NULL; // Key not found
+}
+
+double omni_map_get_string_float(omni_map_t* map, const char* key) {
+    if (!map) return 0.0;
+    
+    uint32_t hash = hash_string(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (strcmp((char*)entry->key, key) == 0) {
+            return *(double*)entry->value;
+        }
+        entry = entry->next;
+    }
+    
+    return 0.0; // Key not found
+}
+
+int32_t omni_map_get_string_bool(omni_map_t* map, const char* key) {
+    if (!map) return 0;
+    
+    uint32_t hash = hash_string(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (strcmp((char*)entry->key, key) == 0) {
+            return *(int32_t*)entry->value;
+        }
+        entry = entry->next;
+    }
+    
+    return 0; // Key not found
+}
+
+const char* omni_map_get_int_string(omni_map_t* map, int32_t key) {
+    if (!map) return NULL;
+    
+    uint32_t hash = hash_int(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (*(int32_t*)entry->key == key) {
+            return (const char*)entry->value;
+        }
+        entry = entry->next;
+    }
+    
+    return NULL; // Key not found
+}
+
+double omni_map_get_int_float(omni_map_t* map, int32_t key) {
+    if (!map) return 0.0;
+    
+    uint32_t hash = hash_int(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (*(int32_t*)entry->key == key) {
+            return *(double*)entry->value;
+        }
+        entry = entry->next;
+    }
+    
+    return 0.0; // Key not found
+}
+
+int32_t omni_map_get_int_bool(omni_map_t* map, int32_t key) {
+    if (!map) return 0;
+    
+    uint32_t hash = hash_int(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    omni_map_entry_t* entry = map->buckets[bucket];
+    while (entry) {
+        if (*(int32_t*)entry->key == key) {
+            return *(int32_t*)entry->value;
+        }
+        entry = entry->next;
+    }
+    
+    return 0; // Key not found
+}
+
+// Map delete operations
+void omni_map_delete_string(omni_map_t* map, const char* key) {
+    if (!map) return;
+    
+    uint32_t hash = hash_string(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    omni_map_entry_t* entry = map->buckets[bucket];
+    omni_map_entry_t* prev = NULL;
+    
+    while (entry) {
+        if (strcmp((char*)entry->key, key) == 0) {
+            if (prev) {
+                prev->next = entry->next;
+            } else {
+                map->buckets[bucket] = entry->next;
+            }
+            free(entry->key);
+            free(entry->value);
+            free(entry);
+            map->size--;
+            return;
+        }
+        prev = entry;
+        entry = entry->next;
+    }
+}
+
+void omni_map_delete_int(omni_map_t* map, int32_t key) {
+    if (!map) return;
+    
+    uint32_t hash = hash_int(key);
+    int32_t bucket = hash % map->bucket_count;
+    
+    omni_map_entry_t* entry = map->buckets[bucket];
+    omni_map_entry_t* prev = NULL;
+    
+    while (entry) {
+        if (*(int32_t*)entry->key == key) {
+            if (prev) {
+                prev->next = entry->next;
+            } else {
+                map->buckets[bucket] = entry->next;
+            }
+            free(entry->key);
+            free(entry->value);
+            free(entry);
+            map->size--;
+            return;
+        }
+        prev = entry;
+        entry = entry->next;
+    }
+}
+
+// ============================================================================
+// Struct Implementation
+// ============================================================================
+
+// Simple struct implementation for OmniLang structs
+// NOTE: This implementation only supports primitive field types (string, int, float, bool).
+// Nested structs and arrays as field values are not supported and will cause
+// memory leaks or crashes. For nested structs, the runtime would need to:
+// 1. Store struct values as omni_struct_t* pointers
+// 2. Recursively free nested structs in omni_struct_destroy
+// 3. Handle type checking for nested struct access
+// This is a known limitation that requires architectural changes to fix.
+typedef struct omni_struct_field {
+    char* name;
+    void* value;
+    int32_t value_type; // 0=string, 1=int, 2=float, 3=bool
+    struct omni_struct_field* next;
+} omni_struct_field_t;
+
+struct omni_struct {
+    omni_struct_field_t* fields;
+};
+
+omni_struct_t* omni_struct_create() {
+    omni_struct_t* struct_ptr = (omni_struct_t*)malloc(sizeof(omni_struct_t));
+    if (!struct_ptr) return NULL;
+    
+    struct_ptr->fields = NULL;
+    return struct_ptr;
+}
+
+void omni_struct_destroy(omni_struct_t* struct_ptr) {
+    if (!struct_ptr) return;
+    
+    omni_struct_field_t* field = struct_ptr->fields;
+    while (field) {
+        omni_struct_field_t* next = field->next;
+        free(field->name);
+        if (field->value_type == 0) { // string
+            free((char*)field->value);
+        } else {
+            free(field->value);
+        }
+        free(field);
+        field = next;
+    }
+    
+    free(struct_ptr);
+}
+
+void omni_struct_set_string_field(omni_struct_t* struct_ptr, const char* field_name, const char* value) {
+    if (!struct_ptr) return;
+    if (!value) return; // Skip NULL values
+    
+    // Check if field already exists
+    omni_struct_field_t* field = struct_ptr->fields;
+    while (field) {
+        if (strcmp(field->name, field_name) == 0) {
+            // Update existing field
+            if (field->value_type == 0) { // string
+                free((char*)field->value);
+            } else {
+                free(field->value);
+            }
+            field->value = malloc(strlen(value) + 1);
+            if (field->value) {
+                strcpy((char*)field->value, value);
+                field->value_type = 0; // string
+            }
+            return;
+        }
+        field = field->next;
+    }
+    
+    // Create new field
+    field = (omni_struct_field_t*)malloc(sizeof(omni_struct_field_t));
+    if (!field) return;
+    
+    field->name = malloc(strlen(field_name) + 1);
+    field->value = malloc(strlen(value) + 1);
+    if (!field->name || !field->value) {
+        free(field->name);
+        free(field->value);
+        free(field);
+        return;
+    }
+    
+    strcpy(field->name, field_name);
+    strcpy((char*)field->value, value);
+    field->value_type = 0; // string
+    field->next = struct_ptr->fields;
+    struct_ptr->fields = field;
+}
+
+void omni_struct_set_int_field(omni_struct_t* struct_ptr, const char* field_name, int32_t value) {
+    if (!struct_ptr) return;
+    
+    // Check if field already exists
+    omni_struct_field_t* field = struct_ptr->fields;
+    while (field) {
+        if (strcmp(field->name, field_name) == 0) {
+            // Update existing field
+            if (field->value_type == 0) { // string
+                free((char*)field->value);
+            } else {
+                free(field->value);
+            }
+            field->value = malloc(sizeof(int32_t));
+            if (field->value) {
+                *(int32_t*)field->value = value;
+                field->value_type = 1; // int
+            }
+            return;
+        }
+        field = field->next;
+    }
+    
+    // Create new field
+    field = (omni_struct_field_t*)malloc(sizeof(omni_struct_field_t));
+    if (!field) return;
+    
+    field->name = malloc(strlen(field_name) + 1);
+    field->value = malloc(sizeof(int32_t));
+    if (!field->name || !field->value) {
+        free(field->name);
+        free(field->value);
+        free(field);
+        return;
+    }
+    
+    strcpy(field->name, field_name);
+    *(int32_t*)field->value = value;
+    field->value_type = 1; // int
+    field->next = struct_ptr->fields;
+    struct_ptr->fields = field;
+}
+
+void omni_struct_set_float_field(omni_struct_t* struct_ptr, const char* field_name, double value) {
+    if (!struct_ptr) return;
+    
+    // Check if field already exists
+    omni_struct_field_t* field = struct_ptr->fields;
+    while (field) {
+        if (strcmp(field->name, field_name) == 0) {
+            // Update existing field
+            if (field->value_type == 0) { // string
+                free((char*)field->value);
+            } else {
+                free(field->value);
+            }
+            field->value = malloc(sizeof(double));
+            if (field->value) {
+                *(double*)field->value = value;
+                field->value_type = 2; // float
+            }
+            return;
+        }
+        field = field->next;
+    }
+    
+    // Create new field
+    field = (omni_struct_field_t*)malloc(sizeof(omni_struct_field_t));
+    if (!field) return;
+    
+    field->name = malloc(strlen(field_name) + 1);
+    field->value = malloc(sizeof(double));
+    if (!field->name || !field->value) {
+        free(field->name);
+        free(field->value);
+        free(field);
+        return;
+    }
+    
+    strcpy(field->name, field_name);
+    *(double*)field->value = value;
+    field->value_type = 2; // float
+    field->next = struct_ptr->fields;
+    struct_ptr->fields = field;
+}
+
+void omni_struct_set_bool_field(omni_struct_t* struct_ptr, const char* field_name, int32_t value) {
+    if (!struct_ptr) return;
+    
+    // Check if field already exists
+    omni_struct_field_t* field = struct_ptr->fields;
+    while (field) {
+        if (strcmp(field->name, field_name) == 0) {
+            // Update existing field
+            if (field->value_type == 0) { // string
+                free((char*)field->value);
+            } else {
+                free(field->value);
+            }
+            field->value = malloc(sizeof(int32_t));
+            if (field->value) {
+                *(int32_t*)field->value = value;
+                field->value_type = 3; // bool
+            }
+            return;
+        }
+        field = field->next;
+    }
+    
+    // Create new field
+    field = (omni_struct_field_t*)malloc(sizeof(omni_struct_field_t));
+    if (!field) return;
+    
+    field->name = malloc(strlen(field_name) + 1);
+    field->value = malloc(sizeof(int32_t));
+    if (!field->name || !field->value) {
+        free(field->name);
+        free(field->value);
+        free(field);
+        return;
+    }
+    
+    strcpy(field->name, field_name);
+    *(int32_t*)field->value = value;
+    field->value_type = 3; // bool
+    field->next = struct_ptr->fields;
+    struct_ptr->fields = field;
+}
+
+const char* omni_struct_get_string_field(omni_struct_t* struct_ptr, const char* field_name) {
+    if (!struct_ptr) return "";
+    
+    omni_struct_field_t* field = struct_ptr->fields;
+    while (field) {
+        if (strcmp(field->name, field_name) == 0 && field->value_type == 0) {
+            return (const char*)field->value;
+        }
+        field = field->next;
+    }
+    
+    return ""; // Field not found, return default value
+}
+
+int32_t omni_struct_get_int_field(omni_struct_t* struct_ptr, const char* field_name) {
+    if (!struct_ptr) return 0;
+    
+    omni_struct_field_t* field = struct_ptr->fields;
+    while (field) {
+        if (strcmp(field->name, field_name) == 0 && field->value_type == 1) {
+            return *(int32_t*)field->value;
+        }
+        field = field->next;
+    }
+    
+    return 0; // Field not found, return default value
+}
+
+double omni_struct_get_float_field(omni_struct_t* struct_ptr, const char* field_name) {
+    if (!struct_ptr) return 0.0;
+    
+    omni_struct_field_t* field = struct_ptr->fields;
+    while (field) {
+        if (strcmp(field->name, field_name) == 0 && field->value_type == 2) {
+            return *(double*)field->value;
+        }
+        field = field->next;
+    }
+    
+    return 0.0; // Field not found, return default value
+}
+
+int32_t omni_struct_get_bool_field(omni_struct_t* struct_ptr, const char* field_name) {
+    if (!struct_ptr) return 0;
+    
+    omni_struct_field_t* field = struct_ptr->fields;
+    while (field) {
+        if (strcmp(field->name, field_name) == 0 && field->value_type == 3) {
+            return *(int32_t*)field->value;
+        }
+        field = field->next;
+    }
+    
+    return 0; // Field not found, return default value
+}
+
+// Optional values
+void omni_opt_unwrap_none(void) {
+    fprintf(stderr, "opt.unwrap: value is none\n");
+    exit(1);
+}
+
+// Deferred calls (defer)
+omni_defer_frame* omni_defer_top = NULL;
+
+void omni_defer_push(omni_defer_frame* frame, void (*fn)(void* env), void* env) {
+    omni_defer_call* call = (omni_defer_call*)malloc(sizeof(omni_defer_call));
+    if (!call) {
+        fprintf(stderr, "defer: out of memory\n");
+        exit(1);
+    }
+    call->fn = fn;
+    call->env = env;
+    call->prev = frame->calls;
+    frame->calls = call;
+}
+
+void omni_defer_unwind(omni_defer_frame* until) {
+    while (omni_defer_top && omni_defer_top != until) {
+        omni_defer_frame* frame = omni_defer_top;
+        omni_defer_call* call = frame->calls;
+        if (!call) {
+            omni_defer_top = frame->prev;
+            continue;
+        }
+        // Pop the call before making it, so that a call that throws does
+        // not run again when the exception unwinds through the frame
+        frame->calls = call->prev;
+        call->fn(call->env);
+        free(call->env);
+        free(call);
+    }
+}
+
+// Exceptions (try/catch/finally)
+omni_try_frame* omni_try_top = NULL;
+omni_exception_t omni_exception;
+
+void omni_throw(omni_exception_t exception) {
+    omni_exception = exception;
+    omni_rethrow();
+}
+
+void omni_rethrow(void) {
+    omni_try_frame* frame = omni_try_top;
+    // The functions the exception leaves make their deferred calls first
+    omni_defer_unwind(frame ? frame->defers : NULL);
+    if (!frame) {
+        if (omni_exception.s) {
+            fprintf(stderr, "uncaught exception: %s\n", omni_exception.s);
+        } else if (omni_exception.type && strcmp(omni_exception.type, "int") == 0) {
+            fprintf(stderr, "uncaught exception: %lld\n", (long long)omni_exception.i);
+        } else {
+            fprintf(stderr, "uncaught exception: %s\n", omni_exception.type ? omni_exception.type : "unknown");
+        }
+        exit(1);
+    }
+    omni_try_top = frame->prev;
+    longjmp(frame->env, 1);
+}
+
+// Promise/Async support (simplified synchronous implementation)
+omni_promise_t* omni_promise_create_int(int32_t value) {
+    omni_promise_t* promise = (omni_promise_t*)malloc(sizeof(omni_promise_t));
+    if (!promise) return NULL;
+    promise->type = 0; // int
+    promise->value = malloc(sizeof(int32_t));
+    if (!promise->value) {
+        free(promise);
+        return NULL;
+    }
+    *(int32_t*)promise->value = value;
+    promise->done = 1;
+    return promise;
+}
+
+omni_promise_t* omni_promise_create_string(const char* value) {
+    omni_promise_t* promise = (omni_promise_t*)malloc(sizeof(omni_promise_t));
+    if (!promise) return NULL;
+    promise->type = 1; // string
+    if (value) {
+        promise->value = strdup(value);
+    } else {
+        promise->value = strdup("");
+    }
+    promise->done = 1;
+    return promise;
+}
+
+omni_promise_t* omni_promise_create_float(double value) {
+    omni_promise_t* promise = (omni_promise_t*)malloc(sizeof(omni_promise_t));
+    if (!promise) return NULL;
+    promise->type = 2; // float
+    promise->value = malloc(sizeof(double));
+    if (!promise->value) {
+        free(promise);
+        return NULL;
+    }
+    *(double*)promise->value = value;
+    promise->done = 1;
+    return promise;
+}
+
+omni_promise_t* omni_promise_create_bool(int32_t value) {
+    omni_promise_t* promise = (omni_promise_t*)malloc(sizeof(omni_promise_t));
+    if (!promise) return NULL;
+    promise->type = 3; // bool
+    promise->value = malloc(sizeof(int32_t));
+    if (!promise->value) {
+        free(promise);
+        return NULL;
+    }
+    *(int32_t*)promise->value = value;
+    promise->done = 1;
+    return promise;
+}
+
+omni_promise_t* omni_promise_create_struct(omni_struct_t* value) {
+    omni_promise_t* promise = (omni_promise_t*)malloc(sizeof(omni_promise_t));
+    if (!promise) return NULL;
+    promise->type = 4; // struct
+    promise->value = value;
+    promise->done = 1;
+    return promise;
+}
+
+int32_t omni_await_int(omni_promise_t* promise) {
+    if (!promise || !promise->done || promise->type != 0) {
+        return 0;
+    }
+    return *(int32_t*)promise->value;
+}
+
+// NOTE: This function returns a newly allocated copy of the string stored in the promise.
+// The caller is responsible for freeing the returned string using free().
+// This ensures the string remains valid even after the promise is freed.
+char* omni_await_string(omni_promise_t* promise) {
+    if (!promise || !promise->done || promise->type != 1) {
+        char* empty = (char*)malloc(1);
+        if (empty) {
+            empty[0] = '\0';
+        }
+        return empty;
+    }
+    const char* str = (const char*)promise->value;
+    if (!str) {
+        char* empty = (char*)malloc(1);
+        if (empty) {
+            empty[0] = '\0';
+        }
+        return empty;
+    }
+    return strdup(str);
+}
+
+double omni_await_float(omni_promise_t* promise) {
+    if (!promise || !promise->done || promise->type != 2) {
+        return 0.0;
+    }
+    return *(double*)promise->value;
+}
+
+int32_t omni_await_bool(omni_promise_t* promise) {
+    if (!promise || !promise->done || promise->type != 3) {
+        return 0;
+    }
+    return *(int32_t*)promise->value;
+}
+
+omni_struct_t* omni_await_struct(omni_promise_t* promise) {
+    if (!promise || !promise->done || promise->type != 4) {
+        return NULL;
+    }
+    return (omni_struct_t*)promise->value;
+}
+
+// NOTE: This function frees the promise and all its associated memory.
+// For string promises, the string returned by omni_await_string becomes invalid after this call.
+// Callers must copy the string (e.g., using strdup) if they need it after freeing the promise.
+void omni_promise_free(omni_promise_t* promise) {
+    if (!promise) return;
+    if (promise->value && promise->type != 4) {
+        // For string promises, value is a strdup'd string that needs freeing
+        // For other types, value is a malloc'd buffer that needs freeing;
+        // a struct belongs to whoever awaited it
+        free(promise->value);
+    }
+    free(promise);
+}
+
+// File I/O convenience functions (for async operations)
+// NOTE: Returns a newly allocated string - caller must free it using free()
+// This function allocates memory that must be freed by the caller to avoid leaks.
+char* omni_read_file(const char* path) {
+    if (!path) {
+        return NULL;
+    }
+    
+    FILE* file = fopen(path, "r");
+    if (!file) {
+        // Return NULL on error instead of leaking strdup("")
+        return NULL;
+    }
+    
+    fseek(file, 0, SEEK_END);
+    long size = ftell(file);
+    fseek(file, 0, SEEK_SET);
+    
+    // Handle empty files
+    if (size <= 0) {
+        fclose(file);
+        char* empty = (char*)malloc(1);
+        if (empty) {
+            empty[0] = '\0';
+        }
+        return empty;
+    }
+    
+    char* buffer = (char*)malloc(size + 1);
+    if (!buffer) {
+        fclose(file);
+        return NULL;
+    }
+    
+    size_t read = fread(buffer, 1, size, file);
+    buffer[read] = '\0';
+    fclose(file);
+    
+    return buffer;
+}
+
+int32_t omni_write_file(const char* path, const char* content) {
+    if (!path) {
+        fprintf(stderr, "ERROR: omni_write_file called with NULL path\n");
+        return 0;
+    }
+    if (!content) {
+        fprintf(stderr, "ERROR: omni_write_file called with NULL content\n");
+        return 0;
+    }
+    
+    FILE* file = fopen(path, "w");
+    if (!file) {
+        return 0;
+    }
+    
+    size_t len = strlen(content);
+    size_t written = fwrite(content, 1, len, file);
+    fclose(file);
+    
+    return (written == len) ? 1 : 0;
+}
+
+int32_t omni_append_file(const char* path, const char* content) {
+    if (!path) {
+        fprintf(stderr, "ERROR: omni_append_file called with NULL path\n");
+        return 0;
+    }
+    if (!content) {
+        fprintf(stderr, "ERROR: omni_append_file called with NULL content\n");
+        return 0;
+    }
+    
+    FILE* file = fopen(path, "a");
+    if (!file) {
+        return 0;
+    }
+    
+    size_t len = strlen(content);
+    size_t written = fwrite(content, 1, len, file);
+    fclose(file);
+    
+    return (written == len) ? 1 : 0;
+}
+
+// ============================================================================
+// Collection Data Structures Implementation
+// ============================================================================
+
+// Set implementation (using map internally)
+struct omni_set {
+    omni_map_t* map; // Use map to store set elements (key = element, value = 1)
+};
+
+omni_set_t* omni_set_create() {
+    omni_set_t* set = (omni_set_t*)malloc(sizeof(omni_set_t));
+    if (!set) return NULL;
+    set->map = omni_map_create();
+    if (!set->map) {
+        free(set);
+        return NULL;
+    }
+    return set;
+}
+
+void omni_set_destroy(omni_set_t* set) {
+    if (!set) return;
+    if (set->map) omni_map_destroy(set->map);
+    free(set);
+}
+
+int32_t omni_set_add(omni_set_t* set, int32_t element) {
+    if (!set || !set->map) return 0;
+    omni_map_put_int_int(set->map, element, 1);
+    return 1;
+}
+
+int32_t omni_set_remove(omni_set_t* set, int32_t element) {
+    if (!set || !set->map) return 0;
+    if (omni_map_contains_int(set->map, element)) {
+        omni_map_delete_int(set->map, element);
+        return 1;
+    }
+    return 0;
+}
+
+int32_t omni_set_contains(omni_set_t* set, int32_t element) {
+    if (!set || !set->map) return 0;
+    return omni_map_contains_int(set->map, element);
+}
+
+int32_t omni_set_size(omni_set_t* set) {
+    if (!set || !set->map) return 0;
+    return omni_map_size(set->map);
+}
+
+void omni_set_clear(omni_set_t* set) {
+    if (!set || !set->map) return;
+    // Recreate the map to clear it
+    omni_map_destroy(set->map);
+    set->map = omni_map_create();
+}
+
+omni_set_t* omni_set_union(omni_set_t* a, omni_set_t* b) {
+    if (!a && !b) return NULL;
+    omni_set_t* result = omni_set_create();
+    if (!result) return NULL;
+    
+    // Add all elements from a
+    if (a && a->map) {
+        for (int32_t i = 0; i < a->map->bucket_count; i++) {
+            omni_map_entry_t* entry = a->map->buckets[i];
+            while (entry) {
+                omni_set_add(result, *(int32_t*)entry->key);
+                entry = entry->next;
+            }
+        }
+    }
+    
+    // Add all elements from b
+    if (b && b->map) {
+        for (int32_t i = 0; i < b->map->bucket_count; i++) {
+            omni_map_entry_t* entry = b->map->buckets[i];
+            while (entry) {
+                omni_set_add(result, *(int32_t*)entry->key);
+                entry = entry->next;
+            }
+        }
+    }
+    
+    return result;
+}
+
+omni_set_t* omni_set_intersection(omni_set_t* a, omni_set_t* b) {
+    if (!a || !b) return omni_set_create();
+    omni_set_t* result = omni_set_create();
+    if (!result) return NULL;
+    
+    // Add elements that are in both sets
+    if (a->map && b->map) {
+        for (int32_t i = 0; i < a->map->bucket_count; i++) {
+            omni_map_entry_t* entry = a->map->buckets[i];
+            while (entry) {
+                int32_t element = *(int32_t*)entry->key;
+                if (omni_set_contains(b, element)) {
+                    omni_set_add(result, element);
+                }
+                entry = entry->next;
+            }
+        }
+    }
+    
+    return result;
+}
+
+omni_set_t* omni_set_difference(omni_set_t* a, omni_set_t* b) {
+    if (!a) return omni_set_create();
+    omni_set_t* result = omni_set_create();
+    if (!result) return NULL;
+    
+    // Add elements from a that are not in b
+    if (a->map) {
+        for (int32_t i = 0; i < a->map->bucket_count; i++) {
+            omni_map_entry_t* entry = a->map->buckets[i];
+            while (entry) {
+                int32_t element = *(int32_t*)entry->key;
+                if (!b || !omni_set_contains(b, element)) {
+                    omni_set_add(result, element);
+                }
+                entry = entry->next;
+            }
+        }
+    }
+    
+    return result;
+}
+
+// Queue implementation (FIFO using linked list)
+typedef struct omni_queue_node {
+    int32_t value;
+    struct omni_queue_node* next;
+} omni_queue_node_t;
+
+struct omni_queue {
+    omni_queue_node_t* front;
+    omni_queue_node_t* rear;
+    int32_t size;
+};
+
+omni_queue_t* omni_queue_create() {
+    omni_queue_t* queue = (omni_queue_t*)malloc(sizeof(omni_queue_t));
+    if (!queue) return NULL;
+    queue->front = NULL;
+    queue->rear = NULL;
+    queue->size = 0;
+    return queue;
+}
+
+void omni_queue_destroy(omni_queue_t* queue) {
+    if (!queue) return;
+    omni_queue_clear(queue);
+    free(queue);
+}
+
+void omni_queue_enqueue(omni_queue_t* queue, int32_t element) {
+    if (!queue) return;
+    omni_queue_node_t* node = (omni_queue_node_t*)malloc(sizeof(omni_queue_node_t));
+    if (!node) return;
+    node->value = element;
+    node->next = NULL;
+    
+    if (queue->rear == NULL) {
+        queue->front = queue->rear = node;
+    } else {
+        queue->rear->next = node;
+        queue->rear = node;
+    }
+    queue->size++;
+}
+
+int32_t omni_queue_dequeue(omni_queue_t* queue) {
+    if (!queue || queue->front == NULL) return 0;
+    omni_queue_node_t* node = queue->front;
+    int32_t value = node->value;
+    queue->front = queue->front->next;
+    if (queue->front == NULL) {
+        queue->rear = NULL;
+    }
+    free(node);
+    queue->size--;
+    return value;
+}
+
+int32_t omni_queue_peek(omni_queue_t* queue) {
+    if (!queue || queue->front == NULL) return 0;
+    return queue->front->value;
+}
+
+int32_t omni_queue_is_empty(omni_queue_t* queue) {
+    return (!queue || queue->front == NULL) ? 1 : 0;
+}
+
+int32_t omni_queue_size(omni_queue_t* queue) {
+    return queue ? queue->size : 0;
+}
+
+void omni_queue_clear(omni_queue_t* queue) {
+    if (!queue) return;
+    while (queue->front != NULL) {
+        omni_queue_dequeue(queue);
+    }
+}
+
+// Stack implementation (LIFO using linked list)
+typedef struct omni_stack_node {
+    int32_t value;
+    struct omni_stack_node* next;
+} omni_stack_node_t;
+
+struct omni_stack {
+    omni_stack_node_t* top;
+    int32_t size;
+};
+
+omni_stack_t* omni_stack_create() {
+    omni_stack_t* stack = (omni_stack_t*)malloc(sizeof(omni_stack_t));
+    if (!stack) return NULL;
+    stack->top = NULL;
+    stack->size = 0;
+    return stack;
+}
+
+void omni_stack_destroy(omni_stack_t* stack) {
+    if (!stack) return;
+    omni_stack_clear(stack);
+    free(stack);
+}
+
+void omni_stack_push(omni_stack_t* stack, int32_t element) {
+    if (!stack) return;
+    omni_stack_node_t* node = (omni_stack_node_t*)malloc(sizeof(omni_stack_node_t));
+    if (!node) return;
+    node->value = element;
+    node->next = stack->top;
+    stack->top = node;
+    stack->size++;
+}
+
+int32_t omni_stack_pop(omni_stack_t* stack) {
+    if (!stack || stack->top == NULL) return 0;
+    omni_stack_node_t* node = stack->top;
+    int32_t value = node->value;
+    stack->top = node->next;
+    free(node);
+    stack->size--;
+    return value;
+}
+
+int32_t omni_stack_peek(omni_stack_t* stack) {
+    if (!stack || stack->top == NULL) return 0;
+    return stack->top->value;
+}
+
+int32_t omni_stack_is_empty(omni_stack_t* stack) {
+    return (!stack || stack->top == NULL) ? 1 : 0;
+}
+
+int32_t omni_stack_size(omni_stack_t* stack) {
+    return stack ? stack->size : 0;
+}
+
+void omni_stack_clear(omni_stack_t* stack) {
+    if (!stack) return;
+    while (stack->top != NULL) {
+        omni_stack_pop(stack);
+    }
+}
+
+// Priority queue implementation (max-heap using array)
+#define OMNI_PQ_MAX_SIZE 1024
+typedef struct omni_priority_queue_node {
+    int32_t element;
+    int32_t priority;
+} omni_priority_queue_node_t;
+
+struct omni_priority_queue {
+    omni_priority_queue_node_t* heap;
+    int32_t size;
+    int32_t capacity;
+};
+
+static void omni_pq_heapify_up(omni_priority_queue_node_t* heap, int32_t index) {
+    while (index > 0) {
+        int32_t parent = (index - 1) / 2;
+        if (heap[parent].priority >= heap[index].priority) break;
+        // Swap
+        omni_priority_queue_node_t temp = heap[parent];
+        heap[parent] = heap[index];
+        heap[index] = temp;
+        index = parent;
+    }
+}
+
+static void omni_pq_heapify_down(omni_priority_queue_node_t* heap, int32_t size, int32_t index) {
+    while (1) {
+        int32_t left = 2 * index + 1;
+        int32_t right = 2 * index + 2;
+        int32_t largest = index;
+        
+        if (left < size && heap[left].priority > heap[largest].priority) {
+            largest = left;
+        }
+        if (right < size && heap[right].priority > heap[largest].priority) {
+            largest = right;
+        }
+        if (largest == index) break;
+        
+        // Swap
+        omni_priority_queue_node_t temp = heap[index];
+        heap[index] = heap[largest];
+        heap[largest] = temp;
+        index = largest;
+    }
+}
+
+omni_priority_queue_t* omni_priority_queue_create() {
+    omni_priority_queue_t* pq = (omni_priority_queue_t*)malloc(sizeof(omni_priority_queue_t));
+    if (!pq) return NULL;
+    pq->capacity = OMNI_PQ_MAX_SIZE;
+    pq->size = 0;
+    pq->heap = (omni_priority_queue_node_t*)malloc(pq->capacity * sizeof(omni_priority_queue_node_t));
+    if (!pq->heap) {
+        free(pq);
+        return NULL;
+    }
+    return pq;
+}
+
+void omni_priority_queue_destroy(omni_priority_queue_t* pq) {
+    if (!pq) return;
+    free(pq->heap);
+    free(pq);
+}
+
+void omni_priority_queue_insert(omni_priority_queue_t* pq, int32_t element, int32_t priority) {
+    if (!pq || pq->size >= pq->capacity) return;
+    pq->heap[pq->size].element = element;
+    pq->heap[pq->size].priority = priority;
+    omni_pq_heapify_up(pq->heap, pq->size);
+    pq->size++;
+}
+
+int32_t omni_priority_queue_extract_max(omni_priority_queue_t* pq) {
+    if (!pq || pq->size == 0) return 0;
+    int32_t max_element = pq->heap[0].element;
+    pq->heap[0] = pq->heap[pq->size - 1];
+    pq->size--;
+    if (pq->size > 0) {
+        omni_pq_heapify_down(pq->heap, pq->size, 0);
+    }
+    return max_element;
+}
+
+int32_t omni_priority_queue_peek(omni_priority_queue_t* pq) {
+    if (!pq || pq->size == 0) return 0;
+    return pq->heap[0].element;
+}
+
+int32_t omni_priority_queue_is_empty(omni_priority_queue_t* pq) {
+    return (!pq || pq->size == 0) ? 1 : 0;
+}
+
+int32_t omni_priority_queue_size(omni_priority_queue_t* pq) {
+    return pq ? pq->size : 0;
+}
+
+// Linked list implementation
+typedef struct omni_linked_list_node {
+    int32_t value;
+    struct omni_linked_list_node* next;
+} omni_linked_list_node_t;
+
+struct omni_linked_list {
+    omni_linked_list_node_t* head;
+    int32_t size;
+};
+
+omni_linked_list_t* omni_linked_list_create() {
+    omni_linked_list_t* ll = (omni_linked_list_t*)malloc(sizeof(omni_linked_list_t));
+    if (!ll) return NULL;
+    ll->head = NULL;
+    ll->size = 0;
+    return ll;
+}
+
+void omni_linked_list_destroy(omni_linked_list_t* ll) {
+    if (!ll) return;
+    omni_linked_list_clear(ll);
+    free(ll);
+}
+
+void omni_linked_list_append(omni_linked_list_t* ll, int32_t element) {
+    if (!ll) return;
+    omni_linked_list_node_t* node = (omni_linked_list_node_t*)malloc(sizeof(omni_linked_list_node_t));
+    if (!node) return;
+    node->value = element;
+    node->next = NULL;
+    
+    if (ll->head == NULL) {
+        ll->head = node;
+    } else {
+        omni_linked_list_node_t* current = ll->head;
+        while (current->next != NULL) {
+            current = current->next;
+        }
+        current->next = node;
+    }
+    ll->size++;
+}
+
+void omni_linked_list_prepend(omni_linked_list_t* ll, int32_t element) {
+    if (!ll) return;
+    omni_linked_list_node_t* node = (omni_linked_list_node_t*)malloc(sizeof(omni_linked_list_node_t));
+    if (!node) return;
+    node->value = element;
+    node->next = ll->head;
+    ll->head = node;
+    ll->size++;
+}
+
+int32_t omni_linked_list_insert(omni_linked_list_t* ll, int32_t index, int32_t element) {
+    if (!ll || index < 0 || index > ll->size) return 0;
+    if (index == 0) {
+        omni_linked_list_prepend(ll, element);
+        return 1;
+    }
+    
+    omni_linked_list_node_t* node = (omni_linked_list_node_t*)malloc(sizeof(omni_linked_list_node_t));
+    if (!node) return 0;
+    node->value = element;
+    
+    omni_linked_list_node_t* current = ll->head;
+    for (int32_t i = 0; i < index - 1; i++) {
+        current = current->next;
+    }
+    node->next = current->next;
+    current->next = node;
+    ll->size++;
+    return 1;
+}
+
+int32_t omni_linked_list_remove(omni_linked_list_t* ll, int32_t index) {
+    if (!ll || index < 0 || index >= ll->size) return 0;
+    
+    omni_linked_list_node_t* to_remove;
+    if (index == 0) {
+        to_remove = ll->head;
+        ll->head = ll->head->next;
+    } else {
+        omni_linked_list_node_t* current = ll->head;
+        for (int32_t i = 0; i < index - 1; i++) {
+            current = current->next;
+        }
+        to_remove = current->next;
+        current->next = to_remove->next;
+    }
+    free(to_remove);
+    ll->size--;
+    return 1;
+}
+
+int32_t omni_linked_list_get(omni_linked_list_t* ll, int32_t index) {
+    if (!ll || index < 0 || index >= ll->size) return 0;
+    omni_linked_list_node_t* current = ll->head;
+    for (int32_t i = 0; i < index; i++) {
+        current = current->next;
+    }
+    return current->value;
+}
+
+int32_t omni_linked_list_set(omni_linked_list_t* ll, int32_t index, int32_t element) {
+    if (!ll || index < 0 || index >= ll->size) return 0;
+    omni_linked_list_node_t* current = ll->head;
+    for (int32_t i = 0; i < index; i++) {
+        current = current->next;
+    }
+    current->value = element;
+    return 1;
+}
+
+int32_t omni_linked_list_size(omni_linked_list_t* ll) {
+    return ll ? ll->size : 0;
+}
+
+int32_t omni_linked_list_is_empty(omni_linked_list_t* ll) {
+    return (!ll || ll->head == NULL) ? 1 : 0;
+}
+
+void omni_linked_list_clear(omni_linked_list_t* ll) {
+    if (!ll) return;
+    while (ll->head != NULL) {
+        omni_linked_list_remove(ll, 0);
+    }
+}
+
+// Binary tree implementation (BST)
+typedef struct omni_binary_tree_node {
+    int32_t value;
+    struct omni_binary_tree_node* left;
+    struct omni_binary_tree_node* right;
+} omni_binary_tree_node_t;
+
+struct omni_binary_tree {
+    omni_binary_tree_node_t* root;
+    int32_t size;
+};
+
+static omni_binary_tree_node_t* omni_bt_node_create(int32_t value) {
+    omni_binary_tree_node_t* node = (omni_binary_tree_node_t*)malloc(sizeof(omni_binary_tree_node_t));
+    if (!node) return NULL;
+    node->value = value;
+    node->left = NULL;
+    node->right = NULL;
+    return node;
+}
+
+static void omni_bt_node_destroy(omni_binary_tree_node_t* node) {
+    if (!node) return;
+    omni_bt_node_destroy(node->left);
+    omni_bt_node_destroy(node->right);
+    free(node);
+}
+
+omni_binary_tree_t* omni_binary_tree_create() {
+    omni_binary_tree_t* bt = (omni_binary_tree_t*)malloc(sizeof(omni_binary_tree_t));
+    if (!bt) return NULL;
+    bt->root = NULL;
+    bt->size = 0;
+    return bt;
+}
+
+void omni_binary_tree_destroy(omni_binary_tree_t* bt) {
+    if (!bt) return;
+    omni_bt_node_destroy(bt->root);
+    free(bt);
+}
+
+static omni_binary_tree_node_t* omni_bt_insert_recursive(omni_binary_tree_node_t* node, int32_t value) {
+    if (node == NULL) {
+        return omni_bt_node_create(value);
+    }
+    if (value < node->value) {
+        node->left = omni_bt_insert_recursive(node->left, value);
+    } else if (value > node->value) {
+        node->right = omni_bt_insert_recursive(node->right, value);
+    }
+    return node;
+}
+
+void omni_binary_tree_insert(omni_binary_tree_t* bt, int32_t element) {
+    if (!bt) return;
+    bt->root = omni_bt_insert_recursive(bt->root, element);
+    bt->size++;
+}
+
+static int32_t omni_bt_search_recursive(omni_binary_tree_node_t* node, int32_t value) {
+    if (node == NULL) return 0;
+    if (value == node->value) return 1;
+    if (value < node->value) {
+        return omni_bt_search_recursive(node->left, value);
+    }
+    return omni_bt_search_recursive(node->right, value);
+}
+
+int32_t omni_binary_tree_search(omni_binary_tree_t* bt, int32_t element) {
+    if (!bt || !bt->root) return 0;
+    return omni_bt_search_recursive(bt->root, element);
+}
+
+static omni_binary_tree_node_t* omni_bt_find_min(omni_binary_tree_node_t* node) {
+    while (node && node->left != NULL) {
+        node = node->left;
+    }
+    return node;
+}
+
+static omni_binary_tree_node_t* omni_bt_remove_recursive(omni_binary_tree_node_t* node, int32_t value) {
+    if (node == NULL) return NULL;
+    
+    if (value < node->value) {
+        node->left = omni_bt_remove_recursive(node->left, value);
+    } else if (value > node->value) {
+        node->right = omni_bt_remove_recursive(node->right, value);
+    } else {
+        if (node->left == NULL) {
+            omni_binary_tree_node_t* temp = node->right;
+            free(node);
+            return temp;
+        } else if (node->right == NULL) {
+            omni_binary_tree_node_t* temp = node->left;
+            free(node);
+            return temp;
+        }
+        
+        omni_binary_tree_node_t* temp = omni_bt_find_min(node->right);
+        node->value = temp->value;
+        node->right = omni_bt_remove_recursive(node->right, temp->value);
+    }
+    return node;
+}
+
+int32_t omni_binary_tree_remove(omni_binary_tree_t* bt, int32_t element) {
+    if (!bt || !bt->root) return 0;
+    if (omni_binary_tree_search(bt, element)) {
+        bt->root = omni_bt_remove_recursive(bt->root, element);
+        bt->size--;
+        return 1;
+    }
+    return 0;
+}
+
+int32_t omni_binary_tree_size(omni_binary_tree_t* bt) {
+    if (!bt) return 0;
+    return bt->size;
+}
+
+int32_t omni_binary_tree_is_empty(omni_binary_tree_t* bt) {
+    return (!bt || bt->root == NULL) ? 1 : 0;
+}
+
+void omni_binary_tree_clear(omni_binary_tree_t* bt) {
+    if (!bt) return;
+    omni_bt_node_destroy(bt->root);
+    bt->root = NULL;
+    bt->size = 0;
+}
+
+// ============================================================================
+// Graph Implementation
+// ============================================================================
+// Graphs are ordinary omni_struct_t values so they flow through generated
+// code like any other struct. Alongside the public "nodes" field, edge i is
+// stored as the int fields "edge.<i>.from", "edge.<i>.to" and
+// "edge.<i>.weight", with the number of edges in "edge_count".
+
+typedef struct {
+    int32_t from;
+    int32_t to;
+    int32_t weight;
+    int32_t index;
+} omni_graph_edge_t;
+
+// omni_graph_load_edges copies the edges of g into a new array in insertion
+// order using a single pass over the struct fields. Caller must free it.
+static omni_graph_edge_t* omni_graph_load_edges(omni_struct_t* g, int32_t* count) {
+    *count = g ? omni_struct_get_int_field(g, "edge_count") : 0;
+    if (*count <= 0) {
+        *count = 0;
+        return NULL;
+    }
+    omni_graph_edge_t* edges = calloc((size_t)*count, sizeof(omni_graph_edge_t));
+    if (!edges) {
+        *count = 0;
+        return NULL;
+    }
+    for (int32_t i = 0; i < *count; i++) {
+        edges[i].index = i;
+    }
+    for (omni_struct_field_t* field = g->fields; field; field = field->next) {
+        int32_t index;
+        char part[8];
+        if (field->value_type != 1 || sscanf(field->name, "edge.%d.%7s", &index, part) != 2) {
+            continue;
+        }
+        if (index < 0 || index >= *count) {
+            continue;
+        }
+        int32_t value = *(int32_t*)field->value;
+        if (strcmp(part, "from") == 0) {
+            edges[index].from = value;
+        } else if (strcmp(part, "to") == 0) {
+            edges[index].to = value;
+        } else if (strcmp(part, "weight") == 0) {
+            edges[index].weight = value;
+        }
+    }
+    return edges;
+}
+
+static void omni_graph_append_edge(omni_struct_t* g, int32_t from, int32_t to, int32_t weight) {
+    int32_t index = omni_struct_get_int_field(g, "edge_count");
+    char name[48];
+    snprintf(name, sizeof(name), "edge.%d.from", index);
+    omni_struct_set_int_field(g, name, from);
+    snprintf(name, sizeof(name), "edge.%d.to", index);
+    omni_struct_set_int_field(g, name, to);
+    snprintf(name, sizeof(name), "edge.%d.weight", index);
+    omni_struct_set_int_field(g, name, weight);
+    omni_struct_set_int_field(g, "edge_count", index + 1);
+}
+
+omni_struct_t* omni_graph_create(int32_t nodes) {
+    omni_struct_t* g = omni_struct_create();
+    if (!g) return NULL;
+    omni_struct_set_int_field(g, "nodes", nodes < 0 ? 0 : nodes);
+    omni_struct_set_int_field(g, "edge_count", 0);
+    return g;
+}
+
+int32_t omni_graph_add_edge(omni_struct_t* g, int32_t from, int32_t to, int32_t weight) {
+    if (!g) return 0;
+    int32_t nodes = omni_struct_get_int_field(g, "nodes");
+    if (from < 0 || from >= nodes || to < 0 || to >= nodes) {
+        return 0;
+    }
+    omni_graph_append_edge(g, from, to, weight);
+    return 1;
+}
+
+int32_t omni_graph_has_edge(omni_struct_t* g, int32_t from, int32_t to) {
+    int32_t count;
+    omni_graph_edge_t* edges = omni_graph_load_edges(g, &count);
+    int32_t found = 0;
+    for (int32_t i = 0; i < count && !found; i++) {
+        found = edges[i].from == from && edges[i].to == to;
+    }
+    free(edges);
+    return found;
+}
+
+int32_t omni_graph_node_count(omni_struct_t* g) {
+    return g ? omni_struct_get_int_field(g, "nodes") : 0;
+}
+
+int32_t omni_graph_edge_count(omni_struct_t* g) {
+    return g ? omni_struct_get_int_field(g, "edge_count") : 0;
+}
+
+int32_t omni_graph_total_weight(omni_struct_t* g) {
+    int32_t count;
+    omni_graph_edge_t* edges = omni_graph_load_edges(g, &count);
+    int32_t total = 0;
+    for (int32_t i = 0; i < count; i++) {
+        total += edges[i].weight;
+    }
+    free(edges);
+    return total;
+}
+
+// Orders edges by weight, breaking ties by insertion order so that the
+// spanning tree matches the VM's stable sort.
+static int omni_graph_edge_compare(const void* a, const void* b) {
+    const omni_graph_edge_t* ea = (const omni_graph_edge_t*)a;
+    const omni_graph_edge_t* eb = (const omni_graph_edge_t*)b;
+    if (ea->weight != eb->weight) {
+        return ea->weight < eb->weight ? -1 : 1;
+    }
+    return ea->index - eb->index;
+}
+
+static int32_t omni_graph_find(int32_t* parent, int32_t x) {
+    while (parent[x] != x) {
+        parent[x] = parent[parent[x]];
+        x = parent[x];
+    }
+    return x;
+}
+
+// Kruskal's algorithm; edges are treated as undirected.
+omni_struct_t* omni_graph_mst(omni_struct_t* g) {
+    int32_t nodes = omni_graph_node_count(g);
+    omni_struct_t* tree = omni_graph_create(nodes);
+    if (!tree) return NULL;
+
+    int32_t count;
+    omni_graph_edge_t* edges = omni_graph_load_edges(g, &count);
+    int32_t* parent = malloc(sizeof(int32_t) * (size_t)(nodes > 0 ? nodes : 1));
+    if (!parent) {
+        free(edges);
+        return tree;
+    }
+    for (int32_t i = 0; i < nodes; i++) {
+        parent[i] = i;
+    }
+
+    qsort(edges, (size_t)count, sizeof(omni_graph_edge_t), omni_graph_edge_compare);
+    int32_t added = 0;
+    for (int32_t i = 0; i < count && added < nodes - 1; i++) {
+        int32_t a = omni_graph_find(parent, edges[i].from);
+        int32_t b = omni_graph_find(parent, edges[i].to);
+        if (a == b) {
+            continue;
+        }
+        parent[a] = b;
+        omni_graph_append_edge(tree, edges[i].from, edges[i].to, edges[i].weight);
+        added++;
+    }
+
+    free(parent);
+    free(edges);
+    return tree;
+}
+
+// omni_graph_adjacency builds a compressed adjacency list: the successors of
+// node n are targets[offsets[n]] .. targets[offsets[n+1]-1].
+static void omni_graph_adjacency(omni_graph_edge_t* edges, int32_t count, int32_t nodes,
+                                 int32_t* offsets, int32_t* targets) {
+    memset(offsets, 0, sizeof(int32_t) * (size_t)(nodes + 1));
+    for (int32_t i = 0; i < count; i++) {
+        offsets[edges[i].from + 1]++;
+    }
+    for (int32_t n = 0; n < nodes; n++) {
+        offsets[n + 1] += offsets[n];
+    }
+    int32_t* fill = malloc(sizeof(int32_t) * (size_t)(nodes + 1));
+    memcpy(fill, offsets, sizeof(int32_t) * (size_t)(nodes + 1));
+    for (int32_t i = 0; i < count; i++) {
+        targets[fill[edges[i].from]++] = edges[i].to;
+    }
+    free(fill);
+}
+
+// Kahn's algorithm, taking the lowest-numbered ready node first. Returns a
+// newly allocated array of omni_graph_node_count(g) nodes, or NULL if g has
+// a cycle.
+int32_t* omni_graph_toposort(omni_struct_t* g) {
+    int32_t nodes = omni_graph_node_count(g);
+    int32_t count;
+    omni_graph_edge_t* edges = omni_graph_load_edges(g, &count);
+    int32_t* offsets = malloc(sizeof(int32_t) * (size_t)(nodes + 1));
+    int32_t* targets = malloc(sizeof(int32_t) * (size_t)(count > 0 ? count : 1));
+    int32_t* indegree = calloc((size_t)(nodes > 0 ? nodes : 1), sizeof(int32_t));
+    int32_t* order = malloc(sizeof(int32_t) * (size_t)(nodes > 0 ? nodes : 1));
+    if (!offsets || !targets || !indegree || !order) {
+        free(edges);
+        free(offsets);
+        free(targets);
+        free(indegree);
+        free(order);
+        return NULL;
+    }
+    omni_graph_adjacency(edges, count, nodes, offsets, targets);
+    for (int32_t i = 0; i < count; i++) {
+        indegree[edges[i].to]++;
+    }
+
+    int32_t emitted = 0;
+    for (; emitted < nodes; emitted++) {
+        int32_t next = -1;
+        for (int32_t n = 0; n < nodes; n++) {
+            if (indegree[n] == 0) {
+                next = n;
+                break;
+            }
+        }
+        if (next < 0) {
+            break;
+        }
+        indegree[next] = -1;
+        order[emitted] = next;
+        for (int32_t j = offsets[next]; j < offsets[next + 1]; j++) {
+            indegree[targets[j]]--;
+        }
+    }
+
+    free(edges);
+    free(offsets);
+    free(targets);
+    free(indegree);
+    if (emitted != nodes) {
+        free(order);
+        return NULL;
+    }
+    return order;
+}
+
+typedef struct {
+    int32_t* offsets;
+    int32_t* targets;
+    int32_t* index;
+    int32_t* lowlink;
+    int32_t* on_stack;
+    int32_t* stack;
+    int32_t stack_size;
+    int32_t next_index;
+    int32_t** components;
+    int32_t component_count;
+} omni_graph_tarjan_t;
+
+static int omni_graph_int_compare(const void* a, const void* b) {
+    return *(const int32_t*)a - *(const int32_t*)b;
+}
+
+static void omni_graph_strongconnect(omni_graph_tarjan_t* t, int32_t v) {
+    t->index[v] = t->next_index;
+    t->lowlink[v] = t->next_index;
+    t->next_index++;
+    t->stack[t->stack_size++] = v;
+    t->on_stack[v] = 1;
+
+    for (int32_t j = t->offsets[v]; j < t->offsets[v + 1]; j++) {
+        int32_t w = t->targets[j];
+        if (t->index[w] < 0) {
+            omni_graph_strongconnect(t, w);
+            if (t->lowlink[w] < t->lowlink[v]) t->lowlink[v] = t->lowlink[w];
+        } else if (t->on_stack[w]) {
+            if (t->index[w] < t->lowlink[v]) t->lowlink[v] = t->index[w];
+        }
+    }
+
+    if (t->lowlink[v] != t->index[v]) {
+        return;
+    }
+    int32_t start = t->stack_size;
+    do {
+        start--;
+        t->on_stack[t->stack[start]] = 0;
+    } while (t->stack[start] != v);
+
+    int32_t size = t->stack_size - start;
+    int32_t* component = malloc(sizeof(int32_t) * (size_t)(size + 1));
+    if (component) {
+        memcpy(component, t->stack + start, sizeof(int32_t) * (size_t)size);
+        qsort(component, (size_t)size, sizeof(int32_t), omni_graph_int_compare);
+        component[size] = -1;
+        t->components[t->component_count++] = component;
+    }
+    t->stack_size = start;
+}
+
+// Tarjan's algorithm. Returns a NULL-terminated array of components in the
+// order they complete (reverse topological order). Each component lists its
+// nodes in ascending order and is terminated by -1.
+int32_t** omni_graph_scc(omni_struct_t* g) {
+    int32_t nodes = omni_graph_node_count(g);
+    int32_t count;
+    omni_graph_edge_t* edges = omni_graph_load_edges(g, &count);
+    size_t slots = (size_t)(nodes > 0 ? nodes : 1);
+
+    omni_graph_tarjan_t t = {0};
+    t.offsets = malloc(sizeof(int32_t) * (size_t)(nodes + 1));
+    t.targets = malloc(sizeof(int32_t) * (size_t)(count > 0 ? count : 1));
+    t.index = malloc(sizeof(int32_t) * slots);
+    t.lowlink = malloc(sizeof(int32_t) * slots);
+    t.on_stack = calloc(slots, sizeof(int32_t));
+    t.stack = malloc(sizeof(int32_t) * slots);
+    t.components = calloc(slots + 1, sizeof(int32_t*));
+
+    if (t.offsets && t.targets && t.index && t.lowlink && t.on_stack && t.stack && t.components) {
+        omni_graph_adjacency(edges, count, nodes, t.offsets, t.targets);
+        for (int32_t n = 0; n < nodes; n++) {
+            t.index[n] = -1;
+        }
+        for (int32_t n = 0; n < nodes; n++) {
+            if (t.index[n] < 0) {
+                omni_graph_strongconnect(&t, n);
+            }
+        }
+    }
+
+    free(edges);
+    free(t.offsets);
+    free(t.targets);
+    free(t.index);
+    free(t.lowlink);
+    free(t.on_stack);
+    free(t.stack);
+    return t.components;
+}
+
+// ============================================================================
+// Deque Implementation
+// ============================================================================
+// Every element has its own node, so both ends grow and shrink in constant
+// time. Strings are not copied; a deque holds the pointers it was given.
+
+struct omni_deque_node {
+    omni_deque_value value;
+    omni_deque_node* prev;
+    omni_deque_node* next;
+};
+
+omni_deque_t* omni_deque_create(void) {
+    return calloc(1, sizeof(omni_deque_t));
+}
+
+static omni_deque_node* omni_deque_new_node(omni_deque_value value) {
+    omni_deque_node* node = calloc(1, sizeof(omni_deque_node));
+    if (!node) {
+        omni_panic("deque: out of memory");
+    }
+    node->value = value;
+    return node;
+}
+
+void omni_deque_push_front(omni_deque_t* d, omni_deque_value value) {
+    if (!d) return;
+    omni_deque_node* node = omni_deque_new_node(value);
+    node->next = d->front;
+    if (d->front) {
+        d->front->prev = node;
+    } else {
+        d->back = node;
+    }
+    d->front = node;
+    d->size++;
+}
+
+void omni_deque_push_back(omni_deque_t* d, omni_deque_value value) {
+    if (!d) return;
+    omni_deque_node* node = omni_deque_new_node(value);
+    node->prev = d->back;
+    if (d->back) {
+        d->back->next = node;
+    } else {
+        d->front = node;
+    }
+    d->back = node;
+    d->size++;
+}
+
+// omni_deque_end returns the front or back node of d, exiting when there is
+// none; name is the function reported in the error.
+static omni_deque_node* omni_deque_end(omni_deque_t* d, int front, const char* name) {
+    omni_deque_node* node = d ? (front ? d->front : d->back) : NULL;
+    if (!node) {
+        char message[64];
+        snprintf(message, sizeof(message), "collections.deque.%s: deque is empty", name);
+        omni_panic(message);
+    }
+    return node;
+}
+
+static omni_deque_value omni_deque_pop(omni_deque_t* d, int front, const char* name) {
+    omni_deque_node* node = omni_deque_end(d, front, name);
+    if (front) {
+        d->front = node->next;
+        if (d->front) {
+            d->front->prev = NULL;
+        } else {
+            d->back = NULL;
+        }
+    } else {
+        d->back = node->prev;
+        if (d->back) {
+            d->back->next = NULL;
+        } else {
+            d->front = NULL;
+        }
+    }
+    d->size--;
+    omni_deque_value value = node->value;
+    free(node);
+    return value;
+}
+
+omni_deque_value omni_deque_pop_front(omni_deque_t* d) {
+    return omni_deque_pop(d, 1, "pop_front");
+}
+
+omni_deque_value omni_deque_pop_back(omni_deque_t* d) {
+    return omni_deque_pop(d, 0, "pop_back");
+}
+
+omni_deque_value omni_deque_peek_front(omni_deque_t* d) {
+    return omni_deque_end(d, 1, "peek_front")->value;
+}
+
+omni_deque_value omni_deque_peek_back(omni_deque_t* d) {
+    return omni_deque_end(d, 0, "peek_back")->value;
+}
+
+int32_t omni_deque_size(omni_deque_t* d) {
+    return d ? d->size : 0;
+}
+
+int32_t omni_deque_is_empty(omni_deque_t* d) {
+    return omni_deque_size(d) == 0;
+}
+
+// ============================================================================
+// Trie Implementation
+// ============================================================================
+// A node has a child for every byte that continues its prefix, kept in a
+// list sorted by byte, so that walking the children in order lists keys in
+// byte order. UTF-8 keeps that the order of the characters the VM uses.
+// Strings are not copied; a trie holds the value pointers it was given.
+
+typedef struct omni_trie_node omni_trie_node;
+struct omni_trie_node {
+    unsigned char byte;
+    int32_t has_value;
+    omni_trie_value value;
+    omni_trie_node* child;
+    omni_trie_node* sibling;
+};
+
+struct omni_trie {
+    omni_trie_node root;
+};
+
+omni_trie_t* omni_trie_create(void) {
+    return calloc(1, sizeof(omni_trie_t));
+}
+
+// omni_trie_find returns the node that prefix leads to, or NULL if no key
+// starts with prefix.
+static omni_trie_node* omni_trie_find(omni_trie_t* t, const char* prefix) {
+    if (!t || !prefix) return NULL;
+    omni_trie_node* node = &t->root;
+    for (const unsigned char* p = (const unsigned char*)prefix; *p && node; p++) {
+        omni_trie_node* child = node->child;
+        while (child && child->byte < *p) {
+            child = child->sibling;
+        }
+        node = child && child->byte == *p ? child : NULL;
+    }
+    return node;
+}
+
+void omni_trie_insert(omni_trie_t* t, const char* key, omni_trie_value value) {
+    if (!t || !key) return;
+    omni_trie_node* node = &t->root;
+    for (const unsigned char* p = (const unsigned char*)key; *p; p++) {
+        omni_trie_node** link = &node->child;
+        while (*link && (*link)->byte < *p) {
+            link = &(*link)->sibling;
+        }
+        if (!*link || (*link)->byte != *p) {
+            omni_trie_node* child = calloc(1, sizeof(omni_trie_node));
+            if (!child) {
+                omni_panic("trie: out of memory");
+            }
+            child->byte = *p;
+            child->sibling = *link;
+            *link = child;
+        }
+        node = *link;
+    }
+    node->value = value;
+    node->has_value = 1;
+}
+
+int32_t omni_trie_search(omni_trie_t* t, const char* key, omni_trie_value* out) {
+    omni_trie_node* node = omni_trie_find(t, key);
+    if (!node || !node->has_value) {
+        return 0;
+    }
+    *out = node->value;
+    return 1;
+}
+
+int32_t omni_trie_starts_with(omni_trie_t* t, const char* prefix) {
+    omni_trie_node* node = omni_trie_find(t, prefix);
+    return node && (node->has_value || node->child);
+}
+
+// omni_trie_keys is the state of a walk that collects keys: the key of the
+// node being visited and the keys found so far.
+typedef struct {
+    char* key;
+    size_t len;
+    size_t key_cap;
+    char** keys;
+    int32_t count;
+    int32_t keys_cap;
+} omni_trie_keys;
+
+static void omni_trie_collect(omni_trie_node* node, omni_trie_keys* k) {
+    if (node->has_value) {
+        if (k->count == k->keys_cap) {
+            k->keys_cap = k->keys_cap ? k->keys_cap * 2 : 8;
+            k->keys = realloc(k->keys, (size_t)k->keys_cap * sizeof(char*));
+            if (!k->keys) {
+                omni_panic("trie: out of memory");
+            }
+        }
+        char* key = malloc(k->len + 1);
+        if (!key) {
+            omni_panic("trie: out of memory");
+        }
+        memcpy(key, k->key, k->len);
+        key[k->len] = '\0';
+        k->keys[k->count++] = key;
+    }
+    for (omni_trie_node* child = node->child; child; child = child->sibling) {
+        if (k->len + 1 >= k->key_cap) {
+            k->key_cap *= 2;
+            k->key = realloc(k->key, k->key_cap);
+            if (!k->key) {
+                omni_panic("trie: out of memory");
+            }
+        }
+        k->key[k->len++] = (char)child->byte;
+        omni_trie_collect(child, k);
+        k->len--;
+    }
+}
+
+char** omni_trie_keys_with_prefix(omni_trie_t* t, const char* prefix, int32_t* count_out) {
+    if (count_out) {
+        *count_out = 0;
+    }
+    omni_trie_node* node = omni_trie_find(t, prefix);
+    if (!node) {
+        return NULL;
+    }
+    omni_trie_keys k = {0};
+    k.len = strlen(prefix);
+    k.key_cap = k.len + 16;
+    k.key = malloc(k.key_cap);
+    if (!k.key) {
+        omni_panic("trie: out of memory");
+    }
+    memcpy(k.key, prefix, k.len);
+    omni_trie_collect(node, &k);
+    free(k.key);
+    if (count_out) {
+        *count_out = k.count;
+    }
+    return k.keys;
+}
+
+// ============================================================================
+// Ordered Map Implementation
+// ============================================================================
+// Every entry sits in a hash bucket, to be found by key, and in a doubly
+// linked list, which keeps insertion order and lets an entry be unlinked in
+// constant time. The table doubles when it holds more entries than buckets.
+// Strings are not copied; a map holds the pointers it was given.
+
+omni_ordered_map_t* omni_ordered_map_create(int32_t string_keys) {
+    omni_ordered_map_t* m = calloc(1, sizeof(omni_ordered_map_t));
+    if (!m) {
+        omni_panic("ordered_map: out of memory");
+    }
+    m->string_keys = string_keys;
+    m->bucket_count = 16;
+    m->buckets = calloc((size_t)m->bucket_count, sizeof(omni_ordered_map_entry*));
+    if (!m->buckets) {
+        omni_panic("ordered_map: out of memory");
+    }
+    return m;
+}
+
+static uint32_t omni_ordered_map_hash(omni_ordered_map_t* m, omni_ordered_map_value key) {
+    if (!m->string_keys) {
+        return (uint32_t)key.i * 2654435761u;
+    }
+    // FNV-1a
+    uint32_t hash = 2166136261u;
+    for (const unsigned char* p = (const unsigned char*)(key.s ? key.s : ""); *p; p++) {
+        hash = (hash ^ *p) * 16777619u;
+    }
+    return hash;
+}
+
+static int omni_ordered_map_equal(omni_ordered_map_t* m, omni_ordered_map_value a, omni_ordered_map_value b) {
+    if (!m->string_keys) {
+        return a.i == b.i;
+    }
+    return strcmp(a.s ? a.s : "", b.s ? b.s : "") == 0;
+}
+
+// omni_ordered_map_slot returns the link that points to the entry of key in
+// its bucket, or the link at the end of the bucket when m does not hold key.
+static omni_ordered_map_entry** omni_ordered_map_slot(omni_ordered_map_t* m, omni_ordered_map_value key) {
+    omni_ordered_map_entry** link = &m->buckets[omni_ordered_map_hash(m, key) % (uint32_t)m->bucket_count];
+    while (*link && !omni_ordered_map_equal(m, (*link)->key, key)) {
+        link = &(*link)->bucket_next;
+    }
+    return link;
+}
+
+static void omni_ordered_map_grow(omni_ordered_map_t* m) {
+    int32_t count = m->bucket_count * 2;
+    omni_ordered_map_entry** buckets = calloc((size_t)count, sizeof(omni_ordered_map_entry*));
+    if (!buckets) {
+        omni_panic("ordered_map: out of memory");
+    }
+    free(m->buckets);
+    m->buckets = buckets;
+    m->bucket_count = count;
+    for (omni_ordered_map_entry* e = m->first; e; e = e->next) {
+        omni_ordered_map_entry** bucket = &buckets[omni_ordered_map_hash(m, e->key) % (uint32_t)count];
+        e->bucket_next = *bucket;
+        *bucket = e;
+    }
+}
+
+void omni_ordered_map_put(omni_ordered_map_t* m, omni_ordered_map_value key, omni_ordered_map_value value) {
+    if (!m) return;
+    omni_ordered_map_entry** link = omni_ordered_map_slot(m, key);
+    if (*link) {
+        (*link)->value = value;
+        return;
+    }
+    omni_ordered_map_entry* e = calloc(1, sizeof(omni_ordered_map_entry));
+    if (!e) {
+        omni_panic("ordered_map: out of memory");
+    }
+    e->key = key;
+    e->value = value;
+    *link = e;
+    e->prev = m->last;
+    if (m->last) {
+        m->last->next = e;
+    } else {
+        m->first = e;
+    }
+    m->last = e;
+    m->size++;
+    if (m->size > m->bucket_count) {
+        omni_ordered_map_grow(m);
+    }
+}
+
+int32_t omni_ordered_map_get(omni_ordered_map_t* m, omni_ordered_map_value key, omni_ordered_map_value* out) {
+    omni_ordered_map_entry* e = m ? *omni_ordered_map_slot(m, key) : NULL;
+    if (!e) {
+        return 0;
+    }
+    *out = e->value;
+    return 1;
+}
+
+int32_t omni_ordered_map_remove(omni_ordered_map_t* m, omni_ordered_map_value key) {
+    if (!m) return 0;
+    omni_ordered_map_entry** link = omni_ordered_map_slot(m, key);
+    omni_ordered_map_entry* e = *link;
+    if (!e) {
+        return 0;
+    }
+    *link = e->bucket_next;
+    if (e->prev) {
+        e->prev->next = e->next;
+    } else {
+        m->first = e->next;
+    }
+    if (e->next) {
+        e->next->prev = e->prev;
+    } else {
+        m->last = e->prev;
+    }
+    m->size--;
+    free(e);
+    return 1;
+}
+
+// ============================================================================
+// Interpolation Implementation
+// ============================================================================
+// Splines are omni_struct_t values with the public "points" field. Segment i
+// is stored as the float fields "x.<i>", "a.<i>", "b.<i>", "c.<i>" and
+// "d.<i>": on [x.i, x.i+1] the spline is a + b*t + c*t^2 + d*t^3 with
+// t = x - x.i.
+
+double omni_interp_linear(double x0, double y0, double x1, double y1, double x) {
+    if (x1 == x0) {
+        return y0;
+    }
+    return y0 + (y1 - y0) * (x - x0) / (x1 - x0);
+}
+
+double omni_interp_lerp(double a, double b, double t) {
+    return a + (b - a) * t;
+}
+
+double omni_interp_smooth_step(double t) {
+    if (t <= 0.0) return 0.0;
+    if (t >= 1.0) return 1.0;
+    return t * t * (3.0 - 2.0 * t);
+}
+
+static double omni_spline_get(omni_struct_t* s, char coeff, int32_t i) {
+    char name[32];
+    snprintf(name, sizeof(name), "%c.%d", coeff, i);
+    return omni_struct_get_float_field(s, name);
+}
+
+static void omni_spline_set(omni_struct_t* s, char coeff, int32_t i, double value) {
+    char name[32];
+    snprintf(name, sizeof(name), "%c.%d", coeff, i);
+    omni_struct_set_float_field(s, name, value);
+}
+
+omni_struct_t* omni_interp_cubic_spline(const double* xs, const double* ys, int32_t n) {
+    omni_struct_t* s = omni_struct_create();
+    if (!s) return NULL;
+    omni_struct_set_int_field(s, "points", 0);
+    if (!xs || !ys || n <= 0) {
+        return s;
+    }
+    for (int32_t i = 1; i < n; i++) {
+        if (xs[i] <= xs[i - 1]) {
+            return s;
+        }
+    }
+
+    // Natural boundary conditions: c[0] = c[n-1] = 0. Solve the tridiagonal
+    // system for c with the Thomas algorithm.
+    double* c = calloc((size_t)n, sizeof(double));
+    double* mu = calloc((size_t)n, sizeof(double));
+    double* z = calloc((size_t)n, sizeof(double));
+    if (!c || !mu || !z) {
+        free(c);
+        free(mu);
+        free(z);
+        return s;
+    }
+    for (int32_t i = 1; i < n - 1; i++) {
+        double h0 = xs[i] - xs[i - 1];
+        double h1 = xs[i + 1] - xs[i];
+        double alpha = 3.0 / h1 * (ys[i + 1] - ys[i]) - 3.0 / h0 * (ys[i] - ys[i - 1]);
+        double l = 2.0 * (xs[i + 1] - xs[i - 1]) - h0 * mu[i - 1];
+        mu[i] = h1 / l;
+        z[i] = (alpha - h0 * z[i - 1]) / l;
+    }
+    for (int32_t i = n - 2; i >= 0; i--) {
+        c[i] = z[i] - mu[i] * c[i + 1];
+    }
+
+    for (int32_t i = 0; i < n; i++) {
+        double b = 0.0;
+        double d = 0.0;
+        if (i < n - 1) {
+            double h = xs[i + 1] - xs[i];
+            b = (ys[i + 1] - ys[i]) / h - h * (c[i + 1] + 2.0 * c[i]) / 3.0;
+            d = (c[i + 1] - c[i]) / (3.0 * h);
+        }
+        omni_spline_set(s, 'x', i, xs[i]);
+        omni_spline_set(s, 'a', i, ys[i]);
+        omni_spline_set(s, 'b', i, b);
+        omni_spline_set(s, 'c', i, c[i]);
+        omni_spline_set(s, 'd', i, d);
+    }
+    omni_struct_set_int_field(s, "points", n);
+    free(c);
+    free(mu);
+    free(z);
+    return s;
+}
+
+double omni_interp_eval(omni_struct_t* s, double x) {
+    int32_t n = s ? omni_struct_get_int_field(s, "points") : 0;
+    if (n <= 0) return 0.0;
+    if (n == 1) return omni_spline_get(s, 'a', 0);
+
+    // Find the last segment start at or below x, clamped to a real segment
+    // so points outside the range extrapolate the end segments.
+    int32_t lo = 0;
+    int32_t hi = n - 2;
+    while (lo < hi) {
+        int32_t mid = lo + (hi - lo + 1) / 2;
+        if (omni_spline_get(s, 'x', mid) <= x) {
+            lo = mid;
+        } else {
+            hi = mid - 1;
+        }
+    }
+    double t = x - omni_spline_get(s, 'x', lo);
+    return omni_spline_get(s, 'a', lo) +
+           t * (omni_spline_get(s, 'b', lo) + t * (omni_spline_get(s, 'c', lo) + t * omni_spline_get(s, 'd', lo)));
+}
+
+// ============================================================================
+// Command History Implementation
+// ============================================================================
+// std.io.readline_history keeps a process-wide list of commands, oldest
+// first. The history file holds one command per line.
+
+#define OMNI_HISTORY_DEFAULT_MAX 1000
+
+static char** omni_history_entries = NULL;
+static int32_t omni_history_len = 0;
+static int32_t omni_history_max = OMNI_HISTORY_DEFAULT_MAX;
+
+// omni_history_trim drops the oldest entries beyond the limit.
+static void omni_history_trim(void) {
+    int32_t extra = omni_history_len - omni_history_max;
+    if (extra <= 0) {
+        return;
+    }
+    for (int32_t i = 0; i < extra; i++) {
+        free(omni_history_entries[i]);
+    }
+    memmove(omni_history_entries, omni_history_entries + extra,
+            (size_t)(omni_history_len - extra) * sizeof(char*));
+    omni_history_len -= extra;
+}
+
+static void omni_history_clear(void) {
+    for (int32_t i = 0; i < omni_history_len; i++) {
+        free(omni_history_entries[i]);
+    }
+    free(omni_history_entries);
+    omni_history_entries = NULL;
+    omni_history_len = 0;
+}
+
+// omni_history_push takes ownership of entry.
+static void omni_history_push(char* entry) {
+    char** grown = realloc(omni_history_entries, (size_t)(omni_history_len + 1) * sizeof(char*));
+    if (!grown) {
+        free(entry);
+        return;
+    }
+    omni_history_entries = grown;
+    omni_history_entries[omni_history_len++] = entry;
+    omni_history_trim();
+}
+
+static int omni_history_is_blank(const char* line) {
+    for (; *line; line++) {
+        if (!isspace((unsigned char)*line)) {
+            return 0;
+        }
+    }
+    return 1;
+}
+
+void omni_history_add(const char* line) {
+    if (!line || omni_history_is_blank(line)) {
+        return;
+    }
+    char* entry = strdup(line);
+    if (!entry) {
+        return;
+    }
+    for (char* p = entry; *p; p++) {
+        if (*p == '\n' || *p == '\r') {
+            *p = ' ';
+        }
+    }
+    omni_history_push(entry);
+}
+
+void omni_history_load(const char* path) {
+    omni_history_clear();
+    FILE* file = path ? fopen(path, "r") : NULL;
+    if (!file) {
+        return;
+    }
+    size_t cap = 128;
+    size_t len = 0;
+    char* line = malloc(cap);
+    int c;
+    while (line) {
+        c = fgetc(file);
+        if (c == EOF || c == '\n') {
+            if (len > 0 && line[len - 1] == '\r') {
+                len--;
+            }
+            line[len] = '\0';
+            if (!omni_history_is_blank(line)) {
+                omni_history_push(strdup(line));
+            }
+            len = 0;
+            if (c == EOF) {
+                break;
+            }
+            continue;
+        }
+        if (len + 1 >= cap) {
+            char* grown = realloc(line, cap * 2);
+            if (!grown) {
+                break;
+            }
+            line = grown;
+            cap *= 2;
+        }
+        line[len++] = (char)c;
+    }
+    free(line);
+    fclose(file);
+}
+
+void omni_history_save(const char* path) {
+    FILE* file = path ? fopen(path, "w") : NULL;
+    if (!file) {
+        return;
+    }
+    for (int32_t i = 0; i < omni_history_len; i++) {
+        fputs(omni_history_entries[i], file);
+        fputc('\n', file);
+    }
+    fclose(file);
+}
+
+const char* omni_history_get(int32_t index) {
+    if (index < 0 || index >= omni_history_len) {
+        return NULL;
+    }
+    return omni_history_entries[index];
+}
+
+int32_t omni_history_count(void) {
+    return omni_history_len;
+}
+
+void omni_history_set_max_entries(int32_t limit) {
+    omni_history_max = limit > 0 ? limit : OMNI_HISTORY_DEFAULT_MAX;
+    omni_history_trim();
+}
+
+// ============================================================================
+// Diff Implementation
+// ============================================================================
+
+// A line of text. len includes the trailing newline when present so that a
+// missing final newline survives a diff/apply round trip.
+typedef struct {
+    const char* text;
+    size_t len;
+} omni_diff_line_t;
+
+typedef struct {
+    char kind;  // ' ' unchanged, '-' removed, '+' added
+    const omni_diff_line_t* line;
+    int32_t old_pos;
+    int32_t new_pos;
+} omni_diff_op_t;
+
+typedef struct {
+    char* data;
+    size_t len;
+    size_t cap;
+} omni_diff_buf_t;
+
+static const char omni_diff_no_newline[] = "\\ No newline at end of file\n";
+
+static int omni_diff_buf_append(omni_diff_buf_t* buf, const char* text, size_t len) {
+    if (buf->len + len + 1 > buf->cap) {
+        size_t new_cap = buf->cap ? buf->cap * 2 : 256;
+        while (new_cap < buf->len + len + 1) {
+            new_cap *= 2;
+        }
+        char* new_data = realloc(buf->data, new_cap);
+        if (!new_data) {
+            return 0;
+        }
+        buf->data = new_data;
+        buf->cap = new_cap;
+    }
+    memcpy(buf->data + buf->len, text, len);
+    buf->len += len;
+    buf->data[buf->len] = '\0';
+    return 1;
+}
+
+static char* omni_diff_buf_finish(omni_diff_buf_t* buf) {
+    if (!buf->data) {
+        return strdup("");
+    }
+    return buf->data;
+}
+
+static omni_diff_line_t* omni_diff_split_lines(const char* str, int32_t* count) {
+    *count = 0;
+    if (!str || !*str) {
+        return NULL;
+    }
+    int32_t n = 0;
+    for (const char* p = str; *p; p++) {
+        if (*p == '\n') n++;
+    }
+    if (str[strlen(str) - 1] != '\n') n++;
+
+    omni_diff_line_t* lines = malloc(sizeof(omni_diff_line_t) * n);
+    if (!lines) {
+        return NULL;
+    }
+    const char* start = str;
+    int32_t i = 0;
+    while (*start) {
+        const char* nl = strchr(start, '\n');
+        size_t len = nl ? (size_t)(nl - start + 1) : strlen(start);
+        lines[i].text = start;
+        lines[i].len = len;
+        i++;
+        start += len;
+    }
+    *count = n;
+    return lines;
+}
+
+static int omni_diff_line_equal(const omni_diff_line_t* a, const omni_diff_line_t* b) {
+    return a->len == b->len && memcmp(a->text, b->text, a->len) == 0;
+}
+
+// omni_diff_myers computes the shortest edit script between a and b using
+// Myers' O(ND) algorithm. Returns the ops in order with their count in *count.
+static omni_diff_op_t* omni_diff_myers(const omni_diff_line_t* a, int32_t n,
+                                       const omni_diff_line_t* b, int32_t m,
+                                       int32_t* count) {
+    int32_t max_d = n + m;
+    int32_t offset = max_d;
+    size_t width = (size_t)(2 * max_d + 2);
+    int32_t* v = calloc(width, sizeof(int32_t));
+    int32_t** trace = calloc((size_t)max_d + 1, sizeof(int32_t*));
+    omni_diff_op_t* ops = malloc(sizeof(omni_diff_op_t) * (size_t)(max_d > 0 ? max_d : 1));
+    *count = 0;
+    if (!v || !trace || !ops) {
+        free(v);
+        free(trace);
+        free(ops);
+        return NULL;
+    }
+
+    int32_t steps = 0;
+    int found = 0;
+    for (int32_t d = 0; d <= max_d && !found; d++) {
+        trace[d] = malloc(width * sizeof(int32_t));
+        if (!trace[d]) {
+            break;
+        }
+        memcpy(trace[d], v, width * sizeof(int32_t));
+        steps = d + 1;
+        for (int32_t k = -d; k <= d; k += 2) {
+            int32_t x;
+            if (k == -d || (k != d && v[offset + k - 1] < v[offset + k + 1])) {
+                x = v[offset + k + 1];
+            } else {
+                x = v[offset + k - 1] + 1;
+            }
+            int32_t y = x - k;
+            while (x < n && y < m && omni_diff_line_equal(&a[x], &b[y])) {
+                x++;
+                y++;
+            }
+            v[offset + k] = x;
+            if (x >= n && y >= m) {
+                found = 1;
+                break;
+            }
+        }
+    }
+
+    // Walk the trace backwards, emitting ops in reverse order.
+    int32_t x = n, y = m;
+    for (int32_t d = steps - 1; d >= 0 && found; d--) {
+        int32_t* snap = trace[d];
+        int32_t k = x - y;
+        int32_t prev_k;
+        if (k == -d || (k != d && snap[offset + k - 1] < snap[offset + k + 1])) {
+            prev_k = k + 1;
+        } else {
+            prev_k = k - 1;
+        }
+        int32_t prev_x = snap[offset + prev_k];
+        int32_t prev_y = prev_x - prev_k;
+        while (x > prev_x && y > prev_y) {
+            x--;
+            y--;
+            ops[*count] = (omni_diff_op_t){' ', &a[x], x, y};
+            (*count)++;
+        }
+        if (d > 0) {
+            if (x == prev_x) {
+                ops[*count] = (omni_diff_op_t){'+', &b[prev_y], prev_x, prev_y};
+            } else {
+                ops[*count] = (omni_diff_op_t){'-', &a[prev_x], prev_x, prev_y};
+            }
+            (*count)++;
+        }
+        x = prev_x;
+        y = prev_y;
+    }
+
+    for (int32_t i = 0; i < steps; i++) {
+        free(trace[i]);
+    }
+    free(trace);
+    free(v);
+
+    for (int32_t i = 0, j = *count - 1; i < j; i++, j--) {
+        omni_diff_op_t tmp = ops[i];
+        ops[i] = ops[j];
+        ops[j] = tmp;
+    }
+    return ops;
+}
+
+static void omni_diff_write_hunk(omni_diff_buf_t* buf, const omni_diff_op_t* ops, int32_t count) {
+    int32_t old_start = ops[0].old_pos, new_start = ops[0].new_pos;
+    int32_t old_count = 0, new_count = 0;
+    for (int32_t i = 0; i < count; i++) {
+        if (ops[i].kind != '+') old_count++;
+        if (ops[i].kind != '-') new_count++;
+    }
+    // Unified diffs use 1-based line numbers, except that an empty range
+    // names the line after which the change applies.
+    if (old_count > 0) old_start++;
+    if (new_count > 0) new_start++;
+
+    char header[96];
+    int len = snprintf(header, sizeof(header), "@@ -%d,%d +%d,%d @@\n",
+                       old_start, old_count, new_start, new_count);
+    omni_diff_buf_append(buf, header, (size_t)len);
+    for (int32_t i = 0; i < count; i++) {
+        const omni_diff_line_t* line = ops[i].line;
+        omni_diff_buf_append(buf, &ops[i].kind, 1);
+        omni_diff_buf_append(buf, line->text, line->len);
+        if (line->len == 0 || line->text[line->len - 1] != '\n') {
+            omni_diff_buf_append(buf, "\n", 1);
+            omni_diff_buf_append(buf, omni_diff_no_newline, sizeof(omni_diff_no_newline) - 1);
+        }
+    }
+}
+
+char* omni_diff_unified(const char* original, const char* modified, int32_t context) {
+    if (!original) original = "";
+    if (!modified) modified = "";
+    if (context < 0) context = 0;
+
+    int32_t n = 0, m = 0, count = 0;
+    omni_diff_line_t* a = omni_diff_split_lines(original, &n);
+    omni_diff_line_t* b = omni_diff_split_lines(modified, &m);
+    omni_diff_op_t* ops = omni_diff_myers(a, n, b, m, &count);
+
+    omni_diff_buf_t buf = {0};
+    int32_t i = 0;
+    while (ops && i < count) {
+        if (ops[i].kind == ' ') {
+            i++;
+            continue;
+        }
+        int32_t start = i - context;
+        if (start < 0) start = 0;
+        int32_t end = i;
+        while (end < count) {
+            if (ops[end].kind != ' ') {
+                end++;
+                continue;
+            }
+            // Merge with the next change if the unchanged run between them
+            // would otherwise be split into overlapping context.
+            int32_t j = end;
+            while (j < count && ops[j].kind == ' ') j++;
+            if (j == count || j - end > 2 * context) {
+                if (end + context < j) j = end + context;
+                end = j;
+                break;
+            }
+            end = j;
+        }
+        if (buf.len == 0) {
+            const char* header = "--- original\n+++ modified\n";
+            omni_diff_buf_append(&buf, header, strlen(header));
+        }
+        omni_diff_write_hunk(&buf, ops + start, end - start);
+        i = end;
+    }
+
+    free(ops);
+    free(a);
+    free(b);
+    return omni_diff_buf_finish(&buf);
+}
+
+// omni_diff_parse_range parses "start[,count]" and returns a pointer past it.
+static const char* omni_diff_parse_range(const char* p, int32_t* start, int32_t* count) {
+    char* end;
+    *start = (int32_t)strtol(p, &end, 10);
+    if (end == p) return NULL;
+    *count = 1;
+    if (*end == ',') {
+        p = end + 1;
+        *count = (int32_t)strtol(p, &end, 10);
+        if (end == p) return NULL;
+    }
+    return end;
+}
+
+char* omni_diff_apply(const char* original, const char* patch) {
+    if (!original) original = "";
+    if (!patch) patch = "";
+
+    int32_t n = 0, pn = 0;
+    omni_diff_line_t* lines = omni_diff_split_lines(original, &n);
+    omni_diff_line_t* plines = omni_diff_split_lines(patch, &pn);
+    omni_diff_buf_t buf = {0};
+    int32_t pos = 0;
+    int ok = 1;
+
+    int32_t i = 0;
+    while (ok && i < pn) {
+        const omni_diff_line_t* pl = &plines[i];
+        if (pl->len < 3 || strncmp(pl->text, "@@ ", 3) != 0) {
+            // File headers and other preamble lines are ignored.
+            i++;
+            continue;
+        }
+
+        int32_t old_start, old_count, new_start, new_count;
+        const char* p = pl->text + 3;
+        if (*p != '-' || !(p = omni_diff_parse_range(p + 1, &old_start, &old_count)) ||
+            *p != ' ' || p[1] != '+' || !(p = omni_diff_parse_range(p + 2, &new_start, &new_count))) {
+            ok = 0;
+            break;
+        }
+        i++;
+
+        int32_t start = old_count == 0 ? old_start : old_start - 1;
+        if (start < pos || start > n) {
+            ok = 0;
+            break;
+        }
+        for (; pos < start; pos++) {
+            omni_diff_buf_append(&buf, lines[pos].text, lines[pos].len);
+        }
+
+        int32_t remaining_old = old_count, remaining_new = new_count;
+        while (ok && (remaining_old > 0 || remaining_new > 0)) {
+            if (i >= pn) {
+                ok = 0;
+                break;
+            }
+            pl = &plines[i++];
+            char kind = pl->text[0];
+            omni_diff_line_t text = {pl->text + 1, pl->len - 1};
+            if (pl->len == 1 && kind == '\n') {
+                // Some tools strip the space prefix from empty context lines.
+                kind = ' ';
+                text.text = pl->text;
+                text.len = 1;
+            }
+            // A following "\ No newline" marker strips the newline from this line.
+            if (i < pn && plines[i].text[0] == '\\') {
+                if (text.len > 0 && text.text[text.len - 1] == '\n') text.len--;
+                i++;
+            }
+            switch (kind) {
+            case ' ':
+            case '-':
+                if (pos >= n || !omni_diff_line_equal(&lines[pos], &text)) {
+                    ok = 0;
+                    break;
+                }
+                if (kind == ' ') {
+                    omni_diff_buf_append(&buf, text.text, text.len);
+                    remaining_new--;
+                }
+                remaining_old--;
+                pos++;
+                break;
+            case '+':
+                omni_diff_buf_append(&buf, text.text, text.len);
+                remaining_new--;
+                break;
+            default:
+                ok = 0;
+                break;
+            }
+        }
+    }
+
+    if (ok) {
+        for (; pos < n; pos++) {
+            omni_diff_buf_append(&buf, lines[pos].text, lines[pos].len);
+        }
+    }
+    free(lines);
+    free(plines);
+    if (!ok) {
+        // Patches that do not apply cleanly yield an empty string, matching the VM.
+        free(buf.data);
+        return strdup("");
+    }
+    return omni_diff_buf_finish(&buf);
+}
+
+// ============================================================================
+// Hash Implementation
+// ============================================================================
+
+static char* omni_hash_to_hex(const uint8_t* digest, size_t len) {
+    static const char hex[] = "0123456789abcdef";
+    char* out = malloc(len * 2 + 1);
+    if (!out) {
+        return NULL;
+    }
+    for (size_t i = 0; i < len; i++) {
+        out[i * 2] = hex[digest[i] >> 4];
+        out[i * 2 + 1] = hex[digest[i] & 0x0f];
+    }
+    out[len * 2] = '\0';
+    return out;
+}
+
+#define OMNI_ROTR32(x, n) (((x) >> (n)) | ((x) << (32 - (n))))
+#define OMNI_ROTL32(x, n) (((x) << (n)) | ((x) >> (32 - (n))))
+#define OMNI_ROTR64(x, n) (((x) >> (n)) | ((x) << (64 - (n))))
+
+static const uint32_t omni_sha256_k[64] = {
+    0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+    0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+    0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+    0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+    0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+    0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+    0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+    0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2,
+};
+
+static void omni_sha256_block(uint32_t state[8], const uint8_t* block) {
+    uint32_t w[64];
+    for (int i = 0; i < 16; i++) {
+        w[i] = ((uint32_t)block[i * 4] << 24) | ((uint32_t)block[i * 4 + 1] << 16) |
+               ((uint32_t)block[i * 4 + 2] << 8) | (uint32_t)block[i * 4 + 3];
+    }
+    for (int i = 16; i < 64; i++) {
+        uint32_t s0 = OMNI_ROTR32(w[i - 15], 7) ^ OMNI_ROTR32(w[i - 15], 18) ^ (w[i - 15] >> 3);
+        uint32_t s1 = OMNI_ROTR32(w[i - 2], 17) ^ OMNI_ROTR32(w[i - 2], 19) ^ (w[i - 2] >> 10);
+        w[i] = w[i - 16] + s0 + w[i - 7] + s1;
+    }
+    uint32_t a = state[0], b = state[1], c = state[2], d = state[3];
+    uint32_t e = state[4], f = state[5], g = state[6], h = state[7];
+    for (int i = 0; i < 64; i++) {
+        uint32_t s1 = OMNI_ROTR32(e, 6) ^ OMNI_ROTR32(e, 11) ^ OMNI_ROTR32(e, 25);
+        uint32_t ch = (e & f) ^ (~e & g);
+        uint32_t t1 = h + s1 + ch + omni_sha256_k[i] + w[i];
+        uint32_t s0 = OMNI_ROTR32(a, 2) ^ OMNI_ROTR32(a, 13) ^ OMNI_ROTR32(a, 22);
+        uint32_t maj = (a & b) ^ (a & c) ^ (b & c);
+        uint32_t t2 = s0 + maj;
+        h = g; g = f; f = e; e = d + t1;
+        d = c; c = b; b = a; a = t1 + t2;
+    }
+    state[0] += a; state[1] += b; state[2] += c; state[3] += d;
+    state[4] += e; state[5] += f; state[6] += g; state[7] += h;
+}
+
+char* omni_hash_sha256(const char* str) {
+    if (!str) str = "";
+    uint32_t state[8] = {
+        0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+    };
+    const uint8_t* data = (const uint8_t*)str;
+    size_t len = strlen(str);
+    size_t i = 0;
+    for (; i + 64 <= len; i += 64) {
+        omni_sha256_block(state, data + i);
+    }
+
+    // Final block(s): remaining bytes, 0x80 terminator, zero padding, 64-bit big-endian bit length
+    uint8_t block[128] = {0};
+    size_t rem = len - i;
+    memcpy(block, data + i, rem);
+    block[rem] = 0x80;
+    size_t total = rem + 1 + 8 <= 64 ? 64 : 128;
+    uint64_t bit_len = (uint64_t)len * 8;
+    for (int j = 0; j < 8; j++) {
+        block[total - 1 - j] = (uint8_t)(bit_len >> (8 * j));
+    }
+    for (size_t off = 0; off < total; off += 64) {
+        omni_sha256_block(state, block + off);
+    }
+
+    uint8_t digest[32];
+    for (int j = 0; j < 8; j++) {
+        digest[j * 4] = (uint8_t)(state[j] >> 24);
+        digest[j * 4 + 1] = (uint8_t)(state[j] >> 16);
+        digest[j * 4 + 2] = (uint8_t)(state[j] >> 8);
+        digest[j * 4 + 3] = (uint8_t)state[j];
+    }
+    return omni_hash_to_hex(digest, sizeof(digest));
+}
+
+static const uint64_t omni_sha512_k[80] = {
+    0x428a2f98d728ae22ULL, 0x7137449123ef65cdULL, 0xb5c0fbcfec4d3b2fULL, 0xe9b5dba58189dbbcULL,
+    0x3956c25bf348b538ULL, 0x59f111f1b605d019ULL, 0x923f82a4af194f9bULL, 0xab1c5ed5da6d8118ULL,
+    0xd807aa98a3030242ULL, 0x12835b0145706fbeULL, 0x243185be4ee4b28cULL, 0x550c7dc3d5ffb4e2ULL,
+    0x72be5d74f27b896fULL, 0x80deb1fe3b1696b1ULL, 0x9bdc06a725c71235ULL, 0xc19bf174cf692694ULL,
+    0xe49b69c19ef14ad2ULL, 0xefbe4786384f25e3ULL, 0x0fc19dc68b8cd5b5ULL, 0x240ca1cc77ac9c65ULL,
+    0x2de92c6f592b0275ULL, 0x4a7484aa6ea6e483ULL, 0x5cb0a9dcbd41fbd4ULL, 0x76f988da831153b5ULL,
+    0x983e5152ee66dfabULL, 0xa831c66d2db43210ULL, 0xb00327c898fb213fULL, 0xbf597fc7beef0ee4ULL,
+    0xc6e00bf33da88fc2ULL, 0xd5a79147930aa725ULL, 0x06ca6351e003826fULL, 0x142929670a0e6e70ULL,
+    0x27b70a8546d22ffcULL, 0x2e1b21385c26c926ULL, 0x4d2c6dfc5ac42aedULL, 0x53380d139d95b3dfULL,
+    0x650a73548baf63deULL, 0x766a0abb3c77b2a8ULL, 0x81c2c92e47edaee6ULL, 0x92722c851482353bULL,
+    0xa2bfe8a14cf10364ULL, 0xa81a664bbc423001ULL, 0xc24b8b70d0f89791ULL, 0xc76c51a30654be30ULL,
+    0xd192e819d6ef5218ULL, 0xd69906245565a910ULL, 0xf40e35855771202aULL, 0x106aa07032bbd1b8ULL,
+    0x19a4c116b8d2d0c8ULL, 0x1e376c085141ab53ULL, 0x2748774cdf8eeb99ULL, 0x34b0bcb5e19b48a8ULL,
+    0x391c0cb3c5c95a63ULL, 0x4ed8aa4ae3418acbULL, 0x5b9cca4f7763e373ULL, 0x682e6ff3d6b2b8a3ULL,
+    0x748f82ee5defb2fcULL, 0x78a5636f43172f60ULL, 0x84c87814a1f0ab72ULL, 0x8cc702081a6439ecULL,
+    0x90befffa23631e28ULL, 0xa4506cebde82bde9ULL, 0xbef9a3f7b2c67915ULL, 0xc67178f2e372532bULL,
+    0xca273eceea26619cULL, 0xd186b8c721c0c207ULL, 0xeada7dd6cde0eb1eULL, 0xf57d4f7fee6ed178ULL,
+    0x06f067aa72176fbaULL, 0x0a637dc5a2c898a6ULL, 0x113f9804bef90daeULL, 0x1b710b35131c471bULL,
+    0x28db77f523047d84ULL, 0x32caab7b40c72493ULL, 0x3c9ebe0a15c9bebcULL, 0x431d67c49c100d4cULL,
+    0x4cc5d4becb3e42b6ULL, 0x597f299cfc657e2aULL, 0x5fcb6fab3ad6faecULL, 0x6c44198c4a475817ULL,
+};
+
+static void omni_sha512_block(uint64_t state[8], const uint8_t* block) {
+    uint64_t w[80];
+    for (int i = 0; i < 16; i++) {
+        w[i] = 0;
+        for (int j = 0; j < 8; j++) {
+            w[i] = (w[i] << 8) | block[i * 8 + j];
+        }
+    }
+    for (int i = 16; i < 80; i++) {
+        uint64_t s0 = OMNI_ROTR64(w[i - 15], 1) ^ OMNI_ROTR64(w[i - 15], 8) ^ (w[i - 15] >> 7);
+        uint64_t s1 = OMNI_ROTR64(w[i - 2], 19) ^ OMNI_ROTR64(w[i - 2], 61) ^ (w[i - 2] >> 6);
+        w[i] = w[i - 16] + s0 + w[i - 7] + s1;
+    }
+    uint64_t a = state[0], b = state[1], c = state[2], d = state[3];
+    uint64_t e = state[4], f = state[5], g = state[6], h = state[7];
+    for (int i = 0; i < 80; i++) {
+        uint64_t s1 = OMNI_ROTR64(e, 14) ^ OMNI_ROTR64(e, 18) ^ OMNI_ROTR64(e, 41);
+        uint64_t ch = (e & f) ^ (~e & g);
+        uint64_t t1 = h + s1 + ch + omni_sha512_k[i] + w[i];
+        uint64_t s0 = OMNI_ROTR64(a, 28) ^ OMNI_ROTR64(a, 34) ^ OMNI_ROTR64(a, 39);
+        uint64_t maj = (a & b) ^ (a & c) ^ (b & c);
+        uint64_t t2 = s0 + maj;
+        h = g; g = f; f = e; e = d + t1;
+        d = c; c = b; b = a; a = t1 + t2;
+    }
+    state[0] += a; state[1] += b; state[2] += c; state[3] += d;
+    state[4] += e; state[5] += f; state[6] += g; state[7] += h;
+}
+
+char* omni_hash_sha512(const char* str) {
+    if (!str) str = "";
+    uint64_t state[8] = {
+        0x6a09e667f3bcc908ULL, 0xbb67ae8584caa73bULL, 0x3c6ef372fe94f82bULL, 0xa54ff53a5f1d36f1ULL,
+        0x510e527fade682d1ULL, 0x9b05688c2b3e6c1fULL, 0x1f83d9abfb41bd6bULL, 0x5be0cd19137e2179ULL,
+    };
+    const uint8_t* data = (const uint8_t*)str;
+    size_t len = strlen(str);
+    size_t i = 0;
+    for (; i + 128 <= len; i += 128) {
+        omni_sha512_block(state, data + i);
+    }
+
+    // Final block(s): remaining bytes, 0x80 terminator, zero padding, 128-bit big-endian bit length
+    uint8_t block[256] = {0};
+    size_t rem = len - i;
+    memcpy(block, data + i, rem);
+    block[rem] = 0x80;
+    size_t total = rem + 1 + 16 <= 128 ? 128 : 256;
+    uint64_t bit_len = (uint64_t)len * 8;
+    for (int j = 0; j < 8; j++) {
+        block[total - 1 - j] = (uint8_t)(bit_len >> (8 * j));
+    }
+    for (size_t off = 0; off < total; off += 128) {
+        omni_sha512_block(state, block + off);
+    }
+
+    uint8_t digest[64];
+    for (int j = 0; j < 8; j++) {
+        for (int k = 0; k < 8; k++) {
+            digest[j * 8 + k] = (uint8_t)(state[j] >> (56 - 8 * k));
+        }
+    }
+    return omni_hash_to_hex(digest, sizeof(digest));
+}
+
+static const uint32_t omni_md5_k[64] = {
+    0xd76aa478, 0xe8c7b756, 0x242070db, 0xc1bdceee, 0xf57c0faf, 0x4787c62a, 0xa8304613, 0xfd469501,
+    0x698098d8, 0x8b44f7af, 0xffff5bb1, 0x895cd7be, 0x6b901122, 0xfd987193, 0xa679438e, 0x49b40821,
+    0xf61e2562, 0xc040b340, 0x265e5a51, 0xe9b6c7aa, 0xd62f105d, 0x02441453, 0xd8a1e681, 0xe7d3fbc8,
+    0x21e1cde6, 0xc33707d6, 0xf4d50d87, 0x455a14ed, 0xa9e3e905, 0xfcefa3f8, 0x676f02d9, 0x8d2a4c8a,
+    0xfffa3942, 0x8771f681, 0x6d9d6122, 0xfde5380c, 0xa4beea44, 0x4bdecfa9, 0xf6bb4b60, 0xbebfbc70,
+    0x289b7ec6, 0xeaa127fa, 0xd4ef3085, 0x04881d05, 0xd9d4d039, 0xe6db99e5, 0x1fa27cf8, 0xc4ac5665,
+    0xf4292244, 0x432aff97, 0xab9423a7, 0xfc93a039, 0x655b59c3, 0x8f0ccc92, 0xffeff47d, 0x85845dd1,
+    0x6fa87e4f, 0xfe2ce6e0, 0xa3014314, 0x4e0811a1, 0xf7537e82, 0xbd3af235, 0x2ad7d2bb, 0xeb86d391,
+};
+
+static const uint8_t omni_md5_r[64] = {
+    7, 12, 17, 22, 7, 12, 17, 22, 7, 12, 17, 22, 7, 12, 17, 22,
+    5, 9, 14, 20, 5, 9, 14, 20, 5, 9, 14, 20, 5, 9, 14, 20,
+    4, 11, 16, 23, 4, 11, 16, 23, 4, 11, 16, 23, 4, 11, 16, 23,
+    6, 10, 15, 21, 6, 10, 15, 21, 6, 10, 15, 21, 6, 10, 15, 21,
+};
+
+static void omni_md5_block(uint32_t state[4], const uint8_t* block) {
+    uint32_t m[16];
+    for (int i = 0; i < 16; i++) {
+        m[i] = (uint32_t)block[i * 4] | ((uint32_t)block[i * 4 + 1] << 8) |
+               ((uint32_t)block[i * 4 + 2] << 16) | ((uint32_t)block[i * 4 + 3] << 24);
+    }
+    uint32_t a = state[0], b = state[1], c = state[2], d = state[3];
+    for (int i = 0; i < 64; i++) {
+        uint32_t f;
+        int g;
+        if (i < 16) {
+            f = (b & c) | (~b & d);
+            g = i;
+        } else if (i < 32) {
+            f = (d & b) | (~d & c);
+            g = (5 * i + 1) % 16;
+        } else if (i < 48) {
+            f = b ^ c ^ d;
+            g = (3 * i + 5) % 16;
+        } else {
+            f = c ^ (b | ~d);
+            g = (7 * i) % 16;
+        }
+        uint32_t tmp = d;
+        d = c;
+        c = b;
+        b = b + OMNI_ROTL32(a + f + omni_md5_k[i] + m[g], omni_md5_r[i]);
+        a = tmp;
+    }
+    state[0] += a; state[1] += b; state[2] += c; state[3] += d;
+}
+
+char* omni_hash_md5(const char* str) {
+    if (!str) str = "";
+    uint32_t state[4] = {0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476};
+    const uint8_t* data = (const uint8_t*)str;
+    size_t len = strlen(str);
+    size_t i = 0;
+    for (; i + 64 <= len; i += 64) {
+        omni_md5_block(state, data + i);
+    }
+
+    // Final block(s): same padding as SHA-256 but with a little-endian bit length
+    uint8_t block[128] = {0};
+    size_t rem = len - i;
+    memcpy(block, data + i, rem);
+    block[rem] = 0x80;
+    size_t total = rem + 1 + 8 <= 64 ? 64 : 128;
+    uint64_t bit_len = (uint64_t)len * 8;
+    for (int j = 0; j < 8; j++) {
+        block[total - 8 + j] = (uint8_t)(bit_len >> (8 * j));
+    }
+    for (size_t off = 0; off < total; off += 64) {
+        omni_md5_block(state, block + off);
+    }
+
+    uint8_t digest[16];
+    for (int j = 0; j < 4; j++) {
+        for (int k = 0; k < 4; k++) {
+            digest[j * 4 + k] = (uint8_t)(state[j] >> (8 * k));
+        }
+    }
+    return omni_hash_to_hex(digest, sizeof(digest));
+}
+
+int32_t omni_hash_fnv32(const char* str) {
+    uint32_t h = 0x811c9dc5u;
+    for (const uint8_t* p = (const uint8_t*)(str ? str : ""); *p; p++) {
+        h ^= *p;
+        h *= 0x01000193u;
+    }
+    return (int32_t)h;
+}
+
+int64_t omni_hash_fnv64(const char* str) {
+    uint64_t h = 0xcbf29ce484222325ULL;
+    for (const uint8_t* p = (const uint8_t*)(str ? str : ""); *p; p++) {
+        h ^= *p;
+        h *= 0x100000001b3ULL;
+    }
+    return (int64_t)h;
+}
+
+int32_t omni_hash_murmur3(const char* str) {
+    const uint8_t* data = (const uint8_t*)(str ? str : "");
+    size_t len = strlen((const char*)data);
+    const uint32_t c1 = 0xcc9e2d51u, c2 = 0x1b873593u;
+    uint32_t h = 0;
+
+    size_t nblocks = len / 4;
+    for (size_t i = 0; i < nblocks; i++) {
+        const uint8_t* b = data + i * 4;
+        uint32_t k = (uint32_t)b[0] | ((uint32_t)b[1] << 8) | ((uint32_t)b[2] << 16) | ((uint32_t)b[3] << 24);
+        k *= c1;
+        k = OMNI_ROTL32(k, 15);
+        k *= c2;
+        h ^= k;
+        h = OMNI_ROTL32(h, 13);
+        h = h * 5 + 0xe6546b64u;
+    }
+
+    const uint8_t* tail = data + nblocks * 4;
+    uint32_t k = 0;
+    switch (len & 3) {
+    case 3:
+        k ^= (uint32_t)tail[2] << 16;
+        /* fallthrough */
+    case 2:
+        k ^= (uint32_t)tail[1] << 8;
+        /* fallthrough */
+    case 1:
+        k ^= tail[0];
+        k *= c1;
+        k = OMNI_ROTL32(k, 15);
+        k *= c2;
+        h ^= k;
+    }
+
+    h ^= (uint32_t)len;
+    h ^= h >> 16;
+    h *= 0x85ebca6bu;
+    h ^= h >> 13;
+    h *= 0xc2b2ae35u;
+    h ^= h >> 16;
+    return (int32_t)h;
+}
+
+// ============================================================================
+// JSON (std.json)
+// ============================================================================
+
+static void json_fail(const char* op, const char* message) {
+    fprintf(stderr, "%s: %s\n", op, message);
+    exit(1);
+}
+
+static omni_json_t* json_new(int32_t kind) {
+    omni_json_t* value = calloc(1, sizeof(omni_json_t));
+    if (!value) {
+        json_fail("json", "out of memory");
+    }
+    value->kind = kind;
+    return value;
+}
+
+static const char* json_kind_name(const omni_json_t* value) {
+    static const char* names[] = {"null", "bool", "int", "float", "string", "array", "object"};
+    return value ? names[value->kind] : "null";
+}
+
+// json_parser reads a document from text; pos is the next byte to read
+typedef struct {
+    const char* text;
+    const char* pos;
+} json_parser;
+
+static void json_parse_fail(json_parser* p, const char* message) {
+    fprintf(stderr, "json.parse: %s at offset %ld\n", message, (long)(p->pos - p->text));
+    exit(1);
+}
+
+static void json_skip_space(json_parser* p) {
+    while (*p->pos == ' ' || *p->pos == '\t' || *p->pos == '\n' || *p->pos == '\r') {
+        p->pos++;
+    }
+}
+
+static void json_append_item(omni_json_t* value, char* key, omni_json_t* item) {
+    if (key) {
+        // A repeated key keeps the last value, as in the VM
+        for (int32_t i = 0; i < value->count; i++) {
+            if (strcmp(value->keys[i], key) == 0) {
+                free(key);
+                value->items[i] = item;
+                return;
+            }
+        }
+    }
+    value->items = realloc(value->items, (size_t)(value->count + 1) * sizeof(omni_json_t*));
+    if (key) {
+        value->keys = realloc(value->keys, (size_t)(value->count + 1) * sizeof(char*));
+    }
+    if (!value->items || (key && !value->keys)) {
+        json_fail("json.parse", "out of memory");
+    }
+    if (key) {
+        value->keys[value->count] = key;
+    }
+    value->items[value->count++] = item;
+}
+
+static int json_hex4(json_parser* p) {
+    int code = 0;
+    for (int i = 0; i < 4; i++) {
+        char c = *p->pos++;
+        code <<= 4;
+        if (c >= '0' && c <= '9') {
+            code |= c - '0';
+        } else if (c >= 'a' && c <= 'f') {
+            code |= c - 'a' + 10;
+        } else if (c >= 'A' && c <= 'F') {
+            code |= c - 'A' + 10;
+        } else {
+            p->pos--;
+            json_parse_fail(p, "invalid \\u escape");
+        }
+    }
+    return code;
+}
+
+static void json_put_utf8(format_buffer* buf, int code);
+static void json_buffer_write(format_buffer* buf, const char* text, size_t n);
+
+// json_parse_string reads a string literal, p->pos being at its opening quote
+static char* json_parse_string(json_parser* p) {
+    format_buffer buf = {malloc(16), 0, 16};
+    if (!buf.data) {
+        json_fail("json.parse", "out of memory");
+    }
+    p->pos++;
+    for (;;) {
+        char c = *p->pos;
+        if (c == '"') {
+            p->pos++;
+            break;
+        }
+        if (c == '\0') {
+            json_parse_fail(p, "unterminated string");
+        }
+        if ((unsigned char)c < 0x20) {
+            json_parse_fail(p, "control character in string");
+        }
+        if (c != '\\') {
+            json_buffer_write(&buf, p->pos++, 1);
+            continue;
+        }
+        p->pos++;
+        char esc = *p->pos++;
+        switch (esc) {
+        case '"': json_buffer_write(&buf, "\"", 1); break;
+        case '\\': json_buffer_write(&buf, "\\", 1); break;
+        case '/': json_buffer_write(&buf, "/", 1); break;
+        case 'b': json_buffer_write(&buf, "\b", 1); break;
+        case 'f': json_buffer_write(&buf, "\f", 1); break;
+        case 'n': json_buffer_write(&buf, "\n", 1); break;
+        case 'r': json_buffer_write(&buf, "\r", 1); break;
+        case 't': json_buffer_write(&buf, "\t", 1); break;
+        case 'u': {
+            int code = json_hex4(p);
+            if (code >= 0xD800 && code < 0xDC00 && p->pos[0] == '\\' && p->pos[1] == 'u') {
+                const char* save = p->pos;
+                p->pos += 2;
+                int low = json_hex4(p);
+                if (low >= 0xDC00 && low < 0xE000) {
+                    code = 0x10000 + ((code - 0xD800) << 10) + (low - 0xDC00);
+                } else {
+                    p->pos = save;
+                    code = 0xFFFD;
+                }
+            } else if (code >= 0xD800 && code < 0xE000) {
+                code = 0xFFFD;
+            }
+            json_put_utf8(&buf, code);
+            break;
+        }
+        default:
+            p->pos--;
+            json_parse_fail(p, "invalid escape in string");
+        }
+    }
+    json_buffer_write(&buf, "", 0);
+    return buf.data;
+}
+
+static omni_json_t* json_parse_value(json_parser* p, int depth);
+
+static omni_json_t* json_parse_number(json_parser* p) {
+    const char* start = p->pos;
+    if (*p->pos == '-') {
+        p->pos++;
+    }
+    if (*p->pos == '0') {
+        p->pos++;
+    } else if (*p->pos >= '1' && *p->pos <= '9') {
+        while (*p->pos >= '0' && *p->pos <= '9') {
+            p->pos++;
+        }
+    } else {
+        json_parse_fail(p, "invalid number");
+    }
+    int integral = 1;
+    if (*p->pos == '.') {
+        integral = 0;
+        p->pos++;
+        if (*p->pos < '0' || *p->pos > '9') {
+            json_parse_fail(p, "invalid number");
+        }
+        while (*p->pos >= '0' && *p->pos <= '9') {
+            p->pos++;
+        }
+    }
+    if (*p->pos == 'e' || *p->pos == 'E') {
+        integral = 0;
+        p->pos++;
+        if (*p->pos == '+' || *p->pos == '-') {
+            p->pos++;
+        }
+        if (*p->pos < '0' || *p->pos > '9') {
+            json_parse_fail(p, "invalid number");
+        }
+        while (*p->pos >= '0' && *p->pos <= '9') {
+            p->pos++;
+        }
+    }
+    if (integral) {
+        errno = 0;
+        long long i = strtoll(start, NULL, 10);
+        if (errno == 0) {
+            omni_json_t* value = json_new(OMNI_JSON_INT);
+            value->i = i;
+            return value;
+        }
+    }
+    omni_json_t* value = json_new(OMNI_JSON_FLOAT);
+    value->f = strtod(start, NULL);
+    return value;
+}
+
+static int json_literal(json_parser* p, const char* word) {
+    size_t n = strlen(word);
+    if (strncmp(p->pos, word, n) != 0) {
+        return 0;
+    }
+    p->pos += n;
+    return 1;
+}
+
+static omni_json_t* json_parse_value(json_parser* p, int depth) {
+    if (depth > 10000) {
+        json_parse_fail(p, "document nested too deeply");
+    }
+    json_skip_space(p);
+    char c = *p->pos;
+    if (c == '{' || c == '[') {
+        char close = c == '{' ? '}' : ']';
+        omni_json_t* value = json_new(c == '{' ? OMNI_JSON_OBJECT : OMNI_JSON_ARRAY);
+        p->pos++;
+        json_skip_space(p);
+        if (*p->pos == close) {
+            p->pos++;
+            return value;
+        }
+        for (;;) {
+            char* key = NULL;
+            if (value->kind == OMNI_JSON_OBJECT) {
+                json_skip_space(p);
+                if (*p->pos != '"') {
+                    json_parse_fail(p, "expected a string key");
+                }
+                key = json_parse_string(p);
+                json_skip_space(p);
+                if (*p->pos != ':') {
+                    json_parse_fail(p, "expected ':' after an object key");
+                }
+                p->pos++;
+            }
+            json_append_item(value, key, json_parse_value(p, depth + 1));
+            json_skip_space(p);
+            if (*p->pos == ',') {
+                p->pos++;
+                continue;
+            }
+            if (*p->pos == close) {
+                p->pos++;
+                return value;
+            }
+            json_parse_fail(p, value->kind == OMNI_JSON_OBJECT ? "expected ',' or '}'" : "expected ',' or ']'");
+        }
+    }
+    if (c == '"') {
+        omni_json_t* value = json_new(OMNI_JSON_STRING);
+        value->s = json_parse_string(p);
+        return value;
+    }
+    if (c == '-' || (c >= '0' && c <= '9')) {
+        return json_parse_number(p);
+    }
+    if (json_literal(p, "true")) {
+        omni_json_t* value = json_new(OMNI_JSON_BOOL);
+        value->i = 1;
+        return value;
+    }
+    if (json_literal(p, "false")) {
+        return json_new(OMNI_JSON_BOOL);
+    }
+    if (json_literal(p, "null")) {
+        return json_new(OMNI_JSON_NULL);
+    }
+    json_parse_fail(p, c ? "unexpected character" : "unexpected end of input");
+    return NULL;
+}
+
+omni_json_t* omni_json_parse(const char* s) {
+    json_parser p = {s ? s : "", s ? s : ""};
+    omni_json_t* value = json_parse_value(&p, 0);
+    json_skip_space(&p);
+    if (*p.pos) {
+        json_parse_fail(&p, "unexpected data after the document");
+    }
+    return value;
+}
+
+static void json_buffer_write(format_buffer* buf, const char* text, size_t n) {
+    if (buf->len + n + 1 > buf->cap) {
+        size_t cap = buf->cap * 2;
+        while (cap < buf->len + n + 1) {
+            cap *= 2;
+        }
+        char* data = realloc(buf->data, cap);
+        if (!data) {
+            json_fail("json", "out of memory");
+        }
+        buf->data = data;
+        buf->cap = cap;
+    }
+    memcpy(buf->data + buf->len, text, n);
+    buf->len += n;
+    buf->data[buf->len] = '\0';
+}
+
+static void json_put_utf8(format_buffer* buf, int code) {
+    char out[4];
+    size_t n;
+    if (code < 0x80) {
+        out[0] = (char)code;
+        n = 1;
+    } else if (code < 0x800) {
+        out[0] = (char)(0xC0 | (code >> 6));
+        out[1] = (char)(0x80 | (code & 0x3F));
+        n = 2;
+    } else if (code < 0x10000) {
+        out[0] = (char)(0xE0 | (code >> 12));
+        out[1] = (char)(0x80 | ((code >> 6) & 0x3F));
+        out[2] = (char)(0x80 | (code & 0x3F));
+        n = 3;
+    } else {
+        out[0] = (char)(0xF0 | (code >> 18));
+        out[1] = (char)(0x80 | ((code >> 12) & 0x3F));
+        out[2] = (char)(0x80 | ((code >> 6) & 0x3F));
+        out[3] = (char)(0x80 | (code & 0x3F));
+        n = 4;
+    }
+    json_buffer_write(buf, out, n);
+}
+
+// json_write_string writes s as a string literal, escaping it the way Go's
+// encoding/json does with HTML escaping off
+static void json_write_string(format_buffer* buf, const char* s) {
+    json_buffer_write(buf, "\"", 1);
+    for (const unsigned char* p = (const unsigned char*)(s ? s : ""); *p; p++) {
+        char esc[8];
+        switch (*p) {
+        case '"': json_buffer_write(buf, "\\\"", 2); continue;
+        case '\\': json_buffer_write(buf, "\\\\", 2); continue;
+        case '\n': json_buffer_write(buf, "\\n", 2); continue;
+        case '\r': json_buffer_write(buf, "\\r", 2); continue;
+        case '\t': json_buffer_write(buf, "\\t", 2); continue;
+        }
+        if (*p < 0x20) {
+            snprintf(esc, sizeof(esc), "\\u%04x", *p);
+            json_buffer_write(buf, esc, 6);
+        } else if (p[0] == 0xE2 && p[1] == 0x80 && (p[2] == 0xA8 || p[2] == 0xA9)) {
+            json_buffer_write(buf, p[2] == 0xA8 ? "\\u2028" : "\\u2029", 6);
+            p += 2;
+        } else {
+            json_buffer_write(buf, (const char*)p, 1);
+        }
+    }
+    json_buffer_write(buf, "\"", 1);
+}
+
+// json_write_float writes f in the shortest form that reads back as f, in
+// plain notation for 1e-6 <= |f| < 1e21 and in exponent notation otherwise
+static void json_write_float(format_buffer* buf, double f) {
+    if (isnan(f) || isinf(f)) {
+        json_fail("json.stringify", "unsupported value: NaN or infinite float");
+    }
+    char digits[32];
+    int precision = 1;
+    for (; precision < 17; precision++) {
+        snprintf(digits, sizeof(digits), "%.*e", precision - 1, f);
+        if (strtod(digits, NULL) == f) {
+            break;
+        }
+    }
+    snprintf(digits, sizeof(digits), "%.*e", precision - 1, f);
+    double magnitude = fabs(f);
+    if (magnitude != 0 && (magnitude < 1e-6 || magnitude >= 1e21)) {
+        // d.ddde-07 becomes d.ddde-7, as in Go
+        char* e = strchr(digits, 'e');
+        if (e && e[1] == '-' && e[2] == '0') {
+            memmove(e + 2, e + 3, strlen(e + 3) + 1);
+        }
+        json_buffer_write(buf, digits, strlen(digits));
+        return;
+    }
+    // Rebuild plain notation from the mantissa digits and the exponent
+    char mantissa[24];
+    size_t n = 0;
+    const char* p = digits;
+    if (*p == '-') {
+        json_buffer_write(buf, "-", 1);
+        p++;
+    }
+    for (; *p && *p != 'e'; p++) {
+        if (*p != '.') {
+            mantissa[n++] = *p;
+        }
+    }
+    int exp = *p == 'e' ? atoi(p + 1) : 0;
+    while (n > 1 && mantissa[n - 1] == '0') {
+        n--;
+    }
+    if (exp < 0) {
+        json_buffer_write(buf, "0.", 2);
+        for (int i = -1; i > exp; i--) {
+            json_buffer_write(buf, "0", 1);
+        }
+        json_buffer_write(buf, mantissa, n);
+        return;
+    }
+    for (int i = 0; i <= exp || i < (int)n; i++) {
+        if (i == exp + 1) {
+            json_buffer_write(buf, ".", 1);
+        }
+        json_buffer_write(buf, i < (int)n ? &mantissa[i] : "0", 1);
+    }
+}
+
+// json_compare_keys orders pointers into the keys of an object by key
+static int json_compare_keys(const void* a, const void* b) {
+    return strcmp(**(char** const*)a, **(char** const*)b);
+}
+
+static void json_write_value(format_buffer* buf, const omni_json_t* value) {
+    char number[32];
+    switch (value ? value->kind : OMNI_JSON_NULL) {
+    case OMNI_JSON_NULL:
+        json_buffer_write(buf, "null", 4);
+        break;
+    case OMNI_JSON_BOOL:
+        json_buffer_write(buf, value->i ? "true" : "false", value->i ? 4 : 5);
+        break;
+    case OMNI_JSON_INT:
+        snprintf(number, sizeof(number), "%lld", (long long)value->i);
+        json_buffer_write(buf, number, strlen(number));
+        break;
+    case OMNI_JSON_FLOAT:
+        json_write_float(buf, value->f);
+        break;
+    case OMNI_JSON_STRING:
+        json_write_string(buf, value->s);
+        break;
+    case OMNI_JSON_ARRAY:
+        json_buffer_write(buf, "[", 1);
+        for (int32_t i = 0; i < value->count; i++) {
+            if (i > 0) {
+                json_buffer_write(buf, ",", 1);
+            }
+            json_write_value(buf, value->items[i]);
+        }
+        json_buffer_write(buf, "]", 1);
+        break;
+    case OMNI_JSON_OBJECT: {
+        // The keys are written in sorted order, as Go writes those of a map
+        char** order[value->count > 0 ? value->count : 1];
+        for (int32_t i = 0; i < value->count; i++) {
+            order[i] = &value->keys[i];
+        }
+        qsort(order, (size_t)value->count, sizeof(char**), json_compare_keys);
+        json_buffer_write(buf, "{", 1);
+        for (int32_t i = 0; i < value->count; i++) {
+            if (i > 0) {
+                json_buffer_write(buf, ",", 1);
+            }
+            json_write_string(buf, *order[i]);
+            json_buffer_write(buf, ":", 1);
+            json_write_value(buf, value->items[order[i] - value->keys]);
+        }
+        json_buffer_write(buf, "}", 1);
+        break;
+    }
+    }
+}
+
+char* omni_json_stringify(const omni_json_t* value) {
+    format_buffer buf = {malloc(64), 0, 64};
+    if (!buf.data) {
+        return NULL;
+    }
+    buf.data[0] = '\0';
+    json_write_value(&buf, value);
+    return buf.data;
+}
+
+omni_json_t* omni_json_member(const omni_json_t* value, const char* name) {
+    char message[256];
+    if (!value || value->kind != OMNI_JSON_OBJECT) {
+        snprintf(message, sizeof(message), "cannot read field \"%s\" of a JSON %s", name, json_kind_name(value));
+        json_fail("member", message);
+    }
+    for (int32_t i = 0; i < value->count; i++) {
+        if (strcmp(value->keys[i], name) == 0) {
+            return value->items[i];
+        }
+    }
+    snprintf(message, sizeof(message), "JSON object has no field \"%s\"", name);
+    json_fail("member", message);
+    return NULL;
+}
+
+omni_json_t* omni_json_index(const omni_json_t* value, int32_t index) {
+    char message[128];
+    if (!value || value->kind != OMNI_JSON_ARRAY) {
+        snprintf(message, sizeof(message), "cannot index a JSON %s", json_kind_name(value));
+        json_fail("index", message);
+    }
+    if (index < 0 || index >= value->count) {
+        snprintf(message, sizeof(message), "index %d out of bounds for JSON array of length %d", index, value->count);
+        json_fail("index", message);
+    }
+    return value->items[index];
+}
+
+int32_t omni_json_length(const omni_json_t* value) {
+    if (value && (value->kind == OMNI_JSON_ARRAY || value->kind == OMNI_JSON_OBJECT)) {
+        return value->count;
+    }
+    return 0;
+}
+
+static void json_cast_fail(const omni_json_t* value, const char* target) {
+    char message[128];
+    snprintf(message, sizeof(message), "cannot convert a JSON %s to %s", json_kind_name(value), target);
+    json_fail("cast", message);
+}
+
+int64_t omni_json_to_int(const omni_json_t* value) {
+    if (!value || value->kind != OMNI_JSON_INT) {
+        json_cast_fail(value, "int");
+    }
+    return value->i;
+}
+
+double omni_json_to_float(const omni_json_t* value) {
+    if (value && value->kind == OMNI_JSON_INT) {
+        return (double)value->i;
+    }
+    if (!value || value->kind != OMNI_JSON_FLOAT) {
+        json_cast_fail(value, "float");
+    }
+    return value->f;
+}
+
+const char* omni_json_to_string(const omni_json_t* value) {
+    if (!value || value->kind != OMNI_JSON_STRING) {
+        json_cast_fail(value, "string");
+    }
+    return value->s;
+}
+
+int32_t omni_json_to_bool(const omni_json_t* value) {
+    if (!value || value->kind != OMNI_JSON_BOOL) {
+        json_cast_fail(value, "bool");
+    }
+    return (int32_t)value->i;
+}
+
+omni_json_t* omni_json_from_int(int64_t value) {
+    omni_json_t* json = json_new(OMNI_JSON_INT);
+    json->i = value;
+    return json;
+}
+
+omni_json_t* omni_json_from_float(double value) {
+    omni_json_t* json = json_new(OMNI_JSON_FLOAT);
+    json->f = value;
+    return json;
+}
+
+omni_json_t* omni_json_from_string(const char* value) {
+    omni_json_t* json = json_new(OMNI_JSON_STRING);
+    json->s = strdup(value ? value : "");
+    return json;
+}
+
+omni_json_t* omni_json_from_bool(int32_t value) {
+    omni_json_t* json = json_new(OMNI_JSON_BOOL);
+    json->i = value != 0;
+    return json;
+}
+
+// ============================================================================
+// Regular expressions (std.regex)
+// ============================================================================
+
+static char* regex_copy(const char* s, regoff_t start, regoff_t end) {
+    if (start < 0 || end < start) {
+        return strdup("");
+    }
+    char* copy = malloc((size_t)(end - start) + 1);
+    if (copy) {
+        memcpy(copy, s + start, (size_t)(end - start));
+        copy[end - start] = '\0';
+    }
+    return copy;
+}
+
+char* omni_regex_find(const char* pattern, const char* s) {
+    regex_t regex;
+    if (!pattern || !s || regcomp(&regex, pattern, REG_EXTENDED) != 0) {
+        return NULL;
+    }
+    regmatch_t match;
+    char* found = NULL;
+    if (regexec(&regex, s, 1, &match, 0) == 0) {
+        found = regex_copy(s, match.rm_so, match.rm_eo);
+    }
+    regfree(&regex);
+    return found;
+}
+
+char** omni_regex_find_all(const char* pattern, const char* s, int32_t* count_out) {
+    *count_out = 0;
+    regex_t regex;
+    if (!pattern || !s || regcomp(&regex, pattern, REG_EXTENDED) != 0) {
+        return NULL;
+    }
+    char** found = NULL;
+    int32_t count = 0;
+    size_t end = strlen(s);
+    // An empty match right after the previous match is skipped, as Go does
+    long prev_end = -1;
+    for (size_t pos = 0; pos <= end;) {
+        regmatch_t match;
+        if (regexec(&regex, s + pos, 1, &match, pos > 0 ? REG_NOTBOL : 0) != 0) {
+            break;
+        }
+        size_t start = pos + (size_t)match.rm_so;
+        size_t stop = pos + (size_t)match.rm_eo;
+        int accept = 1;
+        if (stop == pos) {
+            if ((long)start == prev_end) {
+                accept = 0;
+            }
+            pos = pos < end ? (size_t)(next_utf8_rune(s + pos + 1) - s) : end + 1;
+        } else {
+            pos = stop;
+        }
+        prev_end = (long)stop;
+        if (!accept) {
+            continue;
+        }
+        char** grown = realloc(found, (size_t)(count + 1) * sizeof(char*));
+        if (!grown) {
+            break;
+        }
+        found = grown;
+        found[count++] = regex_copy(s, (regoff_t)start, (regoff_t)stop);
+    }
+    regfree(&regex);
+    *count_out = count;
+    return found;
+}
+
+char** omni_regex_groups(const char* pattern, const char* s, int32_t* count_out) {
+    *count_out = 0;
+    regex_t regex;
+    if (!pattern || !s || regcomp(&regex, pattern, REG_EXTENDED) != 0) {
+        return NULL;
+    }
+    size_t groups = regex.re_nsub + 1;
+    regmatch_t* matches = malloc(groups * sizeof(regmatch_t));
+    char** found = NULL;
+    if (matches && regexec(&regex, s, groups, matches, 0) == 0) {
+        found = malloc(groups * sizeof(char*));
+        if (found) {
+            // A group that took no part in the match is empty
+            for (size_t i = 0; i < groups; i++) {
+                found[i] = regex_copy(s, matches[i].rm_so, matches[i].rm_eo);
+            }
+            *count_out = (int32_t)groups;
+        }
+    }
+    free(matches);
+    regfree(&regex);
+    return found;
+}
+
+// ============================================================================
+// Subprocesses (std.process)
+// ============================================================================
+
+static omni_struct_t* process_result(const char* out, const char* err, int32_t exit_code) {
+    omni_struct_t* result = omni_struct_create();
+    omni_struct_set_string_field(result, "stdout", out);
+    omni_struct_set_string_field(result, "stderr", err);
+    omni_struct_set_int_field(result, "exit_code", exit_code);
+    return result;
+}
+
+#ifdef _WIN32
+omni_struct_t* omni_process_run(const char* cmd, const char** args, int32_t count) {
+    (void)args;
+    (void)count;
+    char message[256];
+    snprintf(message, sizeof(message), "process: cannot run %s: not supported on Windows\n", cmd ? cmd : "");
+    return process_result("", message, 127);
+}
+#else
+static omni_struct_t* process_start_failed(const char* cmd, int code) {
+    char message[512];
+    snprintf(message, sizeof(message), "process: cannot run %s: %s\n", cmd, strerror(code));
+    return process_result("", message, 127);
+}
+
+omni_struct_t* omni_process_run(const char* cmd, const char** args, int32_t count) {
+    if (!cmd || count < 0) {
+        return process_result("", "process: invalid arguments\n", 127);
+    }
+    const char** argv = malloc((size_t)(count + 2) * sizeof(char*));
+    if (!argv) {
+        return process_start_failed(cmd, ENOMEM);
+    }
+    argv[0] = cmd;
+    for (int32_t i = 0; i < count; i++) {
+        argv[i + 1] = args[i] ? args[i] : "";
+    }
+    argv[count + 1] = NULL;
+
+    int out_pipe[2], err_pipe[2], exec_pipe[2];
+    if (pipe(out_pipe) != 0 || pipe(err_pipe) != 0 || pipe(exec_pipe) != 0) {
+        free(argv);
+        return process_start_failed(cmd, errno);
+    }
+    fflush(stdout);
+    fflush(stderr);
+    pid_t pid = fork();
+    if (pid < 0) {
+        free(argv);
+        return process_start_failed(cmd, errno);
+    }
+    if (pid == 0) {
+        // The exec pipe closes on a successful exec; otherwise it carries
+        // errno back to the parent
+        fcntl(exec_pipe[1], F_SETFD, FD_CLOEXEC);
+        dup2(out_pipe[1], STDOUT_FILENO);
+        dup2(err_pipe[1], STDERR_FILENO);
+        close(out_pipe[0]);
+        close(err_pipe[0]);
+        close(exec_pipe[0]);
+        execvp(cmd, (char* const*)argv);
+        int code = errno;
+        ssize_t written = write(exec_pipe[1], &code, sizeof(code));
+        (void)written;
+        _exit(127);
+    }
+    free(argv);
+    close(out_pipe[1]);
+    close(err_pipe[1]);
+    close(exec_pipe[1]);
+
+    // Read both streams together so that a child filling one pipe does not
+    // block while the other is read
+    format_buffer out = {malloc(256), 0, 256};
+    format_buffer err = {malloc(256), 0, 256};
+    if (!out.data || !err.data) {
+        json_fail("process", "out of memory");
+    }
+    out.data[0] = err.data[0] = '\0';
+    struct pollfd fds[2] = {{out_pipe[0], POLLIN, 0}, {err_pipe[0], POLLIN, 0}};
+    format_buffer* bufs[2] = {&out, &err};
+    int open_fds = 2;
+    while (open_fds > 0) {
+        if (poll(fds, 2, -1) < 0) {
+            if (errno == EINTR) {
+                continue;
+            }
+            break;
+        }
+        for (int i = 0; i < 2; i++) {
+            if (fds[i].fd < 0 || !(fds[i].revents & (POLLIN | POLLHUP | POLLERR))) {
+                continue;
+            }
+            char chunk[4096];
+            ssize_t n = read(fds[i].fd, chunk, sizeof(chunk));
+            if (n > 0) {
+                json_buffer_write(bufs[i], chunk, (size_t)n);
+            } else if (n == 0 || errno != EINTR) {
+                close(fds[i].fd);
+                fds[i].fd = -1;
+                open_fds--;
+            }
+        }
+    }
+    for (int i = 0; i < 2; i++) {
+        if (fds[i].fd >= 0) {
+            close(fds[i].fd);
+        }
+    }
+
+    int exec_errno = 0;
+    ssize_t got = read(exec_pipe[0], &exec_errno, sizeof(exec_errno));
+    close(exec_pipe[0]);
+    int status = 0;
+    while (waitpid(pid, &status, 0) < 0 && errno == EINTR) {
+    }
+
+    omni_struct_t* result;
+    if (got == (ssize_t)sizeof(exec_errno)) {
+        result = process_start_failed(cmd, exec_errno);
+    } else {
+        // A child killed by a signal has no exit code, as in the VM
+        int32_t code = WIFEXITED(status) ? WEXITSTATUS(status) : -1;
+        result = process_result(out.data, err.data, code);
+    }
+    free(out.data);
+    free(err.data);
+    return result;
+}
+#endif
+
+// ============================================================================
+// Paths (std.path). These mirror Go's path/filepath so that the VM and the
+// C backend agree: on Windows both separators are accepted and paths may
+// start with a drive letter or a UNC share.
+// ============================================================================
+
+#ifdef _WIN32
+#define OMNI_PATH_SEP '\\'
+#else
+#define OMNI_PATH_SEP '/'
+#endif
+
+static int path_is_sep(char c) {
+#ifdef _WIN32
+    return c == '/' || c == '\\';
+#else
+    return c == '/';
+#endif
+}
+
+// path_volume_len returns the length of the drive letter or UNC share that
+// starts p, which is always 0 outside Windows
+static size_t path_volume_len(const char* p) {
+#ifdef _WIN32
+    size_t n = strlen(p);
+    if (n >= 2 && p[1] == ':' && isalpha((unsigned char)p[0])) {
+        return 2;
+    }
+    // \\host\share
+    if (n >= 5 && path_is_sep(p[0]) && path_is_sep(p[1]) && !path_is_sep(p[2]) && p[2] != '.') {
+        size_t i = 3;
+        while (i < n && !path_is_sep(p[i])) {
+            i++;
+        }
+        if (i < n) {
+            i++;
+            if (i < n && !path_is_sep(p[i])) {
+                while (i < n && !path_is_sep(p[i])) {
+                    i++;
+                }
+                return i;
+            }
+        }
+    }
+    return 0;
+#else
+    (void)p;
+    return 0;
+#endif
+}
+
+char* omni_path_clean(const char* p) {
+    if (!p) {
+        p = "";
+    }
+    size_t vol = path_volume_len(p);
+    size_t n = strlen(p);
+    const char* rest = p + vol;
+    size_t rn = n - vol;
+    // The result is no longer than the input plus a "."
+    char* out = malloc(n + 2);
+    if (!out) {
+        return NULL;
+    }
+    memcpy(out, p, vol);
+    if (rn == 0) {
+        if (vol > 1 && path_is_sep(p[0]) && path_is_sep(p[1])) {
+            out[vol] = '\0';
+        } else {
+            out[vol] = '.';
+            out[vol + 1] = '\0';
+        }
+        return out;
+    }
+    char* buf = out + vol;
+    int rooted = path_is_sep(rest[0]);
+    size_t w = 0, r = 0, dotdot = 0;
+    if (rooted) {
+        buf[w++] = OMNI_PATH_SEP;
+        r = dotdot = 1;
+    }
+    while (r < rn) {
+        if (path_is_sep(rest[r])) {
+            r++;
+        } else if (rest[r] == '.' && (r + 1 == rn || path_is_sep(rest[r + 1]))) {
+            r++;
+        } else if (rest[r] == '.' && rest[r + 1] == '.' && (r + 2 == rn || path_is_sep(rest[r + 2]))) {
+            r += 2;
+            if (w > dotdot) {
+                w--;
+                while (w > dotdot && !path_is_sep(buf[w])) {
+                    w--;
+                }
+            } else if (!rooted) {
+                if (w > 0) {
+                    buf[w++] = OMNI_PATH_SEP;
+                }
+                buf[w++] = '.';
+                buf[w++] = '.';
+                dotdot = w;
+            }
+        } else {
+            if ((rooted && w != 1) || (!rooted && w != 0)) {
+                buf[w++] = OMNI_PATH_SEP;
+            }
+            while (r < rn && !path_is_sep(rest[r])) {
+                buf[w++] = rest[r++];
+            }
+        }
+    }
+    if (w == 0) {
+        buf[w++] = '.';
+    }
+    buf[w] = '\0';
+    return out;
+}
+
+char* omni_path_join(const char** parts, int32_t count) {
+    size_t len = 0;
+    for (int32_t i = 0; i < count; i++) {
+        if (parts[i]) {
+            len += strlen(parts[i]) + 1;
+        }
+    }
+    char* joined = malloc(len + 1);
+    if (!joined) {
+        return NULL;
+    }
+    // Empty parts are skipped; joining nothing but empty parts gives ""
+    size_t w = 0;
+    for (int32_t i = 0; i < count; i++) {
+        if (!parts[i] || parts[i][0] == '\0') {
+            continue;
+        }
+        if (w > 0) {
+            joined[w++] = OMNI_PATH_SEP;
+        }
+        size_t n = strlen(parts[i]);
+        memcpy(joined + w, parts[i], n);
+        w += n;
+    }
+    joined[w] = '\0';
+    if (w == 0) {
+        return joined;
+    }
+    char* cleaned = omni_path_clean(joined);
+    free(joined);
+    return cleaned;
+}
+
+char* omni_path_dirname(const char* p) {
+    if (!p) {
+        p = "";
+    }
+    size_t vol = path_volume_len(p);
+    size_t i = strlen(p);
+    while (i > vol && !path_is_sep(p[i - 1])) {
+        i--;
+    }
+    char* dir = malloc(i + 1);
+    if (!dir) {
+        return NULL;
+    }
+    memcpy(dir, p + vol, i - vol);
+    dir[i - vol] = '\0';
+    char* cleaned = omni_path_clean(dir);
+    free(dir);
+    if (!cleaned) {
+        return NULL;
+    }
+    // A UNC share is its own directory
+    if (strcmp(cleaned, ".") == 0 && vol > 2) {
+        cleaned[0] = '\0';
+    }
+    char* result = malloc(vol + strlen(cleaned) + 1);
+    if (result) {
+        memcpy(result, p, vol);
+        strcpy(result + vol, cleaned);
+    }
+    free(cleaned);
+    return result;
+}
+
+char* omni_path_basename(const char* p) {
+    if (!p || p[0] == '\0') {
+        return strdup(".");
+    }
+    size_t end = strlen(p);
+    while (end > 0 && path_is_sep(p[end - 1])) {
+        end--;
+    }
+    size_t start = path_volume_len(p);
+    if (start > end) {
+        start = end;
+    }
+    size_t i = end;
+    while (i > start && !path_is_sep(p[i - 1])) {
+        i--;
+    }
+    if (i == end) {
+        char sep[2] = {OMNI_PATH_SEP, '\0'};
+        return strdup(sep);
+    }
+    char* base = malloc(end - i + 1);
+    if (base) {
+        memcpy(base, p + i, end - i);
+        base[end - i] = '\0';
+    }
+    return base;
+}
+
+char* omni_path_ext(const char* p) {
+    if (!p) {
+        return strdup("");
+    }
+    for (size_t i = strlen(p); i > 0 && !path_is_sep(p[i - 1]); i--) {
+        if (p[i - 1] == '.') {
+            return strdup(p + i - 1);
+        }
+    }
+    return strdup("");
+}
+
+int32_t omni_path_is_abs(const char* p) {
+    if (!p) {
+        return 0;
+    }
+#ifdef _WIN32
+    size_t vol = path_volume_len(p);
+    if (vol == 0) {
+        return 0;
+    }
+    // A UNC share is absolute; a drive needs the separator after it
+    if (vol > 2 || path_is_sep(p[0])) {
+        return 1;
+    }
+    return path_is_sep(p[vol]);
+#else
+    return p[0] == '/';
+#endif
+}
+
+char* omni_path_abs(const char* p) {
+    if (omni_path_is_abs(p)) {
+        return omni_path_clean(p);
+    }
+    char cwd[4096];
+#ifdef _WIN32
+    if (!_getcwd(cwd, sizeof(cwd))) {
+#else
+    if (!getcwd(cwd, sizeof(cwd))) {
+#endif
+        return omni_path_clean(p);
+    }
+    const char* parts[2] = {cwd, p};
+    return omni_path_join(parts, 2);
+}
+
+// ============================================================================
+// Environment variables (std.env). A variable that is set but empty counts
+// as set; a value that does not parse gives the default, as in the VM.
+// ============================================================================
+
+void omni_panic(const char* message) {
+    fflush(stdout);
+    fprintf(stderr, "%s\n", message ? message : "panic");
+    exit(1);
+}
+
+const char* omni_env_get_string(const char* name, const char* default_value) {
+    const char* value = name ? getenv(name) : NULL;
+    return value ? value : default_value;
+}
+
+int32_t omni_env_get_int(const char* name, int32_t default_value) {
+    const char* value = name ? getenv(name) : NULL;
+    if (!value || value[0] == '\0' || isspace((unsigned char)value[0])) {
+        return default_value;
+    }
+    errno = 0;
+    char* end = NULL;
+    long long parsed = strtoll(value, &end, 10);
+    if (errno != 0 || *end != '\0' || parsed < INT32_MIN || parsed > INT32_MAX) {
+        return default_value;
+    }
+    return (int32_t)parsed;
+}
+
+int32_t omni_env_get_bool(const char* name, int32_t default_value) {
+    const char* value = name ? getenv(name) : NULL;
+    if (!value) {
+        return default_value;
+    }
+    // The spellings Go's strconv.ParseBool accepts
+    static const char* truths[] = {"1", "t", "T", "true", "TRUE", "True"};
+    static const char* falsehoods[] = {"0", "f", "F", "false", "FALSE", "False"};
+    for (size_t i = 0; i < sizeof(truths) / sizeof(truths[0]); i++) {
+        if (strcmp(value, truths[i]) == 0) {
+            return 1;
+        }
+        if (strcmp(value, falsehoods[i]) == 0) {
+            return 0;
+        }
+    }
+    return default_value;
+}
+
+const char* omni_env_require(const char* name) {
+    const char* value = name ? getenv(name) : NULL;
+    if (!value) {
+        char message[512];
+        snprintf(message, sizeof(message), "env.require: environment variable \"%s\" is not set", name ? name : "");
+        omni_panic(message);
+    }
+    return value;
+}
+
+// ============================================================================
+// Sorting (std.sort). Arrays are sorted in place, in the order Go's sort
+// package uses: strings by their bytes and NaN before every other float.
+// ============================================================================
+
+static int sort_compare_ints(const void* a, const void* b) {
+    int32_t x = *(const int32_t*)a, y = *(const int32_t*)b;
+    return (x > y) - (x < y);
+}
+
+static int sort_compare_strings(const void* a, const void* b) {
+    const char* x = *(const char* const*)a;
+    const char* y = *(const char* const*)b;
+    return strcmp(x ? x : "", y ? y : "");
+}
+
+static int sort_compare_floats(const void* a, const void* b) {
+    double x = *(const double*)a, y = *(const double*)b;
+    if (isnan(x) || isnan(y)) {
+        return !isnan(y) ? -1 : !isnan(x);
+    }
+    return (x > y) - (x < y);
+}
+
+static int sort_compare_ints_reversed(const void* a, const void* b) {
+    return sort_compare_ints(b, a);
+}
+
+static int sort_compare_strings_reversed(const void* a, const void* b) {
+    return sort_compare_strings(b, a);
+}
+
+void omni_sort_ints(int32_t* arr, int32_t count) {
+    if (arr && count > 1) {
+        qsort(arr, (size_t)count, sizeof(int32_t), sort_compare_ints);
+    }
+}
+
+void omni_sort_strings(const char** arr, int32_t count) {
+    if (arr && count > 1) {
+        qsort(arr, (size_t)count, sizeof(const char*), sort_compare_strings);
+    }
+}
+
+void omni_sort_floats(double* arr, int32_t count) {
+    if (arr && count > 1) {
+        qsort(arr, (size_t)count, sizeof(double), sort_compare_floats);
+    }
+}
+
+void omni_sort_reverse_ints(int32_t* arr, int32_t count) {
+    if (arr && count > 1) {
+        qsort(arr, (size_t)count, sizeof(int32_t), sort_compare_ints_reversed);
+    }
+}
+
+void omni_sort_reverse_strings(const char** arr, int32_t count) {
+    if (arr && count > 1) {
+        qsort(arr, (size_t)count, sizeof(const char*), sort_compare_strings_reversed);
+    }
+}
+
+// ============================================================================
+// Network Functions Implementation
+// ============================================================================
+
+// IP address functions
+omni_ip_address_t* omni_ip_parse(const char* ip_str) {
+    if (!ip_str) return NULL;
+    omni_ip_address_t* ip = (omni_ip_address_t*)malloc(sizeof(omni_ip_address_t));
+    if (!ip) return NULL;
+    
+    strncpy(ip->address, ip_str, sizeof(ip->address) - 1);
+    ip->address[sizeof(ip->address) - 1] = '\0';
+    
+    // Simple IPv4 detection (contains dots and no colons)
+    ip->is_ipv4 = (strchr(ip_str, '.') != NULL && strchr(ip_str, ':') == NULL) ? 1 : 0;
+    ip->is_ipv6 = (strchr(ip_str, ':') != NULL) ? 1 : 0;
+    
+    return ip;
+}
+
+int32_t omni_ip_is_valid(const char* ip_str) {
+    if (!ip_str) return 0;
+    // Basic validation - check for IPv4 format (dotted decimal)
+    int dot_count = 0;
+    int digit_count = 0;
+    for (const char* p = ip_str; *p; p++) {
+        if (*p == '.') {
+            dot_count++;
+            digit_count = 0;
+        } else if (*p >= '0' && *p <= '9') {
+            digit_count++;
+            if (digit_count > 3) return 0;
+        } else if (*p == ':') {
+            // IPv6 format
+            return 1; // Basic check - assume valid if contains colon
+        } else {
+            return 0;
+        }
+    }
+    return (dot_count == 3) ? 1 : 0;
+}
+
+int32_t omni_ip_is_private(omni_ip_address_t* ip) {
+    if (!ip || !ip->is_ipv4) return 0;
+    // Check for private IP ranges: 10.x.x.x, 172.16-31.x.x, 192.168.x.x
+    if (strncmp(ip->address, "10.", 3) == 0) return 1;
+    if (strncmp(ip->address, "172.16.", 7) == 0 || strncmp(ip->address, "172.17.", 7) == 0 ||
+        strncmp(ip->address, "172.18.", 7) == 0 || strncmp(ip->address, "172.19.", 7) == 0 ||
+        strncmp(ip->address, "172.20.", 7) == 0 || strncmp(ip->address, "172.21.", 7) == 0 ||
+        strncmp(ip->address, "172.22.", 7) == 0 || strncmp(ip->address, "172.23.", 7) == 0 ||
+        strncmp(ip->address, "172.24.", 7) == 0 || strncmp(ip->address, "172.25.", 7) == 0 ||
+        strncmp(ip->address, "172.26.", 7) == 0 || strncmp(ip->address, "172.27.", 7) == 0 ||
+        strncmp(ip->address, "172.28.", 7) == 0 || strncmp(ip->address, "172.29.", 7) == 0 ||
+        strncmp(ip->address, "172.30.", 7) == 0 || strncmp(ip->address, "172.31.", 7) == 0) return 1;
+    if (strncmp(ip->address, "192.168.", 8) == 0) return 1;
+    return 0;
+}
+
+int32_t omni_ip_is_loopback(omni_ip_address_t* ip) {
+    if (!ip || !ip->is_ipv4) return 0;
+    return (strncmp(ip->address, "127.", 4) == 0) ? 1 : 0;
+}
+
+char* omni_ip_to_string(omni_ip_address_t* ip) {
+    if (!ip) return NULL;
+    return strdup(ip->address);
+}
+
+// URL functions
+omni_url_t* omni_url_parse(const char* url_str) {
+    if (!url_str) return NULL;
+    omni_url_t* url = (omni_url_t*)malloc(sizeof(omni_url_t));
+    if (!url) return NULL;
+    
+    // Initialize with defaults
+    strncpy(url->scheme, "http", sizeof(url->scheme) - 1);
+    strncpy(url->host, "localhost", sizeof(url->host) - 1);
+    url->port = 80;
+    strncpy(url->path, "/", sizeof(url->path) - 1);
+    url->query[0] = '\0';
+    url->fragment[0] = '\0';
+    
+    // Basic parsing
+    const char* scheme_end = strstr(url_str, "://");
+    if (scheme_end) {
+        size_t scheme_len = scheme_end - url_str;
+        if (scheme_len < sizeof(url->scheme)) {
+            strncpy(url->scheme, url_str, scheme_len);
+            url->scheme[scheme_len] = '\0';
+        }
+        url_str = scheme_end + 3;
+    }
+    
+    // Extract host and port
+    const char* path_start = strchr(url_str, '/');
+    const char* port_start = strchr(url_str, ':');
+    if (port_start && (!path_start || port_start < path_start)) {
+        size_t host_len = port_start - url_str;
+        if (host_len < sizeof(url->host)) {
+            strncpy(url->host, url_str, host_len);
+            url->host[host_len] = '\0';
+        }
+        sscanf(port_start + 1, "%d", &url->port);
+        if (path_start) {
+            strncpy(url->path, path_start, sizeof(url->path) - 1);
+        }
+    } else if (path_start) {
+        size_t host_len = path_start - url_str;
+        if (host_len < sizeof(url->host)) {
+            strncpy(url->host, url_str, host_len);
+            url->host[host_len] = '\0';
+        }
+        strncpy(url->path, path_start, sizeof(url->path) - 1);
+    } else {
+        strncpy(url->host, url_str, sizeof(url->host) - 1);
+    }
+    
+    return url;
+}
+
+char* omni_url_to_string(omni_url_t* url) {
+    if (!url) return NULL;
+    char* result = (char*)malloc(1024);
+    if (!result) return NULL;
+    
+    snprintf(result, 1024, "%s://%s", url->scheme, url->host);
+    if (url->port > 0 && url->port != 80 && url->port != 443) {
+        char temp[1024];
+        snprintf(temp, 1024, "%s:%d%s", result, url->port, url->path);
+        strncpy(result, temp, 1024);
+    } else {
+        strncat(result, url->path, 1024 - strlen(result) - 1);
+    }
+    if (url->query[0] != '\0') {
+        strncat(result, "?", 1024 - strlen(result) - 1);
+        strncat(result, url->query, 1024 - strlen(result) - 1);
+    }
+    if (url->fragment[0] != '\0') {
+        strncat(result, "#", 1024 - strlen(result) - 1);
+        strncat(result, url->fragment, 1024 - strlen(result) - 1);
+    }
+    return result;
+}
+
+int32_t omni_url_is_valid(const char* url_str) {
+    if (!url_str) return 0;
+    // Basic validation - check for scheme://host format
+    return (strstr(url_str, "://") != NULL) ? 1 : 0;
+}
+
+// DNS functions (stub implementations - would need actual DNS library)
+omni_ip_address_t** omni_dns_lookup(const char* hostname, int32_t* count) {
+    if (!hostname || !count) return NULL;
+    *count = 0;
+    // Stub: would need getaddrinfo or similar
+    return NULL;
+}
+
+char* omni_dns_reverse_lookup(omni_ip_address_t* ip) {
+    if (!ip) return NULL;
+    // Stub: would need getnameinfo or similar
+    return strdup("");
+}
+
+// HTTP client functions (stub implementations - would need HTTP library like libcurl)
+omni_http_response_t* omni_http_get(const char* url) {
+    if (!url) return NULL;
+    omni_http_response_t* resp = (omni_http_response_t*)malloc(sizeof(omni_http_response_t));
+    if (!resp) return NULL;
+    resp->status_code = 200;
+    strncpy(resp->status_text, "OK", sizeof(resp->status_text) - 1);
+    resp->headers = omni_map_create();
+    resp->body = strdup("");
+    return resp;
+}
+
+omni_http_response_t* omni_http_post(const char* url, const char* body) {
+    (void)body; // Unused in stub implementation
+    return omni_http_get(url); // Stub
+}
+
+omni_http_response_t* omni_http_put(const char* url, const char* body) {
+    (void)body; // Unused in stub implementation
+    return omni_http_get(url); // Stub
+}
+
+omni_http_response_t* omni_http_delete(const char* url) {
+    return omni_http_get(url); // Stub
+}
+
+omni_http_response_t* omni_http_request(omni_http_request_t* req) {
+    if (!req) return NULL;
+    return omni_http_get(req->url);
+}
+
+void omni_http_response_destroy(omni_http_response_t* resp) {
+    if (!resp) return;
+    if (resp->headers) omni_map_destroy(resp->headers);
+    if (resp->body) free(resp->body);
+    free(resp);
+}
+
+int32_t omni_http_response_is_success(omni_http_response_t* resp) {
+    if (!resp) return 0;
+    return (resp->status_code >= 200 && resp->status_code < 300) ? 1 : 0;
+}
+
+int32_t omni_http_response_is_client_error(omni_http_response_t* resp) {
+    if (!resp) return 0;
+    return (resp->status_code >= 400 && resp->status_code < 500) ? 1 : 0;
+}
+
+int32_t omni_http_response_is_server_error(omni_http_response_t* resp) {
+    if (!resp) return 0;
+    return (resp->status_code >= 500 && resp->status_code < 600) ? 1 : 0;
+}
+
+char* omni_http_response_get_header(omni_http_response_t* resp, const char* name) {
+    if (!resp || !resp->headers || !name) return NULL;
+    const char* value = omni_map_get_string_string(resp->headers, name);
+    return value ? strdup(value) : NULL;
+}
+
+omni_http_request_t* omni_http_request_create(const char* method, const char* url) {
+    if (!method || !url) return NULL;
+    omni_http_request_t* req = (omni_http_request_t*)malloc(sizeof(omni_http_request_t));
+    if (!req) return NULL;
+    strncpy(req->method, method, sizeof(req->method) - 1);
+    strncpy(req->url, url, sizeof(req->url) - 1);
+    req->headers = omni_map_create();
+    req->body = NULL;
+    return req;
+}
+
+void omni_http_request_set_header(omni_http_request_t* req, const char* name, const char* value) {
+    if (!req || !req->headers || !name || !value) return;
+    omni_map_put_string_string(req->headers, name, value);
+}
+
+void omni_http_request_set_body(omni_http_request_t* req, const char* body) {
+    if (!req) return;
+    if (req->body) free(req->body);
+    req->body = body ? strdup(body) : NULL;
+}
+
+char* omni_http_request_get_header(omni_http_request_t* req, const char* name) {
+    if (!req || !req->headers || !name) return NULL;
+    const char* value = omni_map_get_string_string(req->headers, name);
+    return value ? strdup(value) : NULL;
+}
+
+void omni_http_request_destroy(omni_http_request_t* req) {
+    if (!req) return;
+    if (req->headers) omni_map_destroy(req->headers);
+    if (req->body) free(req->body);
+    free(req);
+}
+
+// Socket functions (stub implementations - would need socket library)
+#ifdef _WIN32
+#include <winsock2.h>
+#include <ws2tcpip.h>
+#else
+#include <sys/socket.h>
+#include <netinet/in.h>
+#include <arpa/inet.h>
+#include <netdb.h>
+#include <unistd.h>
+#endif
+
+int32_t omni_socket_create() {
+#ifdef _WIN32
+    WSADATA wsa;
+    if (WSAStartup(MAKEWORD(2, 2), &wsa) != 0) return -1;
+    return socket(AF_INET, SOCK_STREAM, 0);
+#else
+    return socket(AF_INET, SOCK_STREAM, 0);
+#endif
+}
+
+int32_t omni_socket_connect(int32_t socket, const char* address, int32_t port) {
+    if (socket < 0 || !address) return 0;
+    struct sockaddr_in addr;
+    memset(&addr, 0, sizeof(addr));
+    addr.sin_family = AF_INET;
+    addr.sin_port = htons(port);
+    inet_pton(AF_INET, address, &addr.sin_addr);
+    return (connect(socket, (struct sockaddr*)&addr, sizeof(addr)) == 0) ? 1 : 0;
+}
+
+int32_t omni_socket_bind(int32_t socket, const char* address, int32_t port) {
+    if (socket < 0 || !address) return 0;
+    struct sockaddr_in addr;
+    memset(&addr, 0, sizeof(addr));
+    addr.sin_family = AF_INET;
+    addr.sin_port = htons(port);
+    inet_pton(AF_INET, address, &addr.sin_addr);
+    return (bind(socket, (struct sockaddr*)&addr, sizeof(addr)) == 0) ? 1 : 0;
+}
+
+int32_t omni_socket_listen(int32_t socket, int32_t backlog) {
+    if (socket < 0) return 0;
+    return (listen(socket, backlog) == 0) ? 1 : 0;
+}
+
+int32_t omni_socket_accept(int32_t socket) {
+    if (socket < 0) return -1;
+    struct sockaddr_in addr;
+    socklen_t len = sizeof(addr);
+    return accept(socket, (struct sockaddr*)&addr, &len);
+}
+
+int32_t omni_socket_send(int32_t socket, const char* data) {
+    if (socket < 0 || !data) return -1;
+    return send(socket, data, strlen(data), 0);
+}
+
+int32_t omni_socket_receive(int32_t socket, char* buffer, int32_t buffer_size) {
+    if (socket < 0 || !buffer || buffer_size <= 0) return -1;
+    ssize_t received = recv(socket, buffer, (size_t)(buffer_size - 1), 0);
+    if (received > 0) {
+        buffer[received] = '\0';
+    }
+    return (int32_t)received;
+}
+
+int32_t omni_socket_close(int32_t socket) {
+    if (socket < 0) return 0;
+#ifdef _WIN32
+    return (closesocket(socket) == 0) ? 1 : 0;
+#else
+    return (close(socket) == 0) ? 1 : 0;
+#endif
+}
+
+// Network utility functions
+int32_t omni_network_is_connected() {
+    // Stub: would need to check network interface status
+    return 1; // Assume connected
+}
+
+omni_ip_address_t* omni_network_get_local_ip() {
+    omni_ip_address_t* ip = (omni_ip_address_t*)malloc(sizeof(omni_ip_address_t));
+    if (!ip) return NULL;
+    strncpy(ip->address, "127.0.0.1", sizeof(ip->address) - 1);
+    ip->is_ipv4 = 1;
+    ip->is_ipv6 = 0;
+    return ip;
+}
+
+int32_t omni_network_ping(const char* host) {
+    if (!host) return 0;
+    // Stub: would need ICMP ping implementation
+    return 0;
+}
+
+// ============================================================================
+// Coverage Tracking Infrastructure
+// ============================================================================
+
+#define OMNI_COVERAGE_MAX_ENTRIES 10000
+#define OMNI_COVERAGE_MAX_FUNCTION_NAME 256
+#define OMNI_COVERAGE_MAX_FILE_PATH 512
+
+typedef struct {
+    char function_name[OMNI_COVERAGE_MAX_FUNCTION_NAME];
+    char file_path[OMNI_COVERAGE_MAX_FILE_PATH];
+    int32_t line_number;
+    int32_t call_count;
+} omni_coverage_entry_t;
+
+static struct {
+    omni_coverage_entry_t entries[OMNI_COVERAGE_MAX_ENTRIES];
+    int32_t count;
+    int32_t enabled;
+#ifdef _WIN32
+    CRITICAL_SECTION mutex;
+    int mutex_initialized;
+#else
+    pthread_mutex_t mutex;
+#endif
+} omni_coverage_state = {
+    .count = 0,
+    .enabled = 0,
+#ifdef _WIN32
+    .mutex_initialized = 0
+#else
+    .mutex = PTHREAD_MUTEX_INITIALIZER
+#endif
+};
+
+void omni_coverage_init(void) {
+#ifdef _WIN32
+    if (!omni_coverage_state.mutex_initialized) {
+        InitializeCriticalSection(&omni_coverage_state.mutex);
+        omni_coverage_state.mutex_initialized = 1;
+    }
+    EnterCriticalSection(&omni_coverage_state.mutex);
+#else
+    pthread_mutex_lock(&omni_coverage_state.mutex);
+#endif
+    
+    omni_coverage_state.count = 0;
+    omni_coverage_state.enabled = 1;
+    
+#ifdef _WIN32
+    LeaveCriticalSection(&omni_coverage_state.mutex);
+#else
+    pthread_mutex_unlock(&omni_coverage_state.mutex);
+#endif
+}
+
+void omni_coverage_set_enabled(int32_t enabled) {
+#ifdef _WIN32
+    if (!omni_coverage_state.mutex_initialized) {
+        InitializeCriticalSection(&omni_coverage_state.mutex);
+        omni_coverage_state.mutex_initialized = 1;
+    }
+    EnterCriticalSection(&omni_coverage_state.mutex);
+#else
+    pthread_mutex_lock(&omni_coverage_state.mutex);
+#endif
+    
+    omni_coverage_state.enabled = enabled;
+    
+#ifdef _WIN32
+    LeaveCriticalSection(&omni_coverage_state.mutex);
+#else
+    pthread_mutex_unlock(&omni_coverage_state.mutex);
+#endif
+}
+
+int32_t omni_coverage_is_enabled(void) {
+    return omni_coverage_state.enabled;
+}
+
+void omni_coverage_record(const char* function_name, const char* file_path, int32_t line_number) {
+    if (!omni_coverage_state.enabled || !function_name) {
+        return;
+    }
+    
+#ifdef _WIN32
+    if (!omni_coverage_state.mutex_initialized) {
+        InitializeCriticalSection(&omni_coverage_state.mutex);
+        omni_coverage_state.mutex_initialized = 1;
+    }
+    EnterCriticalSection(&omni_coverage_state.mutex);
+#else
+    pthread_mutex_lock(&omni_coverage_state.mutex);
+#endif
+    
+    // Check if entry already exists
+    int32_t found = 0;
+    for (int32_t i = 0; i < omni_coverage_state.count; i++) {
+        if (strcmp(omni_coverage_state.entries[i].function_name, function_name) == 0 &&
+            (file_path == NULL || strcmp(omni_coverage_state.entries[i].file_path, file_path) == 0) &&
+            omni_coverage_state.entries[i].line_number == line_number) {
+            omni_coverage_state.entries[i].call_count++;
+            found = 1;
+            break;
+        }
+    }
+    
+    // Add new entry if not found and we have space
+    if (!found && omni_coverage_state.count < OMNI_COVERAGE_MAX_ENTRIES) {
+        omni_coverage_entry_t* entry = &omni_coverage_state.entries[omni_coverage_state.count];
+        strncpy(entry->function_name, function_name, OMNI_COVERAGE_MAX_FUNCTION_NAME - 1);
+        entry->function_name[OMNI_COVERAGE_MAX_FUNCTION_NAME - 1] = '\0';
+        
+        if (file_path) {
+            strncpy(entry->file_path, file_path, OMNI_COVERAGE_MAX_FILE_PATH - 1);
+            entry->file_path[OMNI_COVERAGE_MAX_FILE_PATH - 1] = '\0';
+        } else {
+            entry->file_path[0] = '\0';
+        }
+        
+        entry->line_number = line_number;
+        entry->call_count = 1;
+        omni_coverage_state.count++;
+    }
+    
+#ifdef _WIN32
+    LeaveCriticalSection(&omni_coverage_state.mutex);
+#else
+    pthread_mutex_unlock(&omni_coverage_state.mutex);
+#endif
+}
+
+void omni_coverage_reset(void) {
+#ifdef _WIN32
+    if (!omni_coverage_state.mutex_initialized) {
+        InitializeCriticalSection(&omni_coverage_state.mutex);
+        omni_coverage_state.mutex_initialized = 1;
+    }
+    EnterCriticalSection(&omni_coverage_state.mutex);
+#else
+    pthread_mutex_lock(&omni_coverage_state.mutex);
+#endif
+    
+    omni_coverage_state.count = 0;
+    
+#ifdef _WIN32
+    LeaveCriticalSection(&omni_coverage_state.mutex);
+#else
+    pthread_mutex_unlock(&omni_coverage_state.mutex);
+#endif
+}
+
+char* omni_coverage_export(void) {
+    // Estimate buffer size: each entry needs ~200 bytes for JSON
+    size_t buffer_size = 1024 + (omni_coverage_state.count * 300);
+    char* json = malloc(buffer_size);
+    if (!json) {
+        return NULL;
+    }
+    
+#ifdef _WIN32
+    if (!omni_coverage_state.mutex_initialized) {
+        InitializeCriticalSection(&omni_coverage_state.mutex);
+        omni_coverage_state.mutex_initialized = 1;
+    }
+    EnterCriticalSection(&omni_coverage_state.mutex);
+#else
+    pthread_mutex_lock(&omni_coverage_state.mutex);
+#endif
+    
+    size_t pos = 0;
+    pos += snprintf(json + pos, buffer_size - pos, "{\"entries\":[");
+    
+    for (int32_t i = 0; i < omni_coverage_state.count; i++) {
+        if (i > 0) {
+            pos += snprintf(json + pos, buffer_size - pos, ",");
+        }
+        omni_coverage_entry_t* entry = &omni_coverage_state.entries[i];
+        pos += snprintf(json + pos, buffer_size - pos,
+            "{\"function\":\"%s\",\"file\":\"%s\",\"line\":%d,\"count\":%d}",
+            entry->function_name,
+            entry->file_path,
+            entry->line_number,
+            entry->call_count);
+        
+        if (pos >= buffer_size - 100) {
+            // Resize buffer if needed
+            buffer_size *= 2;
+            char* new_json = realloc(json, buffer_size);
+            if (!new_json) {
+                free(json);
+#ifdef _WIN32
+                LeaveCriticalSection(&omni_coverage_state.mutex);
+#else
+                pthread_mutex_unlock(&omni_coverage_state.mutex);
+#endif
+                return NULL;
+            }
+            json = new_json;
+        }
+    }
+    
+    pos += snprintf(json + pos, buffer_size - pos, "]}");
+    
+#ifdef _WIN32
+    LeaveCriticalSection(&omni_coverage_state.mutex);
+#else
+    pthread_mutex_unlock(&omni_coverage_state.mutex);
+#endif
+    
+    return json;
+}
@@ -1,6 +1,7 @@
 #ifndef OMNI_RT_H
 #define OMNI_RT_H
 
+#include <setjmp.h>
 #include <stdint.h>
 #include <stdio.h>
 
@@ -10,7 +11,17 @@
 // Basic I/O functions
 void omni_print_string(const char* str);
 void omni_println_string(const char* str);
+// omni_write_string writes str to stdout as it is, without a newline
+void omni_write_string(const char* str);
+void omni_eprint_string(const char* str);
+void omni_eprintln_string(const char* str);
 char* omni_read_line(void);
+// omni_prompt writes message to stdout without a newline, then reads a line.
+// The numeric prompts ask up to three times and return default_val when no
+// valid number was entered.
+char* omni_prompt(const char* message);
+int32_t omni_prompt_int(const char* message, int32_t default_val);
+double omni_prompt_float(const char* message, double default_val);
 
 // Logging functions
 void omni_log_debug(const char* message);
@@ -40,19 +51,124 @@ char* omni_to_upper(const char* str);
 char* omni_to_lower(const char* str);
 int32_t omni_string_equals(const char* a, const char* b);
 int32_t omni_string_compare(const char* a, const char* b);
+// Splits s around sep and stores the number of pieces in count_out
+char** omni_string_split(const char* s, const char* sep, int32_t* count_out);
+// Repetition and padding; widths count bytes. A negative n or a pad that is
+// not a single character exits through omni_panic
+char* omni_string_repeat(const char* s, int32_t n);
+char* omni_string_pad_left(const char* s, int32_t width, const char* pad);
+char* omni_string_pad_right(const char* s, int32_t width, const char* pad);
+char* omni_string_center(const char* s, int32_t width, const char* pad);
+// Replacement and counting of substrings; occurrences do not overlap and are
+// found from the left. An empty old or substr matches nothing
+char* omni_string_replace_first(const char* s, const char* old, const char* replacement);
+char* omni_string_replace_all(const char* s, const char* old, const char* replacement);
+int32_t omni_string_count_occurrences(const char* s, const char* substr);
+// Parsing of a whole string as a value, as strconv does in the VM. They
+// return 1 and store the value in out, or return 0 and store zero when s does
+// not hold one; ints must fit in 32 bits
+int32_t omni_parse_int(const char* s, int32_t* out);
+int32_t omni_parse_float(const char* s, double* out);
+int32_t omni_parse_bool(const char* s, int32_t* out);
+
+// An argument of omni_string_format, tagged with its kind
+enum { OMNI_FORMAT_INT, OMNI_FORMAT_FLOAT, OMNI_FORMAT_STRING, OMNI_FORMAT_BOOL };
+typedef struct {
+    int32_t kind;
+    int64_t i;
+    double f;
+    const char* s;
+} omni_format_arg;
+omni_format_arg omni_format_int(int64_t value);
+omni_format_arg omni_format_float(double value);
+omni_format_arg omni_format_string(const char* value);
+omni_format_arg omni_format_bool(int32_t value);
+// Substitutes the count args for the {} placeholders of tmpl
+// Returns a newly allocated string - caller must free it
+char* omni_string_format(const char* tmpl, int32_t count, const omni_format_arg* args);
+
+// Command history (std.io.readline_history); one command per line on disk
+void omni_history_load(const char* path);
+void omni_history_save(const char* path);
+void omni_history_add(const char* line);
+// Returns the entry owned by the history, valid until the next load or add; NULL if out of range
+const char* omni_history_get(int32_t index);
+int32_t omni_history_count(void);
+void omni_history_set_max_entries(int32_t limit);
+
+// Diff functions (Myers' algorithm, unified format)
+// Both return a newly allocated string - caller must free it
+char* omni_diff_unified(const char* original, const char* modified, int32_t context);
+char* omni_diff_apply(const char* original, const char* patch);
+
+// Hash functions
+// Digest functions return a newly allocated lowercase hex string - caller must free it
+char* omni_hash_sha256(const char* str);
+char* omni_hash_sha512(const char* str);
+char* omni_hash_md5(const char* str);
+int32_t omni_hash_fnv32(const char* str);
+int64_t omni_hash_fnv64(const char* str);
+int32_t omni_hash_murmur3(const char* str);
+
+// JSON (std.json)
+// A parsed document is a tree of omni_json_t values, which the any type of
+// OmniLang maps to. Trees are never freed, like escaping arrays.
+enum {
+    OMNI_JSON_NULL, OMNI_JSON_BOOL, OMNI_JSON_INT, OMNI_JSON_FLOAT,
+    OMNI_JSON_STRING, OMNI_JSON_ARRAY, OMNI_JSON_OBJECT
+};
+typedef struct omni_json {
+    int32_t kind;
+    int64_t i;              // OMNI_JSON_BOOL and OMNI_JSON_INT
+    double f;               // OMNI_JSON_FLOAT
+    char* s;                // OMNI_JSON_STRING
+    int32_t count;          // elements of an array, fields of an object
+    struct omni_json** items;
+    char** keys;            // field names of an object
+} omni_json_t;
+// Exits with an error for malformed JSON
+omni_json_t* omni_json_parse(const char* s);
+// Returns the compact encoding with object keys sorted - caller must free it
+char* omni_json_stringify(const omni_json_t* value);
+// Reading a missing field, an element out of range or a value of the wrong
+// kind exits with an error
+omni_json_t* omni_json_member(const omni_json_t* value, const char* name);
+omni_json_t* omni_json_index(const omni_json_t* value, int32_t index);
+int32_t omni_json_length(const omni_json_t* value);
+int64_t omni_json_to_int(const omni_json_t* value);
+double omni_json_to_float(const omni_json_t* value);
+const char* omni_json_to_string(const omni_json_t* value);
+int32_t omni_json_to_bool(const omni_json_t* value);
+omni_json_t* omni_json_from_int(int64_t value);
+omni_json_t* omni_json_from_float(double value);
+omni_json_t* omni_json_from_string(const char* value);
+omni_json_t* omni_json_from_bool(int32_t value);
+
+// Regular expressions (std.regex), POSIX extended syntax. An invalid pattern
+// matches nothing.
+// Returns the leftmost match or NULL - caller must free it
+char* omni_regex_find(const char* pattern, const char* s);
+// Return the matches, or the full match and its groups, and store their
+// number in count_out
+char** omni_regex_find_all(const char* pattern, const char* s, int32_t* count_out);
+char** omni_regex_groups(const char* pattern, const char* s, int32_t* count_out);
 
 // Promise/Async support (simplified synchronous implementation)
 typedef struct {
     void* value;
-    int32_t type;  // 0=int, 1=string, 2=float, 3=bool
+    int32_t type;  // 0=int, 1=string, 2=float, 3=bool, 4=struct
     int32_t done;
 } omni_promise_t;
 
+struct omni_struct;
+
 // Create a resolved promise (synchronous implementation)
 omni_promise_t* omni_promise_create_int(int32_t value);
 omni_promise_t* omni_promise_create_string(const char* value);
 omni_promise_t* omni_promise_create_float(double value);
 omni_promise_t* omni_promise_create_bool(int32_t value);
+// The promise does not own the struct; it passes to whoever awaits it
+omni_promise_t* omni_promise_create_struct(struct omni_struct* value);
 
 // Await a promise (synchronous - just extracts the value)
 int32_t omni_await_int(omni_promise_t* promise);
@@ -60,10 +176,73 @@ int32_t omni_await_int(omni_promise_t* promise);
 char* omni_await_string(omni_promise_t* promise);
 double omni_await_float(omni_promise_t* promise);
 int32_t omni_await_bool(omni_promise_t* promise);
+struct omni_struct* omni_await_struct(omni_promise_t* promise);
 
 // Free a promise
 void omni_promise_free(omni_promise_t* promise);
 
+// Optional values (T?) of the types that are not pointers; an optional
+// pointer, such as a string?, is NULL when absent
+#define OMNI_OPTIONAL(T) typedef struct { int32_t has_value; T value; } omni_opt_##T
+OMNI_OPTIONAL(int32_t);
+OMNI_OPTIONAL(int64_t);
+OMNI_OPTIONAL(uint8_t);
+OMNI_OPTIONAL(uint16_t);
+OMNI_OPTIONAL(uint32_t);
+OMNI_OPTIONAL(uint64_t);
+OMNI_OPTIONAL(double);
+
+// Exit with an error for opt.unwrap of an absent value
+void omni_opt_unwrap_none(void);
+
+// Deferred calls (defer)
+// A function with defer statements pushes a frame at entry; each defer
+// statement pushes a call with its arguments captured in env, and the
+// function runs the calls of its frame in reverse order before it returns.
+typedef struct omni_defer_call {
+    void (*fn)(void* env);
+    void* env;
+    struct omni_defer_call* prev;
+} omni_defer_call;
+
+typedef struct omni_defer_frame {
+    omni_defer_call* calls;
+    struct omni_defer_frame* prev;
+} omni_defer_frame;
+
+extern omni_defer_frame* omni_defer_top;
+
+// Push the call fn(env) on frame, which takes ownership of env
+void omni_defer_push(omni_defer_frame* frame, void (*fn)(void* env), void* env);
+// Run and pop the deferred calls of the frames above until, innermost first
+void omni_defer_unwind(omni_defer_frame* until);
+
+// Exceptions (try/catch/finally)
+// A try statement pushes a frame and setjmps into it; omni_throw longjmps
+// to the innermost frame with the exception in omni_exception, after
+// running the deferred calls of the functions it leaves.
+typedef struct omni_try_frame {
+    jmp_buf env;
+    struct omni_try_frame* prev;
+    omni_defer_frame* defers;
+} omni_try_frame;
+
+typedef struct {
+    const char* type;  // "string", "int" or the name of a struct
+    int64_t i;
+    double f;
+    const char* s;
+    void* p;
+} omni_exception_t;
+
+extern omni_try_frame* omni_try_top;
+extern omni_exception_t omni_exception;
+
+// Raise an exception; exits the program if no try statement catches it
+void omni_throw(omni_exception_t exception);
+// Raise the current exception again, for the next try statement out
+void omni_rethrow(void);
+
 // Array operations
 // omni_len returns the length of an array. The length must be passed explicitly
 // by the backend since C arrays don't carry length metadata.
@@ -78,6 +257,8 @@ int32_t omni_abs(int32_t x);
 int32_t omni_max(int32_t a, int32_t b);
 int32_t omni_min(int32_t a, int32_t b);
 char* omni_int_to_string(int32_t value);
+char* omni_int64_to_string(int64_t value);
+char* omni_uint64_to_string(uint64_t value);
 char* omni_float_to_string(double value);
 char* omni_bool_to_string(int32_t value);
 int32_t omni_string_to_int(const char* str);
@@ -118,6 +299,15 @@ int32_t omni_map_get_int_bool(omni_map_t* map, int32_t key);
 
 int32_t omni_map_contains_string(omni_map_t* map, const char* key);
 int32_t omni_map_contains_int(omni_map_t* map, int32_t key);
+// Typed variants used by the map.contains instruction
+int32_t omni_map_contains_string_int(omni_map_t* map, const char* key);
+int32_t omni_map_contains_string_string(omni_map_t* map, const char* key);
+int32_t omni_map_contains_string_float(omni_map_t* map, const char* key);
+int32_t omni_map_contains_string_bool(omni_map_t* map, const char* key);
+int32_t omni_map_contains_int_int(omni_map_t* map, int32_t key);
+int32_t omni_map_contains_int_string(omni_map_t* map, int32_t key);
+int32_t omni_map_contains_int_float(omni_map_t* map, int32_t key);
+int32_t omni_map_contains_int_bool(omni_map_t* map, int32_t key);
 int32_t omni_map_size(omni_map_t* map);
 void omni_map_delete_string(omni_map_t* map, const char* key);
 void omni_map_delete_int(omni_map_t* map, int32_t key);
@@ -295,6 +485,127 @@ int32_t omni_struct_get_int_field(omni_struct_t* struct_ptr, const char* field_n
 double omni_struct_get_float_field(omni_struct_t* struct_ptr, const char* field_name);
 int32_t omni_struct_get_bool_field(omni_struct_t* struct_ptr, const char* field_name);
 
+// Graph operations (std.collections.graph); graphs are omni_struct_t values
+omni_struct_t* omni_graph_create(int32_t nodes);
+int32_t omni_graph_add_edge(omni_struct_t* g, int32_t from, int32_t to, int32_t weight);
+int32_t omni_graph_has_edge(omni_struct_t* g, int32_t from, int32_t to);
+int32_t omni_graph_node_count(omni_struct_t* g);
+int32_t omni_graph_edge_count(omni_struct_t* g);
+int32_t omni_graph_total_weight(omni_struct_t* g);
+omni_struct_t* omni_graph_mst(omni_struct_t* g);
+// Returns a newly allocated array of omni_graph_node_count(g) nodes, or NULL on a cycle
+int32_t* omni_graph_toposort(omni_struct_t* g);
+// Returns a NULL-terminated array of -1-terminated components - caller must free them
+int32_t** omni_graph_scc(omni_struct_t* g);
+
+// Double-ended queues (std.collections.deque), doubly-linked lists of values.
+// The generator reads and writes the member of omni_deque_value that matches
+// the element type: i for int and bool, f for float and s for string.
+typedef union {
+    int32_t i;
+    double f;
+    const char* s;
+} omni_deque_value;
+typedef struct omni_deque_node omni_deque_node;
+typedef struct {
+    omni_deque_node* front;
+    omni_deque_node* back;
+    int32_t size;
+} omni_deque_t;
+omni_deque_t* omni_deque_create(void);
+void omni_deque_push_front(omni_deque_t* d, omni_deque_value value);
+void omni_deque_push_back(omni_deque_t* d, omni_deque_value value);
+// The pops and peeks exit through omni_panic when d is empty
+omni_deque_value omni_deque_pop_front(omni_deque_t* d);
+omni_deque_value omni_deque_pop_back(omni_deque_t* d);
+omni_deque_value omni_deque_peek_front(omni_deque_t* d);
+omni_deque_value omni_deque_peek_back(omni_deque_t* d);
+int32_t omni_deque_size(omni_deque_t* d);
+int32_t omni_deque_is_empty(omni_deque_t* d);
+
+// Prefix trees (std.collections.trie) with string keys. Values travel in the
+// deque's union, read and written by the same members
+typedef omni_deque_value omni_trie_value;
+typedef struct omni_trie omni_trie_t;
+omni_trie_t* omni_trie_create(void);
+void omni_trie_insert(omni_trie_t* t, const char* key, omni_trie_value value);
+// Stores the value of key in out and returns 1, or returns 0 when key has none
+int32_t omni_trie_search(omni_trie_t* t, const char* key, omni_trie_value* out);
+int32_t omni_trie_starts_with(omni_trie_t* t, const char* prefix);
+// Returns the keys starting with prefix in byte order and stores their number
+// in count_out - caller owns the keys and the array
+char** omni_trie_keys_with_prefix(omni_trie_t* t, const char* prefix, int32_t* count_out);
+
+// Ordered maps (std.collections.ordered_map), hash tables whose entries are
+// also linked in insertion order. Keys and values travel in the deque's
+// union; keys are compared as strings or, through member i, as ints
+typedef omni_deque_value omni_ordered_map_value;
+typedef struct omni_ordered_map_entry omni_ordered_map_entry;
+struct omni_ordered_map_entry {
+    omni_ordered_map_value key;
+    omni_ordered_map_value value;
+    omni_ordered_map_entry* prev;
+    omni_ordered_map_entry* next;
+    // The next entry in the same hash bucket
+    omni_ordered_map_entry* bucket_next;
+};
+typedef struct {
+    int32_t string_keys;
+    // The entries in insertion order, which keys(), values() and entries()
+    // walk from first
+    omni_ordered_map_entry* first;
+    omni_ordered_map_entry* last;
+    omni_ordered_map_entry** buckets;
+    int32_t bucket_count;
+    int32_t size;
+} omni_ordered_map_t;
+omni_ordered_map_t* omni_ordered_map_create(int32_t string_keys);
+void omni_ordered_map_put(omni_ordered_map_t* m, omni_ordered_map_value key, omni_ordered_map_value value);
+// Stores the value of key in out and returns 1, or returns 0 when m does not hold key
+int32_t omni_ordered_map_get(omni_ordered_map_t* m, omni_ordered_map_value key, omni_ordered_map_value* out);
+int32_t omni_ordered_map_remove(omni_ordered_map_t* m, omni_ordered_map_value key);
+
+// Interpolation (std.math.interpolation); splines are omni_struct_t values
+double omni_interp_linear(double x0, double y0, double x1, double y1, double x);
+double omni_interp_lerp(double a, double b, double t);
+double omni_interp_smooth_step(double t);
+// Fits a natural cubic spline through n points; xs must be strictly increasing
+omni_struct_t* omni_interp_cubic_spline(const double* xs, const double* ys, int32_t n);
+double omni_interp_eval(omni_struct_t* s, double x);
+
+// Subprocesses (std.process). Runs cmd with count args, found on PATH, and
+// returns a ProcessResult struct with its stdout, stderr and exit_code; a
+// command that cannot start exits with 127
+omni_struct_t* omni_process_run(const char* cmd, const char** args, int32_t count);
+
+// Paths (std.path), as Go's path/filepath handles them on the platform.
+// The strings returned are newly allocated - caller must free them
+char* omni_path_join(const char** parts, int32_t count);
+char* omni_path_clean(const char* p);
+char* omni_path_dirname(const char* p);
+char* omni_path_basename(const char* p);
+char* omni_path_ext(const char* p);
+char* omni_path_abs(const char* p);
+int32_t omni_path_is_abs(const char* p);
+
+// Environment variables (std.env). The strings returned are not copies
+const char* omni_env_get_string(const char* name, const char* default_value);
+int32_t omni_env_get_int(const char* name, int32_t default_value);
+int32_t omni_env_get_bool(const char* name, int32_t default_value);
+// Exits through omni_panic when name is not set
+const char* omni_env_require(const char* name);
+
+// Print message to stderr and exit with status 1
+void omni_panic(const char* message);
+
+// Sorting (std.sort); each sorts the count elements of arr in place, in
+// ascending order or, for the reverse variants, descending
+void omni_sort_ints(int32_t* arr, int32_t count);
+void omni_sort_strings(const char** arr, int32_t count);
+void omni_sort_floats(double* arr, int32_t count);
+void omni_sort_reverse_ints(int32_t* arr, int32_t count);
+void omni_sort_reverse_strings(const char** arr, int32_t count);
+
 double omni_pow(double x, double y);
 double omni_sqrt(double x);
 double omni_floor(double x);
@@ -328,6 +639,12 @@ double omni_tanh(double x);
 double omni_cbrt(double x);
 double omni_trunc(double x);
 
+// Pseudo-random numbers (std.math.random)
+double omni_random_float(void);
+int32_t omni_random_int(int32_t lo, int32_t hi);
+void omni_random_seed(int32_t seed);
+char* omni_random_string(int32_t n);
+
 // File I/O operations
 // Use intptr_t for file handles to safely store FILE* pointers on 64-bit platforms
 intptr_t omni_file_open(const char* filename, const char* mode);
@@ -410,6 +727,11 @@ void omni_time_sleep_seconds(double seconds);
 void omni_time_sleep_milliseconds(int32_t milliseconds);
 int32_t omni_time_zone_offset(void);
 const char* omni_time_zone_name(void);
+// strftime/strptime over std.time.Time structs (UTC); strftime returns a newly allocated string
+char* omni_time_strftime(omni_struct_t* t, const char* pattern);
+omni_struct_t* omni_time_strptime(const char* s, const char* pattern);
+char* omni_time_format(omni_struct_t* t, const char* layout);
+omni_struct_t* omni_time_parse(const char* layout, const char* value);
 
 // Command-line argument functions
 void omni_args_init(int argc, char** argv);
@@ -423,6 +745,11 @@ const char* omni_args_positional(int32_t index, const char* default_value);
 int32_t omni_getpid(void);
 int32_t omni_getppid(void);
 
+// Signal functions
+typedef void (*omni_signal_handler_t)(void);
+void omni_signal_trap(int32_t signum, omni_signal_handler_t handler);
+void omni_signal_raise(int32_t signum);
+
 // Entry point
 int32_t omni_main();
 
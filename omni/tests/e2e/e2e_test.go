@@ -1,6 +1,7 @@
 package e2e
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
@@ -465,6 +466,27 @@ func TestStructComprehensive(t *testing.T) {
 	}
 }
 
+func TestMultipleReturns(t *testing.T) {
+	testFile := "multiple_returns.omni"
+	expected := "23" // divmod(17, 5) is (3, 2), ordered to (2, 3)
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
 func TestPhiLoop(t *testing.T) {
 	testFile := "phi_test.omni"
 	expected := "3" // 0 + 1 + 2 = 3
@@ -597,6 +619,508 @@ func TestStringOperations(t *testing.T) {
 	}
 }
 
+func TestStringSplit(t *testing.T) {
+	testFile := "string_split.omni"
+	expected := "4" // four fields, joined back into the original line
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestStringFormat(t *testing.T) {
+	testFile := "string_format.omni"
+	expected := "4" // integer, float, string and brace substitutions all match
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestJSONNested(t *testing.T) {
+	testFile := "json_nested.omni"
+	expected := "5" // name, flag, line total, re-encoded line and id all match
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestRegexEmail(t *testing.T) {
+	testFile := "regex_email.omni"
+	expected := "5" // groups, group parts, find_all, find and the misses all match
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestProcessEcho(t *testing.T) {
+	testFile := "process_echo.omni"
+	expected := "4" // echo hello, a failing sh, a missing command and run_async
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestPathJoin(t *testing.T) {
+	testFile := "path_join.omni"
+	expected := "7" // join, clean, dirname, basename, ext and the absolute path checks
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestEnvTyped(t *testing.T) {
+	testFile := "env_typed.omni"
+	expected := "5" // get, an empty value, get_int, get_bool and require
+	t.Setenv("OMNI_E2E_NAME", "grace")
+	t.Setenv("OMNI_E2E_EMPTY", "")
+	t.Setenv("OMNI_E2E_PORT", "8080")
+	t.Setenv("OMNI_E2E_DEBUG", "true")
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestSortArrays(t *testing.T) {
+	testFile := "sort_arrays.omni"
+	expected := "5" // ints, reverse_ints, strings, reverse_strings and floats sort in place
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestMathRandom(t *testing.T) {
+	testFile := "math_random.omni"
+	expected := "4" // reseeding repeats the sequence, and float, int and string draws are in range
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestIOStderr(t *testing.T) {
+	testFile := "io_stderr.omni"
+	// io.write leaves stdout without a newline, so the result follows it
+	// directly; io.eprint and io.eprintln go to stderr only
+	wantStderr := "warning: disk almost full\n"
+
+	stdout, stderr, err := runSplit(exec.Command("../../bin/omnir", testFile))
+	if err != nil {
+		t.Fatalf("VM execution failed: %v\nStderr: %s", err, stderr)
+	}
+	if stdout != "progress: done0\n" {
+		t.Errorf("VM: stdout = %q, want %q", stdout, "progress: done0\n")
+	}
+	if stderr != wantStderr {
+		t.Errorf("VM: stderr = %q, want %q", stderr, wantStderr)
+	}
+
+	exe := filepath.Join(t.TempDir(), "io_stderr")
+	compile := exec.Command("../../bin/omnic", "-backend", "c", "-emit", "exe", "-o", exe, testFile)
+	if _, stderr, err := runSplit(compile); err != nil {
+		t.Fatalf("C backend compilation failed: %v\nStderr: %s", err, stderr)
+	}
+	stdout, stderr, err = runSplit(exec.Command(exe))
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v\nStderr: %s", err, stderr)
+	}
+	if stdout != "progress: doneOmniLang program result: 0\n" {
+		t.Errorf("C backend: stdout = %q, want %q", stdout, "progress: doneOmniLang program result: 0\n")
+	}
+	if stderr != wantStderr {
+		t.Errorf("C backend: stderr = %q, want %q", stderr, wantStderr)
+	}
+}
+
+func TestIOPrompt(t *testing.T) {
+	testFile := "io_prompt.omni"
+	// The prompts stay on one line; "abc" is asked again and the ratio
+	// falls back to its default after three invalid answers
+	input := "ada\nabc\n36\nx\n\ny\n"
+	prompts := "name? age? age? ratio? ratio? ratio? \nada 36\ndefault ratio\n"
+
+	vm := exec.Command("../../bin/omnir", testFile)
+	vm.Stdin = strings.NewReader(input)
+	stdout, stderr, err := runSplit(vm)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v\nStderr: %s", err, stderr)
+	}
+	if stdout != prompts+"0\n" {
+		t.Errorf("VM: stdout = %q, want %q", stdout, prompts+"0\n")
+	}
+
+	exe := filepath.Join(t.TempDir(), "io_prompt")
+	compile := exec.Command("../../bin/omnic", "-backend", "c", "-emit", "exe", "-o", exe, testFile)
+	if _, stderr, err := runSplit(compile); err != nil {
+		t.Fatalf("C backend compilation failed: %v\nStderr: %s", err, stderr)
+	}
+	native := exec.Command(exe)
+	native.Stdin = strings.NewReader(input)
+	stdout, stderr, err = runSplit(native)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v\nStderr: %s", err, stderr)
+	}
+	if stdout != prompts+"OmniLang program result: 0\n" {
+		t.Errorf("C backend: stdout = %q, want %q", stdout, prompts+"OmniLang program result: 0\n")
+	}
+}
+
+// runSplit runs cmd from tests/e2e and returns what it wrote to stdout and
+// stderr separately.
+func runSplit(cmd *exec.Cmd) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Dir = "."
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
+}
+
+func TestTimeLayout(t *testing.T) {
+	testFile := "time_layout.omni"
+	expected := "5" // parse, three layouts formatted, and epoch on a mismatch
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestCollectionsHigherOrder(t *testing.T) {
+	testFile := "collections_hof.omni"
+	expected := "5" // filter, map_fn and reduce with named functions and lambdas
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestCollectionsDeque(t *testing.T) {
+	testFile := "collections_deque.omni"
+	expected := "5" // order checks after alternating push_front and push_back
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestCollectionsTrie(t *testing.T) {
+	testFile := "collections_trie.omni"
+	expected := "6" // searches and prefix queries over 100 inserted words
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestCollectionsOrderedMap(t *testing.T) {
+	testFile := "collections_ordered_map.omni"
+	expected := "6" // insertion order checks after puts, a replacement and a removal
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestDeferLoop(t *testing.T) {
+	testFile := "defer_loop.omni"
+	expected := "4321" // one deferred call per iteration, arguments taken at the defer
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestDeferThrow(t *testing.T) {
+	testFile := "defer_throw.omni"
+	expected := "21567" // deferred calls of the callees an exception leaves run before the catch
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestVariadicRange(t *testing.T) {
+	testFile := "variadic_range.omni"
+	expected := "61" // sum(1, 2, 3) * 10 + sum(1), iterating the packed arguments
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestStringReplace(t *testing.T) {
+	testFile := "string_replace.omni"
+	expected := "6" // replace, replace_all and count_occurrences checks
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestStringPad(t *testing.T) {
+	testFile := "string_pad.omni"
+	expected := "5" // padding on either side, centering and repetition
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
+func TestStringParse(t *testing.T) {
+	testFile := "string_parse.omni"
+	expected := "5" // parse_int, parse_float and parse_bool on valid and invalid input
+
+	result, err := runVM(testFile)
+	if err != nil {
+		t.Fatalf("VM execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("VM: expected %s, got %s", expected, result)
+	}
+
+	result, err = runCBackend(testFile)
+	if err != nil {
+		t.Fatalf("C backend execution failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("C backend: expected %s, got %s", expected, result)
+	}
+}
+
 func TestMathUtilities(t *testing.T) {
 	testFile := "new_features/test_math_utilities.omni"
 	expected := "Math and utilities test passed\n0"
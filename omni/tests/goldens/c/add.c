@@ -0,0 +1,30 @@
+#include "omni_rt.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+int32_t add(int32_t a, int32_t b);
+int32_t omni_main();
+
+int32_t add(int32_t a, int32_t b) {
+int32_t v2;
+v2 = a + b;
+return v2;
+}
+
+int32_t omni_main() {
+int32_t v0;
+int32_t v1;
+int32_t v2;
+v1 = 2;
+v2 = 3;
+v0 = add(v1, v2);
+return v0;
+}
+
+int main(int argc, char** argv) {
+omni_args_init(argc, argv);
+int32_t result = omni_main();
+printf("OmniLang program result: %d\n", result);
+return result;
+}
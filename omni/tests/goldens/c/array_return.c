@@ -0,0 +1,111 @@
+#include "omni_rt.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+int32_t* make_array(int32_t n);
+int32_t omni_main();
+
+int32_t* make_array(int32_t n) {
+int32_t* v1 = NULL;
+int32_t v2;
+int32_t v3;
+int32_t v4;
+int32_t v5;
+v2 = 1;
+v3 = n + v2;
+v4 = 2;
+v5 = n + v4;
+v1 = (int32_t*)malloc(3 * sizeof(int32_t));
+v1[0] = n;
+v1[1] = v3;
+v1[2] = v5;
+return v1;
+}
+
+int32_t omni_main() {
+int32_t* v0 = NULL;
+int32_t v1;
+int32_t* v2 = NULL;
+int32_t v3;
+int32_t v4;
+int32_t v5;
+int32_t v6;
+int32_t v7;
+int32_t v8;
+int32_t* v9 = NULL;
+int32_t v10;
+int32_t v11;
+int32_t v12;
+int32_t v13;
+int32_t v14;
+int32_t v15;
+int32_t v16;
+int32_t v17;
+int32_t v18;
+int32_t v19;
+int32_t v20;
+int32_t v21;
+int32_t v22;
+int32_t v23;
+int32_t v24;
+int32_t v25;
+int32_t v26;
+v1 = 40;
+free(v0);
+v0 = make_array(v1);
+v3 = 1;
+v4 = 2;
+free(v2);
+v2 = (int32_t*)malloc(2 * sizeof(int32_t));
+v2[0] = v3;
+v2[1] = v4;
+v5 = 0;
+v6 = 0;
+goto loop_header_0;
+loop_header_0:
+;
+v7 = 3;
+v8 = (v6 < v7) ? 1 : 0;
+if (v8) {
+goto loop_body_1;
+} else {
+goto loop_exit_2;
+}
+loop_body_1:
+;
+free(v9);
+v9 = make_array(v6);
+v10 = 0;
+v11 = omni_array_get_int(v9, v10, 3);
+v12 = v5 + v11;
+v5 = v12;
+v14 = 1;
+v15 = v6 + v14;
+v6 = v15;
+goto loop_header_0;
+loop_exit_2:
+;
+v17 = 2;
+v18 = omni_array_get_int(v0, v17, 3);
+v19 = 0;
+v20 = omni_array_get_int(v2, v19, 2);
+v21 = v18 + v20;
+v22 = 1;
+v23 = v21 - v22;
+v24 = v23 + v5;
+v25 = 3;
+v26 = v24 - v25;
+  // Cleanup: free heap-allocated arrays
+free(v9);
+free(v2);
+free(v0);
+return v26;
+}
+
+int main(int argc, char** argv) {
+omni_args_init(argc, argv);
+int32_t result = omni_main();
+printf("OmniLang program result: %d\n", result);
+return result;
+}
@@ -0,0 +1,129 @@
+#include "omni_rt.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+int32_t popcount(uint32_t x);
+uint32_t rotl(uint32_t x, int32_t k);
+int32_t omni_main();
+
+int32_t popcount(uint32_t x) {
+int32_t v1;
+uint32_t v2;
+int32_t v3;
+uint32_t v4;
+uint32_t v5;
+uint32_t v6;
+uint32_t v7;
+int32_t v8;
+int32_t v9;
+int32_t v10;
+v1 = 0;
+goto while_header_0;
+while_header_0:
+;
+v2 = 0U;
+v3 = (x != v2) ? 1 : 0;
+while (v3) {
+while_body_1:
+;
+v4 = 1U;
+v5 = x - v4;
+v6 = x & v5;
+x = v6;
+v8 = 1;
+v9 = v1 + v8;
+v1 = v9;
+v2 = 0U;
+v3 = (x != v2) ? 1 : 0;
+continue;
+}
+goto while_exit_2;
+while_exit_2:
+;
+return v1;
+}
+
+uint32_t rotl(uint32_t x, int32_t k) {
+uint32_t v2;
+int32_t v3;
+int32_t v4;
+uint32_t v5;
+uint32_t v6;
+v2 = x << k;
+v3 = 32;
+v4 = v3 - k;
+v5 = x >> v4;
+v6 = v2 | v5;
+return v6;
+}
+
+int32_t omni_main() {
+int32_t v0;
+uint8_t v1;
+int32_t v2;
+uint8_t v3;
+uint8_t v4;
+const char* v6 = "wrapped=";
+const char* v7;
+uint64_t v8;
+const char* v10 = "half=";
+uint32_t v11;
+uint64_t v12;
+const char* v13;
+const char* v15 = "rotl=";
+uint32_t v16;
+uint32_t v17;
+int32_t v18;
+const char* v19;
+int32_t v20;
+uint32_t v21;
+uint32_t v22;
+uint32_t v23;
+uint32_t v24;
+int32_t v25;
+int32_t v26;
+v0 = 250;
+v1 = (uint8_t)v0;
+v2 = 10;
+v3 = (uint8_t)v2;
+v4 = v1 + v3;
+const char* temp_str_4_1393 = omni_uint64_to_string(v4);
+v7 = omni_strcat(v6, temp_str_4_1393);
+omni_println_string(v7);
+v8 = 18446744073709551615ULL;
+v11 = 2U;
+v12 = (uint64_t)v8 / (uint64_t)v11;
+const char* temp_str_12_1602 = omni_uint64_to_string(v12);
+v13 = omni_strcat(v10, temp_str_12_1602);
+omni_println_string(v13);
+v17 = 0x80000001U;
+v18 = 4;
+v16 = rotl(v17, v18);
+const char* temp_str_16_1791 = omni_uint64_to_string(v16);
+v19 = omni_strcat(v15, temp_str_16_1791);
+omni_println_string(v19);
+v21 = 0xF0F0U;
+v20 = popcount(v21);
+v22 = 0xFFFFFFFFU;
+v23 = 10U;
+v24 = (uint32_t)v22 % (uint32_t)v23;
+v25 = (int32_t)v24;
+v26 = v20 + v25;
+return v26;
+  // Cleanup: free heap-allocated strings
+if (v19 != NULL) { free((void*)v19); v19 = NULL; }
+if (v13 != NULL) { free((void*)v13); v13 = NULL; }
+if (v7 != NULL) { free((void*)v7); v7 = NULL; }
+  // Cleanup: free temporary string conversion variables
+if (temp_str_16_1791 != NULL) { free((void*)temp_str_16_1791); temp_str_16_1791 = NULL; }
+if (temp_str_12_1602 != NULL) { free((void*)temp_str_12_1602); temp_str_12_1602 = NULL; }
+if (temp_str_4_1393 != NULL) { free((void*)temp_str_4_1393); temp_str_4_1393 = NULL; }
+}
+
+int main(int argc, char** argv) {
+omni_args_init(argc, argv);
+int32_t result = omni_main();
+printf("OmniLang program result: %d\n", result);
+return result;
+}
@@ -0,0 +1,56 @@
+#include "omni_rt.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+int64_t square(int64_t x);
+int32_t omni_main();
+
+int64_t square(int64_t x) {
+int64_t v1;
+v1 = x * x;
+return v1;
+}
+
+int32_t omni_main() {
+int64_t v0;
+int32_t v1;
+int64_t v2;
+const char* v4 = "sum=";
+const char* v5;
+const char* v7 = "square=";
+int64_t v8;
+int32_t v9;
+const char* v10;
+int64_t v11;
+int64_t v12;
+int32_t v13;
+v0 = 3000000000LL;
+v1 = 7;
+v2 = v0 + v1;
+const char* temp_str_2_480 = omni_int64_to_string(v2);
+v5 = omni_strcat(v4, temp_str_2_480);
+omni_println_string(v5);
+v9 = 100000;
+v8 = square(v9);
+const char* temp_str_8_638 = omni_int64_to_string(v8);
+v10 = omni_strcat(v7, temp_str_8_638);
+omni_println_string(v10);
+v11 = 3000000000LL;
+v12 = v2 - v11;
+v13 = (int32_t)v12;
+return v13;
+  // Cleanup: free heap-allocated strings
+if (v10 != NULL) { free((void*)v10); v10 = NULL; }
+if (v5 != NULL) { free((void*)v5); v5 = NULL; }
+  // Cleanup: free temporary string conversion variables
+if (temp_str_8_638 != NULL) { free((void*)temp_str_8_638); temp_str_8_638 = NULL; }
+if (temp_str_2_480 != NULL) { free((void*)temp_str_2_480); temp_str_2_480 = NULL; }
+}
+
+int main(int argc, char** argv) {
+omni_args_init(argc, argv);
+int32_t result = omni_main();
+printf("OmniLang program result: %d\n", result);
+return result;
+}
@@ -0,0 +1,51 @@
+#include "omni_rt.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+typedef struct Shape_vtable {
+int32_t (*area)(omni_struct_t*);
+} Shape_vtable;
+typedef struct { void* data; const Shape_vtable* vtable; } Shape_t;
+
+int32_t Square_area(omni_struct_t* s);
+int32_t measure(Shape_t s);
+int32_t omni_main();
+
+static const Shape_vtable Square_Shape_vtable = { Square_area };
+
+int32_t Square_area(omni_struct_t* s) {
+int32_t v1;
+int32_t v2;
+int32_t v3;
+v1 = omni_struct_get_int_field(s, "side");
+v2 = omni_struct_get_int_field(s, "side");
+v3 = v1 * v2;
+return v3;
+}
+
+int32_t measure(Shape_t s) {
+int32_t v1;
+v1 = s.vtable->area((omni_struct_t*)s.data);
+return v1;
+}
+
+int32_t omni_main() {
+int32_t v0;
+omni_struct_t* v1;
+int32_t v2;
+Shape_t v3;
+v2 = 3;
+v1 = omni_struct_create();
+omni_struct_set_int_field(v1, "side", v2);
+v3 = (Shape_t){ (void*)v1, &Square_Shape_vtable };
+v0 = measure(v3);
+return v0;
+}
+
+int main(int argc, char** argv) {
+omni_args_init(argc, argv);
+int32_t result = omni_main();
+printf("OmniLang program result: %d\n", result);
+return result;
+}
@@ -0,0 +1,62 @@
+#include "omni_rt.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+int32_t omni_main();
+
+int32_t omni_main() {
+omni_json_t* v0;
+const char* v1 = "{\"user\":{\"name\":\"ada\"},\"ids\":[4,2]}";
+omni_json_t* v2;
+const char* v3 = "name";
+omni_json_t* v4;
+const char* v5;
+omni_json_t* v6;
+int32_t v7;
+omni_json_t* v8;
+int32_t v9;
+const char* v10 = NULL;
+omni_json_t* v11;
+const char* v12 = "\"ada\"";
+int32_t v13;
+int32_t v14;
+omni_json_t* v15;
+int32_t v16;
+int32_t v17;
+v0 = omni_json_parse(v1);
+v2 = omni_json_member(v0, "user");
+v4 = omni_json_member(v2, v3);
+v5 = omni_json_to_string(v4);
+v6 = omni_json_member(v0, "ids");
+v7 = 1;
+v8 = omni_json_index(v6, v7);
+v9 = omni_json_to_int(v8);
+v11 = omni_json_from_string(v5);
+v10 = omni_json_stringify(v11);
+v13 = omni_string_equals(v10, v12) ? 1 : 0;
+if (v13) {
+goto then_0;
+} else {
+goto merge_1;
+}
+then_0:
+;
+v15 = omni_json_member(v0, "ids");
+v14 = omni_json_length(v15);
+v16 = v9 + v14;
+return v16;
+merge_1:
+;
+v17 = 0;
+return v17;
+  // Cleanup: free heap-allocated strings
+if (v10 != NULL) { free((void*)v10); v10 = NULL; }
+}
+
+int main(int argc, char** argv) {
+omni_args_init(argc, argv);
+int32_t result = omni_main();
+printf("OmniLang program result: %d\n", result);
+return result;
+}
@@ -0,0 +1,71 @@
+#include "omni_rt.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+int32_t omni_main();
+
+int32_t omni_main() {
+omni_map_t* v0;
+int32_t v1;
+const char* v2 = "one";
+int32_t v3;
+const char* v4 = "two";
+int32_t v5;
+int32_t v6;
+int32_t v7;
+int32_t v8;
+int32_t v9;
+int32_t v10;
+int32_t v11;
+int32_t v12;
+int32_t v13;
+int32_t v14;
+int32_t v15;
+int32_t v16;
+v1 = 1;
+v3 = 2;
+v0 = omni_map_create();
+omni_map_put_int_string(v0, v1, v2);
+omni_map_put_int_string(v0, v3, v4);
+v5 = 0;
+v6 = 2;
+v7 = omni_map_contains_int_string(v0, v6);
+if (v7) {
+goto then_0;
+} else {
+goto merge_1;
+}
+then_0:
+;
+v8 = 10;
+v9 = v5 + v8;
+v5 = v9;
+goto merge_1;
+merge_1:
+;
+v11 = 3;
+v12 = omni_map_contains_int_string(v0, v11);
+v13 = !v12;
+if (v13) {
+goto then_2;
+} else {
+goto merge_3;
+}
+then_2:
+;
+v14 = 5;
+v15 = v5 + v14;
+v5 = v15;
+goto merge_3;
+merge_3:
+;
+return v5;
+}
+
+int main(int argc, char** argv) {
+omni_args_init(argc, argv);
+int32_t result = omni_main();
+printf("OmniLang program result: %d\n", result);
+return result;
+}
@@ -0,0 +1,79 @@
+#include "omni_rt.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+const char* wrap(const char* s);
+int32_t omni_main();
+
+const char* wrap(const char* s) {
+const char* result = "";
+const char* v2 = "(";
+const char* v3;
+const char* v4 = ")";
+const char* v5;
+const char* v6;
+const char* v7 = "";
+int32_t v8;
+const char* v9 = "!";
+const char* v10;
+const char* v11;
+v3 = omni_strcat(v2, s);
+v5 = omni_strcat(v3, v4);
+result = v5;
+v8 = omni_string_equals(s, v7) ? 1 : 0;
+if (v8) {
+goto then_0;
+} else {
+goto merge_1;
+}
+then_0:
+;
+return result;
+merge_1:
+;
+v10 = omni_strcat(result, v9);
+result = v10;
+return result;
+  // Cleanup: free heap-allocated strings
+if (v3 != NULL) { free((void*)v3); v3 = NULL; }
+}
+
+int32_t omni_main() {
+const char* v0;
+const char* v1 = "a";
+const char* v2 = "(a)!";
+int32_t v3;
+const char* v4;
+const char* v5 = "";
+const char* v6 = "()";
+int32_t v7;
+int32_t v8;
+int32_t v9;
+int32_t v10;
+v0 = wrap(v1);
+v3 = omni_string_equals(v0, v2) ? 1 : 0;
+v4 = wrap(v5);
+v7 = omni_string_equals(v4, v6) ? 1 : 0;
+v8 = v3 && v7;
+if (v8) {
+goto then_0;
+} else {
+goto merge_1;
+}
+then_0:
+;
+v9 = 0;
+return v9;
+merge_1:
+;
+v10 = 1;
+return v10;
+}
+
+int main(int argc, char** argv) {
+omni_args_init(argc, argv);
+int32_t result = omni_main();
+printf("OmniLang program result: %d\n", result);
+return result;
+}
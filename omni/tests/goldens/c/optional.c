@@ -0,0 +1,83 @@
+#include "omni_rt.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+omni_opt_int32_t find(int32_t n);
+int32_t omni_main();
+
+omni_opt_int32_t find(int32_t n) {
+int32_t v1;
+int32_t v2;
+int32_t v3;
+int32_t v4;
+omni_opt_int32_t v5;
+void* v6;
+omni_opt_int32_t v7;
+v1 = 0;
+v2 = (n > v1) ? 1 : 0;
+if (v2) {
+goto then_0;
+} else {
+goto merge_1;
+}
+then_0:
+;
+v3 = 2;
+v4 = n * v3;
+v5 = (omni_opt_int32_t){1, v4};
+return v5;
+merge_1:
+;
+v6 = NULL;
+v7 = (omni_opt_int32_t){0, 0};
+return v7;
+}
+
+int32_t omni_main() {
+omni_opt_int32_t v0;
+int32_t v1;
+omni_opt_int32_t v2;
+int32_t v3;
+int32_t v4;
+int32_t v5;
+int32_t v6;
+int32_t v7;
+void* v8;
+int32_t v9;
+int32_t v10;
+int32_t v11;
+int32_t v12;
+v1 = 3;
+v0 = find(v1);
+v3 = 0;
+v2 = find(v3);
+if (!v0.has_value) omni_opt_unwrap_none();
+v4 = v0.value;
+v5 = 30;
+v6 = v2.has_value ? v2.value : v5;
+v7 = v4 + v6;
+v8 = NULL;
+v9 = !v2.has_value;
+if (v9) {
+goto then_0;
+} else {
+goto merge_1;
+}
+then_0:
+;
+v10 = 6;
+v11 = v7 + v10;
+v7 = v11;
+goto merge_1;
+merge_1:
+;
+return v7;
+}
+
+int main(int argc, char** argv) {
+omni_args_init(argc, argv);
+int32_t result = omni_main();
+printf("OmniLang program result: %d\n", result);
+return result;
+}
@@ -0,0 +1,88 @@
+#include "omni_rt.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+const char* domain(const char* address);
+int32_t omni_main();
+
+const char* domain(const char* address) {
+int32_t v1_count = 0;
+const char** v1;
+const char* v2 = "^([[:alnum:]._%+-]+)@([[:alnum:].-]+)\\.([[:alpha:]]+)$";
+int32_t v3;
+int32_t v4;
+int32_t v5;
+const char* v6 = "";
+int32_t v7;
+const char* v8;
+const char* v9 = ".";
+const char* v10;
+int32_t v11;
+const char* v12;
+const char* v13;
+v1 = (const char**)omni_regex_groups(v2, address, &v1_count);
+v3 = v1_count;
+v4 = 4;
+v5 = (v3 != v4) ? 1 : 0;
+if (v5) {
+goto then_0;
+} else {
+goto merge_1;
+}
+then_0:
+;
+return v6;
+merge_1:
+;
+v7 = 2;
+if (v7 < 0 || v7 >= v1_count) { fprintf(stderr, "Array index out of bounds: %d (length: %d)\n", v7, v1_count); exit(1); }
+v8 = v1[v7];
+v10 = omni_strcat(v8, v9);
+v11 = 3;
+if (v11 < 0 || v11 >= v1_count) { fprintf(stderr, "Array index out of bounds: %d (length: %d)\n", v11, v1_count); exit(1); }
+v12 = v1[v11];
+v13 = omni_strcat(v10, v12);
+return v13;
+  // Cleanup: free heap-allocated strings
+if (v10 != NULL) { free((void*)v10); v10 = NULL; }
+}
+
+int32_t omni_main() {
+const char* v0;
+const char* v1 = "grace.hopper@navy.mil";
+const char* v2 = "navy.mil";
+int32_t v3;
+const char* v4;
+const char* v5 = "not an address";
+const char* v6 = "";
+int32_t v7;
+int32_t v8;
+int32_t v9;
+int32_t v10;
+v0 = domain(v1);
+v3 = omni_string_equals(v0, v2) ? 1 : 0;
+v4 = domain(v5);
+v7 = omni_string_equals(v4, v6) ? 1 : 0;
+v8 = v3 && v7;
+if (v8) {
+goto then_0;
+} else {
+goto merge_1;
+}
+then_0:
+;
+v9 = 0;
+return v9;
+merge_1:
+;
+v10 = 1;
+return v10;
+}
+
+int main(int argc, char** argv) {
+omni_args_init(argc, argv);
+int32_t result = omni_main();
+printf("OmniLang program result: %d\n", result);
+return result;
+}
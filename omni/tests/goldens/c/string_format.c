@@ -0,0 +1,49 @@
+#include "omni_rt.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+const char* describe(const char* name, int32_t age, double height);
+int32_t omni_main();
+
+const char* describe(const char* name, int32_t age, double height) {
+const char* v3;
+const char* v4 = "{:s} is {:d} and {:f}m tall {{id {}}}";
+v3 = omni_string_format(v4, 4, (omni_format_arg[]){omni_format_string(name), omni_format_int(age), omni_format_float(height), omni_format_int(age)});
+return v3;
+}
+
+int32_t omni_main() {
+const char* v0;
+const char* v1 = "ada";
+int32_t v2;
+double v3;
+const char* v4 = "ada is 36 and 1.700000m tall {id 36}";
+int32_t v5;
+int32_t v6;
+int32_t v7;
+v2 = 36;
+v3 = 1.7;
+v0 = describe(v1, v2, v3);
+v5 = omni_string_equals(v0, v4) ? 1 : 0;
+if (v5) {
+goto then_0;
+} else {
+goto merge_1;
+}
+then_0:
+;
+v6 = 0;
+return v6;
+merge_1:
+;
+v7 = 1;
+return v7;
+}
+
+int main(int argc, char** argv) {
+omni_args_init(argc, argv);
+int32_t result = omni_main();
+printf("OmniLang program result: %d\n", result);
+return result;
+}
@@ -0,0 +1,115 @@
+#include "omni_rt.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+int32_t omni_main();
+
+int32_t omni_main() {
+omni_struct_t* v0;
+const char* v1 = "42";
+int32_t v2;
+int32_t v3;
+omni_struct_t* v4;
+const char* v5 = "4x2";
+int32_t v6;
+int32_t v7;
+omni_struct_t* v8;
+const char* v9 = "0.5";
+double v10;
+int32_t v11;
+omni_struct_t* v12;
+const char* v13 = "nope";
+int32_t v14;
+int32_t v15;
+int32_t v16;
+int32_t v17;
+int32_t v18;
+int32_t v19;
+int32_t v20;
+int32_t v21;
+int32_t v22;
+int32_t v23;
+int32_t v24;
+double v25;
+int32_t v26;
+int32_t v27;
+int32_t v28;
+int32_t v29;
+int32_t v30;
+int32_t v31;
+int32_t v32;
+int32_t v33;
+{
+int32_t parsed;
+int32_t ok = omni_parse_int(v1, &parsed);
+v0 = omni_struct_create();
+omni_struct_set_int_field(v0, "0", parsed);
+omni_struct_set_bool_field(v0, "1", ok);
+}
+v2 = omni_struct_get_int_field(v0, "0");
+v3 = omni_struct_get_bool_field(v0, "1");
+{
+int32_t parsed;
+int32_t ok = omni_parse_int(v5, &parsed);
+v4 = omni_struct_create();
+omni_struct_set_int_field(v4, "0", parsed);
+omni_struct_set_bool_field(v4, "1", ok);
+}
+v6 = omni_struct_get_int_field(v4, "0");
+v7 = omni_struct_get_bool_field(v4, "1");
+{
+double parsed;
+int32_t ok = omni_parse_float(v9, &parsed);
+v8 = omni_struct_create();
+omni_struct_set_float_field(v8, "0", parsed);
+omni_struct_set_bool_field(v8, "1", ok);
+}
+v10 = omni_struct_get_float_field(v8, "0");
+v11 = omni_struct_get_bool_field(v8, "1");
+{
+int32_t parsed;
+int32_t ok = omni_parse_bool(v13, &parsed);
+v12 = omni_struct_create();
+omni_struct_set_bool_field(v12, "0", parsed);
+omni_struct_set_bool_field(v12, "1", ok);
+}
+v14 = omni_struct_get_bool_field(v12, "0");
+v15 = omni_struct_get_bool_field(v12, "1");
+v16 = 42;
+v17 = (v2 == v16) ? 1 : 0;
+v18 = v3 && v17;
+v19 = !v7;
+v20 = v18 && v19;
+v21 = 0;
+v22 = (v6 == v21) ? 1 : 0;
+v23 = v20 && v22;
+v24 = v23 && v11;
+v25 = 0.5;
+v26 = (v10 == v25) ? 1 : 0;
+v27 = v24 && v26;
+v28 = !v15;
+v29 = v27 && v28;
+v30 = !v14;
+v31 = v29 && v30;
+if (v31) {
+goto then_0;
+} else {
+goto merge_1;
+}
+then_0:
+;
+v32 = 0;
+return v32;
+merge_1:
+;
+v33 = 1;
+return v33;
+}
+
+int main(int argc, char** argv) {
+omni_args_init(argc, argv);
+int32_t result = omni_main();
+printf("OmniLang program result: %d\n", result);
+return result;
+}
@@ -0,0 +1,50 @@
+#include "omni_rt.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+const char* wrap(const char* s);
+int32_t omni_main();
+
+const char* wrap(const char* s) {
+const char* v1 = "(";
+const char* v2;
+const char* v3 = ")";
+const char* v4;
+v2 = omni_strcat(v1, s);
+v4 = omni_strcat(v2, v3);
+return v4;
+  // Cleanup: free heap-allocated strings
+if (v2 != NULL) { free((void*)v2); v2 = NULL; }
+}
+
+int32_t omni_main() {
+const char* v0;
+const char* v1 = "a";
+const char* v2 = "(a)";
+int32_t v3;
+int32_t v4;
+int32_t v5;
+v0 = wrap(v1);
+v3 = omni_string_equals(v0, v2) ? 1 : 0;
+if (v3) {
+goto then_0;
+} else {
+goto merge_1;
+}
+then_0:
+;
+v4 = 0;
+return v4;
+merge_1:
+;
+v5 = 1;
+return v5;
+}
+
+int main(int argc, char** argv) {
+omni_args_init(argc, argv);
+int32_t result = omni_main();
+printf("OmniLang program result: %d\n", result);
+return result;
+}
@@ -0,0 +1,177 @@
+#include "omni_rt.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+int32_t check(int32_t n);
+int32_t omni_main();
+
+int32_t check(int32_t n) {
+int32_t v1;
+int32_t v2;
+omni_struct_t* v3;
+int32_t v4;
+int32_t v6;
+int32_t v7;
+const char* v8 = "five";
+int32_t v10;
+v1 = 4;
+v2 = (n == v1) ? 1 : 0;
+if (v2) {
+goto then_0;
+} else {
+goto merge_1;
+}
+then_0:
+;
+v4 = 30;
+v3 = omni_struct_create();
+omni_struct_set_int_field(v3, "code", v4);
+omni_throw((omni_exception_t){"Oops", 0, 0, NULL, (void*)v3});
+abort();
+merge_1:
+;
+v6 = 5;
+v7 = (n == v6) ? 1 : 0;
+if (v7) {
+goto then_2;
+} else {
+goto merge_3;
+}
+then_2:
+;
+omni_throw((omni_exception_t){"string", 0, 0, v8, NULL});
+abort();
+merge_3:
+;
+v10 = 1;
+return v10;
+}
+
+int32_t omni_main() {
+volatile int32_t v0;
+volatile int32_t v1;
+volatile int32_t v2;
+volatile int32_t v3;
+volatile int32_t v4;
+volatile int32_t v5;
+volatile int32_t v6;
+volatile int32_t v7;
+volatile int32_t v8;
+volatile int32_t v9;
+omni_struct_t* volatile v10;
+volatile int32_t v11;
+volatile int32_t v12;
+volatile int32_t v13;
+const char* volatile v14;
+volatile int32_t v15;
+volatile int32_t v16;
+volatile int32_t v17;
+volatile int32_t v18;
+volatile int32_t v19;
+volatile int32_t v20;
+volatile omni_exception_t v21;
+volatile int32_t v22;
+volatile int32_t v23;
+volatile int32_t v24;
+omni_try_frame omni_try_0;
+omni_try_frame omni_try_1;
+omni_try_frame omni_try_2;
+v0 = 0;
+v1 = 0;
+goto while_header_0;
+while_header_0:
+;
+v2 = 5;
+v3 = (v1 < v2) ? 1 : 0;
+while (v3) {
+while_body_1:
+;
+v4 = 1;
+v5 = v1 + v4;
+v1 = v5;
+goto try_3;
+try_3:
+;
+omni_try_0.prev = omni_try_top;
+omni_try_0.defers = omni_defer_top;
+omni_try_top = &omni_try_0;
+if (setjmp(omni_try_0.env)) {
+omni_try_top = omni_try_0.prev;
+if (strcmp(omni_exception.type, "string") == 0) goto catch_7;
+goto finally_rethrow_6;
+}
+goto try_8;
+try_merge_4:
+;
+v2 = 5;
+v3 = (v1 < v2) ? 1 : 0;
+continue;
+finally_5:
+;
+v18 = 1;
+v19 = v0 + v18;
+v0 = v19;
+goto try_merge_4;
+try_8:
+;
+omni_try_1.prev = omni_try_top;
+omni_try_1.defers = omni_defer_top;
+omni_try_top = &omni_try_1;
+if (setjmp(omni_try_1.env)) {
+omni_try_top = omni_try_1.prev;
+if (strcmp(omni_exception.type, "Oops") == 0) goto catch_10;
+omni_rethrow();
+}
+v7 = check(v1);
+v8 = v0 + v7;
+v0 = v8;
+omni_try_top = omni_try_top->prev;
+goto try_merge_9;
+try_merge_9:
+;
+omni_try_top = omni_try_top->prev;
+goto finally_5;
+}
+goto while_exit_2;
+while_exit_2:
+;
+return v0;
+finally_rethrow_6:
+;
+v21 = omni_exception;
+v22 = 1;
+v23 = v0 + v22;
+v0 = v23;
+omni_throw(v21);
+abort();
+catch_7:
+;
+v14 = omni_exception.s;
+omni_try_2.prev = omni_try_top;
+omni_try_2.defers = omni_defer_top;
+omni_try_top = &omni_try_2;
+if (setjmp(omni_try_2.env)) {
+omni_try_top = omni_try_2.prev;
+goto finally_rethrow_6;
+}
+v15 = 4;
+v16 = v0 + v15;
+v0 = v16;
+omni_try_top = omni_try_top->prev;
+goto finally_5;
+catch_10:
+;
+v10 = (omni_struct_t*)omni_exception.p;
+v11 = omni_struct_get_int_field(v10, "code");
+v12 = v0 + v11;
+v0 = v12;
+goto try_merge_9;
+}
+
+int main(int argc, char** argv) {
+omni_args_init(argc, argv);
+int32_t result = omni_main();
+printf("OmniLang program result: %d\n", result);
+return result;
+}
@@ -0,0 +1,74 @@
+#include "omni_rt.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+int32_t sum(int32_t* xs, int32_t xs_count);
+int32_t omni_main();
+
+int32_t sum(int32_t* xs, int32_t xs_count) {
+int32_t v1;
+int32_t v2;
+int32_t v3;
+int32_t v4;
+int32_t v5;
+int32_t v6;
+int32_t v7;
+int32_t v8;
+int32_t v9;
+int32_t v10;
+v1 = 0;
+v2 = 0;
+goto while_header_0;
+while_header_0:
+;
+v3 = xs_count;
+v4 = (v2 < v3) ? 1 : 0;
+while (v4) {
+while_body_1:
+;
+v5 = omni_array_get_int(xs, v2, xs_count);
+v6 = v1 + v5;
+v1 = v6;
+v8 = 1;
+v9 = v2 + v8;
+v2 = v9;
+v3 = xs_count;
+v4 = (v2 < v3) ? 1 : 0;
+continue;
+}
+goto while_exit_2;
+while_exit_2:
+;
+return v1;
+}
+
+int32_t omni_main() {
+int32_t v0;
+int32_t v1;
+int32_t v2;
+int32_t v3;
+int32_t* v4 = NULL;
+int32_t v5;
+int32_t* v6 = NULL;
+int32_t v7;
+v1 = 1;
+v2 = 2;
+v3 = 3;
+v4 = (int32_t*)malloc(3 * sizeof(int32_t));
+v4[0] = v1;
+v4[1] = v2;
+v4[2] = v3;
+v0 = sum(v4, 3);
+v6 = NULL;
+v5 = sum(v6, 0);
+v7 = v0 + v5;
+return v7;
+}
+
+int main(int argc, char** argv) {
+omni_args_init(argc, argv);
+int32_t result = omni_main();
+printf("OmniLang program result: %d\n", result);
+return result;
+}
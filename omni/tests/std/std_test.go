@@ -151,6 +151,104 @@ func TestAllStdModules(t *testing.T) {
 		}
 	})
 
+	t.Run("std.io.diff", func(t *testing.T) {
+		result, err := runVM("std_io_diff.omni")
+		if err != nil {
+			t.Fatalf("VM execution failed: %v", err)
+		}
+		expected := "0"
+		if result != expected {
+			t.Errorf("Expected %s, got %s", expected, result)
+		}
+	})
+
+	t.Run("std.collections.graph", func(t *testing.T) {
+		result, err := runVM("std_collections_graph.omni")
+		if err != nil {
+			t.Fatalf("VM execution failed: %v", err)
+		}
+		expected := "0"
+		if result != expected {
+			t.Errorf("Expected %s, got %s", expected, result)
+		}
+	})
+
+	t.Run("std.hash", func(t *testing.T) {
+		result, err := runVM("std_hash.omni")
+		if err != nil {
+			t.Fatalf("VM execution failed: %v", err)
+		}
+		expected := "0"
+		if result != expected {
+			t.Errorf("Expected %s, got %s", expected, result)
+		}
+	})
+	t.Run("std.json", func(t *testing.T) {
+		result, err := runVM("std_json.omni")
+		if err != nil {
+			t.Fatalf("VM execution failed: %v", err)
+		}
+		expected := "0"
+		if result != expected {
+			t.Errorf("Expected %s, got %s", expected, result)
+		}
+	})
+
+	t.Run("std.sort", func(t *testing.T) {
+		result, err := runVM("std_sort.omni")
+		if err != nil {
+			t.Fatalf("VM execution failed: %v", err)
+		}
+		expected := "0"
+		if result != expected {
+			t.Errorf("Expected %s, got %s", expected, result)
+		}
+	})
+
+	t.Run("std.math.interpolation", func(t *testing.T) {
+		result, err := runVM("std_math_interpolation.omni")
+		if err != nil {
+			t.Fatalf("VM execution failed: %v", err)
+		}
+		expected := "0"
+		if result != expected {
+			t.Errorf("Expected %s, got %s", expected, result)
+		}
+	})
+
+	t.Run("std.io.readline_history", func(t *testing.T) {
+		result, err := runVM("std_io_readline_history.omni")
+		if err != nil {
+			t.Fatalf("VM execution failed: %v", err)
+		}
+		expected := "0"
+		if result != expected {
+			t.Errorf("Expected %s, got %s", expected, result)
+		}
+	})
+
+	t.Run("std.time.format", func(t *testing.T) {
+		result, err := runVM("std_time_format.omni")
+		if err != nil {
+			t.Fatalf("VM execution failed: %v", err)
+		}
+		expected := "0"
+		if result != expected {
+			t.Errorf("Expected %s, got %s", expected, result)
+		}
+	})
+
+	t.Run("std.os.signal", func(t *testing.T) {
+		result, err := runVM("std_os_signal.omni")
+		if err != nil {
+			t.Fatalf("VM execution failed: %v", err)
+		}
+		expected := "0"
+		if result != expected {
+			t.Errorf("Expected %s, got %s", expected, result)
+		}
+	})
+
 	t.Run("std.network", func(t *testing.T) {
 		result, err := runVM("std_network_comprehensive.omni")
 		if err != nil {
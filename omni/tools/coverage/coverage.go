@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -15,11 +16,23 @@ type CoverageEntry struct {
 	File     string `json:"file"`
 	Line     int    `json:"line"`
 	Count    int    `json:"count"`
+	// TestName is the test function that made the calls; entries recorded
+	// outside tests leave it empty.
+	TestName string `json:"test_name,omitempty"`
 }
 
 // CoverageData represents the full coverage data structure
 type CoverageData struct {
 	Entries []CoverageEntry `json:"entries"`
+	// Branches maps "function:block:index" to the paths taken by that
+	// conditional branch. Only the VM backend records branches.
+	Branches map[string]BranchData `json:"branches,omitempty"`
+}
+
+// BranchData records which paths of a conditional branch were taken
+type BranchData struct {
+	True  bool `json:"true"`
+	False bool `json:"false"`
 }
 
 // FunctionInfo represents information about a function in a std library file
@@ -180,11 +193,20 @@ func determineQualifiedName(filePath, funcName string) string {
 func MatchCoverageToFunctions(coverage *CoverageData, funcsByFile map[string][]FunctionInfo) map[string]*CoverageMatch {
 	matches := make(map[string]*CoverageMatch)
 
-	// Create a map of covered functions
-	covered := make(map[string]bool)
+	// Sum the calls to each function, which the VM records per test
+	calls := make(map[string]int)
+	byTest := make(map[string]map[string]int)
 	for _, entry := range coverage.Entries {
-		key := entry.Function
-		covered[key] = true
+		calls[entry.Function] += entry.Count
+		if entry.TestName == "" {
+			continue
+		}
+		tests := byTest[entry.Function]
+		if tests == nil {
+			tests = make(map[string]int)
+			byTest[entry.Function] = tests
+		}
+		tests[entry.TestName] += entry.Count
 	}
 
 	// Match functions to coverage
@@ -194,22 +216,13 @@ func MatchCoverageToFunctions(coverage *CoverageData, funcsByFile map[string][]F
 				continue // Only track runtime-wired functions
 			}
 
-			match := &CoverageMatch{
-				Function: fn,
-				Covered:  covered[fn.Name],
-			}
-
-			if match.Covered {
-				// Find the coverage entry
-				for _, entry := range coverage.Entries {
-					if entry.Function == fn.Name {
-						match.CallCount = entry.Count
-						break
-					}
-				}
+			count, covered := calls[fn.Name]
+			matches[fn.Name] = &CoverageMatch{
+				Function:  fn,
+				Covered:   covered,
+				CallCount: count,
+				Tests:     byTest[fn.Name],
 			}
-
-			matches[fn.Name] = match
 		}
 	}
 
@@ -221,6 +234,8 @@ type CoverageMatch struct {
 	Function  FunctionInfo
 	Covered   bool
 	CallCount int
+	// Tests maps the tests that called the function to their call counts.
+	Tests map[string]int
 }
 
 // CalculateCoverage calculates coverage statistics
@@ -231,6 +246,9 @@ func CalculateCoverage(matches map[string]*CoverageMatch) CoverageStats {
 		TotalLines:       0,
 		CoveredLines:     0,
 		FunctionDetails:  make(map[string]FunctionCoverage),
+		LineHits:         make(map[string]map[int]int),
+		ByFile:           make(map[string]FileCoverage),
+		ByTest:           make(map[string]TestCoverage),
 	}
 
 	for name, match := range matches {
@@ -247,6 +265,31 @@ func CalculateCoverage(matches map[string]*CoverageMatch) CoverageStats {
 			Covered:   match.Covered,
 			CallCount: match.CallCount,
 		}
+
+		for test, count := range match.Tests {
+			tc := stats.ByTest[test]
+			if tc.Calls == nil {
+				tc.Calls = make(map[string]int)
+			}
+			tc.Calls[name] += count
+			stats.ByTest[test] = tc
+		}
+
+		lines := stats.LineHits[match.Function.File]
+		if lines == nil {
+			lines = make(map[int]int)
+			stats.LineHits[match.Function.File] = lines
+		}
+		lines[match.Function.LineNumber] += match.CallCount
+
+		file := stats.ByFile[match.Function.File]
+		file.TotalFunctions++
+		file.TotalLines++
+		if match.Covered {
+			file.CoveredFunctions++
+			file.CoveredLines++
+		}
+		stats.ByFile[match.Function.File] = file
 	}
 
 	return stats
@@ -259,6 +302,100 @@ type CoverageStats struct {
 	TotalLines       int
 	CoveredLines     int
 	FunctionDetails  map[string]FunctionCoverage
+	// LineHits maps each file to the hit count of its instrumented lines.
+	// Coverage is recorded per call, so the instrumented lines are the
+	// function declarations.
+	LineHits map[string]map[int]int
+	// ByFile holds the same totals broken down per source file.
+	ByFile map[string]FileCoverage
+	// ByTest maps each test function to the std functions it exercised.
+	ByTest map[string]TestCoverage
+	// TotalBranches and CoveredBranches count branch paths, two per
+	// conditional branch; they are set by AddBranches.
+	TotalBranches   int
+	CoveredBranches int
+}
+
+// AddBranches adds the branch paths in branches to the branch totals.
+func (s *CoverageStats) AddBranches(branches map[string]BranchData) {
+	for _, branch := range branches {
+		s.TotalBranches += 2
+		if branch.True {
+			s.CoveredBranches++
+		}
+		if branch.False {
+			s.CoveredBranches++
+		}
+	}
+}
+
+// GetBranchCoveragePercentage returns the percentage of branch paths taken
+func (s CoverageStats) GetBranchCoveragePercentage() float64 {
+	if s.TotalBranches == 0 {
+		return 0.0
+	}
+	return float64(s.CoveredBranches) / float64(s.TotalBranches) * 100.0
+}
+
+// FileCoverage represents coverage statistics for a single file
+type FileCoverage struct {
+	CoveredFunctions int
+	TotalFunctions   int
+	CoveredLines     int
+	TotalLines       int
+}
+
+// TestCoverage represents the std functions a single test called
+type TestCoverage struct {
+	// Calls maps each function the test called to its call count.
+	Calls map[string]int
+}
+
+// Functions returns the functions the test called, sorted by name.
+func (t TestCoverage) Functions() []string {
+	names := make([]string, 0, len(t.Calls))
+	for name := range t.Calls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tests returns the names of the tests in ByTest, sorted.
+func (s CoverageStats) Tests() []string {
+	tests := make([]string, 0, len(s.ByTest))
+	for test := range s.ByTest {
+		tests = append(tests, test)
+	}
+	sort.Strings(tests)
+	return tests
+}
+
+// GetFunctionCoveragePercentage returns the percentage of the file's
+// functions that are covered
+func (f FileCoverage) GetFunctionCoveragePercentage() float64 {
+	if f.TotalFunctions == 0 {
+		return 0.0
+	}
+	return float64(f.CoveredFunctions) / float64(f.TotalFunctions) * 100.0
+}
+
+// FilesByCoverage returns the files in ByFile ordered from the lowest
+// function coverage to the highest, breaking ties by path.
+func (s CoverageStats) FilesByCoverage() []string {
+	files := make([]string, 0, len(s.ByFile))
+	for file := range s.ByFile {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		pi := s.ByFile[files[i]].GetFunctionCoveragePercentage()
+		pj := s.ByFile[files[j]].GetFunctionCoveragePercentage()
+		if pi != pj {
+			return pi < pj
+		}
+		return files[i] < files[j]
+	})
+	return files
 }
 
 // FunctionCoverage represents coverage for a single function
@@ -285,3 +422,65 @@ func (s CoverageStats) GetLineCoveragePercentage() float64 {
 	}
 	return float64(s.CoveredLines) / float64(s.TotalLines) * 100.0
 }
+
+// FuncsByFile maps std library files to the functions they define, as
+// returned by ParseStdLibrary.
+type FuncsByFile map[string][]FunctionInfo
+
+// DiffResult compares two coverage runs over the same std library.
+type DiffResult struct {
+	Files       []FileDelta      `json:"files"`
+	Regressions []FileRegression `json:"regressions"`
+}
+
+// FileDelta is the change in one file's coverage between two runs.
+type FileDelta struct {
+	File                  string  `json:"file"`
+	CoveredFunctionsDelta int     `json:"covered_functions_delta"`
+	CoveredLinesDelta     int     `json:"covered_lines_delta"`
+	Before                float64 `json:"before"`
+	After                 float64 `json:"after"`
+}
+
+// FileRegression records a file whose function coverage decreased.
+type FileRegression struct {
+	File   string  `json:"file"`
+	Before float64 `json:"before"`
+	After  float64 `json:"after"`
+}
+
+// DiffCoverage computes the per-file coverage change from baseline to
+// current. Files are listed in path order; a file regresses when its
+// function coverage percentage drops.
+func DiffCoverage(baseline, current CoverageData, funcs FuncsByFile) DiffResult {
+	before := CalculateCoverage(MatchCoverageToFunctions(&baseline, funcs))
+	after := CalculateCoverage(MatchCoverageToFunctions(&current, funcs))
+
+	files := make([]string, 0, len(after.ByFile))
+	for file := range after.ByFile {
+		files = append(files, file)
+	}
+	for file := range before.ByFile {
+		if _, ok := after.ByFile[file]; !ok {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	result := DiffResult{Files: []FileDelta{}, Regressions: []FileRegression{}}
+	for _, file := range files {
+		b, a := before.ByFile[file], after.ByFile[file]
+		delta := FileDelta{
+			File:                  file,
+			CoveredFunctionsDelta: a.CoveredFunctions - b.CoveredFunctions,
+			CoveredLinesDelta:     a.CoveredLines - b.CoveredLines,
+			Before:                b.GetFunctionCoveragePercentage(),
+			After:                 a.GetFunctionCoveragePercentage(),
+		}
+		result.Files = append(result.Files, delta)
+		if delta.After < delta.Before {
+			result.Regressions = append(result.Regressions, FileRegression{File: file, Before: delta.Before, After: delta.After})
+		}
+	}
+	return result
+}
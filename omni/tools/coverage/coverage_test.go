@@ -0,0 +1,149 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffCoverage(t *testing.T) {
+	funcs := FuncsByFile{
+		"std/io.omni": {
+			{Name: "std.io.print", File: "std/io.omni", LineNumber: 1, IsWired: true},
+			{Name: "std.io.println", File: "std/io.omni", LineNumber: 4, IsWired: true},
+		},
+		"std/math.omni": {
+			{Name: "std.math.abs", File: "std/math.omni", LineNumber: 1, IsWired: true},
+		},
+	}
+	run := func(names ...string) CoverageData {
+		var data CoverageData
+		for _, name := range names {
+			data.Entries = append(data.Entries, CoverageEntry{Function: name, Count: 1})
+		}
+		return data
+	}
+
+	tests := []struct {
+		name        string
+		baseline    CoverageData
+		current     CoverageData
+		ioDelta     int
+		regressions []FileRegression
+	}{
+		{
+			name:     "same coverage",
+			baseline: run("std.io.print", "std.math.abs"),
+			current:  run("std.io.print", "std.math.abs"),
+		},
+		{
+			name:     "improvement",
+			baseline: run("std.io.print"),
+			current:  run("std.io.print", "std.io.println"),
+			ioDelta:  1,
+		},
+		{
+			name:        "regression",
+			baseline:    run("std.io.print", "std.io.println", "std.math.abs"),
+			current:     run("std.io.print", "std.math.abs"),
+			ioDelta:     -1,
+			regressions: []FileRegression{{File: "std/io.omni", Before: 100, After: 50}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := DiffCoverage(tt.baseline, tt.current, funcs)
+			if len(result.Files) != 2 || result.Files[0].File != "std/io.omni" || result.Files[1].File != "std/math.omni" {
+				t.Fatalf("unexpected files: %+v", result.Files)
+			}
+			io := result.Files[0]
+			if io.CoveredFunctionsDelta != tt.ioDelta || io.CoveredLinesDelta != tt.ioDelta {
+				t.Errorf("std/io.omni delta = %+v, want %d", io, tt.ioDelta)
+			}
+			if len(result.Regressions) != len(tt.regressions) {
+				t.Fatalf("regressions = %+v, want %+v", result.Regressions, tt.regressions)
+			}
+			for i, want := range tt.regressions {
+				if result.Regressions[i] != want {
+					t.Errorf("regression %d = %+v, want %+v", i, result.Regressions[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestBranchCoverageInReports(t *testing.T) {
+	stats := CalculateCoverage(map[string]*CoverageMatch{})
+	stats.AddBranches(map[string]BranchData{
+		"main:entry:0": {True: true, False: true},
+		"main:loop:0":  {True: true},
+	})
+	if stats.TotalBranches != 4 || stats.CoveredBranches != 3 {
+		t.Fatalf("branches = %d/%d, want 3/4", stats.CoveredBranches, stats.TotalBranches)
+	}
+
+	dir := t.TempDir()
+	textPath := filepath.Join(dir, "coverage.txt")
+	htmlPath := filepath.Join(dir, "coverage.html")
+	if err := GenerateTextReport(stats, textPath); err != nil {
+		t.Fatalf("GenerateTextReport: %v", err)
+	}
+	if err := GenerateHTMLReport(stats, htmlPath); err != nil {
+		t.Fatalf("GenerateHTMLReport: %v", err)
+	}
+	text, _ := os.ReadFile(textPath)
+	if !strings.Contains(string(text), "Branch Coverage: 75.00% (3/4 branches)") {
+		t.Errorf("text report missing branch coverage:\n%s", text)
+	}
+	html, _ := os.ReadFile(htmlPath)
+	if !strings.Contains(string(html), "Branch Coverage") || !strings.Contains(string(html), "3/4 branches") {
+		t.Errorf("HTML report missing branch coverage")
+	}
+}
+
+func TestCoverageByTest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.json")
+	data := `{"entries": [
+		{"function": "std.math.abs", "file": "", "line": 0, "count": 1},
+		{"function": "std.math.abs", "file": "", "line": 0, "count": 2, "test_name": "test_abs"},
+		{"function": "std.io.print", "file": "", "line": 0, "count": 1, "test_name": "test_abs"},
+		{"function": "std.io.print", "file": "", "line": 0, "count": 3, "test_name": "test_print"}
+	]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("write coverage: %v", err)
+	}
+	parsed, err := ParseCoverageFile(path)
+	if err != nil {
+		t.Fatalf("ParseCoverageFile: %v", err)
+	}
+	if parsed.Entries[1].TestName != "test_abs" {
+		t.Fatalf("TestName = %q, want test_abs", parsed.Entries[1].TestName)
+	}
+
+	funcs := map[string][]FunctionInfo{
+		"std/io.omni":   {{Name: "std.io.print", File: "std/io.omni", LineNumber: 1, IsWired: true}},
+		"std/math.omni": {{Name: "std.math.abs", File: "std/math.omni", LineNumber: 1, IsWired: true}},
+	}
+	stats := CalculateCoverage(MatchCoverageToFunctions(parsed, funcs))
+	if got := stats.FunctionDetails["std.math.abs"].CallCount; got != 3 {
+		t.Errorf("std.math.abs calls = %d, want 3 across all entries", got)
+	}
+	if got := stats.Tests(); len(got) != 2 || got[0] != "test_abs" || got[1] != "test_print" {
+		t.Fatalf("tests = %v, want [test_abs test_print]", got)
+	}
+	abs := stats.ByTest["test_abs"]
+	if fns := abs.Functions(); len(fns) != 2 || abs.Calls["std.math.abs"] != 2 || abs.Calls["std.io.print"] != 1 {
+		t.Errorf("test_abs calls = %v", abs.Calls)
+	}
+
+	htmlPath := filepath.Join(t.TempDir(), "coverage.html")
+	if err := GenerateHTMLReport(stats, htmlPath); err != nil {
+		t.Fatalf("GenerateHTMLReport: %v", err)
+	}
+	html, _ := os.ReadFile(htmlPath)
+	if !strings.Contains(string(html), "<h2>Tests</h2>") || !strings.Contains(string(html), "test_print - 1 function(s)") {
+		t.Errorf("HTML report missing the per-test breakdown:\n%s", html)
+	}
+}
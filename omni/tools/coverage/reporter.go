@@ -20,8 +20,13 @@ func GenerateTextReport(stats CoverageStats, outputPath string) error {
 
 	sb.WriteString(fmt.Sprintf("Function Coverage: %.2f%% (%d/%d functions)\n",
 		funcCoverage, stats.CoveredFunctions, stats.TotalFunctions))
-	sb.WriteString(fmt.Sprintf("Line Coverage: %.2f%% (%d/%d lines)\n\n",
+	sb.WriteString(fmt.Sprintf("Line Coverage: %.2f%% (%d/%d lines)\n",
 		lineCoverage, stats.CoveredLines, stats.TotalLines))
+	if stats.TotalBranches > 0 {
+		sb.WriteString(fmt.Sprintf("Branch Coverage: %.2f%% (%d/%d branches)\n",
+			stats.GetBranchCoveragePercentage(), stats.CoveredBranches, stats.TotalBranches))
+	}
+	sb.WriteString("\n")
 
 	// Group by file
 	files := make(map[string][]FunctionCoverage)
@@ -66,6 +71,71 @@ func GenerateTextReport(stats CoverageStats, outputPath string) error {
 	return os.WriteFile(outputPath, []byte(sb.String()), 0644)
 }
 
+// GenerateLCOVReport writes the coverage in LCOV tracefile format, as read
+// by genhtml, Codecov and Coveralls. An empty outputPath writes to stdout.
+func GenerateLCOVReport(stats CoverageStats, outputPath string) error {
+	files := make(map[string][]FunctionCoverage)
+	for _, fc := range stats.FunctionDetails {
+		files[fc.File] = append(files[fc.File], fc)
+	}
+
+	fileList := make([]string, 0, len(files))
+	for f := range files {
+		fileList = append(fileList, f)
+	}
+	sort.Strings(fileList)
+
+	var sb strings.Builder
+	for _, file := range fileList {
+		funcs := files[file]
+		sort.Slice(funcs, func(i, j int) bool {
+			if funcs[i].Line != funcs[j].Line {
+				return funcs[i].Line < funcs[j].Line
+			}
+			return funcs[i].Function < funcs[j].Function
+		})
+
+		sb.WriteString("TN:\n")
+		sb.WriteString(fmt.Sprintf("SF:%s\n", file))
+		for _, fc := range funcs {
+			sb.WriteString(fmt.Sprintf("FN:%d,%s\n", fc.Line, fc.Function))
+		}
+		hitFuncs := 0
+		for _, fc := range funcs {
+			sb.WriteString(fmt.Sprintf("FNDA:%d,%s\n", fc.CallCount, fc.Function))
+			if fc.Covered {
+				hitFuncs++
+			}
+		}
+		sb.WriteString(fmt.Sprintf("FNF:%d\n", len(funcs)))
+		sb.WriteString(fmt.Sprintf("FNH:%d\n", hitFuncs))
+
+		lineHits := stats.LineHits[file]
+		lines := make([]int, 0, len(lineHits))
+		for line := range lineHits {
+			lines = append(lines, line)
+		}
+		sort.Ints(lines)
+		hitLines := 0
+		for _, line := range lines {
+			sb.WriteString(fmt.Sprintf("DA:%d,%d\n", line, lineHits[line]))
+			if lineHits[line] > 0 {
+				hitLines++
+			}
+		}
+		sb.WriteString(fmt.Sprintf("LF:%d\n", len(lines)))
+		sb.WriteString(fmt.Sprintf("LH:%d\n", hitLines))
+		sb.WriteString("end_of_record\n")
+	}
+
+	if outputPath == "" {
+		fmt.Print(sb.String())
+		return nil
+	}
+
+	return os.WriteFile(outputPath, []byte(sb.String()), 0644)
+}
+
 // GenerateHTMLReport generates an HTML coverage report
 func GenerateHTMLReport(stats CoverageStats, outputPath string) error {
 	funcCoverage := stats.GetFunctionCoveragePercentage()
@@ -121,6 +191,25 @@ func GenerateHTMLReport(stats CoverageStats, outputPath string) error {
 		})
 	}
 
+	// Break the calls down by the test that made them
+	type TestCall struct {
+		Function string
+		Calls    int
+	}
+	type TestData struct {
+		Name  string
+		Calls []TestCall
+	}
+	testDataList := make([]TestData, 0, len(stats.ByTest))
+	for _, test := range stats.Tests() {
+		tc := stats.ByTest[test]
+		data := TestData{Name: test}
+		for _, fn := range tc.Functions() {
+			data.Calls = append(data.Calls, TestCall{Function: fn, Calls: tc.Calls[fn]})
+		}
+		testDataList = append(testDataList, data)
+	}
+
 	tmpl := `<!DOCTYPE html>
 <html>
 <head>
@@ -231,6 +320,15 @@ func GenerateHTMLReport(stats CoverageStats, outputPath string) error {
             <div class="metric-label">Line Coverage</div>
             <div style="font-size: 12px; color: #666;">{{.CoveredLines}}/{{.TotalLines}} lines</div>
         </div>
+        {{if .TotalBranches}}
+        <div class="metric">
+            <div class="metric-value" style="color: {{if ge .BranchCoverage 60.0}}#27ae60{{else if ge .BranchCoverage 30.0}}#f39c12{{else}}#e74c3c{{end}};">
+                {{printf "%.2f" .BranchCoverage}}%
+            </div>
+            <div class="metric-label">Branch Coverage</div>
+            <div style="font-size: 12px; color: #666;">{{.CoveredBranches}}/{{.TotalBranches}} branches</div>
+        </div>
+        {{end}}
     </div>
     
     <h2>Files</h2>
@@ -256,6 +354,20 @@ func GenerateHTMLReport(stats CoverageStats, outputPath string) error {
         {{end}}
     </div>
     {{end}}
+    {{if .Tests}}
+    <h2>Tests</h2>
+    {{range .Tests}}
+    <div class="file">
+        <div class="file-header">{{.Name}} - {{len .Calls}} function(s)</div>
+        {{range .Calls}}
+        <div class="function covered">
+            <span class="function-name">{{.Function}}</span>
+            <span style="color: #27ae60;">Called {{.Calls}} time(s)</span>
+        </div>
+        {{end}}
+    </div>
+    {{end}}
+    {{end}}
 </body>
 </html>`
 
@@ -268,7 +380,11 @@ func GenerateHTMLReport(stats CoverageStats, outputPath string) error {
 		TotalFunctions   int
 		CoveredLines     int
 		TotalLines       int
+		BranchCoverage   float64
+		CoveredBranches  int
+		TotalBranches    int
 		Files            []FileData
+		Tests            []TestData
 	}{
 		FuncCoverage:     funcCoverage,
 		LineCoverage:     lineCoverage,
@@ -276,7 +392,11 @@ func GenerateHTMLReport(stats CoverageStats, outputPath string) error {
 		TotalFunctions:   stats.TotalFunctions,
 		CoveredLines:     stats.CoveredLines,
 		TotalLines:       stats.TotalLines,
+		BranchCoverage:   stats.GetBranchCoveragePercentage(),
+		CoveredBranches:  stats.CoveredBranches,
+		TotalBranches:    stats.TotalBranches,
 		Files:            fileDataList,
+		Tests:            testDataList,
 	}
 
 	var output strings.Builder
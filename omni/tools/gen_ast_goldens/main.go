@@ -200,8 +200,77 @@ func process%d(values:array<int>):int {
 		})
 	}
 
-	if len(cases) != 100 {
-		panic(fmt.Sprintf("expected 100 cases, got %d", len(cases)))
+	// Category K: method declarations and method calls
+	for i := 1; i <= 5; i++ {
+		cases = append(cases, caseSpec{
+			name: fmt.Sprintf("decl_method_%02d", i),
+			source: fmt.Sprintf(`struct Counter%d {
+  count:int
+}
+
+func (c Counter%d) add(n:int):int {
+  return c.count + n
+}
+
+func useCounter%d(c:Counter%d):int {
+  return c.add(%d)
+}`, i, i, i, i, i),
+		})
+	}
+
+	// Category L: match expressions over a three-variant enum
+	for i := 1; i <= 5; i++ {
+		cases = append(cases, caseSpec{
+			name: fmt.Sprintf("expr_match_%02d", i),
+			source: fmt.Sprintf(`enum Signal%d { RED AMBER GREEN }
+
+func wait%d(s:Signal%d):int {
+  return match s {
+    case Signal%d.RED => %d,
+    case Signal%d.AMBER => 1,
+    case _ => 0
+  }
+}`, i, i, i, i, i*10, i),
+		})
+	}
+
+	// Category M: destructuring let and var declarations
+	for i := 1; i <= 5; i++ {
+		cases = append(cases, caseSpec{
+			name: fmt.Sprintf("stmt_destructure_%02d", i),
+			source: fmt.Sprintf(`struct Record%d {
+  id:int
+  label:string
+  score:int
+}
+
+func unpack%d(r:Record%d):int {
+  let (lo, hi) = (%d, %d)
+  var { id, score: s, .. } = r
+  return id + s + lo + hi
+}`, i, i, i, i, i*10),
+		})
+	}
+
+	// Category N: default parameter values, called with and without the
+	// defaulted arguments
+	for i := 1; i <= 5; i++ {
+		cases = append(cases, caseSpec{
+			name: fmt.Sprintf("func_default_params_%02d", i),
+			source: fmt.Sprintf(`func pad%d(text:string, width:int = %d, fill:string = "-"):string {
+  return text
+}
+
+func main():int {
+  let a:string = pad%d("x")
+  let b:string = pad%d("x", %d, "*")
+  return 0
+}`, i, i*4, i, i, i),
+		})
+	}
+
+	if len(cases) != 120 {
+		panic(fmt.Sprintf("expected 120 cases, got %d", len(cases)))
 	}
 
 	return cases
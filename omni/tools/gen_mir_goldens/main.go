@@ -84,5 +84,41 @@ func buildCases() []caseSpec {
 			name:   "if_else",
 			source: "func max(a:int, b:int):int { if a > b { return a } else { return b } }\n",
 		},
+		{
+			name:   "for_sum",
+			source: "func sum():int {\n  var total:int = 0\n  for var i:int = 0; i < 10; i++ {\n    total = total + i\n  }\n  return total\n}\n",
+		},
+		{
+			name:   "inline_call",
+			source: "func square(x:int):int => x * x\nfunc main(n:int):int {\n  let a:int = square(n)\n  return a + square(3)\n}\n",
+		},
+		{
+			name:   "inline_branches",
+			source: "func abs(x:int):int {\n  if x < 0 {\n    return -x\n  }\n  return x\n}\nfunc main(n:int):int {\n  return abs(n) + 1\n}\n",
+		},
+		{
+			name:   "loop_break_continue",
+			source: "func main():int {\n  var sum:int = 0\n  for var i:int = 0; i < 10; i++ {\n    if i == 7 {\n      break\n    }\n    if i % 2 == 0 {\n      continue\n    }\n    sum = sum + i\n  }\n  return sum\n}\n",
+		},
+		{
+			name:   "while_loop",
+			source: "func main():int {\n  var n:int = 27\n  var steps:int = 0\n  while n != 1 {\n    if n % 2 == 0 {\n      n = n / 2\n    } else {\n      n = 3 * n + 1\n    }\n    steps = steps + 1\n  }\n  return steps\n}\n",
+		},
+		{
+			name:   "match_enum",
+			source: "enum Color { RED GREEN BLUE }\nfunc code(c:Color):int {\n  return match c {\n    case Color.RED => 1,\n    case Color.GREEN => 2,\n    case Color.BLUE => 3\n  }\n}\nfunc main():int {\n  return code(Color.GREEN)\n}\n",
+		},
+		{
+			name:   "variadic_sum",
+			source: "func sum(xs:...int):int {\n  var s:int = 0\n  var i:int = 0\n  while i < len(xs) {\n    s = s + xs[i]\n    i = i + 1\n  }\n  return s\n}\nfunc main():int {\n  return sum(1, 2, 3) + sum()\n}\n",
+		},
+		{
+			name:   "global_counter",
+			source: "var count:int = 0\nlet step:int = 2\nfunc bump():int {\n  count = count + step\n  count++\n  return count\n}\nfunc main():int {\n  bump()\n  return bump()\n}\n",
+		},
+		{
+			name:   "inline_recursive",
+			source: "func fact(n:int):int {\n  if n <= 1 {\n    return 1\n  }\n  return n * fact(n - 1)\n}\nfunc main():int {\n  return fact(5)\n}\n",
+		},
 	}
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -32,7 +33,12 @@ func main() {
 		if err != nil {
 			panic(fmt.Errorf("parse %s: %w", omniPath, err))
 		}
-		err = checker.Check(logical, c.source, mod)
+		warnings, err := checker.CheckWithOptions(logical, c.source, mod, checker.Options{WarnDeadCode: true, WarnUnusedVars: true})
+		diags := make([]error, 0, len(warnings)+1)
+		for _, warning := range warnings {
+			diags = append(diags, warning)
+		}
+		err = errors.Join(append(diags, err)...)
 		output := ""
 		if err != nil {
 			output = err.Error()
@@ -47,7 +53,7 @@ func main() {
 }
 
 func buildCases() []caseSpec {
-	cases := make([]caseSpec, 0, 50)
+	cases := make([]caseSpec, 0, 58)
 
 	for i := 1; i <= 25; i++ {
 		cases = append(cases, caseSpec{
@@ -63,8 +69,34 @@ func buildCases() []caseSpec {
 		})
 	}
 
-	if len(cases) != 50 {
-		panic(fmt.Sprintf("expected 50 cases, got %d", len(cases)))
+	unreachable := []string{
+		"func main():int {\n    return 0\n    let x:int = 1\n}\n",
+		"func main():int {\n    throw \"failed\"\n    return 1\n}\n",
+		"func main():int {\n    for i:int = 0; i < 3; i++ {\n        break\n        let x:int = i\n    }\n    return 0\n}\n",
+		"func main():int {\n    var total:int = 0\n    for i:int = 0; i < 3; i++ {\n        continue\n        total = total + i\n    }\n    return total\n}\n",
+		"func sign(a:int):int {\n    if a > 0 {\n        return 1\n    } else {\n        return -1\n    }\n    return 0\n}\n",
+	}
+	for i, source := range unreachable {
+		cases = append(cases, caseSpec{
+			name:   fmt.Sprintf("unreachable_%02d", i+1),
+			source: source,
+		})
+	}
+
+	unused := []string{
+		"func main():int {\n    let count:int = 5\n    return 0\n}\n",
+		"func main():int {\n    var total:int = 0\n    total = 3\n    let (q, r) = (7, 1)\n    return q\n}\n",
+		"func main():int {\n    let _unused = 5\n    return 0\n}\n",
+	}
+	for i, source := range unused {
+		cases = append(cases, caseSpec{
+			name:   fmt.Sprintf("unused_var_%02d", i+1),
+			source: source,
+		})
+	}
+
+	if len(cases) != 58 {
+		panic(fmt.Sprintf("expected 58 cases, got %d", len(cases)))
 	}
 	return cases
 }
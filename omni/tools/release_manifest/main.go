@@ -1,28 +1,15 @@
 package main
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"time"
-)
-
-type artifact struct {
-	Name string `json:"name"`
-	Size int64  `json:"size"`
-	SHA  string `json:"sha256"`
-}
 
-type manifest struct {
-	Version   string     `json:"version"`
-	Generated string     `json:"generated"`
-	Artifacts []artifact `json:"artifacts"`
-}
+	"github.com/omni-lang/omni/internal/packaging"
+)
 
 func main() {
 	dir := flag.String("dir", "", "directory containing release artifacts")
@@ -41,7 +28,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	data := manifest{
+	data := packaging.ReleaseManifest{
 		Version:   *version,
 		Generated: time.Now().UTC().Format(time.RFC3339),
 		Artifacts: entries,
@@ -62,8 +49,8 @@ func main() {
 	}
 }
 
-func collectArtifacts(dir string) ([]artifact, error) {
-	var artifacts []artifact
+func collectArtifacts(dir string) ([]packaging.ReleaseArtifact, error) {
+	var artifacts []packaging.ReleaseArtifact
 	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -72,7 +59,7 @@ func collectArtifacts(dir string) ([]artifact, error) {
 			return nil
 		}
 		name := filepath.Base(path)
-		if name == "release.json" {
+		if name == packaging.ReleaseManifestName || name == packaging.ChecksumsFileName {
 			return nil
 		}
 
@@ -86,14 +73,14 @@ func collectArtifacts(dir string) ([]artifact, error) {
 		if !info.Mode().IsRegular() {
 			return nil
 		}
-		sum, err := sha256File(path)
+		sum, err := packaging.SHA256File(path)
 		if err != nil {
 			return err
 		}
-		artifacts = append(artifacts, artifact{
-			Name: name,
-			Size: info.Size(),
-			SHA:  sum,
+		artifacts = append(artifacts, packaging.ReleaseArtifact{
+			Name:   name,
+			Size:   info.Size(),
+			SHA256: sum,
 		})
 		return nil
 	})
@@ -102,17 +89,3 @@ func collectArtifacts(dir string) ([]artifact, error) {
 	}
 	return artifacts, nil
 }
-
-func sha256File(path string) (string, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return "", err
-	}
-	defer f.Close()
-
-	hasher := sha256.New()
-	if _, err := io.Copy(hasher, f); err != nil {
-		return "", err
-	}
-	return hex.EncodeToString(hasher.Sum(nil)), nil
-}